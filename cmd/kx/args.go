@@ -1,7 +1,5 @@
-// Copyright (c) 2018-2022 Blockwatch Data Inc.
+// Copyright (c) 2018-2025 Blockwatch Data Inc.
 // Author: alex@blockwatch.cc
-//go:build ignore
-// +build ignore
 
 package main
 
@@ -10,9 +8,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strconv"
-	"strings"
 
+	"blockwatch.cc/knoxdb/pkg/knox"
 	"github.com/echa/log"
 )
 
@@ -21,15 +18,24 @@ var (
 	verbose bool
 	debug   bool
 	trace   bool
-	noflush bool
+	dryRun  bool
+	useJson bool
 	cmdinfo = `
 Available Commands:
-  stats       show boltdb stats
+  list        list tables, indexes and enums with metrics
+  compact     compact tables (not supported yet)
   reindex     rebuild indexes
-  rebuild     rebuild statistics
-  compact     compact table
-  flush       flush journals
-  gc          garbage collect bolt storage space
+  flush       merge table journals into table storage
+  checkpoint  write catalog and table checkpoints
+  gc          drop WAL segments no longer required for recovery
+  shell       interactive query shell on a read-only database
+  import      load a CSV or Parquet file into a new table
+  export      write table rows as CSV, Parquet or Arrow to a file or stdout
+
+Commands act on all tables or indexes unless an object name is given.
+Import and export take a table name and a file as extra argument. Files
+with .parquet extension use Parquet format, .arrow files use the Arrow IPC
+stream format (export only), all others CSV.
 `
 )
 
@@ -38,11 +44,12 @@ func init() {
 	flags.BoolVar(&verbose, "v", false, "be verbose")
 	flags.BoolVar(&debug, "vv", false, "debug mode")
 	flags.BoolVar(&trace, "vvv", false, "trace mode")
-	flags.BoolVar(&noflush, "noflush", false, "disable journal flush")
+	flags.BoolVar(&dryRun, "dry-run", false, "show what would be done without changing the database")
+	flags.BoolVar(&useJson, "json", false, "write output as JSON")
 }
 
 func printhelp() {
-	fmt.Println("Usage:\n  kx [flags] [command] [database][/table][/index][/pack]")
+	fmt.Println("Usage:\n  kx [flags] [command] [path/database][/table|/index] [file]")
 	fmt.Println(cmdinfo)
 	fmt.Println("Flags:")
 	flags.PrintDefaults()
@@ -50,11 +57,11 @@ func printhelp() {
 }
 
 type Args struct {
-	cmd   string
-	db    string
-	table string
-	id1   int
-	id2   int
+	cmd    string
+	dir    string
+	db     string
+	object string
+	file   string
 }
 
 func parseArgs() (args Args, err error) {
@@ -62,126 +69,58 @@ func parseArgs() (args Args, err error) {
 	if err != nil {
 		if err == flag.ErrHelp {
 			printhelp()
-			err = nil
-			return
 		}
 		return
 	}
-	lvl := log.LevelInfo
-	switch true {
+
+	// log to stderr to keep stdout clean for command output
+	cfg := log.NewConfig()
+	cfg.Backend = "stderr"
+	switch {
 	case trace:
-		lvl = log.LevelTrace
+		cfg.Level = log.LevelTrace
 	case debug:
-		lvl = log.LevelDebug
+		cfg.Level = log.LevelDebug
 	case verbose:
-		lvl = log.LevelInfo
+		cfg.Level = log.LevelInfo
+	default:
+		cfg.Level = log.LevelWarn
 	}
-	log.SetLevel(lvl)
+	log.Init(cfg)
 
 	if flags.NArg() < 2 {
-		err = fmt.Errorf("Missing argument. Need command and database file!")
+		err = fmt.Errorf("missing argument, need command and database path")
 		return
 	}
-	if cmd := flags.Arg(0); cmd == "" {
-		err = fmt.Errorf("Missing command. See -h")
-		return
-	} else {
-		args.cmd = cmd
-	}
-
-	db, dbx := separateTargetDescriptors(flags.Arg(1))
-	args.db = db
-
-	switch len(dbx) {
-	case 0:
-		// none
-	case 1:
-		// 1: /:table-name
-		// 2: /:pack-id
-		// 3: /:index-id
-		if p, ok := readNumber(dbx[0]); ok {
-			args.id1 = p
-		} else {
-			args.table = dbx[0]
-		}
-	case 2:
-		// 1: /:table-name/:pack-id
-		// 2: /:table-name/:index-id
-		// 3: /:index-id/:pack-id
-		p1, ok1 := readNumber(dbx[0])
-		p2, ok2 := readNumber(dbx[1])
-		if !ok1 {
-			args.table = dbx[0]
-			args.id1 = p2
-		} else if ok2 {
-			args.id1 = p1
-			args.id2 = p2
-		} else {
-			err = fmt.Errorf("invalid id '%s': %v", dbx[1], err)
-			return
-		}
-	case 3:
-		// 1: /:table-name/:index-id/:pack-id
-		args.table = dbx[0]
-		if p, ok := readNumber(dbx[1]); ok {
-			args.id1 = p
-		} else {
-			err = fmt.Errorf("invalid index id '%s': %v", dbx[1], err)
-			return
-		}
-		if p, ok := readNumber(dbx[2]); ok {
-			args.id2 = p
-		} else {
-			err = fmt.Errorf("invalid pack id '%s': %v", dbx[2], err)
-			return
-		}
-	default:
-		err = fmt.Errorf("invalid database locator")
+	args.cmd = flags.Arg(0)
+	args.dir, args.db, args.object, err = separateTarget(flags.Arg(1))
+	if err != nil {
 		return
 	}
-
-	// table name defaults to same name as db file basename
-	if args.table == "" {
-		args.table = strings.TrimSuffix(filepath.Base(args.db), ".db")
-	}
+	args.file = flags.Arg(2)
 
 	if debug {
 		log.Debug("cmd=", args.cmd)
+		log.Debug("dir=", args.dir)
 		log.Debug("db=", args.db)
-		log.Debug("table=", args.table)
-		log.Debug("id1=", args.id1)
-		log.Debug("id2=", args.id2)
+		log.Debug("object=", args.object)
+		log.Debug("file=", args.file)
 	}
 
 	return
 }
 
-// Takes target descriptor and splits it into components
-//
-// returns the filename of the database and an array of optional descriptors
-func separateTargetDescriptors(descriptor string) (string, []string) {
-	targetSplit := strings.Split(descriptor, "/")
-	for i, _ := range targetSplit {
-		info, err := os.Stat(strings.Join(targetSplit[0:i+1], "/"))
-		if err == nil && !info.IsDir() {
-			return strings.Join(targetSplit[0:i+1], "/"), targetSplit[i+1 : len(targetSplit)]
-		}
-	}
-	return "", []string{}
-}
-
-func readNumber(s string) (int, bool) {
-	var (
-		p   int
-		err error
-	)
-	if strings.HasPrefix(s, "0x") {
-		p, err = strconv.Atoi(strings.TrimPrefix(s, "0x"))
-	} else {
-		p, err = strconv.Atoi(s)
+// separateTarget splits a target descriptor into the database directory,
+// the database name and an optional table or index name.
+func separateTarget(s string) (string, string, string, error) {
+	p := filepath.Clean(s)
+	if ok, _ := knox.IsDatabaseExist(filepath.Base(p), knox.WithPath(filepath.Dir(p))); ok {
+		return filepath.Dir(p), filepath.Base(p), "", nil
 	}
-	if err == nil {
-		return p, true
+	db, object := filepath.Split(p)
+	db = filepath.Clean(db)
+	if ok, _ := knox.IsDatabaseExist(filepath.Base(db), knox.WithPath(filepath.Dir(db))); ok {
+		return filepath.Dir(db), filepath.Base(db), object, nil
 	}
-	return 0, false
+	return "", "", "", fmt.Errorf("no database found at %s", s)
 }
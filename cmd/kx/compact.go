@@ -1,7 +1,5 @@
-// Copyright (c) 2018-2020 Blockwatch Data Inc.
+// Copyright (c) 2018-2025 Blockwatch Data Inc.
 // Author: alex@blockwatch.cc
-//go:build ignore
-// +build ignore
 
 package main
 
@@ -9,39 +7,58 @@ import (
 	"context"
 	"fmt"
 
-	"blockwatch.cc/knoxdb/pack"
+	"blockwatch.cc/knoxdb/pkg/knox"
 )
 
-// compact compacts a table and its indexes to remove pack fragmentation
-func compact(ctx context.Context, data interface{}) (err error) {
-	switch t := data.(type) {
-	case *pack.PackTable:
-		err = t.Flush(ctx)
-		if err == nil {
-			err = t.Compact(ctx)
-		}
-	case *pack.KeyValueTable:
-		err = t.Sync(ctx)
-		if err == nil {
-			err = t.Compact(ctx)
-		}
-	default:
-		err = fmt.Errorf("compaction for %T tables not implemented", data)
+// compact would rewrite table data to remove pack fragmentation. The table
+// engine does not rewrite packs yet (it only retrains dictionaries), so
+// report the command as unsupported instead of claiming success.
+func compact(db knox.Database, name string) ([]*Action, error) {
+	if _, err := tableNames(db, name); err != nil {
+		return nil, err
 	}
-	return
+	return nil, fmt.Errorf("compact is not supported: table pack rewrite is not implemented")
 }
 
-// gc runs garbace collection on a bolt kv store by creating a new boltdb file
-// and copying all nested buckets and key/value pairs from the original. Replaces
-// the original file on success. this operation needs up to twice the amount of
-// disk space.
-func gc(ctx context.Context, data interface{}) error {
-	db := data.(*pack.DB)
-	return db.GC(ctx, 1.0)
+// flush merges journal data into table storage.
+func flush(db knox.Database, name string) ([]*Action, error) {
+	names, err := tableNames(db, name)
+	if err != nil {
+		return nil, err
+	}
+	actions := make([]*Action, 0, len(names))
+	for _, n := range names {
+		actions = append(actions, NewAction("flush", n, func(ctx context.Context) error {
+			return db.FlushTable(ctx, n)
+		}))
+	}
+	return actions, nil
+}
+
+// checkpoint writes new catalog and table checkpoints to the WAL.
+func checkpoint(db knox.Database, name string) ([]*Action, error) {
+	if name != "" {
+		return nil, fmt.Errorf("checkpoint applies to the entire database")
+	}
+	return []*Action{NewAction("checkpoint", "", db.Checkpoint)}, nil
 }
 
-// flush flushes journal data to packs
-func flush(ctx context.Context, data interface{}) error {
-	table := data.(*pack.PackTable)
-	return table.Flush(ctx)
+// gc removes WAL segments that are no longer required for crash recovery.
+// Run flush or checkpoint before to advance the WAL watermark.
+func gc(db knox.Database, name string) ([]*Action, error) {
+	if name != "" {
+		return nil, fmt.Errorf("gc applies to the entire database")
+	}
+	return []*Action{NewAction("gc", "", db.GC)}, nil
+}
+
+// tableNames returns the named table or all tables when name is empty.
+func tableNames(db knox.Database, name string) ([]string, error) {
+	if name == "" {
+		return db.ListTables(), nil
+	}
+	if _, err := db.FindTable(name); err != nil {
+		return nil, fmt.Errorf("table %s: %v", name, err)
+	}
+	return []string{name}, nil
 }
@@ -0,0 +1,31 @@
+// Copyright (c) 2018-2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactUnsupported(t *testing.T) {
+	sh, _ := newTestShell(t)
+
+	actions, err := compact(sh.db, "")
+	require.ErrorContains(t, err, "not supported")
+	require.Empty(t, actions)
+
+	actions, err = compact(sh.db, "shell_trade")
+	require.ErrorContains(t, err, "not supported")
+	require.Empty(t, actions)
+
+	// unknown tables are reported first
+	_, err = compact(sh.db, "missing")
+	require.ErrorContains(t, err, "table missing")
+
+	// other maintenance commands still produce actions
+	actions, err = flush(sh.db, "shell_trade")
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2018-2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"blockwatch.cc/knoxdb/pkg/csv"
+	"blockwatch.cc/knoxdb/pkg/knox"
+	"github.com/echa/log"
+)
+
+// importCSV bulk loads a CSV file into a table. The CSV dialect and field
+// types are detected from a sample of the file. The table is created from
+// the detected schema in the import transaction and must not exist yet.
+func importCSV(db knox.Database, name, file string) ([]*Action, error) {
+	if name == "" {
+		return nil, fmt.Errorf("missing table name")
+	}
+	if file == "" {
+		return nil, fmt.Errorf("missing CSV file")
+	}
+	action := NewAction("import", name, func(ctx context.Context) error {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		// detect dialect and schema, then rewind
+		sniff := csv.NewSniffer(f, 0)
+		if err := sniff.Sniff(); err != nil {
+			return fmt.Errorf("sniffing %s: %v", file, err)
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		// imports must run in the transaction which creates the table
+		if _, err := db.FindTable(name); err == nil {
+			return fmt.Errorf("%s: %w", name, knox.ErrTableExists)
+		}
+		ctx, commit, abort, err := db.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer abort()
+		s := sniff.Schema().WithName(name)
+		table, err := db.CreateTable(ctx, s, knox.NewTableOptions()...)
+		if err != nil {
+			return err
+		}
+
+		res := sniff.Result()
+		_, n, err := table.ImportCSV(ctx, f, knox.CSVOptions{
+			Separator:  res.Sep,
+			Header:     res.HasHeader,
+			Trim:       res.NeedsTrim,
+			TimeFormat: res.TimeFormat,
+			DateFormat: res.DateFormat,
+		})
+		if err != nil {
+			return err
+		}
+		if err := commit(); err != nil {
+			return err
+		}
+		log.Infof("Created table %s with %d fields", name, s.NumFields())
+		log.Infof("Imported %d records into %s", n, name)
+		return nil
+	})
+	return []*Action{action}, nil
+}
+
+// exportCSV writes all rows of a table as CSV to file or stdout when
+// file is empty.
+func exportCSV(ctx context.Context, db knox.Database, name, file string) error {
+	if name == "" {
+		return fmt.Errorf("missing table name")
+	}
+	table, err := db.FindTable(name)
+	if err != nil {
+		return err
+	}
+	var w io.Writer = os.Stdout
+	if file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return knox.NewQuery().
+		WithTable(table).
+		ExportCSV(ctx, w, knox.DefaultCSVOptions())
+}
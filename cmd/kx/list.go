@@ -0,0 +1,136 @@
+// Copyright (c) 2018-2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"blockwatch.cc/knoxdb/pkg/knox"
+	"blockwatch.cc/knoxdb/pkg/util"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+type Listing struct {
+	Tables []TableInfo `json:"tables"`
+	Enums  []EnumInfo  `json:"enums,omitempty"`
+}
+
+type TableInfo struct {
+	knox.TableMetrics
+	Indexes []IndexInfo `json:"indexes,omitempty"`
+}
+
+type IndexInfo struct {
+	knox.IndexMetrics
+	Table    string  `json:"table"`
+	Status   string  `json:"status"`
+	Progress float64 `json:"progress"`
+}
+
+type EnumInfo struct {
+	Name   string `json:"name"`
+	Values int    `json:"values"`
+}
+
+// list collects metrics for all tables, their indexes and enums. When
+// name is not empty the listing is limited to a single table or index.
+func list(db knox.Database, name string) (*Listing, error) {
+	l := &Listing{
+		Tables: make([]TableInfo, 0),
+	}
+	for _, tn := range db.ListTables() {
+		t, err := db.FindTable(tn)
+		if err != nil {
+			return nil, err
+		}
+		info := TableInfo{TableMetrics: t.Metrics()}
+		for _, in := range db.ListIndexes(tn) {
+			if name != "" && name != tn && name != in {
+				continue
+			}
+			idx, err := db.FindIndex(in)
+			if err != nil {
+				return nil, err
+			}
+			info.Indexes = append(info.Indexes, IndexInfo{
+				IndexMetrics: idx.Metrics(),
+				Table:        tn,
+				Status:       idx.Status().String(),
+				Progress:     idx.Progress(),
+			})
+		}
+		if name != "" && name != tn && len(info.Indexes) == 0 {
+			continue
+		}
+		l.Tables = append(l.Tables, info)
+	}
+	if name != "" {
+		if len(l.Tables) == 0 {
+			return nil, fmt.Errorf("no table or index %s", name)
+		}
+		return l, nil
+	}
+	for _, en := range db.ListEnums() {
+		e, err := db.FindEnum(en)
+		if err != nil {
+			return nil, err
+		}
+		l.Enums = append(l.Enums, EnumInfo{Name: en, Values: e.Len()})
+	}
+	return l, nil
+}
+
+func (l *Listing) Print(w io.Writer) {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.SetTitle("Tables")
+	t.AppendHeader(table.Row{"Name", "Tuples", "Packs", "Journal", "Segments", "Tombstones", "Size", "Meta"})
+	for _, v := range l.Tables {
+		m := v.TableMetrics
+		t.AppendRow(table.Row{
+			m.Name,
+			util.PrettyInt64(m.TupleCount),
+			util.PrettyInt64(m.PacksCount),
+			util.PrettyInt64(m.JournalTuples),
+			m.JournalSegments,
+			util.PrettyInt64(m.JournalTombstones),
+			util.ByteSize(m.TotalSize),
+			util.ByteSize(m.MetaSize),
+		})
+	}
+	t.Render()
+
+	t = table.NewWriter()
+	t.SetOutputMirror(w)
+	t.SetTitle("Indexes")
+	t.AppendHeader(table.Row{"Name", "Table", "Status", "Progress", "Tuples", "Packs", "Size"})
+	for _, v := range l.Tables {
+		for _, idx := range v.Indexes {
+			m := idx.IndexMetrics
+			t.AppendRow(table.Row{
+				m.Name,
+				idx.Table,
+				idx.Status,
+				fmt.Sprintf("%.2f%%", idx.Progress*100),
+				util.PrettyInt64(m.TupleCount),
+				util.PrettyInt64(m.PacksCount),
+				util.ByteSize(m.TotalSize),
+			})
+		}
+	}
+	t.Render()
+
+	if len(l.Enums) == 0 {
+		return
+	}
+	t = table.NewWriter()
+	t.SetOutputMirror(w)
+	t.SetTitle("Enums")
+	t.AppendHeader(table.Row{"Name", "Values"})
+	for _, e := range l.Enums {
+		t.AppendRow(table.Row{e.Name, e.Values})
+	}
+	t.Render()
+}
@@ -1,7 +1,5 @@
-// Copyright (c) 2018-2020 Blockwatch Data Inc.
+// Copyright (c) 2018-2025 Blockwatch Data Inc.
 // Author: alex@blockwatch.cc
-//go:build ignore
-// +build ignore
 
 // knoxdb cli
 
@@ -9,11 +7,12 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
-	"path/filepath"
-	"strings"
 	"syscall"
 	"time"
 
@@ -24,90 +23,155 @@ import (
 func main() {
 	if err := run(); err != nil {
 		log.Error(err)
+		os.Exit(1)
 	}
 }
 
 func run() error {
 	args, err := parseArgs()
 	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
 		return err
 	}
 
+	// plan commands on a read-only database, writes require exclusive
+	// access which fails while another process has the database open
+	readOnly := dryRun || args.cmd == "list" || args.cmd == "shell" ||
+		args.cmd == "export"
+	ctx := context.Background()
+	db, err := openDatabase(ctx, args, readOnly)
+	if err != nil {
+		return err
+	}
+	defer db.Close(ctx)
+
+	var actions []*Action
 	switch args.cmd {
-	case "stats":
-		return boltStats(args.db)
-	case "compact":
-		db, table, err := openTable(args)
+	case "list":
+		l, err := list(db, args.object)
 		if err != nil {
 			return err
 		}
-		defer db.Close()
-		defer table.Close()
-		return runAbortable(table, compact)
-	case "reindex":
-		db, table, err := openTable(args)
-		if err != nil {
-			return err
+		if useJson {
+			return writeJson(os.Stdout, l)
 		}
-		defer db.Close()
-		defer table.Close()
-		return runAbortable(table, reindex)
-	case "rebuild":
-		return rebuildStatistics(args)
-	case "flush":
-		db, table, err := openTable(args)
-		if err != nil {
-			return err
+		l.Print(os.Stdout)
+		return nil
+	case "shell":
+		return shell(ctx, db, args)
+	case "export":
+		switch {
+		case isParquet(args.file):
+			return exportParquet(ctx, db, args.object, args.file)
+		case isArrow(args.file):
+			return exportArrow(ctx, db, args.object, args.file)
 		}
-		defer db.Close()
-		defer table.Close()
-		return runAbortable(table, flush)
-	case "gc":
-		db, err := openDatabase(args)
-		if err != nil {
-			return err
+		return exportCSV(ctx, db, args.object, args.file)
+	case "import":
+		switch {
+		case isParquet(args.file):
+			actions, err = importParquet(db, args.object, args.file)
+		case isArrow(args.file):
+			err = fmt.Errorf("arrow import is not supported")
+		default:
+			actions, err = importCSV(db, args.object, args.file)
 		}
-		defer db.Close()
-		return runAbortable(db, gc)
+	case "compact":
+		actions, err = compact(db, args.object)
+	case "reindex":
+		actions, err = reindex(db, args.object)
+	case "flush":
+		actions, err = flush(db, args.object)
+	case "checkpoint":
+		actions, err = checkpoint(db, args.object)
+	case "gc":
+		actions, err = gc(db, args.object)
 	default:
 		return fmt.Errorf("unsupported command %s", args.cmd)
 	}
-}
-
-func openTable(args Args) (knox.Table, error) {
-	// db, err := openDatabase(args)
-	// if err != nil {
-	// 	return nil, nil, err
-	// }
-	// opts := pack.DefaultOptions.
-	// 	WithEngine(pack.TableEnginePack).
-	// 	WithDriver("bolt").
-	// 	WithDriverOpts(args.bolt).
-	// 	WithReadOnly(true)
-
-	table, err := db.UseTable(args.table)
 	if err != nil {
-		return nil, nil, fmt.Errorf("opening table '%s': %v", args.table, err)
+		return err
+	}
+
+	err = runAbortable(actions)
+	if useJson {
+		if err := writeJson(os.Stdout, actions); err != nil {
+			return err
+		}
 	}
-	return table, nil
+	return err
 }
 
-func openDatabase(args Args) (knox.Database, error) {
-	opts := knox.ReadonlyDatabaseOptions
-	db, err := knox.OpenDatabase(
-		filepath.Dir(args.db),
-		strings.TrimSuffix(filepath.Base(args.db), ".db"),
-		opts,
+func openDatabase(ctx context.Context, args Args, readOnly bool) (knox.Database, error) {
+	opts := knox.NewDefaultOptions()
+	if readOnly {
+		opts = knox.NewReadOnlyOptions()
+	}
+	opts = append(opts,
+		knox.WithPath(args.dir),
+		knox.WithLogger(log.Log),
 	)
+	db, err := knox.OpenDatabase(ctx, args.db, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("opening database: %v", err)
+		return nil, fmt.Errorf("opening database %s: %v", args.db, err)
 	}
 	return db, nil
 }
 
-type Abortable func(context.Context, interface{}) error
+// Action is a single maintenance operation on a database object.
+type Action struct {
+	Command  string        `json:"command"`
+	Target   string        `json:"target,omitempty"`
+	DryRun   bool          `json:"dry_run"`
+	Done     bool          `json:"done"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+	fn       func(ctx context.Context) error
+}
 
-func runAbortable(data interface{}, fn Abortable) error {
+func NewAction(cmd, target string, fn func(context.Context) error) *Action {
+	return &Action{
+		Command: cmd,
+		Target:  target,
+		DryRun:  dryRun,
+		fn:      fn,
+	}
+}
+
+func (a *Action) String() string {
+	if a.Target == "" {
+		return a.Command
+	}
+	return a.Command + " " + a.Target
+}
+
+func (a *Action) Run(ctx context.Context) error {
+	if a.DryRun {
+		if !useJson {
+			fmt.Printf("would %s\n", a)
+		}
+		return nil
+	}
+	log.Infof("Running %s", a)
+	start := time.Now()
+	err := a.fn(ctx)
+	a.Duration = time.Since(start)
+	if err != nil {
+		a.Error = err.Error()
+		return fmt.Errorf("%s: %v", a, err)
+	}
+	a.Done = true
+	if !useJson {
+		fmt.Printf("%s done in %s\n", a, a.Duration)
+	}
+	return nil
+}
+
+// runAbortable executes actions in order until the first error or
+// until the user stops execution with Ctrl-C.
+func runAbortable(actions []*Action) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	go func() {
@@ -127,11 +191,27 @@ func runAbortable(data interface{}, fn Abortable) error {
 			cancel()
 		}
 	}()
-	log.Info("Stop with Ctrl-C")
+	if len(actions) == 0 {
+		if !useJson {
+			fmt.Println("nothing to do")
+		}
+		return nil
+	}
+	if !dryRun {
+		log.Info("Stop with Ctrl-C")
+	}
 	start := time.Now()
-	if err := fn(ctx, data); err != nil {
-		return err
+	for _, a := range actions {
+		if err := a.Run(ctx); err != nil {
+			return err
+		}
 	}
 	log.Infof("Done in %s", time.Since(start))
 	return nil
 }
+
+func writeJson(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
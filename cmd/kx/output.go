@@ -0,0 +1,226 @@
+// Copyright (c) 2018-2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/knox"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"blockwatch.cc/knoxdb/pkg/series"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// render writes a query result in the current output format. Values use
+// the display format of QueryResult so that decimals, timestamps, enums
+// and logical byte types read the same in every format. When names are
+// given only these result columns are written in the given order.
+func (s *Shell) render(res knox.QueryResult, names ...string) error {
+	fields := visibleFields(res.Schema())
+	if len(names) > 0 {
+		fields = pickFields(fields, names)
+	}
+	switch s.format {
+	case "csv":
+		w := csv.NewWriter(s.out)
+		rec := make([]string, len(fields))
+		for i, f := range fields {
+			rec[i] = f.name
+		}
+		w.Write(rec)
+		for row := range res.Len() {
+			for i, f := range fields {
+				if isNull(res, row, f.col) {
+					rec[i] = ""
+				} else {
+					rec[i] = res.Format(row, f.col)
+				}
+			}
+			w.Write(rec)
+		}
+		w.Flush()
+		return w.Error()
+
+	case "json":
+		w := bufio.NewWriter(s.out)
+		w.WriteByte('[')
+		for row := range res.Len() {
+			if row > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString("\n  {")
+			for i, f := range fields {
+				if i > 0 {
+					w.WriteByte(',')
+				}
+				w.WriteString(strconv.Quote(f.name))
+				w.WriteByte(':')
+				switch {
+				case isNull(res, row, f.col):
+					w.WriteString("null")
+				case f.numeric:
+					v := res.Format(row, f.col)
+					if x, err := strconv.ParseFloat(v, 64); err != nil || math.IsNaN(x) || math.IsInf(x, 0) {
+						v = strconv.Quote(v)
+					}
+					w.WriteString(v)
+				case f.raw:
+					w.WriteString(res.Format(row, f.col))
+				default:
+					v := res.Format(row, f.col)
+					if !f.doc || !json.Valid([]byte(v)) {
+						v = strconv.Quote(v)
+					}
+					w.WriteString(v)
+				}
+			}
+			w.WriteByte('}')
+		}
+		if res.Len() > 0 {
+			w.WriteByte('\n')
+		}
+		w.WriteString("]\n")
+		return w.Flush()
+
+	default:
+		t := table.NewWriter()
+		t.SetOutputMirror(s.out)
+		hdr := make(table.Row, len(fields))
+		cfg := make([]table.ColumnConfig, 0, len(fields))
+		for i, f := range fields {
+			hdr[i] = f.name
+			if f.numeric {
+				cfg = append(cfg, table.ColumnConfig{Number: i + 1, Align: text.AlignRight})
+			}
+		}
+		t.AppendHeader(hdr)
+		t.SetColumnConfigs(cfg)
+		for row := range res.Len() {
+			r := make(table.Row, len(fields))
+			for i, f := range fields {
+				r[i] = res.Format(row, f.col)
+			}
+			t.AppendRow(r)
+		}
+		t.Render()
+		fmt.Fprintf(s.out, "(%d rows)\n", res.Len())
+		return nil
+	}
+}
+
+func (s *Shell) renderCount(n int) error {
+	switch s.format {
+	case "csv":
+		fmt.Fprintf(s.out, "count\n%d\n", n)
+	case "json":
+		fmt.Fprintf(s.out, "[{\"count\":%d}]\n", n)
+	default:
+		t := table.NewWriter()
+		t.SetOutputMirror(s.out)
+		t.AppendHeader(table.Row{"count"})
+		t.AppendRow(table.Row{n})
+		t.Render()
+	}
+	return nil
+}
+
+// renderSeries writes time-series results. Series keep their own JSON
+// layout with one entry per group, table and CSV output use one row per
+// time bucket with an extra group column when results are grouped.
+func (s *Shell) renderSeries(res *series.Result) error {
+	switch s.format {
+	case "json":
+		buf, err := res.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		_, err = s.out.Write(append(buf, '\n'))
+		return err
+	case "csv":
+		return res.WriteCSV(s.out)
+	}
+
+	rs, err := res.Schema()
+	if err != nil {
+		return err
+	}
+	grouped := rs.NumFields() > len(res.Columns())
+	t := table.NewWriter()
+	t.SetOutputMirror(s.out)
+	hdr := make(table.Row, 0, rs.NumFields())
+	for _, f := range rs.Fields {
+		hdr = append(hdr, f.Name)
+	}
+	t.AppendHeader(hdr)
+	var n int
+	for r := range res.Rows() {
+		row := make(table.Row, 0, len(hdr))
+		if grouped {
+			row = append(row, r.Group)
+		}
+		for i := range r.Values {
+			row = append(row, r.Format(i))
+		}
+		t.AppendRow(row)
+		n++
+	}
+	t.Render()
+	fmt.Fprintf(s.out, "(%d rows)\n", n)
+	return nil
+}
+
+type outputField struct {
+	name    string
+	col     int
+	numeric bool // render as JSON number
+	raw     bool // render as JSON literal
+	doc     bool // render as JSON literal when valid
+}
+
+func visibleFields(s *schema.Schema) []outputField {
+	fields := make([]outputField, 0, s.NumFields())
+	for i, f := range s.Fields {
+		if !f.IsVisible() {
+			continue
+		}
+		of := outputField{name: f.Name, col: i}
+		switch f.Type {
+		case types.FieldTypeBoolean:
+			of.raw = true
+		case types.FieldTypeDocument:
+			of.doc = true
+		case types.FieldTypeString, types.FieldTypeBytes, types.FieldTypeTimestamp,
+			types.FieldTypeTime, types.FieldTypeDate, types.FieldTypeList:
+		default:
+			of.numeric = !f.IsEnum()
+		}
+		fields = append(fields, of)
+	}
+	return fields
+}
+
+func pickFields(fields []outputField, names []string) []outputField {
+	picked := make([]outputField, 0, len(names))
+	for _, n := range names {
+		for _, f := range fields {
+			if f.name == n {
+				picked = append(picked, f)
+				break
+			}
+		}
+	}
+	return picked
+}
+
+func isNull(res knox.QueryResult, row, col int) bool {
+	b := res.Pack().Block(col)
+	return b == nil || b.IsNull(row)
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2018-2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"blockwatch.cc/knoxdb/pkg/knox"
+	"blockwatch.cc/knoxdb/pkg/parquet"
+	"github.com/echa/log"
+)
+
+func isParquet(file string) bool {
+	return strings.EqualFold(filepath.Ext(file), ".parquet")
+}
+
+func isArrow(file string) bool {
+	return strings.EqualFold(filepath.Ext(file), ".arrow")
+}
+
+// importParquet bulk loads a Parquet file into a new table created from the
+// file schema in the import transaction.
+func importParquet(db knox.Database, name, file string) ([]*Action, error) {
+	if name == "" {
+		return nil, fmt.Errorf("missing table name")
+	}
+	action := NewAction("import", name, func(ctx context.Context) error {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		fi, err := f.Stat()
+		if err != nil {
+			return err
+		}
+
+		// imports must run in the transaction which creates the table
+		if _, err := db.FindTable(name); err == nil {
+			return fmt.Errorf("%s: %w", name, knox.ErrTableExists)
+		}
+		r, err := parquet.NewReader(f, fi.Size())
+		if err != nil {
+			return fmt.Errorf("reading %s: %v", file, err)
+		}
+		ctx, commit, abort, err := db.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer abort()
+		s := r.Schema().WithName(name)
+		table, err := db.CreateTable(ctx, s, knox.NewTableOptions()...)
+		if err != nil {
+			return err
+		}
+
+		_, n, err := table.ImportParquet(ctx, f, fi.Size())
+		if err != nil {
+			return err
+		}
+		if err := commit(); err != nil {
+			return err
+		}
+		log.Infof("Created table %s with %d fields", name, s.NumFields())
+		log.Infof("Imported %d records into %s", n, name)
+		return nil
+	})
+	return []*Action{action}, nil
+}
+
+// exportArrow writes all rows of a table as Arrow IPC stream to file or
+// stdout when file is empty.
+func exportArrow(ctx context.Context, db knox.Database, name, file string) error {
+	if name == "" {
+		return fmt.Errorf("missing table name")
+	}
+	table, err := db.FindTable(name)
+	if err != nil {
+		return err
+	}
+	var w io.Writer = os.Stdout
+	if file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return knox.NewQuery().WithTable(table).ExportArrow(ctx, w)
+}
+
+// exportParquet writes all rows of a table as Parquet file to file or
+// stdout when file is empty. Row groups hold up to 16k rows, the default
+// table pack size.
+func exportParquet(ctx context.Context, db knox.Database, name, file string) error {
+	if name == "" {
+		return fmt.Errorf("missing table name")
+	}
+	table, err := db.FindTable(name)
+	if err != nil {
+		return err
+	}
+	var w io.Writer = os.Stdout
+	if file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return knox.NewQuery().
+		WithTable(table).
+		ExportParquet(ctx, w, knox.DefaultParquetOptions())
+}
@@ -0,0 +1,448 @@
+// Copyright (c) 2018-2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Shell statements use a small SQL-like syntax:
+//
+//	select <fields|*|count(*)> from <table>
+//	    [join <table> on <field> = <field>]
+//	    [where <cond> [and <cond> ...]]
+//	    [order [by <field>] asc|desc]
+//	    [limit <n>]
+//
+//	series <reducer(field),...> from <table>
+//	    [range <range>] [interval <unit>] [fill <mode>]
+//	    [group by <field>] [limit <n>]
+//
+// Conditions are written as `field op value` with op one of = != < <= >
+// >= ~ (regexp), `field in a,b,c`, `field between a and b`, `field is
+// [not] null` or any filter mode name like `field pf 10.0.0.0/8`. The
+// knox `field.mode = value` form is accepted as well. In joins field
+// names may be prefixed with a table name to select the join side.
+
+var (
+	keywords = []string{
+		"select", "from", "join", "on", "where", "and", "in", "not",
+		"between", "is", "null", "order", "by", "asc", "desc", "limit",
+		"series", "range", "interval", "fill", "group",
+	}
+
+	opModes = map[string]string{
+		"=":  "eq",
+		"==": "eq",
+		"!=": "ne",
+		"<>": "ne",
+		"<":  "lt",
+		"<=": "le",
+		">":  "gt",
+		">=": "ge",
+		"~":  "re",
+	}
+)
+
+type token struct {
+	s      string
+	quoted bool
+}
+
+func (t token) is(kw string) bool {
+	return !t.quoted && strings.EqualFold(t.s, kw)
+}
+
+func isOpChar(c byte) bool {
+	return c == '=' || c == '!' || c == '<' || c == '>' || c == '~'
+}
+
+// tokenize splits a statement into words, quoted strings, operators
+// and commas.
+func tokenize(s string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == ';':
+			i++
+		case c == ',':
+			toks = append(toks, token{s: ","})
+			i++
+		case c == '\'' || c == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(s) && s[j] != c; j++ {
+				if s[j] == '\\' && j+1 < len(s) {
+					j++
+				}
+				b.WriteByte(s[j])
+			}
+			if j == len(s) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			toks = append(toks, token{s: b.String(), quoted: true})
+			i = j + 1
+		case isOpChar(c):
+			j := i + 1
+			for j < len(s) && isOpChar(s[j]) {
+				j++
+			}
+			toks = append(toks, token{s: s[i:j]})
+			i = j
+		default:
+			j := i + 1
+			for j < len(s) && !strings.ContainsRune(" \t;,'\"=!<>~", rune(s[j])) {
+				j++
+			}
+			toks = append(toks, token{s: s[i:j]})
+			i = j
+		}
+	}
+	return toks, nil
+}
+
+// Cond is a single filter condition in knox key/value form where key
+// is `field.mode` and value is the unparsed condition argument.
+type Cond struct {
+	Key   string
+	Value string
+}
+
+type SelectStmt struct {
+	Fields    []string
+	Table     string
+	JoinTable string
+	JoinOn    [2]string
+	Where     []Cond
+	OrderBy   string
+	Desc      bool
+	Limit     int
+	HasLimit  bool
+}
+
+func (s *SelectStmt) IsCount() bool {
+	return len(s.Fields) == 1 && strings.EqualFold(s.Fields[0], "count(*)")
+}
+
+type SeriesStmt struct {
+	Exprs    string
+	Table    string
+	Range    string
+	Interval string
+	Fill     string
+	GroupBy  string
+	Limit    int
+	HasLimit bool
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.toks)
+}
+
+func (p *parser) peek() token {
+	if p.done() {
+		return token{}
+	}
+	return p.toks[p.pos]
+}
+
+func (p *parser) next() (token, error) {
+	if p.done() {
+		return token{}, fmt.Errorf("unexpected end of statement")
+	}
+	t := p.toks[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *parser) accept(kw string) bool {
+	if p.peek().is(kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(kw string) error {
+	if p.accept(kw) {
+		return nil
+	}
+	if p.done() {
+		return fmt.Errorf("expected %q at end of statement", kw)
+	}
+	return fmt.Errorf("expected %q, found %q", kw, p.peek().s)
+}
+
+func (p *parser) isKeyword() bool {
+	t := p.peek()
+	for _, kw := range keywords {
+		if t.is(kw) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) word(what string) (string, error) {
+	t, err := p.next()
+	if err != nil {
+		return "", fmt.Errorf("missing %s", what)
+	}
+	if t.s == "," || (!t.quoted && isOpChar(t.s[0])) {
+		return "", fmt.Errorf("expected %s, found %q", what, t.s)
+	}
+	return t.s, nil
+}
+
+// list reads comma separated words until the next keyword.
+func (p *parser) list() []string {
+	var vals []string
+	for !p.done() && (p.peek().quoted || !p.isKeyword()) {
+		t := p.toks[p.pos]
+		p.pos++
+		if t.s == "," && !t.quoted {
+			continue
+		}
+		vals = append(vals, t.s)
+	}
+	return vals
+}
+
+func (p *parser) limit() (int, error) {
+	s, err := p.word("limit")
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid limit %q", s)
+	}
+	return n, nil
+}
+
+// ParseStatement parses a select or series statement.
+func ParseStatement(s string) (any, error) {
+	toks, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	switch {
+	case p.accept("select"):
+		return p.parseSelect()
+	case p.accept("series"):
+		return p.parseSeries()
+	case p.done():
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown statement %q", p.peek().s)
+	}
+}
+
+func (p *parser) parseSelect() (*SelectStmt, error) {
+	stmt := &SelectStmt{}
+	for _, f := range p.list() {
+		if f != "*" {
+			stmt.Fields = append(stmt.Fields, f)
+		}
+	}
+	var err error
+	if err = p.expect("from"); err != nil {
+		return nil, err
+	}
+	if stmt.Table, err = p.word("table name"); err != nil {
+		return nil, err
+	}
+	if p.accept("join") {
+		if stmt.JoinTable, err = p.word("join table name"); err != nil {
+			return nil, err
+		}
+		if err = p.expect("on"); err != nil {
+			return nil, err
+		}
+		if stmt.JoinOn[0], err = p.word("join field"); err != nil {
+			return nil, err
+		}
+		if t, _ := p.next(); t.s != "=" && t.s != "==" {
+			return nil, fmt.Errorf("only equal joins are supported")
+		}
+		if stmt.JoinOn[1], err = p.word("join field"); err != nil {
+			return nil, err
+		}
+	}
+	for !p.done() {
+		switch {
+		case p.accept("where"):
+			if stmt.Where, err = p.parseConds(); err != nil {
+				return nil, err
+			}
+		case p.accept("order"):
+			if p.accept("by") {
+				if stmt.OrderBy, err = p.word("order field"); err != nil {
+					return nil, err
+				}
+			}
+			switch {
+			case p.accept("asc"):
+			case p.accept("desc"):
+				stmt.Desc = true
+			default:
+				return nil, fmt.Errorf("expected asc or desc")
+			}
+		case p.accept("limit"):
+			if stmt.Limit, err = p.limit(); err != nil {
+				return nil, err
+			}
+			stmt.HasLimit = true
+		default:
+			return nil, fmt.Errorf("unexpected %q", p.peek().s)
+		}
+	}
+	return stmt, nil
+}
+
+func (p *parser) parseConds() ([]Cond, error) {
+	var conds []Cond
+	for {
+		c, err := p.parseCond()
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, c)
+		if !p.accept("and") {
+			return conds, nil
+		}
+	}
+}
+
+func (p *parser) parseCond() (Cond, error) {
+	var c Cond
+	name, err := p.word("condition field")
+	if err != nil {
+		return c, err
+	}
+	c.Key = name
+	op, err := p.next()
+	if err != nil {
+		return c, fmt.Errorf("missing operator after %q", name)
+	}
+	var mode string
+	switch {
+	case op.is("in"):
+		mode, c.Value = "in", strings.Join(p.trimList(p.list()), ",")
+	case op.is("not"):
+		if err := p.expect("in"); err != nil {
+			return c, err
+		}
+		mode, c.Value = "nin", strings.Join(p.trimList(p.list()), ",")
+	case op.is("is"):
+		mode = "nu"
+		if p.accept("not") {
+			mode = "nn"
+		}
+		if err := p.expect("null"); err != nil {
+			return c, err
+		}
+	case op.is("between"):
+		from, err := p.word("range start")
+		if err != nil {
+			return c, err
+		}
+		if err := p.expect("and"); err != nil {
+			return c, err
+		}
+		to, err := p.word("range end")
+		if err != nil {
+			return c, err
+		}
+		mode, c.Value = "rg", from+","+to
+	case op.s == "=" || op.s == "==":
+		// keep knox style field.mode keys intact
+		if c.Value, err = p.word("condition value"); err != nil {
+			return c, err
+		}
+		return c, nil
+	default:
+		var ok bool
+		if mode, ok = opModes[op.s]; !ok {
+			if op.quoted || isOpChar(op.s[0]) {
+				return c, fmt.Errorf("unknown operator %q", op.s)
+			}
+			// plain filter mode names like pf, cs, any, all
+			mode = strings.ToLower(op.s)
+		}
+		if mode == "in" || mode == "nin" || mode == "any" || mode == "all" {
+			c.Value = strings.Join(p.trimList(p.list()), ",")
+		} else if c.Value, err = p.word("condition value"); err != nil {
+			return c, err
+		}
+	}
+	c.Key += "." + mode
+	return c, nil
+}
+
+// trimList removes enclosing parentheses from a value list.
+func (p *parser) trimList(vals []string) []string {
+	if n := len(vals); n > 0 {
+		vals[0] = strings.TrimPrefix(vals[0], "(")
+		vals[n-1] = strings.TrimSuffix(vals[n-1], ")")
+		if vals[n-1] == "" {
+			vals = vals[:n-1]
+		}
+		if len(vals) > 0 && vals[0] == "" {
+			vals = vals[1:]
+		}
+	}
+	return vals
+}
+
+func (p *parser) parseSeries() (*SeriesStmt, error) {
+	stmt := &SeriesStmt{
+		Exprs: strings.Join(p.list(), ","),
+	}
+	if stmt.Exprs == "" {
+		return nil, fmt.Errorf("missing series expressions")
+	}
+	var err error
+	if err = p.expect("from"); err != nil {
+		return nil, err
+	}
+	if stmt.Table, err = p.word("table name"); err != nil {
+		return nil, err
+	}
+	for !p.done() {
+		switch {
+		case p.accept("range"):
+			// custom ranges are written as from,to
+			if stmt.Range = strings.Join(p.list(), ","); stmt.Range == "" {
+				err = fmt.Errorf("missing time range")
+			}
+		case p.accept("interval"):
+			stmt.Interval, err = p.word("interval")
+		case p.accept("fill"):
+			stmt.Fill, err = p.word("fill mode")
+		case p.accept("group"):
+			if err = p.expect("by"); err == nil {
+				stmt.GroupBy, err = p.word("group field")
+			}
+		case p.accept("limit"):
+			stmt.Limit, err = p.limit()
+			stmt.HasLimit = true
+		default:
+			err = fmt.Errorf("unexpected %q", p.peek().s)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return stmt, nil
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2018-2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSelect(t *testing.T) {
+	cases := []struct {
+		in   string
+		want *SelectStmt
+	}{
+		{
+			"select * from trade",
+			&SelectStmt{Table: "trade"},
+		},
+		{
+			"SELECT id, price FROM trade;",
+			&SelectStmt{Fields: []string{"id", "price"}, Table: "trade"},
+		},
+		{
+			"select count(*) from trade where market = 'eth'",
+			&SelectStmt{
+				Fields: []string{"count(*)"},
+				Table:  "trade",
+				Where:  []Cond{{"market", "eth"}},
+			},
+		},
+		{
+			"select * from trade where price >= 1.5 and market != btc and id in (1, 2,3)",
+			&SelectStmt{
+				Table: "trade",
+				Where: []Cond{{"price.ge", "1.5"}, {"market.ne", "btc"}, {"id.in", "1,2,3"}},
+			},
+		},
+		{
+			"select * from trade where id not in 1,2 and price between 1 and 2 and note is null and tag is not null",
+			&SelectStmt{
+				Table: "trade",
+				Where: []Cond{{"id.nin", "1,2"}, {"price.rg", "1,2"}, {"note.nu", ""}, {"tag.nn", ""}},
+			},
+		},
+		{
+			`select * from trade where market ~ "^e" and ip pf 10.0.0.0/8 and price.lt = 3`,
+			&SelectStmt{
+				Table: "trade",
+				Where: []Cond{{"market.re", "^e"}, {"ip.pf", "10.0.0.0/8"}, {"price.lt", "3"}},
+			},
+		},
+		{
+			"select * from trade where market = 'and' order by id desc limit 10",
+			&SelectStmt{
+				Table:    "trade",
+				Where:    []Cond{{"market", "and"}},
+				OrderBy:  "id",
+				Desc:     true,
+				Limit:    10,
+				HasLimit: true,
+			},
+		},
+		{
+			"select * from trade order asc limit 0",
+			&SelectStmt{Table: "trade", HasLimit: true},
+		},
+		{
+			"select trade.id, user.name from trade join user on trade.user = user.id where user.name = 'a'",
+			&SelectStmt{
+				Fields:    []string{"trade.id", "user.name"},
+				Table:     "trade",
+				JoinTable: "user",
+				JoinOn:    [2]string{"trade.user", "user.id"},
+				Where:     []Cond{{"user.name", "a"}},
+			},
+		},
+	}
+	for _, c := range cases {
+		stmt, err := ParseStatement(c.in)
+		require.NoError(t, err, c.in)
+		require.Equal(t, c.want, stmt, c.in)
+	}
+	stmt, _ := ParseStatement("select count(*) from trade")
+	require.True(t, stmt.(*SelectStmt).IsCount())
+}
+
+func TestParseSeries(t *testing.T) {
+	stmt, err := ParseStatement("series sum(price), count(id) from trade range 2025-01-01,2025-02-01 interval 1d fill last group by market limit 5")
+	require.NoError(t, err)
+	require.Equal(t, &SeriesStmt{
+		Exprs:    "sum(price),count(id)",
+		Table:    "trade",
+		Range:    "2025-01-01,2025-02-01",
+		Interval: "1d",
+		Fill:     "last",
+		GroupBy:  "market",
+		Limit:    5,
+		HasLimit: true,
+	}, stmt)
+
+	stmt, err = ParseStatement("series sum(price) from trade range last_week")
+	require.NoError(t, err)
+	require.Equal(t, &SeriesStmt{Exprs: "sum(price)", Table: "trade", Range: "last_week"}, stmt)
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, in := range []string{
+		"delete from trade",
+		"select * from",
+		"select * trade",
+		"select * from trade where",
+		"select * from trade where price",
+		"select * from trade where price >",
+		"select * from trade where price <=> 1",
+		"select * from trade where price between 1",
+		"select * from trade where note is empty",
+		"select * from trade where name = 'open",
+		"select * from trade order by id",
+		"select * from trade limit -1",
+		"select * from trade limit all",
+		"select * from trade group by id",
+		"select * from a join b on a.id < b.id",
+		"select * from a join b a.id = b.id",
+		"series from trade",
+		"series sum(price) from trade range",
+		"series sum(price) from trade group market",
+		"series sum(price) from trade order by id",
+	} {
+		_, err := ParseStatement(in)
+		require.Error(t, err, in)
+	}
+
+	// empty statements parse to nothing
+	stmt, err := ParseStatement(" ; ")
+	require.NoError(t, err)
+	require.Nil(t, stmt)
+}
@@ -0,0 +1,333 @@
+// Copyright (c) 2018-2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	keyCtrlA     = 1
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyEnter     = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyTab       = 9
+	keyEscape    = 27
+	keyBackspace = 127
+	keyCtrlH     = 8
+
+	maxHistory = 1000
+)
+
+// CompleteFunc returns completion candidates for the word that ends at
+// the cursor position. The prefix is the partial word being completed.
+type CompleteFunc func(line string, pos int) (prefix string, candidates []string)
+
+// LineReader reads lines from a terminal with basic editing, history and
+// tab-completion. When input is not a terminal lines are read as is and
+// no prompt is shown, which keeps piped scripts quiet.
+type LineReader struct {
+	in       *os.File
+	out      io.Writer
+	complete CompleteFunc
+	history  []string
+	scanner  *bufio.Scanner
+	rd       *bufio.Reader
+}
+
+func NewLineReader(in *os.File, out io.Writer, fn CompleteFunc) *LineReader {
+	r := &LineReader{
+		in:       in,
+		out:      out,
+		complete: fn,
+	}
+	if isTerminal(int(in.Fd())) {
+		r.rd = bufio.NewReader(in)
+	} else {
+		r.scanner = bufio.NewScanner(in)
+		r.scanner.Buffer(make([]byte, 0, 4096), 1<<20)
+	}
+	return r
+}
+
+func (r *LineReader) IsInteractive() bool {
+	return r.rd != nil
+}
+
+// ReadLine returns the next input line without trailing newline. It
+// returns io.EOF when input ends or the user presses Ctrl-D on an
+// empty line.
+func (r *LineReader) ReadLine(prompt string) (string, error) {
+	if r.scanner != nil {
+		if !r.scanner.Scan() {
+			if err := r.scanner.Err(); err != nil {
+				return "", err
+			}
+			return "", io.EOF
+		}
+		return r.scanner.Text(), nil
+	}
+
+	restore, err := makeRaw(int(r.in.Fd()))
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+
+	e := &lineEditor{
+		out:    r.out,
+		prompt: prompt,
+		hist:   len(r.history),
+	}
+	e.refresh()
+	line, err := r.edit(e)
+	fmt.Fprint(r.out, "\r\n")
+	if err != nil {
+		return "", err
+	}
+	if s := strings.TrimSpace(line); s != "" {
+		if n := len(r.history); n == 0 || r.history[n-1] != line {
+			r.history = append(r.history, line)
+		}
+		if len(r.history) > maxHistory {
+			r.history = r.history[1:]
+		}
+	}
+	return line, nil
+}
+
+func (r *LineReader) edit(e *lineEditor) (string, error) {
+	for {
+		c, _, err := r.rd.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch c {
+		case keyEnter, '\n':
+			return string(e.buf), nil
+		case keyCtrlC:
+			fmt.Fprint(r.out, "^C")
+			e.buf, e.pos = e.buf[:0], 0
+			return "", nil
+		case keyCtrlD:
+			if len(e.buf) == 0 {
+				return "", io.EOF
+			}
+			e.delete()
+		case keyBackspace, keyCtrlH:
+			e.backspace()
+		case keyCtrlA:
+			e.pos = 0
+		case keyCtrlE:
+			e.pos = len(e.buf)
+		case keyCtrlK:
+			e.buf = e.buf[:e.pos]
+		case keyCtrlU:
+			e.buf = slices.Delete(e.buf, 0, e.pos)
+			e.pos = 0
+		case keyCtrlW:
+			e.deleteWord()
+		case keyCtrlL:
+			fmt.Fprint(r.out, "\x1b[H\x1b[2J")
+		case keyCtrlP:
+			r.prev(e)
+		case keyCtrlN:
+			r.next(e)
+		case keyTab:
+			r.tab(e)
+		case keyEscape:
+			if err := r.escape(e); err != nil {
+				return "", err
+			}
+		default:
+			if c >= ' ' {
+				e.insert(c)
+			}
+		}
+		e.refresh()
+	}
+}
+
+// escape handles ANSI cursor key sequences.
+func (r *LineReader) escape(e *lineEditor) error {
+	c, _, err := r.rd.ReadRune()
+	if err != nil {
+		return err
+	}
+	if c != '[' && c != 'O' {
+		return nil
+	}
+	c, _, err = r.rd.ReadRune()
+	if err != nil {
+		return err
+	}
+	switch c {
+	case 'A':
+		r.prev(e)
+	case 'B':
+		r.next(e)
+	case 'C':
+		e.pos = min(e.pos+1, len(e.buf))
+	case 'D':
+		e.pos = max(e.pos-1, 0)
+	case 'H':
+		e.pos = 0
+	case 'F':
+		e.pos = len(e.buf)
+	case '1', '3', '4', '7', '8':
+		// extended sequences like ESC [ 3 ~ (delete)
+		t, _, err := r.rd.ReadRune()
+		if err != nil {
+			return err
+		}
+		if t != '~' {
+			return nil
+		}
+		switch c {
+		case '1', '7':
+			e.pos = 0
+		case '4', '8':
+			e.pos = len(e.buf)
+		case '3':
+			e.delete()
+		}
+	}
+	return nil
+}
+
+func (r *LineReader) prev(e *lineEditor) {
+	if e.hist == 0 {
+		return
+	}
+	if e.hist == len(r.history) {
+		e.saved = string(e.buf)
+	}
+	e.hist--
+	e.set(r.history[e.hist])
+}
+
+func (r *LineReader) next(e *lineEditor) {
+	if e.hist >= len(r.history) {
+		return
+	}
+	e.hist++
+	if e.hist == len(r.history) {
+		e.set(e.saved)
+	} else {
+		e.set(r.history[e.hist])
+	}
+}
+
+// tab completes the word at the cursor. A single candidate is inserted
+// in full, multiple candidates are extended to their common prefix or
+// listed below the prompt when there is nothing left to extend.
+func (r *LineReader) tab(e *lineEditor) {
+	if r.complete == nil {
+		return
+	}
+	prefix, cands := r.complete(string(e.buf), len(string(e.buf[:e.pos])))
+	switch len(cands) {
+	case 0:
+		return
+	case 1:
+		e.insertString(cands[0][len(prefix):] + " ")
+		return
+	}
+	// candidates match the prefix case-insensitively
+	if common := commonPrefix(cands); len(common) > len(prefix) {
+		e.insertString(common[len(prefix):])
+		return
+	}
+	fmt.Fprint(r.out, "\r\n"+strings.Join(cands, "  ")+"\r\n")
+}
+
+func commonPrefix(list []string) string {
+	p := list[0]
+	for _, s := range list[1:] {
+		for !strings.HasPrefix(s, p) {
+			_, n := utf8.DecodeLastRuneInString(p)
+			p = p[:len(p)-n]
+		}
+	}
+	return p
+}
+
+// lineEditor holds the state of the line being edited.
+type lineEditor struct {
+	out    io.Writer
+	prompt string
+	buf    []rune
+	pos    int
+	hist   int
+	saved  string
+}
+
+func (e *lineEditor) set(s string) {
+	e.buf = []rune(s)
+	e.pos = len(e.buf)
+}
+
+func (e *lineEditor) insert(c rune) {
+	e.buf = slices.Insert(e.buf, e.pos, c)
+	e.pos++
+}
+
+func (e *lineEditor) insertString(s string) {
+	rs := []rune(s)
+	e.buf = slices.Insert(e.buf, e.pos, rs...)
+	e.pos += len(rs)
+}
+
+func (e *lineEditor) backspace() {
+	if e.pos == 0 {
+		return
+	}
+	e.buf = slices.Delete(e.buf, e.pos-1, e.pos)
+	e.pos--
+}
+
+func (e *lineEditor) delete() {
+	if e.pos == len(e.buf) {
+		return
+	}
+	e.buf = slices.Delete(e.buf, e.pos, e.pos+1)
+}
+
+func (e *lineEditor) deleteWord() {
+	i := e.pos
+	for i > 0 && e.buf[i-1] == ' ' {
+		i--
+	}
+	for i > 0 && e.buf[i-1] != ' ' {
+		i--
+	}
+	e.buf = slices.Delete(e.buf, i, e.pos)
+	e.pos = i
+}
+
+// refresh redraws prompt and line and places the cursor.
+func (e *lineEditor) refresh() {
+	var b strings.Builder
+	b.WriteString("\r")
+	b.WriteString(e.prompt)
+	b.WriteString(string(e.buf))
+	b.WriteString("\x1b[K")
+	if n := len(e.buf) - e.pos; n > 0 {
+		fmt.Fprintf(&b, "\x1b[%dD", n)
+	}
+	io.WriteString(e.out, b.String())
+}
@@ -1,56 +1,46 @@
-// Copyright (c) 2018-2022 Blockwatch Data Inc.
+// Copyright (c) 2018-2025 Blockwatch Data Inc.
 // Author: alex@blockwatch.cc
-//go:build ignore
-// +build ignore
 
 package main
 
 import (
 	"context"
+	"fmt"
 
-	"github.com/echa/log"
-
-	"blockwatch.cc/knoxdb/pack"
+	"blockwatch.cc/knoxdb/pkg/knox"
 )
 
-// reindex drops and re-creates all indexes defined for a given table.
-func reindex(ctx context.Context, data interface{}) error {
-	table := data.(*pack.PackTable)
-
-	// make sure source table journals are flushed
-	log.Infof("Flushing source table %s", table.Name())
-	if err := table.Flush(ctx); err != nil {
-		return err
+// reindex rebuilds a single index, all indexes of a table or all indexes
+// in the database when name is empty.
+func reindex(db knox.Database, name string) ([]*Action, error) {
+	var names []string
+	switch {
+	case name == "":
+		for _, t := range db.ListTables() {
+			names = append(names, db.ListIndexes(t)...)
+		}
+	default:
+		if _, err := db.FindIndex(name); err == nil {
+			names = []string{name}
+			break
+		}
+		if _, err := db.FindTable(name); err != nil {
+			return nil, fmt.Errorf("no table or index %s", name)
+		}
+		names = db.ListIndexes(name)
 	}
-
-	// walk source table in packs and bulk-insert data into target
-	stats := table.Stats()
-	log.Infof("Rebuild indexes over %d rows / %d packs from table %s",
-		stats[0].TupleCount, stats[0].PacksCount, table.Name())
-
-	// rebuild indexes
-	for _, idx := range table.Indexes() {
-		log.Infof("Rebuilding %s index on field %s (%s)", idx.Name(), idx.Field().Name, idx.Field().Type)
-		prog := make(chan float64, 100)
-		go func() {
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				case f := <-prog:
-					log.Infof("Index build progress %.2f%%", f)
-					if f == 100 {
-						return
-					}
-				}
+	actions := make([]*Action, 0, len(names))
+	for _, n := range names {
+		actions = append(actions, NewAction("reindex", n, func(ctx context.Context) error {
+			if err := db.RebuildIndex(ctx, n); err != nil {
+				return err
 			}
-		}()
-		// flush every 128 packs
-		err := idx.Reindex(ctx, 128, prog)
-		close(prog)
-		if err != nil {
-			return err
-		}
+			idx, err := db.FindIndex(n)
+			if err != nil {
+				return err
+			}
+			return idx.Wait(ctx)
+		}))
 	}
-	return nil
+	return actions, nil
 }
@@ -0,0 +1,560 @@
+// Copyright (c) 2018-2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"blockwatch.cc/knoxdb/internal/reducer"
+	"blockwatch.cc/knoxdb/pkg/knox"
+	"blockwatch.cc/knoxdb/pkg/series"
+	"blockwatch.cc/knoxdb/pkg/util"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+const (
+	defaultShellLimit = 100
+	shellHelp         = `Statements:
+  select <fields|*|count(*)> from <table> [join <table> on <field> = <field>]
+      [where <cond> [and <cond> ...]] [order [by <pk>] asc|desc] [limit <n>]
+  series <reducer(field),...> from <table> [range <range>] [interval <unit>]
+      [fill <mode>] [group by <field>] [limit <n>]
+
+Conditions:
+  field = != < <= > >= ~ value, field in a,b,c, field between a and b,
+  field is [not] null, field <mode> value, field.<mode> = value
+
+Commands:
+  \d [table]                 list tables or describe a table
+  \format table|csv|json     set output format
+  \timing [on|off]           toggle query timing
+  \limit <n>                 default row limit when a query has none (0 = all)
+  \?                         show this help
+  \q                         quit
+`
+)
+
+var errQuit = errors.New("quit")
+
+// Shell executes statements and meta commands against a database and
+// renders results to out.
+type Shell struct {
+	db     knox.Database
+	out    io.Writer
+	format string
+	timing bool
+	limit  int
+}
+
+func NewShell(db knox.Database, out io.Writer) *Shell {
+	return &Shell{
+		db:     db,
+		out:    out,
+		format: "table",
+		limit:  defaultShellLimit,
+	}
+}
+
+// shell runs an interactive read-eval-print loop on stdin. Statements
+// are executed one line at a time and may be stopped with Ctrl-C.
+func shell(ctx context.Context, db knox.Database, args Args) error {
+	if args.object != "" {
+		return fmt.Errorf("shell does not accept object names")
+	}
+	sh := NewShell(db, os.Stdout)
+	if useJson {
+		sh.format = "json"
+	}
+	rd := NewLineReader(os.Stdin, os.Stdout, sh.Complete)
+	if rd.IsInteractive() {
+		fmt.Fprintf(os.Stdout, "Connected to %s (read-only). Type \\? for help.\n", args.db)
+	}
+	for {
+		line, err := rd.ReadLine("kx> ")
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		qctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		err = sh.Exec(qctx, line)
+		stop()
+		if err != nil {
+			if err == errQuit {
+				return nil
+			}
+			if !rd.IsInteractive() {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+	}
+}
+
+// Exec executes a single statement or meta command.
+func (s *Shell) Exec(ctx context.Context, line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "--") {
+		return nil
+	}
+	if line[0] == '\\' {
+		return s.meta(line)
+	}
+	stmt, err := ParseStatement(line)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	switch v := stmt.(type) {
+	case *SelectStmt:
+		if v.JoinTable != "" {
+			err = s.join(ctx, v)
+		} else {
+			err = s.query(ctx, v)
+		}
+	case *SeriesStmt:
+		err = s.series(ctx, v)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if s.timing {
+		fmt.Fprintf(os.Stderr, "Time: %s\n", time.Since(start))
+	}
+	return nil
+}
+
+func (s *Shell) meta(line string) error {
+	cmd, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+	switch cmd {
+	case `\q`, `\quit`:
+		return errQuit
+	case `\?`, `\h`, `\help`:
+		fmt.Fprint(s.out, shellHelp)
+	case `\d`, `\dt`:
+		if arg == "" {
+			return s.listTables()
+		}
+		return s.describe(arg)
+	case `\format`, `\f`:
+		switch arg {
+		case "table", "csv", "json":
+			s.format = arg
+		case "":
+			fmt.Fprintf(s.out, "Output format is %s.\n", s.format)
+		default:
+			return fmt.Errorf("unknown format %q", arg)
+		}
+	case `\timing`:
+		switch arg {
+		case "":
+			s.timing = !s.timing
+		case "on":
+			s.timing = true
+		case "off":
+			s.timing = false
+		default:
+			return fmt.Errorf("expected on or off")
+		}
+		fmt.Fprintf(s.out, "Timing is %s.\n", onOff(s.timing))
+	case `\limit`:
+		if arg == "" {
+			fmt.Fprintf(s.out, "Default limit is %d.\n", s.limit)
+			return nil
+		}
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid limit %q", arg)
+		}
+		s.limit = n
+	default:
+		return fmt.Errorf("unknown command %s, try \\?", cmd)
+	}
+	return nil
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+func (s *Shell) listTables() error {
+	l, err := list(s.db, "")
+	if err != nil {
+		return err
+	}
+	if s.format == "json" {
+		return writeJson(s.out, l.Tables)
+	}
+	t := table.NewWriter()
+	t.SetOutputMirror(s.out)
+	t.AppendHeader(table.Row{"Name", "Fields", "Tuples", "Indexes", "Size"})
+	for _, v := range l.Tables {
+		tab, err := s.db.FindTable(v.Name)
+		if err != nil {
+			return err
+		}
+		t.AppendRow(table.Row{
+			v.Name,
+			tab.Schema().NumVisible(),
+			util.PrettyInt64(v.TupleCount),
+			len(v.Indexes),
+			util.ByteSize(v.TotalSize),
+		})
+	}
+	t.Render()
+	return nil
+}
+
+// describe prints the schema and indexes of a table.
+func (s *Shell) describe(name string) error {
+	tab, err := s.db.FindTable(name)
+	if err != nil {
+		return err
+	}
+	indexed := make(map[string][]string)
+	for _, in := range s.db.ListIndexes(name) {
+		idx, err := s.db.FindIndex(in)
+		if err != nil {
+			return err
+		}
+		is := idx.IndexSchema()
+		for _, f := range is.Fields {
+			indexed[f.Name] = append(indexed[f.Name], in+" ("+is.Type.String()+")")
+		}
+	}
+	t := table.NewWriter()
+	t.SetOutputMirror(s.out)
+	t.SetTitle(name)
+	t.AppendHeader(table.Row{"Field", "Type", "Flags", "Compression", "Filter", "Indexes"})
+	for _, f := range tab.Schema().Fields {
+		if !f.IsVisible() {
+			continue
+		}
+		var filter, compress string
+		if f.Filter > 0 {
+			filter = f.Filter.String()
+		}
+		if f.Compress > 0 {
+			compress = f.Compress.String()
+		}
+		t.AppendRow(table.Row{
+			f.Name,
+			f.TypeName(),
+			f.Flags.String(),
+			compress,
+			filter,
+			strings.Join(indexed[f.Name], ", "),
+		})
+	}
+	t.Render()
+	return nil
+}
+
+// query runs a select statement on a single table.
+func (s *Shell) query(ctx context.Context, stmt *SelectStmt) error {
+	tab, err := s.db.FindTable(stmt.Table)
+	if err != nil {
+		return err
+	}
+	conds, err := parseConds(tab, stmt.Where)
+	if err != nil {
+		return err
+	}
+	q := knox.NewQuery().WithTable(tab).AndCondition(conds...)
+
+	if stmt.IsCount() {
+		n, err := q.Count(ctx)
+		if err != nil {
+			return err
+		}
+		return s.renderCount(n)
+	}
+
+	if stmt.OrderBy != "" && stmt.OrderBy != tab.Schema().Pk().Name {
+		return fmt.Errorf("results can only be ordered by primary key %q", tab.Schema().Pk().Name)
+	}
+	if stmt.Desc {
+		q = q.WithDesc()
+	}
+	if stmt.HasLimit {
+		q = q.WithLimit(stmt.Limit)
+	} else {
+		q = q.WithLimit(s.limit)
+	}
+	var fields []string
+	if len(stmt.Fields) > 0 {
+		fields = make([]string, len(stmt.Fields))
+		for i, f := range stmt.Fields {
+			fields[i] = strings.TrimPrefix(f, stmt.Table+".")
+		}
+		// results always contain the primary key, but we only
+		// render selected fields
+		if pk := tab.Schema().Pk().Name; !slices.Contains(fields, pk) {
+			q = q.WithFields(append(slices.Clone(fields), pk)...)
+		} else {
+			q = q.WithFields(fields...)
+		}
+	}
+	res, err := q.Run(ctx)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+	return s.render(res, fields...)
+}
+
+// join runs an inner join between two tables.
+func (s *Shell) join(ctx context.Context, stmt *SelectStmt) error {
+	if stmt.IsCount() || stmt.Desc || stmt.OrderBy != "" {
+		return fmt.Errorf("joins do not support count or order")
+	}
+	left, err := s.db.FindTable(stmt.Table)
+	if err != nil {
+		return err
+	}
+	right, err := s.db.FindTable(stmt.JoinTable)
+	if err != nil {
+		return err
+	}
+	tables := []knox.Table{left, right}
+
+	// assign select fields and conditions to join sides
+	var (
+		selects [2][]string
+		columns []string // result columns in select order
+	)
+	for _, f := range stmt.Fields {
+		side, name, err := joinSide(tables, f)
+		if err != nil {
+			return err
+		}
+		selects[side] = append(selects[side], name)
+		columns = append(columns, tables[side].Schema().Name+"."+name)
+	}
+	var (
+		where [2][]Cond
+		conds [2]knox.Condition
+	)
+	for _, c := range stmt.Where {
+		field, mode, _ := strings.Cut(c.Key, ".")
+		// keep table prefixes with the field name
+		if t, f, ok := strings.Cut(c.Key, "."); ok && (t == stmt.Table || t == stmt.JoinTable) {
+			field, mode, _ = strings.Cut(f, ".")
+			field = t + "." + field
+		}
+		side, name, err := joinSide(tables, field)
+		if err != nil {
+			return err
+		}
+		if mode != "" {
+			name += "." + mode
+		}
+		where[side] = append(where[side], Cond{Key: name, Value: c.Value})
+	}
+	for i := range 2 {
+		list, err := parseConds(tables[i], where[i])
+		if err != nil {
+			return err
+		}
+		if len(list) > 0 {
+			conds[i] = knox.And(list...)
+		}
+	}
+	_, lon, err := joinSide(tables[:1], stmt.JoinOn[0])
+	if err != nil {
+		return err
+	}
+	_, ron, err := joinSide(tables[1:], stmt.JoinOn[1])
+	if err != nil {
+		return err
+	}
+	// select all fields for *, join predicates must always be selected
+	var aliases [2][]string
+	for i, on := range []string{lon, ron} {
+		if len(stmt.Fields) == 0 {
+			selects[i] = tables[i].Schema().VisibleNames()
+		} else if !slices.Contains(selects[i], on) {
+			selects[i] = append(selects[i], on)
+		}
+		aliases[i] = make([]string, len(selects[i]))
+	}
+	limit := s.limit
+	if stmt.HasLimit {
+		limit = stmt.Limit
+	}
+	res, err := knox.NewJoin().
+		WithType(knox.InnerJoin).
+		WithTables(left, right).
+		WithOnEqual(lon, ron).
+		WithConditions(conds[0], conds[1]).
+		WithSelects(selects[0], selects[1]).
+		WithAliases(aliases[0], aliases[1]).
+		WithLimit(uint32(limit)).
+		Run(ctx)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+	return s.render(res, columns...)
+}
+
+// joinSide finds the table that contains field name. Names may carry
+// a table prefix, otherwise the first table containing the field wins.
+func joinSide(tables []knox.Table, name string) (int, string, error) {
+	if t, f, ok := strings.Cut(name, "."); ok {
+		for i, tab := range tables {
+			if tab.Schema().Name == t {
+				if _, ok := tab.Schema().Find(f); ok {
+					return i, f, nil
+				}
+				return 0, "", fmt.Errorf("unknown field %q in table %s", f, t)
+			}
+		}
+	}
+	for i, tab := range tables {
+		if _, ok := tab.Schema().Find(name); ok {
+			return i, name, nil
+		}
+	}
+	return 0, "", fmt.Errorf("unknown field %q", name)
+}
+
+func parseConds(tab knox.Table, list []Cond) ([]knox.Condition, error) {
+	conds := make([]knox.Condition, 0, len(list))
+	s := tab.Schema()
+	for _, c := range list {
+		key := strings.TrimPrefix(c.Key, s.Name+".")
+		cond, err := knox.ParseCondition(key, c.Value, s)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, cond)
+	}
+	return conds, nil
+}
+
+// series runs a time-series aggregation on a table.
+func (s *Shell) series(ctx context.Context, stmt *SeriesStmt) error {
+	tab, err := s.db.FindTable(stmt.Table)
+	if err != nil {
+		return err
+	}
+	req := series.NewRequest().WithTable(tab.Engine())
+	req.Table = stmt.Table
+	if err := req.Select.UnmarshalText([]byte(stmt.Exprs)); err != nil {
+		return err
+	}
+	if stmt.Range != "" {
+		rng, err := util.ParseTimeRange(stmt.Range)
+		if err != nil {
+			return err
+		}
+		req.WithRange(rng)
+	}
+	if stmt.Interval != "" {
+		u, err := util.ParseTimeUnit(stmt.Interval)
+		if err != nil {
+			return err
+		}
+		req.WithInterval(u)
+	}
+	if stmt.Fill != "" {
+		m := reducer.ParseFillMode(stmt.Fill)
+		if !m.IsValid() {
+			return fmt.Errorf("invalid fill mode %q", stmt.Fill)
+		}
+		req.WithFill(m)
+	}
+	if stmt.GroupBy != "" {
+		req.WithGroupBy(stmt.GroupBy)
+	}
+	if stmt.HasLimit {
+		req.WithLimit(stmt.Limit)
+	}
+
+	ctx, _, abort, err := s.db.Begin(ctx, knox.TxFlagReadOnly)
+	if err != nil {
+		return err
+	}
+	defer abort()
+	req.Sanitize()
+	res, err := req.Run(ctx, stmt.Table)
+	if err != nil {
+		return err
+	}
+	return s.renderSeries(res)
+}
+
+// Complete returns tab-completion candidates for the word ending at pos.
+// Meta commands complete at line start, table names after from, join
+// and \d and field names everywhere else, qualified by table prefix when
+// the word contains a dot.
+func (s *Shell) Complete(line string, pos int) (string, []string) {
+	head := line[:pos]
+	start := strings.LastIndexAny(head, " ,=<>!~(") + 1
+	prefix := head[start:]
+	words := strings.Fields(head[:start])
+
+	var cands []string
+	switch {
+	case len(words) == 0 && strings.HasPrefix(prefix, `\`):
+		cands = []string{`\d`, `\format`, `\timing`, `\limit`, `\q`, `\?`}
+	case len(words) > 0 && slices.Contains([]string{"from", "join", `\d`, `\dt`}, strings.ToLower(words[len(words)-1])):
+		cands = s.db.ListTables()
+	case len(words) > 0 && words[0] == `\format`:
+		cands = []string{"table", "csv", "json"}
+	case strings.Contains(prefix, "."):
+		t, _, _ := strings.Cut(prefix, ".")
+		if tab, err := s.db.FindTable(t); err == nil {
+			for _, n := range tab.Schema().VisibleNames() {
+				cands = append(cands, t+"."+n)
+			}
+		}
+	default:
+		cands = append(cands, keywords...)
+		cands = append(cands, s.db.ListTables()...)
+		for _, w := range words {
+			if tab, err := s.db.FindTable(w); err == nil {
+				cands = append(cands, tab.Schema().VisibleNames()...)
+			}
+		}
+		if len(words) > 0 && strings.EqualFold(words[0], "select") && !slices.ContainsFunc(words, isFrom) {
+			// fields before from, offer all tables
+			for _, n := range s.db.ListTables() {
+				if tab, err := s.db.FindTable(n); err == nil {
+					cands = append(cands, tab.Schema().VisibleNames()...)
+				}
+			}
+		}
+	}
+
+	var match []string
+	for _, c := range cands {
+		if strings.HasPrefix(strings.ToLower(c), strings.ToLower(prefix)) && !slices.Contains(match, c) {
+			match = append(match, c)
+		}
+	}
+	slices.Sort(match)
+	return prefix, match
+}
+
+func isFrom(s string) bool {
+	return strings.EqualFold(s, "from")
+}
@@ -0,0 +1,287 @@
+// Copyright (c) 2018-2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	tests "blockwatch.cc/knoxdb/internal/tests/engine"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/knox"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"github.com/stretchr/testify/require"
+)
+
+type shellTrade struct {
+	Id     uint64  `knox:"id,pk"`
+	Market string  `knox:"market,index=hash"`
+	Price  float64 `knox:"price"`
+	Buy    bool    `knox:"buy"`
+	User   uint64  `knox:"user"`
+}
+
+type shellUser struct {
+	Id   uint64 `knox:"id,pk"`
+	Name string `knox:"name"`
+}
+
+// newTestShell creates a database with trade and user tables and returns
+// a shell writing to buf.
+func newTestShell(t *testing.T) (*Shell, *bytes.Buffer) {
+	t.Helper()
+	ctx := context.Background()
+	eng, _ := tests.NewDatabase(t)
+	for _, typ := range []any{&shellTrade{}, &shellUser{}} {
+		s, err := schema.SchemaOf(typ)
+		require.NoError(t, err)
+		s = s.WithMeta()
+		_, err = eng.CreateTable(ctx, s, tests.NewTestTableOptions(t, "", "").TableOptions()...)
+		require.NoError(t, err)
+		for _, is := range s.Indexes {
+			if is.Type == types.IndexTypePk {
+				continue
+			}
+			_, err = eng.CreateIndex(ctx, is, tests.NewTestIndexOptions(t, "", "").IndexOptions()...)
+			require.NoError(t, err)
+		}
+	}
+	db := knox.WrapEngine(eng)
+	t.Cleanup(func() { db.Close(ctx) })
+
+	trades, err := db.FindTable("shell_trade")
+	require.NoError(t, err)
+	_, _, err = trades.Insert(ctx, []*shellTrade{
+		{Market: "eth", Price: 1.5, Buy: true, User: 1},
+		{Market: "btc", Price: 2.25, User: 2},
+		{Market: "eth", Price: 3, Buy: true, User: 2},
+	})
+	require.NoError(t, err)
+	users, err := db.FindTable("shell_user")
+	require.NoError(t, err)
+	_, _, err = users.Insert(ctx, []*shellUser{{Name: "alice"}, {Name: "bob"}})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	return NewShell(db, &buf), &buf
+}
+
+// exec runs a statement and returns its output.
+func exec(t *testing.T, sh *Shell, buf *bytes.Buffer, line string) string {
+	t.Helper()
+	buf.Reset()
+	require.NoError(t, sh.Exec(context.Background(), line), line)
+	return buf.String()
+}
+
+func TestShellRender(t *testing.T) {
+	sh, buf := newTestShell(t)
+	cases := []struct {
+		line   string
+		format string
+		want   string
+	}{
+		{"select * from shell_trade where market = eth", "table", `+----+--------+-------+------+------+
+| ID | MARKET | PRICE | BUY  | USER |
++----+--------+-------+------+------+
+|  1 | eth    |   1.5 | true |    1 |
+|  3 | eth    |     3 | true |    2 |
++----+--------+-------+------+------+
+(2 rows)
+`},
+		{"select * from shell_trade where market = eth", "csv", `id,market,price,buy,user
+1,eth,1.5,true,1
+3,eth,3,true,2
+`},
+		{"select * from shell_trade where market = eth", "json", `[
+  {"id":1,"market":"eth","price":1.5,"buy":true,"user":1},
+  {"id":3,"market":"eth","price":3,"buy":true,"user":2}
+]
+`},
+		{"select * from shell_trade where market = sol", "json", "[]\n"},
+		{"select count(*) from shell_trade where price > 2", "table", `+-------+
+| COUNT |
++-------+
+|     2 |
++-------+
+`},
+		{"select count(*) from shell_trade where price > 2", "csv", "count\n2\n"},
+		{"select count(*) from shell_trade where price > 2", "json", "[{\"count\":2}]\n"},
+
+		// selected fields without primary key
+		{"select price, buy from shell_trade order by id desc limit 2", "table", `+-------+-------+
+| PRICE | BUY   |
++-------+-------+
+|     3 | true  |
+|  2.25 | false |
++-------+-------+
+(2 rows)
+`},
+		{"select shell_trade.price, buy from shell_trade order desc limit 2", "csv", "price,buy\n3,true\n2.25,false\n"},
+		{"select price, buy from shell_trade order by id desc limit 2", "json", `[
+  {"price":3,"buy":true},
+  {"price":2.25,"buy":false}
+]
+`},
+
+		// joins render qualified names in select order
+		{"select shell_user.name, shell_trade.price from shell_trade join shell_user on user = id where shell_user.name = bob", "table", `+-----------------+-------------------+
+| SHELL_USER.NAME | SHELL_TRADE.PRICE |
++-----------------+-------------------+
+| bob             |              2.25 |
+| bob             |                 3 |
++-----------------+-------------------+
+(2 rows)
+`},
+		{"select shell_user.name, shell_trade.price from shell_trade join shell_user on user = id where shell_user.name = bob", "csv", `shell_user.name,shell_trade.price
+bob,2.25
+bob,3
+`},
+		{"select name, price from shell_trade join shell_user on user = id where name = bob", "json", `[
+  {"shell_user.name":"bob","shell_trade.price":2.25},
+  {"shell_user.name":"bob","shell_trade.price":3}
+]
+`},
+	}
+	for _, c := range cases {
+		require.NoError(t, sh.meta(`\format `+c.format))
+		require.Equal(t, c.want, exec(t, sh, buf, c.line), "%s %s", c.format, c.line)
+	}
+
+	// the default limit applies to queries without limit
+	sh.format = "csv"
+	require.NoError(t, sh.meta(`\limit 1`))
+	require.Equal(t, "id\n1\n", exec(t, sh, buf, "select id from shell_trade"))
+	require.Equal(t, "id\n1\n2\n", exec(t, sh, buf, "select id from shell_trade limit 2"))
+	require.NoError(t, sh.meta(`\limit 0`))
+	require.Equal(t, "id\n1\n2\n3\n", exec(t, sh, buf, "select id from shell_trade"))
+
+	// errors
+	for _, line := range []string{
+		"select * from missing",
+		"select nope from shell_trade",
+		"select * from shell_trade where nope = 1",
+		"select * from shell_trade order by price asc",
+		"select count(*) from shell_trade join shell_user on user = id",
+		"select * from shell_trade join shell_user on nope = id",
+		"select * from shell_trade x",
+	} {
+		require.Error(t, sh.Exec(context.Background(), line), line)
+	}
+}
+
+func TestShellDescribe(t *testing.T) {
+	sh, buf := newTestShell(t)
+
+	out := exec(t, sh, buf, `\d`)
+	require.Regexp(t, `(?m)^\| NAME +\| FIELDS \| TUPLES \| INDEXES \| +SIZE \|$`, out)
+	require.Regexp(t, `(?m)^\| shell_trade \| +5 \| 3 +\| +1 \|`, out)
+	require.Regexp(t, `(?m)^\| shell_user +\| +2 \| 2 +\| +0 \|`, out)
+
+	out = exec(t, sh, buf, `\d shell_trade`)
+	require.Regexp(t, `(?m)^\| shell_trade +\|$`, out)
+	require.Regexp(t, `(?m)^\| FIELD +\| TYPE +\| FLAGS +\| COMPRESSION \| FILTER \| INDEXES +\|$`, out)
+	require.Regexp(t, `(?m)^\| id +\| uint64 +\| primary \|`, out)
+	require.Regexp(t, `(?m)^\| market +\| string .*\| shell_trade_market_index \(hash\) \|$`, out)
+	require.Regexp(t, `(?m)^\| buy +\| boolean \|`, out)
+	require.NotContains(t, out, "$rid", "hidden meta fields")
+
+	// json listings use table metrics
+	sh.format = "json"
+	out = exec(t, sh, buf, `\dt`)
+	require.Contains(t, out, `"name": "shell_trade"`)
+	require.Contains(t, out, `"name": "shell_trade_market_index"`)
+	require.Contains(t, out, `"name": "shell_user"`)
+
+	require.Error(t, sh.Exec(context.Background(), `\d missing`))
+}
+
+func TestShellMeta(t *testing.T) {
+	sh, buf := newTestShell(t)
+
+	// timing toggles without argument
+	require.False(t, sh.timing)
+	require.Equal(t, "Timing is on.\n", exec(t, sh, buf, `\timing`))
+	require.True(t, sh.timing)
+	require.Equal(t, "Timing is off.\n", exec(t, sh, buf, `\timing`))
+	require.Equal(t, "Timing is on.\n", exec(t, sh, buf, `\timing on`))
+	require.Equal(t, "Timing is on.\n", exec(t, sh, buf, `\timing on`))
+	require.Equal(t, "Timing is off.\n", exec(t, sh, buf, `\timing off`))
+	require.False(t, sh.timing)
+	require.Error(t, sh.Exec(context.Background(), `\timing yes`))
+	require.False(t, sh.timing)
+
+	// output format
+	require.Equal(t, "Output format is table.\n", exec(t, sh, buf, `\format`))
+	exec(t, sh, buf, `\f csv`)
+	require.Equal(t, "csv", sh.format)
+	require.Error(t, sh.Exec(context.Background(), `\format xml`))
+	require.Equal(t, "csv", sh.format)
+
+	// default limit
+	require.Equal(t, "Default limit is 100.\n", exec(t, sh, buf, `\limit`))
+	exec(t, sh, buf, `\limit 5`)
+	require.Equal(t, 5, sh.limit)
+	require.Error(t, sh.Exec(context.Background(), `\limit -1`))
+	require.Error(t, sh.Exec(context.Background(), `\limit x`))
+	require.Equal(t, 5, sh.limit)
+
+	// help, comments, quit and unknown commands
+	require.Equal(t, shellHelp, exec(t, sh, buf, `\?`))
+	require.Empty(t, exec(t, sh, buf, "-- select * from missing"))
+	require.Empty(t, exec(t, sh, buf, "  "))
+	require.ErrorIs(t, sh.Exec(context.Background(), `\q`), errQuit)
+	require.ErrorIs(t, sh.Exec(context.Background(), `\quit`), errQuit)
+	require.Error(t, sh.Exec(context.Background(), `\x`))
+}
+
+func TestShellComplete(t *testing.T) {
+	sh, _ := newTestShell(t)
+	cases := []struct {
+		line   string
+		prefix string
+		want   []string
+	}{
+		// meta commands at line start
+		{`\`, `\`, []string{`\?`, `\d`, `\format`, `\limit`, `\q`, `\timing`}},
+		{`\ti`, `\ti`, []string{`\timing`}},
+		{`\format c`, "c", []string{"csv"}},
+
+		// table names after from, join and \d
+		{"select * from ", "", []string{"shell_trade", "shell_user"}},
+		{"select * from shell_t", "shell_t", []string{"shell_trade"}},
+		{"select * from shell_trade join shell_u", "shell_u", []string{"shell_user"}},
+		{`\d SHELL_`, "SHELL_", []string{"shell_trade", "shell_user"}},
+
+		// fields of tables named in the statement
+		{"select * from shell_trade where ma", "ma", []string{"market"}},
+		{"select * from shell_trade where price>=1 and b", "b", []string{"between", "buy", "by"}},
+		{"select * from shell_user where na", "na", []string{"name"}},
+
+		// fields of all tables before from
+		{"select id, pr", "pr", []string{"price"}},
+		{"select na", "na", []string{"name"}},
+
+		// qualified field names
+		{"select shell_trade.p", "shell_trade.p", []string{"shell_trade.price"}},
+		{"select * from shell_trade join shell_user on user = shell_user.", "shell_user.", []string{"shell_user.id", "shell_user.name"}},
+		{"select missing.", "missing.", nil},
+
+		// keywords
+		{"select * from shell_trade ord", "ord", []string{"order"}},
+		{"SEL", "SEL", []string{"select"}},
+	}
+	for _, c := range cases {
+		prefix, match := sh.Complete(c.line, len(c.line))
+		require.Equal(t, c.prefix, prefix, c.line)
+		require.Equal(t, c.want, match, c.line)
+	}
+
+	// completion uses the word at the cursor position
+	line := "select pr from shell_trade"
+	prefix, match := sh.Complete(line, len("select pr"))
+	require.Equal(t, "pr", prefix)
+	require.Equal(t, []string{"price"}, match)
+}
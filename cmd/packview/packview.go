@@ -7,7 +7,6 @@ package main
 
 import (
 	"context"
-	"encoding/hex"
 	"errors"
 	"flag"
 	"fmt"
@@ -395,6 +394,16 @@ func printValue(s *schema.Schema, f *schema.Field, val any) any {
 			}
 		}
 		return val
+	case types.FieldTypeUint32:
+		if f.Flags.Is(types.FieldFlagEnum) && s.HasEnums() {
+			if lut, ok := s.Enums.Load().Lookup(f.Name); ok {
+				enum, ok := lut.Value32(val.(uint32))
+				if ok {
+					return enum
+				}
+			}
+		}
+		return val
 	case types.FieldTypeTimestamp, types.FieldTypeDate, types.FieldTypeTime:
 		return schema.TimeScale(f.Scale).Format(val.(time.Time))
 	case types.FieldTypeInt128, types.FieldTypeInt256, types.FieldTypeDecimal128, types.FieldTypeDecimal256:
@@ -415,10 +424,11 @@ func PrintContent(ctx context.Context, view ContentViewer, desc TableDescriptor,
 	for _, field := range s.Fields {
 		switch field.Type {
 		case types.FieldTypeBytes:
+			l := field.Logical()
 			cfgs = append(cfgs, table.ColumnConfig{
 				Name: field.Name,
 				Transformer: func(val any) string {
-					return hex.EncodeToString(val.([]byte))
+					return l.Format(val.([]byte))
 				},
 			})
 		case types.FieldTypeUint16:
@@ -436,6 +446,21 @@ func PrintContent(ctx context.Context, view ContentViewer, desc TableDescriptor,
 					})
 				}
 			}
+		case types.FieldTypeUint32:
+			if field.Flags.Is(types.FieldFlagEnum) && s.HasEnums() {
+				if lut, ok := s.Enums.Load().Lookup(field.Name); ok {
+					cfgs = append(cfgs, table.ColumnConfig{
+						Name: field.Name,
+						Transformer: func(val any) string {
+							enum, ok := lut.Value32(val.(uint32))
+							if ok {
+								return enum
+							}
+							return strconv.FormatUint(uint64(val.(uint32)), 10)
+						},
+					})
+				}
+			}
 		case types.FieldTypeTimestamp, types.FieldTypeDate, types.FieldTypeTime:
 			cfgs = append(cfgs, table.ColumnConfig{
 				Name: field.Name,
@@ -554,6 +554,9 @@ func (s *Bitset) Append(val bool) int {
 	s.Grow(1)
 	if val {
 		s.setbit(s.size - 1)
+		if s.cnt >= 0 {
+			s.cnt++
+		}
 	}
 	return s.size - 1
 }
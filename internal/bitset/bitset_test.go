@@ -219,6 +219,17 @@ func TestBitsetSet(t *testing.T) {
 	}
 }
 
+func TestBitsetAppendValue(t *testing.T) {
+	bits := New(0)
+	assert.Equal(t, 0, bits.Count(), "count")
+	for i, v := range []bool{true, false, true, true} {
+		assert.Equal(t, i, bits.Append(v), "pos")
+	}
+	assert.Equal(t, 4, bits.Len(), "len")
+	assert.Equal(t, 3, bits.Count(), "count")
+	assert.False(t, bits.Contains(1), "isset(1)")
+}
+
 func TestBitsetSetRange(t *testing.T) {
 	for _, sz := range bitsetSizes {
 		t.Run(f("%d", sz), func(t *testing.T) {
@@ -39,6 +39,15 @@ func NewAccessor[T types.Number](b *Block) Accessor[T] {
 	}
 }
 
+// GetAccessor returns the typed accessor of a materialized or compressed
+// numeric block. T must match the block type.
+func GetAccessor[T types.Number](b *Block) types.NumberAccessor[T] {
+	if b.IsMaterialized() {
+		return NewAccessor[T](b)
+	}
+	return b.any.(types.NumberAccessor[T])
+}
+
 func GetMatcher[T types.Number](b *Block) types.NumberMatcher[T] {
 	return b.any.(types.NumberMatcher[T])
 }
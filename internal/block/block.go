@@ -32,21 +32,24 @@ type page *byte
 type BlockType = types.BlockType
 
 const (
-	BlockInvalid = types.BlockInvalid
-	BlockInt64   = types.BlockInt64
-	BlockInt32   = types.BlockInt32
-	BlockInt16   = types.BlockInt16
-	BlockInt8    = types.BlockInt8
-	BlockUint64  = types.BlockUint64
-	BlockUint32  = types.BlockUint32
-	BlockUint16  = types.BlockUint16
-	BlockUint8   = types.BlockUint8
-	BlockFloat64 = types.BlockFloat64
-	BlockFloat32 = types.BlockFloat32
-	BlockBool    = types.BlockBool
-	BlockBytes   = types.BlockBytes
-	BlockInt128  = types.BlockInt128
-	BlockInt256  = types.BlockInt256
+	BlockInvalid  = types.BlockInvalid
+	BlockInt64    = types.BlockInt64
+	BlockInt32    = types.BlockInt32
+	BlockInt16    = types.BlockInt16
+	BlockInt8     = types.BlockInt8
+	BlockUint64   = types.BlockUint64
+	BlockUint32   = types.BlockUint32
+	BlockUint16   = types.BlockUint16
+	BlockUint8    = types.BlockUint8
+	BlockFloat64  = types.BlockFloat64
+	BlockFloat32  = types.BlockFloat32
+	BlockBool     = types.BlockBool
+	BlockBytes    = types.BlockBytes
+	BlockInt128   = types.BlockInt128
+	BlockInt256   = types.BlockInt256
+	BlockBigint   = types.BlockBigint
+	BlockList     = types.BlockList
+	BlockDocument = types.BlockDocument
 )
 
 // Challenge
@@ -81,17 +84,19 @@ const (
 // `IntegerDictionary[int64]`).
 
 type Block struct {
-	nref     atomic.Int64 // ref counter, nocopy, 64-bit aligned
-	buf      *byte        // backing store for raw numeric types ([0:n:n] n = sz*cap)
-	_        *page        // buffer page reference (to release page lock on close)
-	any      any          // interface to embedded vector container
-	len      uint32       // in type units
-	cap      uint32       // in type units
-	sz       byte         // type size
-	typ      BlockType    // type
-	dirty    bool         // flags
-	writable bool         // flags
-	// _     [21]byte     // pad to 64 bytes
+	nref     atomic.Int64   // ref counter, nocopy, 64-bit aligned
+	buf      *byte          // backing store for raw numeric types ([0:n:n] n = sz*cap)
+	_        *page          // buffer page reference (to release page lock on close)
+	any      any            // interface to embedded vector container
+	valid    *bitset.Bitset // optional validity bitmap (nil = no nulls)
+	len      uint32         // in type units
+	cap      uint32         // in type units
+	sz       byte           // type size
+	typ      BlockType      // type
+	elem     BlockType      // list element type
+	dirty    bool           // flags
+	writable bool           // flags
+	// _     [12]byte       // pad to 64 bytes
 }
 
 func New(typ BlockType, sz int) *Block {
@@ -110,7 +115,7 @@ func New(typ BlockType, sz int) *Block {
 		b.any = num.NewInt256Stride(sz)
 	case BlockBool:
 		b.any = bitset.New(sz).Resize(0)
-	case BlockBytes:
+	case BlockBytes, BlockBigint, BlockList, BlockDocument:
 		b.any = stringx.NewStringPool(sz)
 	default:
 		b.buf = unsafe.SliceData(arena.AllocBytes(sz * int(b.sz)))
@@ -138,7 +143,7 @@ func (b *Block) free() {
 		b.Int256().Close()
 	case BlockBool:
 		b.Bool().Close()
-	case BlockBytes:
+	case BlockBytes, BlockBigint, BlockList, BlockDocument:
 		b.Bytes().Close()
 	default:
 		if b.IsMaterialized() {
@@ -147,12 +152,17 @@ func (b *Block) free() {
 			b.any.(Closer).Close()
 		}
 	}
+	if b.valid != nil {
+		b.valid.Close()
+		b.valid = nil
+	}
 	b.dirty = false
 	b.writable = false
 	b.any = nil
 	b.buf = nil
 	b.nref.Store(0)
 	b.typ = 0
+	b.elem = 0
 	b.len = 0
 	b.cap = 0
 	b.sz = 0
@@ -214,7 +224,7 @@ func (b *Block) Len() int {
 	switch b.typ {
 	case BlockBool:
 		return b.Bool().Len()
-	case BlockBytes:
+	case BlockBytes, BlockBigint, BlockList, BlockDocument:
 		return b.Bytes().Len()
 	case BlockInt128:
 		return b.Int128().Len()
@@ -233,7 +243,7 @@ func (b *Block) Cap() int {
 	switch b.typ {
 	case BlockBool:
 		return b.Bool().Cap()
-	case BlockBytes:
+	case BlockBytes, BlockBigint, BlockList, BlockDocument:
 		return b.Bytes().Cap()
 	case BlockInt128:
 		return b.Int128().Cap()
@@ -250,7 +260,7 @@ func (b *Block) Size() int {
 	switch b.typ {
 	case BlockBool:
 		sz += b.Bool().Size()
-	case BlockBytes:
+	case BlockBytes, BlockBigint, BlockList, BlockDocument:
 		sz += b.Bytes().Size()
 	case BlockInt128:
 		sz += b.Int128().Size()
@@ -269,6 +279,7 @@ func (b *Block) Clone(sz int) *Block {
 		sz = int(b.cap)
 	}
 	c := New(b.typ, sz)
+	c.elem = b.elem
 	switch b.typ {
 	case BlockInt64:
 		b.Int64().AppendTo(c.Int64().Slice(), nil)
@@ -290,7 +301,7 @@ func (b *Block) Clone(sz int) *Block {
 		b.Float64().AppendTo(c.Float64().Slice(), nil)
 	case BlockFloat32:
 		b.Float32().AppendTo(c.Float32().Slice(), nil)
-	case BlockBytes:
+	case BlockBytes, BlockBigint, BlockList, BlockDocument:
 		b.Bytes().AppendTo(c.Bytes(), nil)
 	case BlockBool:
 		b.Bool().AppendTo(c.Bool().Writer(), nil)
@@ -300,6 +311,9 @@ func (b *Block) Clone(sz int) *Block {
 		b.Int256().AppendTo(c.Int256(), nil)
 	}
 	c.len = uint32(b.Len())
+	if b.HasNulls() {
+		c.valid = b.valid.Clone()
+	}
 	c.SetDirty()
 	return c
 }
@@ -312,7 +326,7 @@ func (b *Block) Delete(i, j int) {
 	assert.Always(i >= 0 && j >= 0 && b.Len() >= i && b.Len() >= j,
 		"delete: out of bounds", "dst.len", b.Len(), "i", i, "j", j)
 	switch b.typ {
-	case BlockBytes:
+	case BlockBytes, BlockBigint, BlockList, BlockDocument:
 		b.Bytes().Delete(i, j)
 	case BlockBool:
 		b.Bool().Delete(i, j)
@@ -324,6 +338,9 @@ func (b *Block) Delete(i, j int) {
 		_ = slices.Delete(b.buffer(), i*int(b.sz), j*int(b.sz))
 		b.len -= uint32(j - i)
 	}
+	if b.valid != nil && i < b.valid.Len() {
+		b.valid.Delete(i, j)
+	}
 	b.SetDirty()
 }
 
@@ -332,7 +349,7 @@ func (b *Block) Clear() {
 	assert.Always(b != nil, "clear: nil block, potential use after free")
 	assert.Always(b.IsMaterialized(), "clear: block not materialized")
 	switch b.typ {
-	case BlockBytes:
+	case BlockBytes, BlockBigint, BlockList, BlockDocument:
 		b.Bytes().Clear()
 	case BlockBool:
 		b.Bool().Clear()
@@ -343,6 +360,10 @@ func (b *Block) Clear() {
 	default:
 		b.len = 0
 	}
+	if b.valid != nil {
+		b.valid.Close()
+		b.valid = nil
+	}
 	b.SetDirty()
 }
 
@@ -364,8 +385,9 @@ func (b *Block) AppendRange(src *Block, i, j int) {
 	if b.len+n > b.cap || i > j || j > int(src.len) {
 		panic(ErrBlockOutOfBounds)
 	}
+	b.appendNulls(src, b.Len(), i, j, nil)
 	switch b.typ {
-	case BlockBytes:
+	case BlockBytes, BlockBigint, BlockList, BlockDocument:
 		switch {
 		case n == 1:
 			// single value
@@ -489,6 +511,7 @@ func (b *Block) AppendTo(dst *Block, sel []uint32) {
 	assert.Always(dst != nil, "appendTo: nil dst block, potential use after free")
 	assert.Always(dst.IsMaterialized(), "appendTo: dst block not materialized")
 	assert.Always(dst.Cap()-dst.Len() >= n, "appendTo: dst free capacity smaller than selection")
+	dst.appendNulls(b, dst.Len(), 0, n, sel)
 	switch b.typ {
 	case BlockInt64:
 		b.Int64().AppendTo(dst.Int64().Slice(), sel)
@@ -510,7 +533,7 @@ func (b *Block) AppendTo(dst *Block, sel []uint32) {
 		b.Float64().AppendTo(dst.Float64().Slice(), sel)
 	case BlockFloat32:
 		b.Float32().AppendTo(dst.Float32().Slice(), sel)
-	case BlockBytes:
+	case BlockBytes, BlockBigint, BlockList, BlockDocument:
 		b.Bytes().AppendTo(dst.Bytes(), sel)
 	case BlockBool:
 		b.Bool().AppendTo(dst.Bool().Writer(), sel)
@@ -549,6 +572,12 @@ func (b *Block) Append(val any) {
 		b.Bool().Append(val.(bool))
 	case types.BlockBytes:
 		b.Bytes().Append(val.([]byte))
+	case types.BlockBigint:
+		b.Bytes().Append(bigBytes(val))
+	case types.BlockList:
+		b.Bytes().Append(listBytes(val))
+	case types.BlockDocument:
+		b.Bytes().Append(docBytes(val))
 	case types.BlockInt128:
 		b.Int128().Append(val.(num.Int128))
 	case types.BlockInt256:
@@ -581,7 +610,7 @@ func (b *Block) Get(row int) any {
 		return b.Float32().Get(row)
 	case types.BlockBool:
 		return b.Bool().Get(row)
-	case types.BlockBytes:
+	case types.BlockBytes, types.BlockBigint, types.BlockList, types.BlockDocument:
 		return b.Bytes().Get(row)
 	case types.BlockInt128:
 		return b.Int128().Get(row)
@@ -622,6 +651,12 @@ func (b *Block) Set(row int, val any) {
 		}
 	case types.BlockBytes:
 		b.Bytes().Set(row, val.([]byte))
+	case types.BlockBigint:
+		b.Bytes().Set(row, bigBytes(val))
+	case types.BlockList:
+		b.Bytes().Set(row, listBytes(val))
+	case types.BlockDocument:
+		b.Bytes().Set(row, docBytes(val))
 	case types.BlockInt128:
 		b.Int128().Set(row, val.(num.Int128))
 	case types.BlockInt256:
@@ -630,7 +665,11 @@ func (b *Block) Set(row int, val any) {
 	b.SetDirty()
 }
 
+// MinMax returns min and max values across all non-null values in the block.
 func (b *Block) MinMax() (any, any) {
+	if b.HasNulls() {
+		return b.minMaxValid()
+	}
 	switch b.typ {
 	case BlockInt64:
 		return util.MinMax(b.Int64().Slice()...)
@@ -659,6 +698,16 @@ func (b *Block) MinMax() (any, any) {
 	case BlockBytes:
 		minv, maxv := b.Bytes().MinMax()
 		return bytes.Clone(minv), bytes.Clone(maxv) // clone
+	case BlockBigint:
+		// bigints use numeric order which differs from byte order
+		minv, maxv := minMaxValid(b, b.Bytes().Get, num.BigCmpBytes)
+		return bytes.Clone(minv), bytes.Clone(maxv) // clone
+	case BlockList:
+		// element min/max across all lists
+		return b.listMinMax()
+	case BlockDocument:
+		// documents have no order
+		return []byte{}, []byte{}
 	case BlockBool:
 		switch {
 		case b.Bool().All():
@@ -701,6 +750,9 @@ func (b *Block) Min() any {
 		return util.Min(b.Float32().Slice()...)
 	case BlockBytes:
 		return bytes.Clone(b.Bytes().Min())
+	case BlockBigint, BlockList, BlockDocument:
+		minv, _ := b.MinMax()
+		return minv
 	case BlockBool:
 		return b.Bool().All()
 	default:
@@ -736,9 +788,20 @@ func (b *Block) Max() any {
 		return util.Max(b.Float32().Slice()...)
 	case BlockBytes:
 		return bytes.Clone(b.Bytes().Max())
+	case BlockBigint, BlockList, BlockDocument:
+		_, maxv := b.MinMax()
+		return maxv
 	case BlockBool:
 		return b.Bool().Any()
 	default:
 		return nil
 	}
 }
+
+// bigBytes returns the binary wire format of a bigint block value.
+func bigBytes(val any) []byte {
+	if v, ok := val.(num.Big); ok {
+		return v.Bytes()
+	}
+	return val.([]byte)
+}
@@ -4,9 +4,13 @@
 package block
 
 import (
+	"bytes"
+	"fmt"
 	"math"
 	"testing"
 
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/num"
 	"blockwatch.cc/knoxdb/pkg/util"
 	"github.com/stretchr/testify/require"
 )
@@ -179,3 +183,335 @@ func BenchmarkSet(b *testing.B) {
 		block.Int64().Set(0, math.MaxInt64)
 	}
 }
+
+func TestBlockNulls(t *testing.T) {
+	block := New(BlockInt64, 8)
+	defer block.Deref()
+	for i := range 8 {
+		block.Int64().Append(int64(i) - 4)
+	}
+	require.False(t, block.HasNulls())
+	require.Nil(t, block.Valid())
+
+	// min/max ignore nulls
+	block.SetNull(0)
+	block.SetNull(7)
+	require.True(t, block.HasNulls())
+	require.True(t, block.IsNull(0))
+	require.False(t, block.IsNull(1))
+	minv, maxv := block.MinMax()
+	require.Equal(t, int64(-3), minv)
+	require.Equal(t, int64(2), maxv)
+
+	// clone and delete keep nulls in sync
+	clone := block.Clone(8)
+	defer clone.Deref()
+	require.True(t, clone.IsNull(7))
+	clone.Delete(0, 2)
+	require.Equal(t, 6, clone.Len())
+	require.False(t, clone.IsNull(0))
+	require.True(t, clone.IsNull(5))
+
+	// append range copies nulls
+	dst := New(BlockInt64, 8)
+	defer dst.Deref()
+	dst.AppendRange(block, 6, 8)
+	require.False(t, dst.IsNull(0))
+	require.True(t, dst.IsNull(1))
+
+	// encode/decode roundtrip
+	buf, _, err := block.Encode(0)
+	require.NoError(t, err)
+	dec, err := Decode(BlockInt64, buf)
+	require.NoError(t, err)
+	defer dec.Deref()
+	require.Equal(t, 8, dec.Len())
+	require.True(t, dec.HasNulls())
+	for i := range 8 {
+		require.Equal(t, block.IsNull(i), dec.IsNull(i), "row %d", i)
+		require.Equal(t, block.Int64().Get(i), dec.Int64().Get(i), "row %d", i)
+	}
+
+	// set valid clears null
+	block.SetValid(0)
+	block.SetValid(7)
+	require.False(t, block.HasNulls())
+}
+
+func TestBlockBigint(t *testing.T) {
+	vals := []int64{256, 255, 1 << 40, 0, 1}
+	block := New(BlockBigint, len(vals))
+	defer block.Deref()
+	for _, v := range vals {
+		block.Append(num.NewBig(v))
+	}
+	require.Equal(t, len(vals), block.Len())
+	require.Equal(t, num.NewBig(1<<40).Bytes(), block.Get(2))
+
+	// min/max and sort use numeric order (255 < 256)
+	minv, maxv := block.MinMax()
+	require.Equal(t, 0, num.BigCmpBytes(nil, minv.([]byte)))
+	require.Equal(t, num.NewBig(1<<40).Bytes(), maxv)
+	require.Greater(t, block.Cmp(0, 1), 0)
+	require.Less(t, block.Cmp(1, 2), 0)
+
+	// encode/decode roundtrip
+	buf, _, err := block.Encode(0)
+	require.NoError(t, err)
+	dec, err := Decode(BlockBigint, buf)
+	require.NoError(t, err)
+	defer dec.Deref()
+	require.Equal(t, len(vals), dec.Len())
+	for i, v := range vals {
+		require.Equal(t, num.NewBig(v).Bytes(), dec.Get(i), "row %d", i)
+	}
+	minv, maxv = dec.MinMax()
+	require.Equal(t, 0, num.BigCmpBytes(nil, minv.([]byte)))
+	require.Equal(t, num.NewBig(1<<40).Bytes(), maxv)
+
+	// negative bigints keep their sign and order
+	block.Append(num.NewBig(-1))
+	block.Set(0, num.NewBig(-256))
+	require.Equal(t, len(vals)+1, block.Len())
+	require.Equal(t, num.NewBig(-256).Bytes(), block.Get(0))
+	require.Equal(t, num.NewBig(-1).Bytes(), block.Get(len(vals)))
+	minv, maxv = block.MinMax()
+	require.Equal(t, num.NewBig(-256).Bytes(), minv)
+	require.Equal(t, num.NewBig(1<<40).Bytes(), maxv)
+	require.Less(t, block.Cmp(0, len(vals)), 0)
+	require.Less(t, block.Cmp(len(vals), 3), 0)
+}
+
+func TestBlockList(t *testing.T) {
+	vals := [][]int32{{3, -1, 7}, {}, {42}, {5, 5}}
+	block := NewList(BlockInt32, len(vals))
+	defer block.Deref()
+	for _, v := range vals {
+		block.Append(v)
+	}
+	block.SetNull(1)
+	require.Equal(t, len(vals), block.Len())
+	require.Equal(t, BlockInt32, block.Elem())
+	require.Equal(t, util.ToByteSlice(vals[2]), block.Get(2))
+
+	// min/max use elements
+	minv, maxv := block.MinMax()
+	require.Equal(t, int32(-1), minv)
+	require.Equal(t, int32(42), maxv)
+
+	// clone keeps element type
+	c := block.Clone(len(vals))
+	require.Equal(t, BlockInt32, c.Elem())
+	c.Deref()
+
+	// encode/decode roundtrip
+	buf, ctx, err := block.Encode(0)
+	require.NoError(t, err)
+	require.Equal(t, 5, ctx.Unique())
+	ctx.Close()
+	dec, err := Decode(BlockList, buf)
+	require.NoError(t, err)
+	defer dec.Deref()
+	require.Equal(t, len(vals), dec.Len())
+	require.Equal(t, BlockInt32, dec.Elem())
+	require.True(t, dec.IsNull(1))
+	for i, v := range vals {
+		require.Equal(t, util.ToByteSlice(v), dec.Get(i), "row %d", i)
+	}
+
+	// lists without elements
+	empty := NewList(BlockUint64, 2)
+	defer empty.Deref()
+	empty.Append([]uint64{})
+	empty.Append([]uint64{})
+	buf, ctx, err = empty.Encode(0)
+	require.NoError(t, err)
+	ctx.Close()
+	dec2, err := Decode(BlockList, buf)
+	require.NoError(t, err)
+	defer dec2.Deref()
+	require.Equal(t, 2, dec2.Len())
+	require.Empty(t, dec2.Get(1))
+}
+
+func TestBlockDocument(t *testing.T) {
+	docs := []string{
+		`{"amount":1,"to":"a","x":{"ok":true}}`,
+		`{"amount":2.5,"to":"b"}`,
+		`{"other":[1,2]}`,
+		``,
+		`{"amount":4,"to":7}`,
+	}
+	block := New(BlockDocument, len(docs))
+	defer block.Deref()
+	for _, v := range docs {
+		block.Append([]byte(v))
+	}
+	require.Equal(t, len(docs), block.Len())
+	require.Nil(t, block.DocPath("amount"), "materialized blocks are not shredded")
+
+	// documents have no order
+	minv, maxv := block.MinMax()
+	require.Equal(t, []byte{}, minv)
+	require.Equal(t, []byte{}, maxv)
+
+	// encode/decode roundtrip
+	buf, ctx, err := block.Encode(0)
+	require.NoError(t, err)
+	minv, maxv = ctx.MinMax()
+	require.Equal(t, []byte{}, minv)
+	require.Equal(t, []byte{}, maxv)
+	ctx.Close()
+	dec, err := Decode(BlockDocument, buf)
+	require.NoError(t, err)
+	defer dec.Deref()
+	require.Equal(t, len(docs), dec.Len())
+	for i, v := range docs {
+		require.Equal(t, []byte(v), dec.Get(i), "row %d", i)
+	}
+
+	// frequent paths with a single type are shredded
+	require.Equal(t, []string{"amount"}, dec.DocPaths())
+	amount := dec.DocPath("amount")
+	require.NotNil(t, amount)
+	require.Equal(t, BlockFloat64, amount.Type())
+	require.Equal(t, len(docs), amount.Len())
+	require.Equal(t, 2.5, amount.Get(1))
+	require.Equal(t, float64(4), amount.Get(4))
+	require.True(t, amount.IsNull(2))
+	require.True(t, amount.IsNull(3))
+	require.Nil(t, dec.DocPath("to"), "mixed types are not shredded")
+	require.Nil(t, dec.DocPath("x.ok"), "rare paths are not shredded")
+}
+
+func TestBlockCompression(t *testing.T) {
+	block := New(BlockBytes, 1024)
+	defer block.Deref()
+	for i := range 1024 {
+		block.Bytes().Append([]byte(fmt.Sprintf("account-%04d/%s", i%37, "compressible payload")))
+	}
+
+	for _, c := range []types.BlockCompression{
+		types.BlockCompressNone,
+		types.BlockCompressSnappy,
+		types.BlockCompressLZ4,
+		types.BlockCompressLZ4.WithLevel(9),
+		types.BlockCompressZstd,
+		types.BlockCompressZstd.WithLevel(1),
+		types.BlockCompressZstd.WithLevel(19),
+		types.BlockCompressAuto,
+		types.BlockCompressAuto.WithLevel(types.CompressPolicySpeed),
+		types.BlockCompressAuto.WithLevel(types.CompressPolicyRatio),
+	} {
+		t.Run(c.String(), func(t *testing.T) {
+			buf, _, err := block.Encode(c)
+			require.NoError(t, err)
+
+			// header records the concrete codec only
+			hdr := types.BlockCompression(buf[0])
+			require.Equal(t, 0, hdr.Level())
+			require.NotEqual(t, types.BlockCompressAuto, hdr)
+			if c.Codec() != types.BlockCompressAuto {
+				require.Equal(t, c.Codec(), hdr)
+			}
+
+			dec, err := Decode(BlockBytes, buf)
+			require.NoError(t, err)
+			defer dec.Deref()
+			require.Equal(t, block.Len(), dec.Len())
+			for i := range block.Len() {
+				require.Equal(t, block.Bytes().Get(i), dec.Bytes().Get(i), "row %d", i)
+			}
+		})
+	}
+}
+
+func TestSelectCompression(t *testing.T) {
+	rnd := make([]byte, 64<<10)
+	for i := range rnd {
+		rnd[i] = byte(util.RandUint64())
+	}
+	txt := bytes.Repeat([]byte("the quick brown fox jumps over the lazy dog "), 1500)
+
+	auto := types.BlockCompressAuto
+	speed := auto.WithLevel(types.CompressPolicySpeed)
+	ratio := auto.WithLevel(types.CompressPolicyRatio)
+
+	// incompressible data stays uncompressed
+	require.Equal(t, types.BlockCompressNone, SelectCompression(rnd, auto))
+	require.Equal(t, types.BlockCompressNone, SelectCompression(rnd, ratio))
+
+	// compressible data picks a codec from the policy
+	require.Equal(t, types.BlockCompressLZ4, SelectCompression(txt, speed))
+	require.NotEqual(t, types.BlockCompressNone, SelectCompression(txt, auto))
+	require.NotEqual(t, types.BlockCompressNone, SelectCompression(txt, ratio))
+
+	// explicit codecs are kept
+	zstd19 := types.BlockCompressZstd.WithLevel(19)
+	require.Equal(t, zstd19, SelectCompression(rnd, zstd19))
+}
+
+func TestBlockDict(t *testing.T) {
+	// small blocks with similar content
+	newBlock := func(seed int) *Block {
+		b := New(BlockBytes, 64)
+		for i := range 64 {
+			b.Bytes().Append([]byte(fmt.Sprintf("tz1-%06d/transfer/%d", seed*64+i, i%5)))
+		}
+		return b
+	}
+
+	// sample uncompressed block data
+	var samples [][]byte
+	for i := range 64 {
+		b := newBlock(i)
+		buf, _, err := b.Encode(types.BlockCompressZstd)
+		require.NoError(t, err)
+		raw, err := Uncompress(buf, nil)
+		require.NoError(t, err)
+		samples = append(samples, raw)
+		b.Deref()
+	}
+	_, err := TrainDict(DictId(1, 1), samples[:DictMinSamples-1], 4096)
+	require.ErrorIs(t, err, ErrDictSamples)
+	d, err := TrainDict(DictId(1, 1), samples, 4096)
+	require.NoError(t, err)
+	require.Equal(t, DictId(1, 1), d.Id())
+
+	// reload from serialized form
+	d2, err := NewDict(bytes.Clone(d.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, d.Id(), d2.Id())
+	dicts := NewDictSet()
+	defer dicts.Close()
+	dicts.Add(d)
+	dicts.Add(d2) // duplicate is closed
+	require.Equal(t, 1, dicts.Len())
+	require.Equal(t, d, dicts.Latest(1))
+	require.Nil(t, dicts.Latest(2))
+
+	block := newBlock(100)
+	defer block.Deref()
+	zbuf, _, err := block.Encode(types.BlockCompressZstd)
+	require.NoError(t, err)
+	dbuf, _, err := block.EncodeWithDict(types.BlockCompressDict, d)
+	require.NoError(t, err)
+	require.Equal(t, types.BlockCompressDict, types.BlockCompression(dbuf[0]))
+	require.Less(t, len(dbuf), len(zbuf))
+
+	// decode requires the dictionary
+	_, err = Decode(BlockBytes, dbuf)
+	require.ErrorIs(t, err, ErrDictNotFound)
+	dec, err := DecodeWithDicts(BlockBytes, dbuf, dicts)
+	require.NoError(t, err)
+	defer dec.Deref()
+	require.Equal(t, block.Len(), dec.Len())
+	for i := range block.Len() {
+		require.Equal(t, block.Bytes().Get(i), dec.Bytes().Get(i), "row %d", i)
+	}
+
+	// without dictionary fall back to zstd
+	fbuf, _, err := block.EncodeWithDict(types.BlockCompressDict, nil)
+	require.NoError(t, err)
+	require.Equal(t, types.BlockCompressZstd, types.BlockCompression(fbuf[0]))
+}
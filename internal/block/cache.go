@@ -3,7 +3,10 @@
 
 package block
 
-import "blockwatch.cc/knoxdb/pkg/cache"
+import (
+	"blockwatch.cc/knoxdb/pkg/cache"
+	"blockwatch.cc/knoxdb/pkg/cache/disk"
+)
 
 type (
 	BlockCache struct {
@@ -18,3 +21,34 @@ func NewCache(sz int) BlockCache {
 }
 
 var NoCache BlockCachePartition = NewCache(0).Partition(0)
+
+// DiskCachePartition is a partition of the persistent second cache tier.
+// Blocks are stored without outer compression so they load without
+// dictionaries and decompression. A nil partition disables the tier.
+type DiskCachePartition struct {
+	*disk.Partition
+}
+
+// GetBlock loads the block stored under key. Undecodable entries are
+// removed and reported as miss.
+func (p *DiskCachePartition) GetBlock(key uint64, typ BlockType) (*Block, bool) {
+	buf, ok := p.Get(key)
+	if !ok {
+		return nil, false
+	}
+	b, err := Decode(typ, buf)
+	if err != nil {
+		p.Remove(key)
+		return nil, false
+	}
+	return b, true
+}
+
+// AddBlock stores encoded block data buf as read from storage under key.
+func (p *DiskCachePartition) AddBlock(key uint64, buf []byte, dicts *DictSet) error {
+	buf, err := Decompress(buf, dicts)
+	if err != nil {
+		return err
+	}
+	return p.Add(key, buf)
+}
@@ -4,9 +4,11 @@
 package block
 
 import (
+	"bytes"
 	"io"
 	"runtime"
 
+	"blockwatch.cc/knoxdb/internal/arena"
 	"blockwatch.cc/knoxdb/internal/types"
 	"blockwatch.cc/knoxdb/pkg/util"
 	"github.com/klauspost/compress/s2"
@@ -31,28 +33,39 @@ var (
 		runtime.NumCPU(),
 		func() any { return lz4.NewReader(nil) },
 	)
-	zstdWriterPool = util.NewGenericPool(
+	zstdWriterPools = [...]*util.GenericPool{
+		zstd.SpeedFastest:           newZstdWriterPool(zstd.SpeedFastest),
+		zstd.SpeedDefault:           newZstdWriterPool(zstd.SpeedDefault),
+		zstd.SpeedBetterCompression: newZstdWriterPool(zstd.SpeedBetterCompression),
+		zstd.SpeedBestCompression:   newZstdWriterPool(zstd.SpeedBestCompression),
+	}
+	zstdReaderPool = util.NewGenericPool(
+		runtime.NumCPU(),
+		func() any {
+			r, _ := zstd.NewReader(nil)
+			return r
+		},
+	)
+)
+
+func newZstdWriterPool(l zstd.EncoderLevel) *util.GenericPool {
+	return util.NewGenericPool(
 		runtime.NumCPU(),
 		func() any {
 			w, _ := zstd.NewWriter(nil,
 				zstd.WithEncoderConcurrency(1),
 				zstd.WithEncoderCRC(true),
-				zstd.WithEncoderLevel(zstd.SpeedDefault), // SpeedFastest
+				zstd.WithEncoderLevel(l),
 			)
 			return w
 		},
 	)
-	zstdReaderPool = util.NewGenericPool(
-		runtime.NumCPU(),
-		func() any {
-			r, _ := zstd.NewReader(nil)
-			return r
-		},
-	)
-)
+}
 
+// NewCompressor returns a pooled compressor for codec and level in c. Level 0
+// selects the codec default (lz4 fast mode, zstd default speed).
 func NewCompressor(w io.Writer, c types.BlockCompression) io.WriteCloser {
-	switch c {
+	switch c.Codec() {
 	case types.BlockCompressSnappy:
 		enc := snappyWriterPool.Get().(*s2.Writer)
 		enc.Reset(w)
@@ -60,18 +73,24 @@ func NewCompressor(w io.Writer, c types.BlockCompression) io.WriteCloser {
 	case types.BlockCompressLZ4:
 		enc := lz4WriterPool.Get().(*lz4.Writer)
 		enc.Reset(w)
+		enc.Header.CompressionLevel = c.Level() // reset clears the header
 		return &pooledWriteCloser{pool: lz4WriterPool, w: enc}
 	case types.BlockCompressZstd:
-		enc := zstdWriterPool.Get().(*zstd.Encoder)
+		l := zstd.SpeedDefault
+		if c.Level() > 0 {
+			l = zstd.EncoderLevelFromZstd(c.Level())
+		}
+		pool := zstdWriterPools[l]
+		enc := pool.Get().(*zstd.Encoder)
 		enc.Reset(w)
-		return &pooledWriteCloser{pool: zstdWriterPool, w: enc}
+		return &pooledWriteCloser{pool: pool, w: enc}
 	default:
 		return nopWriteCloser{w}
 	}
 }
 
 func NewDecompressor(r io.Reader, c types.BlockCompression) io.ReadCloser {
-	switch c {
+	switch c.Codec() {
 	case types.BlockCompressSnappy:
 		dec := snappyReaderPool.Get().(*s2.Reader)
 		dec.Reset(r)
@@ -79,16 +98,115 @@ func NewDecompressor(r io.Reader, c types.BlockCompression) io.ReadCloser {
 	case types.BlockCompressLZ4:
 		dec := lz4ReaderPool.Get().(*lz4.Reader)
 		dec.Reset(r)
-		return &pooledReadCloser{pool: lz4WriterPool, r: dec}
+		return &pooledReadCloser{pool: lz4ReaderPool, r: dec}
 	case types.BlockCompressZstd:
 		dec := zstdReaderPool.Get().(*zstd.Decoder)
 		dec.Reset(r)
-		return &pooledReadCloser{pool: zstdWriterPool, r: dec}
+		return &pooledReadCloser{pool: zstdReaderPool, r: dec}
 	default:
 		return io.NopCloser(r)
 	}
 }
 
+// CompressPolicy controls adaptive codec selection. Candidates are ordered
+// from fastest to slowest. A slower candidate replaces the current choice
+// only when it shrinks the trial sample by at least MinGain (relative to
+// uncompressed size for the first candidate) or StepGain (relative to the
+// current choice for subsequent candidates).
+type CompressPolicy struct {
+	SampleSize int                      // max bytes to trial compress
+	MinGain    float64                  // min saving to compress at all
+	StepGain   float64                  // min saving to pick a slower codec
+	Candidates []types.BlockCompression // codecs ordered by speed
+}
+
+// CompressPolicies holds the configurable policies for auto compression
+// indexed by policy id. Changes only affect blocks written afterwards.
+var CompressPolicies = [...]CompressPolicy{
+	types.CompressPolicyBalanced: {
+		SampleSize: 16 << 10,
+		MinGain:    0.1,
+		StepGain:   0.15,
+		Candidates: []types.BlockCompression{
+			types.BlockCompressLZ4,
+			types.BlockCompressZstd,
+		},
+	},
+	types.CompressPolicySpeed: {
+		SampleSize: 16 << 10,
+		MinGain:    0.1,
+		Candidates: []types.BlockCompression{
+			types.BlockCompressLZ4,
+		},
+	},
+	types.CompressPolicyRatio: {
+		SampleSize: 16 << 10,
+		MinGain:    0.02,
+		StepGain:   0.02,
+		Candidates: []types.BlockCompression{
+			types.BlockCompressLZ4,
+			types.BlockCompressZstd.WithLevel(19),
+		},
+	},
+}
+
+// SelectCompression resolves auto compression into a concrete codec by
+// trial compressing a sample of buf under the policy selected by c.
+// Other codecs are returned unchanged.
+func SelectCompression(buf []byte, c types.BlockCompression) types.BlockCompression {
+	if c.Codec() != types.BlockCompressAuto {
+		return c
+	}
+	p := c.Level()
+	if p >= len(CompressPolicies) {
+		p = types.CompressPolicyBalanced
+	}
+	policy := &CompressPolicies[p]
+	sample := compressSample(buf, policy.SampleSize)
+	if len(sample) == 0 {
+		return types.BlockCompressNone
+	}
+
+	var (
+		best  = types.BlockCompressNone
+		size  = len(sample)
+		gain  = policy.MinGain
+		trial = bytes.NewBuffer(arena.AllocBytes(len(sample)))
+	)
+	for _, cand := range policy.Candidates {
+		trial.Reset()
+		enc := NewCompressor(trial, cand)
+		_, err := enc.Write(sample)
+		if err == nil {
+			err = enc.Close()
+		}
+		if err != nil {
+			continue
+		}
+		if float64(size-trial.Len()) >= gain*float64(size) {
+			best, size, gain = cand, trial.Len(), policy.StepGain
+		}
+	}
+	arena.Free(trial.Bytes())
+	return best
+}
+
+// compressSample returns buf when it is small or joins 4 evenly spaced
+// chunks into a sample of at most n bytes otherwise.
+func compressSample(buf []byte, n int) []byte {
+	if n <= 0 || len(buf) <= n {
+		return buf
+	}
+	const chunks = 4
+	sz := n / chunks
+	stride := (len(buf) - sz) / (chunks - 1)
+	sample := make([]byte, 0, n)
+	for i := range chunks {
+		sample = append(sample, buf[i*stride:i*stride+sz]...)
+	}
+	return sample
+}
+
 type pooledWriteCloser struct {
 	pool *util.GenericPool
 	w    io.WriteCloser
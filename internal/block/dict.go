@@ -0,0 +1,181 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package block
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	ErrDictNotFound = errors.New("block: compression dictionary not found")
+	ErrDictSamples  = errors.New("block: not enough samples for dictionary")
+)
+
+const (
+	DictMinSamples = 8       // min number of sample blocks to train a dictionary
+	DictMaxSize    = 1 << 17 // max dictionary history size
+)
+
+// DictId combines field id and dictionary version into a unique dictionary
+// id. Versions start at 1 since zstd reserves id 0.
+func DictId(fid, version uint16) uint32 {
+	return uint32(fid)<<16 | uint32(version)
+}
+
+// Dict is an immutable zstd dictionary trained from encoded blocks of a single
+// table column. Blocks compressed with a dictionary store its id so that
+// retrained dictionaries can coexist with older versions.
+type Dict struct {
+	id  uint32
+	buf []byte
+	enc *zstd.Encoder
+	dec *zstd.Decoder
+}
+
+// NewDict loads a serialized zstd dictionary.
+func NewDict(buf []byte) (*Dict, error) {
+	info, err := zstd.InspectDictionary(buf)
+	if err != nil {
+		return nil, fmt.Errorf("block: loading dictionary: %v", err)
+	}
+	enc, err := zstd.NewWriter(nil,
+		zstd.WithEncoderCRC(true),
+		zstd.WithEncoderLevel(zstd.SpeedBetterCompression),
+		zstd.WithEncoderDict(buf),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("block: loading dictionary: %v", err)
+	}
+	dec, err := zstd.NewReader(nil, zstd.WithDecoderDicts(buf))
+	if err != nil {
+		enc.Close()
+		return nil, fmt.Errorf("block: loading dictionary: %v", err)
+	}
+	return &Dict{
+		id:  info.ID(),
+		buf: buf,
+		enc: enc,
+		dec: dec,
+	}, nil
+}
+
+// TrainDict builds a dictionary of at most size bytes from encoded block
+// samples. History content is taken evenly from the head of each sample
+// where encoding headers and common values repeat most.
+func TrainDict(id uint32, samples [][]byte, size int) (*Dict, error) {
+	if len(samples) < DictMinSamples {
+		return nil, ErrDictSamples
+	}
+	size = min(size, DictMaxSize)
+	per := max(size/len(samples), 64)
+	hist := make([]byte, 0, size)
+	for _, s := range samples {
+		n := min(len(s), per, size-len(hist))
+		hist = append(hist, s[:n]...)
+		if len(hist) == size {
+			break
+		}
+	}
+	buf, err := zstd.BuildDict(zstd.BuildDictOptions{
+		ID:       id,
+		Contents: samples,
+		History:  hist,
+		Offsets:  [3]int{1, 4, 8},
+		Level:    zstd.SpeedBetterCompression,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("block: training dictionary: %v", err)
+	}
+	return NewDict(buf)
+}
+
+func (d *Dict) Id() uint32 {
+	return d.id
+}
+
+func (d *Dict) Bytes() []byte {
+	return d.buf
+}
+
+func (d *Dict) Close() {
+	d.enc.Close()
+	d.dec.Close()
+}
+
+// DictSet holds the compression dictionaries of a table. Readers look up
+// dictionaries by id while writers always use the latest version per field.
+// A nil DictSet is valid and empty.
+type DictSet struct {
+	mu     sync.RWMutex
+	byId   map[uint32]*Dict
+	latest map[uint16]*Dict
+}
+
+func NewDictSet() *DictSet {
+	return &DictSet{
+		byId:   make(map[uint32]*Dict),
+		latest: make(map[uint16]*Dict),
+	}
+}
+
+func (s *DictSet) Get(id uint32) *Dict {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.byId[id]
+}
+
+func (s *DictSet) Latest(fid uint16) *Dict {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.latest[fid]
+}
+
+// Add registers dictionary d and makes it the latest version for its
+// field unless a newer version exists. The set takes ownership of d.
+// Duplicate ids keep the existing dictionary which may be in use.
+func (s *DictSet) Add(d *Dict) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.byId[d.id]; ok {
+		d.Close()
+		return
+	}
+	s.byId[d.id] = d
+	fid := uint16(d.id >> 16)
+	if l, ok := s.latest[fid]; !ok || l.id <= d.id {
+		s.latest[fid] = d
+	}
+}
+
+func (s *DictSet) Len() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.byId)
+}
+
+func (s *DictSet) Close() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range s.byId {
+		d.Close()
+	}
+	clear(s.byId)
+	clear(s.latest)
+}
@@ -0,0 +1,313 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package block
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"blockwatch.cc/knoxdb/internal/arena"
+	"blockwatch.cc/knoxdb/internal/encode"
+	"blockwatch.cc/knoxdb/internal/types"
+)
+
+// Document blocks keep compact JSON documents in a string pool. On disk
+// frequent scalar paths are shredded into typed child vectors so that path
+// filters can match vectors instead of parsing documents. Documents are
+// stored in full, shredded vectors are a read-only projection.
+//
+// Format: [0 hdr][uvarint n]{[uvarint len][path][type][uvarint len][child]}*n[documents]
+//
+// Child vectors use the regular block encoding with validity bitmaps for
+// rows where a path is missing. Numbers are shredded into int64 vectors
+// when all values are integers and into float64 vectors otherwise.
+
+const (
+	maxDocPaths    = 32 // max shredded paths per block
+	minDocPathFill = 2  // shred paths present in at least 1/minDocPathFill rows
+)
+
+// docVector is the decoded container of document blocks. It embeds the
+// document string container and owns shredded path vectors.
+type docVector struct {
+	types.StringAccessor
+	paths map[string]*Block
+}
+
+func (v *docVector) Close() {
+	if v.StringAccessor != nil {
+		v.StringAccessor.Close()
+	}
+	for _, b := range v.paths {
+		b.Deref()
+	}
+	v.paths = nil
+}
+
+func (v *docVector) Size() int {
+	sz := v.StringAccessor.Size()
+	for _, b := range v.paths {
+		sz += b.Size()
+	}
+	return sz
+}
+
+// DocPath returns the shredded vector for document path p or nil when
+// the path is not shredded. Materialized blocks have no shredded paths.
+// Rows where the path is missing or has a different type are NULL.
+func (b *Block) DocPath(p string) *Block {
+	if v, ok := b.any.(*docVector); ok {
+		return v.paths[p]
+	}
+	return nil
+}
+
+// DocPaths returns the sorted list of shredded document paths.
+func (b *Block) DocPaths() []string {
+	v, ok := b.any.(*docVector)
+	if !ok {
+		return nil
+	}
+	paths := make([]string, 0, len(v.paths))
+	for p := range v.paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// docStats hides document contents from pack statistics, documents have
+// no meaningful order.
+type docStats struct {
+	encode.ContextExporter
+}
+
+func (docStats) MinMax() (any, any) {
+	return []byte{}, []byte{}
+}
+
+// docPath collects type info about a document path during analysis.
+type docPath struct {
+	name  string
+	kind  types.BlockType // BlockInt64, BlockFloat64, BlockBytes, BlockBool, invalid when mixed
+	count int
+}
+
+// analyzeDoc returns frequent scalar paths with a single type in src.
+func analyzeDoc(src types.StringAccessor) []docPath {
+	var (
+		n     int
+		stats = make(map[string]*docPath)
+	)
+	for _, buf := range src.Iterator() {
+		doc, err := types.ParseDocument(buf)
+		if err != nil || doc == nil {
+			continue
+		}
+		n++
+		types.WalkDocument(doc, func(path string, val any) {
+			p, ok := stats[path]
+			if !ok {
+				p = &docPath{name: path, kind: docKind(val)}
+				stats[path] = p
+			}
+			p.count++
+			switch k := docKind(val); {
+			case p.kind == k || p.kind == BlockInvalid:
+			case p.kind == BlockInt64 && k == BlockFloat64:
+				p.kind = BlockFloat64
+			case p.kind == BlockFloat64 && k == BlockInt64:
+			default:
+				p.kind = BlockInvalid
+			}
+		})
+	}
+
+	// select most frequent paths
+	paths := make([]docPath, 0, len(stats))
+	for _, p := range stats {
+		if p.kind != BlockInvalid && p.count*minDocPathFill >= n {
+			paths = append(paths, *p)
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if paths[i].count != paths[j].count {
+			return paths[i].count > paths[j].count
+		}
+		return paths[i].name < paths[j].name
+	})
+	if len(paths) > maxDocPaths {
+		paths = paths[:maxDocPaths]
+	}
+	sort.Slice(paths, func(i, j int) bool { return paths[i].name < paths[j].name })
+	return paths
+}
+
+// docKind returns the shredded vector type for scalar document value val.
+func docKind(val any) BlockType {
+	switch v := val.(type) {
+	case json.Number:
+		if _, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return BlockInt64
+		}
+		return BlockFloat64
+	case string:
+		return BlockBytes
+	case bool:
+		return BlockBool
+	default:
+		return BlockInvalid
+	}
+}
+
+// appendDocValue appends scalar document value val to shredded vector b
+// or a NULL when val is missing or has a different type.
+func appendDocValue(b *Block, val any, ok bool) {
+	if ok {
+		switch v := val.(type) {
+		case json.Number:
+			switch b.typ {
+			case BlockInt64:
+				if i64, err := v.Int64(); err == nil {
+					b.Int64().Append(i64)
+					return
+				}
+			case BlockFloat64:
+				if f64, err := v.Float64(); err == nil {
+					b.Float64().Append(f64)
+					return
+				}
+			}
+		case string:
+			if b.typ == BlockBytes {
+				b.Bytes().Append([]byte(v))
+				return
+			}
+		case bool:
+			if b.typ == BlockBool {
+				b.Bool().Append(v)
+				return
+			}
+		}
+	}
+	b.Append(b.typ.Zero())
+	b.SetNull(b.Len() - 1)
+}
+
+func (b *Block) encodeDoc() ([]byte, encode.ContextExporter, error) {
+	src := b.Bytes()
+
+	// build shredded path vectors
+	paths := analyzeDoc(src)
+	childs := make([]*Block, len(paths))
+	for i, p := range paths {
+		childs[i] = New(p.kind, src.Len())
+	}
+	defer func() {
+		for _, c := range childs {
+			c.Deref()
+		}
+	}()
+	if len(paths) > 0 {
+		for _, buf := range src.Iterator() {
+			doc, _ := types.ParseDocument(buf)
+			for i, p := range paths {
+				val, ok := types.LookupPath(doc, p.name)
+				appendDocValue(childs[i], val, ok)
+			}
+		}
+	}
+
+	// add zero byte for compression
+	dst := arena.AllocBytes(1 + binary.MaxVarintLen32)
+	dst = append(dst, 0)
+	dst = binary.AppendUvarint(dst, uint64(len(paths)))
+	for i, p := range paths {
+		cbuf, ctx, err := childs[i].Encode(types.BlockCompressNone)
+		if err != nil {
+			arena.Free(dst)
+			return nil, nil, err
+		}
+		ctx.Close()
+		dst = binary.AppendUvarint(dst, uint64(len(p.name)))
+		dst = append(dst, p.name...)
+		dst = append(dst, byte(p.kind))
+		dst = binary.AppendUvarint(dst, uint64(len(cbuf)))
+		dst = append(dst, cbuf...)
+		arena.Free(cbuf)
+	}
+
+	// append documents
+	ctx := encode.AnalyzeString(src)
+	enc := encode.EncodeString(ctx, src)
+	dst = enc.Store(dst)
+	enc.Close()
+
+	return dst, docStats{ctx}, nil
+}
+
+func (b *Block) decodeDoc(buf []byte) error {
+	n, k := binary.Uvarint(buf)
+	if k <= 0 {
+		return io.ErrShortBuffer
+	}
+	buf = buf[k:]
+	v := &docVector{paths: make(map[string]*Block, int(n))}
+	for range n {
+		l, k := binary.Uvarint(buf)
+		if k <= 0 || len(buf) < k+int(l) {
+			v.Close()
+			return io.ErrShortBuffer
+		}
+		name := string(buf[k : k+int(l)])
+		buf = buf[k+int(l):]
+		if len(buf) == 0 {
+			v.Close()
+			return io.ErrShortBuffer
+		}
+		typ := BlockType(buf[0])
+		l, k = binary.Uvarint(buf[1:])
+		if k <= 0 || len(buf) < 1+k+int(l) {
+			v.Close()
+			return io.ErrShortBuffer
+		}
+		buf = buf[1:]
+		c, err := Decode(typ, buf[k:k+int(l)])
+		if err != nil {
+			v.Close()
+			return fmt.Errorf("block: document path %q: %v", name, err)
+		}
+		v.paths[name] = c
+		buf = buf[k+int(l):]
+	}
+	c, err := encode.LoadString(buf)
+	if err != nil {
+		v.Close()
+		return err
+	}
+	v.StringAccessor = c
+	b.any = v
+	b.len = uint32(c.Len())
+	return nil
+}
+
+// docBytes returns the JSON bytes of a document block value.
+func docBytes(val any) []byte {
+	switch v := val.(type) {
+	case []byte:
+		return v
+	case json.RawMessage:
+		return v
+	default:
+		buf, err := types.DocumentBytes(val)
+		if err != nil {
+			panic(fmt.Errorf("block: invalid document value: %v", err))
+		}
+		return buf
+	}
+}
@@ -5,6 +5,7 @@ package block
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
 	"io"
 
@@ -15,6 +16,13 @@ import (
 )
 
 func (b *Block) Encode(c types.BlockCompression) ([]byte, encode.ContextExporter, error) {
+	return b.EncodeWithDict(c, nil)
+}
+
+// EncodeWithDict encodes the block and compresses it with dictionary d when
+// c selects dictionary compression. Such blocks store the dictionary id after
+// the header byte. Without dictionary they fall back to regular zstd.
+func (b *Block) EncodeWithDict(c types.BlockCompression, d *Dict) ([]byte, encode.ContextExporter, error) {
 	if !b.IsMaterialized() {
 		return nil, nil, ErrBlockNotMaterialized
 	}
@@ -25,10 +33,25 @@ func (b *Block) Encode(c types.BlockCompression) ([]byte, encode.ContextExporter
 		return nil, nil, err
 	}
 
+	// optional: resolve auto compression into a concrete codec, only the
+	// codec is written to the block header so readers need no config
+	c = SelectCompression(buf, c)
+	if c.Codec() == types.BlockCompressDict && d == nil {
+		c = types.BlockCompressZstd
+	}
+
 	// optional: compress block buffer
-	if c > 0 {
+	switch {
+	case c.Codec() == types.BlockCompressDict:
+		dst := arena.AllocBytes(len(buf) + binary.MaxVarintLen32 + 1)
+		dst = append(dst, byte(types.BlockCompressDict))
+		dst = binary.AppendUvarint(dst, uint64(d.Id()))
+		dst = d.enc.EncodeAll(buf, dst)
+		arena.Free(buf)
+		buf = dst
+	case c > 0:
 		cbuf := bytes.NewBuffer(arena.AllocBytes(len(buf)))
-		cbuf.WriteByte(byte(c))
+		cbuf.WriteByte(byte(c.Codec()))
 		enc := NewCompressor(cbuf, c)
 		if _, err := enc.Write(buf); err != nil {
 			return nil, nil, err
@@ -38,12 +61,57 @@ func (b *Block) Encode(c types.BlockCompression) ([]byte, encode.ContextExporter
 		}
 		arena.Free(buf)
 		buf = cbuf.Bytes()
-	} else {
+	default:
 		buf[0] = byte(types.BlockCompressNone)
 	}
+
+	// optional: prepend validity bitmap
+	if b.HasNulls() {
+		buf = b.encodeNulls(buf)
+	}
 	return buf, ctx, nil
 }
 
+// blockNullFlag marks encoded blocks that carry a validity bitmap between
+// the compression header and block data.
+const blockNullFlag = 0x80
+
+// encodeNulls inserts the validity bitmap after the header byte of buf
+// as uvarint bit length followed by bitmap bytes.
+func (b *Block) encodeNulls(buf []byte) []byte {
+	valid := b.Valid()
+	bits := valid.Bytes()
+	dst := arena.AllocBytes(len(buf) + len(bits) + binary.MaxVarintLen32)
+	dst = append(dst, buf[0]|blockNullFlag)
+	dst = binary.AppendUvarint(dst, uint64(valid.Len()))
+	dst = append(dst, bits...)
+	dst = append(dst, buf[1:]...)
+	arena.Free(buf)
+	return dst
+}
+
+// decodeNulls reads the header byte and an optional validity bitmap and
+// returns the header without null flag and the remaining block data.
+func decodeNulls(buf []byte) (byte, []byte, *bitset.Bitset, error) {
+	hdr := buf[0]
+	if hdr&blockNullFlag == 0 {
+		return hdr, buf[1:], nil, nil
+	}
+	n, k := binary.Uvarint(buf[1:])
+	if k <= 0 {
+		return 0, nil, nil, io.ErrShortBuffer
+	}
+	sz := (int(n) + 7) >> 3
+	buf = buf[1+k:]
+	if len(buf) < sz {
+		return 0, nil, nil, io.ErrShortBuffer
+	}
+	valid := bitset.New(int(n))
+	copy(valid.Bytes(), buf[:sz])
+	valid.ResetCount(-1)
+	return hdr &^ blockNullFlag, buf[sz:], valid, nil
+}
+
 func (b *Block) encode() ([]byte, encode.ContextExporter, error) {
 	switch b.typ {
 	case BlockInt64:
@@ -156,7 +224,7 @@ func (b *Block) encode() ([]byte, encode.ContextExporter, error) {
 		enc.Close()
 		return buf, ctx, nil
 
-	case BlockBytes:
+	case BlockBytes, BlockBigint:
 		src := b.Bytes()
 		ctx := encode.AnalyzeString(src)
 		enc := encode.EncodeString(ctx, src)
@@ -166,6 +234,12 @@ func (b *Block) encode() ([]byte, encode.ContextExporter, error) {
 		enc.Close()
 		return buf, ctx, nil
 
+	case BlockList:
+		return b.encodeList()
+
+	case BlockDocument:
+		return b.encodeDoc()
+
 	case BlockInt128:
 		i128 := b.Int128().Slice()
 		ctx := encode.AnalyzeInt128(i128)
@@ -192,42 +266,32 @@ func (b *Block) encode() ([]byte, encode.ContextExporter, error) {
 }
 
 func Decode(typ BlockType, buf []byte) (*Block, error) {
+	return DecodeWithDicts(typ, buf, nil)
+}
+
+// DecodeWithDicts decodes a block and resolves dictionary compressed blocks
+// from dicts.
+func DecodeWithDicts(typ BlockType, buf []byte, dicts *DictSet) (*Block, error) {
 	if len(buf) == 0 {
 		return nil, io.ErrShortBuffer
 	}
 
-	// read optional block compression
-	comp := types.BlockCompression(buf[0])
-
-	if comp > 0 {
-		// decode block data with optional decompressor
-		dec := NewDecompressor(bytes.NewBuffer(buf[1:]), comp)
-		dbuf, err := io.ReadAll(dec)
-		if err != nil {
-			return nil, err
-		}
-		if err := dec.Close(); err != nil {
-			return nil, err
-		}
-		buf = dbuf[1:]
-
-		// TODO: BufferManager: at this point we hold a copy of the decompressed
-		// data which will be referenced by an encode container. we can release
-		// any page locks
-
-	} else {
-		// TODO: BufferManager: here we reference data from a buffer page and
-		// must hold the lock until the block is released (page lock release
-		// happens during block.Deref or we replace Deref with page ref)
+	// read header and optional validity bitmap
+	hdr, buf, valid, err := decodeNulls(buf)
+	if err != nil {
+		return nil, err
+	}
 
-		// with boltdb the backing buffer may become invalid after the tx closes
-		// hence we must make a copy here to allow the block to be cached and shared
-		buf = bytes.Clone(buf[1:])
+	// read optional block compression
+	buf, err = decompress(types.BlockCompression(hdr), buf, dicts)
+	if err != nil {
+		return nil, err
 	}
 
 	b := blockPool.Get().(*Block)
 	b.nref.Store(1)
 	b.typ = typ
+	b.valid = valid
 
 	// decode from buffer, set len/cap
 	switch b.typ {
@@ -311,7 +375,7 @@ func Decode(typ BlockType, buf []byte) (*Block, error) {
 		b.any = c
 		b.len = uint32(c.Len())
 
-	case BlockBytes:
+	case BlockBytes, BlockBigint:
 		c, err := encode.LoadString(buf)
 		if err != nil {
 			return nil, err
@@ -319,6 +383,16 @@ func Decode(typ BlockType, buf []byte) (*Block, error) {
 		b.any = c
 		b.len = uint32(c.Len())
 
+	case BlockList:
+		if err := b.decodeList(buf); err != nil {
+			return nil, err
+		}
+
+	case BlockDocument:
+		if err := b.decodeDoc(buf); err != nil {
+			return nil, err
+		}
+
 	case BlockBool:
 		c, err := encode.LoadBitmap(buf)
 		if err != nil {
@@ -350,3 +424,90 @@ func Decode(typ BlockType, buf []byte) (*Block, error) {
 
 	return b, nil
 }
+
+// Uncompress returns the encoded block data without header, validity
+// bitmap and outer compression. It is used to sample blocks for training
+// compression dictionaries.
+func Uncompress(buf []byte, dicts *DictSet) ([]byte, error) {
+	if len(buf) == 0 {
+		return nil, io.ErrShortBuffer
+	}
+	hdr, buf, _, err := decodeNulls(buf)
+	if err != nil {
+		return nil, err
+	}
+	return decompress(types.BlockCompression(hdr), buf, dicts)
+}
+
+// decompress returns an owned copy of uncompressed block data.
+func decompress(comp types.BlockCompression, buf []byte, dicts *DictSet) ([]byte, error) {
+	switch comp {
+	case types.BlockCompressNone:
+		// TODO: BufferManager: here we reference data from a buffer page and
+		// must hold the lock until the block is released (page lock release
+		// happens during block.Deref or we replace Deref with page ref)
+
+		// with boltdb the backing buffer may become invalid after the tx closes
+		// hence we must make a copy here to allow the block to be cached and shared
+		return bytes.Clone(buf), nil
+
+	case types.BlockCompressDict:
+		id, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return nil, io.ErrShortBuffer
+		}
+		d := dicts.Get(uint32(id))
+		if d == nil {
+			return nil, fmt.Errorf("%w: id 0x%08x", ErrDictNotFound, id)
+		}
+		dbuf, err := d.dec.DecodeAll(buf[n:], nil)
+		if err != nil {
+			return nil, err
+		}
+		return dbuf[1:], nil
+
+	default:
+		// decode block data with optional decompressor
+		dec := NewDecompressor(bytes.NewBuffer(buf), comp)
+		dbuf, err := io.ReadAll(dec)
+		if err != nil {
+			return nil, err
+		}
+		if err := dec.Close(); err != nil {
+			return nil, err
+		}
+
+		// TODO: BufferManager: at this point we hold a copy of the decompressed
+		// data which will be referenced by an encode container. we can release
+		// any page locks
+		return dbuf[1:], nil
+	}
+}
+
+// Decompress returns encoded block data equivalent to buf without outer
+// compression. The result decodes without dictionaries and skips the
+// decompression step on load. Uncompressed input is returned as is.
+func Decompress(buf []byte, dicts *DictSet) ([]byte, error) {
+	if len(buf) == 0 {
+		return nil, io.ErrShortBuffer
+	}
+	hdr, data, _, err := decodeNulls(buf)
+	if err != nil {
+		return nil, err
+	}
+	comp := types.BlockCompression(hdr)
+	if comp == types.BlockCompressNone {
+		return buf, nil
+	}
+	dbuf, err := decompress(comp, data, dicts)
+	if err != nil {
+		return nil, err
+	}
+
+	// keep header and validity bitmap, replace compressed data
+	n := len(buf) - len(data)
+	dst := make([]byte, 0, n+len(dbuf))
+	dst = append(dst, buf[:n]...)
+	dst[0] = buf[0]&blockNullFlag | byte(types.BlockCompressNone)
+	return append(dst, dbuf...), nil
+}
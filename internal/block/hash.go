@@ -37,7 +37,7 @@ func (b *Block) Hash() *Block {
 				u64[i] = one
 			}
 		}
-	case BlockBytes:
+	case BlockBytes, BlockBigint, BlockList, BlockDocument:
 		u64 := h.Uint64().Slice()
 		for i, v := range b.Bytes().Iterator() {
 			u64[i] = hash.Hash(v)
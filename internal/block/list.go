@@ -0,0 +1,196 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package block
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"blockwatch.cc/knoxdb/internal/arena"
+	"blockwatch.cc/knoxdb/internal/encode"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/stringx"
+	"blockwatch.cc/knoxdb/pkg/util"
+)
+
+// List blocks keep one packed little endian element vector per row in a
+// string pool. On disk they are split into an offsets vector and a child
+// vector with all elements so that both can use the existing integer and
+// float encoders.
+//
+// Format: [0 hdr][elem type][uvarint len(offsets)][offsets][elements]
+//
+// Offsets are n+1 cumulative element counts. Both sub-blocks carry their
+// own (zero) header byte.
+
+// NewList allocates a list block for element type elem with space for
+// sz rows.
+func NewList(elem BlockType, sz int) *Block {
+	b := New(BlockList, sz)
+	b.elem = elem
+	return b
+}
+
+// Elem returns the element type of a list block.
+func (b *Block) Elem() BlockType {
+	return b.elem
+}
+
+func (b *Block) encodeList() ([]byte, encode.ContextExporter, error) {
+	if !b.elem.IsListElem() {
+		return nil, nil, fmt.Errorf("block: invalid list element type %s", b.elem)
+	}
+	src := b.Bytes()
+	sz := b.elem.Size()
+	var total int
+	for _, v := range src.Iterator() {
+		total += len(v)
+	}
+
+	// split into offsets and elements
+	offs := New(BlockUint32, src.Len()+1)
+	defer offs.Deref()
+	child := New(b.elem, total/sz)
+	defer child.Deref()
+	u32 := offs.Uint32()
+	u32.Append(0)
+	buf := child.buffer()[:0]
+	for _, v := range src.Iterator() {
+		buf = append(buf, v...)
+		u32.Append(uint32(len(buf) / sz))
+	}
+	child.len = uint32(len(buf) / sz)
+
+	obuf, octx, err := offs.encode()
+	if err != nil {
+		return nil, nil, err
+	}
+	octx.Close()
+	cbuf, ctx, err := child.encode()
+	if err != nil {
+		arena.Free(obuf)
+		return nil, nil, err
+	}
+	obuf[0], cbuf[0] = 0, 0
+
+	// add zero byte for compression
+	dst := arena.AllocBytes(2 + binary.MaxVarintLen32 + len(obuf) + len(cbuf))
+	dst = append(dst, 0, byte(b.elem))
+	dst = binary.AppendUvarint(dst, uint64(len(obuf)))
+	dst = append(dst, obuf...)
+	dst = append(dst, cbuf...)
+	arena.Free(obuf)
+	arena.Free(cbuf)
+
+	// element statistics serve as zone map for list filters
+	return dst, ctx, nil
+}
+
+func (b *Block) decodeList(buf []byte) error {
+	if len(buf) < 2 {
+		return io.ErrShortBuffer
+	}
+	elem := BlockType(buf[0])
+	if !elem.IsListElem() {
+		return fmt.Errorf("block: invalid list element type %d", elem)
+	}
+	l, k := binary.Uvarint(buf[1:])
+	if k <= 0 || len(buf) < 1+k+int(l) {
+		return io.ErrShortBuffer
+	}
+	buf = buf[1+k:]
+	offs, err := decodeMaterialized(BlockUint32, buf[:l])
+	if err != nil {
+		return err
+	}
+	defer offs.Deref()
+	child, err := decodeMaterialized(elem, buf[l:])
+	if err != nil {
+		return err
+	}
+	defer child.Deref()
+
+	// rebuild row vectors
+	u32 := offs.Uint32().Slice()
+	data := child.data()
+	sz := elem.Size()
+	pool := stringx.NewStringPool(max(len(u32)-1, 0))
+	for i := 1; i < len(u32); i++ {
+		pool.Append(data[int(u32[i-1])*sz : int(u32[i])*sz])
+	}
+	b.any = pool
+	b.elem = elem
+	b.len = uint32(pool.Len())
+	return nil
+}
+
+// decodeMaterialized decodes a sub-block and copies it into a new
+// materialized block.
+func decodeMaterialized(typ BlockType, buf []byte) (*Block, error) {
+	c, err := Decode(typ, buf)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Deref()
+	m := New(typ, c.Len())
+	c.AppendTo(m, nil)
+	return m, nil
+}
+
+// listMinMax returns min and max across all list elements in the block.
+// Lists without elements return zero values.
+func (b *Block) listMinMax() (any, any) {
+	src := b.Bytes()
+	switch b.elem {
+	case BlockInt64:
+		return listMinMax[int64](src)
+	case BlockInt32:
+		return listMinMax[int32](src)
+	case BlockInt16:
+		return listMinMax[int16](src)
+	case BlockInt8:
+		return listMinMax[int8](src)
+	case BlockUint64:
+		return listMinMax[uint64](src)
+	case BlockUint32:
+		return listMinMax[uint32](src)
+	case BlockUint16:
+		return listMinMax[uint16](src)
+	case BlockUint8:
+		return listMinMax[uint8](src)
+	case BlockFloat64:
+		return listMinMax[float64](src)
+	case BlockFloat32:
+		return listMinMax[float32](src)
+	default:
+		return nil, nil
+	}
+}
+
+func listMinMax[T types.Number](src types.StringAccessor) (minv, maxv T) {
+	var init bool
+	for _, v := range src.Iterator() {
+		vals := util.FromByteSlice[T](v)
+		if len(vals) == 0 {
+			continue
+		}
+		x, y := util.MinMax(vals...)
+		if !init {
+			minv, maxv, init = x, y, true
+			continue
+		}
+		minv, maxv = min(minv, x), max(maxv, y)
+	}
+	return
+}
+
+// listBytes returns the packed element vector of a list block value.
+func listBytes(val any) []byte {
+	buf, ok := types.ListBytes(val)
+	if !ok {
+		panic(fmt.Errorf("block: invalid list value type %T", val))
+	}
+	return buf
+}
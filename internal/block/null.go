@@ -0,0 +1,160 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package block
+
+import (
+	"bytes"
+	"cmp"
+
+	"blockwatch.cc/knoxdb/internal/bitset"
+	"blockwatch.cc/knoxdb/pkg/num"
+)
+
+// Validity bitmaps track NULL values for nullable fields. A set bit marks
+// a valid value, a cleared bit marks NULL. Blocks without nulls do not
+// allocate a bitmap and the bitmap is extended lazily, i.e. rows beyond
+// its length are always valid. NULL rows keep a zero value in the data
+// container so that vector matchers and encoders can ignore nulls.
+
+// HasNulls returns true when at least one value in the block is NULL.
+func (b *Block) HasNulls() bool {
+	return b.valid != nil && !b.valid.All()
+}
+
+// IsNull returns true when the value at row is NULL.
+func (b *Block) IsNull(row int) bool {
+	return b.valid != nil && row < b.valid.Len() && !b.valid.Contains(row)
+}
+
+// SetNull marks the value at row as NULL.
+func (b *Block) SetNull(row int) {
+	if b.valid == nil {
+		b.valid = bitset.New(max(row+1, b.Len())).One()
+	}
+	if n := b.valid.Len(); row >= n {
+		b.valid.Resize(max(row+1, b.Len()))
+		b.valid.SetRange(n, b.valid.Len()-1)
+	}
+	b.valid.Unset(row)
+	b.SetDirty()
+}
+
+// SetValid marks the value at row as valid (not NULL).
+func (b *Block) SetValid(row int) {
+	if b.valid == nil || row >= b.valid.Len() {
+		return
+	}
+	b.valid.Set(row)
+	b.SetDirty()
+}
+
+// Valid returns the validity bitmap of the block with one bit per row
+// or nil when the block contains no nulls. Callers must not modify
+// the bitmap.
+func (b *Block) Valid() *bitset.Bitset {
+	if !b.HasNulls() {
+		return nil
+	}
+	// sync bitmap length with block length
+	if n := b.valid.Len(); n < b.Len() {
+		b.valid.Resize(b.Len())
+		b.valid.SetRange(n, b.Len()-1)
+	}
+	return b.valid
+}
+
+// appendNulls copies null markers for rows [i:j) or selected rows from src
+// to the block starting at position ofs.
+func (b *Block) appendNulls(src *Block, ofs, i, j int, sel []uint32) {
+	if !src.HasNulls() {
+		return
+	}
+	if sel != nil {
+		for k, row := range sel {
+			if src.IsNull(int(row)) {
+				b.SetNull(ofs + k)
+			}
+		}
+		return
+	}
+	for k := i; k < j; k++ {
+		if src.IsNull(k) {
+			b.SetNull(ofs + k - i)
+		}
+	}
+}
+
+// minMaxValid returns min and max across all non-null values in b.
+func minMaxValid[T any](b *Block, get func(int) T, cmp func(T, T) int) (minv, maxv T) {
+	var init bool
+	for i := range b.Len() {
+		if b.IsNull(i) {
+			continue
+		}
+		v := get(i)
+		switch {
+		case !init:
+			minv, maxv, init = v, v, true
+		case cmp(v, minv) < 0:
+			minv = v
+		case cmp(v, maxv) > 0:
+			maxv = v
+		}
+	}
+	return
+}
+
+func (b *Block) minMaxValid() (any, any) {
+	switch b.typ {
+	case BlockInt64:
+		return minMaxValid(b, b.Int64().Get, cmp.Compare[int64])
+	case BlockInt32:
+		return minMaxValid(b, b.Int32().Get, cmp.Compare[int32])
+	case BlockInt16:
+		return minMaxValid(b, b.Int16().Get, cmp.Compare[int16])
+	case BlockInt8:
+		return minMaxValid(b, b.Int8().Get, cmp.Compare[int8])
+	case BlockUint64:
+		return minMaxValid(b, b.Uint64().Get, cmp.Compare[uint64])
+	case BlockUint32:
+		return minMaxValid(b, b.Uint32().Get, cmp.Compare[uint32])
+	case BlockUint16:
+		return minMaxValid(b, b.Uint16().Get, cmp.Compare[uint16])
+	case BlockUint8:
+		return minMaxValid(b, b.Uint8().Get, cmp.Compare[uint8])
+	case BlockFloat64:
+		return minMaxValid(b, b.Float64().Get, cmp.Compare[float64])
+	case BlockFloat32:
+		return minMaxValid(b, b.Float32().Get, cmp.Compare[float32])
+	case BlockInt128:
+		return minMaxValid(b, b.Int128().Get, num.Int128.Cmp)
+	case BlockInt256:
+		return minMaxValid(b, b.Int256().Get, num.Int256.Cmp)
+	case BlockBytes:
+		minv, maxv := minMaxValid(b, b.Bytes().Get, bytes.Compare)
+		return bytes.Clone(minv), bytes.Clone(maxv) // clone
+	case BlockBigint:
+		minv, maxv := minMaxValid(b, b.Bytes().Get, num.BigCmpBytes)
+		return bytes.Clone(minv), bytes.Clone(maxv) // clone
+	case BlockList:
+		// null rows store empty lists
+		return b.listMinMax()
+	case BlockDocument:
+		return []byte{}, []byte{}
+	case BlockBool:
+		minv, maxv := minMaxValid(b, b.Bool().Get, func(x, y bool) int {
+			switch {
+			case x == y:
+				return 0
+			case !x:
+				return -1
+			default:
+				return 1
+			}
+		})
+		return minv, maxv
+	default:
+		return nil, nil
+	}
+}
@@ -4,6 +4,7 @@
 package block
 
 import (
+	"blockwatch.cc/knoxdb/pkg/num"
 	"blockwatch.cc/knoxdb/pkg/util"
 )
 
@@ -31,8 +32,11 @@ func (b *Block) Cmp(i, j int) int {
 		return b.Float32().Cmp(i, j)
 	case BlockBool:
 		return b.Bool().Cmp(i, j)
-	case BlockBytes:
+	case BlockBytes, BlockList, BlockDocument:
 		return b.Bytes().Cmp(i, j)
+	case BlockBigint:
+		dd := b.Bytes()
+		return num.BigCmpBytes(dd.Get(i), dd.Get(j))
 	case BlockInt256:
 		return b.Int256().Cmp(i, j)
 	case BlockInt128:
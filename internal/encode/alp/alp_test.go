@@ -230,3 +230,9 @@ func TestFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalyzeShortKeepsSource(t *testing.T) {
+	vals := []float64{1, 2.5, 0, 0, 4}
+	Analyze[float64, int64](vals)
+	require.Equal(t, []float64{1, 2.5, 0, 0, 4}, vals)
+}
@@ -11,7 +11,8 @@ const SAMPLE_SIZE = 32
 
 func Sample[T types.Float](dst, src []T) []T {
 	if len(src) <= SAMPLE_SIZE {
-		return src
+		// copy because analysis removes zeros from the sample in place
+		return dst[:copy(dst, src)]
 	}
 	step := len(src) / SAMPLE_SIZE
 	var j int
@@ -0,0 +1,31 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+//go:build amd64
+
+package avx2
+
+import (
+	"testing"
+
+	"blockwatch.cc/knoxdb/internal/cpu"
+	"blockwatch.cc/knoxdb/internal/encode/analyze/tests"
+)
+
+func TestAnalyzeAVX2(t *testing.T) {
+	if !cpu.UseAVX2 {
+		t.Skip()
+	}
+	tests.AnalyzeTest(t, tests.MakeUnsignedTests[uint64](), AnalyzeUint64)
+	tests.AnalyzeTest(t, tests.MakeUnsignedTests[uint32](), AnalyzeUint32)
+	tests.AnalyzeTest(t, tests.MakeUnsignedTests[uint16](), AnalyzeUint16)
+	tests.AnalyzeTest(t, tests.MakeUnsignedTests[uint8](), AnalyzeUint8)
+
+	tests.AnalyzeTest(t, tests.MakeSignedTests[int64](), AnalyzeInt64)
+	tests.AnalyzeTest(t, tests.MakeSignedTests[int32](), AnalyzeInt32)
+	tests.AnalyzeTest(t, tests.MakeSignedTests[int16](), AnalyzeInt16)
+	tests.AnalyzeTest(t, tests.MakeSignedTests[int8](), AnalyzeInt8)
+
+	tests.AnalyzeFloatTest(t, tests.MakeFloatTests[float64](), AnalyzeFloat64)
+	tests.AnalyzeFloatTest(t, tests.MakeFloatTests[float32](), AnalyzeFloat32)
+}
@@ -18,10 +18,15 @@ TEXT ·analyze_u64_avx2(SB), NOSPLIT, $0-24
     SETNE R11B
     ANDB AL, R11B          // R11B = hasDelta
 
+    MOVQ $0x8000000000000000, CX
+    VMOVQ CX, X8
+    VPBROADCASTQ X8, Y8    // Y8 = sign bit (biases unsigned values for signed compare)
+
     MOVQ (R8), R13         // R13 = vals[0]
     VMOVQ R13, X7
-    VPBROADCASTQ X7, Y4    // Y4 = min_vec
-    VMOVDQA Y4, Y5         // Y5 = max_vec
+    VPBROADCASTQ X7, Y4
+    VPXOR Y8, Y4, Y4       // Y4 = min_vec (biased)
+    VMOVDQA Y4, Y5         // Y5 = max_vec (biased)
     VMOVQ R10, X7
     VPBROADCASTQ X7, Y7    // Y7 = delta_vec
     MOVQ $1, SI            // SI = num_runs
@@ -35,10 +40,11 @@ TEXT ·analyze_u64_avx2(SB), NOSPLIT, $0-24
     // First iteration
 first_loop:
     VMOVDQU (R8)(BX*8), Y1 // Load first 4 uint64s
-    VPCMPGTQ Y1, Y4, Y0    // Compare for min (unsigned interpreted as signed)
-    VPBLENDVB Y0, Y1, Y4, Y4 // Update min_vec
-    VPCMPGTQ Y5, Y1, Y3    // Compare for max
-    VPBLENDVB Y3, Y1, Y5, Y5 // Update max_vec
+    VPXOR Y8, Y1, Y9       // Y9 = biased values
+    VPCMPGTQ Y9, Y4, Y0    // Compare for min (biased unsigned as signed)
+    VPBLENDVB Y0, Y9, Y4, Y4 // Update min_vec
+    VPCMPGTQ Y5, Y9, Y3    // Compare for max
+    VPBLENDVB Y3, Y9, Y5, Y5 // Update max_vec
 
     // Create shifted vector
     VPERMQ $0x93, Y1, Y2   // Y2 = [b, c, d, a]
@@ -67,10 +73,11 @@ first_loop:
 vector_loop:
     VMOVDQU (R8)(BX*8), Y1 // Y1 = curr_vec
     VMOVDQU -8(R8)(BX*8), Y2 // Y2 = load prev_vec (faster than shift)
-    VPCMPGTQ Y1, Y4, Y0
-    VPBLENDVB Y0, Y1, Y4, Y4
-    VPCMPGTQ Y5, Y1, Y3
-    VPBLENDVB Y3, Y1, Y5, Y5
+    VPXOR Y8, Y1, Y9
+    VPCMPGTQ Y9, Y4, Y0
+    VPBLENDVB Y0, Y9, Y4, Y4
+    VPCMPGTQ Y5, Y9, Y3
+    VPBLENDVB Y3, Y9, Y5, Y5
 
     // count num_runs
     VPCMPEQQ Y1, Y2, Y6
@@ -103,6 +110,7 @@ next_iter:
     VPCMPGTQ Y1, Y4, Y0
     VPBLENDVB Y0, Y1, Y4, Y4
     VMOVQ X4, AX           // Extract from Y4
+    XORQ CX, AX            // Remove bias
 
     // Max reduction: Select largest value
     VPERMQ $0xB1, Y5, Y0
@@ -112,6 +120,7 @@ next_iter:
     VPCMPGTQ Y1, Y0, Y3
     VPBLENDVB Y3, Y0, Y1, Y0
     VMOVQ X0, DX           // Extract from Y0
+    XORQ CX, DX            // Remove bias
     MOVQ -8(R8)(BX*8), R13 // load last_prev to init tail loop
     JMP tail_loop
 
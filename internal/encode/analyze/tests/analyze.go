@@ -186,6 +186,10 @@ func MakeUnsignedTests[T types.Unsigned]() []TestCase[T] {
 		{"Bounds", []T{
 			types.MinVal[T](), 0, types.MaxVal[T](),
 		}, types.MinVal[T](), types.MaxVal[T](), 0, 2},
+		{"SignBit", []T{
+			types.MaxVal[T]()/2 + 2, types.MaxVal[T]()/2 + 1, types.MaxVal[T]() / 2, types.MaxVal[T]()/2 + 3,
+			types.MaxVal[T]()/2 - 1, types.MaxVal[T]()/2 + 4, types.MaxVal[T]()/2 + 5, types.MaxVal[T]()/2 + 6,
+		}, types.MaxVal[T]()/2 - 1, types.MaxVal[T]()/2 + 6, 0, 8},
 		{"Short", []T{1, 2, 3}, 1, 3, 1, 3},
 		{"MixedRuns", []T{1, 1, 2, 2, 5, 8, 8}, 1, 8, 0, 4},
 		{"Unaligned", []T{1, 2, 3, 4, 5, 6, 7}, 1, 7, 1, 7},
@@ -51,12 +51,15 @@ const (
 	TBitmapDense  // 22
 	TBitmapSparse // 23
 
+	// more string containers
+	TStringFSST // 24
+
 	// Note: always append new values at the end (type is used in storage headers)
 )
 
 var (
-	cTypeNames    = "__const_delta_run_bp_dict_s8_raw_i128_i256_const_run_dict_alp_alprd_raw_const_fixed_compact_dict_zero_one_dense_sparse"
-	cTypeNamesOfs = []int{0, 2, 8, 14, 18, 21, 26, 29, 33, 38, 43, 49, 53, 58, 62, 68, 72, 78, 84, 92, 97, 102, 106, 112, 119}
+	cTypeNames    = "__const_delta_run_bp_dict_s8_raw_i128_i256_const_run_dict_alp_alprd_raw_const_fixed_compact_dict_zero_one_dense_sparse_fsst"
+	cTypeNamesOfs = []int{0, 2, 8, 14, 18, 21, 26, 29, 33, 38, 43, 49, 53, 58, 62, 68, 72, 78, 84, 92, 97, 102, 106, 112, 119, 124}
 )
 
 func (t ContainerType) String() string {
@@ -4,6 +4,7 @@
 package fsst
 
 import (
+	"bytes"
 	"os"
 	"path"
 	"testing"
@@ -62,3 +63,29 @@ func TestFsst(t *testing.T) {
 		})
 	}
 }
+
+func TestFsstString(t *testing.T) {
+	for _, td := range TestData {
+		t.Run(td.Name, func(t *testing.T) {
+			data, err := os.ReadFile(path.Join("testdata", td.FileName))
+			if err != nil {
+				t.Skip()
+			}
+			lines := bytes.Split(data, []byte("\n"))
+			enc := NewEncoder(lines, false)
+			hdr := make([]byte, FSST_MAXHEADER)
+			hdr = hdr[:Export(enc, hdr)]
+			dec, buf, err := NewDecoder(hdr)
+			require.NoError(t, err, "loading decoder should not fail")
+			require.Len(t, buf, 0, "decoder should consume the entire header")
+
+			for i, v := range lines {
+				codes := enc.AppendCompressed(nil, v)
+				require.True(t, bytes.Equal(v, dec.AppendDecompressed(nil, codes)), "line %d", i)
+				require.Equal(t, 0, dec.Compare(codes, v), "line %d", i)
+				next := lines[(i+1)%len(lines)]
+				require.Equal(t, bytes.Compare(v, next), dec.Compare(codes, next), "line %d", i)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package fsst
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Single string API
+//
+// Compress and Decompress work on an entire corpus and produce a single
+// code stream. Columnar containers need random access to individual strings
+// instead, so the functions below compress each string separately with a
+// shared symbol table. Unlike the bulk API they never emit a terminator code,
+// so that a code sequence can be decoded and compared without knowing the
+// length of the uncompressed string.
+
+// AppendCompressed appends the code sequence for string src to dst and
+// returns the extended buffer. The encoder must have been trained on a
+// representative sample before. Compression is deterministic, i.e. equal
+// strings always produce equal code sequences.
+func (e *Encoder) AppendCompressed(dst, src []byte) []byte {
+	var (
+		sym  = e.symbolTable
+		n    = len(src)
+		word uint64
+		tmp  [8]byte
+	)
+	for p := 0; p < n; {
+		// load the next (up to) 8 bytes, zero padded at the end of src
+		rem := n - p
+		if rem >= 8 {
+			word = binary.LittleEndian.Uint64(src[p:])
+		} else {
+			clear(tmp[:])
+			copy(tmp[:], src[p:])
+			word = binary.LittleEndian.Uint64(tmp[:])
+		}
+
+		// try long symbols (3..8 bytes) from hash table first
+		if rem >= 3 {
+			s := sym.hashTab[FSSTHash(word&0xFFFFFF)&(HashTabSize-1)]
+			if s.icl < FSST_ICL_FREE &&
+				int(s.Len()) <= rem &&
+				s.val.Uint64() == word&(0xFFFFFFFFFFFFFFFF>>uint8(s.icl)) {
+				dst = append(dst, uint8(s.Code()))
+				p += int(s.Len())
+				continue
+			}
+		}
+
+		// 2-byte and 1-byte symbols share the short code table after finalize,
+		// escapes have the code base bit set
+		var code uint16
+		if rem >= 2 {
+			code = sym.shortCodes[word&0xFFFF]
+		} else {
+			code = sym.byteCodes[word&0xFF]
+		}
+		if code&FSST_CODE_BASE != 0 {
+			dst = append(dst, FSST_ESC, uint8(word))
+			p++
+			continue
+		}
+		dst = append(dst, uint8(code))
+		p += int(code >> FSST_LEN_BITS)
+	}
+	return dst
+}
+
+// AppendDecompressed decodes code sequence src and appends the original
+// string to dst.
+func (d *Decoder) AppendDecompressed(dst, src []byte) []byte {
+	var tmp [8]byte
+	for i := 0; i < len(src); i++ {
+		code := src[i]
+		if code == FSST_ESC {
+			i++
+			dst = append(dst, src[i])
+			continue
+		}
+		binary.LittleEndian.PutUint64(tmp[:], d.symbol[code])
+		dst = append(dst, tmp[:d.len[code]]...)
+	}
+	return dst
+}
+
+// Compare lexicographically compares the original string of code sequence
+// src with val without decompressing src into a temporary buffer. The result
+// is 0 if both strings are equal, -1 if src < val and +1 if src > val.
+func (d *Decoder) Compare(src, val []byte) int {
+	var tmp [8]byte
+	for i := 0; i < len(src); i++ {
+		var sym []byte
+		if code := src[i]; code == FSST_ESC {
+			i++
+			sym = src[i : i+1]
+		} else {
+			binary.LittleEndian.PutUint64(tmp[:], d.symbol[code])
+			sym = tmp[:d.len[code]]
+		}
+		if len(val) < len(sym) {
+			// val ends inside this symbol
+			if c := bytes.Compare(sym[:len(val)], val); c != 0 {
+				return c
+			}
+			return 1
+		}
+		if c := bytes.Compare(sym, val[:len(sym)]); c != 0 {
+			return c
+		}
+		val = val[len(sym):]
+	}
+	if len(val) > 0 {
+		return -1
+	}
+	return 0
+}
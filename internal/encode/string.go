@@ -6,9 +6,11 @@ package encode
 import (
 	"bytes"
 	"fmt"
+	"math/bits"
 	"sync"
 
 	"blockwatch.cc/knoxdb/internal/arena"
+	"blockwatch.cc/knoxdb/internal/encode/fsst"
 	"blockwatch.cc/knoxdb/internal/hash"
 	"blockwatch.cc/knoxdb/internal/types"
 )
@@ -23,6 +25,7 @@ import (
 // Compact  3*24      8n              ∑_1_c(len(n))  256k + c*len(n)
 // Fixed    8+24                      ∑_1_n(len(n))    32 + n*len(n)
 // Dict     4*24+16   8c   n*log2(c)  ∑_1_c(len(n))   112 + 8c + n*log2(c) + c*len(n)
+// FSST     3*24+2k   8n              r*∑_1_c(len(n))  2k + 256k + r*c*len(n)
 //
 //
 // Examples
@@ -42,6 +45,8 @@ import (
 // fixed len + no zeros   -> fixed
 // dyn len + card < n/2   -> dict
 // dyn len + card >= n/2  -> compact (e.g. block/op hashes)
+// dict/compact + text    -> fsst when a trial run on a sample is smaller
+//                           (e.g. memos, URIs), r = sample compression ratio
 
 type StringContainer interface {
 	// introspect
@@ -68,6 +73,7 @@ type StringContext struct {
 	UniqueSize int            // size of unique strings in bytes
 	UniqueMap  map[uint64]int // unique values hash map to id (optional)
 	Dups       []int32        // <0 = unique string, >=0 position of original
+	Fsst       *fsst.Encoder  // trained FSST symbol table (optional)
 }
 
 func (c *StringContext) Close() {
@@ -85,6 +91,7 @@ func (c *StringContext) Close() {
 	c.NumUnique = 0
 	c.NumValues = 0
 	c.UniqueSize = 0
+	c.Fsst = nil
 	putStringContext(c)
 }
 
@@ -96,6 +103,10 @@ func (c *StringContext) Unique() int {
 	return c.NumUnique
 }
 
+// FSST_MIN_AVGLEN is the minimum average length of unique strings
+// for which FSST compression is considered.
+const FSST_MIN_AVGLEN = 8
+
 var emptyHash uint64 = 11400714785074694791 // xxhash64 prime1 used as AES hash seed
 
 // AnalyzeString produces statistics about []byte vectors.
@@ -159,6 +170,71 @@ func (c *StringContext) UseScheme() ContainerType {
 	}
 }
 
+// UseFsst runs a trial FSST compression on a sample of vals and returns true
+// when the estimated result is smaller than scheme. The symbol table trained
+// on the sample is kept in the context and reused by the FSST container.
+func (c *StringContext) UseFsst(scheme ContainerType, vals types.StringAccessor) bool {
+	// skip small vectors and short strings
+	if c.NumValues < SAMPLE_SIZE || c.UniqueSize < FSST_MIN_AVGLEN*c.NumUnique {
+		return false
+	}
+
+	// sample equal sized chunks across the vector
+	sample := arena.Alloc[[]byte](SAMPLE_COUNT * SAMPLE_SIZE)
+	chunk := c.NumValues / SAMPLE_COUNT
+	for i := range SAMPLE_COUNT {
+		for j := range min(SAMPLE_SIZE, chunk) {
+			sample = append(sample, vals.Get(i*chunk+j))
+		}
+	}
+
+	// train and compress
+	enc := fsst.NewEncoder(sample, false)
+	var rawSize, compSize int
+	buf := arena.Alloc[byte](FSST_MIN_AVGLEN * SAMPLE_SIZE)
+	for _, v := range sample {
+		buf = enc.AppendCompressed(buf[:0], v)
+		rawSize += len(v)
+		compSize += len(buf)
+	}
+	arena.Free(buf)
+	clear(sample)
+	arena.Free(sample)
+	if rawSize == 0 {
+		return false
+	}
+
+	// compare estimated costs
+	var cost int
+	switch scheme {
+	case TStringDictionary:
+		cost = c.dictCosts()
+	default:
+		cost = c.compactCosts()
+	}
+	if c.fsstCosts(float64(compSize)/float64(rawSize)) >= cost {
+		return false
+	}
+	c.Fsst = enc
+	return true
+}
+
+// Cost estimates in bytes for dynamic length schemes. Child containers
+// are assumed to be bit-packed.
+func (c *StringContext) compactCosts() int {
+	return c.UniqueSize + c.NumValues*(bits.Len(uint(c.UniqueSize))+bits.Len(uint(c.MaxLen)))/8
+}
+
+func (c *StringContext) dictCosts() int {
+	return c.UniqueSize + c.NumUnique*(bits.Len(uint(c.UniqueSize))+bits.Len(uint(c.MaxLen)))/8 +
+		c.NumValues*bits.Len(uint(c.NumUnique))/8
+}
+
+func (c *StringContext) fsstCosts(ratio float64) int {
+	sz := int(ratio * float64(c.UniqueSize))
+	return fsst.FSST_MAXHEADER + sz + c.NumValues*(bits.Len(uint(sz))+bits.Len(uint(2*c.MaxLen)))/8
+}
+
 func newStringContext() *StringContext {
 	return stringContextFactory.Get().(*StringContext)
 }
@@ -182,6 +258,8 @@ func NewString(typ ContainerType) StringContainer {
 		return newStringContainer[CompactStringContainer](typ)
 	case TStringDictionary:
 		return newStringContainer[DictStringContainer](typ)
+	case TStringFSST:
+		return newStringContainer[FsstStringContainer](typ)
 	default:
 		panic(fmt.Errorf("invalid string scheme %d (%s)", typ, typ))
 	}
@@ -196,8 +274,17 @@ func EncodeString(ctx *StringContext, v types.StringAccessor) StringContainer {
 		defer ctx.Close()
 	}
 
+	// check if FSST beats the selected scheme
+	scheme := ctx.UseScheme()
+	switch scheme {
+	case TStringCompact, TStringDictionary:
+		if ctx.UseFsst(scheme, v) {
+			scheme = TStringFSST
+		}
+	}
+
 	// alloc best container and encode
-	return NewString(ctx.UseScheme()).Encode(ctx, v)
+	return NewString(scheme).Encode(ctx, v)
 }
 
 // LoadString loads a string container from buffer.
@@ -214,9 +301,11 @@ type StringFactory struct {
 	fixedPool     sync.Pool
 	compactPool   sync.Pool
 	dictPool      sync.Pool
+	fsstPool      sync.Pool
 	fixedItPool   sync.Pool // iterators
 	compactItPool sync.Pool
 	dictItPool    sync.Pool
+	fsstItPool    sync.Pool
 }
 
 func newStringContainer[T any](typ ContainerType) *T {
@@ -229,6 +318,8 @@ func newStringContainer[T any](typ ContainerType) *T {
 		return stringFactory.compactPool.Get().(*T)
 	case TStringDictionary:
 		return stringFactory.dictPool.Get().(*T)
+	case TStringFSST:
+		return stringFactory.fsstPool.Get().(*T)
 	default:
 		return nil
 	}
@@ -244,6 +335,8 @@ func putStringContainer[T StringContainer](c T) {
 		stringFactory.compactPool.Put(c)
 	case TStringDictionary:
 		stringFactory.dictPool.Put(c)
+	case TStringFSST:
+		stringFactory.fsstPool.Put(c)
 	}
 }
 
@@ -255,6 +348,8 @@ func newStringIterator[T any](typ ContainerType) *T {
 		return stringFactory.compactItPool.Get().(*T)
 	case TStringDictionary:
 		return stringFactory.dictItPool.Get().(*T)
+	case TStringFSST:
+		return stringFactory.fsstItPool.Get().(*T)
 	default:
 		return nil
 	}
@@ -268,6 +363,8 @@ func putStringIterator[T types.StringIterator](c T) {
 		stringFactory.compactItPool.Put(c)
 	case *DictStringIterator:
 		stringFactory.dictItPool.Put(c)
+	case *FsstStringIterator:
+		stringFactory.fsstItPool.Put(c)
 	}
 }
 
@@ -284,6 +381,9 @@ var stringFactory = StringFactory{
 	dictPool: sync.Pool{
 		New: func() any { return new(DictStringContainer) },
 	},
+	fsstPool: sync.Pool{
+		New: func() any { return new(FsstStringContainer) },
+	},
 	fixedItPool: sync.Pool{
 		New: func() any { return new(FixedStringIterator) },
 	},
@@ -293,4 +393,7 @@ var stringFactory = StringFactory{
 	dictItPool: sync.Pool{
 		New: func() any { return new(DictStringIterator) },
 	},
+	fsstItPool: sync.Pool{
+		New: func() any { return new(FsstStringIterator) },
+	},
 }
@@ -38,6 +38,7 @@ func BenchmarkStringEncode(b *testing.B) {
 			TStringFixed,
 			TStringCompact,
 			TStringDictionary,
+			TStringFSST,
 		} {
 			data := etests.GenForStringScheme(int(scheme), c.N)
 			ctx := AnalyzeString(data)
@@ -71,6 +72,7 @@ func BenchmarkStringEncodeAndStore(b *testing.B) {
 			TStringFixed,
 			TStringCompact,
 			TStringDictionary,
+			TStringFSST,
 		} {
 			data := etests.GenForStringScheme(int(scheme), c.N)
 			once := etests.ShowInfo
@@ -129,6 +131,7 @@ func BenchmarkStringDecode(b *testing.B) {
 			TStringFixed,
 			TStringCompact,
 			TStringDictionary,
+			TStringFSST,
 		} {
 			data := etests.GenForStringScheme(int(scheme), c.N)
 			ctx := AnalyzeString(data)
@@ -159,6 +162,7 @@ func BenchmarkStringCmp(b *testing.B) {
 			TStringFixed,
 			TStringCompact,
 			TStringDictionary,
+			TStringFSST,
 		} {
 			data := etests.GenForStringScheme(int(scheme), c.N)
 			ctx := AnalyzeString(data)
@@ -183,6 +187,7 @@ func BenchmarkStringIterator(b *testing.B) {
 			TStringFixed,
 			TStringCompact,
 			TStringDictionary,
+			TStringFSST,
 		} {
 			data := etests.GenForStringScheme(int(scheme), c.N)
 			ctx := AnalyzeString(data)
@@ -0,0 +1,342 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package encode
+
+import (
+	"bytes"
+	"fmt"
+	"iter"
+
+	"blockwatch.cc/knoxdb/internal/arena"
+	"blockwatch.cc/knoxdb/internal/encode/fsst"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/num"
+)
+
+// ensure we implement required interfaces
+var (
+	_ types.StringAccessor = (*FsstStringContainer)(nil)
+	_ StringContainer      = (*FsstStringContainer)(nil)
+)
+
+// FsstStringContainer compresses unique strings individually with a shared
+// FSST symbol table. Like compact containers duplicates reference the same
+// code sequence. Random access decodes a single string and matchers compare
+// code sequences symbol by symbol without decompressing them first.
+type FsstStringContainer struct {
+	readOnlyContainer[[]byte]
+	table []byte        // serialized symbol table
+	dec   *fsst.Decoder // symbol table decoder
+	buf   []byte        // code sequences
+	ofs   NumberContainer[uint32]
+	len   NumberContainer[uint32]
+	n     int
+	free  bool
+}
+
+func (c *FsstStringContainer) Info() string {
+	return fmt.Sprintf("FSST(string)_[ofs=%s]_[len=%s]", c.ofs.Info(), c.len.Info())
+}
+
+func (c *FsstStringContainer) Close() {
+	c.ofs.Close()
+	c.len.Close()
+	c.ofs = nil
+	c.len = nil
+	if c.free {
+		arena.Free(c.buf)
+		c.free = false
+	}
+	c.buf = nil
+	c.table = nil
+	c.dec = nil
+	c.n = 0
+	putStringContainer(c)
+}
+
+func (c *FsstStringContainer) Type() ContainerType {
+	return TStringFSST
+}
+
+func (c *FsstStringContainer) Len() int {
+	return c.n
+}
+
+func (c *FsstStringContainer) Size() int {
+	return 1 + c.ofs.Size() + c.len.Size() +
+		num.UvarintLen(len(c.table)) + len(c.table) +
+		num.UvarintLen(len(c.buf)) + len(c.buf)
+}
+
+func (c *FsstStringContainer) Matcher() types.StringMatcher {
+	return c
+}
+
+func (c *FsstStringContainer) Store(dst []byte) []byte {
+	dst = append(dst, byte(TStringFSST))
+	dst = c.ofs.Store(dst)
+	dst = c.len.Store(dst)
+	dst = num.AppendUvarint(dst, uint64(len(c.table)))
+	dst = append(dst, c.table...)
+	dst = num.AppendUvarint(dst, uint64(len(c.buf)))
+	return append(dst, c.buf...)
+}
+
+func (c *FsstStringContainer) Load(buf []byte) ([]byte, error) {
+	if buf[0] != byte(TStringFSST) {
+		return buf, ErrInvalidType
+	}
+	buf = buf[1:]
+
+	var err error
+	c.ofs = NewInt[uint32](ContainerType(buf[0]))
+	buf, err = c.ofs.Load(buf)
+	if err != nil {
+		return buf, err
+	}
+	c.n = c.ofs.Len()
+
+	c.len = NewInt[uint32](ContainerType(buf[0]))
+	buf, err = c.len.Load(buf)
+	if err != nil {
+		return buf, err
+	}
+
+	v, n := num.Uvarint(buf)
+	buf = buf[n:]
+	c.table = buf[:int(v)]
+	buf = buf[int(v):]
+	c.dec, _, err = fsst.NewDecoder(c.table)
+	if err != nil {
+		return buf, err
+	}
+
+	v, n = num.Uvarint(buf)
+	buf = buf[n:]
+	c.buf = buf[:int(v)]
+	return buf[int(v):], nil
+}
+
+// codes returns the compressed code sequence for string i.
+func (c *FsstStringContainer) codes(i int) []byte {
+	ofs := c.ofs.Get(i)
+	len := c.len.Get(i)
+	return c.buf[ofs : ofs+len]
+}
+
+func (c *FsstStringContainer) Get(i int) []byte {
+	if i < 0 || i >= c.n {
+		return nil
+	}
+	return c.dec.AppendDecompressed(nil, c.codes(i))
+}
+
+func (c *FsstStringContainer) Iterator() iter.Seq2[int, []byte] {
+	return func(fn func(int, []byte) bool) {
+		for i := range c.n {
+			if !fn(i, c.dec.AppendDecompressed(nil, c.codes(i))) {
+				return
+			}
+		}
+	}
+}
+
+func (c *FsstStringContainer) Chunks() types.StringIterator {
+	return NewFsstStringIterator(c)
+}
+
+func (c *FsstStringContainer) AppendTo(dst types.StringWriter, sel []uint32) {
+	// decode into a scratch buffer, writers copy appended strings
+	var tmp []byte
+	if sel == nil {
+		for i := range c.n {
+			tmp = c.dec.AppendDecompressed(tmp[:0], c.codes(i))
+			dst.Append(tmp)
+		}
+	} else {
+		for _, v := range sel {
+			tmp = c.dec.AppendDecompressed(tmp[:0], c.codes(int(v)))
+			dst.Append(tmp)
+		}
+	}
+}
+
+func (c *FsstStringContainer) Encode(ctx *StringContext, vals types.StringAccessor) StringContainer {
+	c.n = ctx.NumValues
+
+	// train symbol table on unique strings unless a sample run already did
+	enc := ctx.Fsst
+	if enc == nil {
+		uniq := arena.Alloc[[]byte](ctx.NumUnique)
+		for i, v := range vals.Iterator() {
+			if ctx.Dups[i] < 0 {
+				uniq = append(uniq, v)
+			}
+		}
+		enc = fsst.NewEncoder(uniq, false)
+		clear(uniq)
+		arena.Free(uniq)
+	}
+	c.table = make([]byte, fsst.FSST_MAXHEADER)
+	c.table = c.table[:fsst.Export(enc, c.table)]
+	c.dec, _, _ = fsst.NewDecoder(c.table)
+
+	buf := arena.Alloc[byte](ctx.UniqueSize)
+	offs := arena.Alloc[uint32](ctx.NumValues)[:ctx.NumValues]
+	size := arena.Alloc[uint32](ctx.NumValues)[:ctx.NumValues]
+	uniq := arena.Alloc[int32](ctx.NumUnique)
+
+	// compress unique strings and reference duplicates
+	for i, v := range vals.Iterator() {
+		k := ctx.Dups[i]
+		if k < 0 {
+			offs[i] = uint32(len(buf))
+			buf = enc.AppendCompressed(buf, v)
+			size[i] = uint32(len(buf)) - offs[i]
+			uniq = append(uniq, int32(i))
+		} else {
+			offs[i] = offs[uniq[k]]
+			size[i] = size[uniq[k]]
+		}
+	}
+	arena.Free(uniq)
+
+	// encode child containers
+	c.ofs = EncodeInt(nil, offs)
+	arena.Free(offs)
+	c.len = EncodeInt(nil, size)
+	arena.Free(size)
+	c.buf = buf
+	c.free = true
+
+	return c
+}
+
+func (c *FsstStringContainer) Cmp(i, j int) int {
+	return bytes.Compare(c.Get(i), c.Get(j))
+}
+
+// match sets bits for all strings (or strings in mask) whose code sequence
+// satisfies fn.
+func (c *FsstStringContainer) match(fn func([]byte) bool, bits, mask *Bitset) {
+	set := bits.Bytes()
+	var cnt int
+	if mask != nil {
+		for i := range mask.Iterator() {
+			if !fn(c.codes(i)) {
+				continue
+			}
+			set[i>>3] |= bitmask(i)
+			cnt++
+		}
+	} else {
+		for i := range c.n {
+			if !fn(c.codes(i)) {
+				continue
+			}
+			set[i>>3] |= bitmask(i)
+			cnt++
+		}
+	}
+	bits.ResetCount(cnt)
+}
+
+func (c *FsstStringContainer) MatchEqual(val []byte, bits, mask *Bitset) {
+	c.match(func(v []byte) bool { return c.dec.Compare(v, val) == 0 }, bits, mask)
+}
+
+func (c *FsstStringContainer) MatchNotEqual(val []byte, bits, mask *Bitset) {
+	c.match(func(v []byte) bool { return c.dec.Compare(v, val) != 0 }, bits, mask)
+}
+
+func (c *FsstStringContainer) MatchLess(val []byte, bits, mask *Bitset) {
+	c.match(func(v []byte) bool { return c.dec.Compare(v, val) < 0 }, bits, mask)
+}
+
+func (c *FsstStringContainer) MatchLessEqual(val []byte, bits, mask *Bitset) {
+	c.match(func(v []byte) bool { return c.dec.Compare(v, val) <= 0 }, bits, mask)
+}
+
+func (c *FsstStringContainer) MatchGreater(val []byte, bits, mask *Bitset) {
+	c.match(func(v []byte) bool { return c.dec.Compare(v, val) > 0 }, bits, mask)
+}
+
+func (c *FsstStringContainer) MatchGreaterEqual(val []byte, bits, mask *Bitset) {
+	c.match(func(v []byte) bool { return c.dec.Compare(v, val) >= 0 }, bits, mask)
+}
+
+func (c *FsstStringContainer) MatchBetween(a, b []byte, bits, mask *Bitset) {
+	if c.n == 0 {
+		return
+	}
+	if bytes.Equal(a, b) {
+		c.MatchEqual(a, bits, mask)
+		return
+	}
+	c.match(func(v []byte) bool {
+		return c.dec.Compare(v, a) >= 0 && c.dec.Compare(v, b) <= 0
+	}, bits, mask)
+}
+
+// FsstStringIterator decodes strings chunk-wise into a reusable buffer.
+// Returned strings are only valid until the next chunk is loaded.
+type FsstStringIterator struct {
+	BaseIterator[[]byte]
+	buf   []byte
+	codes []byte
+	dec   *fsst.Decoder
+	start types.NumberIterator[uint32]
+	size  types.NumberIterator[uint32]
+}
+
+func NewFsstStringIterator(c *FsstStringContainer) *FsstStringIterator {
+	it := newStringIterator[FsstStringIterator](TStringFSST)
+	it.codes = c.buf
+	it.dec = c.dec
+	it.start = c.ofs.Chunks()
+	it.size = c.len.Chunks()
+	it.base = -1
+	it.len = c.Len()
+	it.BaseIterator.fill = it.fill
+	return it
+}
+
+func (it *FsstStringIterator) Close() {
+	it.start.Close()
+	it.start = nil
+	it.size.Close()
+	it.size = nil
+	it.codes = nil
+	it.dec = nil
+	it.buf = it.buf[:0]
+	clear(it.chunk[:])
+	it.BaseIterator.Close()
+	putStringIterator(it)
+}
+
+func (it *FsstStringIterator) fill(base int) int {
+	// load code chunk at base and decode
+	it.start.Seek(base)
+	it.size.Seek(base)
+	ofs, n := it.start.NextChunk()
+	size, m := it.size.NextChunk()
+	if n == 0 || n != m {
+		it.ofs = it.len
+		it.base = -1
+		return 0
+	}
+
+	// decode into the shared buffer, when append reallocates strings decoded
+	// earlier keep referencing the old buffer which leaves them intact
+	it.buf = it.buf[:0]
+	for i := range n {
+		start := len(it.buf)
+		it.buf = it.dec.AppendDecompressed(it.buf, it.codes[ofs[i]:ofs[i]+size[i]])
+		end := len(it.buf)
+		it.chunk[i] = it.buf[start:end:end]
+	}
+
+	it.base = base
+	return n
+}
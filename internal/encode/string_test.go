@@ -89,6 +89,7 @@ func TestStringEncode(t *testing.T) {
 	testStringEncode(t, TStringFixed)
 	testStringEncode(t, TStringCompact)
 	testStringEncode(t, TStringDictionary)
+	testStringEncode(t, TStringFSST)
 }
 
 func TestStringFixed(t *testing.T) {
@@ -103,6 +104,26 @@ func TestStringDict(t *testing.T) {
 	testStringContainer(t, TStringDictionary)
 }
 
+func TestStringFsst(t *testing.T) {
+	testStringContainer(t, TStringFSST)
+}
+
+func TestStringFsstScheme(t *testing.T) {
+	// compressible text -> fsst
+	p := tests.GenStringText(1024, 1024)
+	enc := EncodeString(nil, p)
+	assert.Equal(t, TStringFSST, enc.Type(), "selected scheme for text")
+	enc.Close()
+	p.Close()
+
+	// random bytes -> compact
+	p = tests.GenStringDups(1024, 1024, 32)
+	enc = EncodeString(nil, p)
+	assert.Equal(t, TStringCompact, enc.Type(), "selected scheme for random data")
+	enc.Close()
+	p.Close()
+}
+
 func testStringContainer(t *testing.T, scheme ContainerType) {
 	// general
 	testStringEncode(t, scheme)
@@ -336,6 +357,11 @@ func MakeShortStringTests(scheme ContainerType) []TestCaseString {
 		return []TestCaseString{{"compact", n, tests.GenStringDups(n, min(1, n*3/4), -1)}}
 	case TStringDictionary:
 		return []TestCaseString{{"dict", n, tests.GenStringDups(n, n/5, -1)}}
+	case TStringFSST:
+		return []TestCaseString{
+			{"fsst", n, tests.GenStringText(n, n/2)},
+			{"fsst_rand", n, tests.GenStringDups(n, n/2, -1)},
+		}
 	default:
 		return nil
 	}
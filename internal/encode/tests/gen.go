@@ -81,6 +81,9 @@ func GenForStringScheme(scheme, n int) *stringx.StringPool {
 	case 19:
 		// TStringDictionary
 		return tests.GenStringDups(n, n/5, -1)
+	case 24:
+		// TStringFSST
+		return tests.GenStringText(n, max(1, n*3/4))
 	default:
 		panic(fmt.Errorf("GenForStringScheme: unsupported scheme %d", scheme))
 	}
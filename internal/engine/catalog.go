@@ -6,6 +6,7 @@ package engine
 import (
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"path/filepath"
@@ -80,10 +81,10 @@ var (
 	streamsKey   = []byte("streams")   // key => serialized stream config
 
 	// keys
-	schemaKey = []byte("schema")
-	tableKey  = []byte("table")
-	nameKey   = []byte("name")
-	// statusKey     = []byte("status")
+	schemaKey     = []byte("schema")
+	tableKey      = []byte("table")
+	nameKey       = []byte("name")
+	statusKey     = []byte("status")
 	dataKey       = []byte("data")
 	checkpointKey = []byte("checkpoint")
 )
@@ -549,7 +550,7 @@ func (c *Catalog) ListIndexes(ctx context.Context, key uint64) ([]uint64, error)
 	return res, nil
 }
 
-func (c *Catalog) AddIndex(ctx context.Context, ikey, tkey uint64, s *schema.IndexSchema, o Options) error {
+func (c *Catalog) AddIndex(ctx context.Context, ikey, tkey uint64, s *schema.IndexSchema, o Options, status IndexStatus) error {
 	if err := c.PutIndexSchema(ctx, s); err != nil {
 		return err
 	}
@@ -579,10 +580,56 @@ func (c *Catalog) AddIndex(ctx context.Context, ikey, tkey uint64, s *schema.Ind
 	if err := bucket.Put(tableKey, util.U64Bytes(tkey)); err != nil {
 		return err
 	}
+	if err := bucket.Put(statusKey, []byte{byte(status)}); err != nil {
+		return err
+	}
 
 	return nil
 }
 
+// GetIndexStatus returns the build status of an index. Indexes created
+// before status tracking existed have no status key and are ready.
+func (c *Catalog) GetIndexStatus(ctx context.Context, key uint64) (IndexStatus, error) {
+	tx, err := GetTx(ctx).CatalogTx(c.db, false)
+	if err != nil {
+		return 0, err
+	}
+	bucket, err := store.GetBucket(tx, indexesKey, util.U64Bytes(key))
+	if err != nil {
+		return 0, ErrNoIndex
+	}
+	val, err := bucket.Get(statusKey)
+	if err != nil || len(val) == 0 {
+		return IndexStatusReady, nil
+	}
+	return IndexStatus(val[0]), nil
+}
+
+// PutIndexStatus updates the build status of an index. Status changes are
+// not logged to the WAL because they are idempotent: an index found in
+// building state after a crash simply resumes its build from the last
+// checkpoint. Background tasks run without an engine transaction, so like
+// PutCheckpoint we fall back to a separate storage transaction.
+func (c *Catalog) PutIndexStatus(ctx context.Context, key uint64, status IndexStatus) error {
+	writeStatus := func(tx store.Tx) error {
+		bucket, err := store.GetBucket(tx, indexesKey, util.U64Bytes(key))
+		if err != nil {
+			return ErrNoIndex
+		}
+		return bucket.Put(statusKey, []byte{byte(status)})
+	}
+
+	if etx := GetTx(ctx); etx != nil {
+		tx, err := etx.CatalogTx(c.db, true)
+		if err != nil {
+			return err
+		}
+		return writeStatus(tx)
+	} else {
+		return c.db.Update(writeStatus)
+	}
+}
+
 func (c *Catalog) DropIndex(ctx context.Context, key uint64) error {
 	tx, err := GetTx(ctx).CatalogTx(c.db, true)
 	if err != nil {
@@ -854,7 +901,11 @@ func (c *Catalog) runAbortActions(ctx context.Context, pending []Object) error {
 		var err error
 		switch obj.Action() {
 		case wal.RecordTypeInsert:
+			// created objects are only stored on commit, so they may be missing
 			err = obj.Drop(ctx)
+			if errors.Is(err, ErrNoTable) || errors.Is(err, ErrNoIndex) {
+				err = nil
+			}
 		case wal.RecordTypeUpdate:
 			// ignore
 		case wal.RecordTypeDelete:
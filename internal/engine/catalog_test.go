@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"blockwatch.cc/knoxdb/internal/wal"
 	"blockwatch.cc/knoxdb/pkg/schema"
 	"blockwatch.cc/knoxdb/pkg/store"
 	_ "blockwatch.cc/knoxdb/pkg/store/memdb"
@@ -184,7 +185,7 @@ func TestCatalogAddIndex(t *testing.T) {
 		PageSize: 1024,
 	}
 	require.NoError(t, cat.AddTable(tctx, 1, s, topts))
-	require.NoError(t, cat.AddIndex(tctx, 2, 1, s.Indexes[0], iopts))
+	require.NoError(t, cat.AddIndex(tctx, 2, 1, s.Indexes[0], iopts, IndexStatusBuilding))
 	require.NoError(t, commit())
 
 	// list indexes
@@ -203,6 +204,26 @@ func TestCatalogAddIndex(t *testing.T) {
 	require.Equal(t, s2.Name, s.Indexes[0].Name)
 	require.Equal(t, s2.Hash(), s.Indexes[0].Hash())
 	require.Equal(t, opts2, iopts)
+
+	// index status
+	status, err := cat.GetIndexStatus(tctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, IndexStatusBuilding, status)
+	require.NoError(t, abort())
+
+	// update index status
+	tctx, _, commit, abort, err = eng.WithTransaction(ctx)
+	require.NoError(t, err)
+	defer abort()
+	require.NoError(t, cat.PutIndexStatus(tctx, 2, IndexStatusReady))
+	require.NoError(t, commit())
+
+	tctx, _, _, abort, err = eng.WithTransaction(ctx)
+	require.NoError(t, err)
+	defer abort()
+	status, err = cat.GetIndexStatus(tctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, IndexStatusReady, status)
 	require.NoError(t, abort())
 
 	// drop index
@@ -277,3 +298,55 @@ func TestCatalogAddEnum(t *testing.T) {
 	defer abort()
 	require.Error(t, cat.DropEnum(tctx, 1))
 }
+
+func TestCatalogEnumValues(t *testing.T) {
+	ctx, eng, cat, close := WithCatalog(t)
+	defer close()
+	tctx, _, commit, abort, err := eng.WithTransaction(ctx)
+	require.NoError(t, err)
+	defer abort()
+	enum := schema.NewEnumDictionary("dict")
+	require.NoError(t, cat.AddEnum(tctx, enum))
+	require.NoError(t, commit())
+
+	// append values in two steps, roundtrip through wal encoding
+	update := func(offset int, vals ...string) error {
+		obj := &EnumObject{
+			cat:    cat,
+			id:     enum.Tag(),
+			name:   enum.Name(),
+			vals:   vals,
+			offset: offset,
+			action: ALTER,
+		}
+		buf, err := obj.Encode()
+		require.NoError(t, err)
+		dec := &EnumObject{cat: cat}
+		require.NoError(t, dec.Decode(ctx, &wal.Record{Type: ALTER, Data: [][]byte{buf}}))
+		require.Equal(t, obj.offset, dec.offset)
+		require.Equal(t, obj.vals, dec.vals)
+		tctx, _, commit, abort, err := eng.WithTransaction(ctx)
+		require.NoError(t, err)
+		defer abort()
+		if err := dec.Update(tctx); err != nil {
+			return err
+		}
+		return commit()
+	}
+	require.NoError(t, update(0, "a", "b"))
+	require.NoError(t, update(2, "c"))
+
+	// replayed and overlapping records only append missing values
+	require.NoError(t, update(0, "a", "b"))
+	require.NoError(t, update(1, "b", "c", "d"))
+
+	// gaps are rejected
+	require.ErrorIs(t, update(5, "f"), ErrDatabaseCorrupt)
+
+	tctx, _, _, abort, err = eng.WithTransaction(ctx)
+	require.NoError(t, err)
+	defer abort()
+	enum2, err := cat.GetEnum(tctx, enum.Tag())
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "c", "d"}, enum2.Values())
+}
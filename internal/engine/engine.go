@@ -18,6 +18,7 @@ import (
 	"blockwatch.cc/knoxdb/internal/block"
 	"blockwatch.cc/knoxdb/internal/types"
 	"blockwatch.cc/knoxdb/internal/wal"
+	"blockwatch.cc/knoxdb/pkg/cache/disk"
 	"blockwatch.cc/knoxdb/pkg/schema"
 	"blockwatch.cc/knoxdb/pkg/store"
 	"blockwatch.cc/knoxdb/pkg/util"
@@ -69,8 +70,11 @@ type Engine struct {
 	cat      *Catalog                               // objects, identities, configurations
 	cache    CacheManager                           // block and buffer caches
 	tables   *util.LockFreeMap[uint64, TableEngine] // table objects
+	rollups  *util.LockFreeMap[uint64, uint64]      // rollup table tag => source table tag
 	indexes  *util.LockFreeMap[uint64, IndexEngine] // index objects
+	builds   *util.LockFreeMap[uint64, *IndexBuild] // running index builds
 	enums    *schema.EnumRegistry                   // enum objects
+	dicts    *util.LockFreeMap[uint64, int]         // logged dictionary sizes
 	opts     Options                                // engine-wide configuration
 	txchan   chan struct{}                          // single writer enforcement
 	txs      TxList                                 // active read transactions
@@ -91,13 +95,26 @@ type CacheManager struct {
 	// generic buffer cache
 	// - store engine: 64bit store id + 64bit user key
 	buffers BufferCache
+	// optional persistent second tier block cache, same keys as blocks
+	disk *disk.Cache
 }
 
+// PurgeCache clears in-memory caches. The persistent block cache tier is
+// kept because it remains valid across restarts.
 func (e *Engine) PurgeCache() {
 	e.cache.blocks.Purge()
 	e.cache.buffers.Purge()
 }
 
+// PurgeBlockCache removes all cached blocks of table or index key from
+// memory and disk cache tiers.
+func (e *Engine) PurgeBlockCache(key uint64) {
+	e.cache.blocks.Partition(key).Purge()
+	if e.cache.disk != nil {
+		e.cache.disk.Purge(key)
+	}
+}
+
 func (e *Engine) RootPath() string {
 	return e.path
 }
@@ -122,6 +139,23 @@ func (e *Engine) BlockCache(key uint64) block.BlockCachePartition {
 	return e.cache.blocks.Partition(key)
 }
 
+// DiskCache returns the persistent second tier block cache partition for
+// table or index key or nil when the tier is disabled.
+func (e *Engine) DiskCache(key uint64) *block.DiskCachePartition {
+	if e.cache.disk == nil {
+		return nil
+	}
+	return &block.DiskCachePartition{Partition: e.cache.disk.Partition(key)}
+}
+
+// DiskCacheStats returns statistics of the persistent block cache tier.
+func (e *Engine) DiskCacheStats() disk.CacheStats {
+	if e.cache.disk == nil {
+		return disk.CacheStats{}
+	}
+	return e.cache.disk.Stats()
+}
+
 func (e *Engine) BufferCache(key uint64) BufferCachePartition {
 	return e.cache.buffers.Partition(key)
 }
@@ -184,7 +218,15 @@ func Drop(name string, options ...Option) error {
 
 	// remove wal (and other outstanding files)
 	opts.Log.Infof("[db:%s] dropping wal and temp files", name)
-	return os.RemoveAll(dir)
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+
+	// remove disk cache tier
+	if opts.DiskCachePath != "" {
+		return os.RemoveAll(filepath.Join(opts.DiskCachePath, name))
+	}
+	return nil
 }
 
 func prepareDatabaseDirectory(name string, opts Options) error {
@@ -240,8 +282,11 @@ func Create(ctx context.Context, name string, options ...Option) (*Engine, error
 			buffers: NewBufferCache(0),
 		},
 		tables:  util.NewLockFreeMap[uint64, TableEngine](),
+		rollups: util.NewLockFreeMap[uint64, uint64](),
 		indexes: util.NewLockFreeMap[uint64, IndexEngine](),
+		builds:  util.NewLockFreeMap[uint64, *IndexBuild](),
 		enums:   schema.NewEnumRegistry(),
+		dicts:   util.NewLockFreeMap[uint64, int](),
 		txs:     make(TxList, 0),
 		txchan:  make(chan struct{}, 1),
 		xmin:    1,
@@ -307,6 +352,9 @@ func Create(ctx context.Context, name string, options ...Option) (*Engine, error
 		e.cache.blocks = block.NewCache(opts.CacheSize * 90 / 10)
 		e.cache.buffers = NewBufferCache(opts.CacheSize / 10)
 	}
+	if err = e.openDiskCache(name, true); err != nil {
+		return nil, err
+	}
 
 	// start services
 	e.tasks.Start()
@@ -338,8 +386,11 @@ func Open(ctx context.Context, name string, options ...Option) (*Engine, error)
 			buffers: NewBufferCache(0),
 		},
 		tables:  util.NewLockFreeMap[uint64, TableEngine](),
+		rollups: util.NewLockFreeMap[uint64, uint64](),
 		indexes: util.NewLockFreeMap[uint64, IndexEngine](),
+		builds:  util.NewLockFreeMap[uint64, *IndexBuild](),
 		enums:   schema.NewEnumRegistry(),
+		dicts:   util.NewLockFreeMap[uint64, int](),
 		txs:     make(TxList, 0),
 		txchan:  make(chan struct{}, 1),
 		xmin:    1,
@@ -450,6 +501,9 @@ func Open(ctx context.Context, name string, options ...Option) (*Engine, error)
 		e.cache.blocks = block.NewCache(e.opts.CacheSize * 90 / 10)
 		e.cache.buffers = NewBufferCache(e.opts.CacheSize / 10)
 	}
+	if err = e.openDiskCache(name, false); err != nil {
+		return nil, err
+	}
 
 	// start services (enables background merge during wal replay)
 	e.tasks.Start()
@@ -473,6 +527,28 @@ func Open(ctx context.Context, name string, options ...Option) (*Engine, error)
 	return e, nil
 }
 
+// openDiskCache opens the persistent block cache tier in a subdirectory
+// per database. Cache contents of a new database are discarded because
+// a database of the same name may have existed before.
+func (e *Engine) openDiskCache(name string, create bool) error {
+	if e.opts.DiskCacheSize <= 0 || e.opts.DiskCachePath == "" {
+		return nil
+	}
+	path := filepath.Join(e.opts.DiskCachePath, name)
+	if create {
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+	}
+	c, err := disk.Open(path, int64(e.opts.DiskCacheSize))
+	if err != nil {
+		return fmt.Errorf("open disk cache: %w", err)
+	}
+	e.cache.disk = c
+	e.log.Debugf("open disk cache at %q with %d entries", path, c.Len())
+	return nil
+}
+
 func (e *Engine) Close(ctx context.Context) error {
 	e.log.Debugf("close database %s at %s", e.cat.name, e.path)
 
@@ -505,6 +581,7 @@ func (e *Engine) Close(ctx context.Context) error {
 		e.tasks.Stop()
 		e.tasks = nil
 	}
+	e.builds.Clear()
 
 	// wait for transactions and services to release all locks
 	e.log.Trace("wait LM")
@@ -545,6 +622,7 @@ func (e *Engine) Close(ctx context.Context) error {
 		}
 	}
 	e.tables.Clear()
+	e.rollups.Clear()
 
 	// close enums
 	e.log.Trace("close enums")
@@ -552,6 +630,7 @@ func (e *Engine) Close(ctx context.Context) error {
 		schema.UnregisterEnum(e.dbId, enum)
 	}
 	e.enums.Clear()
+	e.dicts.Clear()
 
 	// close catalog (set checkpoint)
 	if e.cat != nil {
@@ -649,6 +728,7 @@ func (e *Engine) ForceShutdown() error {
 		schema.UnregisterEnum(e.dbId, enum)
 	}
 	e.enums.Clear()
+	e.dicts.Clear()
 
 	ctx := context.Background()
 
@@ -671,6 +751,7 @@ func (e *Engine) ForceShutdown() error {
 		}
 	}
 	e.tables.Clear()
+	e.rollups.Clear()
 
 	// release directory lock
 	if e.flock != nil {
@@ -865,3 +946,36 @@ func (e *Engine) RunGC(ctx context.Context) error {
 	}
 	return nil
 }
+
+// Checkpoint writes new catalog and table checkpoints regardless of their
+// current distance to the end of the WAL. Tables make their checkpoints
+// durable with the next journal merge.
+func (e *Engine) Checkpoint(ctx context.Context) error {
+	if e.IsReadOnly() {
+		return ErrDatabaseReadOnly
+	}
+	ctx = WithEngine(ctx, e)
+	if err := e.cat.doCheckpoint(ctx); err != nil {
+		return fmt.Errorf("checkpoint catalog: %v", err)
+	}
+	for _, t := range e.tables.Map() {
+		if err := t.Checkpoint(ctx); err != nil {
+			return fmt.Errorf("checkpoint table %s: %v", t.Schema().Name, err)
+		}
+	}
+	if err := e.wal.Sync(); err != nil {
+		return fmt.Errorf("sync wal: %v", err)
+	}
+	return nil
+}
+
+// GC drops WAL segments below the current watermark which are no longer
+// required for crash recovery.
+func (e *Engine) GC(ctx context.Context) error {
+	if e.IsReadOnly() {
+		return ErrDatabaseReadOnly
+	}
+	lsn := e.Watermark()
+	e.log.Debugf("gc: drop wal segments before LSN 0x%016x", lsn)
+	return e.wal.GC(lsn)
+}
@@ -45,6 +45,7 @@ func NewTestEngine(t testing.TB, opts Options) *Engine {
 		},
 		tables:  util.NewLockFreeMap[uint64, TableEngine](),
 		indexes: util.NewLockFreeMap[uint64, IndexEngine](),
+		builds:  util.NewLockFreeMap[uint64, *IndexBuild](),
 		enums:   schema.NewEnumRegistry(),
 		txs:     make(TxList, 0),
 		txchan:  make(chan struct{}, 1),
@@ -81,6 +82,7 @@ func OpenTestEngine(t testing.TB, opts Options) *Engine {
 		},
 		tables:  util.NewLockFreeMap[uint64, TableEngine](),
 		indexes: util.NewLockFreeMap[uint64, IndexEngine](),
+		builds:  util.NewLockFreeMap[uint64, *IndexBuild](),
 		enums:   schema.NewEnumRegistry(),
 		txs:     make(TxList, 0),
 		txchan:  make(chan struct{}, 1),
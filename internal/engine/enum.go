@@ -124,6 +124,7 @@ func (e *Engine) DropEnum(ctx context.Context, name string) error {
 	// register commit callback
 	tx.OnCommit(func(ctx context.Context) error {
 		e.enums.Del(tag)
+		e.dicts.Del(tag)
 		return nil
 	})
 
@@ -179,6 +180,39 @@ func (e *Engine) ExtendEnum(ctx context.Context, name string, vals ...string) er
 	return commit()
 }
 
+// LogEnums writes values which record encoders have appended to dictionaries
+// of schema s to the WAL. Table engines call this on insert and update inside
+// the write transaction, so dictionary values become durable together with
+// records that use them. Writers are serialized, hence logged values always
+// extend the values that are already logged. On abort the same values are
+// logged again by the next writer.
+func (e *Engine) LogEnums(ctx context.Context, s *schema.Schema) error {
+	for _, f := range s.Fields {
+		if !f.IsDict() || !f.IsActive() {
+			continue
+		}
+		enum, ok := e.enums.Lookup(f.Name)
+		if !ok {
+			return ErrNoEnum
+		}
+		tag := enum.Tag()
+		n, _ := e.dicts.Get(tag)
+		vals := enum.ValuesFrom(n)
+		if len(vals) == 0 {
+			continue
+		}
+		if err := e.cat.AppendEnumValuesCmd(ctx, enum, n, vals); err != nil {
+			return err
+		}
+		e.dicts.Put(tag, n+len(vals))
+		GetTx(ctx).OnAbort(func(_ context.Context) error {
+			e.dicts.Put(tag, n)
+			return nil
+		})
+	}
+	return nil
+}
+
 func (e *Engine) openEnums(ctx context.Context) error {
 	// iterate catalog
 	keys, err := e.cat.ListEnums(ctx)
@@ -193,6 +227,7 @@ func (e *Engine) openEnums(ctx context.Context) error {
 		}
 		e.log.Debugf("loaded enum %s key=0x%016x n=%d", enum.Name(), key, enum.Len())
 		e.enums.Put(key, enum)
+		e.dicts.Put(key, enum.Len())
 	}
 
 	return nil
@@ -40,6 +40,8 @@ var (
 	ErrTableDropWithRefs = errors.New("table is referenced")
 	ErrTableReadOnly     = errors.New("table is read-only")
 	ErrTableNotEmpty     = errors.New("table is not empty")
+	ErrPkOutOfOrder      = errors.New("primary key out of order")
+	ErrTableNotNew       = errors.New("table not created in transaction")
 
 	ErrTxConflict     = errors.New("transaction conflict")
 	ErrTxReadonly     = errors.New("transaction is read-only")
@@ -5,7 +5,10 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 
 	"blockwatch.cc/knoxdb/internal/types"
 	"blockwatch.cc/knoxdb/pkg/schema"
@@ -14,6 +17,97 @@ import (
 
 var indexEngineRegistry = make(map[IndexKind]IndexFactory)
 
+// IndexStatus describes whether an index is usable for queries. Indexes
+// created on non-empty tables start in building state and become ready once
+// a background task has indexed all existing table rows. New writes are
+// indexed during table merge irrespective of status.
+type IndexStatus byte
+
+const (
+	IndexStatusReady IndexStatus = iota
+	IndexStatusBuilding
+)
+
+func (s IndexStatus) String() string {
+	switch s {
+	case IndexStatusReady:
+		return "ready"
+	case IndexStatusBuilding:
+		return "building"
+	default:
+		return "invalid"
+	}
+}
+
+// IndexBuild tracks a running background index build. Index engines report
+// progress while they scan the table. Builds can be cancelled and will resume
+// from their last checkpoint on the next database open.
+type IndexBuild struct {
+	mu      sync.Mutex
+	task    *Task
+	cancel  context.CancelFunc
+	stopped bool
+	done    atomic.Uint64
+	total   atomic.Uint64
+}
+
+// SetProgress is called by index engines to report the number of
+// processed and total table rows.
+func (b *IndexBuild) SetProgress(done, total uint64) {
+	b.done.Store(done)
+	b.total.Store(total)
+}
+
+// Progress returns build progress as fraction between 0 and 1.
+func (b *IndexBuild) Progress() float64 {
+	select {
+	case <-b.task.Done():
+		if b.task.Err() == nil {
+			return 1
+		}
+	default:
+	}
+	done, total := b.done.Load(), b.total.Load()
+	if total == 0 {
+		return 0
+	}
+	return min(float64(done)/float64(total), 1)
+}
+
+// Cancel stops a running build. A build that has not started yet
+// will never start.
+func (b *IndexBuild) Cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stopped = true
+	if b.cancel != nil {
+		b.cancel()
+	}
+}
+
+// Wait blocks until the build completes or the context is cancelled
+// and returns the build error.
+func (b *IndexBuild) Wait(ctx context.Context) error {
+	select {
+	case <-b.task.Done():
+		return b.task.Err()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *IndexBuild) run(ctx context.Context, fn func(context.Context) error) error {
+	b.mu.Lock()
+	if b.stopped {
+		b.mu.Unlock()
+		return ErrTaskAborted
+	}
+	ctx, b.cancel = context.WithCancel(ctx)
+	b.mu.Unlock()
+	defer b.cancel()
+	return fn(ctx)
+}
+
 func RegisterIndexFactory(n IndexKind, fn IndexFactory) {
 	if _, ok := indexEngineRegistry[n]; ok {
 		panic(fmt.Errorf("knox: index engine %s factory already registered", n))
@@ -114,8 +208,15 @@ func (e *Engine) CreateIndex(ctx context.Context, s *schema.IndexSchema, options
 		return nil, err
 	}
 
+	// indexes on non-empty tables are built in the background
+	status := IndexStatusReady
+	if table.State().NRows > 0 {
+		status = IndexStatusBuilding
+	}
+	index.SetStatus(status)
+
 	// schedule create
-	if err := e.cat.AppendIndexCmd(ctx, CREATE, s, opts); err != nil {
+	if err := e.cat.AppendIndexCmd(ctx, CREATE, s, opts, status); err != nil {
 		return nil, err
 	}
 
@@ -132,9 +233,6 @@ func (e *Engine) CreateIndex(ctx context.Context, s *schema.IndexSchema, options
 		// register
 		e.indexes.Put(tag, index)
 
-		// TODO: rebuild in background
-		// index.Rebuild(ctx)
-
 		return nil
 	})
 
@@ -143,6 +241,14 @@ func (e *Engine) CreateIndex(ctx context.Context, s *schema.IndexSchema, options
 		return nil, err
 	}
 
+	// index existing table rows in background, a failure to schedule
+	// is not fatal because builds resume when the database opens
+	if status == IndexStatusBuilding && !e.IsReadOnly() {
+		if err := e.buildIndex(index); err != nil {
+			e.log.Warnf("index %s: schedule build: %v", s.Name, err)
+		}
+	}
+
 	return index, nil
 }
 
@@ -151,8 +257,16 @@ func (e *Engine) RebuildIndex(ctx context.Context, name string) error {
 	if err != nil {
 		return err
 	}
+	if e.IsReadOnly() {
+		return ErrDatabaseReadOnly
+	}
+
+	// stop a running build first
+	if err := e.CancelIndexBuild(ctx, name); err != nil {
+		return err
+	}
 
-	// start read tx (required for table scan and table lock)
+	// start tx (required for table lock and catalog update)
 	ctx, tx, commit, abort, err := e.WithTransaction(ctx)
 	if err != nil {
 		return err
@@ -166,35 +280,83 @@ func (e *Engine) RebuildIndex(ctx context.Context, name string) error {
 		return err
 	}
 
-	// temporarily remove index from table to make it unavailable for queries
-	idx.Table().DisconnectIndex(idx)
+	// hide index from queries until the rebuild completes
+	tag := types.TaggedHash(types.ObjectTagIndex, name)
+	if err := e.cat.PutIndexStatus(ctx, tag, IndexStatusBuilding); err != nil {
+		return err
+	}
+	idx.SetStatus(IndexStatusBuilding)
 
-	// (set index state)
+	// truncate index (will block as long as there are active backend readers)
+	if err := idx.Truncate(ctx); err != nil {
+		return err
+	}
+
+	// commit tx (will release table read lock)
+	if err := commit(); err != nil {
+		return err
+	}
 
 	// schedule index rebuild as background task
-	ok := e.tasks.Submit(NewTask(func(ctx context.Context) error {
-		// truncate index (will block as long as there are active backend readers)
-		if err := idx.Truncate(ctx); err != nil {
+	return e.buildIndex(idx)
+}
+
+// GetIndexBuild returns the running background build for the named index.
+func (e *Engine) GetIndexBuild(name string) (*IndexBuild, bool) {
+	return e.builds.Get(types.TaggedHash(types.ObjectTagIndex, name))
+}
+
+// CancelIndexBuild stops a running background build and waits for it to exit.
+// The index stays in building state and its build resumes on next open.
+func (e *Engine) CancelIndexBuild(ctx context.Context, name string) error {
+	b, ok := e.GetIndexBuild(name)
+	if !ok {
+		return nil
+	}
+	b.Cancel()
+	err := b.Wait(ctx)
+	switch {
+	case err == nil, errors.Is(err, ErrTaskAborted), errors.Is(err, context.Canceled):
+		return nil
+	default:
+		return err
+	}
+}
+
+// buildIndex schedules a background task that indexes all existing table
+// rows and marks the index ready on success.
+func (e *Engine) buildIndex(idx IndexEngine) error {
+	name := idx.IndexSchema().Name
+	tag := types.TaggedHash(types.ObjectTagIndex, name)
+	b := &IndexBuild{}
+	b.task = NewTask(func(ctx context.Context) error {
+		// unregister after the status update so progress never drops
+		defer e.builds.Del(tag)
+		e.log.Debugf("index %s: build started", name)
+		err := b.run(ctx, func(ctx context.Context) error {
+			return idx.Build(ctx, b)
+		})
+		if err != nil {
+			if !errors.Is(err, ErrTaskAborted) && !errors.Is(err, context.Canceled) {
+				e.log.Errorf("index %s: build failed: %v", name, err)
+			}
 			return err
 		}
 
-		// rebuild index
-		if err := idx.Rebuild(ctx); err != nil {
+		// persist ready status and make index available for queries
+		if err := e.cat.PutIndexStatus(ctx, tag, IndexStatusReady); err != nil {
+			e.log.Errorf("index %s: set status: %v", name, err)
 			return err
 		}
-
-		// (set index state)
-
-		// re-add index to table signalling its ready to use again
-		idx.Table().ConnectIndex(idx)
-
-		// commit tx (will release table read lock)
-		return commit()
-	}))
-	if !ok {
+		idx.SetStatus(IndexStatusReady)
+		e.log.Debugf("index %s: build complete", name)
+		return nil
+	})
+	e.builds.Put(tag, b)
+	if !e.tasks.Submit(b.task) {
+		e.builds.Del(tag)
 		return ErrTooManyTasks
 	}
-
 	return nil
 }
 
@@ -206,7 +368,10 @@ func (e *Engine) DropIndex(ctx context.Context, name string) error {
 		return ErrNoIndex
 	}
 
-	// TODO: stop index build if running
+	// stop index build if running
+	if err := e.CancelIndexBuild(ctx, name); err != nil {
+		return err
+	}
 
 	// start transaction and amend context
 	ctx, tx, commit, abort, err := e.WithTransaction(ctx)
@@ -223,7 +388,7 @@ func (e *Engine) DropIndex(ctx context.Context, name string) error {
 	}
 
 	// write wal and schedule drop on commit
-	if err := e.cat.AppendIndexCmd(ctx, DROP, index.IndexSchema(), Options{}); err != nil {
+	if err := e.cat.AppendIndexCmd(ctx, DROP, index.IndexSchema(), Options{}, index.Status()); err != nil {
 		return err
 	}
 
@@ -281,6 +446,11 @@ func (e *Engine) openIndexes(ctx context.Context, table TableEngine, ts *schema.
 		if err := idx.Open(ctx, table, s, opts.IndexOptions()...); err != nil {
 			return err
 		}
+		status, err := e.cat.GetIndexStatus(ctx, key)
+		if err != nil {
+			return err
+		}
+		idx.SetStatus(status)
 		table.ConnectIndex(idx)
 		itag := types.TaggedHash(types.ObjectTagIndex, s.Name)
 		e.indexes.Put(itag, idx)
@@ -288,3 +458,19 @@ func (e *Engine) openIndexes(ctx context.Context, table TableEngine, ts *schema.
 
 	return nil
 }
+
+// resumeIndexBuilds schedules builds for all table indexes which have not
+// finished building before the database was closed.
+func (e *Engine) resumeIndexBuilds(table TableEngine) {
+	if e.IsReadOnly() {
+		return
+	}
+	for _, idx := range e.indexes.Map() {
+		if idx.Table() != table || idx.Status() != IndexStatusBuilding {
+			continue
+		}
+		if err := e.buildIndex(idx); err != nil {
+			e.log.Warnf("index %s: resume build: %v", idx.IndexSchema().Name, err)
+		}
+	}
+}
@@ -4,7 +4,11 @@
 package engine
 
 import (
+	"context"
+	"errors"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestTableIndexCreate(t *testing.T) {
@@ -12,3 +16,71 @@ func TestTableIndexCreate(t *testing.T) {
 
 func TestTableIndexDrop(t *testing.T) {
 }
+
+func TestIndexBuildProgress(t *testing.T) {
+	b := &IndexBuild{}
+	b.task = NewTask(func(ctx context.Context) error {
+		return b.run(ctx, func(context.Context) error { return nil })
+	})
+
+	// unknown total
+	require.Equal(t, 0.0, b.Progress())
+
+	// fraction of processed rows, capped at 1 while running
+	b.SetProgress(25, 100)
+	require.Equal(t, 0.25, b.Progress())
+	b.SetProgress(150, 100)
+	require.Equal(t, 1.0, b.Progress())
+
+	// completed builds report 1
+	b.SetProgress(0, 100)
+	b.task.complete(b.task.run(context.Background()))
+	require.Equal(t, 1.0, b.Progress())
+	require.NoError(t, b.Wait(context.Background()))
+
+	// failed builds report their last progress
+	fail := errors.New("fail")
+	b = &IndexBuild{task: NewTask(nil)}
+	b.SetProgress(40, 100)
+	b.task.complete(fail)
+	require.Equal(t, 0.4, b.Progress())
+	require.ErrorIs(t, b.Wait(context.Background()), fail)
+}
+
+func TestIndexBuildCancel(t *testing.T) {
+	// cancel before start never runs the build
+	var started bool
+	b := &IndexBuild{}
+	b.task = NewTask(func(ctx context.Context) error {
+		return b.run(ctx, func(context.Context) error {
+			started = true
+			return nil
+		})
+	})
+	b.Cancel()
+	b.task.complete(b.task.run(context.Background()))
+	require.False(t, started)
+	require.ErrorIs(t, b.Wait(context.Background()), ErrTaskAborted)
+	require.Equal(t, 0.0, b.Progress())
+
+	// cancel while running stops the build context
+	running := make(chan struct{})
+	b = &IndexBuild{}
+	b.task = NewTask(func(ctx context.Context) error {
+		return b.run(ctx, func(ctx context.Context) error {
+			close(running)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	})
+	go func() { b.task.complete(b.task.run(context.Background())) }()
+	<-running
+	b.Cancel()
+	require.ErrorIs(t, b.Wait(context.Background()), context.Canceled)
+
+	// wait respects the caller context
+	b = &IndexBuild{task: NewTask(nil)}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, b.Wait(ctx), context.Canceled)
+}
@@ -8,6 +8,7 @@ import (
 	"iter"
 
 	"blockwatch.cc/knoxdb/internal/pack"
+	"blockwatch.cc/knoxdb/internal/rollup"
 	"blockwatch.cc/knoxdb/internal/types"
 	"blockwatch.cc/knoxdb/internal/xroar"
 	"blockwatch.cc/knoxdb/pkg/schema"
@@ -32,6 +33,7 @@ const (
 	TableKindPack    = "pack"
 	TableKindLSM     = "lsm"
 	TableKindHistory = "history"
+	TableKindRollup  = "rollup"
 )
 
 type TableFactory func() TableEngine
@@ -52,6 +54,7 @@ type TableEngine interface {
 	Compact(Context) error
 	Truncate(Context) error
 	Checkpoint(Context) error
+	Flush(Context) error
 
 	// data ingress
 	InsertRows(Context, []byte) (uint64, int, error) // wire encoded rows
@@ -65,6 +68,7 @@ type TableEngine interface {
 	Count(Context, QueryPlan) (int, error)
 	Delete(Context, QueryPlan) (int, error)
 	Stream(Context, QueryPlan, func(QueryRow) error) error
+	StreamPacks(Context, QueryPlan, func(*Package) error) error
 
 	// index management
 	ConnectIndex(QueryableIndex)
@@ -81,6 +85,13 @@ type TableEngine interface {
 	NewWriter(uint32) TableWriter
 }
 
+// RollupBuilder is implemented by table engines that maintain rollup
+// tables during journal merge. BuildRollup fills an empty rollup table
+// from all merged table rows.
+type RollupBuilder interface {
+	BuildRollup(Context, *rollup.Rollup, TableEngine) error
+}
+
 type ReadMode byte
 
 const (
@@ -138,6 +149,8 @@ type QueryableIndex interface {
 	Schema() *Schema
 	IsComposite() bool
 	IsPk() bool
+	IsReady() bool
+	Predicate() QueryCondition // compiled partial index predicate or nil
 	CanMatch(QueryCondition) bool
 	Query(Context, QueryCondition) (*Bitmap, bool, error)
 	QueryComposite(Context, QueryCondition) (*Bitmap, bool, error)
@@ -167,8 +180,7 @@ type QueryResult interface {
 	SortBy(name string, order OrderType)
 	Iterator() iter.Seq2[int, QueryRow]
 	Value(int, int) any
-	// Column(name string) (any, error)
-	// TODO: Chunk and Vector access
+	Format(int, int) string
 }
 
 type QueryRow interface {
@@ -203,6 +215,11 @@ type IndexEngine interface {
 	Rebuild(Context) error
 	Sync(Context) error
 
+	// background build
+	Status() IndexStatus
+	SetStatus(IndexStatus)
+	Build(Context, *IndexBuild) error
+
 	// data ingress from table merge
 	AddPack(Context, *Package, WriteMode) error
 	DelPack(Context, *Package, WriteMode, uint32) error
@@ -212,6 +229,8 @@ type IndexEngine interface {
 	// data egress
 	IsComposite() bool
 	IsPk() bool
+	IsReady() bool
+	Predicate() QueryCondition    // compiled partial index predicate or nil
 	CanMatch(QueryCondition) bool // static: based to index engine type
 	Query(Context, QueryCondition) (*Bitmap, bool, error)
 	QueryComposite(Context, QueryCondition) (*Bitmap, bool, error)
@@ -22,6 +22,9 @@ const (
 	CREATE = wal.RecordTypeInsert
 	ALTER  = wal.RecordTypeUpdate
 	DROP   = wal.RecordTypeDelete
+
+	// max number of enum values per wal record
+	maxEnumRecordValues = 1<<16 - 1
 )
 
 type Object interface {
@@ -158,6 +161,7 @@ type EnumObject struct {
 	cat    *Catalog
 	name   string
 	vals   []string
+	offset int // code of the first value
 }
 
 func (c *Catalog) AppendEnumCmd(ctx context.Context, act ActionType, e *schema.EnumDictionary) error {
@@ -171,6 +175,28 @@ func (c *Catalog) AppendEnumCmd(ctx context.Context, act ActionType, e *schema.E
 	return c.append(ctx, obj)
 }
 
+// AppendEnumValuesCmd logs values appended to a dictionary starting at
+// code offset. Large updates are split into multiple records.
+func (c *Catalog) AppendEnumValuesCmd(ctx context.Context, e *schema.EnumDictionary, offset int, vals []string) error {
+	for len(vals) > 0 {
+		n := min(len(vals), maxEnumRecordValues)
+		obj := &EnumObject{
+			cat:    c,
+			id:     e.Tag(),
+			name:   e.Name(),
+			vals:   vals[:n],
+			offset: offset,
+			action: ALTER,
+		}
+		if err := c.append(ctx, obj); err != nil {
+			return err
+		}
+		vals = vals[n:]
+		offset += n
+	}
+	return nil
+}
+
 func (o *EnumObject) Id() uint64 {
 	return o.id
 }
@@ -193,9 +219,26 @@ func (o *EnumObject) Drop(ctx context.Context) error {
 	return o.cat.DropEnum(ctx, o.id)
 }
 
+// Update appends values to the stored dictionary. Values are append-only,
+// so values before offset and values already stored (e.g. on replay) are
+// skipped.
 func (o *EnumObject) Update(ctx context.Context) error {
-	enum := schema.NewEnumDictionary(o.name)
-	_ = enum.Append(o.vals...)
+	enum, err := o.cat.GetEnum(ctx, o.id)
+	if err != nil {
+		return err
+	}
+	n := enum.Len()
+	switch {
+	case n < o.offset:
+		return ErrDatabaseCorrupt
+	case n >= o.offset+len(o.vals):
+		return nil
+	}
+	for _, v := range o.vals[n-o.offset:] {
+		if _, err := enum.Put(v); err != nil {
+			return err
+		}
+	}
 	return o.cat.PutEnum(ctx, enum)
 }
 
@@ -215,12 +258,18 @@ func (o *EnumObject) Encode() ([]byte, error) {
 	}
 
 	// write values
+	if len(o.vals) > maxEnumRecordValues {
+		return nil, schema.ErrEnumFull
+	}
 	binary.Write(buf, LE, uint16(len(o.vals)))
 	for _, v := range o.vals {
 		binary.Write(buf, LE, uint16(len(v)))
 		buf.WriteString(v)
 	}
 
+	// write offset of first value
+	binary.Write(buf, LE, uint32(o.offset))
+
 	return buf.Bytes(), nil
 }
 
@@ -252,6 +301,11 @@ func (o *EnumObject) Decode(ctx context.Context, rec *wal.Record) error {
 		o.vals[i] = string(buf.Next(n))
 	}
 
+	// read offset (older records have none)
+	if buf.Len() >= 4 {
+		o.offset = int(LE.Uint32(buf.Next(4)))
+	}
+
 	return nil
 }
 
@@ -263,9 +317,10 @@ type IndexObject struct {
 	table  string
 	schema *schema.IndexSchema
 	opts   Options
+	status IndexStatus
 }
 
-func (c *Catalog) AppendIndexCmd(ctx context.Context, act ActionType, s *schema.IndexSchema, opts Options) error {
+func (c *Catalog) AppendIndexCmd(ctx context.Context, act ActionType, s *schema.IndexSchema, opts Options, status IndexStatus) error {
 	obj := &IndexObject{
 		cat:    c,
 		id:     s.TaggedHash(types.ObjectTagIndex),
@@ -273,6 +328,7 @@ func (c *Catalog) AppendIndexCmd(ctx context.Context, act ActionType, s *schema.
 		opts:   opts,
 		table:  s.Base.Name,
 		action: act,
+		status: status,
 	}
 	return c.append(ctx, obj)
 }
@@ -291,7 +347,7 @@ func (o *IndexObject) Type() types.ObjectTag {
 
 func (o *IndexObject) Create(ctx context.Context) error {
 	tkey := types.TaggedHash(types.ObjectTagTable, o.table)
-	return o.cat.AddIndex(ctx, o.id, tkey, o.schema, o.opts)
+	return o.cat.AddIndex(ctx, o.id, tkey, o.schema, o.opts, o.status)
 }
 
 func (o *IndexObject) Drop(ctx context.Context) error {
@@ -338,6 +394,9 @@ func (o *IndexObject) Encode() ([]byte, error) {
 	binary.Write(buf, LE, uint32(len(b)))
 	buf.Write(b)
 
+	// write build status
+	buf.WriteByte(byte(o.status))
+
 	return buf.Bytes(), nil
 }
 
@@ -379,5 +438,10 @@ func (o *IndexObject) Decode(ctx context.Context, rec *wal.Record) error {
 	if err != nil {
 		return err
 	}
+
+	// read build status (missing in records written by older versions)
+	if buf.Len() > 0 {
+		o.status = IndexStatus(buf.Next(1)[0])
+	}
 	return nil
 }
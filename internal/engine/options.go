@@ -20,6 +20,8 @@ type Options struct {
 	Namespace       string           // unique db identifier
 	Path            string           // local filesystem
 	CacheSize       int              // in bytes
+	DiskCachePath   string           `knox:"-"` // local directory for second tier block cache
+	DiskCacheSize   int              `knox:"-"` // in bytes, zero disables the second tier
 	WalSegmentSize  int              // wal file size
 	WalRecoveryMode wal.RecoveryMode // howto recover from wal damage
 	LockTimeout     time.Duration    // lock manager timeout
@@ -68,6 +70,7 @@ func (o Options) DatabaseOptions() []Option {
 		WithNamespace(o.Namespace),
 		WithPath(o.Path),
 		WithCacheSize(o.CacheSize),
+		WithDiskCache(o.DiskCachePath, o.DiskCacheSize),
 		WithWalSegmentSize(o.WalSegmentSize),
 		WithWalRecoveryMode(o.WalRecoveryMode),
 		WithLockTimeout(o.LockTimeout),
@@ -180,6 +183,17 @@ func WithCacheSize(n int) Option {
 	}
 }
 
+// WithDiskCache enables a persistent second tier block cache of sz bytes
+// below directory path. The tier keeps blocks read from storage on local
+// disk across restarts which speeds up cold queries when database files
+// live on slow or network storage.
+func WithDiskCache(path string, sz int) Option {
+	return func(o *Options) {
+		o.DiskCachePath = path
+		o.DiskCacheSize = sz
+	}
+}
+
 func WithWalSegmentSize(sz int) Option {
 	return func(o *Options) {
 		if sz > 0 {
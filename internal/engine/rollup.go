@@ -0,0 +1,130 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"blockwatch.cc/knoxdb/internal/rollup"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/schema"
+)
+
+// IsRollupTable returns true when name is a table of kind rollup.
+func (e *Engine) IsRollupTable(name string) bool {
+	_, ok := e.rollups.Get(types.TaggedHash(types.ObjectTagTable, name))
+	return ok
+}
+
+// RollupTables returns all rollup tables of source table name.
+func (e *Engine) RollupTables(name string) []TableEngine {
+	var (
+		res []TableEngine
+		src = types.TaggedHash(types.ObjectTagTable, name)
+	)
+	for tag, ref := range e.rollups.Map() {
+		if ref != src {
+			continue
+		}
+		if t, ok := e.tables.Get(tag); ok {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// linkRollups registers the source table of all rollup tables in the
+// catalog. Rollup table names start with their source table name, the
+// rollup definition must also be valid for the source table schema.
+func (e *Engine) linkRollups(schemas map[uint64]*schema.Schema, options map[uint64]Options) error {
+	for tag, rs := range schemas {
+		if options[tag].Engine != TableKindRollup {
+			continue
+		}
+		var src uint64
+		for stag, s := range schemas {
+			if options[stag].Engine == TableKindRollup {
+				continue
+			}
+			def, err := rollup.Parse(s.Name, rs)
+			if err != nil {
+				continue
+			}
+			if _, err := def.Schema(s); err != nil {
+				continue
+			}
+			src = stag
+			break
+		}
+		if src == 0 {
+			return fmt.Errorf("rollup %s: missing source table: %w", rs.Name, ErrNoTable)
+		}
+		e.rollups.Put(tag, src)
+	}
+	return nil
+}
+
+// CreateRollup creates a hidden rollup table for definition r and fills it
+// from all rows of the source table. The source table must not contain
+// journal data of open transactions, otherwise ErrAgain is returned. Once
+// created, journal merges keep the rollup up to date.
+func (e *Engine) CreateRollup(ctx context.Context, r *rollup.Rollup, options ...Option) (TableEngine, error) {
+	src, err := e.FindTable(r.Table)
+	if err != nil {
+		return nil, err
+	}
+	if e.IsRollupTable(r.Table) {
+		return nil, fmt.Errorf("%s: %w", r.Table, ErrInvalidObjectType)
+	}
+	builder, ok := src.(RollupBuilder)
+	if !ok {
+		return nil, fmt.Errorf("%s: rollups %w", r.Table, ErrNotImplemented)
+	}
+	s, err := r.Schema(src.Schema())
+	if err != nil {
+		return nil, err
+	}
+
+	// start transaction and amend context
+	ctx, tx, commit, abort, err := e.WithTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer abort()
+
+	// block source writers and merge pending journal data so that no
+	// merge can add partials before the rollup is filled
+	if err := tx.Lock(ctx, src.Schema().TaggedHash(types.ObjectTagTable)); err != nil {
+		return nil, err
+	}
+	if err := src.Flush(ctx); err != nil {
+		return nil, err
+	}
+
+	// create the rollup table, pk is always $rid
+	options = append(options, WithEngineType(TableKindRollup))
+	table, err := e.CreateTable(ctx, s, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	// link the rollup to its source table
+	rtag := s.TaggedHash(types.ObjectTagTable)
+	e.rollups.Put(rtag, src.Schema().TaggedHash(types.ObjectTagTable))
+	tx.OnAbort(func(ctx context.Context) error {
+		e.rollups.Del(rtag)
+		return nil
+	})
+
+	// aggregate merged source rows
+	if err := builder.BuildRollup(ctx, r, table); err != nil {
+		return nil, err
+	}
+
+	if err := commit(); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
@@ -16,7 +16,9 @@ var (
 	TombKeySuffix  = []byte("_tomb")  // tomb vectors bucket
 	EpochKeySuffix = []byte("_epoch") // epoch watermark bucket
 	StateKeySuffix = []byte("_state") // table state bucket
+	DictKeySuffix  = []byte("_dict")  // compression dictionary bucket
 	StateKey       = []byte("state")  // table state key
+	BuildKey       = []byte("build")  // index build checkpoint key
 )
 
 // ObjectState stores volatile state of database objects such as
@@ -6,6 +6,7 @@ package engine
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"blockwatch.cc/knoxdb/internal/types"
 	"blockwatch.cc/knoxdb/pkg/schema"
@@ -25,7 +26,10 @@ func (e *Engine) TableNames() []string {
 	tables := e.tables.Map()
 	names := make([]string, 0, len(tables))
 	for _, v := range tables {
-		names = append(names, v.Schema().Name)
+		// rollup tables are internal
+		if _, ok := e.rollups.Get(v.Schema().TaggedHash(types.ObjectTagTable)); !ok {
+			names = append(names, v.Schema().Name)
+		}
 	}
 	return names
 }
@@ -56,14 +60,24 @@ func (e *Engine) CreateTable(ctx context.Context, s *schema.Schema, options ...O
 		return nil, fmt.Errorf("%s: %v", s.Name, ErrTableExists)
 	}
 
-	// check enums exist and collect
-	enums := schema.NewEnumRegistry()
-	var err error
-	for _, n := range s.EnumNames() {
-		enum, ok := e.enums.Lookup(n)
+	// check enums exist and collect, create missing dictionaries
+	var (
+		enums = schema.NewEnumRegistry()
+		dicts []*schema.EnumDictionary
+		err   error
+	)
+	for _, f := range s.Fields {
+		if !f.IsEnum() {
+			continue
+		}
+		enum, ok := e.enums.Lookup(f.Name)
 		if !ok {
-			err = fmt.Errorf("missing enum %q", n)
-			break
+			if !f.IsDict() {
+				err = fmt.Errorf("missing enum %q", f.Name)
+				break
+			}
+			enum = schema.NewEnumDictionary(f.Name)
+			dicts = append(dicts, enum)
 		}
 		enums.Register(enum)
 	}
@@ -87,8 +101,8 @@ func (e *Engine) CreateTable(ctx context.Context, s *schema.Schema, options ...O
 	// handle table options
 	opts := defaultDatabaseOptions.Apply(options...)
 
-	// on history tables set pk to $rid, may clone & alter schema
-	if opts.Engine == TableKindHistory {
+	// on history and rollup tables set pk to $rid, may clone & alter schema
+	if opts.Engine == TableKindHistory || opts.Engine == TableKindRollup {
 		if s.PkId() != schema.MetaRid {
 			s, _ = s.ResetPk(schema.MetaRid)
 		}
@@ -117,6 +131,18 @@ func (e *Engine) CreateTable(ctx context.Context, s *schema.Schema, options ...O
 	}
 	defer abort()
 
+	// schedule dictionary create
+	for _, enum := range dicts {
+		if err := e.cat.AppendEnumCmd(ctx, CREATE, enum); err != nil {
+			return nil, err
+		}
+		e.enums.Put(enum.Tag(), enum)
+		tx.OnAbort(func(ctx context.Context) error {
+			e.enums.Del(enum.Tag())
+			return nil
+		})
+	}
+
 	// schedule create
 	if err := e.cat.AppendTableCmd(ctx, CREATE, s, opts); err != nil {
 		return nil, err
@@ -209,8 +235,8 @@ func (e *Engine) DropTable(ctx context.Context, name string) error {
 		return ErrNoTable
 	}
 
-	// must drop indexes first
-	if len(t.Indexes()) > 0 {
+	// must drop indexes and rollups first
+	if len(t.Indexes()) > 0 || len(e.RollupTables(name)) > 0 {
 		return ErrTableDropWithRefs
 	}
 
@@ -243,9 +269,10 @@ func (e *Engine) DropTable(ctx context.Context, name string) error {
 			e.log.Errorf("close table: %v", err)
 		}
 		e.tables.Del(tag)
+		e.rollups.Del(tag)
 
 		// clear caches
-		e.BlockCache(tag).Purge()
+		e.PurgeBlockCache(tag)
 
 		return nil
 	})
@@ -281,7 +308,19 @@ func (e *Engine) TruncateTable(ctx context.Context, name string) error {
 	}
 
 	// clear caches
-	e.BlockCache(tag).Purge()
+	e.PurgeBlockCache(tag)
+
+	// rollups aggregate table contents and become empty as well
+	for _, r := range e.RollupTables(name) {
+		rtag := r.Schema().TaggedHash(types.ObjectTagTable)
+		if err := tx.Lock(ctx, rtag); err != nil {
+			return err
+		}
+		if err := r.Truncate(ctx); err != nil {
+			return err
+		}
+		e.PurgeBlockCache(rtag)
+	}
 
 	return commit()
 }
@@ -312,11 +351,26 @@ func (e *Engine) CompactTable(ctx context.Context, name string) error {
 	}
 
 	// clear caches
-	e.BlockCache(tag).Purge()
+	e.PurgeBlockCache(tag)
 
 	return commit()
 }
 
+// FlushTable merges all completed journal segments of a table into
+// table storage. Unlike background merges this call blocks until the
+// journal is empty or only contains segments with open transactions.
+func (e *Engine) FlushTable(ctx context.Context, name string) error {
+	tag := types.TaggedHash(types.ObjectTagTable, name)
+	t, ok := e.tables.Get(tag)
+	if !ok {
+		return ErrNoTable
+	}
+	if e.IsReadOnly() {
+		return ErrDatabaseReadOnly
+	}
+	return t.Flush(WithEngine(ctx, e))
+}
+
 func (e *Engine) openTables(ctx context.Context) error {
 	// iterate catalog
 	keys, err := e.cat.ListTables(ctx)
@@ -324,12 +378,38 @@ func (e *Engine) openTables(ctx context.Context) error {
 		return err
 	}
 
+	// load schemas and options
+	schemas := make(map[uint64]*schema.Schema, len(keys))
+	options := make(map[uint64]Options, len(keys))
 	for _, key := range keys {
-		// load schema and options
 		s, opts, err := e.cat.GetTable(ctx, key)
 		if err != nil {
 			return err
 		}
+		schemas[key] = s
+		options[key] = opts
+	}
+
+	// link rollups to their source tables and open them first so that
+	// merges during WAL replay can find them
+	if err := e.linkRollups(schemas, options); err != nil {
+		return err
+	}
+	slices.SortStableFunc(keys, func(a, b uint64) int {
+		_, ra := e.rollups.Get(a)
+		_, rb := e.rollups.Get(b)
+		switch {
+		case ra == rb:
+			return 0
+		case ra:
+			return -1
+		default:
+			return 1
+		}
+	})
+
+	for _, key := range keys {
+		s, opts := schemas[key], options[key]
 
 		// lookup schema enums
 		s.WithEnums(e.CloneEnums(s.EnumNames()...))
@@ -360,6 +440,9 @@ func (e *Engine) openTables(ctx context.Context) error {
 		}
 
 		e.tables.Put(key, table)
+
+		// continue unfinished index builds
+		e.resumeIndexBuilds(table)
 	}
 
 	return nil
@@ -0,0 +1,123 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package filter
+
+import (
+	"time"
+
+	"blockwatch.cc/knoxdb/internal/bitset"
+	"blockwatch.cc/knoxdb/internal/block"
+	"blockwatch.cc/knoxdb/internal/filter"
+	"blockwatch.cc/knoxdb/pkg/schema"
+)
+
+// WithExpr makes the filter match the result of key expression e on
+// field values. Filter values must already be in the expression's output
+// domain (e.g. lower case for lower()).
+func (f *Filter) WithExpr(e IndexExpr, field *schema.Field) *Filter {
+	if !e.IsValid() {
+		return f
+	}
+	f.Expr = e
+	f.Matcher = &exprMatcher{
+		Matcher: f.Matcher,
+		expr:    e,
+		field:   field,
+	}
+	return f
+}
+
+// EvalExpr computes key expression e on all values in block b from field f
+// and returns a new materialized block. Callers must dereference the result.
+func EvalExpr(e schema.IndexExpr, f *schema.Field, b *block.Block) *block.Block {
+	res := block.New(b.Type(), b.Len())
+	switch b.Type() {
+	case block.BlockBytes:
+		acc := res.Bytes()
+		for _, v := range b.Bytes().Iterator() {
+			acc.Append(e.ApplyBytes(v))
+		}
+	case block.BlockInt64:
+		acc := res.Int64()
+		for _, v := range b.Int64().Iterator() {
+			acc.Append(e.ApplyInt(v, f))
+		}
+	default:
+		// other types are not supported by any expression
+		res.Deref()
+		b.Ref()
+		return b
+	}
+	return res
+}
+
+// exprMatcher evaluates a key expression on candidate values before
+// forwarding them to the wrapped matcher.
+type exprMatcher struct {
+	Matcher
+	expr  schema.IndexExpr
+	field *schema.Field
+}
+
+func (m *exprMatcher) Weight() int {
+	return m.Matcher.Weight() + 1
+}
+
+// apply computes the expression on a single value. Values from statistics
+// views use Go types (string, time.Time) which are converted into block
+// types here.
+func (m *exprMatcher) apply(val any) any {
+	switch v := val.(type) {
+	case []byte:
+		return m.expr.ApplyBytes(v)
+	case string:
+		return m.expr.ApplyBytes([]byte(v))
+	case int64:
+		return m.expr.ApplyInt(v, m.field)
+	case time.Time:
+		return m.expr.ApplyInt(schema.TimeScale(m.field.Scale).ToUnix(v), m.field)
+	default:
+		return val
+	}
+}
+
+func (m *exprMatcher) MatchValue(val any) bool {
+	return m.Matcher.MatchValue(m.apply(val))
+}
+
+// MatchRange translates base field min/max statistics into the expression
+// domain. This only works for order preserving expressions, others always
+// match.
+func (m *exprMatcher) MatchRange(from, to any) bool {
+	if !m.expr.IsMonotonic() {
+		return true
+	}
+	return m.Matcher.MatchRange(m.apply(from), m.apply(to))
+}
+
+// MatchFilter always matches because hash filters contain base field values.
+func (m *exprMatcher) MatchFilter(_ filter.Filter) bool {
+	return true
+}
+
+func (m *exprMatcher) MatchVector(b *block.Block, bits, mask *bitset.Bitset) {
+	eb := EvalExpr(m.expr, m.field, b)
+	m.Matcher.MatchVector(eb, bits, mask)
+	eb.Deref()
+}
+
+func (m *exprMatcher) MatchRangeVectors(mins, maxs *block.Block, bits, mask *bitset.Bitset) {
+	if !m.expr.IsMonotonic() {
+		if mask != nil {
+			bits.Copy(mask)
+		} else {
+			bits.One()
+		}
+		return
+	}
+	emin, emax := EvalExpr(m.expr, m.field, mins), EvalExpr(m.expr, m.field, maxs)
+	m.Matcher.MatchRangeVectors(emin, emax, bits, mask)
+	emin.Deref()
+	emax.Deref()
+}
@@ -27,6 +27,7 @@ type Filter struct {
 	Id      uint16     // field unique id (used as storage key)
 	Matcher Matcher    // encapsulated match data and function
 	Value   any        // direct val for eq|ne|gt|ge|lt|le, [2]any for rg, slice for in|nin, string re
+	Expr    IndexExpr  // optional key expression evaluated on field values before matching
 }
 
 func NewFilter(f *schema.Field, idx int, mode FilterMode, val any) *Filter {
@@ -57,6 +58,11 @@ func (f *Filter) Validate() error {
 	switch f.Mode {
 	case FilterModeTrue, FilterModeFalse:
 		// empty matcher or value ok
+	case FilterModeIsNull, FilterModeNotNull:
+		// empty value ok
+		if f.Matcher == nil {
+			return ErrNoMatcher
+		}
 	default:
 		if f.Matcher == nil {
 			return ErrNoMatcher
@@ -70,7 +76,7 @@ func (f *Filter) Validate() error {
 
 func (f *Filter) String() string {
 	return fmt.Sprintf("%s[id=%d,n=%d] %s %s",
-		f.Name,
+		f.Expr.Format(f.Name),
 		f.Id,
 		f.Index,
 		f.Mode.Symbol(),
@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package filter
+
+import (
+	"reflect"
+)
+
+// Implies returns true when every row matching this filter tree also matches
+// predicate p. Both trees must be compiled against the same schema. The check
+// is conservative: it may return false for trees that logically imply p, but
+// never returns true when they don't.
+//
+// Each conjunct of p must be implied by a top-level conjunct of n. Leaf
+// conjuncts are compared by value, OR subtrees must exist verbatim in n.
+func (n *Node) Implies(p *Node) bool {
+	if p == nil {
+		return true
+	}
+	if n == nil {
+		return false
+	}
+	for _, pc := range conjuncts(p) {
+		var ok bool
+		for _, nc := range conjuncts(n) {
+			if nc.implies(pc) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// conjuncts returns the list of AND-ed subtrees.
+func conjuncts(n *Node) []*Node {
+	if n.IsLeaf() || n.OrKind {
+		if len(n.Children) == 1 {
+			return conjuncts(n.Children[0])
+		}
+		return []*Node{n}
+	}
+	var res []*Node
+	for _, c := range n.Children {
+		res = append(res, conjuncts(c)...)
+	}
+	return res
+}
+
+func (n *Node) implies(p *Node) bool {
+	// compare OR subtrees structurally
+	if !n.IsLeaf() || !p.IsLeaf() {
+		return n.String() == p.String()
+	}
+	return n.Filter.implies(p.Filter)
+}
+
+func (f *Filter) implies(p *Filter) bool {
+	if f.Id != p.Id || f.Expr != p.Expr {
+		return false
+	}
+
+	// document filters on the same column may address different paths
+	if f.Type == BlockDocument && f.Name != p.Name {
+		return false
+	}
+
+	// identical conditions
+	if f.Mode == p.Mode && reflect.DeepEqual(f.Value, p.Value) {
+		return true
+	}
+
+	// value conditions never match nulls
+	if p.Mode == FilterModeNotNull {
+		return !f.Mode.IsNullMode() && f.Mode != FilterModeTrue
+	}
+
+	switch f.Mode {
+	case FilterModeEqual:
+		return p.Matcher.MatchValue(f.Value)
+
+	case FilterModeIn:
+		vals := reflect.ValueOf(f.Value)
+		if vals.Kind() != reflect.Slice || vals.Len() == 0 {
+			return false
+		}
+		for i := range vals.Len() {
+			if !p.Matcher.MatchValue(vals.Index(i).Interface()) {
+				return false
+			}
+		}
+		return true
+
+	case FilterModeGt, FilterModeGe:
+		// lower bound must be within a lower bounded predicate
+		switch p.Mode {
+		case FilterModeGt, FilterModeGe:
+			return p.Matcher.MatchValue(f.Value)
+		}
+
+	case FilterModeLt, FilterModeLe:
+		// upper bound must be within an upper bounded predicate
+		switch p.Mode {
+		case FilterModeLt, FilterModeLe:
+			return p.Matcher.MatchValue(f.Value)
+		}
+
+	case FilterModeRange:
+		// both bounds must be within a range predicate
+		switch p.Mode {
+		case FilterModeGt, FilterModeGe, FilterModeLt, FilterModeLe, FilterModeRange:
+			rg, ok := f.Value.(RangeValue)
+			return ok && p.Matcher.MatchValue(rg[0]) && p.Matcher.MatchValue(rg[1])
+		}
+	}
+	return false
+}
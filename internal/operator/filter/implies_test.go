@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package filter
+
+import (
+	"testing"
+
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+// makeNullNode constructs a Node for IS NULL and NOT NULL conditions.
+func makeNullNode(field *schema.Field, mode FilterMode) *Node {
+	return &Node{Filter: &Filter{
+		Name:    field.Name,
+		Mode:    mode,
+		Index:   int(field.Id - 1),
+		Id:      field.Id,
+		Type:    field.Type.BlockType(),
+		Matcher: newFactory(field.Type.BlockType()).New(mode),
+	}}
+}
+
+// TestImplies verifies each implication rule used for partial index selection.
+func TestImplies(t *testing.T) {
+	sm := schema.NewSchema().
+		WithField(schema.NewField(types.FieldTypeInt64).WithName("f1")).
+		WithField(schema.NewField(types.FieldTypeInt64).WithName("f2"))
+	f1, _ := sm.Find("f1")
+	f2, _ := sm.Find("f2")
+
+	tests := []struct {
+		name   string
+		pred   *Node
+		input  *Node
+		expect bool
+	}{
+		// lower bounds
+		{"Ge/Same", makeGeNode(f1, 10), makeGeNode(f1, 10), true},
+		{"Ge/Higher", makeGeNode(f1, 10), makeGeNode(f1, 12), true},
+		{"Ge/Lower", makeGeNode(f1, 10), makeGeNode(f1, 9), false},
+		{"Ge/GtBound", makeGeNode(f1, 10), makeGtNode(f1, 10), true},
+		{"Gt/GeBound", makeGtNode(f1, 10), makeGeNode(f1, 10), false},
+		{"Ge/Equal", makeGeNode(f1, 10), makeEqualNode(f1, 10), true},
+		{"Ge/EqualOutside", makeGeNode(f1, 10), makeEqualNode(f1, 9), false},
+		{"Ge/Upper", makeGeNode(f1, 10), makeLtNode(f1, 20), false},
+
+		// upper bounds
+		{"Lt/Lower", makeLtNode(f1, 20), makeLeNode(f1, 19), true},
+		{"Lt/LeBound", makeLtNode(f1, 20), makeLeNode(f1, 20), false},
+		{"Le/LtBound", makeLeNode(f1, 20), makeLtNode(f1, 20), true},
+		{"Lt/Higher", makeLtNode(f1, 20), makeLtNode(f1, 21), false},
+		{"Lt/Bound", makeLtNode(f1, 20), makeGtNode(f1, 5), false},
+
+		// range containment
+		{"Ge/RangeInside", makeGeNode(f1, 10), makeRangeNode(f1, 10, 20), true},
+		{"Ge/RangeOutside", makeGeNode(f1, 10), makeRangeNode(f1, 5, 20), false},
+		{"Le/RangeInside", makeLeNode(f1, 20), makeRangeNode(f1, 10, 20), true},
+		{"Range/RangeInside", makeRangeNode(f1, 10, 20), makeRangeNode(f1, 12, 18), true},
+		{"Range/RangeOverlap", makeRangeNode(f1, 10, 20), makeRangeNode(f1, 12, 25), false},
+		{"Range/RangeOuter", makeRangeNode(f1, 10, 20), makeRangeNode(f1, 5, 25), false},
+		{"Range/Ge", makeRangeNode(f1, 10, 20), makeGeNode(f1, 12), false},
+		{"Range/Equal", makeRangeNode(f1, 10, 20), makeEqualNode(f1, 15), true},
+
+		// IN subsets
+		{"In/Subset", makeInNode(f1, []int64{1, 2, 3}), makeInNode(f1, []int64{1, 3}), true},
+		{"In/Superset", makeInNode(f1, []int64{1, 2, 3}), makeInNode(f1, []int64{1, 2, 3, 4}), false},
+		{"In/Disjoint", makeInNode(f1, []int64{1, 2, 3}), makeInNode(f1, []int64{1, 4}), false},
+		{"In/Equal", makeInNode(f1, []int64{1, 2, 3}), makeEqualNode(f1, 2), true},
+		{"In/EqualOutside", makeInNode(f1, []int64{1, 2, 3}), makeEqualNode(f1, 4), false},
+		{"Range/InInside", makeRangeNode(f1, 10, 20), makeInNode(f1, []int64{10, 20}), true},
+		{"Range/InOutside", makeRangeNode(f1, 10, 20), makeInNode(f1, []int64{10, 21}), false},
+		{"Ge/In", makeGeNode(f1, 10), makeInNode(f1, []int64{10, 11}), true},
+
+		// null conditions
+		{"NotNull/Equal", makeNullNode(f1, FilterModeNotNull), makeEqualNode(f1, 5), true},
+		{"NotNull/Range", makeNullNode(f1, FilterModeNotNull), makeRangeNode(f1, 1, 5), true},
+		{"NotNull/NotNull", makeNullNode(f1, FilterModeNotNull), makeNullNode(f1, FilterModeNotNull), true},
+		{"NotNull/IsNull", makeNullNode(f1, FilterModeNotNull), makeNullNode(f1, FilterModeIsNull), false},
+		{"Equal/IsNull", makeEqualNode(f1, 5), makeNullNode(f1, FilterModeIsNull), false},
+
+		// other columns
+		{"Field", makeGeNode(f1, 10), makeGeNode(f2, 12), false},
+
+		// AND
+		{"And/Same", makeAndTree(makeGeNode(f1, 10), makeEqualNode(f2, 1)), makeAndTree(makeGeNode(f1, 10), makeEqualNode(f2, 1)), true},
+		{"And/Stricter", makeAndTree(makeGeNode(f1, 10), makeEqualNode(f2, 1)), makeAndTree(makeEqualNode(f2, 1), makeGtNode(f1, 15), makeLtNode(f1, 20)), true},
+		{"And/Nested", makeAndTree(makeGeNode(f1, 10), makeEqualNode(f2, 1)), makeAndTree(makeAndTree(makeGeNode(f1, 11)), makeAndTree(makeEqualNode(f2, 1))), true},
+		{"And/Missing", makeAndTree(makeGeNode(f1, 10), makeEqualNode(f2, 1)), makeGeNode(f1, 10), false},
+		{"And/Weaker", makeAndTree(makeGeNode(f1, 10), makeEqualNode(f2, 1)), makeAndTree(makeGeNode(f1, 9), makeEqualNode(f2, 1)), false},
+		{"And/Leaf", makeGeNode(f1, 10), makeAndTree(makeGeNode(f1, 10), makeEqualNode(f2, 1)), true},
+
+		// OR
+		{"Or/Input", makeGeNode(f1, 10), makeOrTree(makeGeNode(f1, 10), makeEqualNode(f2, 1)), false},
+		{"Or/Verbatim", makeOrTree(makeEqualNode(f1, 1), makeEqualNode(f1, 2)), makeAndTree(makeOrTree(makeEqualNode(f1, 1), makeEqualNode(f1, 2)), makeEqualNode(f2, 3)), true},
+		{"Or/Different", makeOrTree(makeEqualNode(f1, 1), makeEqualNode(f1, 2)), makeOrTree(makeEqualNode(f1, 1), makeEqualNode(f1, 3)), false},
+		{"Or/Single", makeGeNode(f1, 10), makeOrTree(makeGeNode(f1, 12)), true},
+
+		// conservative results
+		{"Or/Branch", makeOrTree(makeEqualNode(f1, 1), makeEqualNode(f1, 2)), makeEqualNode(f1, 1), false},
+		{"In/Range", makeInNode(f1, []int64{1, 2, 3}), makeRangeNode(f1, 1, 3), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, tt.input.Implies(tt.pred))
+		})
+	}
+
+	// missing trees
+	assert.True(t, makeGeNode(f1, 10).Implies(nil))
+	assert.True(t, (*Node)(nil).Implies(nil))
+	assert.False(t, (*Node)(nil).Implies(makeGeNode(f1, 10)))
+}
@@ -114,6 +114,10 @@ func newFactory(typ BlockType) MatcherFactory {
 		return I128MatcherFactory{}
 	case BlockInt256:
 		return I256MatcherFactory{}
+	case BlockBigint:
+		return BigMatcherFactory{}
+	case BlockDocument:
+		return DocMatcherFactory{}
 	default:
 		return nil
 	}
@@ -0,0 +1,430 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package filter
+
+import (
+	"slices"
+
+	"blockwatch.cc/knoxdb/internal/bitset"
+	"blockwatch.cc/knoxdb/internal/block"
+	"blockwatch.cc/knoxdb/internal/filter"
+	"blockwatch.cc/knoxdb/internal/hash"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/num"
+)
+
+// Bigint blocks store values in the order-preserving binary wire format
+// (see num.Big.Bytes). Ordered comparisons use num.BigCmpBytes which also
+// treats empty values as zero. Equality can use container matchers
+// because wire encoding is canonical.
+
+type BigMatcherFactory struct{}
+
+func (f BigMatcherFactory) New(m FilterMode) Matcher {
+	switch m {
+	case FilterModeEqual:
+		return &bigEqualMatcher{}
+	case FilterModeNotEqual:
+		return &bigNotEqualMatcher{}
+	case FilterModeGt:
+		return &bigGtMatcher{}
+	case FilterModeGe:
+		return &bigGeMatcher{}
+	case FilterModeLt:
+		return &bigLtMatcher{}
+	case FilterModeLe:
+		return &bigLeMatcher{}
+	case FilterModeRange:
+		return &bigRangeMatcher{}
+	case FilterModeIn:
+		return &bigInSetMatcher{}
+	case FilterModeNotIn:
+		return &bigNotInSetMatcher{}
+	case FilterModeIsNull, FilterModeNotNull:
+		return newNullMatcher(m)
+	default:
+		// unsupported
+		// FilterModeRegexp, FilterModeContains, FilterModeMatch
+		return &noopMatcher{}
+	}
+}
+
+// bigBytes converts query values and block values to wire format.
+func bigBytes(v any) []byte {
+	if b, ok := v.([]byte); ok {
+		return b
+	}
+	return types.BigOf(v).Bytes()
+}
+
+// matchBigVector sets bits for all (or masked) block values that satisfy fn.
+func matchBigVector(b *block.Block, bits, mask *bitset.Bitset, fn func([]byte) bool) {
+	arr := b.Bytes()
+	if mask != nil {
+		for i := range mask.Iterator() {
+			if fn(arr.Get(i)) {
+				bits.Set(i)
+			}
+		}
+	} else {
+		for i, v := range arr.Iterator() {
+			if fn(v) {
+				bits.Set(i)
+			}
+		}
+	}
+}
+
+type bigMatcher struct {
+	noopMatcher
+	val  []byte
+	hash uint64
+}
+
+func (m *bigMatcher) Weight() int { return len(m.val) }
+
+func (m *bigMatcher) WithValue(v any) {
+	m.val = bigBytes(v)
+	m.hash = hash.Hash(m.val)
+}
+
+func (m *bigMatcher) Value() any {
+	return num.NewBigFromBytes(m.val)
+}
+
+func (m bigMatcher) MatchFilter(flt filter.Filter) bool {
+	return flt.Contains(m.hash)
+}
+
+// EQUAL ---
+
+type bigEqualMatcher struct {
+	bigMatcher
+}
+
+func (m bigEqualMatcher) MatchValue(v any) bool {
+	return num.BigCmpBytes(m.val, bigBytes(v)) == 0
+}
+
+func (m bigEqualMatcher) MatchRange(from, to any) bool {
+	return num.BigCmpBytes(m.val, bigBytes(from)) >= 0 &&
+		num.BigCmpBytes(m.val, bigBytes(to)) <= 0
+}
+
+func (m bigEqualMatcher) MatchVector(b *block.Block, bits, mask *bitset.Bitset) {
+	b.Bytes().Matcher().MatchEqual(m.val, bits, mask)
+}
+
+func (m bigEqualMatcher) MatchRangeVectors(mins, maxs *block.Block, bits, mask *bitset.Bitset) {
+	// min <= v && max >= v, mask is optional
+	f := newFactory(mins.Type())
+	le, ge := f.New(FilterModeLe), f.New(FilterModeGe)
+	le.WithValue(m.val)
+	ge.WithValue(m.val)
+	minBits := bitset.New(mins.Len())
+	le.MatchVector(mins, minBits, mask)
+	if mask != nil {
+		minBits.And(mask)
+	}
+	ge.MatchVector(maxs, bits, minBits)
+	bits.And(minBits)
+	minBits.Close()
+}
+
+// NOT EQUAL ---
+
+type bigNotEqualMatcher struct {
+	bigMatcher
+}
+
+func (m bigNotEqualMatcher) MatchValue(v any) bool {
+	return num.BigCmpBytes(m.val, bigBytes(v)) != 0
+}
+
+func (m bigNotEqualMatcher) MatchRange(from, to any) bool {
+	return num.BigCmpBytes(m.val, bigBytes(from)) < 0 ||
+		num.BigCmpBytes(m.val, bigBytes(to)) > 0
+}
+
+func (m bigNotEqualMatcher) MatchVector(b *block.Block, bits, mask *bitset.Bitset) {
+	b.Bytes().Matcher().MatchNotEqual(m.val, bits, mask)
+}
+
+func (m bigNotEqualMatcher) MatchRangeVectors(_, _ *block.Block, bits, mask *bitset.Bitset) {
+	// undecided, always true
+	if mask != nil {
+		bits.Copy(mask)
+	} else {
+		bits.One()
+	}
+}
+
+// GT ---
+
+type bigGtMatcher struct {
+	bigMatcher
+}
+
+func (m bigGtMatcher) MatchValue(v any) bool {
+	return num.BigCmpBytes(m.val, bigBytes(v)) < 0
+}
+
+func (m bigGtMatcher) MatchRange(_, to any) bool {
+	return num.BigCmpBytes(m.val, bigBytes(to)) < 0
+}
+
+func (m bigGtMatcher) MatchVector(b *block.Block, bits, mask *bitset.Bitset) {
+	matchBigVector(b, bits, mask, func(v []byte) bool {
+		return num.BigCmpBytes(v, m.val) > 0
+	})
+}
+
+func (m bigGtMatcher) MatchRangeVectors(_, maxs *block.Block, bits, mask *bitset.Bitset) {
+	// max > v
+	gt := newFactory(maxs.Type()).New(FilterModeGt)
+	gt.WithValue(m.val)
+	gt.MatchVector(maxs, bits, mask)
+}
+
+// GE ---
+
+type bigGeMatcher struct {
+	bigMatcher
+}
+
+func (m bigGeMatcher) MatchValue(v any) bool {
+	return num.BigCmpBytes(m.val, bigBytes(v)) <= 0
+}
+
+func (m bigGeMatcher) MatchRange(_, to any) bool {
+	return num.BigCmpBytes(m.val, bigBytes(to)) <= 0
+}
+
+func (m bigGeMatcher) MatchVector(b *block.Block, bits, mask *bitset.Bitset) {
+	matchBigVector(b, bits, mask, func(v []byte) bool {
+		return num.BigCmpBytes(v, m.val) >= 0
+	})
+}
+
+func (m bigGeMatcher) MatchRangeVectors(_, maxs *block.Block, bits, mask *bitset.Bitset) {
+	// max >= v
+	ge := newFactory(maxs.Type()).New(FilterModeGe)
+	ge.WithValue(m.val)
+	ge.MatchVector(maxs, bits, mask)
+}
+
+// LT ---
+
+type bigLtMatcher struct {
+	bigMatcher
+}
+
+func (m bigLtMatcher) MatchValue(v any) bool {
+	return num.BigCmpBytes(m.val, bigBytes(v)) > 0
+}
+
+func (m bigLtMatcher) MatchRange(from, _ any) bool {
+	return num.BigCmpBytes(m.val, bigBytes(from)) > 0
+}
+
+func (m bigLtMatcher) MatchVector(b *block.Block, bits, mask *bitset.Bitset) {
+	matchBigVector(b, bits, mask, func(v []byte) bool {
+		return num.BigCmpBytes(v, m.val) < 0
+	})
+}
+
+func (m bigLtMatcher) MatchRangeVectors(mins, _ *block.Block, bits, mask *bitset.Bitset) {
+	// min < v
+	lt := newFactory(mins.Type()).New(FilterModeLt)
+	lt.WithValue(m.val)
+	lt.MatchVector(mins, bits, mask)
+}
+
+// LE ---
+
+type bigLeMatcher struct {
+	bigMatcher
+}
+
+func (m bigLeMatcher) MatchValue(v any) bool {
+	return num.BigCmpBytes(m.val, bigBytes(v)) >= 0
+}
+
+func (m bigLeMatcher) MatchRange(from, _ any) bool {
+	return num.BigCmpBytes(m.val, bigBytes(from)) >= 0
+}
+
+func (m bigLeMatcher) MatchVector(b *block.Block, bits, mask *bitset.Bitset) {
+	matchBigVector(b, bits, mask, func(v []byte) bool {
+		return num.BigCmpBytes(v, m.val) <= 0
+	})
+}
+
+func (m bigLeMatcher) MatchRangeVectors(mins, _ *block.Block, bits, mask *bitset.Bitset) {
+	// min <= v
+	le := newFactory(mins.Type()).New(FilterModeLe)
+	le.WithValue(m.val)
+	le.MatchVector(mins, bits, mask)
+}
+
+// RANGE ---
+
+type bigRangeMatcher struct {
+	noopMatcher
+	from []byte
+	to   []byte
+}
+
+func (m *bigRangeMatcher) Weight() int { return len(m.from) + len(m.to) }
+
+func (m *bigRangeMatcher) Len() int { return 2 }
+
+func (m *bigRangeMatcher) WithValue(v any) {
+	val := v.(RangeValue)
+	m.from = bigBytes(val[0])
+	m.to = bigBytes(val[1])
+}
+
+func (m *bigRangeMatcher) Value() any {
+	val := RangeValue{num.NewBigFromBytes(m.from), num.NewBigFromBytes(m.to)}
+	return val
+}
+
+func (m bigRangeMatcher) match(v []byte) bool {
+	return num.BigCmpBytes(m.from, v) <= 0 && num.BigCmpBytes(m.to, v) >= 0
+}
+
+func (m bigRangeMatcher) MatchValue(v any) bool {
+	return m.match(bigBytes(v))
+}
+
+func (m bigRangeMatcher) MatchRange(from, to any) bool {
+	return num.BigCmpBytes(bigBytes(from), m.to) <= 0 &&
+		num.BigCmpBytes(bigBytes(to), m.from) >= 0
+}
+
+func (m bigRangeMatcher) MatchVector(b *block.Block, bits, mask *bitset.Bitset) {
+	matchBigVector(b, bits, mask, m.match)
+}
+
+func (m bigRangeMatcher) MatchRangeVectors(mins, maxs *block.Block, bits, mask *bitset.Bitset) {
+	// min <= to && max >= from
+	f := newFactory(mins.Type())
+	le, ge := f.New(FilterModeLe), f.New(FilterModeGe)
+	le.WithValue(m.to)
+	ge.WithValue(m.from)
+	minBits := bitset.New(mins.Len())
+	le.MatchVector(mins, minBits, mask)
+	if mask != nil {
+		minBits.And(mask)
+	}
+	ge.MatchVector(maxs, bits, minBits)
+	bits.And(minBits)
+	minBits.Close()
+}
+
+// IN ---
+
+// bigSetMatcher keeps set members in wire format sorted in numeric order.
+type bigSetMatcher struct {
+	noopMatcher
+	vals   []num.Big // original query data, sorted, unique
+	slice  [][]byte  // wire format of vals
+	hashes []uint64  // bloom hashes
+}
+
+func (m *bigSetMatcher) Weight() int { return len(m.slice) }
+
+func (m *bigSetMatcher) Len() int { return len(m.slice) }
+
+func (m *bigSetMatcher) Value() any {
+	return m.vals
+}
+
+func (m *bigSetMatcher) WithValue(val any) {
+	m.WithSlice(val)
+}
+
+func (m *bigSetMatcher) WithSlice(slice any) {
+	m.vals = num.BigUnique(slice.([]num.Big))
+	m.slice = make([][]byte, len(m.vals))
+	m.hashes = make([]uint64, len(m.vals))
+	for i, v := range m.vals {
+		m.slice[i] = v.Bytes()
+		m.hashes[i] = hash.Hash(m.slice[i])
+	}
+}
+
+func (m bigSetMatcher) contains(v []byte) bool {
+	_, ok := slices.BinarySearchFunc(m.slice, v, num.BigCmpBytes)
+	return ok
+}
+
+func (m bigSetMatcher) containsRange(from, to []byte) bool {
+	// find the first member >= from and check it is <= to
+	i, _ := slices.BinarySearchFunc(m.slice, from, num.BigCmpBytes)
+	return i < len(m.slice) && num.BigCmpBytes(m.slice[i], to) <= 0
+}
+
+type bigInSetMatcher struct {
+	bigSetMatcher
+}
+
+func (m bigInSetMatcher) MatchValue(v any) bool {
+	return m.contains(bigBytes(v))
+}
+
+func (m bigInSetMatcher) MatchRange(from, to any) bool {
+	return m.containsRange(bigBytes(from), bigBytes(to))
+}
+
+func (m bigInSetMatcher) MatchFilter(flt filter.Filter) bool {
+	return flt.ContainsAny(m.hashes)
+}
+
+func (m bigInSetMatcher) MatchVector(b *block.Block, bits, mask *bitset.Bitset) {
+	matchBigVector(b, bits, mask, m.contains)
+}
+
+func (m bigInSetMatcher) MatchRangeVectors(mins, maxs *block.Block, bits, mask *bitset.Bitset) {
+	if len(m.slice) == 0 {
+		return
+	}
+	setMin, setMax := m.slice[0], m.slice[len(m.slice)-1]
+	rg := newFactory(mins.Type()).New(FilterModeRange)
+	rg.WithValue(RangeValue{setMin, setMax})
+	rg.MatchRangeVectors(mins, maxs, bits, mask)
+}
+
+// NOT IN ---
+
+type bigNotInSetMatcher struct {
+	bigSetMatcher
+}
+
+func (m bigNotInSetMatcher) MatchValue(v any) bool {
+	return !m.contains(bigBytes(v))
+}
+
+func (m bigNotInSetMatcher) MatchRange(from, to any) bool {
+	return !m.containsRange(bigBytes(from), bigBytes(to))
+}
+
+func (m bigNotInSetMatcher) MatchFilter(_ filter.Filter) bool {
+	// we don't know generally, so full scan is always required
+	return true
+}
+
+func (m bigNotInSetMatcher) MatchVector(b *block.Block, bits, mask *bitset.Bitset) {
+	matchBigVector(b, bits, mask, func(v []byte) bool { return !m.contains(v) })
+}
+
+func (m bigNotInSetMatcher) MatchRangeVectors(_, _ *block.Block, bits, mask *bitset.Bitset) {
+	// undecided, always true
+	if mask != nil {
+		bits.Copy(mask)
+	} else {
+		bits.One()
+	}
+}
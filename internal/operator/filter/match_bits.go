@@ -35,6 +35,8 @@ func (f BitMatcherFactory) New(m FilterMode) Matcher {
 		return &bitInSetMatcher{}
 	case FilterModeNotIn:
 		return &bitNotInSetMatcher{}
+	case FilterModeIsNull, FilterModeNotNull:
+		return newNullMatcher(m)
 	default:
 		// any other mode is unsupported
 		return &noopMatcher{}
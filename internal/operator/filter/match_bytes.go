@@ -12,6 +12,7 @@ import (
 	"blockwatch.cc/knoxdb/internal/block"
 	"blockwatch.cc/knoxdb/internal/filter"
 	"blockwatch.cc/knoxdb/internal/hash"
+	"blockwatch.cc/knoxdb/pkg/schema"
 	"blockwatch.cc/knoxdb/pkg/slicex"
 	"github.com/echa/log"
 )
@@ -40,6 +41,12 @@ func (f BytesMatcherFactory) New(m FilterMode) Matcher {
 		return &bytesNotInSetMatcher{}
 	case FilterModeRegexp:
 		return &bytesRegexpMatcher{}
+	case FilterModeContains:
+		return &bytesContainsMatcher{}
+	case FilterModeMatch:
+		return &bytesTermMatcher{}
+	case FilterModeIsNull, FilterModeNotNull:
+		return newNullMatcher(m)
 	default:
 		return &noopMatcher{}
 	}
@@ -621,3 +628,166 @@ func (m bytesRegexpMatcher) MatchFilter(_ filter.Filter) bool {
 	// we don't know generally, so full scan is always required
 	return true
 }
+
+// CONTAINS ---
+
+// bytesContainsMatcher matches values containing a case-insensitive substring.
+type bytesContainsMatcher struct {
+	noopMatcher
+	val []byte // lower case substring
+}
+
+func (m *bytesContainsMatcher) Value() any {
+	return m.val
+}
+
+func (m *bytesContainsMatcher) Weight() int {
+	return 50 // arbitrary cost
+}
+
+func (m *bytesContainsMatcher) WithValue(v any) {
+	switch val := v.(type) {
+	case []byte:
+		m.val = bytes.ToLower(val)
+	case string:
+		m.val = bytes.ToLower([]byte(val))
+	default:
+		panic(fmt.Errorf("unsupported contains value type %T", v))
+	}
+}
+
+func (m bytesContainsMatcher) match(v []byte) bool {
+	return bytes.Contains(bytes.ToLower(v), m.val)
+}
+
+func (m bytesContainsMatcher) MatchValue(v any) bool {
+	return m.match(v.([]byte))
+}
+
+func (m bytesContainsMatcher) MatchRange(_, _ any) bool {
+	// we don't know generally, so full scan is always required
+	return true
+}
+
+func (m bytesContainsMatcher) MatchVector(b *block.Block, bits, mask *bitset.Bitset) {
+	if mask != nil {
+		arr := b.Bytes()
+		for i := range mask.Iterator() {
+			if m.match(arr.Get(i)) {
+				bits.Set(i)
+			}
+		}
+	} else {
+		for i, v := range b.Bytes().Iterator() {
+			if m.match(v) {
+				bits.Set(i)
+			}
+		}
+	}
+}
+
+func (m bytesContainsMatcher) MatchRangeVectors(_, _ *block.Block, bits, mask *bitset.Bitset) {
+	// undecided, always true
+	if mask != nil {
+		bits.Copy(mask)
+	} else {
+		bits.One()
+	}
+}
+
+func (m bytesContainsMatcher) MatchFilter(_ filter.Filter) bool {
+	// filters contain full values only
+	return true
+}
+
+// MATCH ---
+
+// bytesTermMatcher matches values containing all words of at least one
+// alternative in a term query (see schema.ParseTermQuery).
+type bytesTermMatcher struct {
+	noopMatcher
+	val   []byte     // original query
+	terms [][][]byte // alternatives of required words
+}
+
+func (m *bytesTermMatcher) Value() any {
+	return m.val
+}
+
+func (m *bytesTermMatcher) Weight() int {
+	return 100 // arbitrary cost
+}
+
+func (m *bytesTermMatcher) WithValue(v any) {
+	switch val := v.(type) {
+	case []byte:
+		m.val = val
+	case string:
+		m.val = []byte(val)
+	default:
+		panic(fmt.Errorf("unsupported match value type %T", v))
+	}
+	m.terms = schema.ParseTermQuery(m.val)
+}
+
+func (m bytesTermMatcher) match(v []byte) bool {
+	if len(m.terms) == 0 {
+		return false
+	}
+	words := make(map[string]struct{})
+	for _, w := range schema.Words().Tokens(v) {
+		words[string(w)] = struct{}{}
+	}
+	for _, alt := range m.terms {
+		ok := true
+		for _, t := range alt {
+			if _, ok = words[string(t)]; !ok {
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (m bytesTermMatcher) MatchValue(v any) bool {
+	return m.match(v.([]byte))
+}
+
+func (m bytesTermMatcher) MatchRange(_, _ any) bool {
+	// we don't know generally, so full scan is always required
+	return true
+}
+
+func (m bytesTermMatcher) MatchVector(b *block.Block, bits, mask *bitset.Bitset) {
+	if mask != nil {
+		arr := b.Bytes()
+		for i := range mask.Iterator() {
+			if m.match(arr.Get(i)) {
+				bits.Set(i)
+			}
+		}
+	} else {
+		for i, v := range b.Bytes().Iterator() {
+			if m.match(v) {
+				bits.Set(i)
+			}
+		}
+	}
+}
+
+func (m bytesTermMatcher) MatchRangeVectors(_, _ *block.Block, bits, mask *bitset.Bitset) {
+	// undecided, always true
+	if mask != nil {
+		bits.Copy(mask)
+	} else {
+		bits.One()
+	}
+}
+
+func (m bytesTermMatcher) MatchFilter(_ filter.Filter) bool {
+	// filters contain full values only
+	return true
+}
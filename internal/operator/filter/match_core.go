@@ -33,7 +33,13 @@ func MatchSingle(f *Filter, pkg *pack.Package, bits, mask *bitset.Bitset) *bitse
 	if bits == nil {
 		bits = bitset.New(pkg.Len())
 	}
-	f.Matcher.MatchVector(pkg.Block(f.Index), bits, mask)
+	b := pkg.Block(f.Index)
+	f.Matcher.MatchVector(b, bits, mask)
+
+	// null values never match value conditions
+	if b.HasNulls() && !f.Mode.IsNullMode() {
+		bits.And(b.Valid())
+	}
 	return bits
 }
 
@@ -66,8 +72,9 @@ func MatchAnd(n *Node, pkg *pack.Package, r engine.StatsReader, bits *bitset.Bit
 			// Quick inclusion check to skip matching when the current condition
 			// would return an all-true vector. Note that we do not have to check
 			// for an all-false vector because MaybeMatchTree() has already deselected
-			// packs of that kind (except the journal)
-			if r != nil {
+			// packs of that kind (except the journal). Statistics don't apply
+			// to expression filters, document paths and blocks with nulls.
+			if r != nil && !f.Expr.IsValid() && f.Type != BlockDocument && !pkg.Block(f.Index).HasNulls() {
 				min, max := r.MinMax(f.Index)
 				switch f.Mode {
 				case types.FilterModeEqual:
@@ -148,8 +155,9 @@ func MatchOr(n *Node, pkg *pack.Package, r engine.StatsReader, bits *bitset.Bits
 			// Quick inclusion check to skip matching when the current condition
 			// would return an all-true vector. Note that we do not have to check
 			// for an all-false vector because MaybeMatchPack() has already deselected
-			// packs of that kind (except the journal).
-			if r != nil {
+			// packs of that kind (except the journal). Statistics don't apply
+			// to document paths and blocks with nulls.
+			if r != nil && f.Type != BlockDocument && !pkg.Block(f.Index).HasNulls() {
 				min, max := r.MinMax(f.Index)
 				skipEarly := false
 				switch f.Mode {
@@ -0,0 +1,352 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package filter
+
+import (
+	"cmp"
+	"encoding/json"
+	"strings"
+
+	"blockwatch.cc/knoxdb/internal/bitset"
+	"blockwatch.cc/knoxdb/internal/block"
+	"blockwatch.cc/knoxdb/internal/filter"
+	"blockwatch.cc/knoxdb/internal/types"
+)
+
+// DocMatcherFactory generates matchers for paths inside document columns,
+// e.g. `params.amount > 100`. Matcher values are scalars normalized with
+// types.DocumentScalar (int64, float64, string, bool), range values use
+// RangeValue and sets use []any.
+//
+// Matchers use shredded path vectors when a block contains them and parse
+// documents otherwise. Integers and floats compare numerically, other
+// values only equal values of the same type, so != and NOT IN match rows
+// with a different value type at path. IS NULL matches rows where the path
+// is missing or does not hold a scalar value. Without path only document
+// null checks are supported. Document columns have no statistics, so range
+// and filter checks always match.
+type DocMatcherFactory struct {
+	path string
+}
+
+func NewDocFactory(path string) MatcherFactory {
+	return DocMatcherFactory{path}
+}
+
+func (f DocMatcherFactory) New(m FilterMode) Matcher {
+	if f.path == "" {
+		return newNullMatcher(m)
+	}
+	switch m {
+	case FilterModeEqual, FilterModeNotEqual,
+		FilterModeGt, FilterModeGe, FilterModeLt, FilterModeLe,
+		FilterModeRange, FilterModeIn, FilterModeNotIn,
+		FilterModeIsNull, FilterModeNotNull:
+		return &docMatcher{path: f.path, mode: m}
+	default:
+		// unsupported
+		return &noopMatcher{}
+	}
+}
+
+type docMatcher struct {
+	noopMatcher
+	path string
+	mode FilterMode
+	val  any    // scalar value
+	rg   [2]any // range bounds
+	set  []any  // set members
+}
+
+func (m *docMatcher) Weight() int { return 4 }
+
+func (m *docMatcher) Len() int {
+	switch m.mode {
+	case FilterModeRange:
+		return 2
+	case FilterModeIn, FilterModeNotIn:
+		return len(m.set)
+	default:
+		return 1
+	}
+}
+
+func (m *docMatcher) Value() any {
+	switch m.mode {
+	case FilterModeRange:
+		return RangeValue(m.rg)
+	case FilterModeIn, FilterModeNotIn:
+		return m.set
+	default:
+		return m.val
+	}
+}
+
+func (m *docMatcher) WithValue(v any) {
+	if rg, ok := v.(RangeValue); ok {
+		m.rg = [2]any{rg[0], rg[1]}
+		return
+	}
+	m.val = v
+}
+
+func (m *docMatcher) WithSlice(slice any) {
+	m.set = slice.([]any)
+}
+
+// match checks a single present (ok) or missing path value x.
+func (m docMatcher) match(x any, ok bool) bool {
+	switch m.mode {
+	case FilterModeIsNull:
+		return !ok
+	case FilterModeNotNull:
+		return ok
+	}
+	if !ok {
+		return false
+	}
+	switch m.mode {
+	case FilterModeEqual:
+		c, ok := compareDoc(x, m.val)
+		return ok && c == 0
+	case FilterModeNotEqual:
+		c, ok := compareDoc(x, m.val)
+		return !ok || c != 0
+	case FilterModeGt:
+		c, ok := compareDoc(x, m.val)
+		return ok && c > 0
+	case FilterModeGe:
+		c, ok := compareDoc(x, m.val)
+		return ok && c >= 0
+	case FilterModeLt:
+		c, ok := compareDoc(x, m.val)
+		return ok && c < 0
+	case FilterModeLe:
+		c, ok := compareDoc(x, m.val)
+		return ok && c <= 0
+	case FilterModeRange:
+		c1, ok1 := compareDoc(x, m.rg[0])
+		c2, ok2 := compareDoc(x, m.rg[1])
+		return ok1 && ok2 && c1 >= 0 && c2 <= 0
+	case FilterModeIn, FilterModeNotIn:
+		var found bool
+		for _, v := range m.set {
+			if c, ok := compareDoc(x, v); ok && c == 0 {
+				found = true
+				break
+			}
+		}
+		return found == (m.mode == FilterModeIn)
+	default:
+		return false
+	}
+}
+
+// matchDoc looks up the matcher's path in JSON document buf.
+func (m docMatcher) matchDoc(buf []byte) bool {
+	doc, err := types.ParseDocument(buf)
+	if err != nil {
+		return false
+	}
+	v, ok := types.LookupPath(doc, m.path)
+	if ok {
+		v, err = types.DocumentScalar(v)
+		ok = err == nil
+	}
+	return m.match(v, ok)
+}
+
+func (m docMatcher) MatchValue(v any) bool {
+	switch x := v.(type) {
+	case []byte:
+		return m.matchDoc(x)
+	case json.RawMessage:
+		return m.matchDoc(x)
+	default:
+		return m.match(nil, false)
+	}
+}
+
+func (m docMatcher) MatchRange(_, _ any) bool {
+	return true
+}
+
+func (m docMatcher) MatchFilter(_ filter.Filter) bool {
+	return true
+}
+
+func (m docMatcher) MatchRangeVectors(_, _ *block.Block, bits, mask *bitset.Bitset) {
+	if mask != nil {
+		bits.Copy(mask)
+	} else {
+		bits.One()
+	}
+}
+
+func (m docMatcher) MatchVector(b *block.Block, bits, mask *bitset.Bitset) {
+	// use shredded path vector when available
+	if child := b.DocPath(m.path); child != nil {
+		m.matchPath(child, bits, mask)
+		return
+	}
+
+	// parse documents
+	src := b.Bytes()
+	if mask != nil {
+		for i := range mask.Iterator() {
+			if m.matchDoc(src.Get(i)) {
+				bits.Set(i)
+			}
+		}
+	} else {
+		for i, v := range src.Iterator() {
+			if m.matchDoc(v) {
+				bits.Set(i)
+			}
+		}
+	}
+}
+
+// matchPath matches a shredded path vector. NULL rows are missing paths.
+func (m docMatcher) matchPath(child *block.Block, bits, mask *bitset.Bitset) {
+	switch m.mode {
+	case FilterModeIsNull, FilterModeNotNull:
+		newNullMatcher(m.mode).MatchVector(child, bits, mask)
+		return
+	}
+
+	// use a typed vector matcher when values convert exactly
+	if sub := m.typedMatcher(child.Type()); sub != nil {
+		sub.MatchVector(child, bits, mask)
+		if child.HasNulls() {
+			bits.And(child.Valid())
+		}
+		return
+	}
+
+	// compare single values otherwise
+	fn := func(i int) {
+		if child.IsNull(i) {
+			return
+		}
+		v := child.Get(i)
+		if buf, ok := v.([]byte); ok {
+			v = string(buf)
+		}
+		if m.match(v, true) {
+			bits.Set(i)
+		}
+	}
+	if mask != nil {
+		for i := range mask.Iterator() {
+			fn(i)
+		}
+	} else {
+		for i := range child.Len() {
+			fn(i)
+		}
+	}
+}
+
+// typedMatcher returns a matcher for the shredded vector type typ or nil
+// when the matcher's value does not convert to typ.
+func (m docMatcher) typedMatcher(typ BlockType) Matcher {
+	var val any
+	switch m.mode {
+	case FilterModeEqual, FilterModeNotEqual,
+		FilterModeGt, FilterModeGe, FilterModeLt, FilterModeLe:
+		v, ok := castDoc(typ, m.val)
+		if !ok {
+			return nil
+		}
+		val = v
+	case FilterModeRange:
+		from, ok1 := castDoc(typ, m.rg[0])
+		to, ok2 := castDoc(typ, m.rg[1])
+		if !ok1 || !ok2 {
+			return nil
+		}
+		val = RangeValue{from, to}
+	default:
+		return nil
+	}
+	f := newFactory(typ)
+	if f == nil {
+		return nil
+	}
+	sub := f.New(m.mode)
+	sub.WithValue(val)
+	return sub
+}
+
+// castDoc converts a normalized document value into the Go type of
+// shredded vector type typ without loss.
+func castDoc(typ BlockType, v any) (any, bool) {
+	switch typ {
+	case BlockInt64:
+		switch x := v.(type) {
+		case int64:
+			return x, true
+		case float64:
+			if x == float64(int64(x)) {
+				return int64(x), true
+			}
+		}
+	case BlockFloat64:
+		switch x := v.(type) {
+		case int64:
+			if x == int64(float64(x)) {
+				return float64(x), true
+			}
+		case float64:
+			return x, true
+		}
+	case BlockBytes:
+		if x, ok := v.(string); ok {
+			return []byte(x), true
+		}
+	case BlockBool:
+		if x, ok := v.(bool); ok {
+			return x, true
+		}
+	}
+	return nil, false
+}
+
+// compareDoc compares normalized document values. Integers and floats
+// compare numerically, other types only compare to the same type.
+func compareDoc(a, b any) (int, bool) {
+	switch x := a.(type) {
+	case int64:
+		switch y := b.(type) {
+		case int64:
+			return cmp.Compare(x, y), true
+		case float64:
+			return cmp.Compare(float64(x), y), true
+		}
+	case float64:
+		switch y := b.(type) {
+		case int64:
+			return cmp.Compare(x, float64(y)), true
+		case float64:
+			return cmp.Compare(x, y), true
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), true
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0, true
+			case y:
+				return -1, true
+			default:
+				return 1, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -36,6 +36,8 @@ func (f I128MatcherFactory) New(m FilterMode) Matcher {
 		return &i128InSetMatcher{}
 	case FilterModeNotIn:
 		return &i128NotInSetMatcher{}
+	case FilterModeIsNull, FilterModeNotNull:
+		return newNullMatcher(m)
 	default:
 		// unsupported
 		// FilterModeRegexp:
@@ -36,6 +36,8 @@ func (f I256MatcherFactory) New(m FilterMode) Matcher {
 		return &i256InSetMatcher{}
 	case FilterModeNotIn:
 		return &i256NotInSetMatcher{}
+	case FilterModeIsNull, FilterModeNotNull:
+		return newNullMatcher(m)
 	default:
 		// unsupported
 		// FilterModeRegexp:
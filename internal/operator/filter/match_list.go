@@ -0,0 +1,220 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package filter
+
+import (
+	"slices"
+
+	"blockwatch.cc/knoxdb/internal/bitset"
+	"blockwatch.cc/knoxdb/internal/block"
+	"blockwatch.cc/knoxdb/internal/filter"
+	"blockwatch.cc/knoxdb/internal/filter/bloom"
+	"blockwatch.cc/knoxdb/internal/hash"
+	"blockwatch.cc/knoxdb/pkg/util"
+)
+
+// ListMatcherFactory generates matchers for list columns. List matchers
+// compare element values. Pack statistics (min/max, bloom filters) of list
+// columns are built from elements as well, so range and filter checks use
+// the element type.
+//
+// - CONTAINS(v) matches lists that contain v
+// - ANY(a,b,..) matches lists that contain at least one of the values
+// - ALL(a,b,..) matches lists that contain all values
+type ListMatcherFactory struct {
+	elem BlockType
+}
+
+func NewListFactory(elem FieldType) MatcherFactory {
+	return ListMatcherFactory{elem.BlockType()}
+}
+
+func (f ListMatcherFactory) New(m FilterMode) Matcher {
+	switch m {
+	case FilterModeContains, FilterModeAny, FilterModeAll:
+		switch f.elem {
+		case BlockInt64:
+			return &listMatcher[int64]{mode: m}
+		case BlockInt32:
+			return &listMatcher[int32]{mode: m}
+		case BlockInt16:
+			return &listMatcher[int16]{mode: m}
+		case BlockInt8:
+			return &listMatcher[int8]{mode: m}
+		case BlockUint64:
+			return &listMatcher[uint64]{mode: m}
+		case BlockUint32:
+			return &listMatcher[uint32]{mode: m}
+		case BlockUint16:
+			return &listMatcher[uint16]{mode: m}
+		case BlockUint8:
+			return &listMatcher[uint8]{mode: m}
+		case BlockFloat64:
+			return &listMatcher[float64]{mode: m}
+		case BlockFloat32:
+			return &listMatcher[float32]{mode: m}
+		}
+	case FilterModeIsNull, FilterModeNotNull:
+		return newNullMatcher(m)
+	}
+	// unsupported
+	return &noopMatcher{}
+}
+
+type listMatcher[T Number] struct {
+	noopMatcher
+	mode   FilterMode
+	vals   []T      // sorted unique values
+	hashes []uint64 // value hashes for bloom filters
+}
+
+func (m *listMatcher[T]) Weight() int { return 2 }
+
+func (m *listMatcher[T]) Len() int { return len(m.vals) }
+
+func (m *listMatcher[T]) Value() any {
+	if m.mode == FilterModeContains && len(m.vals) > 0 {
+		return m.vals[0]
+	}
+	return m.vals
+}
+
+func (m *listMatcher[T]) WithValue(v any) {
+	if m.mode == FilterModeContains {
+		m.WithSlice([]T{v.(T)})
+	} else {
+		m.WithSlice(v)
+	}
+}
+
+func (m *listMatcher[T]) WithSlice(slice any) {
+	m.vals = slices.Compact(slices.Sorted(slices.Values(slice.([]T))))
+	m.hashes = m.hashes[:0]
+	for _, v := range m.vals {
+		m.hashes = append(m.hashes, hash.HashT(v))
+	}
+}
+
+// match checks a single list against the matcher's values.
+func (m listMatcher[T]) match(list []T) bool {
+	if m.mode == FilterModeAll {
+		for _, v := range m.vals {
+			if !slices.Contains(list, v) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, v := range list {
+		if _, ok := slices.BinarySearch(m.vals, v); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (m listMatcher[T]) MatchValue(v any) bool {
+	switch x := v.(type) {
+	case []T:
+		return m.match(x)
+	case []byte:
+		return m.match(util.FromByteSlice[T](x))
+	default:
+		return false
+	}
+}
+
+// MatchRange checks values against min/max element ranges.
+func (m listMatcher[T]) MatchRange(from, to any) bool {
+	minv, maxv := from.(T), to.(T)
+	if len(m.vals) == 0 {
+		return m.mode == FilterModeAll
+	}
+	if m.mode == FilterModeAll {
+		return m.vals[0] >= minv && m.vals[len(m.vals)-1] <= maxv
+	}
+	i, _ := slices.BinarySearch(m.vals, minv)
+	return i < len(m.vals) && m.vals[i] <= maxv
+}
+
+func (m listMatcher[T]) MatchFilter(flt filter.Filter) bool {
+	// other filters contain numeric values for integers
+	has := func(i int) bool { return flt.Contains(uint64(m.vals[i])) }
+	if _, ok := flt.(*bloom.Filter); ok {
+		// only bloom uses hashes for all data types
+		has = func(i int) bool { return flt.Contains(m.hashes[i]) }
+	}
+	if m.mode == FilterModeAll {
+		for i := range m.vals {
+			if !has(i) {
+				return false
+			}
+		}
+		return true
+	}
+	for i := range m.vals {
+		if has(i) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m listMatcher[T]) MatchVector(b *block.Block, bits, mask *bitset.Bitset) {
+	src := b.Bytes()
+	if mask != nil {
+		for i := range mask.Iterator() {
+			if m.match(util.FromByteSlice[T](src.Get(i))) {
+				bits.Set(i)
+			}
+		}
+	} else {
+		for i, v := range src.Iterator() {
+			if m.match(util.FromByteSlice[T](v)) {
+				bits.Set(i)
+			}
+		}
+	}
+}
+
+func (m listMatcher[T]) MatchRangeVectors(mins, maxs *block.Block, bits, mask *bitset.Bitset) {
+	f := newFactory(mins.Type())
+	switch {
+	case len(m.vals) == 0:
+		// no values, ALL is always true, others never match
+		if m.mode == FilterModeAll {
+			if mask != nil {
+				bits.Copy(mask)
+			} else {
+				bits.One()
+			}
+		}
+
+	case m.mode == FilterModeAll:
+		// min <= vals[0] && max >= vals[n-1]
+		le, ge := f.New(FilterModeLe), f.New(FilterModeGe)
+		le.WithValue(m.vals[0])
+		ge.WithValue(m.vals[len(m.vals)-1])
+		minBits := bitset.New(mins.Len())
+		le.MatchVector(mins, minBits, mask)
+		if mask != nil {
+			minBits.And(mask)
+		}
+		ge.MatchVector(maxs, bits, minBits)
+		bits.And(minBits)
+		minBits.Close()
+
+	case len(m.vals) == 1:
+		// min <= v <= max
+		eq := f.New(FilterModeEqual)
+		eq.WithValue(m.vals[0])
+		eq.MatchRangeVectors(mins, maxs, bits, mask)
+
+	default:
+		// any value in min/max range
+		in := f.New(FilterModeIn)
+		in.WithSlice(m.vals)
+		in.MatchRangeVectors(mins, maxs, bits, mask)
+	}
+}
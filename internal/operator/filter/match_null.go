@@ -0,0 +1,81 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package filter
+
+import (
+	"blockwatch.cc/knoxdb/internal/bitset"
+	"blockwatch.cc/knoxdb/internal/block"
+	"blockwatch.cc/knoxdb/internal/filter"
+)
+
+// newNullMatcher returns a matcher for IS NULL and IS NOT NULL modes which
+// are type independent and match against block validity bitmaps. Min/max
+// statistics and filters ignore null values, so statistics checks are
+// always positive.
+func newNullMatcher(m FilterMode) Matcher {
+	switch m {
+	case FilterModeIsNull:
+		return &isNullMatcher{}
+	case FilterModeNotNull:
+		return &notNullMatcher{}
+	default:
+		return &noopMatcher{}
+	}
+}
+
+type nullMatcher struct {
+	noopMatcher
+}
+
+func (m nullMatcher) MatchRange(_, _ any) bool {
+	return true
+}
+
+func (m nullMatcher) MatchFilter(_ filter.Filter) bool {
+	return true
+}
+
+func (m nullMatcher) MatchRangeVectors(_, _ *block.Block, bits, mask *bitset.Bitset) {
+	if mask != nil {
+		bits.Copy(mask)
+	} else {
+		bits.One()
+	}
+}
+
+// IS NULL ---
+
+type isNullMatcher struct {
+	nullMatcher
+}
+
+func (m isNullMatcher) MatchValue(v any) bool {
+	return v == nil
+}
+
+func (m isNullMatcher) MatchVector(b *block.Block, bits, _ *bitset.Bitset) {
+	if valid := b.Valid(); valid != nil {
+		bits.Copy(valid).Neg()
+	} else {
+		bits.Zero()
+	}
+}
+
+// IS NOT NULL ---
+
+type notNullMatcher struct {
+	nullMatcher
+}
+
+func (m notNullMatcher) MatchValue(v any) bool {
+	return v != nil
+}
+
+func (m notNullMatcher) MatchVector(b *block.Block, bits, _ *bitset.Bitset) {
+	if valid := b.Valid(); valid != nil {
+		bits.Copy(valid)
+	} else {
+		bits.One()
+	}
+}
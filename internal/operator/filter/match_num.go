@@ -296,6 +296,8 @@ func (f NumMatcherFactory[T]) New(m FilterMode) Matcher {
 		default:
 			return &numNotInSetMatcher[T]{}
 		}
+	case FilterModeIsNull, FilterModeNotNull:
+		return newNullMatcher(m)
 	default:
 		// unsupported
 		// FilterModeRegexp
@@ -4,12 +4,17 @@
 package filter
 
 import (
+	"math/big"
 	"regexp"
+	"slices"
 	"testing"
 
 	"blockwatch.cc/knoxdb/internal/bitset"
 	"blockwatch.cc/knoxdb/internal/block"
+	"blockwatch.cc/knoxdb/internal/filter/bloom"
+	"blockwatch.cc/knoxdb/internal/hash"
 	"blockwatch.cc/knoxdb/internal/tests"
+	"blockwatch.cc/knoxdb/internal/types"
 	"blockwatch.cc/knoxdb/internal/xroar"
 	"blockwatch.cc/knoxdb/pkg/num"
 	"blockwatch.cc/knoxdb/pkg/slicex"
@@ -34,6 +39,7 @@ var (
 		BlockBool,
 		BlockInt128,
 		BlockInt256,
+		BlockBigint,
 	}
 	testMatchSingleValueModes = []FilterMode{
 		FilterModeEqual,
@@ -75,6 +81,12 @@ func makeRandomValue(typ BlockType) any {
 		return num.Int128From2Int64(util.RandInt64(), util.RandInt64())
 	case BlockInt256:
 		return num.Int256From4Int64(util.RandInt64(), util.RandInt64(), util.RandInt64(), util.RandInt64())
+	case BlockBigint:
+		v := num.NewFromBigInt(new(big.Int).SetBytes(util.RandBytes(1 + util.RandIntn(40))))
+		if util.RandIntn(2) == 1 {
+			v = v.Neg()
+		}
+		return v
 	default:
 		return nil
 	}
@@ -112,6 +124,8 @@ func makeRandomBlock(typ BlockType, sz int) *block.Block {
 			b.Int128().Append(num.Int128From2Int64(util.RandInt64(), util.RandInt64()))
 		case BlockInt256:
 			b.Int256().Append(num.Int256From4Int64(util.RandInt64(), util.RandInt64(), util.RandInt64(), util.RandInt64()))
+		case BlockBigint:
+			b.Bytes().Append(makeRandomValue(typ).(num.Big).Bytes())
 		}
 	}
 	return b
@@ -227,7 +241,7 @@ func TestMatchSet(t *testing.T) {
 
 			// bitmap matching only supported in int types
 			switch gen.Type() {
-			case BlockFloat32, BlockFloat64, BlockBytes, BlockInt128, BlockInt256:
+			case BlockFloat32, BlockFloat64, BlockBytes, BlockInt128, BlockInt256, BlockBigint:
 				return
 			}
 			set := xroar.New()
@@ -280,6 +294,188 @@ func TestMatchRegexp(t *testing.T) {
 	require.True(t, set.Contains(0))
 }
 
+func TestMatchContains(t *testing.T) {
+	vals := [][]byte{[]byte("Hello World"), []byte("yellow"), []byte("help"), nil}
+	b := block.New(BlockBytes, len(vals))
+	for _, v := range vals {
+		b.Bytes().Append(v)
+	}
+
+	// case-insensitive substring, string and bytes values
+	for _, v := range []any{"LLo", []byte("llo")} {
+		m := newFactory(BlockBytes).New(FilterModeContains)
+		m.WithValue(v)
+		assert.Equal(t, []byte("llo"), m.Value())
+		assert.True(t, m.MatchValue(vals[0]), "match")
+		assert.True(t, m.MatchValue(vals[1]), "match")
+		assert.False(t, m.MatchValue(vals[2]), "nomatch")
+		assert.False(t, m.MatchValue(vals[3]), "nomatch empty")
+		assert.True(t, m.MatchRange(vals[0], vals[1]), "range-always-true")
+
+		// block match
+		set := bitset.New(len(vals))
+		m.MatchVector(b, set, nil)
+		require.Equal(t, []int{0, 1}, slices.Collect(set.Iterator()))
+
+		// masked block match
+		mask := bitset.New(len(vals))
+		mask.Set(1)
+		mask.Set(2)
+		set.Zero()
+		m.MatchVector(b, set, mask)
+		require.Equal(t, []int{1}, slices.Collect(set.Iterator()))
+
+		// range vectors are undecided
+		set.Zero()
+		m.MatchRangeVectors(b, b, set, mask)
+		require.Equal(t, []int{1, 2}, slices.Collect(set.Iterator()))
+	}
+
+	// empty substring matches all values
+	m := newFactory(BlockBytes).New(FilterModeContains)
+	m.WithValue("")
+	set := bitset.New(len(vals))
+	m.MatchVector(b, set, nil)
+	require.Equal(t, len(vals), set.Count())
+}
+
+func TestMatchTerms(t *testing.T) {
+	vals := [][]byte{
+		[]byte("The quick brown fox"),
+		[]byte("quick-silver"),
+		[]byte("lazy dog, brown fox"),
+		[]byte("foxes"),
+	}
+	b := block.New(BlockBytes, len(vals))
+	for _, v := range vals {
+		b.Bytes().Append(v)
+	}
+
+	cases := []struct {
+		query string
+		want  []int
+	}{
+		{"fox", []int{0, 2}},
+		{"Quick", []int{0, 1}},
+		{"brown FOX", []int{0, 2}},
+		{"quick fox", []int{0}},
+		{"silver | dog", []int{1, 2}},
+		{"quick brown | foxes", []int{0, 3}},
+		{"cat", nil},
+		{"", nil},
+		{" | ", nil},
+	}
+	for _, c := range cases {
+		m := newFactory(BlockBytes).New(FilterModeMatch)
+		m.WithValue(c.query)
+		assert.Equal(t, []byte(c.query), m.Value(), c.query)
+		for i, v := range vals {
+			assert.Equal(t, slices.Contains(c.want, i), m.MatchValue(v), "%q on %q", c.query, v)
+		}
+		set := bitset.New(len(vals))
+		m.MatchVector(b, set, nil)
+		require.Equal(t, c.want, slices.Collect(set.Iterator()), c.query)
+
+		// masked block match
+		mask := bitset.New(len(vals))
+		mask.Set(0)
+		set.Zero()
+		m.MatchVector(b, set, mask)
+		require.Equal(t, slices.Contains(c.want, 0), set.Contains(0), c.query)
+		require.LessOrEqual(t, set.Count(), 1, c.query)
+	}
+}
+
+func TestMatchBigOrder(t *testing.T) {
+	// wire format byte order equals numeric order across signs
+	vals := []int64{255, 256, 1, 1 << 16, 0, -1, -256}
+	b := block.New(BlockBigint, len(vals))
+	for _, v := range vals {
+		b.Append(num.NewBig(v))
+	}
+	defer b.Deref()
+
+	for _, c := range []struct {
+		mode FilterMode
+		val  any
+		res  []uint32
+	}{
+		{FilterModeEqual, num.NewBig(256), []uint32{1}},
+		{FilterModeEqual, num.NewBig(-256), []uint32{6}},
+		{FilterModeGt, num.NewBig(255), []uint32{1, 3}},
+		{FilterModeGt, num.NewBig(-256), []uint32{0, 1, 2, 3, 4, 5}},
+		{FilterModeGe, num.NewBig(256), []uint32{1, 3}},
+		{FilterModeLt, num.NewBig(256), []uint32{0, 2, 4, 5, 6}},
+		{FilterModeLt, num.NewBig(0), []uint32{5, 6}},
+		{FilterModeLe, num.NewBig(1), []uint32{2, 4, 5, 6}},
+		{FilterModeRange, RangeValue{num.NewBig(2), num.NewBig(1000)}, []uint32{0, 1}},
+		{FilterModeRange, RangeValue{num.NewBig(-300), num.NewBig(-1)}, []uint32{5, 6}},
+		{FilterModeIn, []num.Big{num.NewBig(1 << 16), num.NewBig(1), num.NewBig(-1)}, []uint32{2, 3, 5}},
+		{FilterModeNotIn, []num.Big{num.NewBig(0), num.NewBig(256), num.NewBig(-256)}, []uint32{0, 2, 3, 5}},
+	} {
+		m := newFactory(BlockBigint).New(c.mode)
+		m.WithValue(c.val)
+		set := bitset.New(b.Len())
+		m.MatchVector(b, set, nil)
+		require.Equal(t, c.res, set.Indexes(nil), c.mode.String())
+		set.Close()
+	}
+
+	// zone map min/max use numeric order
+	minv, maxv := b.MinMax()
+	require.Equal(t, num.NewBig(-256).Bytes(), minv)
+	require.Equal(t, num.NewBig(1<<16).Bytes(), maxv)
+}
+
+func TestMatchList(t *testing.T) {
+	vals := [][]uint32{{1, 2, 3}, {}, {3, 9}, {7}}
+	b := block.NewList(BlockUint32, len(vals))
+	defer b.Deref()
+	for _, v := range vals {
+		b.Append(v)
+	}
+
+	// element bloom filter
+	flt := bloom.NewFilter(64 * 8)
+	for _, v := range vals {
+		for _, x := range v {
+			flt.Add(hash.HashT(x))
+		}
+	}
+
+	f := NewListFactory(types.FieldTypeUint32)
+	for _, c := range []struct {
+		mode FilterMode
+		val  any
+		res  []uint32
+		rg   bool // element range [1,9]
+		flt  bool
+	}{
+		{FilterModeContains, uint32(3), []uint32{0, 2}, true, true},
+		{FilterModeContains, uint32(4), []uint32{}, true, false},
+		{FilterModeAny, []uint32{7, 9}, []uint32{2, 3}, true, true},
+		{FilterModeAny, []uint32{10, 20}, []uint32{}, false, false},
+		{FilterModeAll, []uint32{1, 3}, []uint32{0}, true, true},
+		{FilterModeAll, []uint32{3, 10}, []uint32{}, false, false},
+	} {
+		m := f.New(c.mode)
+		m.WithValue(c.val)
+		set := bitset.New(b.Len())
+		m.MatchVector(b, set, nil)
+		require.Equal(t, c.res, set.Indexes(nil), "%s %v", c.mode, c.val)
+		require.Equal(t, c.rg, m.MatchRange(uint32(1), uint32(9)), "range %s %v", c.mode, c.val)
+		require.Equal(t, c.flt, m.MatchFilter(flt), "filter %s %v", c.mode, c.val)
+		if len(c.res) > 0 {
+			require.True(t, m.MatchValue(vals[c.res[0]]), "value %s %v", c.mode, c.val)
+			require.True(t, m.MatchValue(b.Get(int(c.res[0]))), "wire value %s %v", c.mode, c.val)
+		}
+		set.Close()
+	}
+
+	// unsupported modes are noop
+	require.IsType(t, &noopMatcher{}, f.New(FilterModeEqual))
+}
+
 func TestMatchBool(t *testing.T) {
 	eq := newFactory(BlockBool).New(FilterModeEqual)
 	eq.WithValue(false)
@@ -408,3 +604,98 @@ func TestMatchBool(t *testing.T) {
 	assert.True(t, rg.MatchRange(false, true), "range-match")
 	assert.True(t, rg.MatchRange(true, true), "range-match")
 }
+
+func TestMatchNull(t *testing.T) {
+	for _, typ := range testMatchBlockTypes {
+		b := makeRandomBlock(typ, matchBlockSize)
+		b.SetNull(1)
+		b.SetNull(7)
+
+		// IS NULL
+		m := newFactory(typ).New(FilterModeIsNull)
+		require.IsType(t, &isNullMatcher{}, m, typ.String())
+		require.True(t, m.MatchValue(nil))
+		require.True(t, m.MatchRange(nil, nil))
+		set := bitset.New(matchBlockSize)
+		m.MatchVector(b, set, nil)
+		require.Equal(t, []uint32{1, 7}, set.Indexes(nil), typ.String())
+
+		// IS NOT NULL
+		m = newFactory(typ).New(FilterModeNotNull)
+		require.False(t, m.MatchValue(nil))
+		m.MatchVector(b, set, nil)
+		require.Equal(t, matchBlockSize-2, set.Count(), typ.String())
+		require.False(t, set.Contains(1))
+		require.False(t, set.Contains(7))
+		b.Deref()
+	}
+}
+
+func TestMatchDocument(t *testing.T) {
+	docs := []string{
+		`{"amount":1,"to":"a","x":{"ok":true}}`,
+		`{"amount":2.5,"to":"b"}`,
+		`{"other":[1,2]}`,
+		``,
+		`{"amount":150,"to":"a","x":{"ok":false}}`,
+	}
+	b := block.New(block.BlockDocument, len(docs))
+	defer b.Deref()
+	for _, v := range docs {
+		b.Append([]byte(v))
+	}
+
+	// shredded block from encode/decode roundtrip
+	buf, ctx, err := b.Encode(0)
+	require.NoError(t, err)
+	ctx.Close()
+	dec, err := block.Decode(block.BlockDocument, buf)
+	require.NoError(t, err)
+	defer dec.Deref()
+	require.NotNil(t, dec.DocPath("amount"))
+
+	for _, c := range []struct {
+		path string
+		mode FilterMode
+		val  any
+		res  []uint32
+	}{
+		{"amount", FilterModeGt, int64(100), []uint32{4}},
+		{"amount", FilterModeLe, 2.5, []uint32{0, 1}},
+		{"amount", FilterModeEqual, int64(1), []uint32{0}},
+		{"amount", FilterModeNotEqual, int64(1), []uint32{1, 4}},
+		{"amount", FilterModeRange, RangeValue{int64(2), int64(200)}, []uint32{1, 4}},
+		{"amount", FilterModeIn, []any{int64(1), 2.5}, []uint32{0, 1}},
+		{"amount", FilterModeEqual, "1", []uint32{}},
+		{"amount", FilterModeIsNull, nil, []uint32{2, 3}},
+		{"amount", FilterModeNotNull, nil, []uint32{0, 1, 4}},
+		{"to", FilterModeEqual, "a", []uint32{0, 4}},
+		{"to", FilterModeNotIn, []any{"a"}, []uint32{1}},
+		{"x.ok", FilterModeEqual, true, []uint32{0}},
+		{"other", FilterModeNotNull, nil, []uint32{}},
+		{"missing", FilterModeIsNull, nil, []uint32{0, 1, 2, 3, 4}},
+	} {
+		m := NewDocFactory(c.path).New(c.mode)
+		switch c.mode {
+		case FilterModeIn, FilterModeNotIn:
+			m.WithSlice(c.val)
+		case FilterModeIsNull, FilterModeNotNull:
+		default:
+			m.WithValue(c.val)
+		}
+		for _, src := range []*block.Block{b, dec} {
+			set := bitset.New(src.Len())
+			m.MatchVector(src, set, nil)
+			require.Equal(t, c.res, set.Indexes(nil), "%s %s %v", c.path, c.mode, c.val)
+			set.Close()
+		}
+		for i, v := range docs {
+			require.Equal(t, slices.Contains(c.res, uint32(i)), m.MatchValue([]byte(v)),
+				"value %d %s %s %v", i, c.path, c.mode, c.val)
+		}
+		require.True(t, m.MatchRange([]byte{}, []byte{}))
+	}
+
+	// unsupported modes are noop
+	require.IsType(t, &noopMatcher{}, NewDocFactory("to").New(FilterModeRegexp))
+}
@@ -140,9 +140,10 @@ func (n *Node) Optimize() {
 }
 
 func simplifyNodes(nodes []*Node, isOrNode bool) []*Node {
-	// split leafs from nested nodes
+	// split leafs from nested nodes, keep expression and document path
+	// filters as is because range and set merges work on raw field values
 	branches, leafs, ok := slicex.CutFunc(nodes, func(n *Node) bool {
-		return !n.IsLeaf()
+		return !n.IsLeaf() || n.Filter.Expr.IsValid() || n.Filter.Type == BlockDocument
 	})
 
 	// nothing to do if there are no leafs
@@ -8,6 +8,7 @@ import (
 
 	"blockwatch.cc/knoxdb/internal/block"
 	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/schema"
 	"blockwatch.cc/knoxdb/pkg/util"
 )
 
@@ -15,6 +16,7 @@ type (
 	FilterMode = types.FilterMode
 	BlockType  = block.BlockType
 	FieldType  = types.FieldType
+	IndexExpr  = schema.IndexExpr
 )
 
 const (
@@ -31,24 +33,34 @@ const (
 	FilterModeRegexp   = types.FilterModeRegexp   // 10
 	FilterModeTrue     = types.FilterModeTrue     // 11
 	FilterModeFalse    = types.FilterModeFalse    // 12
+	FilterModeContains = types.FilterModeContains // 13
+	FilterModeMatch    = types.FilterModeMatch    // 14
+	FilterModeIsNull   = types.FilterModeIsNull   // 15
+	FilterModeNotNull  = types.FilterModeNotNull  // 16
+	FilterModeAny      = types.FilterModeAny      // 17
+	FilterModeAll      = types.FilterModeAll      // 18
+	FilterModePrefix   = types.FilterModePrefix   // 19
 )
 
 const (
-	BlockInvalid = types.BlockInvalid
-	BlockInt64   = types.BlockInt64
-	BlockInt32   = types.BlockInt32
-	BlockInt16   = types.BlockInt16
-	BlockInt8    = types.BlockInt8
-	BlockUint64  = types.BlockUint64
-	BlockUint32  = types.BlockUint32
-	BlockUint16  = types.BlockUint16
-	BlockUint8   = types.BlockUint8
-	BlockFloat64 = types.BlockFloat64
-	BlockFloat32 = types.BlockFloat32
-	BlockBool    = types.BlockBool
-	BlockBytes   = types.BlockBytes
-	BlockInt128  = types.BlockInt128
-	BlockInt256  = types.BlockInt256
+	BlockInvalid  = types.BlockInvalid
+	BlockInt64    = types.BlockInt64
+	BlockInt32    = types.BlockInt32
+	BlockInt16    = types.BlockInt16
+	BlockInt8     = types.BlockInt8
+	BlockUint64   = types.BlockUint64
+	BlockUint32   = types.BlockUint32
+	BlockUint16   = types.BlockUint16
+	BlockUint8    = types.BlockUint8
+	BlockFloat64  = types.BlockFloat64
+	BlockFloat32  = types.BlockFloat32
+	BlockBool     = types.BlockBool
+	BlockBytes    = types.BlockBytes
+	BlockInt128   = types.BlockInt128
+	BlockInt256   = types.BlockInt256
+	BlockBigint   = types.BlockBigint
+	BlockList     = types.BlockList
+	BlockDocument = types.BlockDocument
 )
 
 type RangeValue [2]any
@@ -14,6 +14,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -63,6 +64,7 @@ type JoinPlan struct {
 
 	schema *schema.Schema // result schema (mixed between tables, renamed fields)
 	buf    *bytes.Buffer  // staging buffer for result merge
+	stage  *pack.Package  // single row staging pack for result merge
 }
 
 func NewJoinPlan() *JoinPlan {
@@ -82,6 +84,10 @@ func (p *JoinPlan) Close() {
 	p.Log = nil
 	p.schema = nil
 	p.buf = nil
+	if p.stage != nil {
+		p.stage.Release()
+		p.stage = nil
+	}
 }
 
 func (p *JoinPlan) Runtime() time.Duration {
@@ -174,6 +180,10 @@ type JoinTable struct {
 	Filter *Filter         // updatable query filter for each step
 	PkIdx  int             // schema/block index of primary key field (0 == not exist)
 	OnIdx  int             // schema/block index of the predicate `on` column
+	Fetch  *schema.Schema  // table query schema, select fields plus primary key
+	Cols   []int           // result column indexes of select fields
+	ResPk  int             // result column index of the primary key field
+	ResOn  int             // result column index of the predicate `on` column
 }
 
 func (j JoinTable) Validate(kind string) error {
@@ -214,6 +224,39 @@ func (j JoinTable) Validate(kind string) error {
 	return nil
 }
 
+// compileFetch builds the table query schema from selected fields. The
+// primary key is always fetched because it serves as scan cursor.
+func (j *JoinTable) compileFetch() error {
+	s := j.Table.Schema()
+	ids := j.Select.Ids()
+	if !slices.Contains(ids, s.PkId()) {
+		ids = append(slices.Clone(ids), s.PkId())
+	}
+	fetch, err := s.SelectIds(ids...)
+	if err != nil {
+		return err
+	}
+	j.Fetch = fetch
+	j.ResPk, _ = fetch.IndexId(s.PkId())
+	j.ResOn, _ = fetch.IndexId(j.On.Id)
+	j.Cols = make([]int, 0, j.Select.NumFields())
+	for _, id := range j.Select.Ids() {
+		idx, _ := fetch.IndexId(id)
+		j.Cols = append(j.Cols, idx)
+	}
+	return nil
+}
+
+// appendWire writes selected fields of a result row in wire format. Negative
+// row numbers (unmatched rows in outer joins) produce zero values.
+func (j *JoinTable) appendWire(buf *bytes.Buffer, res QueryResult, row int) error {
+	if row < 0 {
+		_, err := buf.Write(make([]byte, j.Select.WireSize()))
+		return err
+	}
+	return res.Pack().ReadWireFields(buf, row, j.Cols)
+}
+
 func (p *JoinPlan) Name() string {
 	return strings.Join([]string{
 		p.Left.Table.Schema().Name,
@@ -288,11 +331,14 @@ func (p *JoinPlan) Compile(ctx context.Context) error {
 	p.Left.Typ = p.Left.On.Type.BlockType()
 	p.Right.Typ = p.Right.On.Type.BlockType()
 	p.Left.OnIdx, _ = ltab.IndexId(p.Left.On.Id)
-	p.Right.OnIdx, _ = ltab.IndexId(p.Right.On.Id)
+	p.Right.OnIdx, _ = rtab.IndexId(p.Right.On.Id)
 
 	// default names {table_name}.{field_name}
 	for i, field := range p.Left.Select.Fields {
-		alias := p.Left.As[i]
+		var alias string
+		if i < len(p.Left.As) {
+			alias = p.Left.As[i]
+		}
 		if alias == "" {
 			alias = ltab.Name + "." + field.Name
 		}
@@ -306,7 +352,10 @@ func (p *JoinPlan) Compile(ctx context.Context) error {
 	}
 
 	for i, field := range p.Right.Select.Fields {
-		alias := p.Right.As[i]
+		var alias string
+		if i < len(p.Right.As) {
+			alias = p.Right.As[i]
+		}
 		if alias == "" {
 			alias = rtab.Name + "." + field.Name
 		}
@@ -327,34 +376,32 @@ func (p *JoinPlan) Compile(ctx context.Context) error {
 
 	// alloc staging buffer
 	p.buf = bytes.NewBuffer(make([]byte, 0, p.schema.WireSize()))
+	p.stage = pack.New().WithMaxRows(1).WithSchema(p.schema).Alloc()
 
-	// construct and compile query plans, run index scans
-	p.Left.Plan = query.NewQueryPlan().
-		WithTag(p.schema.Name).
-		WithTable(p.Left.Table).
-		WithSchema(p.Left.Select).
-		WithFilters(p.Left.Where).
-		WithLogger(p.Log)
-
-	if err := p.Left.Plan.Compile(ctx); err != nil {
+	// fetch selected fields plus primary keys
+	if err := p.Left.compileFetch(); err != nil {
 		return err
 	}
-	if err := p.Left.Plan.QueryIndexes(ctx); err != nil {
+	if err := p.Right.compileFetch(); err != nil {
 		return err
 	}
 
-	p.Right.Plan = query.NewQueryPlan().
-		WithTag(p.schema.Name).
-		WithTable(p.Right.Table).
-		WithSchema(p.Right.Select).
-		WithFilters(p.Right.Where).
-		WithLogger(p.Log)
-
-	if err := p.Right.Plan.Compile(ctx); err != nil {
-		return err
-	}
-	if err := p.Right.Plan.QueryIndexes(ctx); err != nil {
-		return err
+	// construct and compile query plans for cardinality estimation,
+	// step plans are compiled from copies of the table filters
+	for _, j := range []*JoinTable{&p.Left, &p.Right} {
+		j.Plan = query.NewQueryPlan().
+			WithTag(p.schema.Name).
+			WithTable(j.Table).
+			WithSchema(j.Fetch).
+			WithFilters(cloneNode(j.Where)).
+			WithLogger(p.Log)
+
+		if err := j.Plan.Compile(ctx); err != nil {
+			return err
+		}
+		if err := j.Plan.QueryIndexes(ctx); err != nil {
+			return err
+		}
 	}
 
 	// identify join order when user-defined order is not set
@@ -390,42 +437,77 @@ func (p *JoinPlan) Compile(ctx context.Context) error {
 
 	// pk cursor on large side
 	pkField := x.Table.Schema().Pk()
-	pkBlockTyp := pkField.Type.BlockType()
 	matcher := filter.NewFactory(pkField.Type).New(types.FilterModeGt)
 	x.Filter = &Filter{
 		Name:    pkField.Name,
-		Type:    pkBlockTyp,
+		Type:    pkField.Type.BlockType(),
 		Mode:    types.FilterModeGt,
 		Index:   x.PkIdx,
 		Id:      pkField.Id,
 		Matcher: matcher, // zero
 		Value:   matcher.Value(),
 	}
-	x.Where.Children = append(x.Where.Children, &FilterNode{Filter: x.Filter})
 
 	// add limit to large side
-	x.Plan.WithLimit(p.Limit)
+	x.Limit = p.Limit
 
 	// IN condition for join predicate column on small side ONLY for equi-joins
 	if p.IsEquiJoin() {
 		joinField := y.On
-		joinBlockType := joinField.Type.BlockType()
 		matcher = filter.NewFactory(joinField.Type).New(types.FilterModeIn)
 		y.Filter = &Filter{
 			Name:    joinField.Name,
-			Type:    joinBlockType,
+			Type:    joinField.Type.BlockType(),
 			Mode:    types.FilterModeIn,
 			Index:   y.OnIdx,
 			Id:      joinField.Id,
 			Matcher: matcher, // updated during processing
 			Value:   nil,     // updated during processing
 		}
-		y.Where.Children = append(y.Where.Children, &FilterNode{Filter: y.Filter})
 	}
 
 	return nil
 }
 
+// makePlan compiles a query plan for the next fetch step. Query plans
+// rewrite their filter tree during compile and index scans, so each step
+// works on a copy of the table filters extended by the step filter.
+func (p *JoinPlan) makePlan(ctx context.Context, j *JoinTable) (*QueryPlan, error) {
+	where := cloneNode(j.Where)
+	if j.Filter != nil {
+		where.And(filter.NewNode().SetFilter(j.Filter))
+	}
+	plan := query.NewQueryPlan().
+		WithTag(p.schema.Name).
+		WithTable(j.Table).
+		WithSchema(j.Fetch).
+		WithFilters(where).
+		WithLimit(j.Limit).
+		WithFlags(p.Flags).
+		WithLogger(p.Log)
+	if err := plan.Compile(ctx); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// cloneNode copies the structure of a filter tree. Filters are shared.
+func cloneNode(n *FilterNode) *FilterNode {
+	c := filter.NewNode()
+	if n == nil {
+		return c
+	}
+	c.Filter = n.Filter
+	c.OrKind = n.OrKind
+	if len(n.Children) > 0 {
+		c.Children = make([]*FilterNode, len(n.Children))
+		for i, v := range n.Children {
+			c.Children[i] = cloneNode(v)
+		}
+	}
+	return c
+}
+
 func (p *JoinPlan) Stream(ctx context.Context, fn func(r engine.QueryRow) error) error {
 	if err := p.Compile(ctx); err != nil {
 		return err
@@ -504,8 +586,12 @@ func (p *JoinPlan) doJoin(ctx context.Context, out QueryResultConsumer) error {
 		err        error
 	)
 	defer func() {
-		lRes.Close()
-		rRes.Close()
+		if lRes != nil {
+			lRes.Close()
+		}
+		if rRes != nil {
+			rRes.Close()
+		}
 	}()
 
 	// use row_id as an extra cursor to fetch a new block of matching rows
@@ -514,16 +600,16 @@ func (p *JoinPlan) doJoin(ctx context.Context, out QueryResultConsumer) error {
 		// QUERY
 		// ------------------------------------------------------------
 		if p.Order == JoinOrderLeftRight {
-			lRes, rRes, err = p.doQuery(ctx, p.Left, p.Right)
+			lRes, rRes, err = p.doQuery(ctx, &p.Left, &p.Right)
 		} else {
-			lRes, rRes, err = p.doQuery(ctx, p.Right, p.Left)
+			rRes, lRes, err = p.doQuery(ctx, &p.Right, &p.Left)
 		}
 		if err != nil {
 			break
 		}
 
 		// exit when no more rows are found
-		if lRes.Len() == 0 || rRes.Len() == 0 {
+		if lRes == nil || rRes == nil || lRes.Len() == 0 || rRes.Len() == 0 {
 			p.Log.Debugf("J> %s: FINAL result with %d rows", p.Tag, out.Len())
 			break
 		}
@@ -612,32 +698,25 @@ func (p *JoinPlan) doJoin(ctx context.Context, out QueryResultConsumer) error {
 	return err
 }
 
-func (p *JoinPlan) doQuery(ctx context.Context, x, y JoinTable) (xRes QueryResult, yRes QueryResult, err error) {
+func (p *JoinPlan) doQuery(ctx context.Context, x, y *JoinTable) (xRes QueryResult, yRes QueryResult, err error) {
 	// fetch names once for debugging
 	xname, yname := x.Table.Schema().Name, y.Table.Schema().Name
 
 	// 1  query first side of the join
-	if p.Flags.IsDebug() {
-		p.Log.Debugf("J> %s: %s %s", p.Tag, xname, x.Plan)
-	}
-
-	// run query
-	xRes, err = x.Table.Query(ctx, x.Plan)
-	if err != nil {
+	xRes, err = p.runStep(ctx, x)
+	if err != nil || xRes.Len() == 0 {
 		return
 	}
-
 	p.Log.Debugf("J> %s: %s result %d rows", p.Tag, xname, xRes.Len())
 
 	// update pk cursor on first side
-	pk := xRes.Value(xRes.Len()-1, x.PkIdx)
+	pk := xRes.Value(xRes.Len()-1, x.ResPk)
 	if pk == nil {
 		err = fmt.Errorf("%s: missing pk column in %s query result", p.Tag, xname)
 		return
 	}
-
-	// update plan
 	x.Filter.Matcher.WithValue(pk)
+	x.Filter.Value = pk
 
 	// 2  query second side
 
@@ -646,57 +725,62 @@ func (p *JoinPlan) doQuery(ctx context.Context, x, y JoinTable) (xRes QueryResul
 		// Note: xRes is in row layout here
 		set := xroar.New()
 		for _, row := range xRes.Iterator() {
-			u64, _ := types.Cast[uint64](row.Get(x.OnIdx))
+			u64, _ := types.Cast[uint64](row.Get(x.ResOn))
 			set.Set(u64)
 		}
 		y.Filter.Matcher.WithSet(set)
+		y.Filter.Value = y.Filter.Matcher.Value()
 	}
 
-	if p.Flags.IsDebug() {
-		p.Log.Debugf("J> %s: %s %s", p.Tag, yname, y.Plan)
-	}
-
-	// run query
-	yRes, err = y.Table.Query(ctx, y.Plan)
+	yRes, err = p.runStep(ctx, y)
 	if err != nil {
 		return
 	}
-
 	p.Log.Debugf("J> %s: %s result %d rows", p.Tag, yname, yRes.Len())
 	return
 }
 
+// runStep queries a join table with the current step filter.
+func (p *JoinPlan) runStep(ctx context.Context, j *JoinTable) (QueryResult, error) {
+	plan, err := p.makePlan(ctx, j)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		j.Plan.Stats.Merge(&plan.Stats)
+		plan.Close()
+	}()
+	if p.Flags.IsDebug() {
+		p.Log.Debugf("J> %s: %s %s", p.Tag, j.Table.Schema().Name, plan)
+	}
+	return j.Table.Query(ctx, plan)
+}
+
 func (p *JoinPlan) matchAt(a QueryResult, ra int, b QueryResult, rb int) bool {
-	v1 := a.Value(ra, p.Left.OnIdx)
-	v2 := b.Value(rb, p.Right.OnIdx)
+	v1 := a.Value(ra, p.Left.ResOn)
+	v2 := b.Value(rb, p.Right.ResOn)
 	return p.Left.Typ.Match(p.Mode, v1, v2)
 }
 
 func (p *JoinPlan) compareAt(a QueryResult, ra int, b QueryResult, rb int) int {
-	v1 := a.Value(ra, p.Left.OnIdx)
-	v2 := b.Value(rb, p.Right.OnIdx)
+	v1 := a.Value(ra, p.Left.ResOn)
+	v2 := b.Value(rb, p.Right.ResOn)
 	return p.Left.Typ.Cmp(v1, v2)
 }
 
 func (p *JoinPlan) appendResult(out QueryResultConsumer, left QueryResult, l int, right QueryResult, r int) error {
-	// merge/append and forward into result type, when row number is negative
-	// fill with zero value data
-
-	// TODO: merge left and right columns into join result pack
-
-	// p.buf.Reset()
-	// if l >= 0 {
-	// 	p.buf.Write(left.Record(l))
-	// } else {
-	// 	p.buf.Write(bytes.Repeat([]byte{0}, left.Schema().WireSize()))
-	// }
-	// if r >= 0 {
-	// 	p.buf.Write(right.Record(r))
-	// } else {
-	// 	p.buf.Write(bytes.Repeat([]byte{0}, right.Schema().WireSize()))
-	// }
-	// return out.Append(p.buf.Bytes(), false)
-	return nil
+	// merge selected left and right columns into a single result row, when
+	// row number is negative fill with zero value data
+	p.buf.Reset()
+	if err := p.Left.appendWire(p.buf, left, l); err != nil {
+		return err
+	}
+	if err := p.Right.appendWire(p.buf, right, r); err != nil {
+		return err
+	}
+	p.stage.Clear()
+	p.stage.AppendWire(p.buf.Bytes(), nil)
+	return out.Append(context.Background(), p.stage)
 }
 
 // non-equi joins
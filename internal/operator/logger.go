@@ -64,6 +64,21 @@ func (op *Logger) Process(_ context.Context, src *pack.Package) (*pack.Package,
 					})
 				}
 			}
+		case types.FieldTypeUint32:
+			if field.Flags.Is(types.FieldFlagEnum) && s.HasEnums() {
+				if lut, ok := s.Enums.Load().Lookup(field.Name); ok {
+					cfgs = append(cfgs, table.ColumnConfig{
+						Name: field.Name,
+						Transformer: func(val any) string {
+							enum, ok := lut.Value32(val.(uint32))
+							if ok {
+								return enum
+							}
+							return strconv.FormatUint(uint64(val.(uint32)), 10)
+						},
+					})
+				}
+			}
 		case types.FieldTypeTimestamp, types.FieldTypeDate, types.FieldTypeTime:
 			cfgs = append(cfgs, table.ColumnConfig{
 				Name: field.Name,
@@ -4,6 +4,8 @@
 package pack
 
 import (
+	"encoding/json"
+
 	"blockwatch.cc/knoxdb/internal/types"
 	"blockwatch.cc/knoxdb/pkg/assert"
 	"blockwatch.cc/knoxdb/pkg/num"
@@ -47,6 +49,12 @@ func (p *Package) ReadRow(row int, dst []any) []any {
 			continue
 		}
 
+		// null values are nil
+		if b.IsNull(row) {
+			dst = append(dst, nil)
+			continue
+		}
+
 		// add to result
 		dst = append(dst, p.ReadValue(i, row, f.Type, f.Scale))
 	}
@@ -118,6 +126,10 @@ func (p *Package) ReadValue(col, row int, typ types.FieldType, scale uint8) any
 		return num.NewDecimal32(b.Int32().Get(row), scale)
 	case types.FieldTypeBigint:
 		return num.NewBigFromBytes(b.Bytes().Get(row))
+	case types.FieldTypeList:
+		return types.ListValue(b.Elem(), b.Bytes().Get(row))
+	case types.FieldTypeDocument:
+		return json.RawMessage(b.Bytes().Get(row))
 	default:
 		// oh, its a type we don't support yet
 		assert.Unreachable("unhandled field type", map[string]any{
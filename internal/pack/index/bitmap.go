@@ -0,0 +1,298 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package index
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"blockwatch.cc/knoxdb/internal/operator/filter"
+	"blockwatch.cc/knoxdb/internal/pack"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/internal/xroar"
+	"blockwatch.cc/knoxdb/pkg/num"
+	"blockwatch.cc/knoxdb/pkg/store"
+	"blockwatch.cc/knoxdb/pkg/util"
+)
+
+// Bitmap indexes store one compressed row id bitmap per distinct value
+// instead of sorted (key, rid) packs. Token indexes use the same layout
+// and store one posting list bitmap per term hash. Journal and tomb
+// records are merged into bitmaps in key order, so each merge loads and
+// stores every touched bitmap exactly once.
+//
+// Storage layout
+//   - key: varint(value or term hash) (order preserving)
+//   - val: serialized xroar bitmap of row ids
+//
+// Index state re-uses pack counters for bitmap statistics
+//   - NRows: total number of row ids
+//   - NextRid: number of bitmaps (distinct values)
+//   - NextPk: total bitmap size in bytes
+
+// isBitmap returns true when the index stores row id bitmaps per key.
+func (idx *Index) isBitmap() bool {
+	return idx.sindex.Type == types.IndexTypeBitmap || idx.sindex.Type == types.IndexTypeToken
+}
+
+func (idx *Index) encodeBitmapKey(val uint64) []byte {
+	return num.AppendUvarint(make([]byte, 0, num.MaxVarintLen64), val)
+}
+
+// writes journal records to bitmaps
+func (idx *Index) mergeBitmapAppend(ctx context.Context) error {
+	idx.log.Debugf("merging journal[%d] into bitmaps", idx.journal.Len())
+
+	// co-sort journal vectors in-place
+	j0, j1 := idx.journal.Block(0).Uint64(), idx.journal.Block(1).Uint64()
+	util.Sort2(j0.Slice(), j1.Slice())
+
+	if err := idx.mergeBitmaps(ctx, j0, j1, true); err != nil {
+		return err
+	}
+
+	// reset journal
+	idx.journal.Clear()
+
+	return nil
+}
+
+// removes tombstoned row ids from bitmaps
+func (idx *Index) mergeBitmapTomb(ctx context.Context, tomb *pack.Package) error {
+	idx.log.Debugf("merging tomb[%d] into bitmaps", tomb.Len())
+
+	// access both tomb vectors (pre-sorted on store)
+	return idx.mergeBitmaps(ctx, tomb.Block(0).Uint64(), tomb.Block(1).Uint64(), false)
+}
+
+// mergeBitmaps sets (or unsets) row ids in the bitmaps of their keys.
+// Records must be sorted by key.
+func (idx *Index) mergeBitmaps(ctx context.Context, keys, rids types.NumberAccessor[uint64], set bool) error {
+	var (
+		start                     = time.Now()
+		nRows, nKeys, nSize       int
+		nLoaded, nStored          int
+		nBytesRead, nBytesWritten int
+	)
+
+	err := idx.db.Update(func(tx store.Tx) error {
+		b := idx.dataBucket(tx)
+		if b == nil {
+			return store.ErrBucketNotFound
+		}
+
+		for i, l := 0, keys.Len(); i < l; {
+			// check context
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			// find the run of records for the next key
+			key := keys.Get(i)
+			j := i + 1
+			for j < l && keys.Get(j) == key {
+				j++
+			}
+
+			// load the key's bitmap, missing keys start with an empty bitmap
+			bkey := idx.encodeBitmapKey(key)
+			buf, err := b.Get(bkey)
+			if err != nil && !errors.Is(err, store.ErrKeyNotFound) {
+				return err
+			}
+			bits := xroar.New()
+			if len(buf) > 0 {
+				bits = xroar.NewFromBytes(buf)
+				nLoaded++
+				nBytesRead += len(buf)
+			} else if set {
+				nKeys++
+			}
+
+			// apply changes, duplicates from earlier aborted merges and
+			// stray tombstones don't count
+			for k := i; k < j; k++ {
+				rid := rids.Get(k)
+				if set {
+					if bits.Set(rid) {
+						nRows++
+					}
+				} else if bits.Unset(rid) {
+					nRows--
+				}
+			}
+			i = j
+
+			// store or drop the bitmap
+			nSize -= len(buf)
+			if bits.None() {
+				if len(buf) > 0 {
+					nKeys--
+				}
+				if err := b.Delete(bkey); err != nil {
+					return err
+				}
+				continue
+			}
+			if !set {
+				bits.Cleanup()
+			}
+			val := bits.Bytes()
+			if err := b.Put(bkey, val); err != nil {
+				return err
+			}
+			nSize += len(val)
+			nStored++
+			nBytesWritten += len(val)
+		}
+
+		// update index state
+		idx.state.NRows = uint64(max(0, int(idx.state.NRows)+nRows))
+		idx.state.NextRid = uint64(max(0, int(idx.state.NextRid)+nKeys))
+		idx.state.NextPk = uint64(max(0, int(idx.state.NextPk)+nSize))
+		return idx.state.Store(ctx, tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	// update counters
+	atomic.StoreInt64(&idx.metrics.LastMergeTime, start.UnixNano())
+	atomic.StoreInt64(&idx.metrics.LastMergeDuration, int64(time.Since(start)))
+	atomic.StoreInt64(&idx.metrics.TotalSize, int64(idx.state.NextPk))
+	atomic.StoreInt64(&idx.metrics.PacksCount, int64(idx.state.NextRid))
+	atomic.AddInt64(&idx.metrics.NumCalls, 1)
+	if set {
+		atomic.AddInt64(&idx.metrics.InsertedTuples, int64(nRows))
+	} else {
+		atomic.AddInt64(&idx.metrics.DeletedTuples, int64(-nRows))
+	}
+	atomic.AddInt64(&idx.metrics.PacksLoaded, int64(nLoaded))
+	atomic.AddInt64(&idx.metrics.PacksStored, int64(nStored))
+	atomic.AddInt64(&idx.metrics.BytesRead, int64(nBytesRead))
+	atomic.AddInt64(&idx.metrics.BytesWritten, int64(nBytesWritten))
+
+	idx.log.Debugf("merged bitmaps loaded=%d stored=%d rows=%d/%d total_size=%s in %s",
+		nLoaded,
+		nStored,
+		nRows,
+		keys.Len(),
+		util.ByteSize(nBytesWritten),
+		time.Since(start),
+	)
+
+	return nil
+}
+
+// queryBitmaps returns the union of bitmaps for EQ and IN conditions. NI
+// conditions return the union of all other bitmaps which is exact because
+// each indexed row is contained in exactly one bitmap.
+func (idx *Index) queryBitmaps(ctx context.Context, node *filter.Node) (*xroar.Bitmap, error) {
+	var (
+		bits = xroar.New()
+		keys = idx.convert.QueryKeys(node)
+	)
+
+	err := idx.db.View(func(tx store.Tx) error {
+		b := idx.dataBucket(tx)
+		if b == nil {
+			return store.ErrBucketNotFound
+		}
+
+		// scan all bitmaps except excluded keys
+		if node.Filter.Mode == types.FilterModeNotIn {
+			skip := make(map[uint64]struct{}, len(keys))
+			for _, key := range keys {
+				skip[key] = struct{}{}
+			}
+			for k, v := range b.Scan(nil) {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				key, _ := num.Uvarint(k)
+				if _, ok := skip[key]; ok {
+					continue
+				}
+				bits.Or(xroar.NewFromShared(v))
+			}
+			return nil
+		}
+
+		// lookup bitmaps by key
+		for _, key := range keys {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			buf, err := b.Get(idx.encodeBitmapKey(key))
+			if err != nil {
+				if errors.Is(err, store.ErrKeyNotFound) {
+					continue
+				}
+				return err
+			}
+			bits.Or(xroar.NewFromShared(buf))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	atomic.AddInt64(&idx.metrics.QueriedTuples, int64(bits.Count()))
+
+	return bits, nil
+}
+
+// queryTerms returns the union over all alternatives of intersected term
+// posting lists.
+func (idx *Index) queryTerms(ctx context.Context, alts [][]uint64) (*xroar.Bitmap, error) {
+	bits := xroar.New()
+
+	err := idx.db.View(func(tx store.Tx) error {
+		b := idx.dataBucket(tx)
+		if b == nil {
+			return store.ErrBucketNotFound
+		}
+
+		for _, terms := range alts {
+			var and *xroar.Bitmap
+			for _, term := range terms {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				buf, err := b.Get(idx.encodeBitmapKey(term))
+				if err != nil && !errors.Is(err, store.ErrKeyNotFound) {
+					return err
+				}
+
+				// stop early when no row contains all terms
+				if len(buf) == 0 {
+					and = nil
+					break
+				}
+				if and == nil {
+					and = xroar.NewFromBytes(buf)
+				} else {
+					and = xroar.And(and, xroar.NewFromShared(buf))
+				}
+				if and.None() {
+					break
+				}
+			}
+			if and != nil {
+				bits.Or(and)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	atomic.AddInt64(&idx.metrics.QueriedTuples, int64(bits.Count()))
+
+	return bits, nil
+}
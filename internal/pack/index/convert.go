@@ -14,6 +14,7 @@ import (
 	"blockwatch.cc/knoxdb/internal/pack"
 	"blockwatch.cc/knoxdb/internal/types"
 	"blockwatch.cc/knoxdb/pkg/assert"
+	"blockwatch.cc/knoxdb/pkg/num"
 	"blockwatch.cc/knoxdb/pkg/schema"
 	"blockwatch.cc/knoxdb/pkg/util"
 )
@@ -28,17 +29,43 @@ func convertSchema(is *schema.IndexSchema) (*schema.Schema, Converter, error) {
 	case types.IndexTypeHash:
 		hx, _ := is.Base.IndexId(is.Fields[0].Id)
 		c := &SimpleHashConverter{
-			sout: s,
-			hash: hx,
-			link: append([]int{is.Base.RowIdIndex()}, is.ExtraIndices()...),
+			sout:  s,
+			hash:  hx,
+			link:  append([]int{is.Base.RowIdIndex()}, is.ExtraIndices()...),
+			field: is.Fields[0],
+			expr:  is.Expr,
 		}
 		return s, c, nil
 	case types.IndexTypeInt, types.IndexTypePk:
 		ix, _ := is.Base.IndexId(is.Fields[0].Id)
 		c := &RelinkConverter{
-			sout: s,
-			use:  ix,
-			link: append([]int{is.Base.RowIdIndex()}, is.ExtraIndices()...),
+			sout:  s,
+			use:   ix,
+			link:  append([]int{is.Base.RowIdIndex()}, is.ExtraIndices()...),
+			field: is.Fields[0],
+			expr:  is.Expr,
+		}
+		return s, c, nil
+
+	case types.IndexTypeBitmap:
+		ix, _ := is.Base.IndexId(is.Fields[0].Id)
+		c := &BitmapConverter{
+			RelinkConverter{
+				sout:  s,
+				use:   ix,
+				link:  []int{is.Base.RowIdIndex()},
+				field: is.Fields[0],
+			},
+		}
+		return s, c, nil
+
+	case types.IndexTypeToken:
+		ix, _ := is.Base.IndexId(is.Fields[0].Id)
+		c := &TokenConverter{
+			sout:  s,
+			use:   ix,
+			rid:   is.Base.RowIdIndex(),
+			token: is.Tokenizer,
 		}
 		return s, c, nil
 
@@ -64,9 +91,11 @@ type Converter interface {
 }
 
 type RelinkConverter struct {
-	sout *schema.Schema // output schema
-	use  int            // position oif block to use or convert
-	link []int          // ordered list of extra blocks to link
+	sout  *schema.Schema   // output schema
+	use   int              // position oif block to use or convert
+	link  []int            // ordered list of extra blocks to link
+	field *schema.Field    // indexed source field
+	expr  schema.IndexExpr // optional key expression
 }
 
 func (*RelinkConverter) QueryKeys(_ *filter.Node) []uint64 {
@@ -79,7 +108,7 @@ func (c *RelinkConverter) QueryNode(node *filter.Node) *filter.Node {
 	// Note: index storage is u64
 	f0 := c.sout.Fields[0]
 	flt := node.Filter
-	if f0.Type == types.FieldTypeUint64 {
+	if f0.Type == types.FieldTypeUint64 && !flt.Expr.IsValid() {
 		return &filter.Node{
 			Filter: &filter.Filter{
 				Name:    "int",
@@ -91,34 +120,61 @@ func (c *RelinkConverter) QueryNode(node *filter.Node) *filter.Node {
 			},
 		}
 	} else {
-		val, err := schema.NewCaster(types.FieldTypeUint64, 0, nil).CastValue(flt.Value)
+		// expression filter values are already computed keys, so we match
+		// index keys directly without re-evaluating the expression
+		caster := schema.NewCaster(types.FieldTypeUint64, 0, nil)
+		var (
+			val any
+			err error
+		)
+		if rg, ok := flt.Value.(filter.RangeValue); ok {
+			var from, to any
+			from, err = caster.CastValue(rg[0])
+			if err == nil {
+				to, err = caster.CastValue(rg[1])
+			}
+			val = filter.RangeValue{from, to}
+		} else {
+			val, err = caster.CastValue(flt.Value)
+		}
 		if err != nil {
 			panic(fmt.Errorf("cast index query value %T to u64: %v", flt.Value, err))
 		}
-		matcher := filter.NewFactory(f0.Type).New(flt.Mode)
+		matcher := filter.NewFactory(types.FieldTypeUint64).New(flt.Mode)
 		matcher.WithValue(val)
-		return filter.NewNode().AddLeaf(&filter.Filter{
-			Name:    "int",
-			Type:    flt.Type,
-			Mode:    flt.Mode,
-			Index:   0,
-			Value:   val,
-			Matcher: matcher,
-		})
+		return &filter.Node{
+			Filter: &filter.Filter{
+				Name:    "int",
+				Type:    block.BlockUint64,
+				Mode:    flt.Mode,
+				Index:   0,
+				Value:   val,
+				Matcher: matcher,
+			},
+		}
 	}
 }
 
 func (c *RelinkConverter) ConvertPack(pkg *pack.Package, mode pack.WriteMode) *pack.Package {
 	ipkg := pack.New().WithSchema(c.sout).WithMaxRows(pkg.Cap())
 
+	// compute key expression
+	b := pkg.Block(c.use)
+	if c.expr.IsValid() {
+		b = filter.EvalExpr(c.expr, c.field, b)
+		defer b.Deref()
+	}
+
 	// convert first block to u64
-	if b := pkg.Block(c.use); b.Type() != block.BlockUint64 {
+	if b.Type() != block.BlockUint64 {
 		// convert
 		u64 := block.New(block.BlockUint64, pkg.Len())
 		acc := u64.Uint64()
 		switch b.Type() {
 		case block.BlockInt64:
-			copy(u64.Int64().Slice(), b.Int64().Slice())
+			for _, v := range b.Int64().Slice() {
+				acc.Append(uint64(v))
+			}
 		case block.BlockInt32:
 			for _, v := range b.Int32().Slice() {
 				acc.Append(uint64(v))
@@ -167,14 +223,22 @@ func (c *RelinkConverter) ConvertPack(pkg *pack.Package, mode pack.WriteMode) *p
 // SimpleHashConverter produces a new index pack by hashing a single
 // source column and optionally appending extra source columns as is.
 type SimpleHashConverter struct {
-	sout *schema.Schema // output schema
-	link []int          // ordered list of src blocks to link
-	hash int            // single source block used for hashing
+	sout  *schema.Schema   // output schema
+	link  []int            // ordered list of src blocks to link
+	hash  int              // single source block used for hashing
+	field *schema.Field    // hashed source field
+	expr  schema.IndexExpr // optional key expression
 }
 
 func (c *SimpleHashConverter) ConvertPack(pkg *pack.Package, mode pack.WriteMode) *pack.Package {
 	ipkg := pack.New().WithSchema(c.sout).WithMaxRows(pkg.Cap())
-	ipkg.WithBlock(0, pkg.Block(c.hash).Hash())
+	if c.expr.IsValid() {
+		b := filter.EvalExpr(c.expr, c.field, pkg.Block(c.hash))
+		ipkg.WithBlock(0, b.Hash())
+		b.Deref()
+	} else {
+		ipkg.WithBlock(0, pkg.Block(c.hash).Hash())
+	}
 	for i, v := range c.link {
 		b := pkg.Block(v)
 		b.Ref()
@@ -185,17 +249,14 @@ func (c *SimpleHashConverter) ConvertPack(pkg *pack.Package, mode pack.WriteMode
 }
 
 func (c *SimpleHashConverter) QueryKeys(node *filter.Node) []uint64 {
-	// produce output hash (uint64) from query filter value encoded to LE wire format
-	// use schema field encoding helper to translate Go types from query
-	f0 := c.sout.Fields[0]
-	buf := bytes.NewBuffer(nil)
+	// produce output hash (uint64) from query filter values in the same
+	// way block hashes are computed from source column vectors
 	flt := node.Filter
 
 	switch flt.Mode {
 	case types.FilterModeEqual:
 		// single
-		_ = f0.Encode(buf, flt.Value, LE)
-		return []uint64{hash.Hash(buf.Bytes())}
+		return []uint64{hashValue(flt.Value)}
 
 	case types.FilterModeIn, types.FilterModeNotIn:
 		// slice
@@ -205,9 +266,7 @@ func (c *SimpleHashConverter) QueryKeys(node *filter.Node) []uint64 {
 		}
 		res := make([]uint64, rval.Len())
 		for i := range res {
-			buf.Reset()
-			_ = f0.Encode(buf, rval.Index(i).Interface(), LE)
-			res[i] = hash.Hash(buf.Bytes())
+			res[i] = hashValue(rval.Index(i).Interface())
 		}
 		return res
 
@@ -218,6 +277,48 @@ func (c *SimpleHashConverter) QueryKeys(node *filter.Node) []uint64 {
 	}
 }
 
+// hashValue hashes a query value which must have the Go type of the
+// source block (see block.Hash).
+func hashValue(val any) uint64 {
+	switch v := val.(type) {
+	case []byte:
+		return hash.Hash(v)
+	case string:
+		return hash.Hash([]byte(v))
+	case bool:
+		if v {
+			return hash.One
+		}
+		return hash.Zero
+	case int64:
+		return hash.HashT(v)
+	case int32:
+		return hash.HashT(v)
+	case int16:
+		return hash.HashT(v)
+	case int8:
+		return hash.HashT(v)
+	case uint64:
+		return hash.HashT(v)
+	case uint32:
+		return hash.HashT(v)
+	case uint16:
+		return hash.HashT(v)
+	case uint8:
+		return hash.HashT(v)
+	case float64:
+		return hash.HashT(v)
+	case float32:
+		return hash.HashT(v)
+	case num.Int128:
+		return hash.Int128(v)
+	case num.Int256:
+		return hash.Int256(v)
+	default:
+		return 0
+	}
+}
+
 func (*SimpleHashConverter) QueryNode(_ *filter.Node) *filter.Node {
 	// unused (range index scans only)
 	return nil
@@ -288,7 +389,7 @@ func (c *CompositeHashConverter) ConvertPack(pkg *pack.Package, mode pack.WriteM
 				hasher.Write([]byte{b.Uint8().Get(i)})
 			case block.BlockBool:
 				hasher.Write([]byte{util.Bool2byte(b.Bool().Get(i))})
-			case block.BlockBytes:
+			case block.BlockBytes, block.BlockBigint:
 				hasher.Write(b.Bytes().Get(i))
 			case block.BlockInt128:
 				hasher.Write(b.Int128().Get(i).Bytes())
@@ -335,3 +436,149 @@ func (*CompositeHashConverter) QueryNode(_ *filter.Node) *filter.Node {
 	// unused (range index scans only)
 	return nil
 }
+
+// TokenConverter produces a new index pack by splitting a single string
+// source column into terms and emitting one (hash, rid) journal record per
+// unique term and row. Records are merged into one row id bitmap per term
+// on storage. Output packs are usually longer than source packs and
+// contain selected rows only.
+type TokenConverter struct {
+	sout  *schema.Schema   // output schema
+	use   int              // source block to tokenize
+	rid   int              // source row id block
+	token schema.Tokenizer // term tokenizer
+}
+
+func (c *TokenConverter) ConvertPack(pkg *pack.Package, mode pack.WriteMode) *pack.Package {
+	var (
+		src   = pkg.Block(c.use).Bytes()
+		rids  = pkg.Block(c.rid).Uint64()
+		sel   = pkg.Selected()
+		keys  = make([]uint64, 0, pkg.Len())
+		links = make([]uint64, 0, pkg.Len())
+	)
+	for i, l := 0, pkg.Len(); i < l; i++ {
+		// skip unselected rows
+		if mode == pack.WriteModeIncludeSelected {
+			if len(sel) == 0 || i < int(sel[0]) {
+				continue
+			}
+			sel = sel[1:]
+		} else if mode == pack.WriteModeExcludeSelected {
+			if len(sel) > 0 && i == int(sel[0]) {
+				sel = sel[1:]
+				continue
+			}
+		}
+		rid := rids.Get(i)
+		for _, term := range c.token.Tokens(src.Get(i)) {
+			keys = append(keys, hash.Hash(term))
+			links = append(links, rid)
+		}
+	}
+
+	// construct a new package from term records
+	ipkg := pack.New().WithSchema(c.sout).WithMaxRows(len(keys))
+	kb, rb := block.New(block.BlockUint64, len(keys)), block.New(block.BlockUint64, len(keys))
+	ka, ra := kb.Uint64(), rb.Uint64()
+	for i := range keys {
+		ka.Append(keys[i])
+		ra.Append(links[i])
+	}
+	ipkg.WithBlock(0, kb)
+	ipkg.WithBlock(1, rb)
+	ipkg.UpdateLen()
+	return ipkg
+}
+
+// QueryTerms returns term hashes for contains and match filters as a list
+// of alternatives which each contain a list of required terms. Contains
+// filters use the n-gram tokenizer and produce a single alternative. The
+// result is empty when the index cannot answer the filter.
+func (c *TokenConverter) QueryTerms(node *filter.Node) [][]uint64 {
+	flt := node.Filter
+	val, ok := flt.Value.([]byte)
+	if !ok {
+		return nil
+	}
+	var alts [][][]byte
+	switch {
+	case flt.Mode == types.FilterModeContains && c.token.Type == schema.TokenizerNgram:
+		if terms := c.token.Tokens(val); len(terms) > 0 {
+			alts = [][][]byte{terms}
+		}
+	case flt.Mode == types.FilterModeMatch && c.token.Type == schema.TokenizerWords:
+		alts = schema.ParseTermQuery(val)
+	}
+	res := make([][]uint64, len(alts))
+	for i, terms := range alts {
+		res[i] = make([]uint64, len(terms))
+		for k, term := range terms {
+			res[i][k] = hash.Hash(term)
+		}
+	}
+	return res
+}
+
+func (*TokenConverter) QueryKeys(_ *filter.Node) []uint64 {
+	// unused (see QueryTerms)
+	return nil
+}
+
+func (*TokenConverter) QueryNode(_ *filter.Node) *filter.Node {
+	// unused (see QueryTerms)
+	return nil
+}
+
+// BitmapConverter produces (value, rid) journal records like an integer
+// index. Records are merged into one row id bitmap per distinct value on
+// storage, so query keys are the plain integer values.
+type BitmapConverter struct {
+	RelinkConverter
+}
+
+func (c *BitmapConverter) QueryKeys(node *filter.Node) []uint64 {
+	// produce bitmap keys from query filter values the same way
+	// ConvertPack casts source column values to u64
+	flt := node.Filter
+
+	switch flt.Mode {
+	case types.FilterModeEqual:
+		// single
+		key, ok := bitmapKey(reflect.ValueOf(flt.Value))
+		if !ok {
+			return nil
+		}
+		return []uint64{key}
+
+	case types.FilterModeIn, types.FilterModeNotIn:
+		// slice
+		rval := reflect.ValueOf(flt.Value)
+		if rval.Kind() != reflect.Slice {
+			return nil
+		}
+		res := make([]uint64, 0, rval.Len())
+		for i := range rval.Len() {
+			if key, ok := bitmapKey(rval.Index(i)); ok {
+				res = append(res, key)
+			}
+		}
+		return res
+
+	default:
+		// unreachable
+		assert.Unreachable("invalid filter mode for pack bitmap query", "mode", flt.Mode)
+		return nil
+	}
+}
+
+func bitmapKey(v reflect.Value) (uint64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8:
+		return uint64(v.Int()), true
+	case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		return v.Uint(), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package index
+
+import (
+	"testing"
+
+	"blockwatch.cc/knoxdb/internal/hash"
+	"blockwatch.cc/knoxdb/internal/operator/filter"
+	"blockwatch.cc/knoxdb/internal/pack"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"github.com/stretchr/testify/require"
+)
+
+type convertTestStruct struct {
+	Id    uint64 `knox:"id,pk"`
+	Value int64  `knox:"value,index=int"`
+}
+
+func TestRelinkConverterInt64(t *testing.T) {
+	s, err := schema.SchemaOf(&convertTestStruct{})
+	require.NoError(t, err)
+	s = s.WithMeta()
+	is := s.Indexes[len(s.Indexes)-1]
+	require.Equal(t, "value", is.Fields[0].Name)
+	_, c, err := convertSchema(is)
+	require.NoError(t, err)
+
+	enc := schema.NewGenericEncoder[convertTestStruct]()
+	pkg := pack.New().WithSchema(s).WithMaxRows(8).Alloc()
+	defer pkg.Release()
+	for i := range 5 {
+		buf, err := enc.Encode(convertTestStruct{Id: uint64(i + 1), Value: int64(i) - 2}, nil)
+		require.NoError(t, err)
+		pkg.AppendWire(buf, &schema.Meta{Rid: uint64(i + 1)})
+	}
+
+	ipkg := c.ConvertPack(pkg, pack.WriteModeAll)
+	defer ipkg.Release()
+	require.Equal(t, 5, ipkg.Len())
+	keys := ipkg.Block(0).Uint64().Slice()
+	require.Equal(t, []uint64{1<<64 - 2, 1<<64 - 1, 0, 1, 2}, keys)
+	require.Equal(t, []uint64{1, 2, 3, 4, 5}, ipkg.Block(1).Uint64().Slice())
+}
+
+type tokenTestStruct struct {
+	Id    uint64 `knox:"id,pk"`
+	Text  string `knox:"text,index=token"`
+	Title string `knox:"title,index=token,tokenizer=ngram(3)"`
+}
+
+func tokenTestIndex(t *testing.T, name string) (*schema.Schema, *TokenConverter) {
+	t.Helper()
+	s, err := schema.SchemaOf(&tokenTestStruct{})
+	require.NoError(t, err)
+	s = s.WithMeta()
+	for _, is := range s.Indexes {
+		if is.Fields[0].Name != name {
+			continue
+		}
+		_, c, err := convertSchema(is)
+		require.NoError(t, err)
+		return s, c.(*TokenConverter)
+	}
+	require.Fail(t, "missing index", name)
+	return nil, nil
+}
+
+func TestTokenConverter(t *testing.T) {
+	s, c := tokenTestIndex(t, "text")
+	rows := []tokenTestStruct{
+		{Text: "Hello World"},
+		{Text: "hello hello"},
+		{Text: ""},
+		{Text: "world, again"},
+	}
+	enc := schema.NewGenericEncoder[tokenTestStruct]()
+	pkg := pack.New().WithSchema(s).WithMaxRows(8).Alloc()
+	defer pkg.Release()
+	for i, v := range rows {
+		v.Id = uint64(i + 1)
+		buf, err := enc.Encode(v, nil)
+		require.NoError(t, err)
+		pkg.AppendWire(buf, &schema.Meta{Rid: uint64(i + 10)})
+	}
+	h := func(s string) uint64 { return hash.Hash([]byte(s)) }
+
+	// one record per unique term and row
+	ipkg := c.ConvertPack(pkg, pack.WriteModeAll)
+	require.Equal(t, 5, ipkg.Len())
+	require.Equal(t, []uint64{h("hello"), h("world"), h("hello"), h("world"), h("again")}, ipkg.Block(0).Uint64().Slice())
+	require.Equal(t, []uint64{10, 10, 11, 13, 13}, ipkg.Block(1).Uint64().Slice())
+	ipkg.Release()
+
+	// selected rows only
+	pkg.WithSelection([]uint32{1, 3})
+	ipkg = c.ConvertPack(pkg, pack.WriteModeIncludeSelected)
+	require.Equal(t, []uint64{h("hello"), h("world"), h("again")}, ipkg.Block(0).Uint64().Slice())
+	require.Equal(t, []uint64{11, 13, 13}, ipkg.Block(1).Uint64().Slice())
+	ipkg.Release()
+
+	// unselected rows only
+	ipkg = c.ConvertPack(pkg, pack.WriteModeExcludeSelected)
+	require.Equal(t, []uint64{h("hello"), h("world")}, ipkg.Block(0).Uint64().Slice())
+	require.Equal(t, []uint64{10, 10}, ipkg.Block(1).Uint64().Slice())
+	ipkg.Release()
+}
+
+func TestTokenQueryTerms(t *testing.T) {
+	h := func(s string) uint64 { return hash.Hash([]byte(s)) }
+	node := func(mode types.FilterMode, val any) *filter.Node {
+		return &filter.Node{Filter: &filter.Filter{Mode: mode, Value: val}}
+	}
+
+	// word indexes answer match filters only
+	_, words := tokenTestIndex(t, "text")
+	require.Equal(t, [][]uint64{{h("foo"), h("bar")}, {h("baz")}},
+		words.QueryTerms(node(types.FilterModeMatch, []byte("Foo bar | baz"))))
+	require.Empty(t, words.QueryTerms(node(types.FilterModeMatch, []byte(" | "))))
+	require.Empty(t, words.QueryTerms(node(types.FilterModeContains, []byte("foo"))))
+	require.Empty(t, words.QueryTerms(node(types.FilterModeEqual, []byte("foo"))))
+	require.Empty(t, words.QueryTerms(node(types.FilterModeMatch, "foo")))
+
+	// n-gram indexes answer contains filters with at least n runes
+	_, grams := tokenTestIndex(t, "title")
+	require.Equal(t, [][]uint64{{h("hel"), h("ell"), h("llo")}},
+		grams.QueryTerms(node(types.FilterModeContains, []byte("HELLO"))))
+	require.Empty(t, grams.QueryTerms(node(types.FilterModeContains, []byte("he"))))
+	require.Empty(t, grams.QueryTerms(node(types.FilterModeMatch, []byte("hello"))))
+}
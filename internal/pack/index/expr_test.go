@@ -0,0 +1,413 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package index
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"blockwatch.cc/knoxdb/internal/engine"
+	"blockwatch.cc/knoxdb/internal/hash"
+	"blockwatch.cc/knoxdb/internal/operator/filter"
+	"blockwatch.cc/knoxdb/internal/pack"
+	"blockwatch.cc/knoxdb/internal/query"
+	etests "blockwatch.cc/knoxdb/internal/tests/engine"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/num"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"github.com/stretchr/testify/require"
+)
+
+type exprTestStruct struct {
+	Id    uint64    `knox:"id,pk"`
+	Name  string    `knox:"name"`
+	Time  time.Time `knox:"time,scale=s"`
+	Value uint64    `knox:"value"`
+}
+
+var exprTestDay = time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+var exprTestRows = []exprTestStruct{
+	{Name: "Alice", Time: exprTestDay.Add(10 * time.Hour), Value: 10},
+	{Name: "alice", Time: exprTestDay.Add(23 * time.Hour), Value: 11},
+	{Name: "Bob", Time: exprTestDay.Add(25 * time.Hour), Value: 12},
+	{Name: "ALICE x", Time: exprTestDay.Add(60 * time.Hour), Value: 13},
+	{Name: "bob", Time: exprTestDay.Add(72 * time.Hour), Value: 14},
+}
+
+// exprTestIndex returns an index schema on field name of s using key
+// expression e.
+func exprTestIndex(t *testing.T, s *schema.Schema, name string, typ types.IndexType, e schema.IndexExpr) *schema.IndexSchema {
+	t.Helper()
+	ss, err := s.Select(name)
+	require.NoError(t, err)
+	is := &schema.IndexSchema{
+		Name:   name + "_index",
+		Type:   typ,
+		Base:   s,
+		Fields: ss.Fields,
+	}
+	if e.IsValid() {
+		is = is.WithExpr(e)
+	}
+	require.NoError(t, is.Validate())
+	return is
+}
+
+// TestExprConverter ensures expression indexes store computed keys.
+func TestExprConverter(t *testing.T) {
+	s, err := schema.SchemaOf(&exprTestStruct{})
+	require.NoError(t, err)
+	s = s.WithMeta()
+
+	enc := schema.NewGenericEncoder[exprTestStruct]()
+	pkg := pack.New().WithSchema(s).WithMaxRows(8).Alloc()
+	defer pkg.Release()
+	for i, v := range exprTestRows {
+		v.Id = uint64(i + 1)
+		buf, err := enc.Encode(v, nil)
+		require.NoError(t, err)
+		pkg.AppendWire(buf, &schema.Meta{Rid: uint64(i + 1)})
+	}
+
+	// hash(lower(name))
+	_, c, err := convertSchema(exprTestIndex(t, s, "name", types.IndexTypeHash, schema.Lower()))
+	require.NoError(t, err)
+	ipkg := c.ConvertPack(pkg, pack.WriteModeAll)
+	for i, v := range exprTestRows {
+		require.Equal(t, hash.Hash(bytes.ToLower([]byte(v.Name))), ipkg.Block(0).Uint64().Get(i), v.Name)
+	}
+	require.Equal(t, []uint64{1, 2, 3, 4, 5}, ipkg.Block(1).Uint64().Slice())
+	ipkg.Release()
+
+	// time_bucket(time, 1d) in unix seconds
+	_, c, err = convertSchema(exprTestIndex(t, s, "time", types.IndexTypeInt, schema.TimeBucket(24*time.Hour)))
+	require.NoError(t, err)
+	ipkg = c.ConvertPack(pkg, pack.WriteModeAll)
+	for i, v := range exprTestRows {
+		require.Equal(t, uint64(v.Time.Truncate(24*time.Hour).Unix()), ipkg.Block(0).Uint64().Get(i), v.Time)
+	}
+	ipkg.Release()
+
+	// source blocks stay unchanged
+	require.Equal(t, "Alice", string(pkg.Block(1).Bytes().Get(0)))
+	require.Equal(t, exprTestRows[0].Time.Unix(), pkg.Block(2).Int64().Get(0))
+}
+
+type hashTestStruct struct {
+	Id   uint64        `knox:"id,pk"`
+	I64  int64         `knox:"i64,index=hash"`
+	I32  int32         `knox:"i32,index=hash"`
+	I16  int16         `knox:"i16,index=hash"`
+	I8   int8          `knox:"i8,index=hash"`
+	U64  uint64        `knox:"u64,index=hash"`
+	U32  uint32        `knox:"u32,index=hash"`
+	U16  uint16        `knox:"u16,index=hash"`
+	U8   uint8         `knox:"u8,index=hash"`
+	F64  float64       `knox:"f64,index=hash"`
+	F32  float32       `knox:"f32,index=hash"`
+	Bool bool          `knox:"bool,index=hash"`
+	Str  string        `knox:"str,index=hash"`
+	Byte []byte        `knox:"byte,index=hash"`
+	I128 num.Int128    `knox:"i128,index=hash"`
+	I256 num.Int256    `knox:"i256,index=hash"`
+	Time time.Time     `knox:"time,scale=s,index=hash"`
+	Dec  num.Decimal64 `knox:"dec,scale=2,index=hash"`
+}
+
+// TestHashQueryKeys ensures query values hash to the same keys which
+// hash indexes compute from source blocks.
+func TestHashQueryKeys(t *testing.T) {
+	s, err := schema.SchemaOf(&hashTestStruct{})
+	require.NoError(t, err)
+	s = s.WithMeta()
+
+	rows := []hashTestStruct{
+		{
+			I64: 64, I32: 32, I16: 16, I8: 8,
+			U64: 64, U32: 32, U16: 16, U8: 8,
+			F64: -6.4, F32: 3.2, Bool: true,
+			Str: "hello", Byte: []byte{1, 2, 3},
+			I128: num.Int128FromInt64(-128), I256: num.Int256FromInt64(256),
+			Time: exprTestDay, Dec: num.NewDecimal64(1234, 2),
+		},
+		{
+			I64: 1 << 40, I32: 1 << 20, I16: 1 << 10, I8: 1,
+			U64: 1 << 63, U32: 1 << 31, U16: 1 << 15, U8: 1 << 7,
+			F64: 1e100, F32: -1e10, Bool: false,
+			Str: "", Byte: []byte("world"),
+			I128: num.Int128From2Int64(1, 2), I256: num.Int256From4Int64(1, 2, 3, 4),
+			Time: exprTestDay.Add(time.Hour), Dec: num.NewDecimal64(-5, 2),
+		},
+	}
+	enc := schema.NewGenericEncoder[hashTestStruct]()
+	pkg := pack.New().WithSchema(s).WithMaxRows(len(rows)).Alloc()
+	defer pkg.Release()
+	for i, v := range rows {
+		buf, err := enc.Encode(v, nil)
+		require.NoError(t, err)
+		pkg.AppendWire(buf, &schema.Meta{Rid: uint64(i + 1)})
+	}
+
+	// query values use Go types of struct fields
+	values := func(name string) []any {
+		var res []any
+		for _, v := range rows {
+			switch name {
+			case "i64":
+				res = append(res, v.I64)
+			case "i32":
+				res = append(res, v.I32)
+			case "i16":
+				res = append(res, v.I16)
+			case "i8":
+				res = append(res, v.I8)
+			case "u64":
+				res = append(res, v.U64)
+			case "u32":
+				res = append(res, v.U32)
+			case "u16":
+				res = append(res, v.U16)
+			case "u8":
+				res = append(res, v.U8)
+			case "f64":
+				res = append(res, v.F64)
+			case "f32":
+				res = append(res, v.F32)
+			case "bool":
+				res = append(res, v.Bool)
+			case "str":
+				res = append(res, v.Str)
+			case "byte":
+				res = append(res, v.Byte)
+			case "i128":
+				res = append(res, v.I128)
+			case "i256":
+				res = append(res, v.I256)
+			case "time":
+				res = append(res, v.Time)
+			case "dec":
+				res = append(res, v.Dec)
+			}
+		}
+		return res
+	}
+
+	var n int
+	for _, is := range s.Indexes {
+		if is.Type != types.IndexTypeHash {
+			continue
+		}
+		name := is.Fields[0].Name
+		t.Run(name, func(t *testing.T) {
+			_, c, err := convertSchema(is)
+			require.NoError(t, err)
+			ipkg := c.ConvertPack(pkg, pack.WriteModeAll)
+			defer ipkg.Release()
+			keys := ipkg.Block(0).Uint64().Slice()
+			require.NotEqual(t, keys[0], keys[1])
+
+			vals := values(name)
+			require.Len(t, vals, len(rows))
+			for i, v := range vals {
+				node, err := query.Equal(name, v).Compile(s)
+				require.NoError(t, err)
+				require.Equal(t, []uint64{keys[i]}, c.QueryKeys(node.Children[0]), "EQ %v", v)
+			}
+			set := reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(vals[0])), 0, len(vals))
+			for _, v := range vals {
+				set = reflect.Append(set, reflect.ValueOf(v))
+			}
+			node, err := query.In(name, set.Interface()).Compile(s)
+			require.NoError(t, err)
+			require.ElementsMatch(t, keys, c.QueryKeys(node.Children[0]), "IN")
+		})
+		n++
+	}
+	require.Equal(t, 17, n, "hash indexes")
+}
+
+// TestExprIndexQuery ensures the query planner uses expression indexes
+// for conditions with the same expression (and case-insensitive literal
+// regexps on lower() indexes) but not for plain conditions.
+func TestExprIndexQuery(t *testing.T) {
+	for _, driver := range []string{"mem", "bolt"} {
+		t.Run(driver, func(t *testing.T) {
+			testExprIndexQuery(t, driver)
+		})
+	}
+}
+
+func testExprIndexQuery(t *testing.T, driver string) {
+	e := etests.NewTestEngine(t, etests.NewTestDatabaseOptions(t, driver))
+	defer e.Close(context.Background())
+	ctx := engine.WithEngine(context.Background(), e)
+	tab, ts := exprTestTable(t, ctx, e, driver)
+
+	// build expression and plain indexes from table rows
+	iopts := etests.NewTestIndexOptions(t, driver, "pack")
+	names, days, values := NewIndex().(*Index), NewIndex().(*Index), NewIndex().(*Index)
+	for _, v := range []struct {
+		idx *Index
+		is  *schema.IndexSchema
+	}{
+		{names, exprTestIndex(t, ts, "name", types.IndexTypeHash, schema.Lower())},
+		{days, exprTestIndex(t, ts, "time", types.IndexTypeInt, schema.TimeBucket(24*time.Hour))},
+		{values, exprTestIndex(t, ts, "value", types.IndexTypeInt, schema.IndexExpr{})},
+	} {
+		require.NoError(t, v.idx.Create(ctx, tab, v.is, iopts.IndexOptions()...))
+		defer v.idx.Close(ctx)
+		require.NoError(t, v.idx.Rebuild(ctx))
+	}
+
+	day := func(n int) time.Time { return exprTestDay.Add(time.Duration(n) * 24 * time.Hour) }
+	bucket := func(d time.Duration) schema.IndexExpr { return schema.TimeBucket(d) }
+	cases := []struct {
+		name string
+		cond query.Condition
+		rids []uint64 // nil if no index can be used
+	}{
+		// lower(name)
+		{"lower_eq", query.Equal("name", "alice").WithExpr(schema.Lower()), []uint64{1, 2}},
+		{"lower_in", query.In("name", []string{"alice", "bob"}).WithExpr(schema.Lower()), []uint64{1, 2, 3, 5}},
+		{"lower_none", query.Equal("name", "carol").WithExpr(schema.Lower()), []uint64{}},
+		{"regexp_fold", query.Regexp("name", "(?i)^alice$"), []uint64{1, 2}},
+		{"regexp_case", query.Regexp("name", "^alice$"), nil},
+		{"regexp_prefix", query.Regexp("name", "(?i)^alice"), nil},
+		{"plain_eq", query.Equal("name", "alice"), nil},
+		{"prefix_expr", query.Equal("name", "al").WithExpr(schema.Prefix(2)), nil},
+
+		// time_bucket(time, 1d)
+		{"bucket_eq", query.Equal("time", day(1)).WithExpr(bucket(24 * time.Hour)), []uint64{3}},
+		{"bucket_ge", query.Ge("time", day(1)).WithExpr(bucket(24 * time.Hour)), []uint64{3, 4, 5}},
+		{"bucket_lt", query.Lt("time", day(2)).WithExpr(bucket(24 * time.Hour)), []uint64{1, 2, 3}},
+		{"bucket_rg", query.Range("time", day(0), day(1)).WithExpr(bucket(24 * time.Hour)), []uint64{1, 2, 3}},
+		{"bucket_other", query.Equal("time", day(1)).WithExpr(bucket(time.Hour)), nil},
+		{"plain_ge", query.Ge("time", day(1)), nil},
+
+		// plain index must not match expressions
+		{"value_eq", query.Equal("value", 12), []uint64{3}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rids, ok := planIndexRids(t, ctx, tab, c.cond, names, days, values)
+			if c.rids == nil {
+				require.False(t, ok, "unexpected index use")
+				return
+			}
+			require.True(t, ok, "missing index use")
+			require.Equal(t, c.rids, rids)
+		})
+	}
+}
+
+// TestScanIteratorRange ensures range scans find matches in the first
+// index pack when the range starts before the smallest stored key.
+func TestScanIteratorRange(t *testing.T) {
+	for _, driver := range []string{"mem", "bolt"} {
+		t.Run(driver, func(t *testing.T) {
+			e := etests.NewTestEngine(t, etests.NewTestDatabaseOptions(t, driver))
+			defer e.Close(context.Background())
+			ctx := engine.WithEngine(context.Background(), e)
+			tab, ts := exprTestTable(t, ctx, e, driver)
+
+			idx := NewIndex().(*Index)
+			is := exprTestIndex(t, ts, "value", types.IndexTypeInt, schema.IndexExpr{})
+			require.NoError(t, idx.Create(ctx, tab, is, etests.NewTestIndexOptions(t, driver, "pack").IndexOptions()...))
+			defer idx.Close(ctx)
+			require.NoError(t, idx.Rebuild(ctx))
+
+			cases := []struct {
+				name string
+				cond query.Condition
+				rids []uint64
+			}{
+				{"ge_before", query.Ge("value", 5), []uint64{1, 2, 3, 4, 5}},
+				{"gt_before", query.Gt("value", 0), []uint64{1, 2, 3, 4, 5}},
+				{"rg_before", query.Range("value", 0, 11), []uint64{1, 2}},
+				{"le_first", query.Le("value", 10), []uint64{1}},
+				{"lt_before", query.Lt("value", 10), []uint64{}},
+				{"eq_first", query.Equal("value", 10), []uint64{1}},
+				{"eq_before", query.Equal("value", 9), []uint64{}},
+				{"ge_after", query.Ge("value", 15), []uint64{}},
+				{"rg_inner", query.Range("value", 11, 13), []uint64{2, 3, 4}},
+			}
+			for _, c := range cases {
+				node, err := c.cond.Compile(ts)
+				require.NoError(t, err)
+				require.True(t, idx.CanMatch(node.Children[0]), c.name)
+				bits, collide, err := idx.Query(ctx, node.Children[0])
+				require.NoError(t, err)
+				require.False(t, collide, c.name)
+				require.Equal(t, c.rids, bits.ToArray([]uint64{}), c.name)
+			}
+		})
+	}
+}
+
+// exprTestTable creates a table and stores exprTestRows.
+func exprTestTable(t *testing.T, ctx context.Context, e *engine.Engine, driver string) (engine.TableEngine, *schema.Schema) {
+	t.Helper()
+	s, err := schema.SchemaOf(&exprTestStruct{})
+	require.NoError(t, err)
+	tab, err := e.CreateTable(ctx, s.WithMeta(), etests.NewTestTableOptions(t, driver, "pack").TableOptions()...)
+	require.NoError(t, err)
+	ts := tab.Schema()
+
+	buf, err := schema.NewEncoder(ts).EncodeSlice(exprTestRows, nil)
+	require.NoError(t, err)
+	tctx, _, commit, abort, err := e.WithTransaction(ctx)
+	require.NoError(t, err)
+	_, cnt, err := tab.InsertRows(tctx, buf)
+	require.NoError(t, err)
+	require.Equal(t, len(exprTestRows), cnt)
+	require.NoError(t, commit())
+	abort()
+	require.NoError(t, tab.Flush(ctx))
+	return tab, ts
+}
+
+// planIndexRids runs index queries of a query plan for cond and returns
+// table row ids matched by the resulting row id filter. Returns false when no index
+// was used.
+func planIndexRids(t *testing.T, ctx context.Context, tab engine.TableEngine, cond query.Condition, idxs ...*Index) ([]uint64, bool) {
+	t.Helper()
+	ts := tab.Schema()
+	flt, err := cond.Compile(ts)
+	require.NoError(t, err)
+	plan := query.NewQueryPlan()
+	plan.Table = tab
+	plan.Filters = flt
+	plan.RequestSchema = ts
+	plan.ResultSchema = ts
+	for _, idx := range idxs {
+		plan.Indexes = append(plan.Indexes, idx)
+	}
+	defer plan.Close()
+	require.NoError(t, plan.QueryIndexes(ctx))
+
+	// the optimizer may rewrite row id sets into ranges or equal
+	// conditions, so we match all table row ids instead
+	var (
+		rids []uint64
+		ok   bool
+	)
+	plan.Filters.ForEach(func(f *filter.Filter) error {
+		if f.Name != "$rid" {
+			return nil
+		}
+		ok = true
+		rids = []uint64{}
+		for rid := range uint64(len(exprTestRows)) {
+			if f.Matcher.MatchValue(rid + 1) {
+				rids = append(rids, rid+1)
+			}
+		}
+		return nil
+	})
+	return rids, ok
+}
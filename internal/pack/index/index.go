@@ -8,11 +8,16 @@ import (
 	"encoding/binary"
 	"fmt"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 
 	"blockwatch.cc/knoxdb/internal/engine"
+	"blockwatch.cc/knoxdb/internal/operator/filter"
 	"blockwatch.cc/knoxdb/internal/pack"
+	"blockwatch.cc/knoxdb/internal/query"
 	"blockwatch.cc/knoxdb/internal/types"
 	"blockwatch.cc/knoxdb/pkg/schema"
+	"blockwatch.cc/knoxdb/pkg/slicex"
 	"blockwatch.cc/knoxdb/pkg/store"
 	"github.com/echa/log"
 )
@@ -22,8 +27,9 @@ import (
 // - data placement algorithm is inefficient for hash indexes (use linear hashing)
 
 // This index supports the following condition types on lookup.
-// - hash: EQ, IN (single or composite EQ)
-// - int:  EQ, LT, LE GT, GE, RG (single condition)
+// - hash:  EQ, IN (single or composite EQ)
+// - int:   EQ, LT, LE GT, GE, RG (single condition)
+// - token: CONTAINS (ngram tokenizer), MATCH (words tokenizer)
 
 var _ engine.IndexEngine = (*Index)(nil)
 
@@ -61,8 +67,11 @@ type Index struct {
 	journal *pack.Package       // in-memory updates
 	tomb    *pack.Package       // in-memory deletes
 	convert Converter           // table to index schema converter
+	pred    *filter.Node        // compiled partial index predicate
 	metrics engine.IndexMetrics // usage statistics
 	log     log.Logger          // log instance
+	status  atomic.Uint32       // build status
+	mu      sync.Mutex          // protects journal from concurrent builds
 }
 
 func NewIndex() engine.IndexEngine {
@@ -88,6 +97,12 @@ func (idx *Index) Create(ctx context.Context, t engine.TableEngine, s *schema.In
 		return err
 	}
 
+	// compile partial index predicate once
+	pred, err := query.CompilePredicate(s, t.Schema())
+	if err != nil {
+		return err
+	}
+
 	// setup index
 	idx.engine = engine.GetEngine(ctx)
 	idx.sindex = s
@@ -106,6 +121,7 @@ func (idx *Index) Create(ctx context.Context, t engine.TableEngine, s *schema.In
 		WithSchema(sout).
 		Alloc()
 	idx.convert = conv
+	idx.pred = pred
 	idx.metrics = engine.NewIndexMetrics(s.Name)
 	idx.log = idx.opts.Log.Clone("index:" + s.Name)
 
@@ -174,6 +190,12 @@ func (idx *Index) Open(ctx context.Context, t engine.TableEngine, s *schema.Inde
 		return err
 	}
 
+	// compile partial index predicate once
+	pred, err := query.CompilePredicate(s, t.Schema())
+	if err != nil {
+		return err
+	}
+
 	// setup index
 	idx.engine = engine.GetEngine(ctx)
 	idx.sindex = s
@@ -192,6 +214,7 @@ func (idx *Index) Open(ctx context.Context, t engine.TableEngine, s *schema.Inde
 		WithSchema(sout).
 		Alloc()
 	idx.convert = conv
+	idx.pred = pred
 	idx.metrics = engine.NewIndexMetrics(s.Name)
 	idx.log = idx.opts.Log.Clone("index:" + s.Name)
 
@@ -275,6 +298,7 @@ func (idx *Index) Close(ctx context.Context) (err error) {
 	idx.opts = engine.Options{}
 	idx.metrics = engine.IndexMetrics{}
 	idx.convert = nil
+	idx.pred = nil
 	idx.state.Reset()
 	idx.journal.Release()
 	idx.tomb.Release()
@@ -283,6 +307,14 @@ func (idx *Index) Close(ctx context.Context) (err error) {
 	return
 }
 
+// Predicate returns the compiled partial index predicate or nil.
+func (idx *Index) Predicate() engine.QueryCondition {
+	if idx.pred == nil {
+		return nil
+	}
+	return idx.pred
+}
+
 func (idx *Index) IndexSchema() *schema.IndexSchema {
 	return idx.sindex
 }
@@ -303,6 +335,18 @@ func (idx *Index) IsPk() bool {
 	return idx.sindex.Type == types.IndexTypePk
 }
 
+func (idx *Index) Status() engine.IndexStatus {
+	return engine.IndexStatus(idx.status.Load())
+}
+
+func (idx *Index) SetStatus(s engine.IndexStatus) {
+	idx.status.Store(uint32(s))
+}
+
+func (idx *Index) IsReady() bool {
+	return idx.Status() == engine.IndexStatusReady
+}
+
 func (idx *Index) Sync(ctx context.Context) error {
 	return idx.db.Sync()
 }
@@ -324,6 +368,9 @@ func (idx *Index) Drop(ctx context.Context) error {
 }
 
 func (idx *Index) Truncate(ctx context.Context) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
 	// start direct backend write tx (assumes index and table are
 	// not stored in the same backend db file)
 	err := idx.db.Update(func(tx store.Tx) error {
@@ -362,10 +409,57 @@ func (idx *Index) Truncate(ctx context.Context) error {
 }
 
 func (idx *Index) Rebuild(ctx context.Context) error {
-	// walk all table packs
-	rd := idx.table.NewReader().WithFields(idx.sindex.Ids())
+	return idx.Build(ctx, nil)
+}
+
+// Build indexes all live table rows. Table merges keep adding new rows
+// concurrently, so duplicates are possible and dropped during index merge.
+// The build periodically flushes its journal and stores the next row id
+// as checkpoint to resume after cancel or restart.
+func (idx *Index) Build(ctx context.Context, b *engine.IndexBuild) error {
+	from, err := idx.loadBuildCheckpoint()
+	if err != nil {
+		return err
+	}
+	from = max(from, 1)
+
+	// read live table rows starting at the checkpoint, partial indexes
+	// only contain rows matching their predicate
+	cond := query.And(
+		query.Ge("$rid", from),
+		query.Equal("$xmax", 0),
+	)
+	if pred, ok := idx.sindex.Predicate.(query.Condition); ok {
+		cond = query.And(cond, pred)
+	}
+	ts := idx.table.Schema()
+	flt, err := cond.Compile(ts)
+	if err != nil {
+		return err
+	}
+	rs, err := ts.SelectIds(slicex.Unique(flt.FieldIds())...)
+	if err != nil {
+		return err
+	}
+	plan := query.NewQueryPlan()
+	plan.Tag = idx.name
+	plan.Table = idx.table
+	plan.Filters = flt
+	plan.RequestSchema = rs
+	plan.ResultSchema = rs
+	plan.Snap = &types.Snapshot{Safe: true}
+	plan.Log = idx.log
+	defer plan.Close()
+
+	rd := idx.table.NewReader().WithQuery(plan).WithFields(idx.sindex.Ids())
 	defer rd.Close()
 
+	idx.log.Debugf("build from rid %d", from)
+	var (
+		total = idx.table.State().NextRid
+		next  = from
+		n     int
+	)
 	for {
 		// read next table pack
 		pkg, err := rd.Next(ctx)
@@ -378,23 +472,72 @@ func (idx *Index) Rebuild(ctx context.Context) error {
 			break
 		}
 
-		// add pack contents to index
-		if err := idx.AddPack(ctx, pkg, pack.WriteModeAll); err != nil {
+		// add selected pack contents to index (converters require materialized blocks)
+		mode := pack.WriteModeAll
+		if pkg.Selected() != nil {
+			mode = pack.WriteModeIncludeSelected
+		}
+		if err := idx.AddPack(ctx, pkg.Materialize(), mode); err != nil {
 			return err
 		}
+		n += pkg.NumSelected()
+		next = pkg.RowIds().Get(pkg.Len()-1) + 1
+
+		// flush and checkpoint
+		if n >= idx.opts.JournalSize {
+			if err := idx.checkpoint(ctx, next); err != nil {
+				return err
+			}
+			n = 0
+		}
+		if b != nil {
+			b.SetProgress(next, total)
+		}
 	}
 
 	// final index flush
-	return idx.Finalize(ctx, 0)
+	if err := idx.checkpoint(ctx, 0); err != nil {
+		return err
+	}
+	if b != nil {
+		b.SetProgress(total, total)
+	}
+	return nil
+}
+
+// checkpoint merges the journal and stores the next row id to build from.
+func (idx *Index) checkpoint(ctx context.Context, rid uint64) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.journal.Len() > 0 {
+		if err := idx.mergeAppend(ctx); err != nil {
+			return err
+		}
+	}
+	return idx.storeBuildCheckpoint(rid)
+}
+
+// convertPack builds an index pack from table rows and returns the write
+// mode to use for appending it. Token indexes expand rows into multiple
+// terms and resolve the selection during conversion.
+func (idx *Index) convertPack(pkg *pack.Package, mode pack.WriteMode) (*pack.Package, pack.WriteMode) {
+	ipkg := idx.convert.ConvertPack(pkg, mode)
+	if idx.sindex.Type == types.IndexTypeToken {
+		return ipkg, pack.WriteModeAll
+	}
+	ipkg.WithSelection(pkg.Selected())
+	return ipkg, mode
 }
 
 func (idx *Index) AddPack(ctx context.Context, pkg *pack.Package, mode pack.WriteMode) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
 	// idx.log.Debugf("index[%s]: add journal epoch %d to j[%d:%d]", idx.name, pkg.Key(),
 	// 	idx.journal.Len(), idx.journal.Cap())
 
 	// build new index pack, relink columns and/or produce `hash` column)
-	ipkg := idx.convert.ConvertPack(pkg, mode)
-	ipkg.WithSelection(pkg.Selected())
+	ipkg, mode := idx.convertPack(pkg, mode)
 
 	var state pack.AppendState
 
@@ -425,8 +568,7 @@ func (idx *Index) DelPack(ctx context.Context, pkg *pack.Package, mode pack.Writ
 	// idx.log.Debugf("index[%s]: del journal epoch %d", idx.idxSchema.Name(), pkg.Key())
 
 	// build new index pack, relink columns and produce `hash` column)
-	ipkg := idx.convert.ConvertPack(pkg, mode)
-	ipkg.WithSelection(pkg.Selected())
+	ipkg, mode := idx.convertPack(pkg, mode)
 
 	var state pack.AppendState
 	for {
@@ -453,6 +595,9 @@ func (idx *Index) DelPack(ctx context.Context, pkg *pack.Package, mode pack.Writ
 }
 
 func (idx *Index) Finalize(ctx context.Context, epoch uint32) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
 	// flush remaining journal entries
 	if idx.journal.Len() > 0 {
 		idx.log.Debugf("merge %d journal records", idx.journal.Len())
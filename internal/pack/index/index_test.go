@@ -1,11 +1,16 @@
 package index
 
 import (
+	"context"
 	"testing"
 
+	"blockwatch.cc/knoxdb/internal/engine"
+	"blockwatch.cc/knoxdb/internal/pack"
 	"blockwatch.cc/knoxdb/internal/pack/table"
 	etests "blockwatch.cc/knoxdb/internal/tests/engine"
 	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"github.com/stretchr/testify/require"
 
 	_ "blockwatch.cc/knoxdb/pkg/store/boltdb"
 	_ "blockwatch.cc/knoxdb/pkg/store/memdb"
@@ -21,6 +26,8 @@ func TestIndex(t *testing.T) {
 	typs := []types.IndexType{
 		types.IndexTypeInt,
 		types.IndexTypeHash,
+		types.IndexTypeBitmap,
+		types.IndexTypeToken,
 	}
 	etests.TestIndexEngine[Index, *Index](t, "mem", "pack", table.NewTable(), typs)
 	etests.TestIndexEngine[Index, *Index](t, "bolt", "pack", table.NewTable(), typs)
@@ -30,3 +37,102 @@ func TestIndexComposite(t *testing.T) {
 	etests.TestCompositeIndexEngine[Index, *Index](t, "mem", "pack", table.NewTable())
 	etests.TestCompositeIndexEngine[Index, *Index](t, "bolt", "pack", table.NewTable())
 }
+
+// TestBuildResume ensures a build interrupted by close resumes from its
+// checkpoint after reopen and produces the same index as a full rebuild.
+func TestBuildResume(t *testing.T) {
+	e := etests.NewTestEngine(t, etests.NewTestDatabaseOptions(t, "bolt"))
+	defer e.Close(context.Background())
+	ctx := engine.WithEngine(context.Background(), e)
+
+	s, err := schema.SchemaOf(&mergeTestStruct{})
+	require.NoError(t, err)
+	tab, err := e.CreateTable(ctx, s.WithMeta(), etests.NewTestTableOptions(t, "bolt", "pack").TableOptions()...)
+	require.NoError(t, err)
+	ts := tab.Schema()
+
+	// store table rows
+	const n = 1000
+	rows := make([]mergeTestStruct, n)
+	for i := range rows {
+		rows[i].Value = uint64(i % 13)
+	}
+	buf, err := schema.NewEncoder(ts).EncodeSlice(rows, nil)
+	require.NoError(t, err)
+	tctx, _, commit, abort, err := e.WithTransaction(ctx)
+	require.NoError(t, err)
+	_, cnt, err := tab.InsertRows(tctx, buf)
+	require.NoError(t, err)
+	require.Equal(t, n, cnt)
+	require.NoError(t, commit())
+	abort()
+	require.NoError(t, tab.Flush(ctx))
+
+	ss, err := ts.Select("value")
+	require.NoError(t, err)
+	is := &schema.IndexSchema{
+		Name:   "value_index",
+		Type:   types.IndexTypeInt,
+		Base:   ts,
+		Fields: ss.Fields,
+	}
+	iopts := etests.NewTestIndexOptions(t, "bolt", "pack")
+
+	// index the first half and store a checkpoint, then add more rows
+	// which are lost on close
+	idx := NewIndex().(*Index)
+	require.NoError(t, idx.Create(ctx, tab, is, iopts.IndexOptions()...))
+	enc := schema.NewEncoder(ts)
+	add := func(from, to int) {
+		t.Helper()
+		pkg := pack.New().WithSchema(ts).WithMaxRows(to - from).Alloc()
+		defer pkg.Release()
+		for i := from; i < to; i++ {
+			rid := uint64(i + 1)
+			buf, err := enc.Encode(&mergeTestStruct{Id: rid, Value: rows[i].Value}, nil)
+			require.NoError(t, err)
+			pkg.AppendWire(buf, &schema.Meta{Rid: rid})
+		}
+		require.NoError(t, idx.AddPack(ctx, pkg, pack.WriteModeAll))
+	}
+	add(0, n/2)
+	require.NoError(t, idx.checkpoint(ctx, n/2+1))
+	add(n/2, n/2+100)
+	require.NoError(t, idx.Close(ctx))
+
+	// reopen and resume
+	idx = NewIndex().(*Index)
+	require.NoError(t, idx.Open(ctx, tab, is, iopts.IndexOptions()...))
+	defer idx.Close(ctx)
+	from, err := idx.loadBuildCheckpoint()
+	require.NoError(t, err)
+	require.Equal(t, uint64(n/2+1), from)
+	require.NoError(t, idx.Build(ctx, nil))
+	from, err = idx.loadBuildCheckpoint()
+	require.NoError(t, err)
+	require.Zero(t, from, "checkpoint not removed")
+	resumed := dumpIndex(ctx, idx)
+	require.Len(t, resumed, n)
+	require.Equal(t, int64(n), idx.Metrics().TupleCount)
+
+	// full rebuild produces the same records
+	require.NoError(t, idx.Truncate(ctx))
+	require.NoError(t, idx.Rebuild(ctx))
+	require.Equal(t, dumpIndex(ctx, idx), resumed)
+}
+
+// dumpIndex returns all stored index records as (key, rid) pairs.
+func dumpIndex(ctx context.Context, idx *Index) [][2]uint64 {
+	var res [][2]uint64
+	for i := 0; ; i++ {
+		pkg := idx.ViewPackage(ctx, i)
+		if pkg == nil {
+			return res
+		}
+		keys, rids := pkg.Block(0), pkg.Block(1)
+		for k := range pkg.Len() {
+			res = append(res, [2]uint64{keys.Get(k).(uint64), rids.Get(k).(uint64)})
+		}
+		pkg.Release()
+	}
+}
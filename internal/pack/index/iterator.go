@@ -337,18 +337,18 @@ func (it *ScanIterator) Next(ctx context.Context) (*pack.Package, []uint32, erro
 		// find the first pack with likely matches
 		if it.from != nil {
 			key, _, err := it.bucket.SearchLE(it.from)
-			if err != nil {
-				if !errors.Is(err, store.ErrKeyNotFound) {
-					return nil, nil, err
-				}
-				// there is no match at all
-				return nil, nil, nil
+			switch {
+			case err == nil:
+				// use the pack's key as actual range start, but re-encode
+				// to make sure we start at block 0
+				ik, rid, _ := it.idx.decodePackKey(key)
+				it.from = it.idx.encodePackKey(ik, rid, 0)
+			case errors.Is(err, store.ErrKeyNotFound):
+				// no pack starts before our prefix, but the first pack
+				// may still start with the prefix, so scan from there
+			default:
+				return nil, nil, err
 			}
-
-			// use the pack's key as actual range start, but re-encode
-			// to make sure we start at block 0
-			ik, rid, _ := it.idx.decodePackKey(key)
-			it.from = it.idx.encodePackKey(ik, rid, 0)
 		}
 
 		it.idx.log.Tracef("Scan %s => range %#v .. %#v", it.node, it.from, it.to)
@@ -438,6 +438,11 @@ func (it *MergeIterator) loadNextPack(find MergeValue) ([]byte, error) {
 // writes journal records to index packs. this is called during table merge when
 // index journal runs full and when finalizing index updates.
 func (idx *Index) mergeAppend(ctx context.Context) error {
+	// bitmap and token indexes keep one row id bitmap per key
+	if idx.isBitmap() {
+		return idx.mergeBitmapAppend(ctx)
+	}
+
 	idx.log.Debugf("merging journal[%d]", idx.journal.Len())
 
 	var (
@@ -453,6 +458,20 @@ func (idx *Index) mergeAppend(ctx context.Context) error {
 	// co-sort journal vectors in-place
 	util.Sort2(j0, j1)
 
+	// drop duplicate journal records, background builds and table merges
+	// may both add the same rows
+	if jlen > 1 {
+		n := 1
+		for i := 1; i < jlen; i++ {
+			if j0[i] == j0[n-1] && j1[i] == j1[n-1] {
+				continue
+			}
+			j0[n], j1[n] = j0[i], j1[i]
+			n++
+		}
+		jlen = n
+	}
+
 	// iterator to lookup & load matching source packages
 	it := NewMergeIterator(idx)
 	defer it.Close()
@@ -495,20 +514,25 @@ func (idx *Index) mergeAppend(ctx context.Context) error {
 		o1 := out.Block(1).Uint64()
 
 		// merge src and journal content into out
-		var sval, jval MergeValue
+		var (
+			sval, jval MergeValue
+			jstop      bool
+		)
 	mergeloop:
 		for {
 			// load next values
 			if spos < slen && !sval.IsValid() {
 				sval = NewMergeValue(s0.Get(spos), s1.Get(spos))
 			}
-			if jpos < jlen && !jval.IsValid() {
+			if jpos < jlen && !jval.IsValid() && !jstop {
 				jval = NewMergeValue(j0[jpos], j1[jpos])
 
-				// stop when this journal value crosses next src pack's min
+				// stop using journal values when crossing next src pack's min,
+				// but still copy remaining src values into out
 				if bound.IsValid() && !jval.Less(bound) {
-					// idx.log.Tracef("merge: break at boundary jval %x:%d", jval.Key, jval.Rid)
-					break mergeloop
+					// idx.log.Tracef("merge: stop at boundary jval %x:%d", jval.Key, jval.Rid)
+					jval.Reset()
+					jstop = true
 				}
 			}
 
@@ -625,6 +649,11 @@ func (idx *Index) mergeAppend(ctx context.Context) error {
 
 // removes tombstoned records from journal packs by rewriting packs.
 func (idx *Index) mergeTomb(ctx context.Context, tomb *pack.Package) error {
+	// bitmap and token indexes keep one row id bitmap per key
+	if idx.isBitmap() {
+		return idx.mergeBitmapTomb(ctx, tomb)
+	}
+
 	idx.log.Debugf("merging tomb[%d]", tomb.Len())
 
 	var (
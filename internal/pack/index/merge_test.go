@@ -0,0 +1,156 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package index
+
+import (
+	"context"
+	"testing"
+
+	"blockwatch.cc/knoxdb/internal/engine"
+	"blockwatch.cc/knoxdb/internal/operator/filter"
+	"blockwatch.cc/knoxdb/internal/pack"
+	"blockwatch.cc/knoxdb/internal/pack/table"
+	etests "blockwatch.cc/knoxdb/internal/tests/engine"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"github.com/stretchr/testify/require"
+)
+
+type mergeTestStruct struct {
+	Id    uint64 `knox:"id,pk"`
+	Value uint64 `knox:"value"`
+}
+
+// TestMergeBoundary ensures merge keeps all source records of a pack when
+// a journal value crosses the next pack's boundary.
+func TestMergeBoundary(t *testing.T) {
+	e := etests.NewTestEngine(t, etests.NewTestDatabaseOptions(t, "mem"))
+	defer e.Close(context.Background())
+	ctx := engine.WithEngine(context.Background(), e)
+
+	s, err := schema.SchemaOf(&mergeTestStruct{})
+	require.NoError(t, err)
+	tab := table.NewTable()
+	etests.CreateTable(t, e, tab, etests.NewTestTableOptions(t, "mem", "pack"), s)
+	defer tab.Close(ctx)
+	ts := tab.Schema()
+	ss, err := ts.Select("value")
+	require.NoError(t, err)
+	iopts := etests.NewTestIndexOptions(t, "mem", "pack")
+	idx := NewIndex().(*Index)
+	etests.CreateIndex(t, e, tab, idx, &schema.IndexSchema{
+		Name:   "value_index",
+		Type:   types.IndexTypeInt,
+		Base:   ts,
+		Fields: ss.Fields,
+	}, iopts)
+
+	// even values fill multiple index packs
+	sz := iopts.PackSize
+	enc := schema.NewEncoder(ts)
+	add := func(vals ...uint64) {
+		t.Helper()
+		pkg := pack.New().WithSchema(ts).WithMaxRows(len(vals)).Alloc()
+		defer pkg.Release()
+		for _, v := range vals {
+			buf, err := enc.Encode(&mergeTestStruct{Id: v + 1, Value: v}, nil)
+			require.NoError(t, err)
+			pkg.AppendWire(buf, &schema.Meta{Rid: v + 1})
+		}
+		require.NoError(t, idx.AddPack(ctx, pkg, pack.WriteModeAll))
+		require.NoError(t, idx.Finalize(ctx, 1))
+	}
+	vals := make([]uint64, 2*sz)
+	for i := range vals {
+		vals[i] = uint64(2 * i)
+	}
+	add(vals...)
+	require.NotNil(t, idx.ViewPackage(ctx, 1), "missing second index pack")
+
+	// merge a value into the first pack and a value behind all packs
+	add(1, uint64(4*sz+1))
+
+	f, _ := ts.Find("value")
+	m := filter.NewFactory(f.Type).New(types.FilterModeLt)
+	m.WithValue(uint64(4 * sz))
+	res, _, err := idx.Query(ctx, filter.NewNode().SetFilter(&filter.Filter{
+		Name:    f.Name,
+		Type:    f.Type.BlockType(),
+		Mode:    types.FilterModeLt,
+		Index:   1,
+		Id:      f.Id,
+		Value:   uint64(4 * sz),
+		Matcher: m,
+	}))
+	require.NoError(t, err)
+	require.Equal(t, 2*sz+1, res.Count())
+}
+
+// TestMergeDuplicates ensures merge stores records added more than once
+// only once, e.g. when a background build and a table merge index the
+// same rows.
+func TestMergeDuplicates(t *testing.T) {
+	e := etests.NewTestEngine(t, etests.NewTestDatabaseOptions(t, "mem"))
+	defer e.Close(context.Background())
+	ctx := engine.WithEngine(context.Background(), e)
+
+	s, err := schema.SchemaOf(&mergeTestStruct{})
+	require.NoError(t, err)
+	tab := table.NewTable()
+	etests.CreateTable(t, e, tab, etests.NewTestTableOptions(t, "mem", "pack"), s)
+	defer tab.Close(ctx)
+	ts := tab.Schema()
+	ss, err := ts.Select("value")
+	require.NoError(t, err)
+	idx := NewIndex().(*Index)
+	etests.CreateIndex(t, e, tab, idx, &schema.IndexSchema{
+		Name:   "value_index",
+		Type:   types.IndexTypeInt,
+		Base:   ts,
+		Fields: ss.Fields,
+	}, etests.NewTestIndexOptions(t, "mem", "pack"))
+
+	enc := schema.NewEncoder(ts)
+	add := func(vals ...uint64) {
+		t.Helper()
+		pkg := pack.New().WithSchema(ts).WithMaxRows(len(vals)).Alloc()
+		defer pkg.Release()
+		for _, v := range vals {
+			buf, err := enc.Encode(&mergeTestStruct{Id: v + 1, Value: v % 7}, nil)
+			require.NoError(t, err)
+			pkg.AppendWire(buf, &schema.Meta{Rid: v + 1})
+		}
+		require.NoError(t, idx.AddPack(ctx, pkg, pack.WriteModeAll))
+	}
+	count := func() int {
+		t.Helper()
+		var n int
+		for i := 0; ; i++ {
+			pkg := idx.ViewPackage(ctx, i)
+			if pkg == nil {
+				return n
+			}
+			n += pkg.Len()
+			pkg.Release()
+		}
+	}
+	vals := make([]uint64, 100)
+	for i := range vals {
+		vals[i] = uint64(i)
+	}
+
+	// duplicates within the journal
+	add(vals...)
+	add(vals[:50]...)
+	require.NoError(t, idx.Finalize(ctx, 1))
+	require.Equal(t, 100, count())
+	require.Equal(t, int64(100), idx.Metrics().TupleCount)
+
+	// duplicates of stored records
+	add(vals[50:]...)
+	add(vals[50:]...)
+	require.NoError(t, idx.Finalize(ctx, 2))
+	require.Equal(t, 100, count())
+	require.Equal(t, int64(100), idx.Metrics().TupleCount)
+}
@@ -6,18 +6,23 @@ package index
 import (
 	"context"
 	"fmt"
+	"regexp/syntax"
 	"sort"
+	"strings"
 	"sync/atomic"
 
 	"blockwatch.cc/knoxdb/internal/engine"
 	"blockwatch.cc/knoxdb/internal/operator/filter"
 	"blockwatch.cc/knoxdb/internal/types"
 	"blockwatch.cc/knoxdb/internal/xroar"
+	"blockwatch.cc/knoxdb/pkg/schema"
 )
 
 // This index supports the following condition types on lookup.
-// - hash: EQ, IN, NI (single or composite EQ)
-// - int:  EQ, IN, NI, LT, LE GT, GE, RG (single condition)
+// - hash:   EQ, IN, NI (single or composite EQ)
+// - int:    EQ, IN, NI, LT, LE GT, GE, RG (single condition)
+// - token:  CONTAINS, MATCH (single condition)
+// - bitmap: EQ, IN, NI (single condition)
 func (idx *Index) CanMatch(c engine.QueryCondition) bool {
 	node, ok := c.(*filter.Node)
 	if !ok {
@@ -44,7 +49,7 @@ func (idx *Index) CanMatch(c engine.QueryCondition) bool {
 			if !c.IsLeaf() {
 				continue
 			}
-			if field.Name == c.Filter.Name && c.Filter.Mode == types.FilterModeEqual {
+			if field.Name == c.Filter.Name && c.Filter.Mode == types.FilterModeEqual && !c.Filter.Expr.IsValid() {
 				canMatchField = true
 				break
 			}
@@ -60,11 +65,26 @@ func (idx *Index) canMatchFilter(f *filter.Filter) bool {
 	if !idx.sindex.Contains(f.Name) {
 		return false
 	}
+	// token indexes only answer text search filters with usable terms
+	if idx.sindex.Type == types.IndexTypeToken {
+		if f.Expr.IsValid() {
+			return false
+		}
+		return len(idx.convert.(*TokenConverter).QueryTerms(&filter.Node{Filter: f})) > 0
+	}
+	// expression indexes only match filters using the same expression
+	// except case-insensitive regexp literals on lower() indexes
+	if f.Expr != idx.sindex.Expr {
+		_, ok := idx.foldLiteral(f)
+		return ok
+	}
 	switch f.Mode {
 	case types.FilterModeEqual:
 		return true
 	case types.FilterModeIn:
-		return idx.sindex.Type == types.IndexTypeHash
+		return idx.sindex.Type == types.IndexTypeHash || idx.sindex.Type == types.IndexTypeBitmap
+	case types.FilterModeNotIn:
+		return idx.sindex.Type == types.IndexTypeBitmap
 	case types.FilterModeLt,
 		types.FilterModeLe,
 		types.FilterModeGt,
@@ -98,22 +118,76 @@ func (idx *Index) Query(ctx context.Context, c engine.QueryCondition) (*xroar.Bi
 	)
 	switch idx.sindex.Type {
 	case types.IndexTypeHash:
+		// translate case-insensitive regexp literals into lower() keys
+		if lit, ok := idx.foldLiteral(node.Filter); ok {
+			node = &filter.Node{Filter: node.Filter.As(types.FilterModeEqual, lit)}
+		}
+
 		// convert query values to hash values and lookup
 		bits, err = idx.lookupKeys(ctx, idx.convert.QueryKeys(node))
 
 	case types.IndexTypeInt:
 		// execute the condition directly (like on table scans)
 		bits, err = idx.queryKeys(ctx, idx.convert.QueryNode(node))
+
+	case types.IndexTypeToken:
+		// combine term posting lists
+		bits, err = idx.queryTerms(ctx, idx.convert.(*TokenConverter).QueryTerms(node))
+
+	case types.IndexTypeBitmap:
+		// combine value bitmaps
+		bits, err = idx.queryBitmaps(ctx, node)
 	}
 	if err != nil {
 		return nil, false, err
 	}
 
-	// collide depend on method
-	canCollide := idx.sindex.Type == types.IndexTypeHash
+	// collide depend on method, token results are candidates because
+	// terms are hashed and n-grams only approximate substrings; null values
+	// are indexed as zero values and require a recheck
+	canCollide := idx.sindex.Type != types.IndexTypeInt && idx.sindex.Type != types.IndexTypeBitmap
+	canCollide = canCollide || idx.sindex.Fields[0].IsNullable()
 	return bits, canCollide, err
 }
 
+// foldLiteral returns the lower case literal of a case-insensitive exact
+// match regexp filter like `(?i)^alice$` when the index stores lower()
+// keys for the filter field. Results need a recheck with the original
+// regexp which is guaranteed since hash lookups can collide.
+func (idx *Index) foldLiteral(f *filter.Filter) ([]byte, bool) {
+	if f.Mode != types.FilterModeRegexp || f.Expr.IsValid() {
+		return nil, false
+	}
+	if idx.sindex.Type != types.IndexTypeHash || idx.sindex.Expr.Type != schema.IndexExprLower {
+		return nil, false
+	}
+	var pattern string
+	switch v := f.Value.(type) {
+	case string:
+		pattern = v
+	case []byte:
+		pattern = string(v)
+	default:
+		return nil, false
+	}
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, false
+	}
+	re = re.Simplify()
+	if re.Op != syntax.OpConcat || len(re.Sub) != 3 {
+		return nil, false
+	}
+	begin, lit, end := re.Sub[0], re.Sub[1], re.Sub[2]
+	if begin.Op != syntax.OpBeginText || end.Op != syntax.OpEndText {
+		return nil, false
+	}
+	if lit.Op != syntax.OpLiteral || lit.Flags&syntax.FoldCase == 0 {
+		return nil, false
+	}
+	return []byte(strings.ToLower(string(lit.Rune))), true
+}
+
 func (idx *Index) QueryComposite(ctx context.Context, c engine.QueryCondition) (*xroar.Bitmap, bool, error) {
 	node, ok := c.(*filter.Node)
 	if !ok {
@@ -147,3 +147,34 @@ func (idx *Index) dropTomb(_ context.Context, key, epoch uint32) error {
 		return nil
 	})
 }
+
+// loadBuildCheckpoint returns the next table row id a background build
+// should continue from or zero when no build is in progress.
+func (idx *Index) loadBuildCheckpoint() (uint64, error) {
+	var rid uint64
+	err := idx.db.View(func(tx store.Tx) error {
+		buf, err := store.GetKey(tx, idx.state.Key, engine.BuildKey)
+		if err != nil || len(buf) == 0 {
+			return nil
+		}
+		rid, _ = num.Uvarint(buf)
+		return nil
+	})
+	return rid, err
+}
+
+// storeBuildCheckpoint writes the next table row id to process. A zero row id
+// removes the checkpoint.
+func (idx *Index) storeBuildCheckpoint(rid uint64) error {
+	return idx.db.Update(func(tx store.Tx) error {
+		b, err := tx.Bucket(idx.state.Key)
+		if err != nil {
+			return err
+		}
+		if rid == 0 {
+			return b.Delete(engine.BuildKey)
+		}
+		var buf [num.MaxVarintLen64]byte
+		return b.Put(engine.BuildKey, num.AppendUvarint(buf[:0], rid))
+	})
+}
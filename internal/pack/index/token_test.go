@@ -0,0 +1,213 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package index
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"blockwatch.cc/knoxdb/internal/engine"
+	"blockwatch.cc/knoxdb/internal/operator/filter"
+	"blockwatch.cc/knoxdb/internal/pack"
+	etests "blockwatch.cc/knoxdb/internal/tests/engine"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/internal/xroar"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"blockwatch.cc/knoxdb/pkg/store"
+	"github.com/stretchr/testify/require"
+)
+
+var tokenTestRows = []tokenTestStruct{
+	{Text: "The quick brown fox", Title: "Quickstart"},
+	{Text: "lazy dog", Title: "Lazy days"},
+	{Text: "quick dog", Title: "Brown bear"},
+	{Text: "brown bear", Title: "quick brown"},
+}
+
+// TestTokenIndex ensures token indexes build one posting list bitmap per
+// term from table rows, merge journal and tomb records into stored
+// posting lists and answer lookups from them.
+func TestTokenIndex(t *testing.T) {
+	for _, driver := range []string{"mem", "bolt"} {
+		t.Run(driver, func(t *testing.T) {
+			testTokenIndex(t, driver)
+		})
+	}
+}
+
+func testTokenIndex(t *testing.T, driver string) {
+	e := etests.NewTestEngine(t, etests.NewTestDatabaseOptions(t, driver))
+	defer e.Close(context.Background())
+	ctx := engine.WithEngine(context.Background(), e)
+
+	s, err := schema.SchemaOf(&tokenTestStruct{})
+	require.NoError(t, err)
+	tab, err := e.CreateTable(ctx, s.WithMeta(), etests.NewTestTableOptions(t, driver, "pack").TableOptions()...)
+	require.NoError(t, err)
+	ts := tab.Schema()
+
+	// store table rows
+	buf, err := schema.NewEncoder(ts).EncodeSlice(tokenTestRows, nil)
+	require.NoError(t, err)
+	tctx, _, commit, abort, err := e.WithTransaction(ctx)
+	require.NoError(t, err)
+	_, cnt, err := tab.InsertRows(tctx, buf)
+	require.NoError(t, err)
+	require.Equal(t, len(tokenTestRows), cnt)
+	require.NoError(t, commit())
+	abort()
+	require.NoError(t, tab.Flush(ctx))
+
+	// build word and n-gram indexes from table rows
+	iopts := etests.NewTestIndexOptions(t, driver, "pack")
+	words, grams := NewIndex().(*Index), NewIndex().(*Index)
+	for _, v := range []struct {
+		idx  *Index
+		name string
+	}{
+		{words, "text"},
+		{grams, "title"},
+	} {
+		ss, err := ts.Select(v.name)
+		require.NoError(t, err)
+		is := &schema.IndexSchema{
+			Name:   v.name + "_index",
+			Type:   types.IndexTypeToken,
+			Base:   ts,
+			Fields: ss.Fields,
+		}
+		if v.name == "title" {
+			is.Tokenizer = schema.Ngram(3)
+		}
+		require.NoError(t, v.idx.Create(ctx, tab, is, iopts.IndexOptions()...))
+		defer v.idx.Close(ctx)
+		require.NoError(t, v.idx.Rebuild(ctx))
+	}
+
+	// one posting list per unique term
+	require.Equal(t, []string{"bear", "brown", "dog", "fox", "lazy", "quick", "the"}, postingTerms(t, words, tokenTestRows))
+	require.Equal(t, uint64(10), words.state.NRows)
+	require.Equal(t, []uint64{1, 4}, postingList(t, words, "brown"))
+	require.Equal(t, []uint64{1, 3}, postingList(t, words, "quick"))
+
+	// lookups combine posting lists
+	query := func(idx *Index, mode types.FilterMode, val string) []uint64 {
+		t.Helper()
+		f, _ := ts.Find(idx.sindex.Fields[0].Name)
+		m := filter.NewFactory(f.Type).New(mode)
+		m.WithValue([]byte(val))
+		bits, collide, err := idx.Query(ctx, filter.NewNode().SetFilter(&filter.Filter{
+			Name:    f.Name,
+			Type:    f.Type.BlockType(),
+			Mode:    mode,
+			Id:      f.Id,
+			Value:   []byte(val),
+			Matcher: m,
+		}))
+		require.NoError(t, err)
+		require.True(t, collide, "token results need recheck")
+		return bits.ToArray(nil)
+	}
+	match := func(val string) []uint64 { return query(words, types.FilterModeMatch, val) }
+	contains := func(val string) []uint64 { return query(grams, types.FilterModeContains, val) }
+	require.Equal(t, []uint64{1, 3}, match("quick"))
+	require.Equal(t, []uint64{3}, match("Quick dog"))
+	require.Equal(t, []uint64{2, 3, 4}, match("dog | bear"))
+	require.Equal(t, []uint64{1}, match("fox | cat"))
+	require.Empty(t, match("cat"))
+	require.Empty(t, match("quick cat"))
+	require.Equal(t, []uint64{1, 4}, contains("QUICK"))
+	require.Equal(t, []uint64{3, 4}, contains("brow"))
+	require.Empty(t, contains("quiet"))
+
+	// merge journal records into stored posting lists
+	enc := schema.NewEncoder(ts)
+	add := func(rid uint64, v tokenTestStruct) *pack.Package {
+		t.Helper()
+		pkg := pack.New().WithSchema(ts).WithMaxRows(1).Alloc()
+		v.Id = rid
+		buf, err := enc.Encode(&v, nil)
+		require.NoError(t, err)
+		pkg.AppendWire(buf, &schema.Meta{Rid: rid})
+		for _, idx := range []*Index{words, grams} {
+			require.NoError(t, idx.AddPack(ctx, pkg, pack.WriteModeAll))
+			require.NoError(t, idx.Finalize(ctx, 1))
+		}
+		return pkg
+	}
+	last := add(5, tokenTestStruct{Text: "Quick cat", Title: "quiet"})
+	defer last.Release()
+	require.Equal(t, []uint64{1, 3, 5}, match("quick"))
+	require.Equal(t, []uint64{5}, match("cat"))
+	require.Equal(t, []uint64{5}, contains("quiet"))
+	require.Equal(t, uint64(12), words.state.NRows)
+
+	// merge tombstones, posting lists without rows are removed
+	for _, idx := range []*Index{words, grams} {
+		require.NoError(t, idx.DelPack(ctx, last, pack.WriteModeAll, 2))
+		require.NoError(t, idx.Finalize(ctx, 2))
+		require.NoError(t, idx.GC(ctx, 2))
+	}
+	require.Equal(t, []uint64{1, 3}, match("quick"))
+	require.Empty(t, match("cat"))
+	require.Empty(t, contains("quiet"))
+	require.Equal(t, []string{"bear", "brown", "dog", "fox", "lazy", "quick", "the"}, postingTerms(t, words, tokenTestRows))
+	require.Equal(t, uint64(10), words.state.NRows)
+}
+
+// postingList returns the stored row ids of term.
+func postingList(t *testing.T, idx *Index, term string) []uint64 {
+	t.Helper()
+	var res []uint64
+	key := idx.convert.(*TokenConverter).QueryTerms(&filter.Node{
+		Filter: &filter.Filter{Mode: types.FilterModeMatch, Value: []byte(term)},
+	})[0][0]
+	err := idx.db.View(func(tx store.Tx) error {
+		buf, err := idx.dataBucket(tx).Get(idx.encodeBitmapKey(key))
+		if err != nil {
+			if errors.Is(err, store.ErrKeyNotFound) {
+				err = nil
+			}
+			return err
+		}
+		res = xroar.NewFromBytes(buf).ToArray(nil)
+		return nil
+	})
+	require.NoError(t, err)
+	return res
+}
+
+// postingTerms returns the sorted words of rows which have a stored
+// posting list and checks no other posting lists exist.
+func postingTerms(t *testing.T, idx *Index, rows []tokenTestStruct) []string {
+	t.Helper()
+	var (
+		res  []string
+		seen = make(map[string]struct{})
+		n    int
+	)
+	for _, row := range rows {
+		for _, term := range schema.Words().Tokens([]byte(row.Text)) {
+			if _, ok := seen[string(term)]; ok {
+				continue
+			}
+			seen[string(term)] = struct{}{}
+			if len(postingList(t, idx, string(term))) > 0 {
+				res = append(res, string(term))
+			}
+		}
+	}
+	err := idx.db.View(func(tx store.Tx) error {
+		for range idx.dataBucket(tx).Scan(nil) {
+			n++
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, res, n, "unexpected posting lists")
+	slices.Sort(res)
+	return res
+}
@@ -172,6 +172,12 @@ func (j *Journal) Close() {
 // write the new table checkpoint to disk.
 func (j *Journal) Checkpoint(_ context.Context) error {
 	j.doRotate()
+
+	// without an open transaction no later commit or abort would
+	// complete the rotated segment, so make it mergable right away
+	if seg := j.tail[len(j.tail)-1]; seg.xact == 0 {
+		seg.setState(SegmentStateComplete)
+	}
 	return j.doCheckpoint()
 }
 
@@ -480,6 +486,7 @@ func (j *Journal) Query(plan *query.QueryPlan, epoch uint32) *Result {
 
 	// alloc result and match bitset
 	res := NewResult()
+	res.order = plan.Order
 	bits := bitset.New(j.maxsz)
 
 	// Single-pass merge
@@ -225,6 +225,64 @@ func TestJournalRotate(t *testing.T) {
 	require.Nil(t, j.tip.parent)
 }
 
+func TestJournalCheckpoint(t *testing.T) {
+	ctx, j, makeRecord := setupJournalTest(t)
+	j.WithWal(engine.GetEngine(ctx).Wal())
+	xid := engine.GetTxId(ctx)
+
+	_, _, err := j.InsertRecords(ctx, makeRecord(0))
+	require.NoError(t, err)
+	j.CommitTx(xid)
+
+	// checkpoint rotates the tip, segment without open tx is mergable
+	require.NoError(t, j.Checkpoint(ctx))
+	require.Equal(t, 2, j.NumSegments(), "seg count")
+	seg, err := j.NextMergable()
+	require.NoError(t, err)
+	require.NotNil(t, seg)
+	require.Equal(t, uint32(1), seg.Id())
+	j.ConfirmMerged(ctx, seg)
+	require.Equal(t, 1, j.NumSegments(), "seg count")
+
+	// segment with open tx waits for commit
+	ctx = setupNextTx(t, ctx)
+	xid = engine.GetTxId(ctx)
+	_, _, err = j.InsertRecords(ctx, makeRecord(0))
+	require.NoError(t, err)
+	require.NoError(t, j.Checkpoint(ctx))
+	seg, err = j.NextMergable()
+	require.NoError(t, err)
+	require.Nil(t, seg)
+	canMerge, _ := j.CommitTx(xid)
+	require.True(t, canMerge)
+	seg, err = j.NextMergable()
+	require.NoError(t, err)
+	require.NotNil(t, seg)
+	require.Equal(t, uint32(2), seg.Id())
+}
+
+func TestJournalQueryDesc(t *testing.T) {
+	ctx, j, makeRecord := setupJournalTest(t)
+	for i := range 10 {
+		_, _, err := j.InsertRecords(ctx, makeRecord(i))
+		require.NoError(t, err)
+	}
+
+	// desc queries expect selection vectors in reverse order
+	plan := &query.QueryPlan{
+		Filters: filter.NewNode().AddLeaf(
+			filter.NewFilter(j.schema.Pk(), j.schema.PkIndex(), types.FilterModeGt, uint64(5)),
+		),
+		Snap:  engine.GetSnapshot(ctx),
+		Order: types.OrderDesc,
+		Log:   j.log,
+	}
+	res := j.Query(plan, 0)
+	defer res.Close()
+	require.Equal(t, 5, res.Len())
+	require.Equal(t, []uint32{9, 8, 7, 6, 5}, res.pkgs[0].Selected())
+}
+
 func TestJournalRotateAborted(t *testing.T) {
 	ctx, j, makeRecord := setupJournalTest(t)
 	xid := engine.GetTxId(ctx)
@@ -21,16 +21,18 @@ var (
 )
 
 type Package struct {
-	key      uint32         // identity
-	version  uint32         // version epoch (set on write, only 16 bits used on storage)
-	nRows    int            // current number of rows
-	maxRows  int            // max number of rows (== block allocation size)
-	px       int            // primary key index (position in schema)
-	rx       int            // row id index (position in schema)
-	schema   *schema.Schema // logical data types for column vectors (required)
-	blocks   []*block.Block // physical column vectors, maybe nil when unsued
-	stats    *Stats         // vector and encoder statistics for metadata index (optional)
-	selected []uint32       // selection vector used in operator pipelines (optional)
+	key      uint32                    // identity
+	version  uint32                    // version epoch (set on write, only 16 bits used on storage)
+	nRows    int                       // current number of rows
+	maxRows  int                       // max number of rows (== block allocation size)
+	px       int                       // primary key index (position in schema)
+	rx       int                       // row id index (position in schema)
+	schema   *schema.Schema            // logical data types for column vectors (required)
+	blocks   []*block.Block            // physical column vectors, maybe nil when unsued
+	stats    *Stats                    // vector and encoder statistics for metadata index (optional)
+	selected []uint32                  // selection vector used in operator pipelines (optional)
+	dicts    *block.DictSet            // compression dictionaries for storage (optional)
+	dcache   *block.DiskCachePartition // second tier block cache for disk loads (optional)
 }
 
 func New() *Package {
@@ -82,6 +84,16 @@ func (p *Package) WithBlock(i int, b *block.Block) *Package {
 	return p
 }
 
+func (p *Package) WithDicts(d *block.DictSet) *Package {
+	p.dicts = d
+	return p
+}
+
+func (p *Package) WithDiskCache(c *block.DiskCachePartition) *Package {
+	p.dcache = c
+	return p
+}
+
 func (p *Package) WithSelection(sel []uint32) *Package {
 	p.selected = sel
 	return p
@@ -160,7 +172,11 @@ func (p *Package) Alloc() *Package {
 		}
 
 		// allocate block
-		p.blocks[i] = block.New(field.Type.BlockType(), p.maxRows)
+		if field.Type == types.FieldTypeList {
+			p.blocks[i] = block.NewList(field.Elem.BlockType(), p.maxRows)
+		} else {
+			p.blocks[i] = block.New(field.Type.BlockType(), p.maxRows)
+		}
 	}
 
 	return p
@@ -262,6 +278,8 @@ func (p *Package) Release() {
 	p.px = 0
 	p.rx = 0
 	p.schema = nil
+	p.dicts = nil
+	p.dcache = nil
 	p.blocks = p.blocks[:0]
 	pool.Put(p)
 }
@@ -6,12 +6,14 @@ package pack
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"math"
 	"reflect"
 	"time"
 	"unsafe"
 
+	"blockwatch.cc/knoxdb/internal/block"
 	"blockwatch.cc/knoxdb/internal/types"
 	"blockwatch.cc/knoxdb/pkg/assert"
 	"blockwatch.cc/knoxdb/pkg/num"
@@ -37,6 +39,11 @@ func (p *Package) ReadWireFields(buf *bytes.Buffer, row int, cols []int) error {
 			x   [8]byte
 			err error
 		)
+		if f.HasValidity() {
+			if err = buf.WriteByte(validFlag(b, row)); err != nil {
+				return err
+			}
+		}
 		switch b.Type() {
 		case types.BlockUint64:
 			LE.PutUint64(x[:], b.Uint64().Get(row))
@@ -69,7 +76,7 @@ func (p *Package) ReadWireFields(buf *bytes.Buffer, row int, cols []int) error {
 		case types.BlockBool:
 			v := b.Bool().Get(row)
 			err = buf.WriteByte(*(*byte)(unsafe.Pointer(&v)))
-		case types.BlockBytes:
+		case types.BlockBytes, types.BlockBigint, types.BlockList, types.BlockDocument:
 			if f.Fixed > 0 {
 				_, err = buf.Write(b.Bytes().Get(row)[:f.Fixed])
 			} else {
@@ -107,6 +114,14 @@ var (
 	LE    = binary.LittleEndian // values
 )
 
+// validFlag returns the wire validity flag for a nullable value.
+func validFlag(b *block.Block, row int) byte {
+	if b.IsNull(row) {
+		return 0
+	}
+	return 1
+}
+
 func (p *Package) ReadWireBuffer(buf *bytes.Buffer, row int) error {
 	assert.Always(row >= 0 && row < p.nRows, "invalid row",
 		"row", row,
@@ -134,6 +149,13 @@ func (p *Package) ReadWireBuffer(buf *bytes.Buffer, row int) error {
 			continue
 		}
 
+		// write validity flag for nullable fields
+		if field.HasValidity() {
+			if err = buf.WriteByte(validFlag(b, row)); err != nil {
+				return err
+			}
+		}
+
 		// encoding is based on field type
 		var x [8]byte
 		switch b.Type() {
@@ -168,7 +190,7 @@ func (p *Package) ReadWireBuffer(buf *bytes.Buffer, row int) error {
 		case types.BlockBool:
 			v := b.Bool().Get(row)
 			err = buf.WriteByte(*(*byte)(unsafe.Pointer(&v)))
-		case types.BlockBytes:
+		case types.BlockBytes, types.BlockBigint, types.BlockList, types.BlockDocument:
 			if fixed := field.Fixed; fixed > 0 {
 				_, err = buf.Write(b.Bytes().Get(row)[:fixed])
 			} else {
@@ -231,6 +253,13 @@ func (p *Package) ReadStruct(row int, dst any, dstSchema *schema.Schema, maps []
 		if b == nil {
 			if !field.Flags.Is(types.FieldFlagEnum) {
 				sz := field.WireSize()
+				if field.HasValidity() {
+					// missing values are NULL
+					if fptr = field.SetValid(fptr, false); fptr == nil {
+						continue
+					}
+					sz--
+				}
 				buf := unsafe.Slice((*byte)(fptr), sz)
 
 				// loop copy 32 zeros (some fixed types may be larger)
@@ -243,6 +272,13 @@ func (p *Package) ReadStruct(row int, dst any, dstSchema *schema.Schema, maps []
 			continue
 		}
 
+		// nullable fields resolve the target value or set nil
+		if field.IsNullable() {
+			if fptr = field.SetValid(fptr, !b.IsNull(row)); fptr == nil {
+				continue
+			}
+		}
+
 		switch field.Type {
 		case types.FieldTypeInt64:
 			*(*int64)(fptr) = b.Int64().Get(row)
@@ -257,7 +293,16 @@ func (p *Package) ReadStruct(row int, dst any, dstSchema *schema.Schema, maps []
 			*(*int32)(fptr) = b.Int32().Get(row)
 
 		case types.FieldTypeUint32:
-			*(*uint32)(fptr) = b.Uint32().Get(row)
+			if field.IsEnum() {
+				u32 := b.Uint32().Get(row)
+				val, ok := field.Enum.Value32(u32)
+				if !ok {
+					return fmt.Errorf("%s: invalid dict value %d", field.Name, u32)
+				}
+				*(*string)(fptr) = val
+			} else {
+				*(*uint32)(fptr) = b.Uint32().Get(row)
+			}
 
 		case types.FieldTypeFloat32:
 			*(*float32)(fptr) = b.Float32().Get(row)
@@ -337,6 +382,21 @@ func (p *Package) ReadStruct(row int, dst any, dstSchema *schema.Schema, maps []
 		case types.FieldTypeBigint:
 			(*(*num.Big)(fptr)).SetBytes(b.Bytes().Get(row))
 
+		case types.FieldTypeList:
+			// copy into a typed slice
+			val := types.ListValue(b.Elem(), b.Bytes().Get(row))
+			reflect.NewAt(field.GoType(), fptr).Elem().Set(reflect.ValueOf(val))
+
+		case types.FieldTypeDocument:
+			// unmarshal JSON into the Go value
+			v := reflect.NewAt(field.GoType(), fptr)
+			v.Elem().SetZero()
+			if buf := b.Bytes().Get(row); len(buf) > 0 {
+				if err := json.Unmarshal(buf, v.Interface()); err != nil {
+					return fmt.Errorf("%s: %v", field.Name, err)
+				}
+			}
+
 		default:
 			// oh, its a type we don't support yet
 			assert.Unreachable("unhandled value type",
@@ -380,6 +440,16 @@ func ForEach[T any](pkg *Package, fn func(i int, v *T) error) error {
 	return nil
 }
 
+// IsNull returns true when the value at col, row is NULL. Missing blocks
+// (e.g. after schema change) contain NULL values for nullable fields.
+func (p *Package) IsNull(col, row int) bool {
+	b := p.blocks[col]
+	if b == nil {
+		return p.schema.Fields[col].IsNullable()
+	}
+	return b.IsNull(row)
+}
+
 func (p *Package) Uint64(col, row int) uint64 {
 	return p.blocks[col].Uint64().Get(row)
 }
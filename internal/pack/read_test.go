@@ -5,6 +5,7 @@ package pack
 
 import (
 	"bytes"
+	"database/sql"
 	"fmt"
 	"testing"
 
@@ -108,3 +109,39 @@ func BenchmarkReadWireE2E(b *testing.B) {
 		})
 	}
 }
+
+type nullTestStruct struct {
+	Id  uint64        `knox:"id,pk"`
+	Ptr *int64        `knox:"ptr"`
+	Str *string       `knox:"str"`
+	Val sql.NullInt64 `knox:"val"`
+}
+
+func TestReadStructNull(t *testing.T) {
+	s, err := schema.SchemaOf(&nullTestStruct{})
+	require.NoError(t, err)
+	maps, err := s.MapSchema(s)
+	require.NoError(t, err)
+	pkg := New().WithMaxRows(PACK_SIZE).WithSchema(s).Alloc()
+	enc := schema.NewEncoder(s)
+
+	i, str := int64(42), "knox"
+	vals := []*nullTestStruct{
+		{Id: 1},
+		{Id: 2, Ptr: &i, Str: &str, Val: sql.NullInt64{Int64: 7, Valid: true}},
+	}
+	for _, v := range vals {
+		buf, err := enc.Encode(v, nil)
+		require.NoError(t, err)
+		pkg.AppendWire(buf, nil)
+	}
+
+	require.True(t, pkg.IsNull(1, 0))
+	require.False(t, pkg.IsNull(1, 1))
+
+	for k, v := range vals {
+		var dst nullTestStruct
+		require.NoError(t, pkg.ReadStruct(k, &dst, s, maps))
+		require.Equal(t, *v, dst)
+	}
+}
@@ -58,7 +58,7 @@ func (s *PackageSorter) Less(i, j int) bool {
 			// on equal, continue with next column
 			continue
 		}
-		return o.IsForward()
+		return (cmp < 0) == o.IsForward()
 	}
 	// all equal
 	return false
@@ -0,0 +1,45 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package pack
+
+import (
+	"testing"
+
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func makeSmallPackage(t *testing.T, vals ...smallStruct) *Package {
+	t.Helper()
+	s, err := schema.SchemaOf(&smallStruct{})
+	require.NoError(t, err)
+	pkg := New().WithSchema(s).WithMaxRows(len(vals)).Alloc()
+	for _, v := range vals {
+		pkg.AppendWire(v.Encode(), nil)
+	}
+	return pkg
+}
+
+func TestPackageSorter(t *testing.T) {
+	pkg := makeSmallPackage(t,
+		smallStruct{Id: 1, Two: 30, Three: 1},
+		smallStruct{Id: 2, Two: 10, Three: 2},
+		smallStruct{Id: 3, Two: 20, Three: 2},
+		smallStruct{Id: 4, Two: 10, Three: 1},
+	)
+	defer pkg.Release()
+
+	NewPackageSorter([]int{1}, []types.OrderType{types.OrderAsc}).Sort(pkg)
+	require.Equal(t, []uint32{1, 3, 2, 0}, pkg.Selected())
+
+	pkg.WithSelection(nil)
+	NewPackageSorter([]int{1}, []types.OrderType{types.OrderDesc}).Sort(pkg)
+	require.Equal(t, []uint32{0, 2, 1, 3}, pkg.Selected())
+
+	// equal values continue with the next sort column
+	pkg.WithSelection(nil)
+	NewPackageSorter([]int{2, 1}, []types.OrderType{types.OrderDesc, types.OrderAsc}).Sort(pkg)
+	require.Equal(t, []uint32{1, 2, 3, 0}, pkg.Selected())
+}
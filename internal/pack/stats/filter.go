@@ -272,7 +272,7 @@ func EstimateCardinality(b *block.Block, precision int) (int, []uint64) {
 		flt.Add(hashes...)
 		return min(l, int(flt.Cardinality())), hashes
 
-	case block.BlockBytes:
+	case block.BlockBytes, block.BlockBigint:
 		flt := llb.NewFilterWithPrecision(uint32(precision))
 		hashes := arena.AllocUint64(l)[:l]
 		for i, v := range b.Bytes().Iterator() {
@@ -288,6 +288,13 @@ func EstimateCardinality(b *block.Block, precision int) (int, []uint64) {
 		}
 		return 2, nil
 
+	case block.BlockList:
+		// element cardinality, hashes are not row-aligned
+		flt := llb.NewFilterWithPrecision(uint32(precision))
+		hashes := listHashes(b)
+		flt.Add(hashes...)
+		return min(len(hashes), int(flt.Cardinality())), nil
+
 	default:
 		return 0, nil
 	}
@@ -311,6 +318,12 @@ func BuildBloomFilter(b *block.Block, cardinality int, factor int, hashes []uint
 	// 5        0.000082   0.008%    1 in 12,194
 	flt := bloom.NewFilter(cardinality * factor * 8)
 
+	// lists add all elements of non-null rows
+	if b.Type() == block.BlockList {
+		flt.Add(listHashes(b)...)
+		return flt
+	}
+
 	// reuse hashes from cardinality estimation if available
 	if hashes == nil {
 		// pre-alloc a hash slice
@@ -346,7 +359,7 @@ func BuildBloomFilter(b *block.Block, cardinality int, factor int, hashes []uint
 				hashes[i] = hash.Hash(v.Bytes())
 			}
 
-		case block.BlockBytes:
+		case block.BlockBytes, block.BlockBigint:
 			// write only unique elements (post-dedup optimization this avoids
 			// calculating hashes for duplicates)
 			for i, v := range b.Bytes().Iterator() {
@@ -359,13 +372,71 @@ func BuildBloomFilter(b *block.Block, cardinality int, factor int, hashes []uint
 		}
 	}
 
-	// add values to filter
-	flt.Add(hashes...)
+	// add non-null values to filter
+	flt.Add(compactNulls(b, hashes)...)
 	arena.Free(hashes)
 
 	return flt
 }
 
+// listHashes returns hashes of all elements in non-null rows of list
+// block b. Hashes match the element hashes used by list matchers.
+func listHashes(b *block.Block) []uint64 {
+	switch b.Elem() {
+	case block.BlockInt64:
+		return listHashesT[int64](b)
+	case block.BlockInt32:
+		return listHashesT[int32](b)
+	case block.BlockInt16:
+		return listHashesT[int16](b)
+	case block.BlockInt8:
+		return listHashesT[int8](b)
+	case block.BlockUint64:
+		return listHashesT[uint64](b)
+	case block.BlockUint32:
+		return listHashesT[uint32](b)
+	case block.BlockUint16:
+		return listHashesT[uint16](b)
+	case block.BlockUint8:
+		return listHashesT[uint8](b)
+	case block.BlockFloat64:
+		return listHashesT[float64](b)
+	case block.BlockFloat32:
+		return listHashesT[float32](b)
+	default:
+		return nil
+	}
+}
+
+func listHashesT[T hash.Number](b *block.Block) []uint64 {
+	var hashes []uint64
+	for i, v := range b.Bytes().Iterator() {
+		if b.IsNull(i) {
+			continue
+		}
+		for _, x := range util.FromByteSlice[T](v) {
+			hashes = append(hashes, hash.HashT(x))
+		}
+	}
+	return hashes
+}
+
+// compactNulls removes values at null positions from a row-aligned slice.
+func compactNulls(b *block.Block, vals []uint64) []uint64 {
+	if !b.HasNulls() {
+		return vals
+	}
+	var k int
+	for i, v := range vals {
+		if b.IsNull(i) {
+			continue
+		}
+		vals[k] = v
+		k++
+	}
+	return vals[:k]
+}
+
 func BuildBitsFilter(b *block.Block, cardinality int) *xroar.Bitmap {
 	if cardinality <= 1 {
 		return nil
@@ -375,23 +446,31 @@ func BuildBitsFilter(b *block.Block, cardinality int) *xroar.Bitmap {
 
 	switch b.Type() {
 	case block.BlockInt64, block.BlockUint64:
-		for _, v := range b.Uint64().Slice() {
-			flt.Set(v)
+		for i, v := range b.Uint64().Slice() {
+			if !b.IsNull(i) {
+				flt.Set(v)
+			}
 		}
 
 	case block.BlockInt32, block.BlockUint32:
-		for _, v := range b.Uint32().Slice() {
-			flt.Set(uint64(v))
+		for i, v := range b.Uint32().Slice() {
+			if !b.IsNull(i) {
+				flt.Set(uint64(v))
+			}
 		}
 
 	case block.BlockInt16, block.BlockUint16:
-		for _, v := range b.Uint16().Slice() {
-			flt.Set(uint64(v))
+		for i, v := range b.Uint16().Slice() {
+			if !b.IsNull(i) {
+				flt.Set(uint64(v))
+			}
 		}
 
 	case block.BlockInt8, block.BlockUint8:
-		for _, v := range b.Uint8().Slice() {
-			flt.Set(uint64(v))
+		for i, v := range b.Uint8().Slice() {
+			if !b.IsNull(i) {
+				flt.Set(uint64(v))
+			}
 		}
 
 	default:
@@ -438,7 +517,7 @@ func BuildFuseFilter[T uint8 | uint16](b *block.Block) (*fuse.BinaryFuse[T], err
 			u64[i] = hash.Hash(v.Bytes())
 		}
 
-	case block.BlockBytes:
+	case block.BlockBytes, block.BlockBigint:
 		// write all strings
 		u64 = arena.AllocUint64(b.Len())
 		defer arena.Free(u64)
@@ -451,7 +530,7 @@ func BuildFuseFilter[T uint8 | uint16](b *block.Block) (*fuse.BinaryFuse[T], err
 		return nil, schema.ErrInvalidValueType
 	}
 
-	// need unique values for filter construction
-	u64 = slicex.Unique(u64)
+	// need unique non-null values for filter construction
+	u64 = slicex.Unique(compactNulls(b, u64))
 	return fuse.Build[T](u64)
 }
@@ -14,6 +14,7 @@ import (
 
 	"blockwatch.cc/knoxdb/internal/arena"
 	"blockwatch.cc/knoxdb/internal/bitset"
+	"blockwatch.cc/knoxdb/internal/block"
 	"blockwatch.cc/knoxdb/internal/engine"
 	"blockwatch.cc/knoxdb/internal/operator/filter"
 	"blockwatch.cc/knoxdb/internal/pack"
@@ -548,6 +549,9 @@ func (idx *Index) UpdatePack(ctx context.Context, pkg *pack.Package) error {
 		}
 	}
 
+	// drop older pack versions from the disk cache tier
+	pack.DropFromDiskCache(idx.diskCache(ctx), pkg.Key(), pkg.Version(), false)
+
 	// rebuild bloom and range filters
 	return idx.buildFilters(pkg, node)
 }
@@ -580,10 +584,23 @@ func (idx *Index) DeletePack(ctx context.Context, pkg *pack.Package) error {
 		}
 	}
 
+	// drop all pack versions from the disk cache tier
+	pack.DropFromDiskCache(idx.diskCache(ctx), pkg.Key(), pkg.Version(), true)
+
 	// drop bloom and range filters
 	return idx.dropFilters(pkg)
 }
 
+// diskCache returns the disk cache tier partition of the data table
+// or nil when unavailable.
+func (idx *Index) diskCache(ctx context.Context) *block.DiskCachePartition {
+	e := engine.GetEngine(ctx)
+	if e == nil || idx.table == nil {
+		return nil
+	}
+	return e.DiskCache(idx.table.Schema().TaggedHash(types.ObjectTagTable))
+}
+
 // external tomb access for scheduling pack deletion
 func (idx *Index) Tomb() *Tomb {
 	return idx.tomb
@@ -633,6 +650,11 @@ func (idx *Index) GlobalMaxPk() uint64 {
 	return val.(uint64)
 }
 
+// GlobalMax returns the maximum value of data column i across all packs.
+func (idx *Index) GlobalMax(i int) (any, bool) {
+	return idx.root().Get(idx.view, maxColIndex(i))
+}
+
 func (idx *Index) IsTailFull() bool {
 	if idx.Len() == 0 {
 		return true
@@ -838,6 +860,11 @@ func (idx *Index) Query(ctx context.Context, flt *filter.Node, dir types.OrderTy
 			switch f.Mode {
 			case types.FilterModeEqual, types.FilterModeIn:
 				// bloom filters work only for these modes
+			case types.FilterModeContains, types.FilterModeAny, types.FilterModeAll:
+				// and for element filters on list columns
+				if f.Type != types.BlockList {
+					return nil
+				}
 			default:
 				return nil
 			}
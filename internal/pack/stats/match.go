@@ -198,12 +198,16 @@ func matchFilterVector(f *filter.Filter, pkg *pack.Package, bits, mask *bitset.B
 func filterType(f *filter.Filter, pkg *pack.Package, idx int) types.FilterType {
 	switch f.Mode {
 	case types.FilterModeEqual, types.FilterModeIn:
-		field := pkg.Schema().Fields[idx]
-		if field.Filter > 0 {
-			return field.Filter
+	case types.FilterModeContains, types.FilterModeAny, types.FilterModeAll:
+		// element filters on list columns
+		if f.Type != types.BlockList {
+			return types.FilterTypeNone
 		}
+	default:
+		return types.FilterTypeNone
 	}
-	return types.FilterTypeNone
+	field := pkg.Schema().Fields[idx]
+	return field.Filter
 }
 
 // matchVectorAnd aggregates match bitsets and stops eary when no more match is possible.
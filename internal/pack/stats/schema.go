@@ -39,8 +39,17 @@ func MakeSchema(s *schema.Schema) *schema.Schema {
 
 	// add min/max fields interleaved
 	for _, src := range s.Fields {
+		// lists keep element min/max, documents have empty min/max
+		typ := src.Type
+		switch typ {
+		case types.FieldTypeList:
+			typ = src.Elem
+		case types.FieldTypeDocument:
+			typ = types.FieldTypeBytes
+		}
+
 		// generate clean field from source
-		f := schema.NewField(src.Type).
+		f := schema.NewField(typ).
 			WithName("min_" + src.Name).
 			WithScale(src.Scale).
 			WithFixed(src.Fixed).
@@ -124,7 +124,23 @@ func (p *Package) DropFromCache(bcache block.BlockCachePartition) {
 	bcache.Unlock()
 }
 
-// Loads missing blocks from disk, blocks are read-only
+// DropFromDiskCache removes cached blocks of pack key from the second cache
+// tier. Blocks of version ver are kept unless all is set. Cache keys only
+// keep 16 bits of a version, so stale blocks must be removed before their
+// version number is reused. The tier groups entries by the low 32 bits of
+// cache keys, so only blocks of pack key are visited.
+func DropFromDiskCache(dcache *block.DiskCachePartition, key, ver uint32, all bool) {
+	if dcache == nil {
+		return
+	}
+	dcache.RemoveGroup(key, func(ckey uint64) bool {
+		return all || uint16(ckey>>32) != uint16(ver)
+	})
+}
+
+// Loads missing blocks from disk, blocks are read-only. With a second cache
+// tier blocks are loaded from the tier first and blocks read from storage
+// are added to the tier.
 func (p *Package) LoadFromDisk(ctx context.Context, bucket store.Bucket, fids []uint16, nRows int) (int, error) {
 	if bucket == nil {
 		return 0, store.ErrBucketNotFound
@@ -147,6 +163,15 @@ func (p *Package) LoadFromDisk(ctx context.Context, bucket store.Bucket, fids []
 			continue
 		}
 
+		// try the second cache tier before storage
+		ckey := cacheKey(p.key, p.version, f.Id)
+		if p.dcache != nil {
+			if b, ok := p.dcache.GetBlock(ckey, f.Type.BlockType()); ok {
+				p.blocks[i] = b
+				continue
+			}
+		}
+
 		// generate storage key for this block
 		bkey := EncodeBlockKey(p.key, p.version, f.Id)
 
@@ -158,8 +183,13 @@ func (p *Package) LoadFromDisk(ctx context.Context, bucket store.Bucket, fids []
 		}
 		n += len(buf)
 
+		// cache errors only cost a future storage read
+		if p.dcache != nil {
+			_ = p.dcache.AddBlock(ckey, buf, p.dicts)
+		}
+
 		// decode block from buffer page
-		b, err := block.Decode(f.Type.BlockType(), buf)
+		b, err := block.DecodeWithDicts(f.Type.BlockType(), buf, p.dicts)
 		if err != nil {
 			return n, fmt.Errorf("loading block 0x%08x:%02d: %v", p.key, f.Id, err)
 		}
@@ -204,8 +234,12 @@ func (p *Package) StoreToDisk(ctx context.Context, bucket store.Bucket) (int, er
 			continue
 		}
 
-		// encode block
-		buf, stats, err := b.Encode(f.Compress)
+		// encode block, use the latest dictionary for dict compressed fields
+		var dict *block.Dict
+		if f.Compress.Codec() == types.BlockCompressDict {
+			dict = p.dicts.Latest(f.Id)
+		}
+		buf, stats, err := b.EncodeWithDict(f.Compress, dict)
 		if err != nil {
 			return 0, err
 		}
@@ -227,6 +261,10 @@ func (p *Package) StoreToDisk(ctx context.Context, bucket store.Bucket) (int, er
 		if p.stats != nil {
 			val, _ := bucket.Get(okey)
 			minv, maxv := stats.MinMax()
+			if b.HasNulls() {
+				// encoder statistics include zero values stored for nulls
+				minv, maxv = b.MinMax()
+			}
 			p.stats.MinMax[i][0] = minv
 			p.stats.MinMax[i][1] = maxv
 			p.stats.Unique[i] = stats.Unique()
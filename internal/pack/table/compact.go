@@ -17,9 +17,8 @@ import (
 // - rewrites per pack metadata statistics
 // - copies pending/stored journal segments
 // - copies table state
+// - retrains compression dictionaries
 func (t *Table) Compact(ctx context.Context) error {
-	return nil
-
 	// TODO
 	// exclusive table lock to prevent concurrent write or background merge
 	// create new db file
@@ -31,4 +30,10 @@ func (t *Table) Compact(ctx context.Context) error {
 	// clear caches
 	// install new db file, atomic rename
 	// close old db backend, replace by new backend
+
+	// retrain compression dictionaries on compacted data
+	if t.IsReadOnly() {
+		return nil
+	}
+	return t.TrainDicts(ctx)
 }
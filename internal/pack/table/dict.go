@@ -0,0 +1,201 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package table
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"blockwatch.cc/knoxdb/internal/block"
+	"blockwatch.cc/knoxdb/internal/engine"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/num"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"blockwatch.cc/knoxdb/pkg/store"
+)
+
+// Fields with `zip=dict` compression use a zstd dictionary trained from a
+// sample of the column's stored blocks. Dictionaries live in the table's dict
+// bucket keyed by dictionary id (field id + version) and are never replaced.
+// Blocks reference the dictionary version they were written with, so older
+// versions stay readable after retraining. Until a field has a dictionary
+// its blocks use regular zstd compression.
+
+const (
+	dictSize        = 64 << 10 // target dictionary size
+	dictMaxSamples  = 1024     // max number of sampled blocks
+	dictSampleRatio = 8        // min sample bytes per dictionary byte
+)
+
+func encodeDictKey(id uint32) []byte {
+	return binary.BigEndian.AppendUint32(nil, id)
+}
+
+func (t *Table) dictBucket(tx store.Tx) store.Bucket {
+	key := append([]byte(t.schema.Name), engine.DictKeySuffix...)
+	b, _ := tx.Bucket(key)
+	return b
+}
+
+// loadDicts loads all stored dictionaries. Tables created before dictionary
+// support have no dict bucket which is created on first training.
+func (t *Table) loadDicts(tx store.Tx) error {
+	t.dicts = block.NewDictSet()
+	b := t.dictBucket(tx)
+	if b == nil {
+		return nil
+	}
+	for k, v := range b.Scan(nil) {
+		d, err := block.NewDict(bytes.Clone(v))
+		if err != nil {
+			return fmt.Errorf("dict 0x%x: %v", k, err)
+		}
+		t.dicts.Add(d)
+	}
+	return nil
+}
+
+// TrainDicts trains new dictionary versions for all fields with dictionary
+// compression or the selected fields when fids is not empty. New versions
+// apply to blocks written afterwards. Call after schema changes or
+// compaction to adapt dictionaries to current data.
+func (t *Table) TrainDicts(ctx context.Context, fids ...uint16) error {
+	if t.IsReadOnly() {
+		return engine.ErrTableReadOnly
+	}
+	t.dmu.Lock()
+	defer t.dmu.Unlock()
+	for _, f := range t.schema.Fields {
+		if !f.IsActive() || f.Compress.Codec() != types.BlockCompressDict {
+			continue
+		}
+		if len(fids) > 0 && !slices.Contains(fids, f.Id) {
+			continue
+		}
+		if err := t.trainDict(ctx, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trainMissingDicts trains first dictionary versions once enough blocks
+// have been stored. It runs after journal merges.
+func (t *Table) trainMissingDicts(ctx context.Context) {
+	t.dmu.Lock()
+	defer t.dmu.Unlock()
+	for _, f := range t.schema.Fields {
+		if !f.IsActive() || f.Compress.Codec() != types.BlockCompressDict {
+			continue
+		}
+		if t.dicts.Latest(f.Id) != nil {
+			continue
+		}
+		if err := t.trainDict(ctx, f); err != nil {
+			t.log.Warnf("train dict %s: %v", f.Name, err)
+		}
+	}
+}
+
+func (t *Table) trainDict(ctx context.Context, f *schema.Field) error {
+	// next dictionary version
+	version := 1
+	if d := t.dicts.Latest(f.Id); d != nil {
+		version = int(uint16(d.Id())) + 1
+	}
+	if version > math.MaxUint16 {
+		return fmt.Errorf("dict %s: too many versions", f.Name)
+	}
+
+	// sample stored blocks
+	samples, err := t.sampleBlocks(ctx, f.Id)
+	if err != nil {
+		return err
+	}
+	var n int
+	for _, s := range samples {
+		n += len(s)
+	}
+	if len(samples) < block.DictMinSamples || n < dictSize/dictSampleRatio {
+		t.log.Debugf("dict %s: skip training with %d blocks, %d bytes", f.Name, len(samples), n)
+		return nil
+	}
+
+	// train
+	id := block.DictId(f.Id, uint16(version))
+	d, err := block.TrainDict(id, samples, min(dictSize, n/dictSampleRatio))
+	if err != nil {
+		return fmt.Errorf("dict %s: %v", f.Name, err)
+	}
+
+	// persist before use
+	err = t.db.Update(func(tx store.Tx) error {
+		b := t.dictBucket(tx)
+		if b == nil {
+			key := append([]byte(t.schema.Name), engine.DictKeySuffix...)
+			if b, err = tx.CreateBucket(key); err != nil {
+				return err
+			}
+		}
+		return b.Put(encodeDictKey(id), d.Bytes())
+	})
+	if err != nil {
+		d.Close()
+		return fmt.Errorf("dict %s: %v", f.Name, err)
+	}
+	t.dicts.Add(d)
+	t.log.Debugf("dict %s: trained v%d size=%d from %d blocks, %d bytes",
+		f.Name, version, len(d.Bytes()), len(samples), n)
+
+	return nil
+}
+
+// sampleBlocks returns uncompressed block data for field fid evenly spread
+// across all stored blocks.
+func (t *Table) sampleBlocks(ctx context.Context, fid uint16) ([][]byte, error) {
+	var samples [][]byte
+	prefix := num.AppendUvarint(nil, uint64(fid))
+	err := t.db.View(func(tx store.Tx) error {
+		b := t.dataBucket(tx)
+		if b == nil {
+			return store.ErrBucketNotFound
+		}
+		var cnt int
+		for range b.Scan(prefix) {
+			cnt++
+		}
+		if cnt < block.DictMinSamples {
+			return nil
+		}
+		stride := max(1, cnt/dictMaxSamples)
+		var i int
+		for _, v := range b.Scan(prefix) {
+			i++
+			if (i-1)%stride != 0 {
+				continue
+			}
+			buf, err := block.Uncompress(v, t.dicts)
+			if err != nil {
+				return err
+			}
+			samples = append(samples, buf)
+			if len(samples) == dictMaxSamples {
+				break
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, store.ErrBucketNotFound) {
+		err = nil
+	}
+	return samples, err
+}
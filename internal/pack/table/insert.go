@@ -5,10 +5,13 @@ package table
 
 import (
 	"context"
+	"fmt"
 	"sync/atomic"
 
+	"blockwatch.cc/knoxdb/internal/block"
 	"blockwatch.cc/knoxdb/internal/engine"
 	"blockwatch.cc/knoxdb/internal/pack"
+	"blockwatch.cc/knoxdb/internal/types"
 	"blockwatch.cc/knoxdb/pkg/schema"
 )
 
@@ -76,6 +79,11 @@ func (t *Table) InsertRows(ctx context.Context, buf []byte) (uint64, int, error)
 	// register table for commit/abort callbacks
 	tx.Touch(t.id)
 
+	// log new dictionary values before records which use them
+	if err := t.engine.LogEnums(ctx, t.schema); err != nil {
+		return 0, 0, err
+	}
+
 	// protect journal access
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -137,22 +145,164 @@ func (t *Table) InsertInto(ctx context.Context, src *pack.Package) (uint64, int,
 	return pk, n, nil
 }
 
-func (t *Table) ImportInto(ctx context.Context, pkg *pack.Package) (uint64, int, error) {
-	// TODO bulk import from external (CSV, parquet, network sync) or table copy
-	// - src: full pkg without selection (importer or TableReader)
-	// - dst: direct write to table storage without wal
-	//
-	// Steps
-	// - ensure journal is flushed and merged
-	// - assign metadata to pack (pk, rid, xmin)
-	// - write wal (maybe not here, this should happen once per copy/import stmt)
-	// - assign pack id, store as table pack
-	//
-	// Tx handling
-	// - import/sync would block all write tx (maybe that is desired)
-	// - abort would have to clear table storage again (truncate/delete)
-	// - design wise import/copy may be limited to new tables only (created in same tx)
-	//   then a tx rollback would clean up the table file and references if import/sync
-	//   fails
-	return 0, 0, nil
+// ImportInto writes records from src directly into table storage at pack
+// granularity bypassing journal and WAL. Import flushes the journal first and
+// requires it to stay empty, then assigns row ids and the caller's tx id as
+// xmin and stores new packs and index entries in a single writer epoch.
+// Non-zero primary keys are preserved and must be strictly ascending and
+// larger than any existing key, zero keys are assigned from the table
+// sequence. Returns the primary key of the first imported record.
+//
+// Since imported packs are not logged, only the transaction which created
+// the table may import. When it aborts the table is truncated and dropped,
+// crash recovery drops tables whose creation has not committed.
+func (t *Table) ImportInto(ctx context.Context, src *pack.Package) (uint64, int, error) {
+	// ensure pack schemas match
+	if !src.Schema().Equal(t.schema) {
+		return 0, 0, schema.ErrSchemaMismatch
+	}
+
+	// check table state
+	if t.opts.ReadOnly {
+		return 0, 0, engine.ErrTableReadOnly
+	}
+	n := src.NumSelected()
+	if n == 0 {
+		return 0, 0, nil
+	}
+	atomic.AddInt64(&t.metrics.InsertCalls, 1)
+
+	// imports are only undone when the table creation is undone
+	tx := engine.GetTx(ctx)
+	if tx == nil || tx.Id() != t.xcreate {
+		return 0, 0, engine.ErrTableNotNew
+	}
+
+	// obtain exclusive table lock, blocks concurrent writers
+	if err := tx.Lock(ctx, t.id); err != nil {
+		return 0, 0, err
+	}
+
+	// remove imported data on abort, register only once
+	if !tx.Touched(t.id) {
+		tx.OnAbort(t.truncateImport)
+	}
+	tx.Touch(t.id)
+
+	// merge pending journal data so imported packs follow existing rows
+	if err := t.Flush(ctx); err != nil {
+		return 0, 0, err
+	}
+
+	// segments of open transactions cannot merge yet
+	t.mu.Lock()
+	if t.journal.NumSegments() > 1 || t.journal.Len() > 0 {
+		t.mu.Unlock()
+		return 0, 0, engine.ErrAgain
+	}
+	state := t.journal.State()
+	epoch := t.journal.Tip().Id()
+	t.mu.Unlock()
+
+	// copy source data and assign record metadata like journal inserts do
+	stage := pack.New().
+		WithSchema(t.schema).
+		WithMaxRows(n).
+		Alloc()
+	defer stage.Release()
+	src.AppendTo(stage, src.Selected())
+	stage.UpdateLen()
+	if err := t.assignMeta(stage, &state, tx.Id()); err != nil {
+		return 0, 0, err
+	}
+	firstPk := stage.Pks().Get(0)
+
+	// write packs and indexes like a journal merge, the exclusive
+	// table lock keeps writers from adding journal data meanwhile
+	w := t.NewWriter(epoch)
+	defer w.Close()
+	if err := w.Append(ctx, stage, pack.WriteModeAll); err != nil {
+		return 0, 0, err
+	}
+	state.Checkpoint = t.state.Checkpoint
+	if err := w.Finalize(ctx, state); err != nil {
+		return 0, 0, err
+	}
+
+	// continue journal after the new table epoch
+	t.mu.Lock()
+	t.journal.Reset()
+	t.journal.WithState(t.state)
+	t.mu.Unlock()
+	atomic.AddInt64(&t.metrics.InsertedTuples, int64(n))
+
+	return firstPk, n, nil
+}
+
+// truncateImport removes imported packs and index entries of an aborted
+// transaction before the table itself is dropped.
+func (t *Table) truncateImport(ctx context.Context) error {
+	if err := t.Truncate(ctx); err != nil {
+		return err
+	}
+	for _, idx := range t.Indexes() {
+		if ie, ok := idx.(engine.IndexEngine); ok {
+			if err := ie.Truncate(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	t.engine.PurgeBlockCache(t.id)
+	return nil
+}
+
+// assignMeta assigns missing primary keys and overwrites metadata of all
+// records in pkg and advances state sequences. Existing primary keys must be
+// strictly ascending and not below the next sequence value. Tables without
+// user defined pk use $rid.
+func (t *Table) assignMeta(pkg *pack.Package, state *engine.ObjectState, xid types.XID) error {
+	var (
+		n       = pkg.Len()
+		pkIsRid = pkg.PkBlock() == pkg.RowIdBlock()
+	)
+	if !pkIsRid {
+		pks := pkg.Pks()
+		next := state.NextPk
+		for i := range n {
+			pk := pks.Get(i)
+			switch {
+			case pk == 0:
+				pks.Set(i, next)
+				pk = next
+			case pk < next:
+				return fmt.Errorf("import pk %d: %w", pk, engine.ErrPkOutOfOrder)
+			}
+			next = pk + 1
+		}
+		state.NextPk = next
+		pkg.PkBlock().SetDirty()
+	}
+	for _, b := range []*block.Block{
+		pkg.RowIdBlock(),
+		pkg.RefIdBlock(),
+		pkg.XminBlock(),
+		pkg.XmaxBlock(),
+		pkg.DelBlock(),
+	} {
+		b.Clear()
+		b.SetDirty()
+	}
+	for range n {
+		if pkIsRid {
+			state.NextPk++
+		}
+		pkg.RowIdBlock().Uint64().Append(state.NextRid)
+		pkg.RefIdBlock().Uint64().Append(state.NextRid)
+		pkg.XminBlock().Uint64().Append(uint64(xid))
+		pkg.XmaxBlock().Uint64().Append(0)
+		pkg.DelBlock().Bool().Append(false)
+		state.NextRid++
+	}
+	state.NRows += uint64(n)
+	return nil
 }
@@ -158,6 +158,9 @@ func (t *Table) Merge(ctx context.Context) error {
 		t.journal.ConfirmMerged(ctx, seg)
 		t.mu.Unlock()
 
+		// train missing compression dictionaries from merged data
+		t.trainMissingDicts(ctx)
+
 		// gc wal after merge. this ensures that we don't keep a large amount
 		// of wal files at high write volume. internally TryGC() will schedule
 		// a task that rotates and checkpoints all table journals.
@@ -169,6 +172,50 @@ func (t *Table) Merge(ctx context.Context) error {
 	return err
 }
 
+// Flush checkpoints the journal and merges all completed journal segments
+// in the calling goroutine. Segments that still contain open transactions
+// are left in the journal. Flush waits for a running background merge to
+// finish first.
+func (t *Table) Flush(ctx context.Context) error {
+	if t.IsReadOnly() {
+		return engine.ErrTableReadOnly
+	}
+
+	// wait for background merge
+	if task := t.task.Load(); task != nil {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-task.Done():
+		}
+	}
+
+	// rotate the active segment and write a new checkpoint
+	t.mu.Lock()
+	err := t.journal.Checkpoint(ctx)
+	n := t.journal.NumSegments()
+	t.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// merge segments until only the active segment is left or no more
+	// progress can be made
+	for n > 1 {
+		if err := t.Merge(ctx); err != nil {
+			return err
+		}
+		t.mu.RLock()
+		m := t.journal.NumSegments()
+		t.mu.RUnlock()
+		if m >= n {
+			break
+		}
+		n = m
+	}
+	return nil
+}
+
 func (t *Table) mergeJournal(ctx context.Context, seg *journal.Segment) error {
 	// metrics
 	var (
@@ -198,12 +245,29 @@ func (t *Table) mergeJournal(ctx context.Context, seg *journal.Segment) error {
 		defer hist.Close()
 	}
 
+	// init rollup writers
+	rollups, err := t.openRollups(ctx, seg.Id())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, w := range rollups {
+			w.Close()
+		}
+	}()
+
 	// Phase 1 - move deleted rows to history, rewrite table packs
 	stones := seg.Tomb().Stones() // non-aborted deletes (within and outside the segment)
 	mask := seg.Tomb().RowIds()   // row id bitmap of all deletes, nil when empty
 	replaced := seg.Replaced()    // bitset of updated/deleted records, nil when empty
 	nStones = len(stones)
 
+	// rollups subtract deleted rows once all deletes are visible
+	var delXid types.XID
+	for _, v := range stones {
+		delXid = max(delXid, v.Xid)
+	}
+
 	if mask != nil && mask.Any() && mask.Min() < t.stats.Get().GlobalMaxRid() {
 		t.log.Tracef("merge phase 1: %d/%d tombstones", mask.Count(), len(stones))
 		src := t.NewReader().WithMask(mask, engine.ReadModeIncludeMask)
@@ -218,6 +282,13 @@ func (t *Table) mergeJournal(ctx context.Context, seg *journal.Segment) error {
 			}
 			nPacks++
 			nDel++
+
+			// subtract deleted rows from rollups
+			for _, w := range rollups {
+				if err := w.Sub(ctx, pkg, delXid); err != nil {
+					return err
+				}
+			}
 			// t.log.Debugf("merge pack 0x%08x[v%d] with %d tombs",
 			// 	pkg.Key(), pkg.Version(), len(pkg.Selected()))
 
@@ -319,6 +390,13 @@ func (t *Table) mergeJournal(ctx context.Context, seg *journal.Segment) error {
 				return err
 			}
 
+			// add active records to rollups
+			for _, w := range rollups {
+				if err := w.Add(ctx, pkg); err != nil {
+					return err
+				}
+			}
+
 			// free copy
 			arena.Free(pkg.Selected())
 			pkg.Release()
@@ -334,6 +412,11 @@ func (t *Table) mergeJournal(ctx context.Context, seg *journal.Segment) error {
 			if err := table.Append(ctx, pkg, pack.WriteModeAll); err != nil {
 				return err
 			}
+			for _, w := range rollups {
+				if err := w.Add(ctx, pkg); err != nil {
+					return err
+				}
+			}
 		}
 	}
 
@@ -345,6 +428,14 @@ func (t *Table) mergeJournal(ctx context.Context, seg *journal.Segment) error {
 		}
 	}
 
+	// store rollup partials before the table epoch, rollups record the
+	// segment epoch so that a retried merge does not count it twice
+	for _, w := range rollups {
+		if err := w.Finalize(ctx); err != nil {
+			return err
+		}
+	}
+
 	// finalize will flush remaining writer packs to disk, update table state
 	// and make new epoch visible by atomically replacing the table stats index
 	// with the new version produced during merge
@@ -36,10 +36,20 @@ func (t *Table) Query(ctx context.Context, q engine.QueryPlan) (engine.QueryResu
 		return nil, err
 	}
 
-	// prepare result
+	// protect journal access
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	atomic.AddInt64(&t.metrics.QueryCalls, 1)
+
+	// prepare result, unlimited queries start with a single pack of
+	// capacity and grow as rows are added
+	maxRows := int(plan.Limit)
+	if maxRows == 0 {
+		maxRows = t.opts.PackSize
+	}
 	res := query.NewResult(
 		pack.New().
-			WithMaxRows(int(plan.Limit)).
+			WithMaxRows(maxRows).
 			WithSchema(plan.ResultSchema).
 			Alloc(),
 	).
@@ -47,11 +57,6 @@ func (t *Table) Query(ctx context.Context, q engine.QueryPlan) (engine.QueryResu
 		WithOffset(plan.Offset).
 		WithOrder(plan.Order)
 
-	// protect journal access
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-	atomic.AddInt64(&t.metrics.QueryCalls, 1)
-
 	// execute query
 	switch plan.Order {
 	case types.OrderDesc, types.OrderDescCaseInsensitive:
@@ -81,6 +86,48 @@ func (t *Table) Stream(ctx context.Context, q engine.QueryPlan, fn func(engine.Q
 
 	// prepare result
 	res := query.NewStreamResult(fn).
+		WithSchema(plan.ResultSchema).
+		WithLimit(plan.Limit).
+		WithOffset(plan.Offset).
+		WithOrder(plan.Order)
+	defer res.Close()
+
+	// protect journal access
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	atomic.AddInt64(&t.metrics.StreamCalls, 1)
+
+	// execute query
+	switch plan.Order {
+	case types.OrderDesc, types.OrderDescCaseInsensitive:
+		err = t.doQueryDesc(ctx, plan, res)
+	default:
+		err = t.doQueryAsc(ctx, plan, res)
+	}
+	if err != nil && err != types.EndStream {
+		return err
+	}
+
+	return nil
+}
+
+// StreamPacks runs the query and calls fn once for every source pack with
+// matches. Packs use the result schema and are only valid during the call.
+func (t *Table) StreamPacks(ctx context.Context, q engine.QueryPlan, fn func(*pack.Package) error) error {
+	plan, ok := q.(*query.QueryPlan)
+	if !ok {
+		return fmt.Errorf("invalid query plan type %T", q)
+	}
+
+	// obtain shared table lock
+	err := engine.GetTx(ctx).RLock(ctx, t.id)
+	if err != nil {
+		return err
+	}
+
+	// prepare result
+	res := query.NewPackStreamResult(fn).
+		WithSchema(plan.ResultSchema).
 		WithLimit(plan.Limit).
 		WithOffset(plan.Offset).
 		WithOrder(plan.Order)
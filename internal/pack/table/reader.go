@@ -73,6 +73,7 @@ type Reader struct {
 	hits      []uint32                  // selection vector
 	bits      *bitset.Bitset            // selection bitset
 	bcache    block.BlockCachePartition // block cache reference
+	dcache    *block.DiskCachePartition // disk cache tier reference (optional)
 	mode      engine.ReadMode           // exclude or include masked row ids
 	log       log.Logger
 	useCache  bool // use cache
@@ -150,6 +151,7 @@ func (r *Reader) Reset() {
 	r.resFields = nil
 	r.mask = nil
 	r.bcache = nil
+	r.dcache = nil
 	r.mode = 0
 	r.log = r.table.log
 	r.useCache = false
@@ -186,6 +188,7 @@ func (r *Reader) Close() {
 	r.resFields = nil
 	r.mask = nil
 	r.bcache = nil
+	r.dcache = nil
 	r.useCache = false
 	r.log = nil
 	r.mode = 0
@@ -213,6 +216,7 @@ func (r *Reader) Next(ctx context.Context) (*pack.Package, error) {
 	if r.bcache == nil {
 		if r.useCache {
 			r.bcache = engine.GetEngine(ctx).BlockCache(r.table.id)
+			r.dcache = engine.GetEngine(ctx).DiskCache(r.table.id)
 		} else {
 			r.bcache = block.NoCache
 		}
@@ -472,6 +476,7 @@ func (r *Reader) Read(ctx context.Context, key uint32) (*pack.Package, error) {
 	if r.bcache == nil {
 		if r.useCache {
 			r.bcache = engine.GetEngine(ctx).BlockCache(r.table.id)
+			r.dcache = engine.GetEngine(ctx).DiskCache(r.table.id)
 		} else {
 			r.bcache = block.NoCache
 		}
@@ -490,7 +495,9 @@ func (r *Reader) loadPack(ctx context.Context, key, ver uint32, nval int, fids [
 			WithKey(key).
 			WithVersion(ver).
 			WithSchema(r.table.schema).
-			WithMaxRows(util.NonZero(nval, r.table.opts.PackSize))
+			WithMaxRows(util.NonZero(nval, r.table.opts.PackSize)).
+			WithDicts(r.table.dicts).
+			WithDiskCache(r.dcache)
 	}
 
 	// try load from cache using tableid as cache tag
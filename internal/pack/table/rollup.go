@@ -0,0 +1,200 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package table
+
+import (
+	"context"
+
+	"blockwatch.cc/knoxdb/internal/engine"
+	"blockwatch.cc/knoxdb/internal/pack"
+	"blockwatch.cc/knoxdb/internal/query"
+	"blockwatch.cc/knoxdb/internal/rollup"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/schema"
+)
+
+var _ engine.RollupBuilder = (*Table)(nil)
+
+// rollupWriter appends partial aggregates of merged rows to a rollup
+// table. Rollup tables have no journal writers, so partials are written
+// directly to table storage like imports. The rollup table epoch is the
+// epoch of the last source segment it contains.
+type rollupWriter struct {
+	def   *rollup.Rollup
+	table *Table
+	w     engine.TableWriter
+	state engine.ObjectState
+	dirty bool
+}
+
+// openRollups returns writers for all rollup tables of t which do not yet
+// contain source epoch. Rollups store partials before the source table
+// finalizes a merge, so a merge that failed in between and is retried
+// must skip them.
+func (t *Table) openRollups(ctx context.Context, epoch uint32) ([]*rollupWriter, error) {
+	if t.engine == nil || t.engine.IsRollupTable(t.schema.Name) {
+		return nil, nil
+	}
+	var res []*rollupWriter
+	for _, v := range t.engine.RollupTables(t.schema.Name) {
+		rt, ok := v.(*Table)
+		if !ok {
+			continue
+		}
+		rt.mu.RLock()
+		applied := rt.state.Epoch >= uint64(epoch)
+		rt.mu.RUnlock()
+		if applied {
+			continue
+		}
+		def, err := rollup.Parse(t.schema.Name, rt.schema)
+		if err != nil {
+			for _, w := range res {
+				w.Close()
+			}
+			return nil, err
+		}
+		res = append(res, rt.newRollupWriter(def, epoch))
+	}
+	return res, nil
+}
+
+func (t *Table) newRollupWriter(def *rollup.Rollup, epoch uint32) *rollupWriter {
+	t.mu.Lock()
+	state := t.journal.State()
+	t.mu.Unlock()
+	return &rollupWriter{
+		def:   def,
+		table: t,
+		w:     t.NewWriter(epoch),
+		state: state,
+	}
+}
+
+// Add aggregates selected rows of src into new partials.
+func (w *rollupWriter) Add(ctx context.Context, src *pack.Package) error {
+	return w.append(ctx, src, false, 0)
+}
+
+// Sub writes negative partials for selected rows of src deleted by
+// transactions up to xid.
+func (w *rollupWriter) Sub(ctx context.Context, src *pack.Package, xid types.XID) error {
+	return w.append(ctx, src, true, xid)
+}
+
+func (w *rollupWriter) append(ctx context.Context, src *pack.Package, neg bool, xid types.XID) error {
+	pkg, maxXid, err := w.def.Aggregate(src, w.table.schema, neg)
+	if err != nil || pkg == nil {
+		return err
+	}
+	defer pkg.Release()
+
+	// partials become visible together with the youngest source change
+	if err := w.table.assignMeta(pkg, &w.state, max(xid, maxXid)); err != nil {
+		return err
+	}
+	w.dirty = true
+	return w.w.Append(ctx, pkg, pack.WriteModeAll)
+}
+
+// Finalize stores written partials and updates rollup table state.
+func (w *rollupWriter) Finalize(ctx context.Context) error {
+	if !w.dirty {
+		return nil
+	}
+	t := w.table
+	w.state.Checkpoint = t.state.Checkpoint
+	if err := w.w.Finalize(ctx, w.state); err != nil {
+		return err
+	}
+
+	// continue journal after the new table epoch
+	t.mu.Lock()
+	t.journal.Reset()
+	t.journal.WithState(t.state)
+	t.mu.Unlock()
+	w.dirty = false
+	return nil
+}
+
+func (w *rollupWriter) Close() {
+	w.w.Close()
+}
+
+// BuildRollup fills the empty rollup table dst from all merged rows of t.
+// The caller must hold an exclusive lock on t. Returns ErrAgain when the
+// journal contains data of open transactions.
+func (t *Table) BuildRollup(ctx context.Context, def *rollup.Rollup, dst engine.TableEngine) error {
+	rt, ok := dst.(*Table)
+	if !ok {
+		return engine.ErrInvalidObjectType
+	}
+
+	// merge pending journal data first, rows which remain in the journal
+	// would be counted again by the next merge
+	if err := t.Flush(ctx); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	pending := t.journal.NumSegments() > 1 || t.journal.Len() > 0
+	t.mu.Unlock()
+	if pending {
+		return engine.ErrAgain
+	}
+
+	// read live table rows
+	flt, err := query.Equal("$xmax", 0).Compile(t.schema)
+	if err != nil {
+		return err
+	}
+	rs, err := t.schema.SelectIds(schema.MetaRid, schema.MetaXmin, schema.MetaXmax)
+	if err != nil {
+		return err
+	}
+	names := []string{def.Time}
+	if def.GroupBy != "" {
+		names = append(names, def.GroupBy)
+	}
+	for _, a := range def.Aggs {
+		names = append(names, a.Field)
+	}
+	fs, err := t.schema.Select(names...)
+	if err != nil {
+		return err
+	}
+	plan := query.NewQueryPlan()
+	plan.Tag = rt.schema.Name
+	plan.Table = t
+	plan.Filters = flt
+	plan.RequestSchema = rs
+	plan.ResultSchema = rs
+	plan.Snap = &types.Snapshot{Safe: true}
+	plan.Log = t.log
+	defer plan.Close()
+
+	rd := t.NewReader().
+		WithQuery(plan).
+		WithFields(append(fs.Ids(), schema.MetaXmin))
+	defer rd.Close()
+
+	// the rollup contains all segments up to the current table epoch
+	t.mu.RLock()
+	epoch := uint32(t.state.Epoch)
+	t.mu.RUnlock()
+	w := rt.newRollupWriter(def, epoch)
+	defer w.Close()
+	for {
+		pkg, err := rd.Next(ctx)
+		if err != nil {
+			return err
+		}
+		if pkg == nil {
+			break
+		}
+		if err := w.Add(ctx, pkg); err != nil {
+			return err
+		}
+	}
+	return w.Finalize(ctx)
+}
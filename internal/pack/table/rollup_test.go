@@ -0,0 +1,126 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package table
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"blockwatch.cc/knoxdb/internal/engine"
+	"blockwatch.cc/knoxdb/internal/query"
+	"blockwatch.cc/knoxdb/internal/rollup"
+	etests "blockwatch.cc/knoxdb/internal/tests/engine"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"blockwatch.cc/knoxdb/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+type rollupTestStruct struct {
+	Id    uint64    `knox:"id,pk"`
+	Time  time.Time `knox:"time,scale=s,timebase"`
+	Value int64     `knox:"value"`
+}
+
+// TestRollupRetryMerge ensures a merge which is retried after rollup
+// partials were stored does not count the segment twice.
+func TestRollupRetryMerge(t *testing.T) {
+	e := etests.NewTestEngine(t, etests.NewTestDatabaseOptions(t, "mem"))
+	defer e.Close(context.Background())
+	ctx := engine.WithEngine(context.Background(), e)
+
+	s, err := schema.SchemaOf(&rollupTestStruct{})
+	require.NoError(t, err)
+	opts := etests.NewTestTableOptions(t, "mem", "pack").TableOptions()
+	tab, err := e.CreateTable(ctx, s.WithMeta(), opts...)
+	require.NoError(t, err)
+	src := tab.(*Table)
+	def := &rollup.Rollup{
+		Table:    src.schema.Name,
+		Interval: util.TimeUnitHour,
+		Aggs:     []rollup.Aggregate{{Field: "value", Func: rollup.FuncSum}},
+	}
+	rtab, err := e.CreateRollup(ctx, def, opts...)
+	require.NoError(t, err)
+	rt := rtab.(*Table)
+
+	enc := schema.NewEncoder(src.schema)
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	insert := func(n int) {
+		t.Helper()
+		rows := make([]rollupTestStruct, n)
+		for i := range rows {
+			rows[i] = rollupTestStruct{Time: base.Add(time.Duration(i) * time.Minute), Value: 1}
+		}
+		ctx, _, commit, abort, err := e.WithTransaction(ctx)
+		require.NoError(t, err)
+		defer abort()
+		buf, err := enc.EncodeSlice(rows, nil)
+		require.NoError(t, err)
+		_, _, err = src.InsertRows(ctx, buf)
+		require.NoError(t, err)
+		require.NoError(t, commit())
+	}
+
+	// sum row counts of all stored partials
+	count := func() int64 {
+		t.Helper()
+		return sumColumn(t, ctx, rt, rollup.CountField)
+	}
+
+	// store partials of the next segment like a merge which fails before
+	// the source table epoch is written
+	insert(10)
+	epoch := src.journal.Tip().Id()
+	ws, err := src.openRollups(ctx, epoch)
+	require.NoError(t, err)
+	require.Len(t, ws, 1)
+	require.NoError(t, ws[0].Add(ctx, src.journal.Tip().Data()))
+	require.NoError(t, ws[0].Finalize(ctx))
+	ws[0].Close()
+	require.Equal(t, uint64(epoch), rt.state.Epoch, "rollup epoch")
+
+	// the retried merge skips the rollup
+	ws, err = src.openRollups(ctx, epoch)
+	require.NoError(t, err)
+	require.Empty(t, ws)
+	require.NoError(t, src.Flush(ctx))
+	require.Equal(t, int64(10), count())
+
+	// later segments are added, the cached tail pack is replaced
+	insert(5)
+	require.NoError(t, src.Flush(ctx))
+	require.Equal(t, int64(15), count())
+	require.Equal(t, int64(15), sumColumn(t, ctx, src, "value"))
+}
+
+// sumColumn sums int64 column name over all live rows of table tab.
+func sumColumn(t *testing.T, ctx context.Context, tab *Table, name string) int64 {
+	t.Helper()
+	flt, err := query.Equal("$xmax", 0).Compile(tab.schema)
+	require.NoError(t, err)
+	plan := query.NewQueryPlan()
+	plan.Table = tab
+	plan.Filters = flt
+	plan.RequestSchema = tab.schema
+	plan.ResultSchema = tab.schema
+	plan.Snap = &types.Snapshot{Safe: true}
+	defer plan.Close()
+	col, ok := tab.schema.Index(name)
+	require.True(t, ok)
+	rd := tab.NewReader().WithQuery(plan)
+	defer rd.Close()
+	var n int64
+	for {
+		pkg, err := rd.Next(ctx)
+		require.NoError(t, err)
+		if pkg == nil {
+			return n
+		}
+		for i := range pkg.Len() {
+			n += pkg.Int64(col, i)
+		}
+	}
+}
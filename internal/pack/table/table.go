@@ -12,6 +12,7 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"blockwatch.cc/knoxdb/internal/block"
 	"blockwatch.cc/knoxdb/internal/engine"
 	"blockwatch.cc/knoxdb/internal/pack/journal"
 	"blockwatch.cc/knoxdb/internal/pack/stats"
@@ -27,6 +28,7 @@ var _ engine.TableEngine = (*Table)(nil)
 func init() {
 	engine.RegisterTableFactory(engine.TableKindPack, NewTable)
 	engine.RegisterTableFactory(engine.TableKindHistory, NewTable)
+	engine.RegisterTableFactory(engine.TableKindRollup, NewTable)
 }
 
 var (
@@ -69,6 +71,9 @@ type Table struct {
 	journal *journal.Journal            // in-memory data not yet written to packs
 	metrics engine.TableMetrics         // usage statistics
 	task    atomic.Pointer[engine.Task] // merge task pointer
+	dicts   *block.DictSet              // compression dictionaries
+	dmu     sync.Mutex                  // serializes dictionary training
+	xcreate types.XID                   // creating tx, the only one allowed to import
 	log     log.Logger
 }
 
@@ -100,7 +105,7 @@ func (t *Table) PkIndex() (engine.QueryableIndex, bool) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	for _, idx := range t.indexes {
-		if idx.IsPk() {
+		if idx.IsPk() && idx.IsReady() {
 			return idx, true
 		}
 	}
@@ -141,6 +146,9 @@ func (t *Table) Create(ctx context.Context, s *schema.Schema, options ...engine.
 	t.state = engine.NewObjectState(s.Name)
 	t.metrics = engine.NewTableMetrics(s.Name)
 	t.log = t.opts.Log.Clone("table:" + s.Name)
+	if tx := engine.GetTx(ctx); tx != nil {
+		t.xcreate = tx.Id()
+	}
 
 	// write initial checkpoint
 	lsn, err := t.engine.Wal().Write(&wal.Record{
@@ -194,6 +202,7 @@ func (t *Table) createBackend(ctx context.Context) error {
 	for _, v := range [][]byte{
 		engine.DataKeySuffix,
 		engine.StateKeySuffix,
+		engine.DictKeySuffix,
 	} {
 		key := append([]byte(name), v...)
 		if _, err := tx.CreateBucket(key); err != nil {
@@ -216,6 +225,7 @@ func (t *Table) createBackend(ctx context.Context) error {
 		return err
 	}
 	t.stats = sx.AtomicPtr()
+	t.dicts = block.NewDictSet()
 
 	// init and store table state
 	if err := t.state.Store(ctx, tx); err != nil {
@@ -275,6 +285,7 @@ func (t *Table) Open(ctx context.Context, s *schema.Schema, options ...engine.Op
 			state.NRows, t.journal.NumTuples(), t.journal.NumTombstones(),
 			state.NextRid, state.NextPk)
 	} else {
+		t.engine.UpdateTxHorizon(t.maxPackXid())
 		t.log.Debugf("opened with rows=%d rid=%d pk=%d",
 			t.state.NRows, t.state.NextRid, t.state.NextPk)
 	}
@@ -321,6 +332,11 @@ func (t *Table) openBackend(ctx context.Context) error {
 			return fmt.Errorf("loading state: %v", err)
 		}
 
+		// load compression dictionaries
+		if err := t.loadDicts(tx); err != nil {
+			return fmt.Errorf("loading dicts: %v", err)
+		}
+
 		t.log.Debugf("loaded state pk=%d rid=%d nrows=%d epoch=%d lsn=0x%x",
 			t.state.NextPk, t.state.NextRid, t.state.NRows,
 			t.state.Epoch, t.state.Checkpoint)
@@ -377,6 +393,8 @@ func (t *Table) Close(ctx context.Context) (err error) {
 		t.stats.Get().Close()
 		t.stats = nil
 	}
+	t.dicts.Close()
+	t.dicts = nil
 	return
 }
 
@@ -445,8 +463,11 @@ func (t *Table) Truncate(ctx context.Context) error {
 			return err
 		}
 
-		// reset state
+		// reset state, keep the epoch so new journal segments stay
+		// above the stats index epoch and are not skipped as merged
+		epoch := t.state.Epoch
 		t.state.Reset()
+		t.state.Epoch = epoch
 		t.state.Checkpoint = lsn
 		t.journal.WithState(t.state)
 		return t.state.Store(ctx, tx)
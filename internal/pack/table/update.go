@@ -87,6 +87,11 @@ func (t *Table) UpdateRows(ctx context.Context, buf []byte) (int, error) {
 	// register table for commit/abort callbacks
 	tx.Touch(t.id)
 
+	// log new dictionary values before records which use them
+	if err := t.engine.LogEnums(ctx, t.schema); err != nil {
+		return 0, err
+	}
+
 	// build a hash map for pk -> rid (assumes u64 primary keys)
 	ridMap := make(map[uint64]uint64, len(pks))
 	for _, v := range pks {
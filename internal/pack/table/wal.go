@@ -11,6 +11,7 @@ import (
 	"blockwatch.cc/knoxdb/internal/engine"
 	"blockwatch.cc/knoxdb/internal/types"
 	"blockwatch.cc/knoxdb/internal/wal"
+	"blockwatch.cc/knoxdb/pkg/schema"
 )
 
 // WAL record formats
@@ -24,7 +25,7 @@ import (
 // Delete
 // | wal rec header | rid1 | rid2 | ... |
 func (t *Table) ReplayWal(ctx context.Context) error {
-	var xmax types.XID // highest xid seen
+	xmax := t.maxPackXid() // highest xid seen
 	start := time.Now()
 
 	t.log.Debugf("recovering journals from wal lsn 0x%x", t.state.Checkpoint)
@@ -89,3 +90,24 @@ func (t *Table) ReplayWal(ctx context.Context) error {
 
 	return nil
 }
+
+// maxPackXid returns the highest xid that created or deleted a merged
+// record. A fully merged table has no WAL records after its checkpoint
+// so the engine's tx horizon must also be restored from pack data.
+func (t *Table) maxPackXid() types.XID {
+	var xmax types.XID
+	s := t.stats.Retain()
+	defer s.Release(false)
+	for _, id := range []uint16{schema.MetaXmin, schema.MetaXmax} {
+		i, ok := t.schema.IndexId(id)
+		if !ok {
+			continue
+		}
+		if v, ok := s.GlobalMax(i); ok {
+			if x, ok := v.(uint64); ok {
+				xmax = max(xmax, types.XID(x))
+			}
+		}
+	}
+	return xmax
+}
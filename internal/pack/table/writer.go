@@ -8,8 +8,11 @@ import (
 	"sync/atomic"
 	"time"
 
+	"blockwatch.cc/knoxdb/internal/arena"
+	"blockwatch.cc/knoxdb/internal/bitset"
 	"blockwatch.cc/knoxdb/internal/block"
 	"blockwatch.cc/knoxdb/internal/engine"
+	"blockwatch.cc/knoxdb/internal/operator/filter"
 	"blockwatch.cc/knoxdb/internal/pack"
 	"blockwatch.cc/knoxdb/internal/pack/stats"
 	"blockwatch.cc/knoxdb/pkg/store"
@@ -243,8 +246,12 @@ func (w *Writer) Finalize(ctx context.Context, state engine.ObjectState) error {
 }
 
 func (w *Writer) AppendIndexes(ctx context.Context, src *pack.Package, mode engine.WriteMode) error {
-	for _, idx := range w.table.Indexes() {
-		if err := idx.(engine.IndexEngine).AddPack(ctx, src, mode); err != nil {
+	for _, v := range w.table.Indexes() {
+		idx := v.(engine.IndexEngine)
+		err := w.withPredicate(idx, src, mode, func(mode engine.WriteMode) error {
+			return idx.AddPack(ctx, src, mode)
+		})
+		if err != nil {
 			return err
 		}
 	}
@@ -252,14 +259,69 @@ func (w *Writer) AppendIndexes(ctx context.Context, src *pack.Package, mode engi
 }
 
 func (w *Writer) DeleteIndexes(ctx context.Context, src *pack.Package, mode engine.WriteMode) error {
-	for _, idx := range w.table.Indexes() {
-		if err := idx.(engine.IndexEngine).DelPack(ctx, src, mode, w.stats.Epoch()); err != nil {
+	for _, v := range w.table.Indexes() {
+		idx := v.(engine.IndexEngine)
+		err := w.withPredicate(idx, src, mode, func(mode engine.WriteMode) error {
+			return idx.DelPack(ctx, src, mode, w.stats.Epoch())
+		})
+		if err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// withPredicate restricts src rows to those matching a partial index predicate
+// and calls fn with an adjusted write mode. The original selection vector is
+// restored on return. Non-partial indexes see src unchanged.
+func (w *Writer) withPredicate(idx engine.IndexEngine, src *pack.Package, mode engine.WriteMode, fn func(engine.WriteMode) error) error {
+	pred, ok := idx.Predicate().(*filter.Node)
+	if !ok {
+		return fn(mode)
+	}
+
+	// match predicate against all rows
+	bits := filter.Match(pred, src, nil, bitset.New(src.Len()))
+	defer bits.Close()
+
+	// skip when no row matches
+	orig := src.Selected()
+	if bits.None() || (mode == pack.WriteModeIncludeSelected && len(orig) == 0) {
+		return nil
+	}
+
+	// combine with source selection
+	var sel []uint32
+	switch mode {
+	case pack.WriteModeIncludeSelected:
+		sel = arena.AllocUint32(len(orig))
+		for _, v := range orig {
+			if bits.Contains(int(v)) {
+				sel = append(sel, v)
+			}
+		}
+	case pack.WriteModeExcludeSelected:
+		for _, v := range orig {
+			bits.Unset(int(v))
+		}
+		if bits.None() {
+			return nil
+		}
+		sel = bits.Indexes(nil)
+	default:
+		sel = bits.Indexes(nil)
+	}
+	defer arena.Free(sel)
+	if len(sel) == 0 {
+		return nil
+	}
+
+	src.WithSelection(sel)
+	err := fn(pack.WriteModeIncludeSelected)
+	src.WithSelection(orig)
+	return err
+}
+
 func (w *Writer) FinalizeIndexes(ctx context.Context) error {
 	for _, v := range w.table.Indexes() {
 		idx := v.(engine.IndexEngine)
@@ -331,8 +393,8 @@ func (w *Writer) storePack(ctx context.Context, pkg *pack.Package) error {
 		return err
 	}
 
-	// init statistics
-	pkg.WithStats()
+	// init statistics and compression dictionaries
+	pkg.WithStats().WithDicts(w.table.dicts)
 
 	// w.log.Debugf("storing pack %08x[v%d]", pkg.Key(), pkg.Version())
 
@@ -383,7 +445,8 @@ func (w *Writer) loadTail(ctx context.Context) (*pack.Package, error) {
 		WithKey(key).
 		WithVersion(ver).
 		WithSchema(w.table.schema).
-		WithMaxRows(w.table.opts.PackSize)
+		WithMaxRows(w.table.opts.PackSize).
+		WithDicts(w.table.dicts)
 
 	// try load from cache using tableid as cache tag
 	// count number of expected blocks
@@ -394,8 +457,10 @@ func (w *Writer) loadTail(ctx context.Context) (*pack.Package, error) {
 		w.bcache = engine.GetEngine(ctx).BlockCache(w.table.id)
 	}
 
-	// stop early when all requested blocks are found
+	// stop early when all requested blocks are found, the stored
+	// version must still be replaced
 	if pkg.LoadFromCache(w.bcache, nil) == nBlocks {
+		w.vtail = ver
 		return pkg, nil
 	}
 
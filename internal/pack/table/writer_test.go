@@ -0,0 +1,220 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package table
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+
+	"blockwatch.cc/knoxdb/internal/engine"
+	"blockwatch.cc/knoxdb/internal/operator/filter"
+	"blockwatch.cc/knoxdb/internal/pack"
+	"blockwatch.cc/knoxdb/internal/query"
+	etests "blockwatch.cc/knoxdb/internal/tests/engine"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"github.com/stretchr/testify/require"
+
+	_ "blockwatch.cc/knoxdb/internal/pack/index"
+)
+
+type partialTestStruct struct {
+	Id     uint64 `knox:"id,pk"`
+	Active bool   `knox:"active"`
+	Score  int64  `knox:"score"`
+}
+
+// recordIndex is a partial index which records rows passed by table merge.
+type recordIndex struct {
+	engine.IndexEngine // unused methods
+	t                  *testing.T
+	s                  *schema.IndexSchema
+	pred               *filter.Node
+	rows               map[uint64]int64 // rid -> score
+}
+
+func (idx *recordIndex) IndexSchema() *schema.IndexSchema           { return idx.s }
+func (idx *recordIndex) Predicate() engine.QueryCondition           { return idx.pred }
+func (idx *recordIndex) IsPk() bool                                 { return false }
+func (idx *recordIndex) IsReady() bool                              { return false }
+func (idx *recordIndex) Finalize(_ context.Context, _ uint32) error { return nil }
+
+func (idx *recordIndex) Schema() *schema.Schema {
+	s, _ := idx.s.StorageSchema()
+	return s
+}
+
+func (idx *recordIndex) AddPack(_ context.Context, pkg *pack.Package, mode pack.WriteMode) error {
+	for _, i := range idx.selected(pkg, mode) {
+		rid := pkg.RowIds().Get(i)
+		require.NotContains(idx.t, idx.rows, rid, "duplicate add")
+		idx.rows[rid] = idx.score(pkg, i)
+	}
+	return nil
+}
+
+func (idx *recordIndex) DelPack(_ context.Context, pkg *pack.Package, mode pack.WriteMode, _ uint32) error {
+	for _, i := range idx.selected(pkg, mode) {
+		rid := pkg.RowIds().Get(i)
+		require.Contains(idx.t, idx.rows, rid, "delete of unindexed row")
+		delete(idx.rows, rid)
+	}
+	return nil
+}
+
+func (idx *recordIndex) selected(pkg *pack.Package, mode pack.WriteMode) []int {
+	var res []int
+	switch mode {
+	case pack.WriteModeIncludeSelected:
+		for _, v := range pkg.Selected() {
+			res = append(res, int(v))
+		}
+	case pack.WriteModeExcludeSelected:
+		for _, v := range types.NegateSelection(pkg.Selected(), pkg.Len()) {
+			res = append(res, int(v))
+		}
+	default:
+		for i := range pkg.Len() {
+			res = append(res, i)
+		}
+	}
+	for _, i := range res {
+		require.True(idx.t, pkg.Block(idx.fieldIndex(pkg, "active")).Bool().Get(i), "row outside predicate")
+	}
+	return res
+}
+
+func (idx *recordIndex) score(pkg *pack.Package, i int) int64 {
+	return pkg.Block(idx.fieldIndex(pkg, "score")).Int64().Get(i)
+}
+
+func (idx *recordIndex) fieldIndex(pkg *pack.Package, name string) int {
+	f, ok := pkg.Schema().Find(name)
+	require.True(idx.t, ok, name)
+	x, ok := pkg.Schema().IndexId(f.Id)
+	require.True(idx.t, ok, name)
+	return x
+}
+
+// TestWriterPartialIndex ensures table merge only passes rows matching
+// a partial index predicate to the index, also when updates move rows
+// into or out of the predicate.
+func TestWriterPartialIndex(t *testing.T) {
+	e := etests.NewTestEngine(t, etests.NewTestDatabaseOptions(t, "mem"))
+	defer e.Close(context.Background())
+	ctx := engine.WithEngine(context.Background(), e)
+
+	s, err := schema.SchemaOf(&partialTestStruct{})
+	require.NoError(t, err)
+	s = s.WithMeta()
+	tab, err := e.CreateTable(ctx, s, etests.NewTestTableOptions(t, "mem", "pack").TableOptions()...)
+	require.NoError(t, err)
+	for _, is := range s.Indexes {
+		_, err = e.CreateIndex(ctx, is, etests.NewTestIndexOptions(t, "mem", "pack").IndexOptions()...)
+		require.NoError(t, err)
+	}
+	ts := tab.Schema()
+
+	// connect a partial index on active rows
+	cond := query.Equal("active", true)
+	pred, err := cond.Compile(ts)
+	require.NoError(t, err)
+	ss, err := ts.Select("score")
+	require.NoError(t, err)
+	idx := &recordIndex{
+		t: t,
+		s: (&schema.IndexSchema{
+			Name:   "active_score",
+			Type:   types.IndexTypeInt,
+			Base:   ts,
+			Fields: ss.Fields,
+		}).WithPredicate(cond),
+		pred: pred,
+		rows: make(map[uint64]int64),
+	}
+	tab.ConnectIndex(idx)
+	defer tab.DisconnectIndex(idx)
+
+	enc := schema.NewEncoder(ts)
+	rows := make(map[uint64]partialTestStruct)
+	check := func() {
+		t.Helper()
+		require.NoError(t, tab.Flush(ctx))
+		var exp []int64
+		for _, r := range rows {
+			if r.Active {
+				exp = append(exp, r.Score)
+			}
+		}
+		slices.Sort(exp)
+		require.Equal(t, exp, slices.Sorted(maps.Values(idx.rows)))
+	}
+	withTx := func(fn func(ctx context.Context)) {
+		t.Helper()
+		ctx, _, commit, abort, err := e.WithTransaction(ctx)
+		require.NoError(t, err)
+		defer abort()
+		fn(ctx)
+		require.NoError(t, commit())
+	}
+
+	// insert
+	ins := make([]partialTestStruct, 20)
+	for i := range ins {
+		ins[i] = partialTestStruct{Active: i%2 == 0, Score: int64(i)}
+	}
+	withTx(func(ctx context.Context) {
+		buf, err := enc.EncodeSlice(ins, nil)
+		require.NoError(t, err)
+		_, n, err := tab.InsertRows(ctx, buf)
+		require.NoError(t, err)
+		require.Equal(t, len(ins), n)
+	})
+	for i, r := range ins {
+		r.Id = uint64(i + 1)
+		rows[r.Id] = r
+	}
+	check()
+
+	// update rows out of, into, within and outside the predicate
+	upd := []partialTestStruct{
+		{Id: 1, Active: false, Score: 100},
+		{Id: 2, Active: true, Score: 101},
+		{Id: 3, Active: true, Score: 102},
+		{Id: 4, Active: false, Score: 103},
+	}
+	withTx(func(ctx context.Context) {
+		buf, err := enc.EncodeSlice(upd, nil)
+		require.NoError(t, err)
+		n, err := tab.UpdateRows(ctx, buf)
+		require.NoError(t, err)
+		require.Equal(t, len(upd), n)
+	})
+	for _, r := range upd {
+		rows[r.Id] = r
+	}
+	check()
+
+	// delete rows inside and outside the predicate and a row moved in
+	withTx(func(ctx context.Context) {
+		flt, err := query.In("id", []uint64{2, 5, 6}).Compile(ts)
+		require.NoError(t, err)
+		plan := query.NewQueryPlan().
+			WithFilters(flt).
+			WithSchema(ts).
+			WithTable(tab)
+		defer plan.Close()
+		require.NoError(t, plan.Validate())
+		require.NoError(t, plan.Compile(ctx))
+		n, err := tab.Delete(ctx, plan)
+		require.NoError(t, err)
+		require.Equal(t, 3, n)
+	})
+	delete(rows, 2)
+	delete(rows, 5)
+	delete(rows, 6)
+	check()
+}
@@ -68,6 +68,13 @@ func (p *Package) AppendWire(buf []byte, meta *schema.Meta) {
 			continue
 		}
 
+		// read validity flag, null values are stored as zero values
+		var isNull bool
+		if field.HasValidity() {
+			isNull = buf[0] == 0
+			buf = buf[1:]
+		}
+
 		switch b.Type() {
 		case types.BlockUint64, types.BlockInt64, types.BlockFloat64:
 			b.Uint64().Append(*(*uint64)(unsafe.Pointer(&buf[0])))
@@ -89,7 +96,7 @@ func (p *Package) AppendWire(buf []byte, meta *schema.Meta) {
 			b.Bool().Append(*(*bool)(unsafe.Pointer(&buf[0])))
 			buf = buf[1:]
 
-		case types.BlockBytes:
+		case types.BlockBytes, types.BlockBigint, types.BlockList, types.BlockDocument:
 			if fixed := field.Fixed; fixed > 0 {
 				b.Bytes().Append(buf[:fixed])
 				buf = buf[fixed:]
@@ -118,6 +125,9 @@ func (p *Package) AppendWire(buf []byte, meta *schema.Meta) {
 				"version", p.schema.Version,
 			)
 		}
+		if isNull {
+			b.SetNull(b.Len() - 1)
+		}
 		b.SetDirty()
 	}
 	p.nRows++
@@ -169,6 +179,26 @@ func (p *Package) SetValue(col, row int, val any) error {
 		return nil
 	}
 
+	// nil sets NULL on nullable fields
+	if p.schema.Fields[col].IsNullable() {
+		if val == nil {
+			b.SetNull(row)
+			return nil
+		}
+		b.SetValid(row)
+	}
+
+	// documents accept any Go value that marshals to JSON
+	if p.schema.Fields[col].Type == types.FieldTypeDocument {
+		buf, err := types.DocumentBytes(val)
+		if err != nil {
+			return err
+		}
+		b.Bytes().Set(row, buf)
+		b.SetDirty()
+		return nil
+	}
+
 	// try direct types first
 	switch v := val.(type) {
 	case int64:
@@ -226,6 +256,9 @@ func (p *Package) SetValue(col, row int, val any) error {
 		b.Int32().Set(row, v.Quantize(p.schema.Fields[col].Scale).Int32())
 	case num.Big:
 		b.Bytes().Set(row, v.Bytes())
+	case []int64, []int32, []int16, []int8, []uint64, []uint32, []uint16, []float64, []float32:
+		buf, _ := types.ListBytes(v)
+		b.Bytes().Set(row, buf)
 	default:
 		// fallback to reflect for enum types
 		rval := reflect.Indirect(reflect.ValueOf(val))
@@ -285,6 +318,17 @@ func (p *Package) SetWire(row int, buf []byte) {
 		if b == nil {
 			continue
 		}
+
+		// read validity flag, null values are stored as zero values
+		if field.HasValidity() {
+			if buf[0] == 0 {
+				b.SetNull(row)
+			} else {
+				b.SetValid(row)
+			}
+			buf = buf[1:]
+		}
+
 		switch b.Type() {
 		case types.BlockUint64, types.BlockInt64, types.BlockFloat64:
 			b.Uint64().Set(row, *(*uint64)(unsafe.Pointer(&buf[0])))
@@ -310,7 +354,7 @@ func (p *Package) SetWire(row int, buf []byte) {
 			}
 			buf = buf[1:]
 
-		case types.BlockBytes:
+		case types.BlockBytes, types.BlockBigint, types.BlockList, types.BlockDocument:
 			if fixed := field.Fixed; fixed > 0 {
 				b.Bytes().Set(row, buf[:fixed])
 				buf = buf[fixed:]
@@ -420,6 +464,50 @@ func (p *Package) AppendTo(dst *Package, sel []uint32) int {
 	return n
 }
 
+// View links blocks of package p into dst by field id without copying
+// data. dst must be created with a schema that selects a subset of p's
+// fields. Linked blocks are referenced and released with dst.
+func (p *Package) View(dst *Package) *Package {
+	for k, f := range dst.schema.Fields {
+		if dst.blocks[k] != nil {
+			dst.blocks[k].Deref()
+			dst.blocks[k] = nil
+		}
+		i, ok := p.schema.IndexId(f.Id)
+		if !ok || p.blocks[i] == nil {
+			continue
+		}
+		p.blocks[i].Ref()
+		dst.blocks[k] = p.blocks[i]
+	}
+	dst.nRows = p.nRows
+	dst.selected = p.selected
+	return dst
+}
+
+// AppendFieldsTo appends selected entries in package p to dst like
+// AppendTo but matches blocks by field id. Use this when dst contains
+// a subset of p's fields in a different order.
+func (p *Package) AppendFieldsTo(dst *Package, sel []uint32) int {
+	n := min(p.nRows, dst.maxRows-dst.nRows)
+	if sel != nil {
+		n = min(len(sel), n)
+		sel = sel[:n]
+	}
+	for k, f := range dst.schema.Fields {
+		if dst.blocks[k] == nil {
+			continue
+		}
+		i, ok := p.schema.IndexId(f.Id)
+		if !ok || p.blocks[i] == nil {
+			continue
+		}
+		p.blocks[i].AppendTo(dst.blocks[k], sel)
+	}
+	dst.nRows += n
+	return n
+}
+
 func (p *Package) Delete(i, j int) error {
 	if i < 0 || j < 0 || j < i || p.nRows < j {
 		return fmt.Errorf("delete: invalid range [%d:%d] (nrows=%d)", i, j, p.nRows)
@@ -6,6 +6,8 @@ package pack
 import (
 	"fmt"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 func BenchmarkAppendWire(b *testing.B) {
@@ -43,3 +45,39 @@ func BenchmarkAppendWireE2E(b *testing.B) {
 		})
 	}
 }
+
+func TestPackageView(t *testing.T) {
+	src := makeSmallPackage(t,
+		smallStruct{Id: 1, Two: 10},
+		smallStruct{Id: 2, Two: 20},
+	)
+	defer src.Release()
+	s, err := src.Schema().Select("two", "id")
+	require.NoError(t, err)
+	view := src.View(New().WithSchema(s))
+	defer view.Release()
+	require.Equal(t, 2, view.Len())
+	require.Equal(t, int64(20), view.Int64(0, 1))
+	require.Equal(t, uint64(2), view.Uint64(1, 1))
+
+	// blocks are shared with the source
+	require.Same(t, src.Block(0), view.Block(1))
+}
+
+func TestPackageAppendFieldsTo(t *testing.T) {
+	src := makeSmallPackage(t,
+		smallStruct{Id: 1, Two: 10},
+		smallStruct{Id: 2, Two: 20},
+		smallStruct{Id: 3, Two: 30},
+	)
+	defer src.Release()
+	s, err := src.Schema().Select("two", "id")
+	require.NoError(t, err)
+	dst := New().WithSchema(s).WithMaxRows(5).Alloc()
+	defer dst.Release()
+	require.Equal(t, 2, src.AppendFieldsTo(dst, []uint32{2, 0}))
+	require.Equal(t, 3, src.AppendFieldsTo(dst, nil))
+	require.Equal(t, 5, dst.Len())
+	require.Equal(t, []int64{30, 10, 10, 20, 30}, dst.Block(0).Int64().Slice())
+	require.Equal(t, []uint64{3, 1, 1, 2, 3}, dst.Block(1).Uint64().Slice())
+}
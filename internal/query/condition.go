@@ -5,10 +5,13 @@ package query
 
 import (
 	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
 
 	"blockwatch.cc/knoxdb/internal/operator/filter"
 	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/num"
 	"blockwatch.cc/knoxdb/pkg/schema"
 	"blockwatch.cc/knoxdb/pkg/slicex"
 )
@@ -23,8 +26,9 @@ type RangeValue = filter.RangeValue
 // Condition represents a tree of user-defined query filters
 type Condition struct {
 	Name     string           // schema field name
-	Mode     types.FilterMode // eq|ne|gt|ge|lt|le|in|ni|rg|re
+	Mode     types.FilterMode // eq|ne|gt|ge|lt|le|in|ni|rg|re|pf
 	Value    any              // typed value ([2]any for range)
+	Expr     schema.IndexExpr // optional key expression on field
 	OrKind   bool             // true to represent all children are ORed
 	Children []Condition      // child conditions
 }
@@ -59,6 +63,13 @@ func (c Condition) Fields() []string {
 	return slicex.UniqueStrings(names)
 }
 
+// WithExpr makes a leaf condition match key expression e on its field
+// instead of raw field values, e.g. lower(name) = "alice".
+func (c Condition) WithExpr(e schema.IndexExpr) Condition {
+	c.Expr = e
+	return c
+}
+
 func (c Condition) Rename(name string) Condition {
 	if name != "" {
 		c.Name = name
@@ -76,6 +87,9 @@ func ParseCondition(key, val string, s *schema.Schema) (c Condition, err error)
 		err = fmt.Errorf("unknown column %q", name)
 		return
 	}
+	if field.Type == types.FieldTypeDocument {
+		return parseDocCondition(field.Name, mode, val)
+	}
 	c.Mode = types.ParseFilterMode(mode)
 	if !c.Mode.IsValid() {
 		err = fmt.Errorf("invalid filter mode '%s'", mode)
@@ -87,6 +101,16 @@ func ParseCondition(key, val string, s *schema.Schema) (c Condition, err error)
 		enum, _ = s.Enums.Load().Lookup(c.Name)
 	}
 	parser := schema.NewParser(field.Type, field.Scale, enum)
+	parser2 := parser
+	switch {
+	case field.Type == types.FieldTypeList:
+		// list conditions use element values
+		parser = schema.NewParser(field.Elem, 0, nil)
+	case isDecimal(field.Type):
+		r1, r2 := decimalRounding(c.Mode)
+		parser = schema.NewDecimalParser(field.Type, field.Scale, r1)
+		parser2 = schema.NewDecimalParser(field.Type, field.Scale, r2)
+	}
 	switch c.Mode {
 	case types.FilterModeRange:
 		v1, v2, ok := strings.Cut(val, ",")
@@ -94,15 +118,20 @@ func ParseCondition(key, val string, s *schema.Schema) (c Condition, err error)
 			var res filter.RangeValue
 			res[0], err = parser.ParseValue(v1)
 			if err == nil {
-				res[1], err = parser.ParseValue(v2)
+				res[1], err = parser2.ParseValue(v2)
 			}
 			c.Value = res
 		} else {
 			err = fmt.Errorf("range conditions require exactly two arguments")
 			return
 		}
-	case types.FilterModeIn, types.FilterModeNotIn:
+	case types.FilterModeIn, types.FilterModeNotIn, types.FilterModeAny, types.FilterModeAll:
 		c.Value, err = parser.ParseSlice(val)
+	case types.FilterModeIsNull, types.FilterModeNotNull:
+		// null conditions have no value
+	case types.FilterModePrefix:
+		// prefixes are resolved against the field type during compile
+		c.Value = val
 	default:
 		c.Value, err = parser.ParseValue(val)
 	}
@@ -112,6 +141,59 @@ func ParseCondition(key, val string, s *schema.Schema) (c Condition, err error)
 	return
 }
 
+// parseDocCondition parses conditions on document column name. Keys address
+// document paths between column name and filter mode, e.g. `params.amount.gt`.
+// Values are parsed as integer, float, boolean or string in this order.
+func parseDocCondition(name, key, val string) (c Condition, err error) {
+	path, mode := key, ""
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		if types.ParseFilterMode(key[i+1:]).IsValid() {
+			path, mode = key[:i], key[i+1:]
+		}
+	} else if types.ParseFilterMode(key).IsValid() {
+		path, mode = "", key
+	}
+	c.Mode = types.ParseFilterMode(mode)
+	c.Name = name
+	if path != "" {
+		c.Name += "." + path
+	}
+	switch c.Mode {
+	case types.FilterModeRange:
+		v1, v2, ok := strings.Cut(val, ",")
+		if !ok {
+			err = fmt.Errorf("range conditions require exactly two arguments")
+			return
+		}
+		c.Value = filter.RangeValue{parseDocValue(v1), parseDocValue(v2)}
+	case types.FilterModeIn, types.FilterModeNotIn:
+		vv := strings.Split(val, ",")
+		slice := make([]any, len(vv))
+		for i, v := range vv {
+			slice[i] = parseDocValue(v)
+		}
+		c.Value = slice
+	case types.FilterModeIsNull, types.FilterModeNotNull:
+		// null conditions have no value
+	default:
+		c.Value = parseDocValue(val)
+	}
+	return
+}
+
+func parseDocValue(s string) any {
+	if i64, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i64
+	}
+	if f64, err := strconv.ParseFloat(s, 64); err == nil {
+		return f64
+	}
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b
+	}
+	return s
+}
+
 func (c Condition) Validate() error {
 	return c.validate(true)
 }
@@ -132,7 +214,7 @@ func (c Condition) validate(isRoot bool) error {
 		if !c.Mode.IsValid() {
 			return fmt.Errorf("invalid filter mode")
 		}
-		if c.Value == nil {
+		if c.Value == nil && !c.Mode.IsNullMode() {
 			return fmt.Errorf("nil filter value")
 		}
 	} else {
@@ -173,12 +255,74 @@ func (c Condition) Compile(s *schema.Schema) (*filter.Node, error) {
 		// lookup field and fill missing values
 		fx, ok := s.Index(c.Name)
 		if !ok {
+			// try document path
+			name, path, _ := strings.Cut(c.Name, ".")
+			if fx, ok = s.Index(name); ok && path != "" && s.Fields[fx].Type == types.FieldTypeDocument {
+				return c.compileDoc(s.Fields[fx], fx, path)
+			}
 			return nil, fmt.Errorf("unknown column %q", c.Name)
 		}
 		field := s.Fields[fx]
+		if err := c.Expr.Validate(field); err != nil {
+			return nil, err
+		}
+		if field.Type == types.FieldTypeDocument && !c.Mode.IsNullMode() {
+			return nil, fmt.Errorf("%s filter unsupported on field %s type %s, use a document path",
+				c.Mode.Symbol(), field.Name, field.Type)
+		}
+		if field.IsDict() {
+			// dictionary codes follow insert order, so only equality
+			// conditions translate into code comparisons
+			switch c.Mode {
+			case types.FilterModeEqual, types.FilterModeNotEqual,
+				types.FilterModeIn, types.FilterModeNotIn,
+				types.FilterModeIsNull, types.FilterModeNotNull:
+			default:
+				return nil, fmt.Errorf("%s filter unsupported on dictionary field %s",
+					c.Mode.Symbol(), field.Name)
+			}
+		}
+		if c.Mode == types.FilterModePrefix {
+			// prefix filters on fixed size bytes translate into value ranges
+			// which keeps pack statistics and range matchers usable
+			from, to, err := field.PrefixRange(c.Value)
+			if err != nil {
+				return nil, err
+			}
+			c.Mode, c.Value = types.FilterModeRange, filter.RangeValue{from, to}
+		}
+		isList := field.Type == types.FieldTypeList
+		switch c.Mode {
+		case types.FilterModeContains:
+			if field.Type != types.FieldTypeString && field.Type != types.FieldTypeBytes && !isList {
+				return nil, fmt.Errorf("%s filter unsupported on field %s type %s",
+					c.Mode.Symbol(), field.Name, field.Type)
+			}
+		case types.FilterModeMatch:
+			if field.Type != types.FieldTypeString && field.Type != types.FieldTypeBytes {
+				return nil, fmt.Errorf("%s filter unsupported on field %s type %s",
+					c.Mode.Symbol(), field.Name, field.Type)
+			}
+		case types.FilterModeAny, types.FilterModeAll:
+			if !isList {
+				return nil, fmt.Errorf("%s filter unsupported on field %s type %s",
+					c.Mode.Symbol(), field.Name, field.Type)
+			}
+		case types.FilterModeIsNull, types.FilterModeNotNull:
+		default:
+			if isList {
+				return nil, fmt.Errorf("%s filter unsupported on field %s type %s",
+					c.Mode.Symbol(), field.Name, field.Type)
+			}
+		}
 
 		// Use matcher factory to generate matcher impl for type and mode
-		matcher := filter.NewFactory(field.Type).New(c.Mode)
+		var matcher filter.Matcher
+		if isList {
+			matcher = filter.NewListFactory(field.Elem).New(c.Mode)
+		} else {
+			matcher = filter.NewFactory(field.Type).New(c.Mode)
+		}
 
 		// Cast types of condition values since we allow external use.
 		// The wire format code path is safe because data encoding follows
@@ -187,7 +331,23 @@ func (c Condition) Compile(s *schema.Schema) (*filter.Node, error) {
 		if s.HasEnums() {
 			enum, _ = s.Enums.Load().Lookup(c.Name)
 		}
-		caster := schema.NewCaster(field.Type, field.Scale, enum)
+		var caster, caster2 schema.ValueCaster
+		switch {
+		case isList:
+			// list conditions use element values
+			caster = schema.NewCaster(field.Elem, 0, nil)
+		case field.Type == types.FieldTypeDocument:
+			// only null conditions without value
+		case isDecimal(field.Type):
+			r1, r2 := decimalRounding(c.Mode)
+			caster = schema.NewDecimalCaster(field.Type, field.Scale, r1)
+			caster2 = schema.NewDecimalCaster(field.Type, field.Scale, r2)
+		default:
+			caster = schema.NewCaster(field.Type, field.Scale, enum)
+		}
+		if caster2 == nil {
+			caster2 = caster
+		}
 
 		// init matcher impl from value(s)
 		var (
@@ -195,7 +355,7 @@ func (c Condition) Compile(s *schema.Schema) (*filter.Node, error) {
 			err  error
 		)
 		switch c.Mode {
-		case types.FilterModeIn, types.FilterModeNotIn:
+		case types.FilterModeIn, types.FilterModeNotIn, types.FilterModeAny, types.FilterModeAll:
 			// ensure slice type matches blocks
 			var slice any
 			slice, err = caster.CastSlice(c.Value)
@@ -208,12 +368,15 @@ func (c Condition) Compile(s *schema.Schema) (*filter.Node, error) {
 			var from, to any
 			from, err = caster.CastValue(c.Value.(filter.RangeValue)[0])
 			if err == nil {
-				to, err = caster.CastValue(c.Value.(filter.RangeValue)[1])
+				to, err = caster2.CastValue(c.Value.(filter.RangeValue)[1])
 				if err == nil {
 					c.Value = filter.RangeValue{from, to}
 					matcher.WithValue(c.Value)
 				}
 			}
+		case types.FilterModeIsNull, types.FilterModeNotNull:
+			// null conditions have no value
+			c.Value = nil
 		default:
 			// ensure value type matches blocks
 			var val any
@@ -230,7 +393,7 @@ func (c Condition) Compile(s *schema.Schema) (*filter.Node, error) {
 		// use the subtree node from rewrite above or create a new child
 		// node from matcher
 		if node == nil {
-			node = filter.NewNode().AddLeaf(&filter.Filter{
+			node = filter.NewNode().AddLeaf((&filter.Filter{
 				Name:    c.Name,
 				Type:    field.Type.BlockType(),
 				Mode:    c.Mode,
@@ -238,7 +401,7 @@ func (c Condition) Compile(s *schema.Schema) (*filter.Node, error) {
 				Id:      field.Id,
 				Value:   c.Value,
 				Matcher: matcher,
-			})
+			}).WithExpr(c.Expr, field))
 		}
 
 		return node, nil
@@ -256,6 +419,109 @@ func (c Condition) Compile(s *schema.Schema) (*filter.Node, error) {
 	return node, nil
 }
 
+func isDecimal(typ types.FieldType) bool {
+	switch typ {
+	case types.FieldTypeDecimal32, types.FieldTypeDecimal64,
+		types.FieldTypeDecimal128, types.FieldTypeDecimal256:
+		return true
+	default:
+		return false
+	}
+}
+
+// decimalRounding returns rounding modes for the first and second value of
+// decimal conditions. Literals with more fractional digits than the column
+// scale round in the direction that keeps comparison results exact, e.g.
+// x > 1.005 equals x > 1.00 and x >= 1.005 equals x >= 1.01 at scale 2.
+// Equality and set conditions fail on inexact literals.
+func decimalRounding(mode types.FilterMode) (num.RoundingMode, num.RoundingMode) {
+	switch mode {
+	case types.FilterModeGt, types.FilterModeLe:
+		return num.RoundFloor, num.RoundFloor
+	case types.FilterModeGe, types.FilterModeLt:
+		return num.RoundCeil, num.RoundCeil
+	case types.FilterModeRange:
+		return num.RoundCeil, num.RoundFloor
+	default:
+		return num.RoundExact, num.RoundExact
+	}
+}
+
+// compileDoc translates a condition on document path into a filter on
+// document column field. Values are normalized to document scalars.
+func (c Condition) compileDoc(field *schema.Field, fx int, path string) (*filter.Node, error) {
+	if c.Expr.IsValid() {
+		return nil, fmt.Errorf("expression filter unsupported on document path %q", c.Name)
+	}
+	var err error
+	switch c.Mode {
+	case types.FilterModeEqual, types.FilterModeNotEqual,
+		types.FilterModeGt, types.FilterModeGe, types.FilterModeLt, types.FilterModeLe:
+		c.Value, err = types.DocumentScalar(c.Value)
+	case types.FilterModeRange:
+		rg := c.Value.(filter.RangeValue)
+		rg[0], err = types.DocumentScalar(rg[0])
+		if err == nil {
+			rg[1], err = types.DocumentScalar(rg[1])
+		}
+		c.Value = rg
+	case types.FilterModeIn, types.FilterModeNotIn:
+		vals := reflect.ValueOf(c.Value)
+		if vals.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("%s filter on document path %q requires a slice value",
+				c.Mode.Symbol(), c.Name)
+		}
+		slice := make([]any, vals.Len())
+		for i := range slice {
+			if slice[i], err = types.DocumentScalar(vals.Index(i).Interface()); err != nil {
+				break
+			}
+		}
+		c.Value = slice
+	case types.FilterModeIsNull, types.FilterModeNotNull:
+		c.Value = nil
+	default:
+		return nil, fmt.Errorf("%s filter unsupported on document path %q",
+			c.Mode.Symbol(), c.Name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("document path %q: %v", c.Name, err)
+	}
+
+	matcher := filter.NewDocFactory(path).New(c.Mode)
+	switch c.Mode {
+	case types.FilterModeIn, types.FilterModeNotIn:
+		matcher.WithSlice(c.Value)
+	case types.FilterModeIsNull, types.FilterModeNotNull:
+	default:
+		matcher.WithValue(c.Value)
+	}
+
+	node := filter.NewNode().AddLeaf(&filter.Filter{
+		Name:    c.Name,
+		Type:    types.BlockDocument,
+		Mode:    c.Mode,
+		Index:   fx,
+		Id:      field.Id,
+		Value:   c.Value,
+		Matcher: matcher,
+	})
+	return node, nil
+}
+
+// CompilePredicate compiles the partial index predicate of index s against
+// table schema ts. Returns nil when the index has no predicate.
+func CompilePredicate(s *schema.IndexSchema, ts *schema.Schema) (*filter.Node, error) {
+	if s.Predicate == nil {
+		return nil, nil
+	}
+	c, ok := s.Predicate.(Condition)
+	if !ok {
+		return nil, fmt.Errorf("index %s: unsupported predicate type %T", s.Name, s.Predicate)
+	}
+	return c.Compile(ts)
+}
+
 func (c *Condition) And(col string, mode types.FilterMode, value any) {
 	c.Add(Condition{
 		Name:   col,
@@ -377,6 +643,47 @@ func Regexp(col string, val any) Condition {
 	return Condition{Name: col, Mode: types.FilterModeRegexp, Value: val}
 }
 
+// Contains matches string values containing val as case-insensitive
+// substring. Token indexes with n-gram tokenizer accelerate this filter.
+// On list columns Contains matches lists with element val.
+func Contains(col string, val any) Condition {
+	return Condition{Name: col, Mode: types.FilterModeContains, Value: val}
+}
+
+// Match matches string values containing the words of term query val.
+// Alternatives are separated by `|`, e.g. `foo bar | baz` matches values
+// containing foo and bar or baz. Token indexes with word tokenizer
+// accelerate this filter.
+func Match(col string, val any) Condition {
+	return Condition{Name: col, Mode: types.FilterModeMatch, Value: val}
+}
+
+// Any matches list values containing at least one element of val.
+func Any(col string, val any) Condition {
+	return Condition{Name: col, Mode: types.FilterModeAny, Value: val}
+}
+
+// All matches list values containing all elements of val.
+func All(col string, val any) Condition {
+	return Condition{Name: col, Mode: types.FilterModeAll, Value: val}
+}
+
+// Prefix matches fixed size byte values starting with prefix val. IP fields
+// accept CIDR prefixes like `10.0.0.0/8`, other fields accept hex prefixes.
+func Prefix(col string, val any) Condition {
+	return Condition{Name: col, Mode: types.FilterModePrefix, Value: val}
+}
+
+// IsNull matches NULL values in nullable columns.
+func IsNull(col string) Condition {
+	return Condition{Name: col, Mode: types.FilterModeIsNull}
+}
+
+// NotNull matches non-NULL values in nullable columns.
+func NotNull(col string) Condition {
+	return Condition{Name: col, Mode: types.FilterModeNotNull}
+}
+
 func Range(col string, from, to any) Condition {
 	return Condition{Name: col, Mode: types.FilterModeRange, Value: filter.RangeValue{from, to}}
 }
@@ -4,10 +4,17 @@
 package query
 
 import (
+	"net/netip"
+	"slices"
 	"testing"
+	"time"
 
+	"blockwatch.cc/knoxdb/internal/bitset"
+	"blockwatch.cc/knoxdb/internal/block"
 	"blockwatch.cc/knoxdb/internal/operator/filter"
 	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/num"
+	"blockwatch.cc/knoxdb/pkg/schema"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -637,3 +644,332 @@ func buildLargeConditionTree(size int) Condition {
 	}
 	return c
 }
+
+// TestConditionBinary verifies conditions survive a binary round trip and
+// compile to the same filter tree.
+func TestConditionBinary(t *testing.T) {
+	tests := []struct {
+		name string
+		cond Condition
+	}{
+		{"Equal", Equal("id", uint64(1))},
+		{"String", Equal("name", "test")},
+		{"Enum", Equal("status", "active")},
+		{"Float", Gt("score", 4.5)},
+		{"Bool", Equal("is_active", true)},
+		{"Time", Ge("created", time.Unix(1700000000, 0).UTC())},
+		{"Range", Range("id", 1, 10)},
+		{"In", In("id", []uint64{1, 2, 3})},
+		{"InString", In("name", []string{"a", "b"})},
+		{"Tree", And(
+			Equal("status", "active"),
+			Or(Lt("score", 1.0), Gt("score", 9.0)),
+		)},
+		{"Lower", Equal("name", "alice").WithExpr(schema.Lower())},
+		{"TimeBucket", Ge("created", time.Unix(1700000000, 0).UTC()).WithExpr(schema.TimeBucket(24 * time.Hour))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf, err := tt.cond.MarshalBinary()
+			require.NoError(t, err)
+			var c Condition
+			require.NoError(t, c.UnmarshalBinary(buf))
+			n1, err := tt.cond.Compile(testSchema)
+			require.NoError(t, err)
+			n2, err := c.Compile(testSchema)
+			require.NoError(t, err)
+			assert.Equal(t, n1.String(), n2.String())
+			assert.Equal(t, tt.cond.Expr, c.Expr)
+		})
+	}
+
+	// unsupported value types fail
+	_, err := Equal("id", struct{}{}).MarshalBinary()
+	require.Error(t, err)
+}
+
+// TestConditionImplies verifies predicate implication used for partial
+// index selection.
+func TestConditionImplies(t *testing.T) {
+	pred := And(Equal("status", "active"), Ge("score", 5.0))
+	tests := []struct {
+		name   string
+		cond   Condition
+		expect bool
+	}{
+		{"Same", And(Equal("status", "active"), Ge("score", 5.0)), true},
+		{"Stricter", And(Equal("status", "active"), Gt("score", 6.0), Equal("name", "x")), true},
+		{"EqualValue", And(Equal("status", "active"), Equal("score", 7.0)), true},
+		{"RangeInside", And(Equal("status", "active"), Range("score", 5.0, 8.0)), true},
+		{"MissingConjunct", Ge("score", 5.0), false},
+		{"WrongValue", And(Equal("status", "pending"), Ge("score", 5.0)), false},
+		{"Weaker", And(Equal("status", "active"), Ge("score", 4.0)), false},
+		{"WrongDirection", And(Equal("status", "active"), Le("score", 8.0)), false},
+		{"Or", Or(Equal("status", "active"), Ge("score", 5.0)), false},
+	}
+	p, err := pred.Compile(testSchema)
+	require.NoError(t, err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := tt.cond.Compile(testSchema)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, n.Implies(p))
+		})
+	}
+}
+
+type docTestStruct struct {
+	Id     uint64         `knox:"id,pk"`
+	Params map[string]any `knox:"params"`
+}
+
+// TestConditionDocument verifies parsing and compilation of conditions
+// on document paths.
+func TestConditionDocument(t *testing.T) {
+	s := schema.MustSchemaOf(&docTestStruct{})
+
+	// parse path and mode
+	c, err := ParseCondition("params.amount.gt", "100", s)
+	require.NoError(t, err)
+	assert.Equal(t, Condition{Name: "params.amount", Mode: types.FilterModeGt, Value: int64(100)}, c)
+	c, err = ParseCondition("params.transfer.to", "tz1", s)
+	require.NoError(t, err)
+	assert.Equal(t, Condition{Name: "params.transfer.to", Mode: types.FilterModeEqual, Value: "tz1"}, c)
+	c, err = ParseCondition("params.rate.in", "1.5,true", s)
+	require.NoError(t, err)
+	assert.Equal(t, Condition{Name: "params.rate", Mode: types.FilterModeIn, Value: []any{1.5, true}}, c)
+	c, err = ParseCondition("params.nn", "", s)
+	require.NoError(t, err)
+	assert.Equal(t, Condition{Name: "params", Mode: types.FilterModeNotNull}, c)
+
+	// compile path filters
+	node, err := Gt("params.amount", 100).Compile(s)
+	require.NoError(t, err)
+	require.Len(t, node.Children, 1)
+	f := node.Children[0].Filter
+	assert.Equal(t, "params.amount", f.Name)
+	assert.Equal(t, types.BlockDocument, f.Type)
+	assert.Equal(t, 1, f.Index)
+	assert.Equal(t, int64(100), f.Value)
+	assert.True(t, f.Matcher.MatchValue([]byte(`{"amount":101}`)))
+	assert.False(t, f.Matcher.MatchValue([]byte(`{"amount":100}`)))
+
+	node, err = In("params.to", []string{"a", "b"}).Compile(s)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"a", "b"}, node.Children[0].Filter.Value)
+
+	_, err = IsNull("params").Compile(s)
+	require.NoError(t, err)
+
+	// errors
+	_, err = Equal("params", 1).Compile(s)
+	require.Error(t, err, "document without path")
+	_, err = Regexp("params.to", "a.*").Compile(s)
+	require.Error(t, err, "unsupported mode")
+	_, err = Equal("params.to", []int{1}).Compile(s)
+	require.Error(t, err, "non-scalar value")
+	_, err = Equal("id.x", 1).Compile(s)
+	require.Error(t, err, "path on non-document field")
+}
+
+type dictTestStruct struct {
+	Id  uint64 `knox:"id,pk"`
+	Sym string `knox:"sym,dict"`
+}
+
+// TestConditionDict verifies dictionary field conditions compile into
+// code comparisons.
+func TestConditionDict(t *testing.T) {
+	s := schema.MustSchemaOf(&dictTestStruct{})
+	enum, ok := s.Enums.Load().Lookup("sym")
+	require.True(t, ok)
+	_, err := enum.Put("a")
+	require.NoError(t, err)
+	_, err = enum.Put("b")
+	require.NoError(t, err)
+
+	node, err := Equal("sym", "b").Compile(s)
+	require.NoError(t, err)
+	f := node.Children[0].Filter
+	assert.Equal(t, types.BlockUint32, f.Type)
+	assert.Equal(t, uint32(1), f.Value)
+
+	// unknown values match no code
+	node, err = In("sym", []string{"a", "x"}).Compile(s)
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{0, schema.DictNoCode}, node.Children[0].Filter.Value)
+
+	c, err := ParseCondition("sym.in", "b,a", s)
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{1, 0}, c.Value)
+
+	// codes have no order
+	_, err = Gt("sym", "a").Compile(s)
+	require.Error(t, err)
+}
+
+type decimalTestStruct struct {
+	Id     uint64        `knox:"id,pk"`
+	Amount num.Decimal64 `knox:"amount,scale=2"`
+}
+
+// TestConditionDecimal verifies decimal literals of any width are converted
+// to column scale without silent precision loss.
+func TestConditionDecimal(t *testing.T) {
+	s := schema.MustSchemaOf(&decimalTestStruct{})
+
+	// wider literal with compatible scale
+	node, err := Equal("amount", num.MustParseDecimal128("1.2500")).Compile(s)
+	require.NoError(t, err)
+	assert.Equal(t, int64(125), node.Children[0].Filter.Value)
+
+	// inexact equality fails
+	_, err = Equal("amount", num.MustParseDecimal128("1.005")).Compile(s)
+	require.Error(t, err)
+	_, err = In("amount", []num.Decimal64{num.MustParseDecimal64("1.001")}).Compile(s)
+	require.Error(t, err)
+
+	// inexact comparisons round towards an equivalent condition
+	for _, tt := range []struct {
+		cond Condition
+		val  any
+	}{
+		{Gt("amount", num.MustParseDecimal256("1.005")), int64(100)},
+		{Le("amount", num.MustParseDecimal256("1.005")), int64(100)},
+		{Ge("amount", num.MustParseDecimal256("1.005")), int64(101)},
+		{Lt("amount", num.MustParseDecimal256("1.005")), int64(101)},
+		{Range("amount", num.MustParseDecimal128("1.001"), num.MustParseDecimal128("2.009")), RangeValue{int64(101), int64(200)}},
+	} {
+		node, err := tt.cond.Compile(s)
+		require.NoError(t, err, tt.cond.String())
+		assert.Equal(t, tt.val, node.Children[0].Filter.Value, tt.cond.String())
+	}
+
+	// parsed literals follow the same rules
+	c, err := ParseCondition("amount.gte", "1.005", s)
+	require.NoError(t, err)
+	assert.Equal(t, int64(101), c.Value)
+	c, err = ParseCondition("amount.rg", "-1.009,1.009", s)
+	require.NoError(t, err)
+	assert.Equal(t, RangeValue{int64(-100), int64(100)}, c.Value)
+	_, err = ParseCondition("amount.eq", "1.005", s)
+	require.Error(t, err)
+}
+
+type logicalTestStruct struct {
+	Id   uint64   `knox:"id,pk"`
+	Uid  [16]byte `knox:"uid,uuid"`
+	Peer [16]byte `knox:"peer,ip"`
+	Hash [32]byte `knox:"hash,hash32"`
+	Num  int64    `knox:"num"`
+}
+
+// TestConditionLogical verifies typed parsing of logical byte types and
+// the translation of prefix filters into value ranges.
+func TestConditionLogical(t *testing.T) {
+	s := schema.MustSchemaOf(&logicalTestStruct{})
+	ip := func(s string) []byte {
+		b := netip.MustParseAddr(s).As16()
+		return b[:]
+	}
+
+	c, err := ParseCondition("peer", "10.1.2.3", s)
+	require.NoError(t, err)
+	assert.Equal(t, ip("10.1.2.3"), c.Value)
+	c, err = ParseCondition("uid.in", "75fcf875-017d-4579-bfd9-791d3e6767f0,00000000000000000000000000000001", s)
+	require.NoError(t, err)
+	require.Len(t, c.Value, 2)
+	_, err = ParseCondition("peer", "10.1.2", s)
+	require.Error(t, err)
+
+	// prefix filters compile into ranges
+	c, err = ParseCondition("peer.cidr", "10.1.0.0/16", s)
+	require.NoError(t, err)
+	assert.Equal(t, types.FilterModePrefix, c.Mode)
+	node, err := c.Compile(s)
+	require.NoError(t, err)
+	assert.Equal(t, types.FilterModeRange, node.Children[0].Filter.Mode)
+	assert.Equal(t, RangeValue{ip("10.1.0.0"), ip("10.1.255.255")}, node.Children[0].Filter.Value)
+
+	node, err = Prefix("peer", netip.MustParsePrefix("2001:db8::/32")).Compile(s)
+	require.NoError(t, err)
+	assert.Equal(t, RangeValue{ip("2001:db8::"), ip("2001:db8:ffff:ffff:ffff:ffff:ffff:ffff")}, node.Children[0].Filter.Value)
+
+	node, err = Prefix("hash", "0xff").Compile(s)
+	require.NoError(t, err)
+	rg := node.Children[0].Filter.Value.(RangeValue)
+	assert.Equal(t, byte(0xff), rg[0].([]byte)[0])
+	assert.Equal(t, byte(0), rg[0].([]byte)[31])
+	assert.Equal(t, byte(0xff), rg[1].([]byte)[31])
+
+	// prefix filters require fixed size bytes
+	_, err = Prefix("num", "10").Compile(s)
+	require.Error(t, err)
+}
+
+// TestConditionExpr verifies expression conditions compile to filters
+// which evaluate the expression on field values before matching.
+func TestConditionExpr(t *testing.T) {
+	// expressions must be valid for the field type
+	_, err := Equal("score", 1.0).WithExpr(schema.Lower()).Compile(testSchema)
+	require.Error(t, err)
+	_, err = Equal("name", "a").WithExpr(schema.TimeBucket(time.Hour)).Compile(testSchema)
+	require.Error(t, err)
+	_, err = Equal("created", time.Now()).WithExpr(schema.TimeBucket(0)).Compile(testSchema)
+	require.Error(t, err)
+
+	// lower(name) = "alice"
+	node, err := Equal("name", "alice").WithExpr(schema.Lower()).Compile(testSchema)
+	require.NoError(t, err)
+	f := node.Children[0].Filter
+	require.Equal(t, schema.Lower(), f.Expr)
+	require.Contains(t, f.String(), "lower(name)")
+	m := f.Matcher
+	assert.True(t, m.MatchValue([]byte("Alice")), "bytes")
+	assert.True(t, m.MatchValue("ALICE"), "string")
+	assert.False(t, m.MatchValue([]byte("Bob")), "nomatch")
+	// lower() is not order preserving, ranges always match
+	assert.True(t, m.MatchRange([]byte("A"), []byte("B")), "range")
+
+	b := block.New(types.BlockBytes, 3)
+	b.Bytes().Append([]byte("ALICE"))
+	b.Bytes().Append([]byte("alice"))
+	b.Bytes().Append([]byte("Alicia"))
+	bits := bitset.New(3)
+	m.MatchVector(b, bits, nil)
+	assert.Equal(t, []int{0, 1}, slices.Collect(bits.Iterator()))
+	bits.Zero()
+	m.MatchRangeVectors(b, b, bits, nil)
+	assert.Equal(t, 3, bits.Count(), "range vectors")
+
+	// time_bucket(created, 1d) >= day
+	day := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+	node, err = Ge("created", day).WithExpr(schema.TimeBucket(24 * time.Hour)).Compile(testSchema)
+	require.NoError(t, err)
+	f = node.Children[0].Filter
+	m = f.Matcher
+	field, _ := testSchema.Find("created")
+	unix := func(t time.Time) int64 { return schema.TimeScale(field.Scale).ToUnix(t) }
+	assert.True(t, m.MatchValue(day.Add(time.Hour)), "time")
+	assert.True(t, m.MatchValue(unix(day.Add(23*time.Hour))), "wire")
+	assert.False(t, m.MatchValue(day.Add(-time.Second)), "previous day")
+	// time_bucket() is order preserving, ranges use bucketed min/max
+	assert.True(t, m.MatchRange(day.Add(-time.Hour), day.Add(time.Hour)), "range")
+	assert.False(t, m.MatchRange(day.Add(-48*time.Hour), day.Add(-time.Second)), "range before")
+
+	b = block.New(types.BlockInt64, 3)
+	b.Int64().Append(unix(day.Add(-time.Second)))
+	b.Int64().Append(unix(day))
+	b.Int64().Append(unix(day.Add(25 * time.Hour)))
+	bits = bitset.New(3)
+	m.MatchVector(b, bits, nil)
+	assert.Equal(t, []int{1, 2}, slices.Collect(bits.Iterator()))
+	mins, maxs := block.New(types.BlockInt64, 2), block.New(types.BlockInt64, 2)
+	mins.Int64().Append(unix(day.Add(-48 * time.Hour)))
+	maxs.Int64().Append(unix(day.Add(-time.Second)))
+	mins.Int64().Append(unix(day.Add(-time.Hour)))
+	maxs.Int64().Append(unix(day.Add(time.Hour)))
+	bits = bitset.New(2)
+	m.MatchRangeVectors(mins, maxs, bits, nil)
+	assert.Equal(t, []int{1}, slices.Collect(bits.Iterator()))
+}
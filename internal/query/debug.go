@@ -40,10 +40,11 @@ func (c Condition) dump(level int, w *strings.Builder) {
 }
 
 func (c Condition) FilterString() string {
+	name := c.Expr.Format(c.Name)
 	switch c.Mode {
 	case types.FilterModeRange:
 		return fmt.Sprintf("%s %s [%s, %s]",
-			c.Name,
+			name,
 			c.Mode.Symbol(),
 			util.ToString(c.Value.(filter.RangeValue)[0]),
 			util.ToString(c.Value.(filter.RangeValue)[1]),
@@ -51,12 +52,12 @@ func (c Condition) FilterString() string {
 	case types.FilterModeIn, types.FilterModeNotIn:
 		size := reflect.ValueOf(c.Value).Len()
 		if size > 16 {
-			return fmt.Sprintf("%s %s [%d values]", c.Name, c.Mode.Symbol(), size)
+			return fmt.Sprintf("%s %s [%d values]", name, c.Mode.Symbol(), size)
 		} else {
-			return fmt.Sprintf("%s %s [%#v]", c.Name, c.Mode.Symbol(), c.Value)
+			return fmt.Sprintf("%s %s [%#v]", name, c.Mode.Symbol(), c.Value)
 		}
 	default:
-		return fmt.Sprintf("%s %s %s", c.Name, c.Mode.Symbol(), util.ToString(c.Value))
+		return fmt.Sprintf("%s %s %s", name, c.Mode.Symbol(), util.ToString(c.Value))
 	}
 }
 
@@ -0,0 +1,376 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package query
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"time"
+
+	"blockwatch.cc/knoxdb/internal/operator/filter"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/num"
+	"blockwatch.cc/knoxdb/pkg/schema"
+)
+
+// Binary condition encoding is used to store partial index predicates
+// in the catalog. Values are stored in a canonical Go type (int64, uint64,
+// float64, string, []byte, time.Time, bool, Int128, Int256) and cast to
+// the field type when the condition is compiled against a schema.
+
+func init() {
+	schema.RegisterPredicateDecoder(func(buf []byte) (schema.IndexPredicate, error) {
+		var c Condition
+		if err := c.UnmarshalBinary(buf); err != nil {
+			return nil, err
+		}
+		return c, nil
+	})
+}
+
+var _ schema.IndexPredicate = Condition{}
+
+const (
+	condFlagLeaf = 1 << iota
+	condFlagOr
+	condFlagExpr
+)
+
+const (
+	valNil byte = iota
+	valBool
+	valInt
+	valUint
+	valFloat
+	valString
+	valBytes
+	valTime
+	valInt128
+	valInt256
+	valRange
+	valSlice
+)
+
+func (c Condition) MarshalBinary() ([]byte, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c.appendBinary(make([]byte, 0, 64))
+}
+
+func (c *Condition) UnmarshalBinary(buf []byte) error {
+	c.Clear()
+	n, err := c.readBinary(buf)
+	if err != nil {
+		return err
+	}
+	if n != len(buf) {
+		return fmt.Errorf("condition: %d trailing bytes", len(buf)-n)
+	}
+	return nil
+}
+
+func (c Condition) appendBinary(buf []byte) ([]byte, error) {
+	var flags byte
+	if c.IsLeaf() {
+		flags |= condFlagLeaf
+	}
+	if c.OrKind {
+		flags |= condFlagOr
+	}
+	if c.Expr.IsValid() {
+		flags |= condFlagExpr
+	}
+	buf = append(buf, flags)
+
+	if c.IsLeaf() {
+		buf = num.AppendUvarint(buf, uint64(len(c.Name)))
+		buf = append(buf, c.Name...)
+		buf = append(buf, byte(c.Mode))
+		if c.Expr.IsValid() {
+			buf = append(buf, byte(c.Expr.Type))
+			buf = binary.AppendVarint(buf, c.Expr.Arg)
+		}
+		return appendValue(buf, c.Value)
+	}
+
+	buf = num.AppendUvarint(buf, uint64(len(c.Children)))
+	for _, v := range c.Children {
+		var err error
+		buf, err = v.appendBinary(buf)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+func (c *Condition) readBinary(buf []byte) (int, error) {
+	if len(buf) < 2 {
+		return 0, io.ErrShortBuffer
+	}
+	flags := buf[0]
+	c.OrKind = flags&condFlagOr > 0
+	pos := 1
+
+	if flags&condFlagLeaf > 0 {
+		l, n := num.Uvarint(buf[pos:])
+		pos += n
+		if n <= 0 || len(buf) < pos+int(l)+1 {
+			return 0, io.ErrShortBuffer
+		}
+		c.Name = string(buf[pos : pos+int(l)])
+		pos += int(l)
+		c.Mode = types.FilterMode(buf[pos])
+		pos++
+		if flags&condFlagExpr > 0 {
+			if len(buf) < pos+2 {
+				return 0, io.ErrShortBuffer
+			}
+			c.Expr.Type = schema.IndexExprType(buf[pos])
+			pos++
+			v, n := binary.Varint(buf[pos:])
+			if n <= 0 {
+				return 0, io.ErrShortBuffer
+			}
+			c.Expr.Arg = v
+			pos += n
+		}
+		val, n, err := readValue(buf[pos:])
+		if err != nil {
+			return 0, err
+		}
+		c.Value = val
+		return pos + n, nil
+	}
+
+	l, n := num.Uvarint(buf[pos:])
+	if n <= 0 {
+		return 0, io.ErrShortBuffer
+	}
+	pos += n
+	c.Children = make([]Condition, int(l))
+	for i := range c.Children {
+		n, err := c.Children[i].readBinary(buf[pos:])
+		if err != nil {
+			return 0, err
+		}
+		pos += n
+	}
+	return pos, nil
+}
+
+func appendValue(buf []byte, val any) ([]byte, error) {
+	switch v := val.(type) {
+	case nil:
+		return append(buf, valNil), nil
+	case filter.RangeValue:
+		buf = append(buf, valRange)
+		buf, err := appendValue(buf, v[0])
+		if err != nil {
+			return nil, err
+		}
+		return appendValue(buf, v[1])
+	case []byte:
+		return appendScalar(buf, v)
+	}
+
+	// slices of scalar values
+	rv := reflect.ValueOf(val)
+	if rv.Kind() == reflect.Slice {
+		l := rv.Len()
+		if l == 0 {
+			return nil, fmt.Errorf("condition: empty slice value")
+		}
+		buf = append(buf, valSlice)
+		buf = num.AppendUvarint(buf, uint64(l))
+		for i := range l {
+			var err error
+			buf, err = appendScalar(buf, rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	}
+	return appendScalar(buf, val)
+}
+
+func appendScalar(buf []byte, val any) ([]byte, error) {
+	switch v := val.(type) {
+	case bool:
+		if v {
+			return append(buf, valBool, 1), nil
+		}
+		return append(buf, valBool, 0), nil
+	case int:
+		return binary.AppendVarint(append(buf, valInt), int64(v)), nil
+	case int64:
+		return binary.AppendVarint(append(buf, valInt), v), nil
+	case int32:
+		return binary.AppendVarint(append(buf, valInt), int64(v)), nil
+	case int16:
+		return binary.AppendVarint(append(buf, valInt), int64(v)), nil
+	case int8:
+		return binary.AppendVarint(append(buf, valInt), int64(v)), nil
+	case uint:
+		return num.AppendUvarint(append(buf, valUint), uint64(v)), nil
+	case uint64:
+		return num.AppendUvarint(append(buf, valUint), v), nil
+	case uint32:
+		return num.AppendUvarint(append(buf, valUint), uint64(v)), nil
+	case uint16:
+		return num.AppendUvarint(append(buf, valUint), uint64(v)), nil
+	case uint8:
+		return num.AppendUvarint(append(buf, valUint), uint64(v)), nil
+	case float64:
+		return binary.LittleEndian.AppendUint64(append(buf, valFloat), math.Float64bits(v)), nil
+	case float32:
+		return binary.LittleEndian.AppendUint64(append(buf, valFloat), math.Float64bits(float64(v))), nil
+	case string:
+		buf = num.AppendUvarint(append(buf, valString), uint64(len(v)))
+		return append(buf, v...), nil
+	case []byte:
+		buf = num.AppendUvarint(append(buf, valBytes), uint64(len(v)))
+		return append(buf, v...), nil
+	case time.Time:
+		return binary.AppendVarint(append(buf, valTime), v.UnixNano()), nil
+	case num.Int128:
+		b := v.Bytes16()
+		return append(append(buf, valInt128), b[:]...), nil
+	case num.Int256:
+		b := v.Bytes32()
+		return append(append(buf, valInt256), b[:]...), nil
+	default:
+		return nil, fmt.Errorf("condition: unsupported value type %T", val)
+	}
+}
+
+func readValue(buf []byte) (any, int, error) {
+	if len(buf) == 0 {
+		return nil, 0, io.ErrShortBuffer
+	}
+	switch buf[0] {
+	case valNil:
+		return nil, 1, nil
+	case valRange:
+		from, n1, err := readValue(buf[1:])
+		if err != nil {
+			return nil, 0, err
+		}
+		to, n2, err := readValue(buf[1+n1:])
+		if err != nil {
+			return nil, 0, err
+		}
+		return filter.RangeValue{from, to}, 1 + n1 + n2, nil
+	case valSlice:
+		l, n := num.Uvarint(buf[1:])
+		if n <= 0 || l == 0 || len(buf) < 2+n {
+			return nil, 0, io.ErrShortBuffer
+		}
+		pos := 1 + n
+		typ, err := scalarType(buf[pos])
+		if err != nil {
+			return nil, 0, err
+		}
+		slice := reflect.MakeSlice(reflect.SliceOf(typ), int(l), int(l))
+		for i := range int(l) {
+			v, n, err := readScalar(buf[pos:])
+			if err != nil {
+				return nil, 0, err
+			}
+			if reflect.TypeOf(v) != typ {
+				return nil, 0, fmt.Errorf("condition: mixed slice value types")
+			}
+			slice.Index(i).Set(reflect.ValueOf(v))
+			pos += n
+		}
+		return slice.Interface(), pos, nil
+	default:
+		return readScalar(buf)
+	}
+}
+
+func scalarType(tag byte) (reflect.Type, error) {
+	switch tag {
+	case valBool:
+		return reflect.TypeFor[bool](), nil
+	case valInt:
+		return reflect.TypeFor[int64](), nil
+	case valUint:
+		return reflect.TypeFor[uint64](), nil
+	case valFloat:
+		return reflect.TypeFor[float64](), nil
+	case valString:
+		return reflect.TypeFor[string](), nil
+	case valBytes:
+		return reflect.TypeFor[[]byte](), nil
+	case valTime:
+		return reflect.TypeFor[time.Time](), nil
+	case valInt128:
+		return reflect.TypeFor[num.Int128](), nil
+	case valInt256:
+		return reflect.TypeFor[num.Int256](), nil
+	default:
+		return nil, fmt.Errorf("condition: invalid value tag %d", tag)
+	}
+}
+
+func readScalar(buf []byte) (any, int, error) {
+	if len(buf) < 2 {
+		return nil, 0, io.ErrShortBuffer
+	}
+	tag, buf := buf[0], buf[1:]
+	switch tag {
+	case valBool:
+		return buf[0] > 0, 2, nil
+	case valInt:
+		v, n := binary.Varint(buf)
+		if n <= 0 {
+			return nil, 0, io.ErrShortBuffer
+		}
+		return v, 1 + n, nil
+	case valUint:
+		v, n := num.Uvarint(buf)
+		if n <= 0 {
+			return nil, 0, io.ErrShortBuffer
+		}
+		return v, 1 + n, nil
+	case valFloat:
+		if len(buf) < 8 {
+			return nil, 0, io.ErrShortBuffer
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(buf)), 9, nil
+	case valString, valBytes:
+		l, n := num.Uvarint(buf)
+		if n <= 0 || len(buf) < n+int(l) {
+			return nil, 0, io.ErrShortBuffer
+		}
+		if tag == valString {
+			return string(buf[n : n+int(l)]), 1 + n + int(l), nil
+		}
+		return append([]byte{}, buf[n:n+int(l)]...), 1 + n + int(l), nil
+	case valTime:
+		v, n := binary.Varint(buf)
+		if n <= 0 {
+			return nil, 0, io.ErrShortBuffer
+		}
+		return time.Unix(0, v).UTC(), 1 + n, nil
+	case valInt128:
+		if len(buf) < 16 {
+			return nil, 0, io.ErrShortBuffer
+		}
+		return num.Int128FromBytes(buf[:16]), 17, nil
+	case valInt256:
+		if len(buf) < 32 {
+			return nil, 0, io.ErrShortBuffer
+		}
+		return num.Int256FromBytes(buf[:32]), 33, nil
+	default:
+		return nil, 0, fmt.Errorf("condition: invalid value tag %d", tag)
+	}
+}
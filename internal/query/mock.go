@@ -32,6 +32,14 @@ func (idx *MockIndex) IsPk() bool {
 	return true
 }
 
+func (idx *MockIndex) IsReady() bool {
+	return true
+}
+
+func (idx *MockIndex) Predicate() engine.QueryCondition {
+	return nil
+}
+
 func (idx *MockIndex) IndexSchema() *schema.IndexSchema {
 	return idx.schema
 }
@@ -276,6 +276,11 @@ func (p *QueryPlan) Compile(ctx context.Context) error {
 
 	// identify relevant indexes based on request schema fields
 	for _, idx := range p.Table.Indexes() {
+		// skip indexes which are still building
+		if !idx.IsReady() {
+			continue
+		}
+
 		// its sufficient to check the first indexed field only
 		// this will select all single-field indexes and all
 		// composite indexes where the first index field is used as
@@ -283,6 +288,15 @@ func (p *QueryPlan) Compile(ctx context.Context) error {
 		if !slicex.Contains(filterFieldIds, idx.IndexSchema().Fields[0].Id) {
 			continue
 		}
+
+		// partial indexes are only usable when the query filter
+		// implies the index predicate
+		if idx.IndexSchema().IsPartial() {
+			pred, ok := idx.Predicate().(*filter.Node)
+			if !ok || !p.Filters.Implies(pred) {
+				continue
+			}
+		}
 		p.Indexes = append(p.Indexes, idx)
 	}
 
@@ -6,6 +6,8 @@ package query
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"iter"
 	"slices"
 	"time"
@@ -63,6 +65,9 @@ type StreamCallback func(engine.QueryRow) error
 type StreamResult struct {
 	r      *Result
 	fn     StreamCallback
+	schema *schema.Schema // optional result schema to project onto
+	src    *schema.Schema // source schema the view was built from
+	view   *pack.Package  // projection of source packs onto result schema
 	n      uint32
 	limit  uint32
 	offset uint32
@@ -77,6 +82,14 @@ func NewStreamResult(fn StreamCallback) *StreamResult {
 	return sr
 }
 
+// WithSchema projects streamed packs onto schema s so that row field
+// positions match the result schema. Journal and pack data may contain
+// more fields than requested.
+func (r *StreamResult) WithSchema(s *schema.Schema) *StreamResult {
+	r.schema = s
+	return r
+}
+
 func (r *StreamResult) WithLimit(l uint32) *StreamResult {
 	r.limit = l
 	return r
@@ -94,6 +107,13 @@ func (r *StreamResult) WithOrder(o types.OrderType) *StreamResult {
 
 // QueryResultConsumer interface
 func (r *StreamResult) Append(_ context.Context, pkg *pack.Package) error {
+	if r.schema != nil && pkg.Schema() != r.schema {
+		view, err := r.makeView(pkg.Schema())
+		if err != nil {
+			return err
+		}
+		pkg = pkg.View(view)
+	}
 	r.r.pkg = pkg
 	sel := pkg.Selected()
 	if sel == nil {
@@ -114,7 +134,7 @@ func (r *StreamResult) Append(_ context.Context, pkg *pack.Package) error {
 				}
 			}
 		} else {
-			for i := pkg.Len() - 1; i > -0; i-- {
+			for i := pkg.Len() - 1; i >= 0; i-- {
 				// skip offset
 				if r.offset > 0 {
 					r.offset--
@@ -151,11 +171,36 @@ func (r *StreamResult) Append(_ context.Context, pkg *pack.Package) error {
 	return nil
 }
 
+// makeView selects result fields from the source schema so that field
+// metadata and enum dictionaries are taken from the source.
+func (r *StreamResult) makeView(src *schema.Schema) (*pack.Package, error) {
+	if r.view != nil && r.src == src {
+		return r.view, nil
+	}
+	s, err := src.SelectIds(r.schema.Ids()...)
+	if err != nil {
+		return nil, err
+	}
+	s.Enums.Store(src.Enums.Load())
+	if r.view != nil {
+		r.view.Release()
+	}
+	r.src = src
+	r.view = pack.New().WithSchema(s)
+	return r.view, nil
+}
+
 func (r *StreamResult) Len() int {
 	return int(r.n)
 }
 
 func (r *StreamResult) Close() {
+	if r.view != nil {
+		r.view.Release()
+		r.view = nil
+	}
+	r.src = nil
+	r.schema = nil
 	r.r.pkg = nil
 	r.fn = nil
 	r.r.Close()
@@ -164,6 +209,78 @@ func (r *StreamResult) Close() {
 	r.offset = 0
 }
 
+type PackStreamCallback func(*pack.Package) error
+
+// PackStreamResult forwards matching rows of every source pack as a single
+// package in result schema.
+type PackStreamResult struct {
+	r     *Result
+	fn    PackStreamCallback
+	n     uint32
+	limit uint32
+}
+
+func NewPackStreamResult(fn PackStreamCallback) *PackStreamResult {
+	return &PackStreamResult{
+		r:  NewResult(nil),
+		fn: fn,
+	}
+}
+
+func (r *PackStreamResult) WithSchema(s *schema.Schema) *PackStreamResult {
+	r.r.pkg = pack.New().WithSchema(s)
+	return r
+}
+
+func (r *PackStreamResult) WithLimit(l uint32) *PackStreamResult {
+	r.limit = l
+	return r
+}
+
+func (r *PackStreamResult) WithOffset(o uint32) *PackStreamResult {
+	r.r.WithOffset(o)
+	return r
+}
+
+func (r *PackStreamResult) WithOrder(o types.OrderType) *PackStreamResult {
+	r.r.WithOrder(o)
+	return r
+}
+
+// QueryResultConsumer interface
+func (r *PackStreamResult) Append(ctx context.Context, pkg *pack.Package) error {
+	// collect selected rows into the reused result package, limits
+	// apply across all packs
+	r.r.Reset()
+	r.r.grow(pkg.Len())
+	if r.limit > 0 {
+		r.r.limit = r.limit - r.n
+	}
+	err := r.r.Append(ctx, pkg)
+	if err != nil && err != types.EndStream {
+		return err
+	}
+	if n := r.r.Len(); n > 0 {
+		r.n += uint32(n)
+		if err := r.fn(r.r.pkg); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func (r *PackStreamResult) Len() int {
+	return int(r.n)
+}
+
+func (r *PackStreamResult) Close() {
+	r.r.Close()
+	r.r = nil
+	r.fn = nil
+	r.n = 0
+	r.limit = 0
+}
+
 type Result struct {
 	pkg    *pack.Package
 	row    *Row // row cache
@@ -252,9 +369,23 @@ func (r *Result) Append(_ context.Context, src *pack.Package) error {
 		slices.Reverse(sel)
 	}
 
+	// make room for unlimited results
+	if r.limit == 0 {
+		if sel != nil {
+			r.grow(len(sel))
+		} else {
+			r.grow(src.Len())
+		}
+	}
+
 	// append selected elements (note: without src selection or desc order,
-	// limit and offset sel is nil here)
-	src.AppendTo(r.pkg, sel)
+	// limit and offset sel is nil here), journal and pack data may contain
+	// more fields than requested
+	if src.Schema() == r.pkg.Schema() {
+		src.AppendTo(r.pkg, sel)
+	} else {
+		src.AppendFieldsTo(r.pkg, sel)
+	}
 
 	// stop when limit is reached
 	if r.limit > 0 && r.pkg.Len() == int(r.limit) {
@@ -263,6 +394,22 @@ func (r *Result) Append(_ context.Context, src *pack.Package) error {
 	return nil
 }
 
+// grow extends the result pack so that n more rows fit. Capacity at
+// least doubles to amortize copies.
+func (r *Result) grow(n int) {
+	if r.pkg.FreeSpace() >= n {
+		return
+	}
+	if r.pkg.Cap() == 0 {
+		r.pkg.WithMaxRows(n).Alloc()
+		return
+	}
+	sz := max(2*r.pkg.Cap(), r.pkg.Len()+n)
+	pkg := r.pkg.Clone(sz).WithMaxRows(sz)
+	r.pkg.Release()
+	r.pkg = pkg
+}
+
 func (r *Result) Reset() {
 	r.pkg.Clear()
 }
@@ -337,6 +484,15 @@ func (r *Result) SortBy(name string, order types.OrderType) {
 		return
 	}
 	pack.NewPackageSorter([]int{idx}, []types.OrderType{order}).Sort(r.pkg)
+
+	// materialize sort order so that row positions follow the sort
+	sorted := pack.New().
+		WithMaxRows(r.pkg.Len()).
+		WithSchema(r.pkg.Schema()).
+		Alloc()
+	r.pkg.AppendTo(sorted, r.pkg.Selected())
+	r.pkg.Release()
+	r.pkg = sorted
 }
 
 func (r *Result) Iterator() iter.Seq2[int, engine.QueryRow] {
@@ -353,6 +509,51 @@ func (r *Result) Value(row, col int) any {
 	return r.pkg.Block(col).Get(row)
 }
 
+// Format returns a display string for the value at row and col. Logical
+// byte types use their text format (uuid, ip, hash32, addr20), other
+// bytes are hex encoded, decimals apply their scale and time values
+// their time scale format.
+func (r *Result) Format(row, col int) string {
+	f := r.pkg.Schema().Fields[col]
+	b := r.pkg.Block(col)
+	if b == nil || b.IsNull(row) {
+		return "null"
+	}
+	if f.IsEnum() && r.pkg.Schema().HasEnums() {
+		if enum, ok := r.pkg.Schema().Enums.Load().Lookup(f.Name); ok {
+			var (
+				val string
+				ok  bool
+			)
+			if f.Type == types.FieldTypeUint32 {
+				val, ok = enum.Value32(b.Uint32().Get(row))
+			} else {
+				val, ok = enum.Value(b.Uint16().Get(row))
+			}
+			if ok {
+				return val
+			}
+		}
+	}
+	switch v := r.pkg.ReadValue(col, row, f.Type, f.Scale).(type) {
+	case []byte:
+		return f.Logical().Format(v)
+	case string:
+		return v
+	case time.Time:
+		if f.Type == types.FieldTypeTime {
+			return v.Format(f.TimeFormat())
+		}
+		return schema.TimeScale(f.Scale).Format(v)
+	case json.RawMessage:
+		return string(v)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
 // Pack row
 type Row struct {
 	res    *Result        // result including query result schema
@@ -499,6 +700,11 @@ func (r *Row) Enum(col int) string {
 	if !ok {
 		return ""
 	}
-	val, _ := enum.Value(r.res.pkg.Uint16(col, r.row))
+	var val string
+	if r.schema.Fields[col].Type == types.FieldTypeUint32 {
+		val, _ = enum.Value32(r.res.pkg.Uint32(col, r.row))
+	} else {
+		val, _ = enum.Value(r.res.pkg.Uint16(col, r.row))
+	}
 	return val
 }
@@ -0,0 +1,159 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package query
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	"blockwatch.cc/knoxdb/internal/engine"
+	"blockwatch.cc/knoxdb/internal/pack"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/num"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"github.com/stretchr/testify/require"
+)
+
+type formatTestStruct struct {
+	Id     uint64        `knox:"id,pk"`
+	Peer   [16]byte      `knox:"peer,ip"`
+	Uid    [16]byte      `knox:"uid,uuid"`
+	Raw    [4]byte       `knox:"raw"`
+	Amount num.Decimal64 `knox:"amount,scale=2"`
+}
+
+// TestResultFormat verifies display strings of logical byte types.
+func TestResultFormat(t *testing.T) {
+	s := schema.MustSchemaOf(&formatTestStruct{})
+	pkg := pack.New().WithSchema(s).WithMaxRows(2).Alloc()
+	enc := schema.NewGenericEncoder[formatTestStruct]()
+	for i, v := range []formatTestStruct{
+		{Id: 1, Peer: netip.MustParseAddr("10.1.2.3").As16(), Raw: [4]byte{0xde, 0xad, 0xbe, 0xef}, Amount: num.NewDecimal64(12345, 2)},
+		{Id: 2, Peer: netip.MustParseAddr("2001:db8::1").As16(), Uid: [16]byte{0: 0x75, 15: 0xf0}},
+	} {
+		buf, err := enc.Encode(v, nil)
+		require.NoError(t, err)
+		pkg.AppendWire(buf, &schema.Meta{Rid: uint64(i + 1), Xmin: 1})
+	}
+	res := NewResult(pkg)
+	require.Equal(t, "10.1.2.3", res.Format(0, 1))
+	require.Equal(t, "2001:db8::1", res.Format(1, 1))
+	require.Equal(t, "75000000-0000-0000-0000-0000000000f0", res.Format(1, 2))
+	require.Equal(t, "deadbeef", res.Format(0, 3))
+	require.Equal(t, "123.45", res.Format(0, 4))
+}
+
+// TestResultAppendProjection verifies appending full packs into a result
+// that contains a subset of fields in different order.
+func TestResultAppendProjection(t *testing.T) {
+	s := schema.MustSchemaOf(&formatTestStruct{})
+	src := pack.New().WithSchema(s).WithMaxRows(2).Alloc()
+	enc := schema.NewGenericEncoder[formatTestStruct]()
+	for i, v := range []formatTestStruct{
+		{Id: 1, Amount: num.NewDecimal64(12345, 2)},
+		{Id: 2, Amount: num.NewDecimal64(-500, 2)},
+	} {
+		buf, err := enc.Encode(v, nil)
+		require.NoError(t, err)
+		src.AppendWire(buf, &schema.Meta{Rid: uint64(i + 1), Xmin: 1})
+	}
+	rs, err := s.Select("amount", "id")
+	require.NoError(t, err)
+	res := NewResult(pack.New().WithSchema(rs).WithMaxRows(2).Alloc())
+	require.NoError(t, res.Append(context.Background(), src))
+	require.Equal(t, 2, res.Len())
+	require.Equal(t, "123.45", res.Format(0, 0))
+	require.Equal(t, "1", res.Format(0, 1))
+	require.Equal(t, "-5.00", res.Format(1, 0))
+	require.Equal(t, "2", res.Format(1, 1))
+}
+
+func makeFormatTestPack(t *testing.T, vals ...formatTestStruct) *pack.Package {
+	s := schema.MustSchemaOf(&formatTestStruct{})
+	pkg := pack.New().WithSchema(s).WithMaxRows(len(vals)).Alloc()
+	enc := schema.NewGenericEncoder[formatTestStruct]()
+	for i, v := range vals {
+		buf, err := enc.Encode(v, nil)
+		require.NoError(t, err)
+		pkg.AppendWire(buf, &schema.Meta{Rid: uint64(i + 1), Xmin: 1})
+	}
+	return pkg
+}
+
+// TestResultSortBy verifies sorted results return rows in sort order.
+func TestResultSortBy(t *testing.T) {
+	res := NewResult(makeFormatTestPack(t,
+		formatTestStruct{Id: 1, Amount: num.NewDecimal64(300, 2)},
+		formatTestStruct{Id: 2, Amount: num.NewDecimal64(100, 2)},
+		formatTestStruct{Id: 3, Amount: num.NewDecimal64(200, 2)},
+	))
+	defer res.Close()
+	res.SortBy("amount", types.OrderAsc)
+	require.Equal(t, []string{"2", "3", "1"}, []string{res.Format(0, 0), res.Format(1, 0), res.Format(2, 0)})
+	res.SortBy("amount", types.OrderDesc)
+	require.Equal(t, []string{"1", "3", "2"}, []string{res.Format(0, 0), res.Format(1, 0), res.Format(2, 0)})
+}
+
+// TestStreamResultProjection verifies streamed rows use result schema
+// positions when packs contain more fields than requested.
+func TestStreamResultProjection(t *testing.T) {
+	src := makeFormatTestPack(t,
+		formatTestStruct{Id: 1, Amount: num.NewDecimal64(12345, 2)},
+		formatTestStruct{Id: 2, Amount: num.NewDecimal64(-500, 2)},
+	)
+	rs, err := src.Schema().Select("amount", "id")
+	require.NoError(t, err)
+	var ids []any
+	res := NewStreamResult(func(r engine.QueryRow) error {
+		ids = append(ids, r.Get(1))
+		return nil
+	}).WithSchema(rs)
+	require.NoError(t, res.Append(context.Background(), src))
+	res.Close()
+	require.Equal(t, []any{uint64(1), uint64(2)}, ids)
+}
+
+// TestStreamResultDesc verifies descending streams visit all rows.
+func TestStreamResultDesc(t *testing.T) {
+	src := makeFormatTestPack(t,
+		formatTestStruct{Id: 1},
+		formatTestStruct{Id: 2},
+		formatTestStruct{Id: 3},
+	)
+	defer src.Release()
+	var ids []any
+	res := NewStreamResult(func(r engine.QueryRow) error {
+		ids = append(ids, r.Get(0))
+		return nil
+	}).WithOrder(types.OrderDesc)
+	require.NoError(t, res.Append(context.Background(), src))
+	res.Close()
+	require.Equal(t, []any{uint64(3), uint64(2), uint64(1)}, ids)
+}
+
+// TestResultAppendGrow verifies unlimited results grow beyond their
+// initial capacity while limited results stop at the limit.
+func TestResultAppendGrow(t *testing.T) {
+	src := makeFormatTestPack(t,
+		formatTestStruct{Id: 1, Amount: num.NewDecimal64(100, 2)},
+		formatTestStruct{Id: 2, Amount: num.NewDecimal64(200, 2)},
+		formatTestStruct{Id: 3, Amount: num.NewDecimal64(300, 2)},
+	)
+	defer src.Release()
+
+	res := NewResult(pack.New().WithSchema(src.Schema()).WithMaxRows(2).Alloc())
+	defer res.Close()
+	require.NoError(t, res.Append(context.Background(), src))
+	require.NoError(t, res.Append(context.Background(), src.WithSelection([]uint32{2})))
+	require.Equal(t, 4, res.Len())
+	require.GreaterOrEqual(t, res.Pack().Cap(), 4)
+	require.Equal(t, []string{"1", "2", "3", "3"}, []string{res.Format(0, 0), res.Format(1, 0), res.Format(2, 0), res.Format(3, 0)})
+	require.Equal(t, "3.00", res.Format(3, 4))
+
+	lim := NewResult(pack.New().WithSchema(src.Schema()).WithMaxRows(2).Alloc()).WithLimit(2)
+	defer lim.Close()
+	require.ErrorIs(t, lim.Append(context.Background(), src.WithSelection(nil)), types.EndStream)
+	require.Equal(t, 2, lim.Pack().Cap())
+}
@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"encoding"
 	"math"
+	"math/big"
 	"strconv"
 
 	"blockwatch.cc/knoxdb/pkg/bitmap"
@@ -15,6 +16,12 @@ import (
 
 type Bitmap = bitmap.Bitmap // xroar.Bitmap
 
+// Divisible aggregatables compute exact means from their sum instead of
+// a floating point approximation.
+type Divisible interface {
+	Quo(int) Aggregatable
+}
+
 type Aggregatable interface {
 	encoding.BinaryUnmarshaler
 	Emit(*bytes.Buffer) error
@@ -28,9 +35,10 @@ type Aggregatable interface {
 
 // Exported type templates for use in type maps
 var (
-	// BigIntAgg         = func(scale uint8) Aggregatable { return &BigIntAggregator{scale: scale} }
+	BigIntAgg    = func(scale uint8) Aggregatable { return &BigIntAggregator{scale: scale} }
 	I128Agg      = func(scale uint8) Aggregatable { return &Int128Aggregator{scale: scale} }
 	I256Agg      = func(scale uint8) Aggregatable { return &Int256Aggregator{scale: scale} }
+	DecAgg       = func(s uint8, m num.RoundingMode) Aggregatable { return &DecimalAggregator{scale: s, out: s, mode: m} }
 	BitAgg       = func() Aggregatable { return &BitmapAggregator{} }
 	BitAggAnd    = func(src *Bitmap) Aggregatable { return &BitmapAggregator{src: src, fn: bitmap.And} }
 	BitAggOr     = func(src *Bitmap) Aggregatable { return &BitmapAggregator{src: src, fn: bitmap.Or} }
@@ -133,56 +141,56 @@ func (b *BitmapAggregator) SetFloat64(f64 float64) {
 }
 
 // BigInt
-// type BigIntAggregator struct {
-// 	BigInt
-// 	scale int
-// }
-
-// func (b *BigIntAggregator) Init(val Aggregatable) {
-// 	b.scale = val.(*BigIntAggregator).scale
-// }
-
-// func (b *BigIntAggregator) UnmarshalBinary(src []byte) error {
-// 	return b.BigInt.UnmarshalBinary(src)
-// }
-
-// func (b BigIntAggregator) Emit(buf *bytes.Buffer) error {
-// 	_, err := buf.WriteString(strconv.Quote(b.BigInt.Decimals(b.scale)))
-// 	return err
-// }
-
-// func (b BigIntAggregator) Zero() Aggregatable {
-// 	return &BigIntAggregator{
-// 		scale: b.scale,
-// 	}
-// }
-
-// func (b *BigIntAggregator) Add(val Aggregatable) Aggregatable {
-// 	a, ok := val.(*BigIntAggregator)
-// 	if !ok {
-// 		return b
-// 	}
-// 	return &BigIntAggregator{b.BigInt.Add(a.BigInt), b.scale}
-// }
-
-// func (b BigIntAggregator) Cmp(val Aggregatable) int {
-// 	a, ok := val.(*BigIntAggregator)
-// 	if !ok {
-// 		return 0
-// 	}
-// 	return b.BigInt.Big().Cmp(a.BigInt.Big())
-// }
-
-// func (b BigIntAggregator) Float64() float64 {
-// 	return b.BigInt.Float64(0)
-// }
-
-// func (b *BigIntAggregator) SetFloat64(f64 float64) {
-// 	f64 = math.RoundToEven(f64)
-// 	fs, _, _ := strings.Cut(strconv.FormatFloat(f64, 'f', -1, 64), ".")
-// 	bi, _ := ParseBigInt(fs)
-// 	b.BigInt = bi
-// }
+type BigIntAggregator struct {
+	num.Big
+	scale uint8
+}
+
+func (b *BigIntAggregator) Init(val Aggregatable) {
+	b.scale = val.(*BigIntAggregator).scale
+}
+
+func (b *BigIntAggregator) UnmarshalBinary(src []byte) error {
+	return b.Big.UnmarshalBinary(src)
+}
+
+func (b BigIntAggregator) Emit(buf *bytes.Buffer) error {
+	_, err := buf.WriteString(strconv.Quote(b.Big.Decimals(int(b.scale))))
+	return err
+}
+
+func (b BigIntAggregator) Zero() Aggregatable {
+	return &BigIntAggregator{num.BigZero, b.scale}
+}
+
+func (b *BigIntAggregator) Add(val Aggregatable) Aggregatable {
+	a, ok := val.(*BigIntAggregator)
+	if !ok {
+		return b
+	}
+	return &BigIntAggregator{b.Big.Add(a.Big), b.scale}
+}
+
+func (b BigIntAggregator) Cmp(val Aggregatable) int {
+	a, ok := val.(*BigIntAggregator)
+	if !ok {
+		return 0
+	}
+	return b.Big.Cmp(a.Big)
+}
+
+func (b BigIntAggregator) Float64() float64 {
+	return b.Big.Float64(0)
+}
+
+func (b *BigIntAggregator) SetFloat64(f64 float64) {
+	if math.IsNaN(f64) || math.IsInf(f64, 0) {
+		b.Big = num.BigZero
+		return
+	}
+	i, _ := new(big.Float).SetFloat64(math.RoundToEven(f64)).Int(nil)
+	b.Big = num.NewFromBigInt(i)
+}
 
 // Int128
 type Int128Aggregator struct {
@@ -212,6 +220,10 @@ func (b *Int128Aggregator) Add(val Aggregatable) Aggregatable {
 	if !ok {
 		return b
 	}
+	if a.scale != b.scale {
+		d := num.NewDecimal128(a.Int128, a.scale).Quantize(b.scale)
+		return &Int128Aggregator{b.Int128.Add(d.Int128()), b.scale}
+	}
 	return &Int128Aggregator{b.Int128.Add(a.Int128), b.scale}
 }
 
@@ -220,7 +232,7 @@ func (b Int128Aggregator) Cmp(val Aggregatable) int {
 	if !ok {
 		return 0
 	}
-	return b.Int128.Cmp(a.Int128)
+	return num.NewDecimal128(b.Int128, b.scale).Cmp(num.NewDecimal128(a.Int128, a.scale))
 }
 
 func (b Int128Aggregator) Float64() float64 {
@@ -259,6 +271,10 @@ func (b *Int256Aggregator) Add(val Aggregatable) Aggregatable {
 	if !ok {
 		return b
 	}
+	if a.scale != b.scale {
+		d := num.NewDecimal256(a.Int256, a.scale).Quantize(b.scale)
+		return &Int256Aggregator{b.Int256.Add(d.Int256()), b.scale}
+	}
 	return &Int256Aggregator{b.Int256.Add(a.Int256), b.scale}
 }
 
@@ -267,7 +283,7 @@ func (b Int256Aggregator) Cmp(val Aggregatable) int {
 	if !ok {
 		return 0
 	}
-	return b.Int256.Cmp(a.Int256)
+	return num.NewDecimal256(b.Int256, b.scale).Cmp(num.NewDecimal256(a.Int256, a.scale))
 }
 
 func (b Int256Aggregator) Float64() float64 {
@@ -277,3 +293,115 @@ func (b Int256Aggregator) Float64() float64 {
 func (b *Int256Aggregator) SetFloat64(f64 float64) {
 	b.Int256.SetFloat64(f64)
 }
+
+// Decimal aggregates decimal values of any width without loss of precision.
+// Values are kept unscaled at the highest scale seen so that sums of values
+// with different scales are exact. Output is rounded to the configured scale
+// using the configured rounding mode. Means are computed exactly from the sum.
+type DecimalAggregator struct {
+	val   num.Int256
+	scale uint8            // scale of val
+	out   uint8            // output scale
+	mode  num.RoundingMode // output rounding mode
+}
+
+func (b *DecimalAggregator) Init(val Aggregatable) {
+	a := val.(*DecimalAggregator)
+	b.out = a.out
+	b.mode = a.mode
+}
+
+func (b *DecimalAggregator) UnmarshalBinary(_ []byte) error {
+	return nil
+}
+
+// Decimal returns the current value rounded to output scale. When the value
+// exceeds 256 bits at output scale it is returned at its internal scale.
+func (b DecimalAggregator) Decimal() num.Decimal256 {
+	d := num.NewDecimal256(b.val, b.scale)
+	if r, err := d.Rescale(b.out, b.mode); err == nil {
+		return r
+	}
+	return d
+}
+
+func (b DecimalAggregator) Emit(buf *bytes.Buffer) error {
+	_, err := buf.WriteString(strconv.Quote(b.Decimal().String()))
+	return err
+}
+
+func (b DecimalAggregator) Zero() Aggregatable {
+	return &DecimalAggregator{scale: b.out, out: b.out, mode: b.mode}
+}
+
+func (b *DecimalAggregator) Add(val Aggregatable) Aggregatable {
+	y, ys, ok := decimalOf(val)
+	if !ok {
+		return b
+	}
+	x, xs := b.val, b.scale
+
+	// align to the higher scale, round to the lower scale only when
+	// the aligned value would not fit
+	switch {
+	case xs < ys:
+		if d, err := num.NewDecimal256(x, xs).Rescale(ys, num.RoundExact); err == nil {
+			x, xs = d.Int256(), ys
+		} else {
+			y = num.NewDecimal256(y, ys).Quantize(xs).Int256()
+		}
+	case xs > ys:
+		if d, err := num.NewDecimal256(y, ys).Rescale(xs, num.RoundExact); err == nil {
+			y = d.Int256()
+		} else {
+			x, xs = num.NewDecimal256(x, xs).Quantize(ys).Int256(), ys
+		}
+	}
+	return &DecimalAggregator{x.Add(y), xs, b.out, b.mode}
+}
+
+func (b DecimalAggregator) Cmp(val Aggregatable) int {
+	y, ys, ok := decimalOf(val)
+	if !ok {
+		return 0
+	}
+	return num.NewDecimal256(b.val, b.scale).Cmp(num.NewDecimal256(y, ys))
+}
+
+// Quo divides the aggregated value by n with a single rounding step.
+func (b DecimalAggregator) Quo(n int) Aggregatable {
+	d, err := num.NewDecimal256(b.val, b.scale).Quo64(int64(n), b.out, b.mode)
+	if err != nil {
+		z := b.Zero()
+		z.SetFloat64(num.NewDecimal256(b.val, b.scale).Float64() / float64(n))
+		return z
+	}
+	return &DecimalAggregator{d.Int256(), b.out, b.out, b.mode}
+}
+
+func (b DecimalAggregator) Float64() float64 {
+	return num.NewDecimal256(b.val, b.scale).Float64()
+}
+
+func (b *DecimalAggregator) SetFloat64(f64 float64) {
+	var d num.Decimal256
+	if math.IsNaN(f64) || math.IsInf(f64, 0) || d.SetFloat64(f64, b.out) != nil {
+		b.val, b.scale = num.ZeroInt256, b.out
+		return
+	}
+	b.val, b.scale = d.Int256(), b.out
+}
+
+// decimalOf returns unscaled value and scale of decimal compatible aggregators.
+func decimalOf(val Aggregatable) (num.Int256, uint8, bool) {
+	switch a := val.(type) {
+	case *DecimalAggregator:
+		return a.val, a.scale, true
+	case *Int128Aggregator:
+		return a.Int128.Int256(), a.scale, true
+	case *Int256Aggregator:
+		return a.Int256, a.scale, true
+	default:
+		return num.ZeroInt256, 0, false
+	}
+}
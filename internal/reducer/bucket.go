@@ -10,6 +10,7 @@ import (
 
 	"blockwatch.cc/knoxdb/internal/engine"
 	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/schema"
 	"blockwatch.cc/knoxdb/pkg/util"
 )
 
@@ -28,13 +29,27 @@ type Bucket interface {
 	Len() int
 	Push(time.Time, engine.QueryRow, bool) error
 	Emit(*bytes.Buffer) error
+
+	// Remove drops the reduced value of the window starting at t. The
+	// window is then filled like a window without input rows.
+	Remove(time.Time)
+
+	// Walk calls fn for every output step with the window start time and
+	// the value as Go type matching the bucket's field type or nil for
+	// null fills. Iteration stops when fn returns false.
+	Walk(func(time.Time, any) bool)
+
+	// Type returns field type and scale of bucket values.
+	Type() (types.FieldType, uint8)
 }
 
-func NewBucket(typ types.FieldType) Bucket {
+// NewBucket returns a bucket for field type typ. Scale is only used
+// by decimal types.
+func NewBucket(typ types.FieldType, scale uint8) Bucket {
 	switch typ {
 	case types.FieldTypeTimestamp, types.FieldTypeDate, types.FieldTypeTime:
 		// required for time column
-		return NewTimeBucket()
+		return NewTimeBucket(scale)
 
 	case types.FieldTypeBytes: // requires an aggregator type, use WithTypeOf(&MyType{})
 		return NewTypedBucket()
@@ -80,23 +95,11 @@ func NewBucket(typ types.FieldType) Bucket {
 		b.emit = emitFloats[float32]
 		return b
 
-	// TODO: maybe a DecimalBucket makes sense
-	case types.FieldTypeDecimal256:
-		b := NewNativeBucket[float64]()
-		b.emit = emitFloats[float64]
-		return b
-	case types.FieldTypeDecimal128:
-		b := NewNativeBucket[float64]()
-		b.emit = emitFloats[float64]
-		return b
-	case types.FieldTypeDecimal64:
-		b := NewNativeBucket[float64]()
-		b.emit = emitFloats[float64]
-		return b
-	case types.FieldTypeDecimal32:
-		b := NewNativeBucket[float64]()
-		b.emit = emitFloats[float64]
-		return b
+	case types.FieldTypeDecimal256,
+		types.FieldTypeDecimal128,
+		types.FieldTypeDecimal64,
+		types.FieldTypeDecimal32:
+		return NewDecimalBucket(scale)
 
 	case types.FieldTypeInt256:
 		b := NewTypedBucket()
@@ -110,9 +113,65 @@ func NewBucket(typ types.FieldType) Bucket {
 		b.read = b.readInt128
 		return b
 
+	case types.FieldTypeBigint:
+		b := NewTypedBucket()
+		b.WithTypeOf(&BigIntAggregator{})
+		b.read = b.readBigint
+		return b
+
 		// unsupported for time-series output (can still use as filter)
 		// case types.FieldTypeString:
 		// case types.FieldTypeBoolean:
 	}
 	return nil
 }
+
+// NewHistBucket returns a bucket for value distributions of numeric field
+// type typ with bins equal width bins per window. Distributions are output
+// as Histogram values and encoded as JSON objects. Returns nil for
+// unsupported field types.
+func NewHistBucket(typ types.FieldType, bins int) Bucket {
+	if bins <= 0 {
+		bins = DefaultHistogramBins
+	}
+	switch typ {
+	case types.FieldTypeInt64:
+		return newHistBucket[int64](bins)
+	case types.FieldTypeInt32:
+		return newHistBucket[int32](bins)
+	case types.FieldTypeInt16:
+		return newHistBucket[int16](bins)
+	case types.FieldTypeInt8:
+		return newHistBucket[int8](bins)
+	case types.FieldTypeUint64:
+		return newHistBucket[uint64](bins)
+	case types.FieldTypeUint32:
+		return newHistBucket[uint32](bins)
+	case types.FieldTypeUint16:
+		return newHistBucket[uint16](bins)
+	case types.FieldTypeUint8:
+		return newHistBucket[uint8](bins)
+	case types.FieldTypeFloat64:
+		return newHistBucket[float64](bins)
+	case types.FieldTypeFloat32:
+		return newHistBucket[float32](bins)
+	}
+	return nil
+}
+
+// NewTWABucket returns a bucket for time-weighted averages of numeric field
+// type typ. Sample times are read from the time field at index timeIndex
+// stored at scale. Returns nil for unsupported field types.
+func NewTWABucket(typ types.FieldType, timeIndex int, scale uint8) Bucket {
+	switch typ {
+	case types.FieldTypeTimestamp, types.FieldTypeDate, types.FieldTypeTime:
+		return nil
+	}
+	b, ok := NewBucket(typ, 0).(interface {
+		withSampleTime(int, schema.TimeScale) Bucket
+	})
+	if !ok {
+		return nil
+	}
+	return b.withSampleTime(timeIndex, schema.TimeScale(scale)).WithReducer(ReducerFuncTWA)
+}
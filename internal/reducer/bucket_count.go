@@ -6,10 +6,12 @@ package reducer
 import (
 	"bytes"
 	"reflect"
+	"slices"
 	"sort"
 	"time"
 
 	"blockwatch.cc/knoxdb/internal/engine"
+	"blockwatch.cc/knoxdb/internal/types"
 	"blockwatch.cc/knoxdb/pkg/util"
 )
 
@@ -83,6 +85,12 @@ func (b *CountBucket) Len() int {
 	return len(b.reducers)
 }
 
+func (b *CountBucket) Remove(t time.Time) {
+	b.reducers = slices.DeleteFunc(b.reducers, func(r *CountReducer[int]) bool {
+		return r.Time().Equal(t)
+	})
+}
+
 func (b *CountBucket) grow() *CountReducer[int] {
 	r := &CountReducer[int]{}
 	b.reducers = append(b.reducers, r)
@@ -140,11 +148,12 @@ func (b *CountBucket) Push(t time.Time, _ engine.QueryRow, join bool) error {
 	return nil
 }
 
-func (b *CountBucket) Emit(buf *bytes.Buffer) error {
-	buf.WriteByte('[')
-	defer buf.WriteByte(']')
+// Each calls fn for every output step in time order with the window start
+// time and the row count or filled value. Null fills pass ok=false.
+// Iteration stops when fn returns false.
+func (b *CountBucket) Each(fn func(t time.Time, val int, ok bool) bool) {
 	if len(b.reducers) == 0 {
-		return nil
+		return
 	}
 	var (
 		last  = b.reducers[0]
@@ -169,15 +178,10 @@ func (b *CountBucket) Emit(buf *bytes.Buffer) error {
 				nextVal, _ := next.Value()
 				lastVal, _ := last.Value()
 				if fillVal, ok, isNull := Fill(b.fill, step, last.Time(), next.Time(), lastVal, nextVal); ok {
-					if count > 0 {
-						buf.WriteByte(',')
-					}
-					if isNull {
-						buf.Write(null)
-					} else {
-						buf.WriteString(emitIntegers(fillVal))
-					}
 					count++
+					if !fn(step, fillVal, !isNull) {
+						return
+					}
 				}
 			}
 			continue
@@ -186,15 +190,44 @@ func (b *CountBucket) Emit(buf *bytes.Buffer) error {
 		// output value
 		val, ok := next.Value()
 		if ok {
-			if count > 0 {
-				buf.WriteByte(',')
-			}
-			buf.WriteString(emitIntegers(val))
 			count++
+			if !fn(step, val, true) {
+				return
+			}
 		}
 		idx++
 		last = next
 	}
+}
 
+func (b *CountBucket) Walk(fn func(time.Time, any) bool) {
+	b.Each(func(t time.Time, val int, ok bool) bool {
+		if !ok {
+			return fn(t, nil)
+		}
+		return fn(t, int64(val))
+	})
+}
+
+func (b *CountBucket) Type() (types.FieldType, uint8) {
+	return types.FieldTypeInt64, 0
+}
+
+func (b *CountBucket) Emit(buf *bytes.Buffer) error {
+	buf.WriteByte('[')
+	var count int
+	b.Each(func(_ time.Time, val int, ok bool) bool {
+		if count > 0 {
+			buf.WriteByte(',')
+		}
+		if ok {
+			buf.WriteString(emitIntegers(val))
+		} else {
+			buf.Write(null)
+		}
+		count++
+		return true
+	})
+	buf.WriteByte(']')
 	return nil
 }
@@ -6,6 +6,7 @@ package reducer
 import (
 	"bytes"
 	"fmt"
+	"slices"
 	"sort"
 
 	"reflect"
@@ -13,6 +14,8 @@ import (
 	"time"
 
 	"blockwatch.cc/knoxdb/internal/engine"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/schema"
 	"blockwatch.cc/knoxdb/pkg/util"
 )
 
@@ -29,12 +32,18 @@ type NativeBucket[T Number] struct {
 	limit    int            // value limit
 	fill     FillMode       // fill missing data
 	emit     func(T) string
+	value    func(time.Time, T) any                   // converts values for Walk
+	hist     func(Reducer[T]) Histogram               // outputs distributions instead of values
+	sample   func(engine.QueryRow) (time.Time, error) // reads sample times for Sampler reducers
+	typ      types.FieldType                          // result field type
+	scale    uint8                                    // result field scale
 }
 
 func NewNativeBucket[T Number]() *NativeBucket[T] {
 	return &NativeBucket[T]{
 		template: NewReducer[T](ReducerFuncSum),
 		reducers: make([]Reducer[T], 0),
+		typ:      fieldType[T](),
 	}
 }
 
@@ -95,6 +104,12 @@ func (b *NativeBucket[T]) Len() int {
 	return len(b.reducers)
 }
 
+func (b *NativeBucket[T]) Remove(t time.Time) {
+	b.reducers = slices.DeleteFunc(b.reducers, func(r Reducer[T]) bool {
+		return r.Time().Equal(t)
+	})
+}
+
 func (b *NativeBucket[T]) grow() Reducer[T] {
 	r := NewReducer[T](b.template.Type())
 	b.reducers = append(b.reducers, r)
@@ -154,15 +169,65 @@ func (b *NativeBucket[T]) Push(t time.Time, r engine.QueryRow, join bool) error
 		}
 	}
 
+	if b.sample != nil {
+		if s, ok := b.reducers[target].(Sampler[T]); ok {
+			at, err := b.sample(r)
+			if err != nil {
+				return err
+			}
+			s.Sample(nextVal, t, at)
+			return nil
+		}
+	}
 	b.reducers[target].Reduce(nextVal, t, join)
 	return nil
 }
 
-func (b *NativeBucket[T]) Emit(buf *bytes.Buffer) error {
-	buf.WriteByte('[')
-	defer buf.WriteByte(']')
+// withSampleTime reads sample times from the time field at index.
+func (b *NativeBucket[T]) withSampleTime(index int, scale schema.TimeScale) Bucket {
+	b.sample = func(r engine.QueryRow) (time.Time, error) {
+		switch val := r.Get(index).(type) {
+		case time.Time:
+			return val.UTC(), nil
+		case int64:
+			return scale.FromUnix(val), nil
+		default:
+			return time.Time{}, fmt.Errorf("invalid value type %T for time field", val)
+		}
+	}
+	return b
+}
+
+// carry connects time-weighted reducers of consecutive windows.
+func (b *NativeBucket[T]) carry() {
+	var prev *TWAReducer[T]
+	for _, r := range b.reducers {
+		tw, ok := r.(*TWAReducer[T])
+		if !ok {
+			return
+		}
+		tw.Carry(b.window.Next(tw.Time(), 1), prev)
+		prev = tw
+	}
+}
+
+// Each calls fn for every output step in time order with the window start
+// time and the reduced or filled value. Null fills pass ok=false. Iteration
+// stops when fn returns false.
+func (b *NativeBucket[T]) Each(fn func(t time.Time, val T, ok bool) bool) {
+	b.each(func(t time.Time, _ Reducer[T], val T, ok bool) bool {
+		return fn(t, val, ok)
+	})
+}
+
+// each works like Each and also passes the reducer of reduced windows.
+// Filled values pass a nil reducer.
+func (b *NativeBucket[T]) each(fn func(time.Time, Reducer[T], T, bool) bool) {
 	if len(b.reducers) == 0 {
-		return nil
+		return
+	}
+	if b.sample != nil {
+		b.carry()
 	}
 	var (
 		last  = b.reducers[0]
@@ -187,15 +252,10 @@ func (b *NativeBucket[T]) Emit(buf *bytes.Buffer) error {
 				nextVal, _ := next.Value()
 				lastVal, _ := last.Value()
 				if fillVal, ok, isNull := Fill(b.fill, step, last.Time(), next.Time(), lastVal, nextVal); ok {
-					if count > 0 {
-						buf.WriteByte(',')
-					}
-					if isNull {
-						buf.Write(null)
-					} else {
-						buf.WriteString(b.emit(fillVal))
-					}
 					count++
+					if !fn(step, nil, fillVal, !isNull) {
+						return
+					}
 				}
 			}
 			continue
@@ -204,19 +264,75 @@ func (b *NativeBucket[T]) Emit(buf *bytes.Buffer) error {
 		// output value
 		val, ok := next.Value()
 		if ok {
-			if count > 0 {
-				buf.WriteByte(',')
-			}
-			buf.WriteString(b.emit(val))
 			count++
+			if !fn(step, next, val, true) {
+				return
+			}
 		}
 		idx++
 		last = next
 	}
+}
 
+func (b *NativeBucket[T]) Walk(fn func(time.Time, any) bool) {
+	b.each(func(t time.Time, r Reducer[T], val T, ok bool) bool {
+		switch {
+		case !ok:
+			return fn(t, nil)
+		case b.hist != nil:
+			if r == nil {
+				return fn(t, nil)
+			}
+			return fn(t, b.hist(r))
+		case b.value != nil:
+			return fn(t, b.value(t, val))
+		default:
+			return fn(t, val)
+		}
+	})
+}
+
+func (b *NativeBucket[T]) Type() (types.FieldType, uint8) {
+	return b.typ, b.scale
+}
+
+func (b *NativeBucket[T]) Emit(buf *bytes.Buffer) error {
+	buf.WriteByte('[')
+	var count int
+	b.each(func(_ time.Time, r Reducer[T], val T, ok bool) bool {
+		if count > 0 {
+			buf.WriteByte(',')
+		}
+		switch {
+		case !ok, b.hist != nil && r == nil:
+			buf.Write(null)
+		case b.hist != nil:
+			b.hist(r).Emit(buf)
+		default:
+			buf.WriteString(b.emit(val))
+		}
+		count++
+		return true
+	})
+	buf.WriteByte(']')
 	return nil
 }
 
+// newHistBucket returns a bucket for the value distribution per window.
+// Filled windows have no distribution and are output as null.
+func newHistBucket[T Number](bins int) *NativeBucket[T] {
+	b := NewNativeBucket[T]()
+	b.template = NewReducer[T](ReducerFuncHist)
+	b.typ = types.FieldTypeString
+	b.hist = func(r Reducer[T]) Histogram {
+		if h, ok := r.(*HistReducer[T]); ok {
+			return h.Histogram(bins)
+		}
+		return Histogram{}
+	}
+	return b
+}
+
 func emitIntegers[T Signed](num T) string {
 	return strconv.FormatInt(int64(num), 10)
 }
@@ -237,3 +353,29 @@ func (b *NativeBucket[T]) read(r engine.QueryRow) (T, error) {
 	}
 	return t, nil
 }
+
+// fieldType returns the schema field type of native values of type T.
+func fieldType[T Number]() types.FieldType {
+	switch any(T(0)).(type) {
+	case int64:
+		return types.FieldTypeInt64
+	case int32:
+		return types.FieldTypeInt32
+	case int16:
+		return types.FieldTypeInt16
+	case int8:
+		return types.FieldTypeInt8
+	case uint64:
+		return types.FieldTypeUint64
+	case uint32:
+		return types.FieldTypeUint32
+	case uint16:
+		return types.FieldTypeUint16
+	case uint8:
+		return types.FieldTypeUint8
+	case float32:
+		return types.FieldTypeFloat32
+	default:
+		return types.FieldTypeFloat64
+	}
+}
@@ -6,20 +6,28 @@ package reducer
 import (
 	"strconv"
 	"time"
+
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/schema"
 )
 
 type TimeBucket struct {
 	NativeBucket[int64]
+	scale schema.TimeScale
 }
 
-func NewTimeBucket() *TimeBucket {
+func NewTimeBucket(scale uint8) *TimeBucket {
 	t := &TimeBucket{
-		*NewNativeBucket[int64](),
+		NativeBucket: *NewNativeBucket[int64](),
+		scale:        schema.TimeScale(scale),
 	}
 	t.template = NewReducer[int64](ReducerFuncFirst)
 	t.fill = FillModeNow
 	t.locked = true
 	t.emit = t.emitTime
+	t.value = windowTime
+	t.typ = types.FieldTypeTimestamp
+	t.NativeBucket.scale = scale
 	return t
 }
 
@@ -36,6 +44,12 @@ func NewTimeBucket() *TimeBucket {
 // }
 
 func (b *TimeBucket) emitTime(t int64) string {
-	val := b.window.Truncate(time.Unix(0, t).UTC())
+	val := b.window.Truncate(b.scale.FromUnix(t))
 	return strconv.Quote(val.Format(time.RFC3339))
 }
+
+// windowTime returns the window start time of an output step as time
+// column value.
+func windowTime(t time.Time, _ int64) any {
+	return t
+}
@@ -7,10 +7,12 @@ import (
 	"bytes"
 	"fmt"
 	"reflect"
+	"slices"
 	"sort"
 	"time"
 
 	"blockwatch.cc/knoxdb/internal/engine"
+	"blockwatch.cc/knoxdb/internal/types"
 	"blockwatch.cc/knoxdb/pkg/num"
 	"blockwatch.cc/knoxdb/pkg/util"
 )
@@ -30,6 +32,18 @@ type TypedBucket struct {
 	read     func(engine.QueryRow) (Aggregatable, error)
 }
 
+// NewDecimalBucket returns a bucket for decimal columns stored at scale.
+// Results are rounded to the same scale using round half even unless
+// configured otherwise with WithTypeOf(DecAgg(scale, mode)).
+func NewDecimalBucket(scale uint8) *TypedBucket {
+	b := NewTypedBucket()
+	b.WithTypeOf(DecAgg(scale, num.RoundHalfEven))
+	b.read = func(r engine.QueryRow) (Aggregatable, error) {
+		return b.readDecimal(r, scale)
+	}
+	return b
+}
+
 func NewTypedBucket() *TypedBucket {
 	t := &TypedBucket{
 		reducers: make([]TypedReducer, 0),
@@ -115,6 +129,12 @@ func (b *TypedBucket) Len() int {
 	return len(b.reducers)
 }
 
+func (b *TypedBucket) Remove(t time.Time) {
+	b.reducers = slices.DeleteFunc(b.reducers, func(r TypedReducer) bool {
+		return r.Time().Equal(t)
+	})
+}
+
 func (b *TypedBucket) grow() TypedReducer {
 	r := NewTypedReducer(b.typ, b.template.Type())
 	r.Init(b.template.Config())
@@ -178,24 +198,19 @@ func (b *TypedBucket) Push(t time.Time, r engine.QueryRow, join bool) error {
 	return nil
 }
 
-func (b *TypedBucket) Emit(buf *bytes.Buffer) error {
-	buf.WriteByte('[')
-	defer buf.WriteByte(']')
+// Each calls fn for every output step in time order with the window start
+// time and the reduced or filled value. Null fills pass ok=false. Iteration
+// stops when fn returns false. Filled values are allocated per step.
+func (b *TypedBucket) Each(fn func(t time.Time, val Aggregatable, ok bool) bool) {
 	if len(b.reducers) == 0 {
-		return nil
+		return
 	}
 	var (
-		last   = b.reducers[0]
-		filler Aggregatable
-		idx    int
-		count  int
+		last  = b.reducers[0]
+		idx   int
+		count int
 	)
 
-	if b.fill != FillModeNone {
-		filler = reflect.New(b.typ).Interface().(Aggregatable)
-		filler.Init(b.template.Config())
-	}
-
 	// UTC and truncated to window
 	start, end := b.trange.From, b.trange.To
 
@@ -213,16 +228,15 @@ func (b *TypedBucket) Emit(buf *bytes.Buffer) error {
 				nextVal, _ := next.Value()
 				lastVal, _ := last.Value()
 				if fillVal, ok, isNull := Fill(b.fill, step, last.Time(), next.Time(), lastVal.Float64(), nextVal.Float64()); ok {
-					if count > 0 {
-						buf.WriteByte(',')
-					}
-					if isNull {
-						buf.Write(null)
-					} else {
+					count++
+					var filler Aggregatable
+					if !isNull {
+						filler = b.zero()
 						filler.SetFloat64(fillVal)
-						filler.Emit(buf)
 					}
-					count++
+					if !fn(step, filler, !isNull) {
+						return
+					}
 				}
 			}
 			continue
@@ -231,19 +245,115 @@ func (b *TypedBucket) Emit(buf *bytes.Buffer) error {
 		// output value
 		val, ok := next.Value()
 		if ok {
-			if count > 0 {
-				buf.WriteByte(',')
-			}
-			val.Emit(buf)
 			count++
+			if !fn(step, val, true) {
+				return
+			}
 		}
 		idx++
 		last = next
 	}
+}
+
+func (b *TypedBucket) Walk(fn func(time.Time, any) bool) {
+	b.Each(func(t time.Time, val Aggregatable, ok bool) bool {
+		if !ok {
+			return fn(t, nil)
+		}
+		return fn(t, valueOf(val))
+	})
+}
+
+// zero returns a new output value.
+func (b *TypedBucket) zero() Aggregatable {
+	if b.counts() {
+		return &BitmapAggregator{}
+	}
+	v := reflect.New(b.typ).Interface().(Aggregatable)
+	v.Init(b.template.Config())
+	return v
+}
 
+// counts returns true when the reducer outputs counts instead of values.
+func (b *TypedBucket) counts() bool {
+	switch b.template.Type() {
+	case ReducerFuncCount, ReducerFuncCountDistinct:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *TypedBucket) Type() (types.FieldType, uint8) {
+	if b.counts() {
+		return types.FieldTypeInt64, 0
+	}
+	switch v := b.template.Config().(type) {
+	case *DecimalAggregator:
+		return types.FieldTypeDecimal256, v.out
+	case *Int128Aggregator:
+		if v.scale > 0 {
+			return types.FieldTypeDecimal128, v.scale
+		}
+		return types.FieldTypeInt128, 0
+	case *Int256Aggregator:
+		if v.scale > 0 {
+			return types.FieldTypeDecimal256, v.scale
+		}
+		return types.FieldTypeInt256, 0
+	case *BigIntAggregator:
+		return types.FieldTypeBigint, 0
+	case *BitmapAggregator:
+		return types.FieldTypeInt64, 0
+	default:
+		return types.FieldTypeFloat64, 0
+	}
+}
+
+func (b *TypedBucket) Emit(buf *bytes.Buffer) error {
+	buf.WriteByte('[')
+	var count int
+	b.Each(func(_ time.Time, val Aggregatable, ok bool) bool {
+		if count > 0 {
+			buf.WriteByte(',')
+		}
+		if ok {
+			val.Emit(buf)
+		} else {
+			buf.Write(null)
+		}
+		count++
+		return true
+	})
+	buf.WriteByte(']')
 	return nil
 }
 
+// valueOf converts aggregated values into the Go type matching the
+// bucket's field type. Unknown aggregators are converted to float64.
+func valueOf(a Aggregatable) any {
+	switch v := a.(type) {
+	case *DecimalAggregator:
+		return v.Decimal()
+	case *Int128Aggregator:
+		if v.scale > 0 {
+			return num.NewDecimal128(v.Int128, v.scale)
+		}
+		return v.Int128
+	case *Int256Aggregator:
+		if v.scale > 0 {
+			return num.NewDecimal256(v.Int256, v.scale)
+		}
+		return v.Int256
+	case *BigIntAggregator:
+		return v.Big
+	case *BitmapAggregator:
+		return int64(v.Count())
+	default:
+		return a.Float64()
+	}
+}
+
 func (b *TypedBucket) readBytes(r engine.QueryRow) (Aggregatable, error) {
 	val := r.Get(b.index)
 	buf, ok := val.([]byte)
@@ -276,3 +386,35 @@ func (b *TypedBucket) readInt256(r engine.QueryRow) (Aggregatable, error) {
 	elem.Init(b.template.Config())
 	return elem, nil
 }
+
+func (b *TypedBucket) readBigint(r engine.QueryRow) (Aggregatable, error) {
+	var elem *BigIntAggregator
+	switch v := r.Get(b.index).(type) {
+	case num.Big:
+		elem = &BigIntAggregator{v, 0}
+	case []byte:
+		elem = &BigIntAggregator{num.NewBigFromBytes(v), 0}
+	default:
+		return nil, fmt.Errorf("invalid value type %T for num.Big", v)
+	}
+	elem.Init(b.template.Config())
+	return elem, nil
+}
+
+func (b *TypedBucket) readDecimal(r engine.QueryRow, scale uint8) (Aggregatable, error) {
+	elem := &DecimalAggregator{scale: scale}
+	switch v := r.Get(b.index).(type) {
+	case int32:
+		elem.val = num.Int256FromInt64(int64(v))
+	case int64:
+		elem.val = num.Int256FromInt64(v)
+	case num.Int128:
+		elem.val = v.Int256()
+	case num.Int256:
+		elem.val = v
+	default:
+		return nil, fmt.Errorf("invalid value type %T for decimal", v)
+	}
+	elem.Init(b.template.Config())
+	return elem, nil
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package reducer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+
+	"blockwatch.cc/knoxdb/internal/engine"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/util"
+)
+
+var ErrReadOnlyBucket = errors.New("read-only bucket")
+
+// ValueBucket holds output values computed elsewhere, for example values
+// of another series aligned to the windows of a result. It does not read
+// or aggregate rows.
+type ValueBucket struct {
+	name  string
+	times []time.Time
+	vals  []any
+	typ   types.FieldType
+	scale uint8
+}
+
+// NewValueBucket returns an empty bucket for values of field type typ.
+// Values must use the Go types returned by Walk of other buckets.
+func NewValueBucket(typ types.FieldType, scale uint8) *ValueBucket {
+	return &ValueBucket{
+		typ:   typ,
+		scale: scale,
+	}
+}
+
+// Append adds value v for the window starting at t. Nil values are null.
+func (b *ValueBucket) Append(t time.Time, v any) {
+	b.times = append(b.times, t)
+	b.vals = append(b.vals, v)
+}
+
+func (b *ValueBucket) WithDimensions(_ util.TimeRange, _ util.TimeUnit) Bucket {
+	return b
+}
+
+func (b *ValueBucket) WithReducer(_ ReducerFunc) Bucket {
+	return b
+}
+
+func (b *ValueBucket) WithName(name string) Bucket {
+	b.name = name
+	return b
+}
+
+func (b *ValueBucket) WithIndex(_ int) Bucket {
+	return b
+}
+
+func (b *ValueBucket) WithFill(_ FillMode) Bucket {
+	return b
+}
+
+func (b *ValueBucket) WithLimit(_ int) Bucket {
+	return b
+}
+
+func (b *ValueBucket) WithType(_ reflect.Type) Bucket {
+	return b
+}
+
+func (b *ValueBucket) WithTypeOf(_ Aggregatable) Bucket {
+	return b
+}
+
+func (b *ValueBucket) WithInit(_ Aggregatable) Bucket {
+	return b
+}
+
+func (b *ValueBucket) Len() int {
+	return len(b.times)
+}
+
+func (b *ValueBucket) Push(_ time.Time, _ engine.QueryRow, _ bool) error {
+	return ErrReadOnlyBucket
+}
+
+func (b *ValueBucket) Remove(t time.Time) {
+	if i := slices.IndexFunc(b.times, t.Equal); i >= 0 {
+		b.times = slices.Delete(b.times, i, i+1)
+		b.vals = slices.Delete(b.vals, i, i+1)
+	}
+}
+
+func (b *ValueBucket) Walk(fn func(time.Time, any) bool) {
+	for i, t := range b.times {
+		if !fn(t, b.vals[i]) {
+			return
+		}
+	}
+}
+
+func (b *ValueBucket) Type() (types.FieldType, uint8) {
+	return b.typ, b.scale
+}
+
+func (b *ValueBucket) Emit(buf *bytes.Buffer) error {
+	buf.WriteByte('[')
+	for i, v := range b.vals {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		emitValue(buf, v)
+	}
+	buf.WriteByte(']')
+	return nil
+}
+
+// emitValue writes v like the bucket type which produced it. Numbers
+// are written as JSON numbers, all other values as JSON strings.
+func emitValue(buf *bytes.Buffer, v any) {
+	switch val := v.(type) {
+	case nil:
+		buf.Write(null)
+	case time.Time:
+		buf.WriteString(strconv.Quote(val.Format(time.RFC3339)))
+	case float64:
+		buf.WriteString(emitFloats(val))
+	case float32:
+		buf.WriteString(emitFloats(val))
+	case int64:
+		buf.WriteString(emitIntegers(val))
+	case int32:
+		buf.WriteString(emitIntegers(val))
+	case int16:
+		buf.WriteString(emitIntegers(val))
+	case int8:
+		buf.WriteString(emitIntegers(val))
+	case uint64:
+		buf.WriteString(emitUnsigneds(val))
+	case uint32:
+		buf.WriteString(emitUnsigneds(val))
+	case uint16:
+		buf.WriteString(emitUnsigneds(val))
+	case uint8:
+		buf.WriteString(emitUnsigneds(val))
+	case Histogram:
+		val.Emit(buf)
+	default:
+		buf.WriteString(strconv.Quote(fmt.Sprint(val)))
+	}
+}
@@ -5,33 +5,52 @@ package reducer
 
 import (
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// FillMode defines how windows without data are output. Besides named
+// modes any number is a valid fill mode which fills a constant value.
 type FillMode string
 
 const (
-	FillModeInvalid FillMode = ""
-	FillModeNone    FillMode = "none"
-	FillModeNull    FillMode = "null"
-	FillModeLast    FillMode = "last"
-	FillModeLinear  FillMode = "linear"
-	FillModeZero    FillMode = "zero"
-	FillModeNow     FillMode = "now"
+	FillModeInvalid  FillMode = ""
+	FillModeNone     FillMode = "none"
+	FillModeNull     FillMode = "null"
+	FillModeLast     FillMode = "last"
+	FillModePrevious FillMode = "previous"
+	FillModeNext     FillMode = "next"
+	FillModeLinear   FillMode = "linear"
+	FillModeZero     FillMode = "zero"
+	FillModeNow      FillMode = "now"
 )
 
 func ParseFillMode(s string) FillMode {
 	switch m := FillMode(strings.ToLower(s)); m {
-	case FillModeNone, FillModeNull, FillModeLast, FillModeLinear, FillModeZero:
+	case FillModeNone, FillModeNull, FillModeLast, FillModePrevious, FillModeNext,
+		FillModeLinear, FillModeZero:
 		return m
 	case "":
 		return FillModeNone
 	default:
+		if _, ok := m.Constant(); ok {
+			return m
+		}
 		return FillModeInvalid
 	}
 }
 
+// Constant returns the value of constant fill modes.
+func (m FillMode) Constant() (float64, bool) {
+	v, err := strconv.ParseFloat(string(m), 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
+
 func (m FillMode) IsValid() bool {
 	return m != FillModeInvalid
 }
@@ -57,12 +76,18 @@ func (m *FillMode) UnmarshalText(data []byte) error {
 // and (nextTime, nextValue) and returns the value of the point on the line with time
 // windowTime where y = mx + b
 func linearFill[T Number](windowTime, previousTime, nextTime int64, previousValue, nextValue T) T {
-	m := float64(nextValue-previousValue) / float64(nextTime-previousTime) // the slope of the line
-	x := float64(windowTime - previousTime)                                // how far into the interval we are
+	m := (float64(nextValue) - float64(previousValue)) / float64(nextTime-previousTime) // the slope of the line
+	x := float64(windowTime - previousTime)                                             // how far into the interval we are
 	b := float64(previousValue)
 	return T(m*x + b)
 }
 
+// Fill returns the fill value for the empty window starting at now between
+// the windows at prev and next and whether a value is output at all and
+// whether it is null. Before the first window prev equals next and after
+// the last window next equals prev. Modes which require a missing
+// neighbour output null, except last which extends the first value to
+// earlier windows.
 func Fill[T Number](mode FillMode, now, prev, next time.Time, preval, nextval T) (T, bool, bool) {
 	switch mode {
 	case FillModeNone:
@@ -71,7 +96,20 @@ func Fill[T Number](mode FillMode, now, prev, next time.Time, preval, nextval T)
 		return 0, true, true
 	case FillModeLast:
 		return preval, true, false
+	case FillModePrevious:
+		if prev.After(now) {
+			return 0, true, true
+		}
+		return preval, true, false
+	case FillModeNext:
+		if next.Before(now) {
+			return 0, true, true
+		}
+		return nextval, true, false
 	case FillModeLinear:
+		if !prev.Before(now) || !next.After(now) {
+			return 0, true, true
+		}
 		return linearFill(now.Unix(), prev.Unix(), next.Unix(), preval, nextval), true, false
 	case FillModeZero:
 		return 0, true, false
@@ -79,6 +117,9 @@ func Fill[T Number](mode FillMode, now, prev, next time.Time, preval, nextval T)
 		// used for time column only!
 		return T(now.UnixNano()), true, false
 	default:
+		if v, ok := mode.Constant(); ok {
+			return T(v), true, false
+		}
 		// none
 		return 0, false, false
 	}
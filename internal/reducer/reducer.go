@@ -10,6 +10,8 @@ import (
 	"math"
 	"strings"
 	"time"
+
+	"blockwatch.cc/knoxdb/internal/hash"
 )
 
 type Number interface {
@@ -34,6 +36,22 @@ const (
 	ReducerFuncStd     ReducerFunc = "std"
 	ReducerFuncCount   ReducerFunc = "count"
 
+	// order statistics, exact up to ExactQuantileLimit values per window
+	ReducerFuncMedian ReducerFunc = "median"
+	ReducerFuncP50    ReducerFunc = "p50"
+	ReducerFuncP90    ReducerFunc = "p90"
+	ReducerFuncP99    ReducerFunc = "p99"
+	ReducerFuncMode   ReducerFunc = "mode"
+
+	// distinct values, exact up to ExactDistinctLimit values per window
+	ReducerFuncCountDistinct ReducerFunc = "count_distinct"
+
+	// value distribution, requires a HistBucket
+	ReducerFuncHist ReducerFunc = "hist"
+
+	// time-weighted average, requires a TWABucket
+	ReducerFuncTWA ReducerFunc = "twa"
+
 	// sum same timestamp items, then apply reducer
 	ReducerFuncFirstJoin ReducerFunc = "first_join"
 	ReducerFuncLastJoin  ReducerFunc = "last_join"
@@ -42,10 +60,6 @@ const (
 	ReducerFuncMeanJoin  ReducerFunc = "mean_join"
 	ReducerFuncVarJoin   ReducerFunc = "var_join"
 	ReducerFuncStdJoin   ReducerFunc = "std_join"
-
-	// ReducerFuncHist    ReducerFunc = "hist"
-	// ReducerFuncMode = "mode"
-	// ReducerFuncMedian = "median"
 )
 
 func ParseReducerFunc(s string) ReducerFunc {
@@ -57,8 +71,10 @@ func ParseReducerFunc(s string) ReducerFunc {
 	case ReducerFuncFirstJoin, ReducerFuncLastJoin, ReducerFuncMinJoin, ReducerFuncMaxJoin,
 		ReducerFuncMeanJoin, ReducerFuncVarJoin, ReducerFuncStdJoin:
 		return f
-	// case ReducerFuncHist:
-	// 	return f
+
+	case ReducerFuncMedian, ReducerFuncP50, ReducerFuncP90, ReducerFuncP99,
+		ReducerFuncMode, ReducerFuncCountDistinct, ReducerFuncHist, ReducerFuncTWA:
+		return f
 	default:
 		return ReducerFuncInvalid
 	}
@@ -68,6 +84,20 @@ func (f ReducerFunc) IsValid() bool {
 	return f != ReducerFuncInvalid
 }
 
+// Quantile returns the quantile computed by order statistic reducers.
+func (f ReducerFunc) Quantile() (float64, bool) {
+	switch f {
+	case ReducerFuncMedian, ReducerFuncP50:
+		return 0.5, true
+	case ReducerFuncP90:
+		return 0.9, true
+	case ReducerFuncP99:
+		return 0.99, true
+	default:
+		return 0, false
+	}
+}
+
 func (f ReducerFunc) String() string {
 	return string(f)
 }
@@ -119,8 +149,17 @@ func NewReducer[T Number](fn ReducerFunc) Reducer[T] {
 		return &VarJoinReducer[T]{}
 	case ReducerFuncStdJoin:
 		return &StdJoinReducer[T]{}
-	// case ReducerFuncHist:
-	// 	return &HistReducer[T]{}
+	case ReducerFuncMedian, ReducerFuncP50, ReducerFuncP90, ReducerFuncP99:
+		q, _ := fn.Quantile()
+		return &QuantileReducer[T]{fn: fn, q: q}
+	case ReducerFuncMode:
+		return &ModeReducer[T]{}
+	case ReducerFuncCountDistinct:
+		return &CountDistinctReducer[T]{}
+	case ReducerFuncHist:
+		return &HistReducer[T]{}
+	case ReducerFuncTWA:
+		return &TWAReducer[T]{}
 	default:
 		return nil
 	}
@@ -134,6 +173,12 @@ type Reducer[T Number] interface {
 	Type() ReducerFunc
 }
 
+// Sampler is implemented by reducers which depend on the exact sample
+// time of values inside a window.
+type Sampler[T Number] interface {
+	Sample(val T, window, at time.Time)
+}
+
 // COUNT
 type CountReducer[T Number] struct {
 	t time.Time
@@ -730,33 +775,230 @@ func (r *StdJoinReducer[T]) Type() ReducerFunc {
 	return ReducerFuncStdJoin
 }
 
+// QUANTILE
+type QuantileReducer[T Number] struct {
+	t  time.Time
+	fn ReducerFunc
+	q  float64
+	s  quantileSketch
+}
+
+func (r *QuantileReducer[T]) Reduce(v T, t time.Time, _ bool) {
+	if r.t.IsZero() {
+		r.t = t
+	}
+	r.s.Add(float64(v))
+}
+
+func (r *QuantileReducer[T]) Reset() {
+	r.t = time.Time{}
+	r.s.Reset()
+}
+
+func (r *QuantileReducer[T]) Value() (T, bool) {
+	if r.s.Len() == 0 {
+		return 0, false
+	}
+	return T(r.s.Quantile(r.q)), true
+}
+
+func (r *QuantileReducer[T]) Time() time.Time {
+	return r.t
+}
+
+func (r *QuantileReducer[T]) Type() ReducerFunc {
+	return r.fn
+}
+
+// MODE
+// Returns the most frequent value, the smallest value on ties.
+type ModeReducer[T Number] struct {
+	t time.Time
+	n map[T]int
+}
+
+func (r *ModeReducer[T]) Reduce(v T, t time.Time, _ bool) {
+	if r.t.IsZero() {
+		r.t = t
+		r.n = make(map[T]int)
+	}
+	r.n[v]++
+}
+
+func (r *ModeReducer[T]) Reset() {
+	r.t = time.Time{}
+	r.n = nil
+}
+
+func (r *ModeReducer[T]) Value() (T, bool) {
+	var (
+		val T
+		cnt int
+	)
+	for v, n := range r.n {
+		if n > cnt || (n == cnt && v < val) {
+			val, cnt = v, n
+		}
+	}
+	return val, cnt > 0
+}
+
+func (r *ModeReducer[T]) Time() time.Time {
+	return r.t
+}
+
+func (r *ModeReducer[T]) Type() ReducerFunc {
+	return ReducerFuncMode
+}
+
+// COUNT DISTINCT
+type CountDistinctReducer[T Number] struct {
+	t time.Time
+	s distinctSketch
+}
+
+func (r *CountDistinctReducer[T]) Reduce(v T, t time.Time, _ bool) {
+	if r.t.IsZero() {
+		r.t = t
+	}
+	r.s.Add(hashOf(v))
+}
+
+func (r *CountDistinctReducer[T]) Reset() {
+	r.t = time.Time{}
+	r.s.Reset()
+}
+
+func (r *CountDistinctReducer[T]) Value() (T, bool) {
+	return T(r.s.Count()), !r.t.IsZero()
+}
+
+func (r *CountDistinctReducer[T]) Time() time.Time {
+	return r.t
+}
+
+func (r *CountDistinctReducer[T]) Type() ReducerFunc {
+	return ReducerFuncCountDistinct
+}
+
+// hashOf hashes the binary value of v, floats by their bit pattern.
+func hashOf[T Number](v T) uint64 {
+	switch x := any(v).(type) {
+	case float64:
+		return hash.Float64(x)
+	case float32:
+		return hash.Float32(x)
+	default:
+		return hash.Uint64(uint64(v))
+	}
+}
+
 // HIST
-// type HistReducer[T Number] struct {
-// 	t time.Time
-// 	v Histogram[T]
-// }
-
-// func (r *HistReducer[T]) Reduce(v T, t time.Time) {
-// 	if r.t.IsZero() {
-// 		r.t = t
-// 	}
-// 	// TODO: need `v` and `fn(t)` as histogram inputs
-// 	r.v.Add(v)
-// }
-
-// func (r *HistReducer[T]) Reset() {
-// 	r.t = time.Time{}
-// 	r.v.Reset()
-// }
-
-// func (r *HistReducer[T]) Value() (T, bool) {
-// 	return r.v, !r.t.IsZero()
-// }
-
-// func (r *HistReducer[T]) Time() time.Time {
-// 	return r.t
-// }
-
-// func (r *HistReducer[T]) Type() ReducerFunc {
-// 	return ReducerFuncHist
-// }
+// Value returns the number of values, use Histogram for the distribution.
+type HistReducer[T Number] struct {
+	t time.Time
+	s quantileSketch
+}
+
+func (r *HistReducer[T]) Reduce(v T, t time.Time, _ bool) {
+	if r.t.IsZero() {
+		r.t = t
+	}
+	r.s.Add(float64(v))
+}
+
+func (r *HistReducer[T]) Reset() {
+	r.t = time.Time{}
+	r.s.Reset()
+}
+
+func (r *HistReducer[T]) Value() (T, bool) {
+	return T(r.s.Len()), !r.t.IsZero()
+}
+
+func (r *HistReducer[T]) Histogram(bins int) Histogram {
+	return r.s.Histogram(bins)
+}
+
+func (r *HistReducer[T]) Time() time.Time {
+	return r.t
+}
+
+func (r *HistReducer[T]) Type() ReducerFunc {
+	return ReducerFuncHist
+}
+
+// TWA
+// Time-weighted average of the step function defined by samples where each
+// sample holds until the next sample. The last sample of an earlier window
+// holds until the first sample of a window, so averages span window
+// boundaries. The last sample holds until the window end. Samples must
+// arrive in time order.
+type TWAReducer[T Number] struct {
+	t     time.Time // window start
+	end   time.Time // window end
+	first time.Time // first sample time
+	last  time.Time // last sample time
+	val   float64   // last sample value
+	area  float64   // integral between first and last sample
+	prev  float64   // last sample value of earlier windows
+	carry bool      // prev is valid
+	n     int
+}
+
+func (r *TWAReducer[T]) Reduce(v T, t time.Time, _ bool) {
+	r.Sample(v, t, t)
+}
+
+func (r *TWAReducer[T]) Sample(v T, window, at time.Time) {
+	if r.n == 0 {
+		r.t = window
+		r.first = at
+	} else {
+		r.area += r.val * at.Sub(r.last).Seconds()
+	}
+	r.last = at
+	r.val = float64(v)
+	r.n++
+}
+
+// Carry sets the window end and the value carried over from the previous
+// window. Call before Value.
+func (r *TWAReducer[T]) Carry(end time.Time, prev *TWAReducer[T]) {
+	r.end = end
+	r.carry = prev != nil && prev.n > 0
+	if r.carry {
+		r.prev = prev.val
+	}
+}
+
+func (r *TWAReducer[T]) Reset() {
+	*r = TWAReducer[T]{}
+}
+
+func (r *TWAReducer[T]) Value() (T, bool) {
+	if r.n == 0 {
+		return 0, false
+	}
+	from, end, area := r.first, r.end, r.area
+	if r.carry {
+		area += r.prev * r.first.Sub(r.t).Seconds()
+		from = r.t
+	}
+	if end.Before(r.last) {
+		end = r.last
+	}
+	area += r.val * end.Sub(r.last).Seconds()
+	if d := end.Sub(from).Seconds(); d > 0 {
+		return T(area / d), true
+	}
+	return T(r.val), true
+}
+
+func (r *TWAReducer[T]) Time() time.Time {
+	return r.t
+}
+
+func (r *TWAReducer[T]) Type() ReducerFunc {
+	return ReducerFuncTWA
+}
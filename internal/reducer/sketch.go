@@ -0,0 +1,352 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package reducer
+
+import (
+	"bytes"
+	"math"
+	"slices"
+	"strconv"
+
+	"blockwatch.cc/knoxdb/internal/filter/llb"
+)
+
+const (
+	// ExactQuantileLimit is the number of values per window up to which
+	// quantiles and histograms are exact. Larger windows are compressed
+	// into a t-digest.
+	ExactQuantileLimit = 1 << 10
+
+	// ExactDistinctLimit is the number of distinct values per window up
+	// to which distinct counts are exact. Larger windows are estimated
+	// with a LogLog-Beta sketch.
+	ExactDistinctLimit = 1 << 10
+
+	// DefaultHistogramBins is the number of equal width bins per window.
+	DefaultHistogramBins = 10
+
+	tdigestCompression = 100
+	distinctPrecision  = 12
+)
+
+// quantileSketch collects values for quantile and histogram estimation.
+type quantileSketch struct {
+	vals   []float64
+	sorted bool
+	digest *tdigest
+}
+
+func (s *quantileSketch) Add(v float64) {
+	if math.IsNaN(v) {
+		return
+	}
+	if s.digest != nil {
+		s.digest.Add(v)
+		return
+	}
+	s.vals = append(s.vals, v)
+	s.sorted = false
+	if len(s.vals) > ExactQuantileLimit {
+		s.digest = newTDigest(tdigestCompression)
+		for _, v := range s.vals {
+			s.digest.Add(v)
+		}
+		s.vals = nil
+	}
+}
+
+func (s *quantileSketch) Reset() {
+	s.vals = s.vals[:0]
+	s.sorted = false
+	s.digest = nil
+}
+
+func (s *quantileSketch) Len() int {
+	if s.digest != nil {
+		return int(s.digest.count)
+	}
+	return len(s.vals)
+}
+
+func (s *quantileSketch) sort() {
+	if !s.sorted {
+		slices.Sort(s.vals)
+		s.sorted = true
+	}
+}
+
+// Quantile returns the q-quantile of all values. Exact quantiles linearly
+// interpolate between the two closest ranks.
+func (s *quantileSketch) Quantile(q float64) float64 {
+	if s.digest != nil {
+		return s.digest.Quantile(q)
+	}
+	n := len(s.vals)
+	if n == 0 {
+		return math.NaN()
+	}
+	s.sort()
+	h := q * float64(n-1)
+	lo := int(math.Floor(h))
+	if lo >= n-1 {
+		return s.vals[n-1]
+	}
+	return s.vals[lo] + (h-float64(lo))*(s.vals[lo+1]-s.vals[lo])
+}
+
+// Histogram returns counts of values in n equal width bins between the
+// smallest and largest value. The last bin includes the largest value.
+func (s *quantileSketch) Histogram(n int) Histogram {
+	h := Histogram{Counts: make([]int64, n)}
+	if s.Len() == 0 || n == 0 {
+		return h
+	}
+	if s.digest != nil {
+		h.Min, h.Max = s.digest.min, s.digest.max
+	} else {
+		s.sort()
+		h.Min, h.Max = s.vals[0], s.vals[len(s.vals)-1]
+	}
+	width := (h.Max - h.Min) / float64(n)
+	if width == 0 {
+		h.Counts[0] = int64(s.Len())
+		return h
+	}
+
+	// exact counts
+	if s.digest == nil {
+		for _, v := range s.vals {
+			h.Counts[min(int((v-h.Min)/width), n-1)]++
+		}
+		return h
+	}
+
+	// estimate counts from rounded cumulative weights so that counts
+	// sum up to the number of values
+	var last int64
+	for i := range n - 1 {
+		next := int64(math.Round(s.digest.CDF(h.Min+float64(i+1)*width) * s.digest.count))
+		h.Counts[i] = next - last
+		last = next
+	}
+	h.Counts[n-1] = int64(s.digest.count) - last
+	return h
+}
+
+// Histogram is the distribution of values inside a window. Bins have
+// equal width (Max-Min)/len(Counts).
+type Histogram struct {
+	Min    float64
+	Max    float64
+	Counts []int64
+}
+
+func (h Histogram) String() string {
+	var buf bytes.Buffer
+	h.Emit(&buf)
+	return buf.String()
+}
+
+func (h Histogram) Emit(buf *bytes.Buffer) error {
+	buf.WriteString(`{"min":`)
+	buf.WriteString(strconv.FormatFloat(h.Min, 'f', -1, 64))
+	buf.WriteString(`,"max":`)
+	buf.WriteString(strconv.FormatFloat(h.Max, 'f', -1, 64))
+	buf.WriteString(`,"counts":[`)
+	for i, v := range h.Counts {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(strconv.FormatInt(v, 10))
+	}
+	buf.WriteString(`]}`)
+	return nil
+}
+
+// distinctSketch counts distinct value hashes, exact up to
+// ExactDistinctLimit hashes.
+type distinctSketch struct {
+	set map[uint64]struct{}
+	llb *llb.LogLogBeta
+}
+
+func (s *distinctSketch) Add(h uint64) {
+	if s.llb != nil {
+		s.llb.Add(h)
+		return
+	}
+	if s.set == nil {
+		s.set = make(map[uint64]struct{})
+	}
+	s.set[h] = struct{}{}
+	if len(s.set) > ExactDistinctLimit {
+		s.llb = llb.NewFilterWithPrecision(distinctPrecision)
+		for h := range s.set {
+			s.llb.Add(h)
+		}
+		s.set = nil
+	}
+}
+
+func (s *distinctSketch) Reset() {
+	s.set = nil
+	s.llb = nil
+}
+
+func (s *distinctSketch) Count() int64 {
+	if s.llb != nil {
+		return int64(s.llb.Cardinality())
+	}
+	return int64(len(s.set))
+}
+
+// tdigest is a merging t-digest for streaming quantile estimation with
+// bounded memory, see https://arxiv.org/abs/1902.04023. Centroid sizes
+// follow the k1 scale function which keeps tails accurate.
+type tdigest struct {
+	compression float64
+	centroids   []centroid // merged, sorted by mean
+	buf         []centroid // unmerged
+	count       float64    // total weight
+	min         float64
+	max         float64
+}
+
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+func newTDigest(compression float64) *tdigest {
+	return &tdigest{
+		compression: compression,
+		buf:         make([]centroid, 0, 5*int(compression)),
+		min:         math.Inf(1),
+		max:         math.Inf(-1),
+	}
+}
+
+func (d *tdigest) Add(v float64) {
+	d.buf = append(d.buf, centroid{v, 1})
+	d.count++
+	d.min = min(d.min, v)
+	d.max = max(d.max, v)
+	if len(d.buf) == cap(d.buf) {
+		d.compress()
+	}
+}
+
+// k maps quantile q to the k1 scale.
+func (d *tdigest) k(q float64) float64 {
+	return d.compression / (2 * math.Pi) * math.Asin(2*q-1)
+}
+
+// q maps scale k back to a quantile.
+func (d *tdigest) q(k float64) float64 {
+	if k >= d.compression/4 {
+		return 1
+	}
+	return (math.Sin(k*2*math.Pi/d.compression) + 1) / 2
+}
+
+func (d *tdigest) compress() {
+	if len(d.buf) == 0 {
+		return
+	}
+	all := append(d.centroids, d.buf...)
+	slices.SortFunc(all, func(a, b centroid) int {
+		switch {
+		case a.mean < b.mean:
+			return -1
+		case a.mean > b.mean:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	// merge neighbours while the merged centroid spans at most one
+	// unit on the k scale, merged output never overtakes the input
+	var (
+		res   = all[:0]
+		cur   = all[0]
+		done  float64
+		limit = d.q(d.k(0) + 1)
+	)
+	for _, c := range all[1:] {
+		if (done+cur.weight+c.weight)/d.count <= limit {
+			cur.weight += c.weight
+			cur.mean += (c.mean - cur.mean) * c.weight / cur.weight
+			continue
+		}
+		done += cur.weight
+		res = append(res, cur)
+		limit = d.q(d.k(done/d.count) + 1)
+		cur = c
+	}
+	d.centroids = append(res, cur)
+	d.buf = d.buf[:0]
+}
+
+// Quantile interpolates between centroid means, each centroid's weight is
+// centered at its mean. The tails interpolate towards min and max.
+func (d *tdigest) Quantile(q float64) float64 {
+	d.compress()
+	c := d.centroids
+	switch {
+	case len(c) == 0:
+		return math.NaN()
+	case q <= 0:
+		return d.min
+	case q >= 1:
+		return d.max
+	case len(c) == 1:
+		return c[0].mean
+	}
+
+	idx := q * d.count
+	if w := c[0].weight / 2; idx < w {
+		return d.min + (c[0].mean-d.min)*idx/w
+	}
+	done := c[0].weight / 2
+	for i := range len(c) - 1 {
+		dw := (c[i].weight + c[i+1].weight) / 2
+		if done+dw > idx {
+			return c[i].mean + (c[i+1].mean-c[i].mean)*(idx-done)/dw
+		}
+		done += dw
+	}
+	last := c[len(c)-1]
+	if w := last.weight / 2; w > 0 {
+		return last.mean + (d.max-last.mean)*min(idx-done, w)/w
+	}
+	return last.mean
+}
+
+// CDF returns the estimated fraction of values less or equal to v.
+func (d *tdigest) CDF(v float64) float64 {
+	d.compress()
+	c := d.centroids
+	switch {
+	case len(c) == 0:
+		return math.NaN()
+	case v < d.min:
+		return 0
+	case v >= d.max:
+		return 1
+	}
+	if v < c[0].mean {
+		return c[0].weight / 2 * (v - d.min) / (c[0].mean - d.min) / d.count
+	}
+	done := c[0].weight / 2
+	for i := range len(c) - 1 {
+		dw := (c[i].weight + c[i+1].weight) / 2
+		if v < c[i+1].mean {
+			return (done + dw*(v-c[i].mean)/(c[i+1].mean-c[i].mean)) / d.count
+		}
+		done += dw
+	}
+	last := c[len(c)-1]
+	return (done + last.weight/2*(v-last.mean)/(d.max-last.mean)) / d.count
+}
@@ -4,9 +4,12 @@
 package reducer
 
 import (
+	"bytes"
 	"math"
 	"reflect"
 	"time"
+
+	"blockwatch.cc/knoxdb/internal/hash"
 )
 
 func NewTypedReducer(typ reflect.Type, fn ReducerFunc) TypedReducer {
@@ -28,6 +31,15 @@ func NewTypedReducer(typ reflect.Type, fn ReducerFunc) TypedReducer {
 		return &TypedVarReducer{v: val}
 	case ReducerFuncStd:
 		return &TypedStdReducer{v: val}
+	case ReducerFuncCount:
+		return &TypedCountReducer{v: val}
+	case ReducerFuncMedian, ReducerFuncP50, ReducerFuncP90, ReducerFuncP99:
+		q, _ := fn.Quantile()
+		return &TypedQuantileReducer{fn: fn, q: q, v: val}
+	case ReducerFuncMode:
+		return &TypedModeReducer{v: val}
+	case ReducerFuncCountDistinct:
+		return &TypedCountDistinctReducer{v: val}
 	case ReducerFuncFirstJoin:
 		return &TypedFirstJoinReducer{v: val}
 	case ReducerFuncLastJoin:
@@ -253,10 +265,13 @@ func (r *TypedMinReducer) Type() ReducerFunc {
 // MEAN
 // Welford's Online algorithm, see
 // https://en.wikipedia.org/wiki/Algorithms_for_calculating_variance
+//
+// Divisible types compute an exact mean from their sum instead.
 type TypedMeanReducer struct {
 	t    time.Time
 	n    int
 	mean float64
+	sum  Aggregatable
 	v    Aggregatable
 }
 
@@ -265,6 +280,13 @@ func (r *TypedMeanReducer) Reduce(v Aggregatable, t time.Time, _ bool) {
 		r.t = t
 	}
 	r.n++
+	if _, ok := v.(Divisible); ok {
+		if r.sum == nil {
+			r.sum = r.v.Zero()
+		}
+		r.sum = r.sum.Add(v)
+		return
+	}
 	delta := v.Float64() - r.mean
 	r.mean += delta / float64(r.n)
 }
@@ -272,6 +294,7 @@ func (r *TypedMeanReducer) Reduce(v Aggregatable, t time.Time, _ bool) {
 func (r *TypedMeanReducer) Reset() {
 	r.t = time.Time{}
 	r.mean = 0
+	r.sum = nil
 	r.n = 0
 }
 
@@ -284,6 +307,9 @@ func (r *TypedMeanReducer) Config() Aggregatable {
 }
 
 func (r *TypedMeanReducer) Value() (Aggregatable, bool) {
+	if d, ok := r.sum.(Divisible); ok {
+		return d.Quo(r.n), r.n > 0
+	}
 	v := r.v.Zero()
 	v.SetFloat64(r.mean)
 	return v, r.n > 0
@@ -401,6 +427,201 @@ func (r *TypedStdReducer) Type() ReducerFunc {
 	return ReducerFuncStd
 }
 
+// COUNT
+// Counts are output as integers independent of the value type.
+type TypedCountReducer struct {
+	t time.Time
+	n int
+	v Aggregatable
+}
+
+func (r *TypedCountReducer) Reduce(_ Aggregatable, t time.Time, _ bool) {
+	if r.t.IsZero() {
+		r.t = t
+	}
+	r.n++
+}
+
+func (r *TypedCountReducer) Reset() {
+	r.t = time.Time{}
+	r.n = 0
+}
+
+func (r *TypedCountReducer) Init(v Aggregatable) {
+	r.v = v
+}
+
+func (r *TypedCountReducer) Config() Aggregatable {
+	return r.v
+}
+
+func (r *TypedCountReducer) Value() (Aggregatable, bool) {
+	return &BitmapAggregator{count: r.n}, !r.t.IsZero()
+}
+
+func (r *TypedCountReducer) Time() time.Time {
+	return r.t
+}
+
+func (r *TypedCountReducer) Type() ReducerFunc {
+	return ReducerFuncCount
+}
+
+// QUANTILE
+// Quantiles are estimated from float64 values.
+type TypedQuantileReducer struct {
+	t  time.Time
+	fn ReducerFunc
+	q  float64
+	s  quantileSketch
+	v  Aggregatable
+}
+
+func (r *TypedQuantileReducer) Reduce(v Aggregatable, t time.Time, _ bool) {
+	if r.t.IsZero() {
+		r.t = t
+	}
+	r.s.Add(v.Float64())
+}
+
+func (r *TypedQuantileReducer) Reset() {
+	r.t = time.Time{}
+	r.s.Reset()
+}
+
+func (r *TypedQuantileReducer) Init(v Aggregatable) {
+	r.v = v
+}
+
+func (r *TypedQuantileReducer) Config() Aggregatable {
+	return r.v
+}
+
+func (r *TypedQuantileReducer) Value() (Aggregatable, bool) {
+	v := r.v.Zero()
+	if r.s.Len() == 0 {
+		return v, false
+	}
+	v.SetFloat64(r.s.Quantile(r.q))
+	return v, true
+}
+
+func (r *TypedQuantileReducer) Time() time.Time {
+	return r.t
+}
+
+func (r *TypedQuantileReducer) Type() ReducerFunc {
+	return r.fn
+}
+
+// MODE
+// Values are equal when their encodings are equal. Returns the smallest
+// value on ties.
+type TypedModeReducer struct {
+	t    time.Time
+	n    map[string]int
+	vals map[string]Aggregatable
+	v    Aggregatable
+}
+
+func (r *TypedModeReducer) Reduce(v Aggregatable, t time.Time, _ bool) {
+	if r.t.IsZero() {
+		r.t = t
+		r.n = make(map[string]int)
+		r.vals = make(map[string]Aggregatable)
+	}
+	var buf bytes.Buffer
+	v.Emit(&buf)
+	key := buf.String()
+	if _, ok := r.vals[key]; !ok {
+		r.vals[key] = v
+	}
+	r.n[key]++
+}
+
+func (r *TypedModeReducer) Reset() {
+	r.t = time.Time{}
+	r.n = nil
+	r.vals = nil
+}
+
+func (r *TypedModeReducer) Init(v Aggregatable) {
+	r.v = v
+}
+
+func (r *TypedModeReducer) Config() Aggregatable {
+	return r.v
+}
+
+func (r *TypedModeReducer) Value() (Aggregatable, bool) {
+	var (
+		val Aggregatable
+		cnt int
+	)
+	for key, n := range r.n {
+		v := r.vals[key]
+		if n > cnt || (n == cnt && v.Cmp(val) < 0) {
+			val, cnt = v, n
+		}
+	}
+	if val == nil {
+		return r.v.Zero(), false
+	}
+	val.Init(r.v)
+	return val, true
+}
+
+func (r *TypedModeReducer) Time() time.Time {
+	return r.t
+}
+
+func (r *TypedModeReducer) Type() ReducerFunc {
+	return ReducerFuncMode
+}
+
+// COUNT DISTINCT
+// Values are distinct when their encodings differ. Counts are output as
+// integers independent of the value type.
+type TypedCountDistinctReducer struct {
+	t time.Time
+	s distinctSketch
+	v Aggregatable
+}
+
+func (r *TypedCountDistinctReducer) Reduce(v Aggregatable, t time.Time, _ bool) {
+	if r.t.IsZero() {
+		r.t = t
+	}
+	var buf bytes.Buffer
+	v.Emit(&buf)
+	r.s.Add(hash.Hash(buf.Bytes()))
+}
+
+func (r *TypedCountDistinctReducer) Reset() {
+	r.t = time.Time{}
+	r.s.Reset()
+}
+
+func (r *TypedCountDistinctReducer) Init(v Aggregatable) {
+	r.v = v
+}
+
+func (r *TypedCountDistinctReducer) Config() Aggregatable {
+	return r.v
+}
+
+func (r *TypedCountDistinctReducer) Value() (Aggregatable, bool) {
+	return &BitmapAggregator{count: int(r.s.Count())}, !r.t.IsZero()
+}
+
+func (r *TypedCountDistinctReducer) Time() time.Time {
+	return r.t
+}
+
+func (r *TypedCountDistinctReducer) Type() ReducerFunc {
+	return ReducerFuncCountDistinct
+}
+
 // FIRST JOIN
 type TypedFirstJoinReducer struct {
 	t    time.Time
@@ -603,10 +824,13 @@ func (r *TypedMinJoinReducer) Type() ReducerFunc {
 // MEAN JOIN
 // Welford's Online algorithm, see
 // https://en.wikipedia.org/wiki/Algorithms_for_calculating_variance
+//
+// Divisible types compute an exact mean from their sum instead.
 type TypedMeanJoinReducer struct {
 	t    time.Time
 	n    int
 	mean float64
+	sum  Aggregatable
 	v    Aggregatable
 	j    Aggregatable
 }
@@ -622,8 +846,15 @@ func (r *TypedMeanJoinReducer) Reduce(v Aggregatable, t time.Time, join bool) {
 	} else {
 		r.n++
 		r.j.Init(r.v)
-		delta := r.j.Float64() - r.mean
-		r.mean += delta / float64(r.n)
+		if _, ok := r.j.(Divisible); ok {
+			if r.sum == nil {
+				r.sum = r.v.Zero()
+			}
+			r.sum = r.sum.Add(r.j)
+		} else {
+			delta := r.j.Float64() - r.mean
+			r.mean += delta / float64(r.n)
+		}
 		r.j = v
 	}
 }
@@ -631,6 +862,7 @@ func (r *TypedMeanJoinReducer) Reduce(v Aggregatable, t time.Time, join bool) {
 func (r *TypedMeanJoinReducer) Reset() {
 	r.t = time.Time{}
 	r.mean = 0
+	r.sum = nil
 	r.n = 0
 	r.v = r.v.Zero()
 	r.j = r.j.Zero()
@@ -647,6 +879,9 @@ func (r *TypedMeanJoinReducer) Config() Aggregatable {
 
 func (r *TypedMeanJoinReducer) Value() (Aggregatable, bool) {
 	r.Reduce(r.v.Zero(), r.t, false)
+	if d, ok := r.sum.(Divisible); ok {
+		return d.Quo(r.n), r.n > 0
+	}
 	v := r.v.Zero()
 	v.SetFloat64(r.mean)
 	return v, r.n > 0
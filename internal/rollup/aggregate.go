@@ -0,0 +1,278 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package rollup
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"blockwatch.cc/knoxdb/internal/block"
+	"blockwatch.cc/knoxdb/internal/pack"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/schema"
+)
+
+// Aggregate computes partial aggregates of all selected rows in src and
+// returns them in a new package with rollup table schema dst, one row per
+// window and group in window order. Negative partials of deleted rows
+// subtract counts and sums, minimum and maximum partials are neutral.
+// Metadata blocks are left empty for the caller to assign. Also returns
+// the largest $xmin of all aggregated rows. Returns a nil package when
+// src has no selected rows.
+func (r *Rollup) Aggregate(src *pack.Package, dst *schema.Schema, neg bool) (*pack.Package, types.XID, error) {
+	n := src.NumSelected()
+	if n == 0 {
+		return nil, 0, nil
+	}
+	ss := src.Schema()
+
+	// bind source blocks
+	ti, ok := ss.Index(r.Time)
+	if !ok {
+		return nil, 0, fmt.Errorf("%w %q", ErrNoTimebase, r.Time)
+	}
+	scale := schema.TimeScale(ss.Fields[ti].Scale)
+	times := block.GetAccessor[int64](src.Block(ti))
+	var groups *block.Block
+	if r.GroupBy != "" {
+		gi, ok := ss.Index(r.GroupBy)
+		if !ok {
+			return nil, 0, fmt.Errorf("%w %q", ErrInvalidField, r.GroupBy)
+		}
+		groups = src.Block(gi)
+	}
+	accs := make([]accumulator, len(r.Aggs))
+	for i, a := range r.Aggs {
+		ai, ok := ss.Index(a.Field)
+		if !ok {
+			return nil, 0, fmt.Errorf("%w %q", ErrInvalidField, a.Field)
+		}
+		acc := newAccumulator(src.Block(ai), a.Func, neg)
+		if acc == nil {
+			return nil, 0, fmt.Errorf("%w %q: cannot aggregate type %s", ErrInvalidField, a.Field, ss.Fields[ai].Type)
+		}
+		accs[i] = acc
+	}
+	var xmins types.NumberAccessor[uint64]
+	if src.HasMeta() {
+		xmins = src.Xmins()
+	}
+
+	// assign rows to slots, cache the current window bounds because
+	// source rows are usually sorted by time
+	type key struct {
+		window int64
+		group  any
+	}
+	var (
+		slots        = make(map[key]int)
+		keys         = make([]key, 0)
+		counts       = make([]int64, 0)
+		lo, hi int64 = 1, 0
+		maxXid types.XID
+		delta  int64 = 1
+	)
+	if neg {
+		delta = -1
+	}
+	visit := func(row int) {
+		ts := times.Get(row)
+		if ts < lo || ts >= hi {
+			start := r.Interval.Truncate(scale.FromUnix(ts))
+			lo, hi = scale.ToUnix(start), scale.ToUnix(r.Interval.Next(start, 1))
+		}
+		k := key{window: lo}
+		if groups != nil {
+			k.group = groups.Get(row)
+			if b, ok := k.group.([]byte); ok {
+				k.group = string(b)
+			}
+		}
+		slot, ok := slots[k]
+		if !ok {
+			slot = len(keys)
+			slots[k] = slot
+			keys = append(keys, k)
+			counts = append(counts, 0)
+			for _, acc := range accs {
+				acc.grow()
+			}
+		}
+		counts[slot] += delta
+		for _, acc := range accs {
+			acc.add(row, slot)
+		}
+		if xmins != nil {
+			maxXid = max(maxXid, types.XID(xmins.Get(row)))
+		}
+	}
+	if sel := src.Selected(); sel != nil {
+		for _, row := range sel {
+			visit(int(row))
+		}
+	} else {
+		for row := range src.Len() {
+			visit(row)
+		}
+	}
+
+	// sort slots by window, keep group order of first appearance
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		switch x, y := keys[a].window, keys[b].window; {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	// write output rows, field order follows Schema()
+	pkg := pack.New().
+		WithSchema(dst).
+		WithMaxRows(len(keys)).
+		Alloc()
+	for _, slot := range order {
+		k, col := keys[slot], 0
+		pkg.Block(col).Append(k.window)
+		col++
+		if groups != nil {
+			if s, ok := k.group.(string); ok {
+				pkg.Block(col).Append([]byte(s))
+			} else {
+				pkg.Block(col).Append(k.group)
+			}
+			col++
+		}
+		pkg.Block(col).Append(counts[slot])
+		col++
+		for _, acc := range accs {
+			acc.emit(pkg.Block(col), slot)
+			col++
+		}
+	}
+	pkg.UpdateLen()
+	return pkg, maxXid, nil
+}
+
+type accumulator interface {
+	grow()
+	add(row, slot int)
+	emit(dst *block.Block, slot int)
+}
+
+func newAccumulator(b *block.Block, fn Func, neg bool) accumulator {
+	switch b.Type() {
+	case types.BlockInt64:
+		return newNumAccumulator[int64](b, fn, neg)
+	case types.BlockInt32:
+		return newNumAccumulator[int32](b, fn, neg)
+	case types.BlockInt16:
+		return newNumAccumulator[int16](b, fn, neg)
+	case types.BlockInt8:
+		return newNumAccumulator[int8](b, fn, neg)
+	case types.BlockUint64:
+		return newNumAccumulator[uint64](b, fn, neg)
+	case types.BlockUint32:
+		return newNumAccumulator[uint32](b, fn, neg)
+	case types.BlockUint16:
+		return newNumAccumulator[uint16](b, fn, neg)
+	case types.BlockUint8:
+		return newNumAccumulator[uint8](b, fn, neg)
+	case types.BlockFloat64:
+		return newNumAccumulator[float64](b, fn, neg)
+	case types.BlockFloat32:
+		return newNumAccumulator[float32](b, fn, neg)
+	default:
+		return nil
+	}
+}
+
+type numAccumulator[T types.Number] struct {
+	src  types.NumberAccessor[T]
+	vals []T
+	init T
+	fn   Func
+	neg  bool
+}
+
+func newNumAccumulator[T types.Number](b *block.Block, fn Func, neg bool) *numAccumulator[T] {
+	acc := &numAccumulator[T]{
+		src: block.GetAccessor[T](b),
+		fn:  fn,
+		neg: neg,
+	}
+	lo, hi := limits[T]()
+	switch fn {
+	case FuncMin:
+		acc.init = hi
+	case FuncMax:
+		acc.init = lo
+	}
+	return acc
+}
+
+func (a *numAccumulator[T]) grow() {
+	a.vals = append(a.vals, a.init)
+}
+
+func (a *numAccumulator[T]) add(row, slot int) {
+	v := a.src.Get(row)
+	switch a.fn {
+	case FuncSum:
+		if a.neg {
+			a.vals[slot] -= v
+		} else {
+			a.vals[slot] += v
+		}
+	case FuncMin:
+		if !a.neg {
+			a.vals[slot] = min(a.vals[slot], v)
+		}
+	case FuncMax:
+		if !a.neg {
+			a.vals[slot] = max(a.vals[slot], v)
+		}
+	}
+}
+
+func (a *numAccumulator[T]) emit(dst *block.Block, slot int) {
+	dst.Append(a.vals[slot])
+}
+
+// limits returns the smallest and largest values of T. Floats use
+// infinity so that min and max partials stay neutral.
+func limits[T types.Number]() (lo, hi T) {
+	var v any
+	switch any(lo).(type) {
+	case int64:
+		v = [2]int64{math.MinInt64, math.MaxInt64}
+	case int32:
+		v = [2]int32{math.MinInt32, math.MaxInt32}
+	case int16:
+		v = [2]int16{math.MinInt16, math.MaxInt16}
+	case int8:
+		v = [2]int8{math.MinInt8, math.MaxInt8}
+	case uint64:
+		v = [2]uint64{0, math.MaxUint64}
+	case uint32:
+		v = [2]uint32{0, math.MaxUint32}
+	case uint16:
+		v = [2]uint16{0, math.MaxUint16}
+	case uint8:
+		v = [2]uint8{0, math.MaxUint8}
+	case float64:
+		v = [2]float64{math.Inf(-1), math.Inf(1)}
+	case float32:
+		v = [2]float32{float32(math.Inf(-1)), float32(math.Inf(1))}
+	}
+	l := v.([2]T)
+	return l[0], l[1]
+}
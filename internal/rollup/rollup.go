@@ -0,0 +1,226 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+// Package rollup defines continuous time-bucketed aggregates over table
+// data. A rollup stores one partial aggregate row per merge, time window
+// and group in a hidden table. Partials of the same window are combined
+// at query time, so a rollup never needs to rewrite stored rows.
+package rollup
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"blockwatch.cc/knoxdb/pkg/util"
+)
+
+const (
+	// Infix separates source table name and interval in rollup table names.
+	Infix = "_rollup_"
+
+	// CountField is the name of the row count field in rollup tables.
+	CountField = "n_rows"
+)
+
+var (
+	ErrNoTimebase   = fmt.Errorf("rollup: missing timebase field")
+	ErrInvalidField = fmt.Errorf("rollup: invalid field")
+	ErrInvalidFunc  = fmt.Errorf("rollup: invalid function")
+	ErrNoRollup     = fmt.Errorf("rollup: not a rollup table")
+)
+
+// Func is an aggregate function that can be maintained incrementally.
+type Func string
+
+const (
+	FuncSum Func = "sum"
+	FuncMin Func = "min"
+	FuncMax Func = "max"
+)
+
+func (f Func) IsValid() bool {
+	switch f {
+	case FuncSum, FuncMin, FuncMax:
+		return true
+	default:
+		return false
+	}
+}
+
+// Aggregate reduces a numeric source field with a function.
+type Aggregate struct {
+	Field string
+	Func  Func
+}
+
+// Name returns the rollup table field name, e.g. `price_max`.
+func (a Aggregate) Name() string {
+	return a.Field + "_" + string(a.Func)
+}
+
+// Rollup defines time-bucketed aggregates of a source table.
+type Rollup struct {
+	Table    string        // source table name
+	Time     string        // source timebase field, defaults to the first timebase
+	Interval util.TimeUnit // window size
+	GroupBy  string        // optional group field
+	Aggs     []Aggregate   // aggregates, a row count is always kept
+}
+
+// Name returns the rollup table name, e.g. `trades_rollup_1h_market`.
+func (r *Rollup) Name() string {
+	name := r.Table + Infix + strconv.Itoa(r.Interval.Value) + string(r.Interval.Unit)
+	if r.GroupBy != "" {
+		name += "_" + r.GroupBy
+	}
+	return name
+}
+
+// Find returns the aggregate of field and fn.
+func (r *Rollup) Find(field string, fn Func) (Aggregate, bool) {
+	for _, a := range r.Aggs {
+		if a.Field == field && a.Func == fn {
+			return a, true
+		}
+	}
+	return Aggregate{}, false
+}
+
+// Schema validates the rollup against the source table schema src and
+// returns the schema of its rollup table. The rollup schema contains the
+// window start time, the optional group field, the row count and one
+// field per aggregate with the source field's type.
+func (r *Rollup) Schema(src *schema.Schema) (*schema.Schema, error) {
+	if _, err := util.ParseTimeUnit(r.Interval.String()); err != nil || r.Interval.Value <= 0 {
+		return nil, fmt.Errorf("rollup: invalid interval %q", r.Interval)
+	}
+
+	// find the timebase field
+	var tf *schema.Field
+	if r.Time == "" {
+		for _, f := range src.Fields {
+			if f.IsTimebase() && f.IsActive() {
+				tf = f
+				break
+			}
+		}
+		if tf == nil {
+			return nil, ErrNoTimebase
+		}
+		r.Time = tf.Name
+	} else {
+		f, ok := src.Find(r.Time)
+		if !ok || !f.IsTimebase() || !f.IsActive() {
+			return nil, fmt.Errorf("%w %q", ErrNoTimebase, r.Time)
+		}
+		tf = f
+	}
+	if tf.Type != types.FieldTypeTimestamp {
+		return nil, fmt.Errorf("%w %q: type %s is not a timestamp", ErrInvalidField, tf.Name, tf.Type)
+	}
+
+	b := schema.NewBuilder().
+		WithName(r.Name()).
+		Add(tf.Name, types.FieldTypeTimestamp, schema.Scale(tf.Scale), schema.Timebase())
+
+	// copy group field, keep enum connection
+	if r.GroupBy != "" {
+		gf, ok := src.Find(r.GroupBy)
+		if !ok || !gf.IsVisible() || gf.IsNullable() || gf.Name == tf.Name {
+			return nil, fmt.Errorf("%w %q: cannot group", ErrInvalidField, r.GroupBy)
+		}
+		switch gf.Type.BlockType() {
+		case types.BlockList, types.BlockDocument, types.BlockBigint:
+			return nil, fmt.Errorf("%w %q: cannot group by type %s", ErrInvalidField, gf.Name, gf.Type)
+		}
+		b.Field(schema.NewField(gf.Type).
+			WithName(gf.Name).
+			WithFixed(gf.Fixed).
+			WithScale(gf.Scale).
+			WithFlags(gf.Flags & schema.F_ENUM))
+	}
+	b.Add(CountField, types.FieldTypeInt64)
+
+	// add aggregates
+	seen := make(map[string]struct{})
+	for _, a := range r.Aggs {
+		if !a.Func.IsValid() {
+			return nil, fmt.Errorf("%w %q", ErrInvalidFunc, a.Func)
+		}
+		f, ok := src.Find(a.Field)
+		if !ok || !f.IsVisible() || f.IsNullable() || f.Name == r.GroupBy {
+			return nil, fmt.Errorf("%w %q", ErrInvalidField, a.Field)
+		}
+		if !canAggregate(f.Type) {
+			return nil, fmt.Errorf("%w %q: cannot aggregate type %s", ErrInvalidField, a.Field, f.Type)
+		}
+		if _, ok := seen[a.Name()]; ok {
+			return nil, fmt.Errorf("rollup: duplicate aggregate %q", a.Name())
+		}
+		seen[a.Name()] = struct{}{}
+		b.Add(a.Name(), f.Type, schema.Scale(f.Scale))
+	}
+
+	s := b.Finalize().Schema()
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Parse reconstructs the definition of rollup table s created for source
+// table src.
+func Parse(src string, s *schema.Schema) (*Rollup, error) {
+	rest, ok := strings.CutPrefix(s.Name, src+Infix)
+	if !ok {
+		return nil, ErrNoRollup
+	}
+	unit, group, _ := strings.Cut(rest, "_")
+	ival, err := util.ParseTimeUnit(unit)
+	if err != nil {
+		return nil, fmt.Errorf("rollup %s: %v", s.Name, err)
+	}
+	r := &Rollup{
+		Table:    src,
+		Interval: ival,
+		GroupBy:  group,
+	}
+	for _, f := range s.Fields {
+		switch {
+		case !f.IsVisible():
+			continue
+		case f.IsTimebase():
+			r.Time = f.Name
+		case f.Name == CountField, f.Name == group:
+			continue
+		default:
+			i := strings.LastIndexByte(f.Name, '_')
+			if i < 0 {
+				return nil, fmt.Errorf("rollup %s: %w %q", s.Name, ErrInvalidField, f.Name)
+			}
+			r.Aggs = append(r.Aggs, Aggregate{
+				Field: f.Name[:i],
+				Func:  Func(f.Name[i+1:]),
+			})
+		}
+	}
+	if r.Time == "" {
+		return nil, fmt.Errorf("rollup %s: %w", s.Name, ErrNoTimebase)
+	}
+	return r, nil
+}
+
+func canAggregate(typ types.FieldType) bool {
+	switch typ {
+	case types.FieldTypeInt64, types.FieldTypeInt32, types.FieldTypeInt16, types.FieldTypeInt8,
+		types.FieldTypeUint64, types.FieldTypeUint32, types.FieldTypeUint16, types.FieldTypeUint8,
+		types.FieldTypeFloat64, types.FieldTypeFloat32,
+		types.FieldTypeDecimal64, types.FieldTypeDecimal32:
+		return true
+	default:
+		return false
+	}
+}
@@ -104,9 +104,13 @@ func TestIndexEngine[T any, F IF[T]](t *testing.T, driver, eng string, table eng
 				// re-read scherma because table adds metadata
 				ts := table.Schema()
 
-				// prepare index
+				// prepare index, token indexes use n-grams of a string field
 				iopts := NewTestIndexOptions(t, driver, eng)
-				ss, err := ts.Select("u64")
+				name := "u64"
+				if indexType == types.IndexTypeToken {
+					name = "string"
+				}
+				ss, err := ts.Select(name)
 				require.NoError(t, err)
 				indexSchema := &schema.IndexSchema{
 					Name:   "test_index",
@@ -114,6 +118,9 @@ func TestIndexEngine[T any, F IF[T]](t *testing.T, driver, eng string, table eng
 					Base:   ts,
 					Fields: ss.Fields,
 				}
+				if indexType == types.IndexTypeToken {
+					indexSchema.Tokenizer = schema.Ngram(3)
+				}
 
 				var indexEngine F = new(T)
 				c.Run(t, e, table, ts, topts, indexEngine, indexSchema, iopts)
@@ -268,6 +275,43 @@ func CanMatchIndexTest(t *testing.T, e *engine.Engine, te engine.TableEngine, ts
 		)), "no multi")
 		// no ineligible fields
 		require.False(t, ie.CanMatch(makeFilter(ts, "i32", EQ, 1, nil)), "non index field")
+
+	case types.IndexTypeBitmap:
+		// eq
+		require.True(t, ie.CanMatch(makeFilter(ts, "u64", EQ, 1, nil)), EQ)
+		// in
+		require.True(t, ie.CanMatch(makeFilter(ts, "u64", IN, []int{1, 2}, nil)), IN)
+		// ni
+		require.True(t, ie.CanMatch(makeFilter(ts, "u64", NI, []int{1, 2}, nil)), NI)
+		// no other mode
+		require.False(t, ie.CanMatch(makeFilter(ts, "u64", LE, 1, nil)), LE)
+		require.False(t, ie.CanMatch(makeFilter(ts, "u64", LT, 1, nil)), LT)
+		require.False(t, ie.CanMatch(makeFilter(ts, "u64", GE, 1, nil)), GE)
+		require.False(t, ie.CanMatch(makeFilter(ts, "u64", GT, 1, nil)), GT)
+		require.False(t, ie.CanMatch(makeFilter(ts, "u64", RG, 1, 2)), RG)
+		// no trees
+		require.False(t, ie.CanMatch(makeTree(
+			makeFilter(ts, "u64", EQ, 1, nil),
+			makeFilter(ts, "u32", EQ, 2, nil),
+		)), "no multi")
+		// no ineligible fields
+		require.False(t, ie.CanMatch(makeFilter(ts, "i32", EQ, 1, nil)), "non index field")
+
+	case types.IndexTypeToken:
+		// contains with at least n runes
+		require.True(t, ie.CanMatch(makeFilter(ts, "string", CS, "001", nil)), CS)
+		require.False(t, ie.CanMatch(makeFilter(ts, "string", CS, "01", nil)), "short "+CS.String())
+		// no other mode
+		require.False(t, ie.CanMatch(makeFilter(ts, "string", MT, "001", nil)), MT)
+		require.False(t, ie.CanMatch(makeFilter(ts, "string", EQ, "001", nil)), EQ)
+		require.False(t, ie.CanMatch(makeFilter(ts, "string", IN, []string{"001"}, nil)), IN)
+		// no trees
+		require.False(t, ie.CanMatch(makeTree(
+			makeFilter(ts, "string", CS, "001", nil),
+			makeFilter(ts, "u32", EQ, 2, nil),
+		)), "no multi")
+		// no ineligible fields
+		require.False(t, ie.CanMatch(makeFilter(ts, "bytes", CS, "001", nil)), "non index field")
 	default:
 		require.Fail(t, "no case for testing index type %s", is.Type)
 	}
@@ -288,6 +332,14 @@ func AddIndexTest(t *testing.T, e *engine.Engine, te engine.TableEngine, ts *sch
 		QueryIndex(t, ctx, ie, makeFilter(ts, "u64", LT, 6, nil), 6)
 		QueryIndex(t, ctx, ie, makeFilter(ts, "u64", GT, 15, nil), 0)
 
+	case types.IndexTypeBitmap:
+		QueryIndex(t, ctx, ie, makeFilter(ts, "u64", EQ, 5, nil), 1)
+		QueryIndex(t, ctx, ie, makeFilter(ts, "u64", EQ, 15, nil), 0)
+
+	case types.IndexTypeToken:
+		QueryIndex(t, ctx, ie, makeFilter(ts, "string", CS, "005", nil), 1)
+		QueryIndex(t, ctx, ie, makeFilter(ts, "string", CS, "015", nil), 0)
+
 	default:
 		require.Fail(t, "no case for testing index type %s", is.Type)
 	}
@@ -305,6 +357,14 @@ func DeleteIndexTest(t *testing.T, e *engine.Engine, te engine.TableEngine, ts *
 	case types.IndexTypeInt:
 		QueryIndex(t, ctx, ie, makeFilter(ts, "u64", LT, 6, nil), 6)
 
+	case types.IndexTypeBitmap:
+		QueryIndex(t, ctx, ie, makeFilter(ts, "u64", EQ, 5, nil), 1)
+		QueryIndex(t, ctx, ie, makeFilter(ts, "u64", NI, []int{0}, nil), 5)
+
+	case types.IndexTypeToken:
+		QueryIndex(t, ctx, ie, makeFilter(ts, "string", CS, "005", nil), 1)
+		QueryIndex(t, ctx, ie, makeFilter(ts, "string", CS, "000", nil), 6)
+
 	default:
 		require.Fail(t, "no case for testing index type %s", is.Type)
 	}
@@ -322,6 +382,14 @@ func DeleteIndexTest(t *testing.T, e *engine.Engine, te engine.TableEngine, ts *
 	case types.IndexTypeInt:
 		QueryIndex(t, ctx, ie, makeFilter(ts, "u64", LT, 6, nil), 5)
 
+	case types.IndexTypeBitmap:
+		QueryIndex(t, ctx, ie, makeFilter(ts, "u64", EQ, 5, nil), 0)
+		QueryIndex(t, ctx, ie, makeFilter(ts, "u64", NI, []int{0}, nil), 4)
+
+	case types.IndexTypeToken:
+		QueryIndex(t, ctx, ie, makeFilter(ts, "string", CS, "005", nil), 0)
+		QueryIndex(t, ctx, ie, makeFilter(ts, "string", CS, "000", nil), 5)
+
 	default:
 		require.Fail(t, "no case for testing index type %s", is.Type)
 	}
@@ -352,6 +420,19 @@ func QueryIndexTest(t *testing.T, e *engine.Engine, te engine.TableEngine, ts *s
 		QueryIndex(t, ctx, ie, makeFilter(ts, "u64", GT, 1, nil), 4)
 		// rg
 		QueryIndex(t, ctx, ie, makeFilter(ts, "u64", RG, 1, 2), 2)
+	case types.IndexTypeBitmap:
+		// eq
+		QueryIndex(t, ctx, ie, makeFilter(ts, "u64", EQ, 1, nil), 1)
+		// in
+		QueryIndex(t, ctx, ie, makeFilter(ts, "u64", IN, []int{1, 2, 9}, nil), 2)
+		// ni
+		QueryIndex(t, ctx, ie, makeFilter(ts, "u64", NI, []int{1, 2}, nil), 4)
+	case types.IndexTypeToken:
+		// cs, all terms must match
+		QueryIndex(t, ctx, ie, makeFilter(ts, "string", CS, "0001", nil), 1)
+		QueryIndex(t, ctx, ie, makeFilter(ts, "string", CS, "0000000000000003", nil), 1)
+		QueryIndex(t, ctx, ie, makeFilter(ts, "string", CS, "000", nil), 6)
+		QueryIndex(t, ctx, ie, makeFilter(ts, "string", CS, "0012", nil), 0)
 	default:
 		require.Fail(t, "no case for testing index type %s", is.Type)
 	}
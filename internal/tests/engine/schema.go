@@ -24,6 +24,8 @@ var (
 	GE = types.FilterModeGe
 	GT = types.FilterModeGt
 	RG = types.FilterModeRange
+	CS = types.FilterModeContains
+	MT = types.FilterModeMatch
 )
 
 var myEnums = []string{"one", "two", "three", "four"}
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"blockwatch.cc/knoxdb/internal/engine"
+	"blockwatch.cc/knoxdb/internal/pack"
 	"blockwatch.cc/knoxdb/internal/query"
 	"blockwatch.cc/knoxdb/internal/types"
 	"blockwatch.cc/knoxdb/internal/xroar"
@@ -66,6 +67,18 @@ var TestCases = []TestCase{
 	// 	Name: "InsertRows:ReadOnlyDb",
 	// 	Run:  InsertRowsReadOnlyTableTest,
 	// },
+	{
+		Name: "ImportInto",
+		Run:  ImportIntoTableTest,
+	},
+	{
+		Name: "ImportInto:Pk",
+		Run:  ImportIntoPkTableTest,
+	},
+	{
+		Name: "ImportInto:Abort",
+		Run:  ImportIntoAbortTableTest,
+	},
 	{
 		Name: "UpdateRows",
 		Run:  UpdateRowsTableTest,
@@ -74,6 +87,10 @@ var TestCases = []TestCase{
 		Name: "Query",
 		Run:  QueryTableTest,
 	},
+	{
+		Name: "QueryUnlimited",
+		Run:  QueryUnlimitedTableTest,
+	},
 	{
 		Name: "Count",
 		Run:  CountTableTest,
@@ -263,6 +280,167 @@ func InsertRowsReadOnlyTableTest(t *testing.T, e *engine.Engine, tab engine.Tabl
 	require.NoError(t, commit())
 }
 
+// BeginImport creates the test table in a new transaction which is the only
+// one allowed to import into the table.
+func BeginImport(t *testing.T, e *engine.Engine, tab engine.TableEngine, opts engine.Options) (context.Context, func() error, func() error) {
+	t.Helper()
+	CreateEnum(t, e)
+	ctx, tx, commit, abort, err := e.WithTransaction(context.Background())
+	require.NoError(t, err)
+	tx.WithFlags(engine.TxFlagCatalog) // let tx sync wal
+	s := allTypesSchema.Clone().WithEnums(e.CloneEnums(allTypesSchema.EnumNames()...)).WithMeta().Finalize()
+	require.NoError(t, tab.Create(ctx, s, opts.TableOptions()...))
+	return ctx, commit, abort
+}
+
+func makeImportPack(t *testing.T, tab engine.TableEngine, ids ...uint64) *pack.Package {
+	t.Helper()
+	enc := schema.NewEncoder(tab.Schema())
+	pkg := pack.New().WithSchema(tab.Schema()).WithMaxRows(len(ids)).Alloc()
+	for i, id := range ids {
+		v := NewAllTypes(i)
+		v.Id = id
+		buf, err := enc.Encode(v, nil)
+		require.NoError(t, err)
+		pkg.AppendWire(buf, nil)
+	}
+	return pkg
+}
+
+func ImportIntoTableTest(t *testing.T, e *engine.Engine, tab engine.TableEngine, opts engine.Options) {
+	ctx, commit, abort := BeginImport(t, e, tab, opts)
+
+	// build an import pack with zero primary keys and metadata
+	pkg := makeImportPack(t, tab, 0, 0, 0, 0, 0)
+	defer pkg.Release()
+	pk, n, err := tab.ImportInto(ctx, pkg)
+	if err != nil {
+		abort()
+	}
+	require.NoError(t, err)
+	require.NoError(t, commit())
+	assert.Equal(t, uint64(1), pk, "first pk")
+	assert.Equal(t, 5, n)
+
+	// later transactions cannot import
+	ctx, _, _, abort, err = e.WithTransaction(context.Background())
+	require.NoError(t, err)
+	_, _, err = tab.ImportInto(ctx, pkg)
+	abort()
+	require.ErrorIs(t, err, engine.ErrTableNotNew)
+
+	// journal inserts continue the pk sequence after imported rows
+	InsertData(t, e, tab)
+	assert.Equal(t, uint64(15), tab.State().NRows)
+
+	ctx, _, commit, abort, err = e.WithTransaction(context.Background())
+	defer abort()
+	require.NoError(t, err)
+
+	plan := query.NewQueryPlan().
+		WithFilters(makeFilter(tab.Schema(), "id", GT, 3, nil)).
+		WithSchema(tab.Schema()).
+		WithTable(tab)
+	defer plan.Close()
+	require.NoError(t, plan.Validate())
+	require.NoError(t, plan.Compile(ctx))
+
+	res, err := tab.Query(ctx, plan)
+	require.NoError(t, err)
+	defer res.Close()
+	require.Equal(t, 12, res.Len())
+	for i := range res.Len() {
+		assert.Equal(t, uint64(4+i), res.Value(i, 0), "pk")
+	}
+	require.NoError(t, commit())
+}
+
+func ImportIntoPkTableTest(t *testing.T, e *engine.Engine, tab engine.TableEngine, opts engine.Options) {
+	ctx, commit, abort := BeginImport(t, e, tab, opts)
+
+	// non-zero primary keys are preserved, zero keys follow the last key
+	pkg := makeImportPack(t, tab, 10, 20, 0, 30)
+	defer pkg.Release()
+	pk, n, err := tab.ImportInto(ctx, pkg)
+	if err != nil {
+		abort()
+	}
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), pk, "first pk")
+	assert.Equal(t, 4, n)
+	assert.Equal(t, uint64(31), tab.State().NextPk)
+
+	// keys must stay above existing keys
+	pkg2 := makeImportPack(t, tab, 30)
+	defer pkg2.Release()
+	_, _, err = tab.ImportInto(ctx, pkg2)
+	require.ErrorIs(t, err, engine.ErrPkOutOfOrder)
+	require.NoError(t, commit())
+
+	// journal inserts continue the pk sequence after imported rows
+	InsertData(t, e, tab)
+	assert.Equal(t, uint64(14), tab.State().NRows)
+
+	ctx, _, commit, abort, err = e.WithTransaction(context.Background())
+	defer abort()
+	require.NoError(t, err)
+
+	plan := query.NewQueryPlan().
+		WithFilters(makeFilter(tab.Schema(), "id", GE, 20, nil)).
+		WithSchema(tab.Schema()).
+		WithTable(tab)
+	defer plan.Close()
+	require.NoError(t, plan.Validate())
+	require.NoError(t, plan.Compile(ctx))
+
+	res, err := tab.Query(ctx, plan)
+	require.NoError(t, err)
+	defer res.Close()
+	require.Equal(t, 13, res.Len())
+	for i, id := range []uint64{20, 21, 30, 31, 32} {
+		assert.Equal(t, id, res.Value(i, 0), "pk")
+	}
+	require.NoError(t, commit())
+}
+
+func ImportIntoAbortTableTest(t *testing.T, e *engine.Engine, tab engine.TableEngine, opts engine.Options) {
+	ctx, _, abort := BeginImport(t, e, tab, opts)
+	pkg := makeImportPack(t, tab, 0, 0, 0)
+	defer pkg.Release()
+	_, n, err := tab.ImportInto(ctx, pkg)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+
+	// abort removes imported packs
+	require.NoError(t, abort())
+	require.Equal(t, uint64(0), tab.State().NRows)
+
+	// the table continues empty
+	InsertData(t, e, tab)
+	assert.Equal(t, uint64(10), tab.State().NRows)
+
+	ctx, _, commit, abort, err := e.WithTransaction(context.Background())
+	defer abort()
+	require.NoError(t, err)
+
+	plan := query.NewQueryPlan().
+		WithFilters(makeFilter(tab.Schema(), "id", GT, 0, nil)).
+		WithSchema(tab.Schema()).
+		WithTable(tab)
+	defer plan.Close()
+	require.NoError(t, plan.Validate())
+	require.NoError(t, plan.Compile(ctx))
+
+	res, err := tab.Query(ctx, plan)
+	require.NoError(t, err)
+	defer res.Close()
+	require.Equal(t, 10, res.Len())
+	for i := range res.Len() {
+		assert.Equal(t, uint64(1+i), res.Value(i, 0), "pk")
+	}
+	require.NoError(t, commit())
+}
+
 func UpdateRowsTableTest(t *testing.T, e *engine.Engine, tab engine.TableEngine, opts engine.Options) {
 	SetupTableTest(t, e, tab, opts)
 	InsertData(t, e, tab)
@@ -318,6 +496,35 @@ func QueryTableTest(t *testing.T, e *engine.Engine, tab engine.TableEngine, opts
 	require.NoError(t, commit())
 }
 
+func QueryUnlimitedTableTest(t *testing.T, e *engine.Engine, tab engine.TableEngine, opts engine.Options) {
+	SetupTableTest(t, e, tab, opts)
+	InsertData(t, e, tab)
+
+	ctx, _, commit, abort, err := e.WithTransaction(context.Background())
+	defer abort()
+	require.NoError(t, err)
+
+	plan := query.NewQueryPlan().
+		WithFilters(makeFilter(tab.Schema(), "id", LT, 5, nil)).
+		WithSchema(tab.Schema()).
+		WithTable(tab)
+	if testing.Verbose() {
+		plan.WithFlags(query.QueryFlagDebug)
+	}
+	defer plan.Close()
+	require.NoError(t, plan.Validate())
+	require.NoError(t, plan.Compile(ctx))
+
+	res, err := tab.Query(ctx, plan)
+	require.NoError(t, err)
+	defer res.Close()
+	assert.Equal(t, int(4), res.Len())
+
+	// results are not preallocated for all table rows
+	assert.LessOrEqual(t, res.(*query.Result).Pack().Cap(), opts.PackSize)
+	require.NoError(t, commit())
+}
+
 func CountTableTest(t *testing.T, e *engine.Engine, tab engine.TableEngine, opts engine.Options) {
 	SetupTableTest(t, e, tab, opts)
 	InsertData(t, e, tab)
@@ -33,6 +33,7 @@ var Generators = []Generator{
 	BoolsGenerator{},
 	Int128Generator{},
 	Int256Generator{},
+	BigGenerator{},
 }
 
 type Generator interface {
@@ -224,6 +225,31 @@ func (Int256Generator) MakeSlice(n ...int) any {
 	return s
 }
 
+// bigint
+var _ Generator = (*BigGenerator)(nil)
+
+type BigGenerator struct{}
+
+func (BigGenerator) Type() types.BlockType {
+	return types.BlockBigint
+}
+
+func (BigGenerator) Name() string {
+	return "bigint"
+}
+
+func (BigGenerator) MakeValue(n int) any {
+	return num.NewBig(int64(n))
+}
+
+func (BigGenerator) MakeSlice(n ...int) any {
+	s := make([]num.Big, len(n))
+	for i := range n {
+		s[i] = num.NewBig(int64(n[i]))
+	}
+	return s
+}
+
 // Generic Generator Functions
 
 // creates n sequential values
@@ -596,3 +622,29 @@ func GenStringRuns(n, r, l int) *stringx.StringPool {
 	}
 	return p
 }
+
+// creates n URI-like text values at cardinality c (compressible with
+// symbol table based schemes like FSST)
+func GenStringText(n, c int) *stringx.StringPool {
+	if c > n {
+		panic(fmt.Errorf("c=%d must be smaller than n=%d", c, n))
+	}
+	if c <= 0 {
+		c = 1
+	}
+	words := []string{
+		"api", "v1", "account", "contract", "transfer", "entrypoint",
+		"default", "memo", "search", "items", "block", "operation",
+	}
+	word := func() string { return words[util.RandIntn(len(words))] }
+	unique := make([][]byte, 0, c)
+	for range c {
+		unique = append(unique, fmt.Appendf(nil, "https://%s.example.com/%s/%s?id=%d",
+			word(), word(), word(), util.RandIntn(1<<20)))
+	}
+	p := stringx.NewStringPool(n)
+	for range n {
+		p.Append(unique[util.RandIntn(c)])
+	}
+	return p
+}
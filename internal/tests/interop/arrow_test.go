@@ -0,0 +1,174 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package interop
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	karrow "blockwatch.cc/knoxdb/pkg/arrow"
+	"blockwatch.cc/knoxdb/pkg/num"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/stretchr/testify/require"
+)
+
+type ArrowRow struct {
+	Id      uint64         `knox:"id,pk"`
+	Int64   int64          `knox:"i64"`
+	Int16   int16          `knox:"i16"`
+	Uint8   uint8          `knox:"u8"`
+	Float64 float64        `knox:"f64"`
+	D64     num.Decimal64  `knox:"d64,scale=4"`
+	D128    num.Decimal128 `knox:"d128,scale=18"`
+	I256    num.Int256     `knox:"i256"`
+	Bool    bool           `knox:"bool"`
+	Ts      time.Time      `knox:"ts,scale=ms"`
+	Date    time.Time      `knox:"date,date"`
+	Tod     time.Time      `knox:"tod,time"`
+	String  string         `knox:"string"`
+	Hash    []byte         `knox:"bytes"`
+	Array   [2]byte        `knox:"array2"`
+	OptInt  *int64         `knox:"opt_i64"`
+	OptStr  *string        `knox:"opt_str"`
+}
+
+func makeArrowRows(n int) []ArrowRow {
+	res := make([]ArrowRow, n)
+	tm := time.Date(2025, 6, 7, 2, 0, 1, 0, time.UTC)
+	for i := range res {
+		v := int64(i) - int64(n/2)
+		r := ArrowRow{
+			Id:      uint64(i + 1),
+			Int64:   v * 1e12,
+			Int16:   int16(v),
+			Uint8:   uint8(i) << 4,
+			Float64: float64(v) * 1.5,
+			D64:     num.NewDecimal64(v*10001, 4),
+			D128:    num.NewDecimal128(num.Int128FromInt64(v*1000000000000000001), 18),
+			I256:    num.Int256FromInt64(v).Lsh(200),
+			Bool:    i%3 == 0,
+			Ts:      tm.Add(time.Duration(v) * time.Millisecond),
+			Date:    tm.AddDate(0, 0, int(v)).Truncate(24 * time.Hour),
+			Tod:     time.Unix(int64(i)*61, 0).UTC(),
+			String:  string(rune('a' + i%26)),
+			Hash:    bytes.Repeat([]byte{byte(i)}, i%5),
+			Array:   [2]byte{byte(i), byte(i >> 8)},
+		}
+		if i%4 != 0 {
+			x, s := v, r.String
+			r.OptInt, r.OptStr = &x, &s
+		}
+		res[i] = r
+	}
+	return res
+}
+
+// TestIPCReader ensures that Apache Arrow's IPC stream reader decodes
+// streams written by knoxdb to the original values.
+func TestIPCReader(t *testing.T) {
+	s := schema.MustSchemaOf(ArrowRow{})
+	rows := makeArrowRows(40)
+	p1 := makePack(t, s, rows[:24])
+	defer p1.Release()
+	p2 := makePack(t, s, rows[24:])
+	defer p2.Release()
+
+	// the second batch writes a selection
+	p2.WithSelection([]uint32{0, 3, 4, 9, 15})
+	exp := append([]ArrowRow{}, rows[:24]...)
+	for _, i := range p2.Selected() {
+		exp = append(exp, rows[24+int(i)])
+	}
+
+	buf := new(bytes.Buffer)
+	w, err := karrow.NewWriter(s, buf)
+	require.NoError(t, err)
+	require.NoError(t, w.WritePack(p1))
+	require.NoError(t, w.WritePack(p2))
+	require.NoError(t, w.Close())
+
+	r, err := ipc.NewReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	defer r.Release()
+
+	// schema
+	as := r.Schema()
+	require.Equal(t, len(s.Fields), as.NumFields())
+	for i, f := range s.Fields {
+		af := as.Field(i)
+		require.Equal(t, f.Name, af.Name)
+		require.Equal(t, f.IsNullable(), af.Nullable, f.Name)
+	}
+	for name, typ := range map[string]arrow.DataType{
+		"id":      arrow.PrimitiveTypes.Uint64,
+		"i64":     arrow.PrimitiveTypes.Int64,
+		"i16":     arrow.PrimitiveTypes.Int16,
+		"u8":      arrow.PrimitiveTypes.Uint8,
+		"f64":     arrow.PrimitiveTypes.Float64,
+		"d64":     &arrow.Decimal128Type{Precision: 18, Scale: 4},
+		"d128":    &arrow.Decimal128Type{Precision: 38, Scale: 18},
+		"i256":    &arrow.Decimal256Type{Precision: 76, Scale: 0},
+		"bool":    arrow.FixedWidthTypes.Boolean,
+		"ts":      arrow.FixedWidthTypes.Timestamp_ms,
+		"date":    arrow.FixedWidthTypes.Date32,
+		"tod":     arrow.FixedWidthTypes.Time32s,
+		"string":  arrow.BinaryTypes.String,
+		"bytes":   arrow.BinaryTypes.Binary,
+		"array2":  &arrow.FixedSizeBinaryType{ByteWidth: 2},
+		"opt_i64": arrow.PrimitiveTypes.Int64,
+		"opt_str": arrow.BinaryTypes.String,
+	} {
+		idx := as.FieldIndices(name)
+		require.Len(t, idx, 1, name)
+		require.True(t, arrow.TypeEqual(typ, as.Field(idx[0]).Type), "%s: %s", name, as.Field(idx[0]).Type)
+	}
+
+	// values
+	var (
+		row     int
+		batches int
+	)
+	for r.Next() {
+		rec := r.RecordBatch()
+		batches++
+		col := func(name string) arrow.Array {
+			return rec.Column(rec.Schema().FieldIndices(name)[0])
+		}
+		for k := range int(rec.NumRows()) {
+			e := exp[row]
+			require.Equal(t, e.Id, col("id").(*array.Uint64).Value(k))
+			require.Equal(t, e.Int64, col("i64").(*array.Int64).Value(k))
+			require.Equal(t, e.Int16, col("i16").(*array.Int16).Value(k))
+			require.Equal(t, e.Uint8, col("u8").(*array.Uint8).Value(k))
+			require.Equal(t, e.Float64, col("f64").(*array.Float64).Value(k))
+			require.Equal(t, e.D64.Int64(), col("d64").(*array.Decimal128).Value(k).BigInt().Int64())
+			require.Equal(t, e.D128.Int128().String(), col("d128").(*array.Decimal128).Value(k).BigInt().String())
+			require.Equal(t, e.I256.String(), col("i256").(*array.Decimal256).Value(k).BigInt().String())
+			require.Equal(t, e.Bool, col("bool").(*array.Boolean).Value(k))
+			require.Equal(t, e.Ts, col("ts").(*array.Timestamp).Value(k).ToTime(arrow.Millisecond))
+			require.Equal(t, e.Date, col("date").(*array.Date32).Value(k).ToTime())
+			tod := col("tod").(*array.Time32).Value(k).ToTime(arrow.Second)
+			require.Equal(t, e.Tod.Sub(e.Tod.Truncate(24*time.Hour)), tod.Sub(tod.Truncate(24*time.Hour)))
+			require.Equal(t, e.String, col("string").(*array.String).Value(k))
+			require.Equal(t, e.Hash, append([]byte{}, col("bytes").(*array.Binary).Value(k)...))
+			require.Equal(t, e.Array[:], col("array2").(*array.FixedSizeBinary).Value(k))
+			require.Equal(t, e.OptInt == nil, col("opt_i64").IsNull(k), "row %d", row)
+			if e.OptInt != nil {
+				require.Equal(t, *e.OptInt, col("opt_i64").(*array.Int64).Value(k))
+			}
+			require.Equal(t, e.OptStr == nil, col("opt_str").IsNull(k), "row %d", row)
+			if e.OptStr != nil {
+				require.Equal(t, *e.OptStr, col("opt_str").(*array.String).Value(k))
+			}
+			row++
+		}
+	}
+	require.NoError(t, r.Err())
+	require.Equal(t, 2, batches)
+	require.Equal(t, len(exp), row)
+}
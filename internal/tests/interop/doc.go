@@ -0,0 +1,7 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+// Package interop tests that files and streams written by knoxdb are
+// readable by Apache Arrow's Go implementation. It lives in a separate
+// module so that knoxdb does not depend on arrow-go.
+package interop
@@ -0,0 +1,117 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+// Command gen writes Parquet golden files with Apache Arrow's Go
+// implementation. Files cover dictionary pages, optional columns,
+// decimals stored as INT32, INT64 and FIXED_LEN_BYTE_ARRAY and all
+// timestamp units in v1 and v2 data pages. Values must match goldenRow
+// in pkg/parquet/golden_test.go. Run from the interop module directory:
+//
+//	go run ./gen -out ../../../pkg/parquet/testdata
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/decimal128"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+)
+
+const (
+	numRows     = 150
+	rowGroupLen = 64
+	baseMillis  = 1749261601000 // 2025-06-07T02:00:01Z
+)
+
+var names = []string{"alpha", "beta", "gamma", "delta"}
+
+func main() {
+	out := flag.String("out", "../../../pkg/parquet/testdata", "output directory")
+	flag.Parse()
+	for _, f := range []struct {
+		name  string
+		page  parquet.DataPageVersion
+		codec compress.Compression
+	}{
+		{"golden_v1.parquet", parquet.DataPageV1, compress.Codecs.Snappy},
+		{"golden_v2.parquet", parquet.DataPageV2, compress.Codecs.Zstd},
+	} {
+		if err := write(filepath.Join(*out, f.name), f.page, f.codec); err != nil {
+			log.Fatalf("%s: %v", f.name, err)
+		}
+	}
+}
+
+func write(name string, page parquet.DataPageVersion, codec compress.Compression) error {
+	s := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "i32", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+		{Name: "d9", Type: &arrow.Decimal128Type{Precision: 9, Scale: 2}},
+		{Name: "d18", Type: &arrow.Decimal128Type{Precision: 18, Scale: 4}, Nullable: true},
+		{Name: "d38", Type: &arrow.Decimal128Type{Precision: 38, Scale: 6}},
+		{Name: "ts_ms", Type: arrow.FixedWidthTypes.Timestamp_ms},
+		{Name: "ts_us", Type: arrow.FixedWidthTypes.Timestamp_us},
+		{Name: "ts_ns", Type: arrow.FixedWidthTypes.Timestamp_ns, Nullable: true},
+	}, nil)
+
+	b := array.NewRecordBuilder(memory.DefaultAllocator, s)
+	defer b.Release()
+	for i := range numRows {
+		v := int64(i - numRows/2)
+		b.Field(0).(*array.Int64Builder).Append(int64(i + 1))
+		if i%5 == 0 {
+			b.Field(1).AppendNull()
+		} else {
+			b.Field(1).(*array.StringBuilder).Append(names[i%4])
+		}
+		if i%3 == 0 {
+			b.Field(2).AppendNull()
+		} else {
+			b.Field(2).(*array.Int32Builder).Append(int32(v * 1000))
+		}
+		b.Field(3).(*array.Decimal128Builder).Append(decimal128.FromI64(v * 12345))
+		if i%7 == 0 {
+			b.Field(4).AppendNull()
+		} else {
+			b.Field(4).(*array.Decimal128Builder).Append(decimal128.FromI64(v * 1000000000001))
+		}
+		d38 := decimal128.FromI64(v).Mul(decimal128.FromI64(1e18)).Add(decimal128.FromI64(int64(i)))
+		b.Field(5).(*array.Decimal128Builder).Append(d38)
+		ms := baseMillis + v*3600001
+		b.Field(6).(*array.TimestampBuilder).Append(arrow.Timestamp(ms))
+		b.Field(7).(*array.TimestampBuilder).Append(arrow.Timestamp(ms*1000 + v))
+		if i%11 == 0 {
+			b.Field(8).AppendNull()
+		} else {
+			b.Field(8).(*array.TimestampBuilder).Append(arrow.Timestamp(ms*1000000 + v*1001))
+		}
+	}
+	rec := b.NewRecordBatch()
+	defer rec.Release()
+	tbl := array.NewTableFromRecords(s, []arrow.RecordBatch{rec})
+	defer tbl.Release()
+
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	props := parquet.NewWriterProperties(
+		parquet.WithVersion(parquet.V2_6),
+		parquet.WithDataPageVersion(page),
+		parquet.WithCompression(codec),
+		parquet.WithDictionaryDefault(true),
+		parquet.WithStoreDecimalAsInteger(true),
+		parquet.WithMaxRowGroupLength(rowGroupLen),
+	)
+	// the writer closes f
+	return pqarrow.WriteTable(tbl, f, rowGroupLen, props, pqarrow.DefaultWriterProps())
+}
@@ -0,0 +1,23 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package interop
+
+import (
+	"testing"
+
+	"blockwatch.cc/knoxdb/internal/pack"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func makePack[T any](t testing.TB, s *schema.Schema, rows []T) *pack.Package {
+	pkg := pack.New().WithSchema(s).WithMaxRows(len(rows)).Alloc()
+	enc := schema.NewGenericEncoder[T]()
+	for _, v := range rows {
+		buf, err := enc.Encode(v, nil)
+		require.NoError(t, err)
+		pkg.AppendWire(buf, nil)
+	}
+	return pkg
+}
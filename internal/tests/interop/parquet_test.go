@@ -0,0 +1,245 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package interop
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"blockwatch.cc/knoxdb/pkg/num"
+	"blockwatch.cc/knoxdb/pkg/parquet"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	"github.com/stretchr/testify/require"
+)
+
+// all supported types including nullable fields
+type ParquetRow struct {
+	Id      uint64         `knox:"id,pk"`
+	Int64   int64          `knox:"i64"`
+	Int32   int32          `knox:"i32"`
+	Int16   int16          `knox:"i16"`
+	Int8    int8           `knox:"i8"`
+	Uint64  uint64         `knox:"u64"`
+	Uint32  uint32         `knox:"u32"`
+	Uint16  uint16         `knox:"u16"`
+	Uint8   uint8          `knox:"u8"`
+	Float64 float64        `knox:"f64"`
+	Float32 float32        `knox:"f32"`
+	D32     num.Decimal32  `knox:"d32,scale=5"`
+	D64     num.Decimal64  `knox:"d64,scale=15"`
+	D128    num.Decimal128 `knox:"d128,scale=18"`
+	D256    num.Decimal256 `knox:"d256,scale=24"`
+	I128    num.Int128     `knox:"i128"`
+	I256    num.Int256     `knox:"i256"`
+	Bool    bool           `knox:"bool"`
+	Time    time.Time      `knox:"time,scale=s"`
+	Ts      time.Time      `knox:"ts,scale=us"`
+	Date    time.Time      `knox:"date,date"`
+	Tod     time.Time      `knox:"tod,time"`
+	Hash    []byte         `knox:"bytes"`
+	Array   [2]byte        `knox:"array2"`
+	Uid     [16]byte       `knox:"uid,uuid"`
+	String  string         `knox:"string"`
+	Big     num.Big        `knox:"big"`
+	OptInt  *int64         `knox:"opt_i64"`
+	OptStr  *string        `knox:"opt_str"`
+}
+
+func makeParquetRows(n int) []ParquetRow {
+	res := make([]ParquetRow, n)
+	tm := time.Date(2025, 6, 7, 2, 0, 1, 0, time.UTC)
+	for i := range res {
+		v := int64(i) - int64(n/2) // include negative values
+		r := ParquetRow{
+			Id:      uint64(i + 1),
+			Int64:   v * 1e12,
+			Int32:   int32(v) * 1e6,
+			Int16:   int16(v),
+			Int8:    int8(v),
+			Uint64:  uint64(i) << 60,
+			Uint32:  uint32(i) << 28,
+			Uint16:  uint16(i) << 12,
+			Uint8:   uint8(i) << 4,
+			Float64: float64(v) * 1.5,
+			Float32: float32(v) * 0.5,
+			D32:     num.NewDecimal32(int32(v)*100001, 5),
+			D64:     num.NewDecimal64(v*1000000000000001, 15),
+			D128:    num.NewDecimal128(num.Int128FromInt64(v*1000000000000000001), 18),
+			D256:    num.NewDecimal256(num.Int256FromInt64(v).Mul(num.Int256FromInt64(1e18)), 24),
+			I128:    num.Int128FromInt64(v).Lsh(70),
+			I256:    num.Int256FromInt64(v).Lsh(200),
+			Bool:    i%3 == 0,
+			Time:    tm.Add(time.Duration(v) * time.Hour),
+			Ts:      tm.Add(time.Duration(v) * time.Microsecond),
+			Date:    tm.AddDate(0, 0, int(v)).Truncate(24 * time.Hour),
+			Tod:     time.Unix(int64(i)*61, 0).UTC(), // time of day
+			Hash:    bytes.Repeat([]byte{byte(i)}, i%5),
+			Array:   [2]byte{byte(i), byte(i >> 8)},
+			Uid:     [16]byte{0: byte(i), 15: 1},
+			String:  string(rune('a' + i%26)),
+			Big:     num.NewBig(v * 1e15),
+		}
+		if i%4 != 0 {
+			x, s := v, r.String
+			r.OptInt, r.OptStr = &x, &s
+		}
+		res[i] = r
+	}
+	return res
+}
+
+// TestArrowReadsParquet ensures that Apache Arrow's Parquet reader decodes
+// files written by knoxdb to the original values.
+func TestArrowReadsParquet(t *testing.T) {
+	s := schema.MustSchemaOf(ParquetRow{})
+	rows := makeParquetRows(40)
+	p1 := makePack(t, s, rows[:24])
+	defer p1.Release()
+	p2 := makePack(t, s, rows[24:])
+	defer p2.Release()
+
+	for _, c := range []parquet.Compression{parquet.CompressionSnappy, parquet.CompressionZstd} {
+		t.Run(c.String(), func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			w, err := parquet.NewWriter(s, buf)
+			require.NoError(t, err)
+			w.WithCompression(c)
+			require.NoError(t, w.WritePack(p1))
+			require.NoError(t, w.WritePack(p2))
+			require.NoError(t, w.Close())
+
+			tbl, err := pqarrow.ReadTable(
+				context.Background(),
+				bytes.NewReader(buf.Bytes()),
+				nil,
+				pqarrow.ArrowReadProperties{},
+				memory.DefaultAllocator,
+			)
+			require.NoError(t, err)
+			defer tbl.Release()
+			require.Equal(t, int64(len(rows)), tbl.NumRows())
+
+			col := func(name string) []arrow.Array {
+				t.Helper()
+				idx := tbl.Schema().FieldIndices(name)
+				require.Len(t, idx, 1, name)
+				return tbl.Column(idx[0]).Data().Chunks()
+			}
+			each := func(name string, fn func(arr arrow.Array, k int, r ParquetRow)) {
+				t.Helper()
+				var i int
+				for _, arr := range col(name) {
+					for k := range arr.Len() {
+						fn(arr, k, rows[i])
+						i++
+					}
+				}
+				require.Equal(t, len(rows), i, name)
+			}
+
+			each("id", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, r.Id, a.(*array.Uint64).Value(k))
+			})
+			each("i64", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, r.Int64, a.(*array.Int64).Value(k))
+			})
+			each("i32", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, r.Int32, a.(*array.Int32).Value(k))
+			})
+			each("i16", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, r.Int16, a.(*array.Int16).Value(k))
+			})
+			each("i8", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, r.Int8, a.(*array.Int8).Value(k))
+			})
+			each("u64", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, r.Uint64, a.(*array.Uint64).Value(k))
+			})
+			each("u32", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, r.Uint32, a.(*array.Uint32).Value(k))
+			})
+			each("u16", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, r.Uint16, a.(*array.Uint16).Value(k))
+			})
+			each("u8", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, r.Uint8, a.(*array.Uint8).Value(k))
+			})
+			each("f64", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, r.Float64, a.(*array.Float64).Value(k))
+			})
+			each("f32", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, r.Float32, a.(*array.Float32).Value(k))
+			})
+			each("d32", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, &arrow.Decimal128Type{Precision: 9, Scale: 5}, a.DataType())
+				require.Equal(t, r.D32.Int64(), a.(*array.Decimal128).Value(k).BigInt().Int64())
+			})
+			each("d64", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, &arrow.Decimal128Type{Precision: 18, Scale: 15}, a.DataType())
+				require.Equal(t, r.D64.Int64(), a.(*array.Decimal128).Value(k).BigInt().Int64())
+			})
+			each("d128", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, &arrow.Decimal128Type{Precision: 38, Scale: 18}, a.DataType())
+				require.Equal(t, r.D128.Int128().String(), a.(*array.Decimal128).Value(k).BigInt().String())
+			})
+			each("d256", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, &arrow.Decimal256Type{Precision: 76, Scale: 24}, a.DataType())
+				require.Equal(t, r.D256.Int256().String(), a.(*array.Decimal256).Value(k).BigInt().String())
+			})
+			each("i128", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, r.I128.String(), a.(*array.Decimal128).Value(k).BigInt().String())
+			})
+			each("i256", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, r.I256.String(), a.(*array.Decimal256).Value(k).BigInt().String())
+			})
+			each("bool", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, r.Bool, a.(*array.Boolean).Value(k))
+			})
+			each("time", func(a arrow.Array, k int, r ParquetRow) {
+				tu := a.DataType().(*arrow.TimestampType).Unit
+				require.Equal(t, arrow.Millisecond, tu)
+				require.Equal(t, r.Time, a.(*array.Timestamp).Value(k).ToTime(tu))
+			})
+			each("ts", func(a arrow.Array, k int, r ParquetRow) {
+				tu := a.DataType().(*arrow.TimestampType).Unit
+				require.Equal(t, arrow.Microsecond, tu)
+				require.Equal(t, r.Ts, a.(*array.Timestamp).Value(k).ToTime(tu))
+			})
+			each("date", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, r.Date, a.(*array.Date32).Value(k).ToTime())
+			})
+			each("tod", func(a arrow.Array, k int, r ParquetRow) {
+				tod := a.(*array.Time32).Value(k).ToTime(arrow.Millisecond)
+				require.Equal(t, r.Tod.Sub(r.Tod.Truncate(24*time.Hour)), tod.Sub(tod.Truncate(24*time.Hour)))
+			})
+			each("bytes", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, r.Hash, append([]byte{}, a.(*array.Binary).Value(k)...))
+			})
+			each("array2", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, r.Array[:], a.(*array.FixedSizeBinary).Value(k))
+			})
+			each("string", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, r.String, a.(*array.String).Value(k))
+			})
+			each("opt_i64", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, r.OptInt == nil, a.IsNull(k))
+				if r.OptInt != nil {
+					require.Equal(t, *r.OptInt, a.(*array.Int64).Value(k))
+				}
+			})
+			each("opt_str", func(a arrow.Array, k int, r ParquetRow) {
+				require.Equal(t, r.OptStr == nil, a.IsNull(k))
+				if r.OptStr != nil {
+					require.Equal(t, *r.OptStr, a.(*array.String).Value(k))
+				}
+			})
+		})
+	}
+}
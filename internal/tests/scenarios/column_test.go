@@ -0,0 +1,128 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+//
+// TestColumnAccess ensures typed column access on query results.
+// Ensures:
+// - columns of unselected results reference result memory without copy.
+// - selected results are copied in selection order.
+// - chunk iterators cover all rows in order.
+// - unknown columns and mismatched types fail.
+// - results export as Arrow IPC stream.
+
+package scenarios
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+
+	tests "blockwatch.cc/knoxdb/internal/tests/engine"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/knox"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"github.com/stretchr/testify/require"
+)
+
+type ColTick struct {
+	Id     uint64  `knox:"id,pk"`
+	Price  float64 `knox:"price"`
+	Amount int64   `knox:"amount"`
+}
+
+func TestColumnAccess(t *testing.T) {
+	eng, _ := tests.NewDatabase(t, &ColTick{})
+	db := knox.WrapEngine(eng)
+	ctx := context.Background()
+	defer db.Close(ctx)
+
+	table, err := db.FindTable("col_tick")
+	require.NoError(t, err, "Missing table")
+
+	const n = 300
+	ticks := make([]*ColTick, n)
+	for i := range ticks {
+		ticks[i] = &ColTick{Price: float64(i) / 2, Amount: int64(i) * 10}
+	}
+	_, _, err = table.Insert(ctx, ticks)
+	require.NoError(t, err)
+
+	res, err := knox.NewQuery().WithTable(table).Run(ctx)
+	require.NoError(t, err)
+	defer res.Close()
+	require.Equal(t, n, res.Len())
+
+	// zero copy access
+	amounts, err := knox.Column[int64](res, "amount")
+	require.NoError(t, err)
+	require.Len(t, amounts, n)
+	for i, v := range amounts {
+		require.Equal(t, ticks[i].Amount, v, "row %d", i)
+	}
+	idx, _ := res.Schema().Index("amount")
+	require.Same(t, &res.Pack().Block(idx).Int64().Slice()[0], &amounts[0], "zero copy")
+
+	// chunked access
+	var (
+		prices []float64
+		next   int
+	)
+	chunks, err := knox.Chunks[float64](res, "price")
+	require.NoError(t, err)
+	for ofs, chunk := range chunks {
+		require.Equal(t, next, ofs)
+		require.LessOrEqual(t, len(chunk), types.CHUNK_SIZE)
+		prices = append(prices, chunk...)
+		next += len(chunk)
+	}
+	require.Len(t, prices, n)
+	for i, v := range prices {
+		require.Equal(t, ticks[i].Price, v, "row %d", i)
+	}
+
+	// selected rows
+	sel := []uint32{299, 7, 130, 0}
+	res.Pack().WithSelection(sel)
+	amounts, err = knox.Column[int64](res, "amount")
+	require.NoError(t, err)
+	prices = prices[:0]
+	chunks, err = knox.Chunks[float64](res, "price")
+	require.NoError(t, err)
+	for _, chunk := range chunks {
+		prices = append(prices, chunk...)
+	}
+	for i, row := range sel {
+		require.Equal(t, ticks[row].Amount, amounts[i])
+		require.Equal(t, ticks[row].Price, prices[i])
+	}
+	res.Pack().WithSelection(nil)
+
+	// errors
+	_, err = knox.Column[int64](res, "missing")
+	require.ErrorIs(t, err, schema.ErrInvalidField)
+	_, err = knox.Column[float64](res, "amount")
+	require.ErrorIs(t, err, schema.ErrInvalidValueType)
+	_, err = knox.Chunks[uint32](res, "id")
+	require.ErrorIs(t, err, schema.ErrInvalidValueType)
+
+	// arrow stream: schema, one record batch, end of stream
+	var buf bytes.Buffer
+	require.NoError(t, knox.NewQuery().WithTable(table).ExportArrow(ctx, &buf))
+	data := buf.Bytes()
+	var msgs int
+	for {
+		require.Equal(t, uint32(0xFFFFFFFF), binary.LittleEndian.Uint32(data))
+		size := int(binary.LittleEndian.Uint32(data[4:]))
+		if size == 0 {
+			require.Len(t, data, 8)
+			break
+		}
+		msgs++
+		data = data[8+size:]
+		if msgs == 2 {
+			// skip record batch body: 3 columns, 8 byte values
+			data = data[3*8*n:]
+		}
+	}
+	require.Equal(t, 2, msgs)
+}
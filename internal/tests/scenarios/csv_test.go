@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+//
+// TestCSVImportExport ensures CSV bulk import loads new tables.
+// Ensures:
+// - the transaction which creates a table may import into it.
+// - journal inserts continue after imported primary keys.
+// - imported and inserted rows are visible to later queries.
+// - later transactions cannot import into the table.
+// - query results export as CSV including a header line.
+//
+// TestCSVImportAbort ensures aborted imports leave no table behind.
+
+package scenarios
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	tests "blockwatch.cc/knoxdb/internal/tests/engine"
+	"blockwatch.cc/knoxdb/pkg/knox"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"github.com/stretchr/testify/require"
+)
+
+type CsvTrade struct {
+	Id     uint64  `knox:"id,pk"`
+	Market string  `knox:"market"`
+	Price  float64 `knox:"price"`
+	Buy    bool    `knox:"buy"`
+}
+
+const csvTrades = `id,market,price,buy
+0,eth,1.5,true
+0,btc,2.25,false
+0,eth,3,true
+`
+
+func TestCSVImportExport(t *testing.T) {
+	eng, _ := tests.NewDatabase(t)
+	db := knox.WrapEngine(eng)
+	ctx := context.Background()
+	defer db.Close(ctx)
+
+	// create and import in the same transaction
+	tctx, commit, abort, err := db.Begin(ctx)
+	require.NoError(t, err)
+	defer abort()
+	opts := tests.NewTestTableOptions(t, "", "")
+	table, err := db.CreateTable(tctx, schema.MustSchemaOf(&CsvTrade{}), opts.TableOptions()...)
+	require.NoError(t, err, "Failed to create table")
+
+	pk, n, err := table.ImportCSV(tctx, strings.NewReader(csvTrades), knox.DefaultCSVOptions())
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), pk, "first pk")
+	require.Equal(t, 3, n, "imported rows")
+	require.Equal(t, int64(0), table.Metrics().JournalTuples, "journal tuples")
+	require.NoError(t, commit())
+
+	// journal inserts continue after imported rows
+	pk, _, err = table.Insert(ctx, []*CsvTrade{
+		{Market: "sol", Price: 0.5, Buy: true},
+		{Market: "btc", Price: 4},
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), pk, "next pk")
+
+	// the table exists now, later imports fail
+	_, _, err = table.ImportCSV(ctx, strings.NewReader(csvTrades), knox.DefaultCSVOptions())
+	require.ErrorIs(t, err, knox.ErrTableNotNew)
+
+	cnt, err := knox.NewGenericQuery[CsvTrade]().
+		WithTable(table).
+		AndEqual("market", "eth").
+		Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, cnt, "imported rows")
+
+	var buf bytes.Buffer
+	err = knox.NewQuery().
+		WithTable(table).
+		AndGte("id", 2).
+		ExportCSV(ctx, &buf, knox.DefaultCSVOptions())
+	require.NoError(t, err)
+	require.Equal(t, `id,market,price,buy
+2,btc,2.25,false
+3,eth,3,true
+4,sol,0.5,true
+5,btc,4,false
+`, buf.String())
+}
+
+func TestCSVImportAbort(t *testing.T) {
+	eng, _ := tests.NewDatabase(t)
+	db := knox.WrapEngine(eng)
+	ctx := context.Background()
+	defer db.Close(ctx)
+
+	tctx, _, abort, err := db.Begin(ctx)
+	require.NoError(t, err)
+	opts := tests.NewTestTableOptions(t, "", "")
+	table, err := db.CreateTable(tctx, schema.MustSchemaOf(&CsvTrade{}), opts.TableOptions()...)
+	require.NoError(t, err, "Failed to create table")
+
+	_, n, err := table.ImportCSV(tctx, strings.NewReader(csvTrades), knox.DefaultCSVOptions())
+	require.NoError(t, err)
+	require.Equal(t, 3, n, "imported rows")
+	require.NoError(t, abort())
+
+	// abort drops the table together with imported packs
+	_, err = db.FindTable("csv_trade")
+	require.ErrorIs(t, err, knox.ErrNoTable)
+}
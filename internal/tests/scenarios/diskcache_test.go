@@ -0,0 +1,99 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+//
+// TestDiskCache ensures the persistent second tier block cache serves
+// table reads.
+// Ensures:
+// - blocks read from storage are added to the disk tier.
+// - the disk tier survives restarts and serves cold reads.
+// - new pack versions replace older cached versions.
+// - truncated tables drop their cached blocks.
+
+package scenarios
+
+import (
+	"context"
+	"testing"
+
+	tests "blockwatch.cc/knoxdb/internal/tests/engine"
+	"blockwatch.cc/knoxdb/pkg/knox"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"github.com/stretchr/testify/require"
+)
+
+type DiskCacheRow struct {
+	Id    uint64 `knox:"id,pk"`
+	Name  string `knox:"name"`
+	Value int64  `knox:"value"`
+}
+
+func TestDiskCache(t *testing.T) {
+	ctx := context.Background()
+	dbo := tests.NewTestDatabaseOptions(t, "")
+	dbo.DiskCachePath = t.TempDir()
+	dbo.DiskCacheSize = 16 << 20
+	eng := tests.NewTestEngine(t, dbo)
+	s, err := schema.SchemaOf(&DiskCacheRow{})
+	require.NoError(t, err)
+	s = s.WithMeta()
+	_, err = eng.CreateTable(ctx, s, tests.NewTestTableOptions(t, "", "").TableOptions()...)
+	require.NoError(t, err)
+	for _, is := range s.Indexes {
+		_, err = eng.CreateIndex(ctx, is, tests.NewTestIndexOptions(t, "", "").IndexOptions()...)
+		require.NoError(t, err)
+	}
+	db := knox.WrapEngine(eng)
+	defer func() { db.Close(ctx) }()
+
+	table, err := db.FindTable("disk_cache_row")
+	require.NoError(t, err, "Missing table")
+
+	const n = 1000
+	rows := make([]*DiskCacheRow, n)
+	for i := range rows {
+		rows[i] = &DiskCacheRow{Name: "row", Value: int64(i)}
+	}
+	_, _, err = table.Insert(ctx, rows)
+	require.NoError(t, err)
+	require.NoError(t, db.FlushTable(ctx, "disk_cache_row"))
+
+	sum := func(want int) (total int64) {
+		res, err := knox.NewGenericQuery[DiskCacheRow]().WithTable(table).Run(ctx)
+		require.NoError(t, err)
+		require.Len(t, res, want)
+		for _, r := range res {
+			total += r.Value
+		}
+		return
+	}
+
+	// cold reads from storage populate the disk tier
+	require.Equal(t, int64(n*(n-1)/2), sum(n))
+	stats := eng.DiskCacheStats()
+	require.Positive(t, stats.Inserts)
+	require.Zero(t, stats.Hits)
+	blocks := stats.Count
+
+	// after restart reads are served from the disk tier
+	require.NoError(t, db.Close(ctx))
+	eng = tests.OpenTestEngine(t, dbo)
+	db = knox.WrapEngine(eng)
+	table, err = db.FindTable("disk_cache_row")
+	require.NoError(t, err, "Missing table")
+	require.Equal(t, blocks, eng.DiskCacheStats().Count)
+	require.Equal(t, int64(n*(n-1)/2), sum(n))
+	stats = eng.DiskCacheStats()
+	require.Equal(t, blocks, stats.Hits)
+	require.Zero(t, stats.Inserts)
+
+	// deletes write a new pack version which replaces cached blocks
+	_, err = knox.NewQuery().WithTable(table).AndRange("id", 1, 100).Delete(ctx)
+	require.NoError(t, err)
+	require.NoError(t, db.FlushTable(ctx, "disk_cache_row"))
+	require.Equal(t, int64(n*(n-1)/2-100*99/2), sum(n-100))
+	require.Equal(t, blocks, eng.DiskCacheStats().Count)
+
+	// truncate drops cached blocks
+	require.NoError(t, db.TruncateTable(ctx, "disk_cache_row"))
+	require.Zero(t, eng.DiskCacheStats().Count)
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+//
+// TestSeriesFill ensures fill modes, time-weighted averages and as-of
+// joins of time-series results.
+// Ensures:
+// - previous, next, linear and constant fills output null where a
+//   required neighbour is missing.
+// - time-weighted averages carry the last sample across window boundaries.
+// - as-of joins align series of different intervals with last value
+//   carried forward, for grouped and ungrouped series.
+// - joined results encode as JSON and CSV.
+
+package scenarios
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"blockwatch.cc/knoxdb/internal/reducer"
+	tests "blockwatch.cc/knoxdb/internal/tests/engine"
+	"blockwatch.cc/knoxdb/pkg/knox"
+	"blockwatch.cc/knoxdb/pkg/series"
+	"blockwatch.cc/knoxdb/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+type FillBalance struct {
+	Id      uint64    `knox:"id,pk"`
+	Time    time.Time `knox:"time,scale=s"`
+	Account string    `knox:"account"`
+	Balance float64   `knox:"balance"`
+}
+
+type FillPrice struct {
+	Id     uint64    `knox:"id,pk"`
+	Time   time.Time `knox:"time,scale=s"`
+	Symbol string    `knox:"symbol"`
+	Price  float64   `knox:"price"`
+}
+
+func TestSeriesFill(t *testing.T) {
+	eng, _ := tests.NewDatabase(t, &FillBalance{}, &FillPrice{})
+	db := knox.WrapEngine(eng)
+	ctx := context.Background()
+	defer db.Close(ctx)
+
+	balances, err := db.FindTable("fill_balance")
+	require.NoError(t, err, "Missing table")
+	prices, err := db.FindTable("fill_price")
+	require.NoError(t, err, "Missing table")
+
+	// account a changes balance at hours 1 and 4, account b at
+	// 00:00, 00:30 and 01:15 on the first day
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, _, err = balances.Insert(ctx, []*FillBalance{
+		{Time: base, Account: "b", Balance: 10},
+		{Time: base.Add(30 * time.Minute), Account: "b", Balance: 20},
+		{Time: base.Add(time.Hour), Account: "a", Balance: 10},
+		{Time: base.Add(75 * time.Minute), Account: "b", Balance: 40},
+		{Time: base.Add(4 * time.Hour), Account: "a", Balance: 40},
+	})
+	require.NoError(t, err)
+
+	// daily prices starting on the second day
+	_, _, err = prices.Insert(ctx, []*FillPrice{
+		{Time: base.Add(25 * time.Hour), Symbol: "x", Price: 200},
+		{Time: base.Add(49 * time.Hour), Symbol: "x", Price: 300},
+	})
+	require.NoError(t, err)
+
+	ctx, _, abort, err := db.Begin(ctx, knox.TxFlagReadOnly)
+	require.NoError(t, err)
+	defer abort()
+
+	run := func(table knox.Table, cols, group, fill string, ival util.TimeUnit, to time.Duration) *series.Result {
+		t.Helper()
+		req := series.NewRequest().
+			WithTable(table.Engine()).
+			WithRange(util.TimeRange{From: base, To: base.Add(to)}).
+			WithInterval(ival).
+			WithGroupBy(group).
+			WithFill(reducer.ParseFillMode(fill))
+		req.Table = table.Schema().Name
+		require.NoError(t, req.Select.UnmarshalText([]byte(cols)))
+		req.Sanitize()
+		res, err := req.Run(ctx, "series")
+		require.NoError(t, err)
+		return res
+	}
+	column := func(res *series.Result, group string, col int) []any {
+		t.Helper()
+		var vals []any
+		for row := range res.Rows() {
+			if row.Group == group {
+				vals = append(vals, row.Values[col])
+			}
+		}
+		return vals
+	}
+
+	// fill modes
+	hour := util.TimeUnitHour
+	for _, c := range []struct {
+		fill string
+		want []any
+	}{
+		{"null", []any{nil, 10.0, nil, nil, 40.0, nil}},
+		{"previous", []any{nil, 10.0, 10.0, 10.0, 40.0, 40.0}},
+		{"next", []any{10.0, 10.0, 40.0, 40.0, 40.0, nil}},
+		{"linear", []any{nil, 10.0, 20.0, 30.0, 40.0, nil}},
+		{"-1.5", []any{-1.5, 10.0, -1.5, -1.5, 40.0, -1.5}},
+		{"zero", []any{0.0, 10.0, 0.0, 0.0, 40.0, 0.0}},
+	} {
+		res := run(balances, "last(balance)", "account", c.fill, hour, 5*time.Hour)
+		require.Equal(t, c.want, column(res, "a", 1), "fill %s", c.fill)
+	}
+
+	// time-weighted averages span window boundaries
+	res := run(balances, "twa(balance)", "account", "none", hour, time.Hour)
+	twa, err := series.GetResultVector[float64](res, "b", "balance")
+	require.NoError(t, err)
+	require.Equal(t, []float64{15, 35}, twa)
+	twa, err = series.GetResultVector[float64](res, "a", "balance")
+	require.NoError(t, err)
+	require.Equal(t, []float64{10}, twa)
+
+	// ungrouped daily prices joined to balances per 6 hours
+	six := util.MustParseTimeUnit("6h")
+	bal := run(balances, "last(balance)", "account", "previous", six, 42*time.Hour)
+	px := run(prices, "last(price)", "", "none", util.TimeUnitDay, 42*time.Hour)
+	joined, err := bal.JoinAsOf(px)
+	require.NoError(t, err)
+	require.Equal(t, []string{"time", "balance", "price"}, joined.Columns())
+	price := []any{nil, nil, nil, nil, 200.0, 200.0, 200.0, 200.0}
+	require.Equal(t, price, column(joined, "a", 2))
+	require.Equal(t, price, column(joined, "b", 2))
+	require.Equal(t, []any{40.0, 40.0, 40.0, 40.0, 40.0, 40.0, 40.0, 40.0}, column(joined, "a", 1))
+
+	// grouped joins match groups and prefix duplicate column names
+	peak := run(balances, "max(balance)", "account", "none", util.TimeUnitDay, 42*time.Hour)
+	joined, err = bal.JoinAsOf(peak)
+	require.NoError(t, err)
+	require.Equal(t, []string{"time", "balance", "fill_balance.balance"}, joined.Columns())
+	require.Equal(t, []any{40.0, 40.0, 40.0, 40.0, 40.0, 40.0, 40.0, 40.0}, column(joined, "a", 2))
+	require.Equal(t, []any{40.0, 40.0, 40.0, 40.0, 40.0, 40.0, 40.0, 40.0}, column(joined, "b", 2))
+
+	// groups must match
+	_, err = bal.JoinAsOf(run(prices, "last(price)", "symbol", "none", util.TimeUnitDay, 42*time.Hour))
+	require.Error(t, err)
+
+	// joined results encode like other results
+	joined, err = bal.JoinAsOf(px)
+	require.NoError(t, err)
+	buf, err := json.Marshal(joined)
+	require.NoError(t, err)
+	var out struct {
+		Series []struct {
+			Columns []string            `json:"columns"`
+			Values  [][]json.RawMessage `json:"values"`
+		} `json:"series"`
+	}
+	require.NoError(t, json.Unmarshal(buf, &out))
+	require.Len(t, out.Series, 2)
+	require.Equal(t, []string{"time", "balance", "price"}, out.Series[0].Columns)
+	require.Equal(t, "null", string(out.Series[0].Values[2][0]))
+	require.Equal(t, "200", string(out.Series[0].Values[2][4]))
+
+	var csv bytes.Buffer
+	require.NoError(t, joined.WriteCSV(&csv))
+	require.Contains(t, csv.String(), "a,2025-01-02T00:00:00Z,40,200")
+	require.NoError(t, joined.WriteArrow(&csv))
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+//
+// TestFlushReopen ensures fully merged tables remain visible after restart.
+// Ensures:
+// - flush merges all journal segments into table packs.
+// - the tx horizon is restored from pack data when no WAL records remain.
+
+package scenarios
+
+import (
+	"context"
+	"testing"
+
+	tests "blockwatch.cc/knoxdb/internal/tests/engine"
+	"blockwatch.cc/knoxdb/pkg/knox"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlushReopen(t *testing.T) {
+	// setup determinism
+	SetupDeterministicRand(t)
+
+	eng, _ := tests.NewDatabase(t, &tests.Types{})
+	dbo := eng.Options()
+	db := knox.WrapEngine(eng)
+	table, err := db.FindTable("types")
+	require.NoError(t, err, "Missing table")
+
+	ctx := context.Background()
+	const txnSize = 100
+
+	data := make([]*tests.Types, txnSize)
+	for i := range txnSize {
+		data[i] = tests.NewRandomTypes(i)
+	}
+	_, _, err = table.Insert(ctx, data)
+	require.NoError(t, err, "Failed to insert data")
+
+	// merge the entire journal
+	require.NoError(t, db.FlushTable(ctx, "types"))
+	m := table.Metrics()
+	require.Equal(t, int64(0), m.JournalTuples, "journal tuples")
+	require.Equal(t, int64(1), m.PacksCount, "packs")
+	require.NoError(t, db.Close(ctx))
+
+	// reopen and count
+	eng = tests.OpenTestEngine(t, dbo)
+	db = knox.WrapEngine(eng)
+	defer db.Close(ctx)
+	table, err = db.FindTable("types")
+	require.NoError(t, err, "Missing table")
+	n, err := knox.NewGenericQuery[tests.Types]().WithTable(table).Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, txnSize, n, "Row count mismatch")
+}
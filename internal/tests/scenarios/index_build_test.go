@@ -0,0 +1,152 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+//
+// TestIndexBuild ensures indexes created on populated tables are built
+// in the background.
+// Ensures:
+// - new indexes stay in building state until the build completes.
+// - rows inserted while a build is pending or running are indexed once.
+// - cancelled builds never start and resume on next open.
+// - builds pending at close resume after reopen.
+// - progress is monotonic, between 0 and 1 and reaches 1 when ready.
+// - the resumed index matches a full rebuild.
+
+package scenarios
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"blockwatch.cc/knoxdb/internal/engine"
+	tests "blockwatch.cc/knoxdb/internal/tests/engine"
+	"blockwatch.cc/knoxdb/pkg/knox"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"github.com/stretchr/testify/require"
+)
+
+type IndexBuildRow struct {
+	Id    uint64 `knox:"id,pk"`
+	Group int64  `knox:"group,index=int"`
+}
+
+func TestIndexBuild(t *testing.T) {
+	ctx := context.Background()
+	dbo := tests.NewTestDatabaseOptions(t, "")
+	dbo.MaxWorkers = 1
+	eng := tests.NewTestEngine(t, dbo)
+	s, err := schema.SchemaOf(&IndexBuildRow{})
+	require.NoError(t, err)
+	s = s.WithMeta()
+	_, err = eng.CreateTable(ctx, s, tests.NewTestTableOptions(t, "", "").TableOptions()...)
+	require.NoError(t, err)
+	db := knox.WrapEngine(eng)
+	defer func() { db.Close(ctx) }()
+
+	table, err := db.FindTable("index_build_row")
+	require.NoError(t, err, "Missing table")
+
+	const groups = 10
+	var nrows int
+	insert := func(table knox.Table, n int) {
+		t.Helper()
+		rows := make([]*IndexBuildRow, n)
+		for i := range rows {
+			rows[i] = &IndexBuildRow{Group: int64((nrows + i) % groups)}
+		}
+		_, _, err := table.Insert(ctx, rows)
+		require.NoError(t, err)
+		require.NoError(t, db.FlushTable(ctx, "index_build_row"))
+		nrows += n
+	}
+	count := func(table knox.Table, group int64) int {
+		t.Helper()
+		n, err := knox.NewQuery().WithTable(table).AndEqual("group", group).Count(ctx)
+		require.NoError(t, err)
+		return n
+	}
+	check := func(idx knox.Index, table knox.Table) {
+		t.Helper()
+		require.Equal(t, knox.IndexStatusReady, idx.Status())
+		require.Equal(t, 1.0, idx.Progress())
+		require.Equal(t, int64(nrows), idx.Metrics().TupleCount)
+		for g := range int64(groups) {
+			require.Equal(t, nrows/groups, count(table, g), "group %d", g)
+		}
+	}
+	insert(table, 2000)
+
+	// occupy the only worker so the build stays pending
+	release := make(chan struct{})
+	require.True(t, eng.Schedule(engine.NewTask(func(ctx context.Context) error {
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return nil
+	})))
+
+	// create index on the populated table
+	is := s.Indexes[0]
+	_, err = eng.CreateIndex(ctx, is, tests.NewTestIndexOptions(t, "", "").IndexOptions()...)
+	require.NoError(t, err)
+	idx, err := db.FindIndex(is.Name)
+	require.NoError(t, err)
+	require.Equal(t, knox.IndexStatusBuilding, idx.Status())
+	require.Equal(t, 0.0, idx.Progress())
+
+	// table merges index new rows while the build is pending
+	insert(table, 500)
+
+	// cancel the pending build, it must not start
+	b, ok := eng.GetIndexBuild(is.Name)
+	require.True(t, ok, "missing build")
+	b.Cancel()
+	close(release)
+	require.ErrorIs(t, b.Wait(ctx), engine.ErrTaskAborted)
+	require.NoError(t, idx.Cancel(ctx))
+	require.Equal(t, knox.IndexStatusBuilding, idx.Status())
+	require.Equal(t, 0.0, idx.Progress())
+
+	// reopen resumes the build, insert concurrently and sample progress
+	require.NoError(t, db.Close(ctx))
+	dbo.MaxWorkers = 2
+	eng = tests.OpenTestEngine(t, dbo)
+	db = knox.WrapEngine(eng)
+	table, err = db.FindTable("index_build_row")
+	require.NoError(t, err, "Missing table")
+	idx, err = db.FindIndex(is.Name)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var last float64
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			p := idx.Progress()
+			if p < last || p > 1 {
+				t.Errorf("invalid progress %f after %f", p, last)
+				return
+			}
+			last = p
+		}
+	}()
+	insert(table, 500)
+	require.NoError(t, idx.Wait(ctx))
+	close(done)
+	wg.Wait()
+	require.NoError(t, db.FlushTable(ctx, "index_build_row"))
+	check(idx, table)
+
+	// the resumed index equals a full rebuild
+	require.NoError(t, db.RebuildIndex(ctx, is.Name))
+	require.NoError(t, idx.Wait(ctx))
+	check(idx, table)
+}
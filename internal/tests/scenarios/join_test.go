@@ -0,0 +1,78 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+//
+// TestJoinInner ensures equi-joins return all matching row pairs.
+// Ensures:
+// - the small join side is queried without limit.
+// - left rows are merged in join field order across fetch blocks.
+// - joined rows contain values from both tables.
+
+package scenarios
+
+import (
+	"context"
+	"testing"
+
+	tests "blockwatch.cc/knoxdb/internal/tests/engine"
+	"blockwatch.cc/knoxdb/pkg/knox"
+	"github.com/stretchr/testify/require"
+)
+
+type JoinTrade struct {
+	Id     uint64 `knox:"id,pk"`
+	User   uint64 `knox:"user"`
+	Volume int64  `knox:"volume"`
+}
+
+type JoinUser struct {
+	Id   uint64 `knox:"id,pk"`
+	Name string `knox:"name"`
+}
+
+func TestJoinInner(t *testing.T) {
+	eng, _ := tests.NewDatabase(t, &JoinTrade{}, &JoinUser{})
+	db := knox.WrapEngine(eng)
+	ctx := context.Background()
+	defer db.Close(ctx)
+
+	trades, err := db.FindTable("join_trade")
+	require.NoError(t, err, "Missing table")
+	users, err := db.FindTable("join_user")
+	require.NoError(t, err, "Missing table")
+
+	names := []string{"alice", "bob", "carol"}
+	for _, n := range names {
+		_, _, err = users.Insert(ctx, &JoinUser{Name: n})
+		require.NoError(t, err)
+	}
+	data := make([]*JoinTrade, 20)
+	for i := range data {
+		data[i] = &JoinTrade{User: uint64(i%3 + 1), Volume: int64(i)}
+	}
+	_, _, err = trades.Insert(ctx, data)
+	require.NoError(t, err)
+
+	res, err := knox.NewJoin().
+		WithTables(trades, users).
+		WithOnEqual("user", "id").
+		WithSelects([]string{"id", "user", "volume"}, []string{"id", "name"}).
+		WithAliases([]string{"", "", ""}, []string{"", ""}).
+		WithLimit(15).
+		Run(ctx)
+	require.NoError(t, err)
+	defer res.Close()
+	require.Equal(t, 15, res.Len())
+
+	// result columns are left selects followed by right selects
+	seen := make(map[uint64]bool)
+	for i := range res.Len() {
+		id := res.Value(i, 0).(uint64)
+		user := res.Value(i, 1).(uint64)
+		require.Equal(t, int64(id-1), res.Value(i, 2), "volume")
+		require.Equal(t, user, res.Value(i, 3), "user id")
+		require.Equal(t, names[user-1], string(res.Value(i, 4).([]byte)), "user name")
+		require.Equal(t, (id-1)%3+1, user, "join match")
+		require.False(t, seen[id], "duplicate row %d", id)
+		seen[id] = true
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+//
+// TestParquetExportImport ensures Parquet files round trip between tables.
+// Ensures:
+// - query results export as Parquet row groups.
+// - exports stream one row group per table pack and journal segment.
+// - import into a table created in the same transaction preserves
+//   primary keys including gaps from deleted rows.
+// - importing keys below existing keys fails.
+// - journal inserts continue after the largest imported key.
+
+package scenarios
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	tests "blockwatch.cc/knoxdb/internal/tests/engine"
+	"blockwatch.cc/knoxdb/pkg/knox"
+	"blockwatch.cc/knoxdb/pkg/num"
+	"blockwatch.cc/knoxdb/pkg/parquet"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"github.com/stretchr/testify/require"
+)
+
+type PqTrade struct {
+	Id     uint64        `knox:"id,pk"`
+	Market string        `knox:"market"`
+	Price  num.Decimal64 `knox:"price,scale=2"`
+	Time   time.Time     `knox:"time,scale=ms"`
+	Note   *string       `knox:"note"`
+}
+
+type PqTradeCopy PqTrade
+
+func TestParquetExportImport(t *testing.T) {
+	eng, _ := tests.NewDatabase(t, &PqTrade{})
+	db := knox.WrapEngine(eng)
+	ctx := context.Background()
+	defer db.Close(ctx)
+
+	src, err := db.FindTable("pq_trade")
+	require.NoError(t, err, "Missing table")
+
+	note := "first"
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	var trades []*PqTrade
+	for i := range 5 {
+		trades = append(trades, &PqTrade{
+			Market: []string{"eth", "btc"}[i%2],
+			Price:  num.NewDecimal64(int64(100+i), 2),
+			Time:   now.Add(time.Duration(i) * time.Millisecond),
+		})
+	}
+	trades[0].Note = &note
+	_, _, err = src.Insert(ctx, trades)
+	require.NoError(t, err)
+
+	n, err := knox.NewQuery().WithTable(src).AndIn("id", []uint64{2, 4}).Delete(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, n, "deleted rows")
+
+	var buf bytes.Buffer
+	opts := knox.DefaultParquetOptions()
+	opts.RowGroupSize = 2
+	require.NoError(t, knox.NewQuery().WithTable(src).ExportParquet(ctx, &buf, opts))
+	require.Equal(t, []int{2, 1}, parquetRowGroups(t, buf.Bytes()), "row groups")
+
+	// create and import in the same transaction
+	tctx, commit, abort, err := db.Begin(ctx)
+	require.NoError(t, err)
+	defer abort()
+	topts := tests.NewTestTableOptions(t, "", "")
+	dst, err := db.CreateTable(tctx, schema.MustSchemaOf(&PqTradeCopy{}), topts.TableOptions()...)
+	require.NoError(t, err, "Failed to create table")
+
+	pk, n, err := dst.ImportParquet(tctx, bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), pk, "first pk")
+	require.Equal(t, 3, n, "imported rows")
+
+	// importing existing keys again fails
+	_, _, err = dst.ImportParquet(tctx, bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.ErrorIs(t, err, knox.ErrPkOutOfOrder)
+	require.NoError(t, commit())
+
+	res, err := knox.NewGenericQuery[PqTradeCopy]().WithTable(dst).Run(ctx)
+	require.NoError(t, err)
+	require.Len(t, res, 3)
+	for i, id := range []uint64{1, 3, 5} {
+		exp := PqTradeCopy(*trades[id-1])
+		exp.Id = id
+		require.Equal(t, exp, res[i], "row %d", id)
+	}
+
+	// journal inserts continue after imported rows
+	pk, _, err = dst.Insert(ctx, &PqTradeCopy{Market: "sol"})
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), pk, "next pk")
+}
+
+func TestParquetExportPacks(t *testing.T) {
+	eng, _ := tests.NewDatabase(t, &PqTrade{})
+	db := knox.WrapEngine(eng)
+	ctx := context.Background()
+	defer db.Close(ctx)
+
+	src, err := db.FindTable("pq_trade")
+	require.NoError(t, err, "Missing table")
+
+	// store full table packs plus a journal segment
+	packSize := tests.NewTestTableOptions(t, "", "").PackSize
+	insert := func(n int) {
+		trades := make([]*PqTrade, n)
+		for i := range trades {
+			trades[i] = &PqTrade{Market: "eth", Price: num.NewDecimal64(int64(i), 2)}
+		}
+		_, _, err := src.Insert(ctx, trades)
+		require.NoError(t, err)
+	}
+	insert(2 * packSize)
+	require.NoError(t, db.FlushTable(ctx, "pq_trade"))
+	insert(10)
+	require.Equal(t, 2, int(src.Metrics().PacksCount), "table packs")
+
+	export := func(q knox.Query, opts knox.ParquetOptions) []int {
+		var buf bytes.Buffer
+		require.NoError(t, q.WithTable(src).ExportParquet(ctx, &buf, opts))
+		return parquetRowGroups(t, buf.Bytes())
+	}
+	opts := knox.DefaultParquetOptions()
+	require.Equal(t, []int{packSize, packSize, 10}, export(knox.NewQuery(), opts))
+
+	// row groups only contain matching rows, limits apply across packs
+	require.Equal(t, []int{1, 1, 1}, export(knox.NewQuery().AndIn("id", []uint64{1, uint64(packSize + 1), uint64(2*packSize + 1)}), opts))
+	require.Equal(t, []int{packSize, 1}, export(knox.NewQuery().WithLimit(packSize+1), opts))
+	require.Equal(t, []int{10, packSize, packSize}, export(knox.NewQuery().WithDesc(), opts))
+	require.Empty(t, export(knox.NewQuery().AndEqual("market", "btc"), opts))
+
+	// large packs are split
+	opts.RowGroupSize = packSize - 1
+	require.Equal(t, []int{packSize - 1, 1, packSize - 1, 1, 10}, export(knox.NewQuery(), opts))
+}
+
+// parquetRowGroups returns the row counts of all row groups in file buf.
+func parquetRowGroups(t *testing.T, buf []byte) []int {
+	t.Helper()
+	pr, err := parquet.NewReader(bytes.NewReader(buf), int64(len(buf)))
+	require.NoError(t, err)
+	var res []int
+	for i := range pr.NumRowGroups() {
+		res = append(res, pr.RowGroupLen(i))
+	}
+	return res
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+//
+// TestSeriesReducers ensures order statistics, distinct counts and
+// histograms in time-series requests.
+// Ensures:
+// - small windows produce exact percentiles, medians, modes and histograms.
+// - large windows estimate percentiles, distinct counts and histograms.
+// - decimal columns support counts, percentiles, modes and distinct counts.
+// - histograms encode as JSON objects and fill as null.
+
+package scenarios
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"blockwatch.cc/knoxdb/internal/reducer"
+	tests "blockwatch.cc/knoxdb/internal/tests/engine"
+	"blockwatch.cc/knoxdb/pkg/knox"
+	"blockwatch.cc/knoxdb/pkg/num"
+	"blockwatch.cc/knoxdb/pkg/series"
+	"blockwatch.cc/knoxdb/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+type ReducerTick struct {
+	Id     uint64        `knox:"id,pk"`
+	Time   time.Time     `knox:"time,scale=s"`
+	Market string        `knox:"market"`
+	Price  float64       `knox:"price"`
+	Qty    int64         `knox:"qty"`
+	Amount num.Decimal64 `knox:"amount,scale=2"`
+}
+
+func TestSeriesReducers(t *testing.T) {
+	eng, _ := tests.NewDatabase(t, &ReducerTick{})
+	db := knox.WrapEngine(eng)
+	ctx := context.Background()
+	defer db.Close(ctx)
+
+	table, err := db.FindTable("reducer_tick")
+	require.NoError(t, err, "Missing table")
+
+	// first hour: 9 small ticks, second hour: 5000 ticks, third hour empty
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var ticks []*ReducerTick
+	for i := range 9 {
+		ticks = append(ticks, &ReducerTick{
+			Time:   base.Add(time.Duration(i) * time.Minute),
+			Market: []string{"a", "b"}[i%2],
+			Price:  float64(9 - i),
+			Qty:    []int64{3, 1, 2, 2, 3, 3, 1, 2, 3}[i],
+			Amount: num.NewDecimal64(int64(i%3)*125, 2),
+		})
+	}
+	const large = 5000
+	for i := range large {
+		ticks = append(ticks, &ReducerTick{
+			Time:   base.Add(time.Hour + time.Duration(i%3600)*time.Second),
+			Market: "a",
+			Price:  float64((i * 7919) % large),
+			Qty:    int64(i % 3000),
+			Amount: num.NewDecimal64(int64(i%100), 2),
+		})
+	}
+	_, _, err = table.Insert(ctx, ticks)
+	require.NoError(t, err)
+
+	ctx, _, abort, err := db.Begin(ctx, knox.TxFlagReadOnly)
+	require.NoError(t, err)
+	defer abort()
+
+	run := func(cols, group string) *series.Result {
+		req := series.NewRequest().
+			WithTable(table.Engine()).
+			WithRange(util.TimeRange{From: base, To: base.Add(2 * time.Hour)}).
+			WithInterval(util.TimeUnitHour).
+			WithGroupBy(group).
+			WithFill("null")
+		req.Table = "reducer_tick"
+		require.NoError(t, req.Select.UnmarshalText([]byte(cols)))
+		req.Sanitize()
+		res, err := req.Run(ctx, "series")
+		require.NoError(t, err)
+		return res
+	}
+	floats := func(res *series.Result, group, col string) []float64 {
+		v, err := series.GetResultVector[float64](res, group, col)
+		require.NoError(t, err)
+		return v
+	}
+
+	// exact order statistics in small windows, estimates in large windows
+	res := run("median(price)", "")
+	v := floats(res, "", "price")
+	require.Equal(t, float64(5), v[0])
+	require.InDelta(t, 2499.5, v[1], large*0.005)
+	res = run("p90(price)", "")
+	v = floats(res, "", "price")
+	require.InDelta(t, 8.2, v[0], 1e-9)
+	require.InDelta(t, 4499.1, v[1], large*0.01)
+	res = run("p99(price)", "")
+	v = floats(res, "", "price")
+	require.InDelta(t, 8.92, v[0], 1e-9)
+	require.InDelta(t, 4949.01, v[1], large*0.005)
+	res = run("p50(price)", "market")
+	require.Equal(t, float64(5), floats(res, "a", "price")[0])
+	require.Equal(t, []float64{5, 0, 0}, floats(res, "b", "price"))
+
+	// mode prefers the smallest value on ties, distinct counts are exact
+	// for small windows and estimated for large windows
+	res = run("mode(qty)", "")
+	qty, err := series.GetResultVector[int64](res, "", "qty")
+	require.NoError(t, err)
+	require.Equal(t, int64(3), qty[0])
+	require.Equal(t, int64(0), qty[1])
+	res = run("count_distinct(qty)", "")
+	qty, err = series.GetResultVector[int64](res, "", "qty")
+	require.NoError(t, err)
+	require.Equal(t, int64(3), qty[0])
+	require.InDelta(t, 3000, qty[1], 3000*0.05)
+
+	// decimal columns
+	res = run("count(amount),median(amount),mode(amount),count_distinct(amount)", "")
+	require.Equal(t, []string{"time", "amount", "amount", "amount", "amount"}, res.Columns())
+	var rows []series.Row
+	for row := range res.Rows() {
+		row.Values = append([]any(nil), row.Values...)
+		rows = append(rows, row)
+	}
+	require.Len(t, rows, 3)
+	require.Equal(t, int64(9), rows[0].Values[1])
+	require.Equal(t, "1.25", rows[0].Format(2))
+	require.Equal(t, "0.00", rows[0].Format(3))
+	require.Equal(t, int64(3), rows[0].Values[4])
+	require.Equal(t, int64(large), rows[1].Values[1])
+	require.Equal(t, "0.50", rows[1].Format(2))
+	require.Equal(t, int64(100), rows[1].Values[4])
+	require.Equal(t, []any{base.Add(2 * time.Hour), nil, nil, nil, nil}, rows[2].Values)
+
+	// histograms
+	res = run("hist(price)", "")
+	var hists []any
+	for row := range res.Rows() {
+		hists = append(hists, row.Values[1])
+	}
+	require.Len(t, hists, 3)
+	require.Equal(t, reducer.Histogram{
+		Min:    1,
+		Max:    9,
+		Counts: []int64{1, 1, 1, 1, 0, 1, 1, 1, 1, 1},
+	}, hists[0])
+	h := hists[1].(reducer.Histogram)
+	require.Equal(t, float64(0), h.Min)
+	require.Equal(t, float64(large-1), h.Max)
+	var total int64
+	for _, n := range h.Counts {
+		require.InDelta(t, large/10, n, large/10*0.1)
+		total += n
+	}
+	require.Equal(t, int64(large), total)
+	require.Nil(t, hists[2])
+
+	// histograms encode as JSON objects in all formats
+	buf, err := json.Marshal(res)
+	require.NoError(t, err)
+	var out struct {
+		Series []struct {
+			Values [][]json.RawMessage `json:"values"`
+		} `json:"series"`
+	}
+	require.NoError(t, json.Unmarshal(buf, &out))
+	require.Len(t, out.Series, 1)
+	require.JSONEq(t, `{"min":1,"max":9,"counts":[1,1,1,1,0,1,1,1,1,1]}`, string(out.Series[0].Values[1][0]))
+	require.Equal(t, "null", string(out.Series[0].Values[1][2]))
+
+	var csv bytes.Buffer
+	require.NoError(t, res.WriteCSV(&csv))
+	require.Contains(t, csv.String(), `2025-01-01T00:00:00Z,"{""min"":1,""max"":9,""counts"":[1,1,1,1,0,1,1,1,1,1]}"`)
+	require.NoError(t, res.WriteArrow(&csv))
+
+	// hist requires numeric columns
+	req := series.NewRequest().WithTable(table.Engine())
+	req.Table = "reducer_tick"
+	require.NoError(t, req.Select.UnmarshalText([]byte("hist(amount)")))
+	req.Sanitize()
+	_, err = req.Run(ctx, "series")
+	require.Error(t, err)
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+//
+// TestRollup ensures time-series requests answered from rollups match raw
+// aggregation.
+// Ensures:
+// - merges add partials for inserted rows and subtract deleted rows.
+// - min and max of windows with merged deletes match raw aggregation.
+// - rollups created on tables with data are filled from merged rows.
+// - requests use a matching rollup and see unmerged rows only after flush.
+// - rollups reopen with their source table.
+// - rollup tables are hidden and must be dropped before their source.
+// - tables are rollups by engine kind, not by name.
+
+package scenarios
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"blockwatch.cc/knoxdb/internal/engine"
+	tests "blockwatch.cc/knoxdb/internal/tests/engine"
+	"blockwatch.cc/knoxdb/pkg/knox"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"blockwatch.cc/knoxdb/pkg/series"
+	"blockwatch.cc/knoxdb/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+type RollupTick struct {
+	Id     uint64    `knox:"id,pk"`
+	Time   time.Time `knox:"time,scale=s,timebase"`
+	Market string    `knox:"market"`
+	Price  float64   `knox:"price"`
+	Volume int64     `knox:"volume"`
+}
+
+type RollupPlain struct {
+	Id   uint64    `knox:"id,pk"`
+	Time time.Time `knox:"time,scale=s,timebase"`
+	Sum  float64   `knox:"price_sum"`
+}
+
+func TestRollup(t *testing.T) {
+	eng, _ := tests.NewDatabase(t, &RollupTick{})
+	dbo := eng.Options()
+	db := knox.WrapEngine(eng)
+	ctx := context.Background()
+	defer func() { db.Close(ctx) }()
+
+	table, err := db.FindTable("rollup_tick")
+	require.NoError(t, err, "Missing table")
+
+	// hourly rollup per market on the empty table
+	hourly := &knox.Rollup{
+		Table:    "rollup_tick",
+		Interval: util.TimeUnit{Value: 1, Unit: 'h'},
+		GroupBy:  "market",
+		Aggs: []knox.RollupAggregate{
+			{Field: "price", Func: knox.RollupSum},
+			{Field: "price", Func: knox.RollupMin},
+			{Field: "volume", Func: knox.RollupMax},
+		},
+	}
+	require.NoError(t, db.CreateRollup(ctx, hourly))
+
+	// two days of ticks every 30 minutes, alternating markets,
+	// later ticks arrive late and repeat earlier times
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	insert := func(from, n int) {
+		ticks := make([]*RollupTick, n)
+		for i := range ticks {
+			k := from + i
+			ticks[i] = &RollupTick{
+				Time:   base.Add(time.Duration(k%96) * 30 * time.Minute),
+				Market: []string{"a", "b"}[k%2],
+				Price:  float64(k),
+				Volume: int64(k % 7),
+			}
+		}
+		_, _, err := table.Insert(ctx, ticks)
+		require.NoError(t, err)
+	}
+	insert(0, 96)
+	require.NoError(t, db.FlushTable(ctx, "rollup_tick"))
+
+	run := func(cols, group string, ival util.TimeUnit, raw bool) string {
+		tctx, _, abort, err := db.Begin(ctx, knox.TxFlagReadOnly)
+		require.NoError(t, err)
+		defer abort()
+		req := series.NewRequest().
+			WithTable(table.Engine()).
+			WithRange(util.TimeRange{From: base, To: base.Add(47 * time.Hour)}).
+			WithInterval(ival).
+			WithGroupBy(group).
+			WithNoRollup(raw)
+		req.Table = "rollup_tick"
+		require.NoError(t, req.Select.UnmarshalText([]byte(cols)))
+		req.Sanitize()
+		res, err := req.Run(tctx, "series")
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		require.NoError(t, res.WriteCSV(&buf))
+		return buf.String()
+	}
+	compare := func(cols, group string, ival util.TimeUnit) {
+		t.Helper()
+		want := run(cols, group, ival, true)
+		got := run(cols, group, ival, false)
+		require.Equal(t, want, got, "%s by %q per %s", cols, group, ival)
+	}
+
+	// merged inserts
+	const all = "price,min(price),max(volume),count"
+	for _, ival := range []string{"h", "3h", "d"} {
+		compare(all, "", util.MustParseTimeUnit(ival))
+		compare(all, "market", util.MustParseTimeUnit(ival))
+	}
+
+	// daily rollup created from existing rows
+	daily := &knox.Rollup{
+		Table:    "rollup_tick",
+		Interval: util.TimeUnit{Value: 1, Unit: 'd'},
+		Aggs:     []knox.RollupAggregate{{Field: "price", Func: knox.RollupSum}},
+	}
+	require.NoError(t, db.CreateRollup(ctx, daily))
+	require.ElementsMatch(t,
+		[]string{"rollup_tick_rollup_1h_market", "rollup_tick_rollup_1d"},
+		db.ListRollups("rollup_tick"),
+	)
+	compare("price,count", "", util.TimeUnitDay)
+
+	// unmerged late rows are only visible in raw results
+	insert(96, 2)
+	day := util.TimeUnitDay
+	require.NotEqual(t, run("price,count", "", day, true), run("price,count", "", day, false))
+	require.NoError(t, db.FlushTable(ctx, "rollup_tick"))
+	compare("price,count", "", day)
+
+	// merged deletes, some windows lose all rows
+	n, err := knox.NewQuery().WithTable(table).AndRange("id", 10, 30).Delete(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 21, n)
+	require.NoError(t, db.FlushTable(ctx, "rollup_tick"))
+	compare("price,count", "", util.TimeUnitHour)
+	compare("price,count", "market", util.TimeUnitHour)
+	compare("price,count", "", day)
+
+	// deleted extremes are not served from stale min/max partials, the
+	// 03:00 window keeps one row but loses its minimum price and maximum
+	// volume (id 7)
+	n, err = knox.NewQuery().WithTable(table).AndEqual("id", 7).Delete(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.NoError(t, db.FlushTable(ctx, "rollup_tick"))
+	compare("min(price),max(volume)", "", util.TimeUnitHour)
+	compare("min(price),max(volume)", "market", util.TimeUnitHour)
+	compare("min(price),max(volume)", "", day)
+
+	// plain tables with rollup-like names are no rollups
+	s, err := schema.SchemaOf(&RollupPlain{})
+	require.NoError(t, err)
+	s = s.WithName("rollup_tick_rollup_1w").WithMeta()
+	_, err = eng.CreateTable(ctx, s, tests.NewTestTableOptions(t, "", "").TableOptions()...)
+	require.NoError(t, err)
+
+	// rollups survive restart
+	require.NoError(t, db.Close(ctx))
+	eng = tests.OpenTestEngine(t, dbo)
+	db = knox.WrapEngine(eng)
+	table, err = db.FindTable("rollup_tick")
+	require.NoError(t, err, "Missing table")
+	compare("price,count", "market", util.TimeUnitHour)
+
+	// rollup tables are hidden and reference their source
+	require.NotContains(t, db.ListTables(), "rollup_tick_rollup_1d")
+	require.Contains(t, db.ListTables(), "rollup_tick_rollup_1w")
+	require.NotContains(t, db.ListRollups("rollup_tick"), "rollup_tick_rollup_1w")
+	require.ErrorIs(t, db.DropRollup(ctx, "rollup_tick_rollup_1w"), engine.ErrNoTable)
+	require.NoError(t, db.DropTable(ctx, "rollup_tick_rollup_1w"))
+	for _, name := range db.ListIndexes("rollup_tick") {
+		require.NoError(t, db.DropIndex(ctx, name))
+	}
+	require.ErrorIs(t, db.DropTable(ctx, "rollup_tick"), engine.ErrTableDropWithRefs)
+	require.NoError(t, db.DropRollup(ctx, "rollup_tick_rollup_1d"))
+	require.NoError(t, db.DropRollup(ctx, "rollup_tick_rollup_1h_market"))
+	require.Empty(t, db.ListRollups("rollup_tick"))
+	require.NoError(t, db.DropTable(ctx, "rollup_tick"))
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+//
+// TestSeriesQuery ensures time-series requests aggregate table rows.
+// Ensures:
+// - time columns with second scale produce correct window times.
+// - value columns are read by result schema position.
+// - non-enum string columns can be used to group series.
+
+package scenarios
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	tests "blockwatch.cc/knoxdb/internal/tests/engine"
+	"blockwatch.cc/knoxdb/pkg/knox"
+	"blockwatch.cc/knoxdb/pkg/series"
+	"blockwatch.cc/knoxdb/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+type SeriesQueryTick struct {
+	Id     uint64    `knox:"id,pk"`
+	Market string    `knox:"market"`
+	Price  float64   `knox:"price"`
+	Time   time.Time `knox:"time,scale=s"`
+}
+
+func TestSeriesQuery(t *testing.T) {
+	eng, _ := tests.NewDatabase(t, &SeriesQueryTick{})
+	db := knox.WrapEngine(eng)
+	ctx := context.Background()
+	defer db.Close(ctx)
+
+	table, err := db.FindTable("series_query_tick")
+	require.NoError(t, err, "Missing table")
+
+	// two ticks per hour for 2 hours, alternating markets
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	ticks := make([]*SeriesQueryTick, 4)
+	for i := range ticks {
+		ticks[i] = &SeriesQueryTick{
+			Time:   base.Add(time.Duration(i) * 30 * time.Minute),
+			Market: []string{"a", "b"}[i%2],
+			Price:  float64(i + 1),
+		}
+	}
+	_, _, err = table.Insert(ctx, ticks)
+	require.NoError(t, err)
+
+	ctx, _, abort, err := db.Begin(ctx, knox.TxFlagReadOnly)
+	require.NoError(t, err)
+	defer abort()
+
+	type output struct {
+		Series []struct {
+			Tags    map[string]string `json:"tags"`
+			Columns []string          `json:"columns"`
+			Values  [][]any           `json:"values"` // by column
+		} `json:"series"`
+	}
+	run := func(group string) (out output) {
+		t.Helper()
+		req := series.NewRequest().
+			WithTable(table.Engine()).
+			WithRange(util.TimeRange{From: base, To: base.Add(time.Hour)}).
+			WithInterval(util.TimeUnit{Value: 1, Unit: 'h'}).
+			WithGroupBy(group)
+		req.Table = "series_query_tick"
+		require.NoError(t, req.Select.UnmarshalText([]byte("price")))
+		req.Sanitize()
+		res, err := req.Run(ctx, "series")
+		require.NoError(t, err)
+		buf, err := json.Marshal(res)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(buf, &out))
+		return
+	}
+
+	// ungrouped hourly sums
+	out := run("")
+	require.Len(t, out.Series, 1)
+	require.Equal(t, []string{"time", "price"}, out.Series[0].Columns)
+	require.Equal(t, [][]any{
+		{"2025-01-01T00:00:00Z", "2025-01-01T01:00:00Z"},
+		{3.0, 7.0},
+	}, out.Series[0].Values)
+
+	// grouped by a plain string column
+	out = run("market")
+	require.Len(t, out.Series, 2)
+	for _, s := range out.Series {
+		switch s.Tags["market"] {
+		case "a":
+			require.Equal(t, []any{1.0, 3.0}, s.Values[1])
+		case "b":
+			require.Equal(t, []any{2.0, 4.0}, s.Values[1])
+		default:
+			require.Fail(t, "unexpected group", "tags %v", s.Tags)
+		}
+	}
+}
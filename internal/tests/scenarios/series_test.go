@@ -0,0 +1,157 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+//
+// TestSeriesAccess ensures typed access to time-series results.
+// Ensures:
+// - window times and typed value vectors match aggregated input.
+// - null fills read as zero values in vectors and nil in rows.
+// - grouped results iterate rows by group and time.
+// - results encode as CSV and Arrow IPC stream.
+
+package scenarios
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	tests "blockwatch.cc/knoxdb/internal/tests/engine"
+	"blockwatch.cc/knoxdb/pkg/knox"
+	"blockwatch.cc/knoxdb/pkg/num"
+	"blockwatch.cc/knoxdb/pkg/series"
+	"blockwatch.cc/knoxdb/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+type SeriesTick struct {
+	Id     uint64        `knox:"id,pk"`
+	Time   time.Time     `knox:"time,scale=s"`
+	Market string        `knox:"market"`
+	Price  float64       `knox:"price"`
+	Volume num.Decimal64 `knox:"volume,scale=2"`
+}
+
+func TestSeriesAccess(t *testing.T) {
+	eng, _ := tests.NewDatabase(t, &SeriesTick{})
+	db := knox.WrapEngine(eng)
+	ctx := context.Background()
+	defer db.Close(ctx)
+
+	table, err := db.FindTable("series_tick")
+	require.NoError(t, err, "Missing table")
+
+	// two ticks per hour for 3 hours, alternating markets
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	ticks := make([]*SeriesTick, 6)
+	for i := range ticks {
+		ticks[i] = &SeriesTick{
+			Time:   base.Add(time.Duration(i) * 30 * time.Minute),
+			Market: []string{"a", "b"}[i%2],
+			Price:  float64(i),
+			Volume: num.NewDecimal64(int64(i)*150, 2),
+		}
+	}
+	_, _, err = table.Insert(ctx, ticks)
+	require.NoError(t, err)
+
+	ctx, _, abort, err := db.Begin(ctx, knox.TxFlagReadOnly)
+	require.NoError(t, err)
+	defer abort()
+
+	run := func(cols, group string) *series.Result {
+		req := series.NewRequest().
+			WithTable(table.Engine()).
+			WithRange(util.TimeRange{From: base, To: base.Add(3 * time.Hour)}).
+			WithInterval(util.TimeUnit{Value: 1, Unit: 'h'}).
+			WithGroupBy(group).
+			WithFill("null")
+		req.Table = "series_tick"
+		require.NoError(t, req.Select.UnmarshalText([]byte(cols)))
+		req.Sanitize()
+		res, err := req.Run(ctx, "series")
+		require.NoError(t, err)
+		return res
+	}
+
+	// ungrouped: 3 hourly windows and one null fill at the inclusive end
+	res := run("price,volume,count", "")
+	require.Equal(t, []string{"time", "price", "volume", "count"}, res.Columns())
+	times, err := series.GetResultTimes(res, "")
+	require.NoError(t, err)
+	require.Len(t, times, 4)
+	for i, tm := range times {
+		require.Equal(t, base.Add(time.Duration(i)*time.Hour), tm)
+	}
+	stamps, err := series.GetResultVector[time.Time](res, "", "time")
+	require.NoError(t, err)
+	require.Equal(t, times, stamps)
+	prices, err := series.GetResultVector[float64](res, "", "price")
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 5, 9, 0}, prices)
+	counts, err := series.GetResultVector[int64](res, "", "count")
+	require.NoError(t, err)
+	require.Equal(t, []int64{2, 2, 2, 0}, counts)
+	volumes, err := series.GetResultVector[num.Decimal256](res, "", "volume")
+	require.NoError(t, err)
+	require.Len(t, volumes, 4)
+	for i, v := range []string{"1.50", "7.50", "13.50"} {
+		require.Equal(t, v, volumes[i].String())
+	}
+
+	// errors
+	_, err = series.GetResultVector[int64](res, "", "price")
+	require.Error(t, err)
+	_, err = series.GetResultVector[float64](res, "", "missing")
+	require.Error(t, err)
+	_, err = series.GetResultTimes(res, "a")
+	require.Error(t, err)
+
+	// rows with null fills
+	var rows []series.Row
+	for row := range res.Rows() {
+		row.Values = append([]any(nil), row.Values...)
+		rows = append(rows, row)
+	}
+	require.Len(t, rows, 4)
+	require.Equal(t, times[1], rows[1].Time)
+	require.Equal(t, []any{times[1], float64(5), volumes[1], int64(2)}, rows[1].Values)
+	require.Equal(t, []any{times[3], nil, nil, nil}, rows[3].Values)
+
+	var buf bytes.Buffer
+	require.NoError(t, res.WriteCSV(&buf))
+	require.Equal(t, `time,price,volume,count
+2025-01-01T00:00:00Z,1,1.50,2
+2025-01-01T01:00:00Z,5,7.50,2
+2025-01-01T02:00:00Z,9,13.50,2
+2025-01-01T03:00:00Z,,,
+`, buf.String())
+
+	// grouped: rows are ordered by group, then time
+	res = run("price", "market")
+	require.Equal(t, []string{"a", "b"}, res.Groups())
+	prices, err = series.GetResultVector[float64](res, "b", "price")
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 3, 5, 0}, prices)
+	var groups []string
+	for row := range res.Rows() {
+		groups = append(groups, row.Group)
+	}
+	require.Equal(t, []string{"a", "a", "a", "a", "b", "b", "b", "b"}, groups)
+
+	buf.Reset()
+	require.NoError(t, res.WriteCSV(&buf))
+	require.True(t, strings.HasPrefix(buf.String(), "market,time,price\na,2025-01-01T00:00:00Z,0\n"))
+
+	// arrow stream framing, batch contents are covered by pkg/arrow
+	s, err := res.Schema()
+	require.NoError(t, err)
+	require.Equal(t, 3, s.NumFields())
+	buf.Reset()
+	require.NoError(t, res.WriteArrow(&buf))
+	data := buf.Bytes()
+	require.Equal(t, uint32(0xFFFFFFFF), binary.LittleEndian.Uint32(data))
+	require.Equal(t, []byte{0xFF, 0xFF, 0xFF, 0xFF, 0, 0, 0, 0}, data[len(data)-8:])
+}
@@ -19,6 +19,7 @@ var (
 		types.BlockInt64:   types.FieldTypeInt64,
 		types.BlockInt128:  types.FieldTypeInt128,
 		types.BlockInt256:  types.FieldTypeInt256,
+		types.BlockBigint:  types.FieldTypeBigint,
 		types.BlockUint8:   types.FieldTypeUint8,
 		types.BlockUint16:  types.FieldTypeUint16,
 		types.BlockUint32:  types.FieldTypeUint32,
@@ -7,6 +7,8 @@ import (
 	"bytes"
 	"fmt"
 	"math"
+	"math/big"
+	"strconv"
 
 	"blockwatch.cc/knoxdb/internal/xroar"
 	"blockwatch.cc/knoxdb/pkg/num"
@@ -18,21 +20,24 @@ import (
 type BlockType byte
 
 const (
-	BlockInvalid BlockType = iota // 0
-	BlockInt64                    // 1
-	BlockInt32                    // 2
-	BlockInt16                    // 3
-	BlockInt8                     // 4
-	BlockUint64                   // 5
-	BlockUint32                   // 6
-	BlockUint16                   // 7
-	BlockUint8                    // 8
-	BlockFloat64                  // 9
-	BlockFloat32                  // 10
-	BlockBool                     // 11
-	BlockBytes                    // 12
-	BlockInt128                   // 13
-	BlockInt256                   // 14
+	BlockInvalid  BlockType = iota // 0
+	BlockInt64                     // 1
+	BlockInt32                     // 2
+	BlockInt16                     // 3
+	BlockInt8                      // 4
+	BlockUint64                    // 5
+	BlockUint32                    // 6
+	BlockUint16                    // 7
+	BlockUint8                     // 8
+	BlockFloat64                   // 9
+	BlockFloat32                   // 10
+	BlockBool                      // 11
+	BlockBytes                     // 12
+	BlockInt128                    // 13
+	BlockInt256                    // 14
+	BlockBigint                    // 15
+	BlockList                      // 16
+	BlockDocument                  // 17
 )
 
 type BlockKind byte
@@ -45,8 +50,15 @@ const (
 	BlockKindBitmap
 	BlockKindInt128
 	BlockKindInt256
+	BlockKindBigint
+	BlockKindList
+	BlockKindDocument
 )
 
+// BlockCompression stores a block codec in the lower 3 bits and an optional
+// codec level in the upper 5 bits where level 0 selects the codec default.
+// Auto compression uses the level to select a compression policy. Encoded
+// blocks only record the codec, so readers need no level information.
 type BlockCompression byte
 
 const (
@@ -54,34 +66,89 @@ const (
 	BlockCompressSnappy
 	BlockCompressLZ4
 	BlockCompressZstd
+	BlockCompressAuto
+	BlockCompressDict
 )
 
+const (
+	BlockCompressCodecMask  = 0x7
+	BlockCompressLevelShift = 3
+	BlockCompressMaxLevel   = 1<<5 - 1
+)
+
+// Compression policies for auto compression.
+const (
+	CompressPolicyBalanced = iota
+	CompressPolicySpeed
+	CompressPolicyRatio
+)
+
+var compressPolicyNames = [...]string{"balanced", "speed", "ratio"}
+
+func ParseCompressPolicy(s string) (int, bool) {
+	for i, n := range compressPolicyNames {
+		if n == s {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
 func (i BlockCompression) Is(f BlockCompression) bool {
 	return i&f > 0
 }
 
+func (c BlockCompression) Codec() BlockCompression {
+	return c & BlockCompressCodecMask
+}
+
+func (c BlockCompression) Level() int {
+	return int(c >> BlockCompressLevelShift)
+}
+
+func (c BlockCompression) WithLevel(l int) BlockCompression {
+	l = max(0, min(l, BlockCompressMaxLevel))
+	return c.Codec() | BlockCompression(l<<BlockCompressLevelShift)
+}
+
+func (c BlockCompression) IsValid() bool {
+	switch c.Codec() {
+	case BlockCompressNone, BlockCompressSnappy, BlockCompressDict:
+		return c.Level() == 0
+	case BlockCompressAuto:
+		return c.Level() < len(compressPolicyNames)
+	case BlockCompressLZ4, BlockCompressZstd:
+		return true
+	default:
+		return false
+	}
+}
+
 var (
-	blockTypeNames        = "__i64_i32_i16_i8_u64_u32_u16_u8_f64_f32_bool_bytes_i128_i256"
-	blockTypeNamesOfs     = []int{0, 2, 6, 10, 14, 17, 21, 25, 29, 32, 36, 40, 45, 51, 56, 61}
-	blockCompressNames    = "__snappy_lz4_zstd"
-	blockCompressNamesOfs = []int{0, 2, 7, 13, 18}
+	blockTypeNames        = "__i64_i32_i16_i8_u64_u32_u16_u8_f64_f32_bool_bytes_i128_i256_bigint_list_document"
+	blockTypeNamesOfs     = []int{0, 2, 6, 10, 14, 17, 21, 25, 29, 32, 36, 40, 45, 51, 56, 61, 68, 73, 82}
+	blockCompressNames    = "__snappy_lz4_zstd_auto_dict"
+	blockCompressNamesOfs = []int{0, 2, 9, 13, 18, 23, 28}
 
 	blockTypeDataSize = [...]int{
-		BlockInvalid: 0,
-		BlockInt64:   8,
-		BlockInt32:   4,
-		BlockInt16:   2,
-		BlockInt8:    1,
-		BlockUint64:  8,
-		BlockUint32:  4,
-		BlockUint16:  2,
-		BlockUint8:   1,
-		BlockFloat64: 8,
-		BlockFloat32: 4,
-		BlockBool:    1,
-		BlockBytes:   0, // fixed or variable
-		BlockInt128:  16,
-		BlockInt256:  32,
+		BlockInvalid:  0,
+		BlockInt64:    8,
+		BlockInt32:    4,
+		BlockInt16:    2,
+		BlockInt8:     1,
+		BlockUint64:   8,
+		BlockUint32:   4,
+		BlockUint16:   2,
+		BlockUint8:    1,
+		BlockFloat64:  8,
+		BlockFloat32:  4,
+		BlockBool:     1,
+		BlockBytes:    0, // fixed or variable
+		BlockInt128:   16,
+		BlockInt256:   32,
+		BlockBigint:   0, // variable
+		BlockList:     0, // variable
+		BlockDocument: 0, // variable
 	}
 
 	BlockTypes = [...]BlockType{
@@ -106,18 +173,34 @@ var (
 		FieldTypeDecimal128: BlockInt128,
 		FieldTypeDecimal64:  BlockInt64,
 		FieldTypeDecimal32:  BlockInt32,
-		FieldTypeBigint:     BlockBytes,
+		FieldTypeBigint:     BlockBigint,
 		FieldTypeDate:       BlockInt64,
 		FieldTypeTime:       BlockInt64,
+		FieldTypeList:       BlockList,
+		FieldTypeDocument:   BlockDocument,
 	}
 )
 
+// String returns the codec name followed by level or policy, e.g. `zstd=19`
+// or `auto=speed`.
 func (t BlockCompression) String() string {
-	return blockCompressNames[blockCompressNamesOfs[t] : blockCompressNamesOfs[t+1]-1]
+	c := t.Codec()
+	if c > BlockCompressDict {
+		return "invalid compression"
+	}
+	name := blockCompressNames[blockCompressNamesOfs[c] : blockCompressNamesOfs[c+1]-1]
+	switch l := t.Level(); {
+	case l == 0:
+		return name
+	case c == BlockCompressAuto && l < len(compressPolicyNames):
+		return name + "=" + compressPolicyNames[l]
+	default:
+		return name + "=" + strconv.Itoa(l)
+	}
 }
 
 func (t BlockType) IsValid() bool {
-	return t > 0 && t <= BlockInt256
+	return t > 0 && t <= BlockDocument
 }
 
 func (t BlockType) String() string {
@@ -149,6 +232,12 @@ func (t BlockType) Kind() BlockKind {
 		return BlockKindInt128
 	case BlockInt256:
 		return BlockKindInt256
+	case BlockBigint:
+		return BlockKindBigint
+	case BlockList:
+		return BlockKindList
+	case BlockDocument:
+		return BlockKindDocument
 	default:
 		return BlockKindInvalid
 	}
@@ -235,6 +324,8 @@ func (t BlockType) MinNumericVal() any {
 		return num.MinInt256
 	case BlockBytes:
 		return []byte{}
+	case BlockBigint:
+		return bigMin{}
 	default:
 		panic(fmt.Errorf("min: unsupported block type %s", t))
 	}
@@ -268,7 +359,7 @@ func (t BlockType) MaxNumericVal() any {
 		return num.MaxInt128
 	case BlockInt256:
 		return num.MaxInt256
-	case BlockBytes:
+	case BlockBytes, BlockBigint:
 		return nil
 	default:
 		panic(fmt.Errorf("max: unsupported block type %s", t))
@@ -305,6 +396,8 @@ func (t BlockType) Add(a, b any) any {
 		return a.(float32) + b.(float32)
 	case BlockBytes:
 		return append(bytes.Clone(a.([]byte)), b.([]byte)...)
+	case BlockBigint:
+		return BigOf(a).Add(BigOf(b))
 	default:
 		panic(fmt.Errorf("add: unsupported block type %s", t))
 	}
@@ -353,6 +446,8 @@ func (t BlockType) Inc(v any) any {
 			c = append([]byte{1}, c...)
 		}
 		return c
+	case BlockBigint:
+		return BigOf(v).AddU64(1)
 	default:
 		panic(fmt.Errorf("inc: unsupported block type %s", t))
 	}
@@ -400,6 +495,8 @@ func (t BlockType) Dec(v any) any {
 			c = c[:len(c)-1]
 		}
 		return c
+	case BlockBigint:
+		return BigOf(v).SubU64(1)
 	default:
 		panic(fmt.Errorf("dec: unsupported block type %s", t))
 	}
@@ -433,8 +530,10 @@ func (t BlockType) Zero() any {
 		return float64(0)
 	case BlockFloat32:
 		return float32(0)
-	case BlockBytes:
+	case BlockBytes, BlockList, BlockDocument:
 		return []byte{}
+	case BlockBigint:
+		return num.BigZero
 	default:
 		panic(fmt.Errorf("zero: unsupported block type %s", t))
 	}
@@ -475,7 +574,7 @@ func (t BlockType) Cmp(a, b any) (c int) {
 		c = util.Cmp(a.(float64), b.(float64))
 	case BlockBool:
 		c = util.CmpBool(a.(bool), b.(bool))
-	case BlockBytes:
+	case BlockBytes, BlockList, BlockDocument:
 		// check nil interface (nil == empty slice)
 		switch {
 		case a == nil && b == nil:
@@ -505,6 +604,20 @@ func (t BlockType) Cmp(a, b any) (c int) {
 		c = a.(num.Int128).Cmp(b.(num.Int128))
 	case BlockInt256:
 		c = a.(num.Int256).Cmp(b.(num.Int256))
+	case BlockBigint:
+		// bigMin and nil are unbounded (see MinNumericVal, MaxNumericVal)
+		_, aMin := a.(bigMin)
+		_, bMin := b.(bigMin)
+		switch {
+		case aMin && bMin, a == nil && b == nil:
+			return 0
+		case aMin, b == nil:
+			c = -1
+		case bMin, a == nil:
+			c = 1
+		default:
+			c = BigOf(a).Cmp(BigOf(b))
+		}
 	default:
 		panic(fmt.Errorf("cmp: unsupported block type %s", t))
 	}
@@ -574,6 +687,8 @@ func (t BlockType) Unique(a any) any {
 		return num.Int128Unique(a.([]num.Int128))
 	case BlockInt256:
 		return num.Int256Unique(a.([]num.Int256))
+	case BlockBigint:
+		return num.BigUnique(a.([]num.Big))
 	default:
 		panic(fmt.Errorf("unique: unsupported block type %s", t))
 	}
@@ -632,6 +747,8 @@ func (t BlockType) Intersect(a, b any) any {
 		return num.Int128Intersect(a.([]num.Int128), b.([]num.Int128))
 	case BlockInt256:
 		return num.Int256Intersect(a.([]num.Int256), b.([]num.Int256))
+	case BlockBigint:
+		return num.BigIntersect(a.([]num.Big), b.([]num.Big))
 	default:
 		panic(fmt.Errorf("intersect: unsupported block type %s", t))
 	}
@@ -690,6 +807,8 @@ func (t BlockType) Union(a, b any) any {
 		return num.Int128Union(a.([]num.Int128), b.([]num.Int128))
 	case BlockInt256:
 		return num.Int256Union(a.([]num.Int256), b.([]num.Int256))
+	case BlockBigint:
+		return num.BigUnion(a.([]num.Big), b.([]num.Big))
 	default:
 		panic(fmt.Errorf("union: unsupported block type %s", t))
 	}
@@ -748,6 +867,8 @@ func (t BlockType) Difference(a, b any) any {
 		return num.Int128Difference(a.([]num.Int128), b.([]num.Int128))
 	case BlockInt256:
 		return num.Int256Difference(a.([]num.Int256), b.([]num.Int256))
+	case BlockBigint:
+		return num.BigDifference(a.([]num.Big), b.([]num.Big))
 	default:
 		panic(fmt.Errorf("difference: unsupported block type %s", t))
 	}
@@ -807,6 +928,11 @@ func (t BlockType) Range(set any) (minv any, maxv any, isContinuous bool) {
 		mini, maxi := num.Int256MinMax(num.Int256Sort(i256s))
 		minv, maxv = mini, maxi
 		isContinuous = int(maxi.Sub(mini).Int64()+1) == len(i256s)
+	case BlockBigint:
+		bigs := set.([]num.Big)
+		mini, maxi := num.BigMinMax(num.BigSort(bigs))
+		minv, maxv = mini, maxi
+		isContinuous = maxi.Sub(mini).AddU64(1).Equal(num.NewBig(int64(len(bigs))))
 	case BlockFloat64:
 		x := slicex.NewOrderedFloats(set.([]float64))
 		minv, maxv = x.MinMax()
@@ -880,6 +1006,13 @@ func (t BlockType) RemoveRange(s, from, to any) any {
 		return num.Int128RemoveRange(s.([]num.Int128), from.(num.Int128), to.(num.Int128))
 	case BlockInt256:
 		return num.Int256RemoveRange(s.([]num.Int256), from.(num.Int256), to.(num.Int256))
+	case BlockBigint:
+		bigs := s.([]num.Big)
+		if len(bigs) == 0 {
+			return bigs
+		}
+		from, to := bigBounds(bigs, from, to)
+		return num.BigRemoveRange(bigs, from, to)
 	default:
 		panic(fmt.Errorf("remove range: unsupported block type %s", t))
 	}
@@ -929,7 +1062,48 @@ func (t BlockType) IntersectRange(s, from, to any) any {
 		return num.Int128IntersectRange(s.([]num.Int128), from.(num.Int128), to.(num.Int128))
 	case BlockInt256:
 		return num.Int256IntersectRange(s.([]num.Int256), from.(num.Int256), to.(num.Int256))
+	case BlockBigint:
+		bigs := s.([]num.Big)
+		if len(bigs) == 0 {
+			return bigs
+		}
+		from, to := bigBounds(bigs, from, to)
+		return num.BigIntersectRange(bigs, from, to)
 	default:
 		panic(fmt.Errorf("intersect range: unsupported block type %s", t))
 	}
 }
+
+// bigMin is the unbounded minimum of bigint values, nil is the unbounded
+// maximum. Filter optimization rewrites ranges with unbounded ends, so
+// neither reaches matchers.
+type bigMin struct{}
+
+// bigBounds replaces unbounded range ends by the first and last value of
+// sorted non-empty set s.
+func bigBounds(s []num.Big, from, to any) (num.Big, num.Big) {
+	lo, hi := s[0], s[len(s)-1]
+	if _, ok := from.(bigMin); !ok {
+		lo = BigOf(from)
+	}
+	if to != nil {
+		hi = BigOf(to)
+	}
+	return lo, hi
+}
+
+// BigOf converts a bigint block value into num.Big. Blocks store bigints
+// in order-preserving binary wire format, while query values are cast to
+// num.Big. Unsupported values convert to zero.
+func BigOf(v any) num.Big {
+	switch x := v.(type) {
+	case num.Big:
+		return x
+	case []byte:
+		return num.NewBigFromBytes(x)
+	case *big.Int:
+		return num.NewFromBigInt(x)
+	default:
+		return num.BigZero
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Documents are stored as compact JSON objects in wire format and in
+// document blocks. Query paths address nested object keys with dots,
+// e.g. `amount` or `transfer.to`. Array elements are not addressable.
+// Empty documents and JSON `null` are stored as zero length values.
+
+// DocumentBytes converts a Go value into compact JSON document bytes.
+// Byte slices and strings are expected to contain JSON already.
+func DocumentBytes(val any) ([]byte, error) {
+	var (
+		buf []byte
+		err error
+	)
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		buf = v
+	case json.RawMessage:
+		buf = v
+	case string:
+		buf = []byte(v)
+	default:
+		buf, err = json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(buf) == 0 || bytes.Equal(buf, jsonNull) {
+		return nil, nil
+	}
+	var b bytes.Buffer
+	if err := json.Compact(&b, buf); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+var jsonNull = []byte("null")
+
+// ParseDocument decodes a JSON document into a generic Go value.
+// Numbers are kept as json.Number to avoid precision loss.
+func ParseDocument(buf []byte) (any, error) {
+	if len(buf) == 0 {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// LookupPath returns the value at path inside document value doc.
+// Missing keys and non-object parents return false.
+func LookupPath(doc any, path string) (any, bool) {
+	for len(path) > 0 {
+		obj, ok := doc.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		key, rest, _ := strings.Cut(path, ".")
+		doc, ok = obj[key]
+		if !ok {
+			return nil, false
+		}
+		path = rest
+	}
+	return doc, true
+}
+
+// WalkDocument calls fn for every scalar leaf value (json.Number, string,
+// bool) of document value doc in key order. Nested objects extend the
+// path, arrays and null values are skipped.
+func WalkDocument(doc any, fn func(path string, val any)) {
+	walkDocument(doc, "", fn)
+}
+
+func walkDocument(doc any, prefix string, fn func(string, any)) {
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		switch v := obj[k].(type) {
+		case map[string]any:
+			walkDocument(v, path, fn)
+		case json.Number, string, bool:
+			fn(path, v)
+		}
+	}
+}
+
+// DocumentScalar normalizes a scalar document or query value to int64,
+// float64, string or bool. Integral json.Number values become int64.
+func DocumentScalar(val any) (any, error) {
+	switch v := val.(type) {
+	case json.Number:
+		if i64, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return i64, nil
+		}
+		return v.Float64()
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case int32:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int8:
+		return int64(v), nil
+	case uint:
+		return docUint(uint64(v))
+	case uint64:
+		return docUint(v)
+	case uint32:
+		return int64(v), nil
+	case uint16:
+		return int64(v), nil
+	case uint8:
+		return int64(v), nil
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case bool:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unsupported document value type %T", val)
+	}
+}
+
+func docUint(v uint64) (any, error) {
+	if v > math.MaxInt64 {
+		return float64(v), nil
+	}
+	return int64(v), nil
+}
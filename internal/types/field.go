@@ -37,11 +37,13 @@ const (
 	FieldTypeBigint
 	FieldTypeDate
 	FieldTypeTime
+	FieldTypeList
+	FieldTypeDocument
 )
 
 var (
-	fieldTypeString  = "__timestamp_int64_uint64_float64_boolean_string_bytes_int32_int16_int8_uint32_uint16_uint8_float32_int256_int128_decimal256_decimal128_decimal64_decimal32_bigint_date_time"
-	fieldTypeIdx     = [...]int{0, 2, 12, 18, 25, 33, 41, 48, 54, 60, 66, 71, 78, 85, 91, 99, 106, 113, 124, 135, 145, 155, 162, 167, 172}
+	fieldTypeString  = "__timestamp_int64_uint64_float64_boolean_string_bytes_int32_int16_int8_uint32_uint16_uint8_float32_int256_int128_decimal256_decimal128_decimal64_decimal32_bigint_date_time_list_document"
+	fieldTypeIdx     = [...]int{0, 2, 12, 18, 25, 33, 41, 48, 54, 60, 66, 71, 78, 85, 91, 99, 106, 113, 124, 135, 145, 155, 162, 167, 172, 177, 186}
 	fieldTypeReverse = map[string]FieldType{}
 
 	fieldTypeWireSize = [...]int{
@@ -69,17 +71,19 @@ var (
 		FieldTypeBigint:     4, // stored as var bytes
 		FieldTypeDate:       8, // i64
 		FieldTypeTime:       8, // i64
+		FieldTypeList:       4, // minimum uint32 for size
+		FieldTypeDocument:   4, // minimum uint32 for size
 	}
 )
 
 func init() {
-	for t := FieldTypeInvalid; t <= FieldTypeTime; t++ {
+	for t := FieldTypeInvalid; t <= FieldTypeDocument; t++ {
 		fieldTypeReverse[t.String()] = t
 	}
 }
 
 func (t FieldType) IsValid() bool {
-	return t > FieldTypeInvalid && t <= FieldTypeTime
+	return t > FieldTypeInvalid && t <= FieldTypeDocument
 }
 
 func (t FieldType) String() string {
@@ -157,11 +161,12 @@ const (
 	FieldFlagDeleted
 	FieldFlagMetadata
 	FieldFlagNullable
+	FieldFlagValidity
 )
 
 var (
-	fieldFlagNames = "primary_timebase_enum_deleted_metadata_nullable"
-	fieldFlagIdx   = [...]int{0, 8, 17, 22, 30, 39, 48}
+	fieldFlagNames = "primary_timebase_enum_deleted_metadata_nullable_validity"
+	fieldFlagIdx   = [...]int{0, 8, 17, 22, 30, 39, 48, 57}
 )
 
 func (i FieldFlags) Is(f FieldFlags) bool {
@@ -13,6 +13,8 @@ const (
 	IndexTypeInt
 	IndexTypePk
 	IndexTypeComposite
+	IndexTypeToken
+	IndexTypeBitmap
 )
 
 func (i IndexType) Is(f IndexType) bool {
@@ -20,19 +22,19 @@ func (i IndexType) Is(f IndexType) bool {
 }
 
 var (
-	indexTypeString  = "__hash_int_pk_composite"
-	indexTypeIdx     = [...]int{0, 2, 7, 11, 14, 24}
+	indexTypeString  = "__hash_int_pk_composite_token_bitmap"
+	indexTypeIdx     = [...]int{0, 2, 7, 11, 14, 24, 30, 37}
 	indexTypeReverse = map[string]IndexType{}
 )
 
 func init() {
-	for t := IndexTypeNone; t <= IndexTypeComposite; t++ {
+	for t := IndexTypeNone; t <= IndexTypeBitmap; t++ {
 		indexTypeReverse[t.String()] = t
 	}
 }
 
 func (t IndexType) IsValid() bool {
-	return t > IndexTypeNone && t <= IndexTypeComposite
+	return t > IndexTypeNone && t <= IndexTypeBitmap
 }
 
 func (t IndexType) String() string {
@@ -0,0 +1,77 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package types
+
+import (
+	"bytes"
+	"slices"
+
+	"blockwatch.cc/knoxdb/pkg/util"
+)
+
+// List values are stored as packed little endian vectors of their
+// fixed size elements in wire format and in list blocks. An element
+// vector's length in bytes is a multiple of the element size.
+
+// IsListElem returns true when t can be used as list element type.
+func (t BlockType) IsListElem() bool {
+	return t.IsInt() || t.IsFloat()
+}
+
+// ListBytes returns the packed element vector of a typed Go slice.
+// The result references the slice's backing array.
+func ListBytes(val any) ([]byte, bool) {
+	switch v := val.(type) {
+	case []byte:
+		return v, true
+	case []int64:
+		return util.ToByteSlice(v), true
+	case []int32:
+		return util.ToByteSlice(v), true
+	case []int16:
+		return util.ToByteSlice(v), true
+	case []int8:
+		return util.ToByteSlice(v), true
+	case []uint64:
+		return util.ToByteSlice(v), true
+	case []uint32:
+		return util.ToByteSlice(v), true
+	case []uint16:
+		return util.ToByteSlice(v), true
+	case []float64:
+		return util.ToByteSlice(v), true
+	case []float32:
+		return util.ToByteSlice(v), true
+	default:
+		return nil, false
+	}
+}
+
+// ListValue returns a typed Go slice copy of packed element vector buf.
+func ListValue(elem BlockType, buf []byte) any {
+	switch elem {
+	case BlockInt64:
+		return slices.Clone(util.FromByteSlice[int64](buf))
+	case BlockInt32:
+		return slices.Clone(util.FromByteSlice[int32](buf))
+	case BlockInt16:
+		return slices.Clone(util.FromByteSlice[int16](buf))
+	case BlockInt8:
+		return slices.Clone(util.FromByteSlice[int8](buf))
+	case BlockUint64:
+		return slices.Clone(util.FromByteSlice[uint64](buf))
+	case BlockUint32:
+		return slices.Clone(util.FromByteSlice[uint32](buf))
+	case BlockUint16:
+		return slices.Clone(util.FromByteSlice[uint16](buf))
+	case BlockUint8:
+		return bytes.Clone(buf)
+	case BlockFloat64:
+		return slices.Clone(util.FromByteSlice[float64](buf))
+	case BlockFloat32:
+		return slices.Clone(util.FromByteSlice[float32](buf))
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package types
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// LogicalType annotates fixed size byte fields with a value domain that
+// defines how values are parsed and formatted. Values are stored as raw
+// fixed size bytes and sort bytewise which is equal to the canonical
+// order of each domain (UUIDs by string, IPs in network order with IPv4
+// stored as IPv4-mapped IPv6 addresses, hashes and addresses as hex).
+type LogicalType byte
+
+const (
+	LogicalTypeNone   LogicalType = iota
+	LogicalTypeUUID               // [16]byte RFC 9562 UUID
+	LogicalTypeIP                 // [16]byte IPv6 or IPv4-mapped IPv6 address
+	LogicalTypeHash32             // [32]byte hash
+	LogicalTypeAddr20             // [20]byte address
+)
+
+var (
+	logicalTypeNames = [...]string{
+		LogicalTypeNone:   "",
+		LogicalTypeUUID:   "uuid",
+		LogicalTypeIP:     "ip",
+		LogicalTypeHash32: "hash32",
+		LogicalTypeAddr20: "addr20",
+	}
+	logicalTypeSizes = [...]int{
+		LogicalTypeNone:   0,
+		LogicalTypeUUID:   16,
+		LogicalTypeIP:     16,
+		LogicalTypeHash32: 32,
+		LogicalTypeAddr20: 20,
+	}
+)
+
+func ParseLogicalType(s string) LogicalType {
+	for i, n := range logicalTypeNames {
+		if i > 0 && n == s {
+			return LogicalType(i)
+		}
+	}
+	return LogicalTypeNone
+}
+
+func (t LogicalType) IsValid() bool {
+	return t <= LogicalTypeAddr20
+}
+
+func (t LogicalType) String() string {
+	if !t.IsValid() {
+		return "invalid"
+	}
+	return logicalTypeNames[t]
+}
+
+// Size returns the storage size in bytes or zero for LogicalTypeNone.
+func (t LogicalType) Size() int {
+	if !t.IsValid() {
+		return 0
+	}
+	return logicalTypeSizes[t]
+}
+
+// Format returns the canonical string representation of b.
+func (t LogicalType) Format(b []byte) string {
+	return string(t.Append(nil, b))
+}
+
+// Append appends the canonical string representation of b to dst. Values
+// of unexpected length are appended as hex.
+func (t LogicalType) Append(dst, b []byte) []byte {
+	if len(b) != t.Size() {
+		return hex.AppendEncode(dst, b)
+	}
+	switch t {
+	case LogicalTypeUUID:
+		dst = hex.AppendEncode(dst, b[:4])
+		dst = append(dst, '-')
+		dst = hex.AppendEncode(dst, b[4:6])
+		dst = append(dst, '-')
+		dst = hex.AppendEncode(dst, b[6:8])
+		dst = append(dst, '-')
+		dst = hex.AppendEncode(dst, b[8:10])
+		dst = append(dst, '-')
+		return hex.AppendEncode(dst, b[10:])
+	case LogicalTypeIP:
+		return netip.AddrFrom16([16]byte(b)).Unmap().AppendTo(dst)
+	case LogicalTypeHash32, LogicalTypeAddr20:
+		dst = append(dst, "0x"...)
+		return hex.AppendEncode(dst, b)
+	default:
+		return hex.AppendEncode(dst, b)
+	}
+}
+
+// Parse parses s into a new byte slice of Size. UUIDs are accepted with
+// and without dashes, IPs in IPv4 or IPv6 notation and hashes and addresses
+// as hex strings with optional 0x prefix.
+func (t LogicalType) Parse(s string) ([]byte, error) {
+	switch t {
+	case LogicalTypeIP:
+		a, err := netip.ParseAddr(s)
+		if err != nil {
+			return nil, err
+		}
+		b := a.As16()
+		return b[:], nil
+	case LogicalTypeUUID:
+		if len(s) == 36 {
+			if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+				return nil, fmt.Errorf("invalid uuid %q", s)
+			}
+			s = s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+		}
+	case LogicalTypeHash32, LogicalTypeAddr20:
+		s = trimHexPrefix(s)
+	default:
+		return nil, fmt.Errorf("invalid logical type %d", t)
+	}
+	if len(s) != 2*t.Size() {
+		return nil, fmt.Errorf("invalid %s length %d", t, len(s))
+	}
+	return hex.DecodeString(s)
+}
+
+// ParsePrefix parses a value prefix from s and returns the prefix bytes
+// and the number of significant bits. IPs use CIDR notation where bare
+// addresses and IPv4 prefixes are converted to IPv6 prefixes. All other
+// types use hex prefixes with nibble precision.
+func (t LogicalType) ParsePrefix(s string) ([]byte, int, error) {
+	switch t {
+	case LogicalTypeIP:
+		var (
+			p   netip.Prefix
+			err error
+		)
+		if strings.IndexByte(s, '/') >= 0 {
+			p, err = netip.ParsePrefix(s)
+		} else {
+			var a netip.Addr
+			a, err = netip.ParseAddr(s)
+			if err == nil {
+				p = netip.PrefixFrom(a, a.BitLen())
+			}
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		bits := p.Bits()
+		if p.Addr().Is4() {
+			bits += 96
+		}
+		b := p.Masked().Addr().As16()
+		return b[:], bits, nil
+	case LogicalTypeUUID:
+		s = strings.ReplaceAll(s, "-", "")
+	default:
+		s = trimHexPrefix(s)
+	}
+	if sz := t.Size(); sz > 0 && len(s) > 2*sz {
+		return nil, 0, fmt.Errorf("%s prefix too long", t)
+	}
+	bits := 4 * len(s)
+	if len(s)&1 == 1 {
+		s += "0"
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, 0, err
+	}
+	return b, bits, nil
+}
+
+// PrefixRange returns the smallest and largest values of length size that
+// share the first bits of prefix p.
+func PrefixRange(p []byte, bits, size int) ([]byte, []byte) {
+	lo, hi := make([]byte, size), make([]byte, size)
+	bits = min(bits, 8*size, 8*len(p))
+	n := bits / 8
+	copy(lo, p[:n])
+	copy(hi, p[:n])
+	if r := bits % 8; r > 0 {
+		mask := byte(0xff) >> r
+		lo[n] = p[n] &^ mask
+		hi[n] = p[n] | mask
+		n++
+	}
+	for i := n; i < size; i++ {
+		hi[i] = 0xff
+	}
+	return lo, hi
+}
+
+func trimHexPrefix(s string) string {
+	if len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		return s[2:]
+	}
+	return s
+}
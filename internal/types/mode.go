@@ -24,6 +24,13 @@ const (
 	FilterModeRegexp
 	FilterModeTrue
 	FilterModeFalse
+	FilterModeContains
+	FilterModeMatch
+	FilterModeIsNull
+	FilterModeNotNull
+	FilterModeAny
+	FilterModeAll
+	FilterModePrefix
 )
 
 var filterModeOperators = [...]string{
@@ -40,6 +47,13 @@ var filterModeOperators = [...]string{
 	FilterModeRegexp:   "re",
 	FilterModeTrue:     "++",
 	FilterModeFalse:    "--",
+	FilterModeContains: "cs",
+	FilterModeMatch:    "mt",
+	FilterModeIsNull:   "nu",
+	FilterModeNotNull:  "nn",
+	FilterModeAny:      "any",
+	FilterModeAll:      "all",
+	FilterModePrefix:   "pf",
 }
 
 var filterModeSymbols = [...]string{
@@ -55,6 +69,13 @@ var filterModeSymbols = [...]string{
 	FilterModeRegexp:   "~=",
 	FilterModeTrue:     "TRUE",
 	FilterModeFalse:    "FALSE",
+	FilterModeContains: "CONTAINS",
+	FilterModeMatch:    "MATCH",
+	FilterModeIsNull:   "IS NULL",
+	FilterModeNotNull:  "IS NOT NULL",
+	FilterModeAny:      "ANY",
+	FilterModeAll:      "ALL",
+	FilterModePrefix:   "PREFIX",
 }
 
 func ParseFilterMode(s string) FilterMode {
@@ -79,13 +100,32 @@ func ParseFilterMode(s string) FilterMode {
 		return FilterModeRange
 	case "re":
 		return FilterModeRegexp
+	case "cs":
+		return FilterModeContains
+	case "mt":
+		return FilterModeMatch
+	case "nu", "null":
+		return FilterModeIsNull
+	case "nn", "notnull":
+		return FilterModeNotNull
+	case "any":
+		return FilterModeAny
+	case "all":
+		return FilterModeAll
+	case "pf", "prefix", "cidr":
+		return FilterModePrefix
 	default:
 		return FilterModeInvalid
 	}
 }
 
 func (m FilterMode) IsValid() bool {
-	return m > FilterModeInvalid && m <= FilterModeFalse
+	return m > FilterModeInvalid && m <= FilterModePrefix
+}
+
+// IsNullMode returns true for modes that match NULL values.
+func (m FilterMode) IsNullMode() bool {
+	return m == FilterModeIsNull || m == FilterModeNotNull
 }
 
 func (m FilterMode) Symbol() string {
@@ -98,9 +138,9 @@ func (m FilterMode) String() string {
 
 func (m FilterMode) MaxValues() int {
 	switch m {
-	case FilterModeTrue, FilterModeFalse:
+	case FilterModeTrue, FilterModeFalse, FilterModeIsNull, FilterModeNotNull:
 		return 0
-	case FilterModeIn, FilterModeNotIn:
+	case FilterModeIn, FilterModeNotIn, FilterModeAny, FilterModeAll:
 		return math.MaxInt
 	case FilterModeRange:
 		return 2
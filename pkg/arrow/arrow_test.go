@@ -0,0 +1,473 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package arrow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"blockwatch.cc/knoxdb/internal/pack"
+	"blockwatch.cc/knoxdb/pkg/num"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"github.com/stretchr/testify/require"
+)
+
+var le = binary.LittleEndian
+
+type TestRow struct {
+	Id      uint64         `knox:"id,pk"`
+	Int64   int64          `knox:"i64"`
+	Int16   int16          `knox:"i16"`
+	Uint8   uint8          `knox:"u8"`
+	Float64 float64        `knox:"f64"`
+	D64     num.Decimal64  `knox:"d64,scale=4"`
+	D128    num.Decimal128 `knox:"d128,scale=18"`
+	I256    num.Int256     `knox:"i256"`
+	Bool    bool           `knox:"bool"`
+	Ts      time.Time      `knox:"ts,scale=ms"`
+	Date    time.Time      `knox:"date,date"`
+	Tod     time.Time      `knox:"tod,time"`
+	String  string         `knox:"string"`
+	Hash    []byte         `knox:"bytes"`
+	Array   [2]byte        `knox:"array2"`
+	OptInt  *int64         `knox:"opt_i64"`
+	OptStr  *string        `knox:"opt_str"`
+}
+
+func makeRows(n int) []TestRow {
+	res := make([]TestRow, n)
+	tm := time.Date(2025, 6, 7, 2, 0, 1, 0, time.UTC)
+	for i := range res {
+		v := int64(i) - int64(n/2)
+		r := TestRow{
+			Id:      uint64(i + 1),
+			Int64:   v * 1e12,
+			Int16:   int16(v),
+			Uint8:   uint8(i) << 4,
+			Float64: float64(v) * 1.5,
+			D64:     num.NewDecimal64(v*10001, 4),
+			D128:    num.NewDecimal128(num.Int128FromInt64(v*1000000000000000001), 18),
+			I256:    num.Int256FromInt64(v).Lsh(200),
+			Bool:    i%3 == 0,
+			Ts:      tm.Add(time.Duration(v) * time.Millisecond),
+			Date:    tm.AddDate(0, 0, int(v)).Truncate(24 * time.Hour),
+			Tod:     time.Unix(int64(i)*61, 0).UTC(),
+			String:  string(rune('a' + i%26)),
+			Hash:    bytes.Repeat([]byte{byte(i)}, i%5),
+			Array:   [2]byte{byte(i), byte(i >> 8)},
+		}
+		if i%4 != 0 {
+			x, s := v, r.String
+			r.OptInt, r.OptStr = &x, &s
+		}
+		res[i] = r
+	}
+	return res
+}
+
+func makePack(t testing.TB, s *schema.Schema, rows []TestRow) *pack.Package {
+	pkg := pack.New().WithSchema(s).WithMaxRows(len(rows)).Alloc()
+	enc := schema.NewGenericEncoder[TestRow]()
+	for _, v := range rows {
+		buf, err := enc.Encode(v, nil)
+		require.NoError(t, err)
+		pkg.AppendWire(buf, nil)
+	}
+	return pkg
+}
+
+// fbTab is a read-only view of a flatbuffer table.
+type fbTab struct {
+	buf []byte
+	pos int
+}
+
+func fbRoot(buf []byte) fbTab {
+	return fbTab{buf, int(le.Uint32(buf))}
+}
+
+// field returns the absolute position of field id or zero when absent.
+func (t fbTab) field(id int) int {
+	vt := t.pos - int(int32(le.Uint32(t.buf[t.pos:])))
+	if 4+2*id >= int(le.Uint16(t.buf[vt:])) {
+		return 0
+	}
+	if off := int(le.Uint16(t.buf[vt+4+2*id:])); off > 0 {
+		return t.pos + off
+	}
+	return 0
+}
+
+func (t fbTab) has(id int) bool {
+	return t.field(id) > 0
+}
+
+func (t fbTab) uint8(id int) uint8 {
+	if p := t.field(id); p > 0 {
+		return t.buf[p]
+	}
+	return 0
+}
+
+func (t fbTab) int16(id int) int16 {
+	if p := t.field(id); p > 0 {
+		return int16(le.Uint16(t.buf[p:]))
+	}
+	return 0
+}
+
+func (t fbTab) int32(id int) int32 {
+	if p := t.field(id); p > 0 {
+		return int32(le.Uint32(t.buf[p:]))
+	}
+	return 0
+}
+
+func (t fbTab) int64(id int) int64 {
+	if p := t.field(id); p > 0 {
+		return int64(le.Uint64(t.buf[p:]))
+	}
+	return 0
+}
+
+func (t fbTab) deref(id int) int {
+	p := t.field(id)
+	if p == 0 {
+		return 0
+	}
+	return p + int(le.Uint32(t.buf[p:]))
+}
+
+func (t fbTab) table(id int) fbTab {
+	return fbTab{t.buf, t.deref(id)}
+}
+
+func (t fbTab) string(id int) string {
+	p := t.deref(id)
+	n := int(le.Uint32(t.buf[p:]))
+	return string(t.buf[p+4 : p+4+n])
+}
+
+func (t fbTab) tables(id int) []fbTab {
+	p := t.deref(id)
+	n := int(le.Uint32(t.buf[p:]))
+	res := make([]fbTab, n)
+	for i := range res {
+		q := p + 4 + 4*i
+		res[i] = fbTab{t.buf, q + int(le.Uint32(t.buf[q:]))}
+	}
+	return res
+}
+
+// structs returns pairs of int64 from a vector of 16 byte structs.
+func (t fbTab) structs(id int) [][2]int64 {
+	p := t.deref(id)
+	if (p+4)%8 != 0 {
+		panic("unaligned struct vector")
+	}
+	n := int(le.Uint32(t.buf[p:]))
+	res := make([][2]int64, n)
+	for i := range res {
+		q := p + 4 + 16*i
+		res[i] = [2]int64{int64(le.Uint64(t.buf[q:])), int64(le.Uint64(t.buf[q+8:]))}
+	}
+	return res
+}
+
+type testMessage struct {
+	typ    uint8
+	header fbTab
+	body   []byte
+}
+
+func readStream(t *testing.T, buf []byte) []testMessage {
+	t.Helper()
+	var msgs []testMessage
+	for {
+		require.GreaterOrEqual(t, len(buf), 8, "short stream")
+		require.Equal(t, uint32(continuation), le.Uint32(buf))
+		n := int(le.Uint32(buf[4:]))
+		buf = buf[8:]
+		if n == 0 {
+			require.Empty(t, buf, "data after end of stream")
+			return msgs
+		}
+		require.Zero(t, n%8, "metadata padding")
+		msg := fbRoot(buf[:n])
+		buf = buf[n:]
+		require.Equal(t, int16(metadataV5), msg.int16(0))
+		size := msg.int64(3)
+		require.Zero(t, size%8, "body padding")
+		msgs = append(msgs, testMessage{
+			typ:    msg.uint8(1),
+			header: msg.table(2),
+			body:   buf[:size],
+		})
+		buf = buf[size:]
+	}
+}
+
+// testColumn is a decoded record batch column.
+type testColumn struct {
+	length, nulls int
+	bufs          [][]byte
+}
+
+func (c testColumn) valid(i int) bool {
+	return len(c.bufs[0]) == 0 || c.bufs[0][i>>3]&(1<<(i&7)) != 0
+}
+
+func (c testColumn) bytes(i int) []byte {
+	offs := c.bufs[1]
+	return c.bufs[2][le.Uint32(offs[4*i:]):le.Uint32(offs[4*i+4:])]
+}
+
+func readBatch(t *testing.T, fields []fbTab, m testMessage) (int, map[string]testColumn) {
+	t.Helper()
+	require.Equal(t, uint8(headerBatch), m.typ)
+	nodes := m.header.structs(1)
+	bufs := m.header.structs(2)
+	require.Len(t, nodes, len(fields))
+	cols := make(map[string]testColumn)
+	for i, f := range fields {
+		nbuf := 2
+		switch f.uint8(2) {
+		case typeUtf8, typeBinary:
+			nbuf = 3
+		}
+		c := testColumn{length: int(nodes[i][0]), nulls: int(nodes[i][1])}
+		for _, b := range bufs[:nbuf] {
+			require.Zero(t, b[0]%8, "buffer alignment")
+			c.bufs = append(c.bufs, m.body[b[0]:b[0]+b[1]])
+		}
+		bufs = bufs[nbuf:]
+		cols[f.string(0)] = c
+	}
+	require.Empty(t, bufs, "extra buffers")
+	return int(m.header.int64(0)), cols
+}
+
+func requireColumns(t *testing.T, rows []TestRow, n int, cols map[string]testColumn) {
+	t.Helper()
+	require.Equal(t, len(rows), n)
+	for name, c := range cols {
+		require.Equal(t, n, c.length, name)
+	}
+	for i, r := range rows {
+		require.Equal(t, r.Id, le.Uint64(cols["id"].bufs[1][8*i:]), "id")
+		require.Equal(t, r.Int64, int64(le.Uint64(cols["i64"].bufs[1][8*i:])), "i64")
+		require.Equal(t, r.Int16, int16(le.Uint16(cols["i16"].bufs[1][2*i:])), "i16")
+		require.Equal(t, r.Uint8, cols["u8"].bufs[1][i], "u8")
+		require.Equal(t, r.Float64, math.Float64frombits(le.Uint64(cols["f64"].bufs[1][8*i:])), "f64")
+
+		d64 := cols["d64"].bufs[1][16*i:]
+		require.Equal(t, r.D64.Int64(), int64(le.Uint64(d64)), "d64 lo")
+		require.Equal(t, r.D64.Int64()>>63, int64(le.Uint64(d64[8:])), "d64 hi")
+
+		d128 := cols["d128"].bufs[1][16*i:]
+		i128 := r.D128.Int128()
+		require.Equal(t, i128[1], le.Uint64(d128), "d128 lo")
+		require.Equal(t, i128[0], le.Uint64(d128[8:]), "d128 hi")
+
+		i256 := cols["i256"].bufs[1][32*i:]
+		for k := range 4 {
+			require.Equal(t, r.I256[3-k], le.Uint64(i256[8*k:]), "i256 word %d", k)
+		}
+
+		require.Equal(t, r.Bool, cols["bool"].bufs[1][i>>3]&(1<<(i&7)) != 0, "bool")
+		require.Equal(t, r.Ts.UnixMilli(), int64(le.Uint64(cols["ts"].bufs[1][8*i:])), "ts")
+		require.Equal(t, r.Date.Unix()/86400, int64(int32(le.Uint32(cols["date"].bufs[1][4*i:]))), "date")
+		require.Equal(t, r.Tod.Unix()%86400, int64(int32(le.Uint32(cols["tod"].bufs[1][4*i:]))), "tod")
+		require.Equal(t, r.String, string(cols["string"].bytes(i)), "string")
+		require.Equal(t, string(r.Hash), string(cols["bytes"].bytes(i)), "bytes")
+		require.Equal(t, r.Array[:], cols["array2"].bufs[1][2*i:2*i+2], "array2")
+
+		require.Equal(t, r.OptInt != nil, cols["opt_i64"].valid(i), "opt_i64 valid")
+		if r.OptInt != nil {
+			require.Equal(t, *r.OptInt, int64(le.Uint64(cols["opt_i64"].bufs[1][8*i:])), "opt_i64")
+		}
+		require.Equal(t, r.OptStr != nil, cols["opt_str"].valid(i), "opt_str valid")
+		if r.OptStr != nil {
+			require.Equal(t, *r.OptStr, string(cols["opt_str"].bytes(i)), "opt_str")
+		} else {
+			require.Empty(t, cols["opt_str"].bytes(i), "opt_str null")
+		}
+	}
+}
+
+func countNulls(rows []TestRow) int {
+	var n int
+	for _, r := range rows {
+		if r.OptInt == nil {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSchema(t *testing.T) {
+	s := schema.MustSchemaOf(TestRow{})
+	buf := new(bytes.Buffer)
+	w, err := NewWriter(s, buf)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	msgs := readStream(t, buf.Bytes())
+	require.Len(t, msgs, 1)
+	require.Equal(t, uint8(headerSchema), msgs[0].typ)
+	hdr := msgs[0].header
+	require.Equal(t, int16(littleEndian), hdr.int16(0))
+
+	fields := hdr.tables(1)
+	require.Len(t, fields, len(s.Fields))
+	for i, f := range fields {
+		require.Equal(t, s.Fields[i].Name, f.string(0))
+		require.Equal(t, s.Fields[i].IsNullable(), f.uint8(1) == 1, f.string(0))
+		require.Len(t, f.tables(5), 0, "children")
+	}
+
+	type typeInfo struct {
+		name   string
+		typ    uint8
+		params []int64
+	}
+	for i, exp := range []typeInfo{
+		{"id", typeInt, []int64{64, 0}},
+		{"i64", typeInt, []int64{64, 1}},
+		{"i16", typeInt, []int64{16, 1}},
+		{"u8", typeInt, []int64{8, 0}},
+		{"f64", typeFloatingPoint, []int64{precisionDouble}},
+		{"d64", typeDecimal, []int64{num.MaxDecimal64Precision, 4, 128}},
+		{"d128", typeDecimal, []int64{num.MaxDecimal128Precision, 18, 128}},
+		{"i256", typeDecimal, []int64{num.MaxDecimal256Precision, 0, 256}},
+		{"bool", typeBool, nil},
+		{"ts", typeTimestamp, []int64{unitMilli}},
+		{"date", typeDate, []int64{unitDay}},
+		{"tod", typeTime, []int64{unitSecond, 32}},
+		{"string", typeUtf8, nil},
+		{"bytes", typeBinary, nil},
+		{"array2", typeFixedSizeBinary, []int64{2}},
+		{"opt_i64", typeInt, []int64{64, 1}},
+		{"opt_str", typeUtf8, nil},
+	} {
+		f := fields[i]
+		require.Equal(t, exp.name, f.string(0))
+		require.Equal(t, exp.typ, f.uint8(2), exp.name)
+		typ := f.table(3)
+		switch exp.typ {
+		case typeInt:
+			require.Equal(t, exp.params, []int64{int64(typ.int32(0)), int64(typ.uint8(1))}, exp.name)
+		case typeFloatingPoint, typeTimestamp, typeDate:
+			require.True(t, typ.has(0), "explicit unit for %s", exp.name)
+			require.Equal(t, exp.params, []int64{int64(typ.int16(0))}, exp.name)
+		case typeTime:
+			require.Equal(t, exp.params, []int64{int64(typ.int16(0)), int64(typ.int32(1))}, exp.name)
+		case typeDecimal:
+			require.Equal(t, exp.params, []int64{int64(typ.int32(0)), int64(typ.int32(1)), int64(typ.int32(2))}, exp.name)
+		case typeFixedSizeBinary:
+			require.Equal(t, exp.params, []int64{int64(typ.int32(0))}, exp.name)
+		}
+	}
+	require.Equal(t, "UTC", fields[9].table(3).string(1))
+
+	// original schema is embedded as custom metadata
+	kv := hdr.tables(2)
+	require.Len(t, kv, 1)
+	require.Equal(t, schemaKey, kv[0].string(0))
+	require.NotEmpty(t, kv[0].string(1))
+}
+
+func TestRecordBatch(t *testing.T) {
+	s := schema.MustSchemaOf(TestRow{})
+	rows := makeRows(40)
+	p1 := makePack(t, s, rows[:24])
+	defer p1.Release()
+	p2 := makePack(t, s, rows[24:])
+	defer p2.Release()
+
+	buf := new(bytes.Buffer)
+	w, err := NewWriter(s, buf)
+	require.NoError(t, err)
+	require.NoError(t, w.WritePack(p1))
+	require.NoError(t, w.WritePack(p2))
+	require.NoError(t, w.Close())
+
+	msgs := readStream(t, buf.Bytes())
+	require.Len(t, msgs, 3)
+	fields := msgs[0].header.tables(1)
+	for i, exp := range [][]TestRow{rows[:24], rows[24:]} {
+		n, cols := readBatch(t, fields, msgs[i+1])
+		requireColumns(t, exp, n, cols)
+		require.Equal(t, countNulls(exp), cols["opt_i64"].nulls)
+		require.Equal(t, countNulls(exp), cols["opt_str"].nulls)
+		require.Zero(t, cols["i64"].nulls)
+		require.Empty(t, cols["i64"].bufs[0], "validity without nulls")
+	}
+}
+
+func TestWriteSelection(t *testing.T) {
+	s := schema.MustSchemaOf(TestRow{})
+	rows := makeRows(10)
+	pkg := makePack(t, s, rows)
+	defer pkg.Release()
+	pkg.WithSelection([]uint32{1, 4, 8, 9})
+
+	buf := new(bytes.Buffer)
+	w, err := NewWriter(s, buf)
+	require.NoError(t, err)
+	require.NoError(t, w.WritePack(pkg))
+	require.NoError(t, w.Close())
+
+	msgs := readStream(t, buf.Bytes())
+	require.Len(t, msgs, 2)
+	exp := []TestRow{rows[1], rows[4], rows[8], rows[9]}
+	n, cols := readBatch(t, msgs[0].header.tables(1), msgs[1])
+	requireColumns(t, exp, n, cols)
+	require.Equal(t, 2, cols["opt_i64"].nulls)
+}
+
+func TestWriteErrors(t *testing.T) {
+	s := schema.MustSchemaOf(TestRow{})
+	other := schema.MustSchemaOf(struct {
+		Id uint64 `knox:"id,pk"`
+	}{})
+	pkg := pack.New().WithSchema(other).WithMaxRows(1).Alloc()
+	defer pkg.Release()
+
+	w, err := NewWriter(s, new(bytes.Buffer))
+	require.NoError(t, err)
+	require.ErrorIs(t, w.WritePack(pkg), schema.ErrSchemaMismatch)
+	require.NoError(t, w.Close())
+	require.Error(t, w.WritePack(pkg))
+}
+
+func TestFlatbuffer(t *testing.T) {
+	var b fbBuilder
+	child := newTable(2).int32(1, -7)
+	root := newTable(6).
+		bool(0, true).
+		int16(1, -2).
+		int64(2, math.MinInt64).
+		object(3, fbString("knox")).
+		object(4, fbVector{child, child}).
+		object(5, fbStructs{1, appendStruct(nil, 3, 4)})
+	buf := b.finish(root)
+	require.Zero(t, len(buf)%8)
+
+	r := fbRoot(buf)
+	require.Equal(t, uint8(1), r.uint8(0))
+	require.Equal(t, int16(-2), r.int16(1))
+	require.Equal(t, int64(math.MinInt64), r.int64(2))
+	require.Zero(t, r.field(2)%8, "int64 alignment")
+	require.Equal(t, "knox", r.string(3))
+	kids := r.tables(4)
+	require.Len(t, kids, 2)
+	require.False(t, kids[0].has(0))
+	require.Equal(t, int32(-7), kids[1].int32(1))
+	require.Equal(t, [][2]int64{{3, 4}}, r.structs(5))
+	require.False(t, r.has(7), "field beyond vtable")
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package arrow
+
+import (
+	"encoding/binary"
+)
+
+// Minimal FlatBuffers serializer for Arrow IPC metadata. Objects are laid
+// out front to back, i.e. a table is written before the children it points
+// to, which keeps all unsigned offsets positive as the format requires.
+
+type fbObject interface {
+	// write serializes the object and returns the position offsets
+	// referencing it must point to.
+	write(b *fbBuilder) int
+}
+
+type fbBuilder struct {
+	buf []byte
+}
+
+// finish serializes root and returns the buffer padded to 8 bytes.
+func (b *fbBuilder) finish(root fbObject) []byte {
+	b.buf = append(b.buf[:0], 0, 0, 0, 0)
+	pos := root.write(b)
+	binary.LittleEndian.PutUint32(b.buf, uint32(pos))
+	b.pad(8, 0)
+	return b.buf
+}
+
+// pad appends zero bytes until the buffer length modulo align equals rem.
+func (b *fbBuilder) pad(align, rem int) {
+	for len(b.buf)%align != rem {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+func (b *fbBuilder) putOffset(at, target int) {
+	binary.LittleEndian.PutUint32(b.buf[at:], uint32(target-at))
+}
+
+// fbField is a table field, either an inline scalar of size bytes or an
+// offset to another object.
+type fbField struct {
+	size int
+	val  uint64
+	obj  fbObject
+}
+
+// fbTable is a table indexed by field id. Unset fields are omitted and
+// read back as their schema default.
+type fbTable []fbField
+
+func newTable(n int) fbTable {
+	return make(fbTable, n)
+}
+
+func (t fbTable) bool(id int, v bool) fbTable {
+	if v {
+		t[id] = fbField{size: 1, val: 1}
+	} else {
+		t[id] = fbField{size: 1}
+	}
+	return t
+}
+
+func (t fbTable) uint8(id int, v uint8) fbTable {
+	t[id] = fbField{size: 1, val: uint64(v)}
+	return t
+}
+
+func (t fbTable) int16(id int, v int16) fbTable {
+	t[id] = fbField{size: 2, val: uint64(uint16(v))}
+	return t
+}
+
+func (t fbTable) int32(id int, v int32) fbTable {
+	t[id] = fbField{size: 4, val: uint64(uint32(v))}
+	return t
+}
+
+func (t fbTable) int64(id int, v int64) fbTable {
+	t[id] = fbField{size: 8, val: uint64(v)}
+	return t
+}
+
+func (t fbTable) object(id int, v fbObject) fbTable {
+	t[id] = fbField{size: 4, obj: v}
+	return t
+}
+
+func (t fbTable) write(b *fbBuilder) int {
+	// place inline fields by decreasing size behind the vtable offset
+	// so every field is naturally aligned
+	offs := make([]int, len(t))
+	size, align := 4, 4
+	for _, sz := range [...]int{8, 4, 2, 1} {
+		for i, f := range t {
+			if f.size != sz {
+				continue
+			}
+			size = (size + sz - 1) &^ (sz - 1)
+			offs[i] = size
+			size += sz
+			align = max(align, sz)
+		}
+	}
+
+	// vtable
+	le := binary.LittleEndian
+	b.pad(2, 0)
+	vt := len(b.buf)
+	b.buf = le.AppendUint16(b.buf, uint16(4+2*len(t)))
+	b.buf = le.AppendUint16(b.buf, uint16(size))
+	for _, o := range offs {
+		b.buf = le.AppendUint16(b.buf, uint16(o))
+	}
+
+	// table
+	b.pad(align, 0)
+	pos := len(b.buf)
+	b.buf = append(b.buf, make([]byte, size)...)
+	le.PutUint32(b.buf[pos:], uint32(int32(pos-vt)))
+	for i, f := range t {
+		switch {
+		case f.size == 0 || f.obj != nil:
+		case f.size == 1:
+			b.buf[pos+offs[i]] = byte(f.val)
+		case f.size == 2:
+			le.PutUint16(b.buf[pos+offs[i]:], uint16(f.val))
+		case f.size == 4:
+			le.PutUint32(b.buf[pos+offs[i]:], uint32(f.val))
+		case f.size == 8:
+			le.PutUint64(b.buf[pos+offs[i]:], f.val)
+		}
+	}
+
+	// children
+	for i, f := range t {
+		if f.obj != nil {
+			b.putOffset(pos+offs[i], f.obj.write(b))
+		}
+	}
+	return pos
+}
+
+// fbString is a zero terminated string.
+type fbString string
+
+func (s fbString) write(b *fbBuilder) int {
+	b.pad(4, 0)
+	pos := len(b.buf)
+	b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(len(s)))
+	b.buf = append(b.buf, s...)
+	b.buf = append(b.buf, 0)
+	return pos
+}
+
+// fbVector is a vector of offsets to tables or strings.
+type fbVector []fbObject
+
+func (v fbVector) write(b *fbBuilder) int {
+	b.pad(4, 0)
+	pos := len(b.buf)
+	b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(len(v)))
+	b.buf = append(b.buf, make([]byte, 4*len(v))...)
+	for i, o := range v {
+		b.putOffset(pos+4+4*i, o.write(b))
+	}
+	return pos
+}
+
+// fbStructs is a vector of n inline structs with 8 byte alignment.
+type fbStructs struct {
+	n    int
+	data []byte
+}
+
+func (v fbStructs) write(b *fbBuilder) int {
+	b.pad(8, 4)
+	pos := len(b.buf)
+	b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(v.n))
+	b.buf = append(b.buf, v.data...)
+	return pos
+}
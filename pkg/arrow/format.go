@@ -0,0 +1,228 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package arrow
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/num"
+	"blockwatch.cc/knoxdb/pkg/schema"
+)
+
+var ErrUnsupportedType = errors.New("arrow: unsupported type")
+
+// key of the custom metadata entry carrying the original knox schema
+const schemaKey = "knox.schema"
+
+// IPC stream framing
+const (
+	continuation = 0xFFFFFFFF
+	metadataV5   = 4 // MetadataVersion.V5
+	littleEndian = 0 // Endianness.Little
+	bufferAlign  = 8
+	headerSchema = 1 // MessageHeader.Schema
+	headerBatch  = 3 // MessageHeader.RecordBatch
+)
+
+// Type union member ids
+const (
+	typeInt             = 2
+	typeFloatingPoint   = 3
+	typeBinary          = 4
+	typeUtf8            = 5
+	typeBool            = 6
+	typeDecimal         = 7
+	typeDate            = 8
+	typeTime            = 9
+	typeTimestamp       = 10
+	typeFixedSizeBinary = 15
+)
+
+// TimeUnit and DateUnit values
+const (
+	unitSecond = 0
+	unitMilli  = 1
+	unitMicro  = 2
+	unitNano   = 3
+	unitDay    = 0
+)
+
+// float precision values
+const (
+	precisionSingle = 1
+	precisionDouble = 2
+)
+
+// nanoseconds per Arrow time unit
+var unitNanos = [...]int64{
+	unitSecond: 1000000000,
+	unitMilli:  1000000,
+	unitMicro:  1000,
+	unitNano:   1,
+}
+
+// kind defines how block values are converted into Arrow buffers.
+type kind byte
+
+const (
+	kindNumber  kind = iota // native little endian numbers, zero copy
+	kindTime                // rescaled time, date and timestamp values
+	kindDecimal             // 32/64 bit decimals widened to 128 bit
+	kindInt128              // 128 bit integers and decimals
+	kindInt256              // 256 bit integers and decimals
+	kindBool                // bitmap
+	kindBinary              // variable length strings and bytes
+	kindFixed               // fixed length bytes
+	kindEnum                // enum codes translated to strings
+)
+
+// column describes the Arrow representation of a schema field.
+type column struct {
+	typ    byte    // type union member
+	params fbTable // type table
+	kind   kind
+	width  int   // value width for kindTime and kindFixed
+	from   int64 // knox time scale in nanoseconds
+	to     int64 // Arrow time unit in nanoseconds
+	tod    bool  // keep time of day only
+}
+
+// newColumn maps a knox schema field to an Arrow column type.
+func newColumn(f *schema.Field) (column, error) {
+	if f.Is(types.FieldFlagEnum) {
+		return column{typ: typeUtf8, params: newTable(0), kind: kindEnum}, nil
+	}
+	c := column{kind: kindNumber}
+	switch f.Type {
+	case types.FieldTypeTimestamp:
+		c.typ = typeTimestamp
+		unit := timeUnit(f.Scale)
+		c.params = newTable(2).int16(0, unit).object(1, fbString("UTC"))
+		if schema.TimeScale(f.Scale) >= schema.TIME_SCALE_DAY {
+			c.kind, c.width = kindTime, 8
+			c.from, c.to = schema.TimeScale(f.Scale).Nanos(), unitNanos[unit]
+		}
+	case types.FieldTypeDate:
+		c.typ = typeDate
+		c.params = newTable(1).int16(0, unitDay)
+		c.kind, c.width = kindTime, 4
+		c.from, c.to = schema.TimeScale(f.Scale).Nanos(), schema.TIME_SCALE_DAY.Nanos()
+	case types.FieldTypeTime:
+		c.typ = typeTime
+		unit := timeUnit(f.Scale)
+		c.kind, c.width, c.tod = kindTime, 4, true
+		if unit >= unitMicro {
+			c.width = 8
+		}
+		c.params = newTable(2).int16(0, unit).int32(1, int32(c.width*8))
+		c.from, c.to = schema.TimeScale(f.Scale).Nanos(), unitNanos[unit]
+	case types.FieldTypeInt64:
+		c.typ, c.params = typeInt, intType(64, true)
+	case types.FieldTypeInt32:
+		c.typ, c.params = typeInt, intType(32, true)
+	case types.FieldTypeInt16:
+		c.typ, c.params = typeInt, intType(16, true)
+	case types.FieldTypeInt8:
+		c.typ, c.params = typeInt, intType(8, true)
+	case types.FieldTypeUint64:
+		c.typ, c.params = typeInt, intType(64, false)
+	case types.FieldTypeUint32:
+		c.typ, c.params = typeInt, intType(32, false)
+	case types.FieldTypeUint16:
+		c.typ, c.params = typeInt, intType(16, false)
+	case types.FieldTypeUint8:
+		c.typ, c.params = typeInt, intType(8, false)
+	case types.FieldTypeFloat64:
+		c.typ = typeFloatingPoint
+		c.params = newTable(1).int16(0, precisionDouble)
+	case types.FieldTypeFloat32:
+		c.typ = typeFloatingPoint
+		c.params = newTable(1).int16(0, precisionSingle)
+	case types.FieldTypeBoolean:
+		c.typ, c.params, c.kind = typeBool, newTable(0), kindBool
+	case types.FieldTypeString:
+		c.typ, c.params, c.kind = typeUtf8, newTable(0), kindBinary
+	case types.FieldTypeBytes:
+		if f.Fixed > 0 {
+			c.typ, c.kind, c.width = typeFixedSizeBinary, kindFixed, int(f.Fixed)
+			c.params = newTable(1).int32(0, int32(f.Fixed))
+		} else {
+			c.typ, c.params, c.kind = typeBinary, newTable(0), kindBinary
+		}
+	case types.FieldTypeBigint:
+		c.typ, c.params, c.kind = typeBinary, newTable(0), kindBinary
+	case types.FieldTypeDecimal32:
+		c.typ, c.kind = typeDecimal, kindDecimal
+		c.params = decimalType(num.MaxDecimal32Precision, f.Scale, 128)
+	case types.FieldTypeDecimal64:
+		c.typ, c.kind = typeDecimal, kindDecimal
+		c.params = decimalType(num.MaxDecimal64Precision, f.Scale, 128)
+	case types.FieldTypeDecimal128:
+		c.typ, c.kind = typeDecimal, kindInt128
+		c.params = decimalType(num.MaxDecimal128Precision, f.Scale, 128)
+	case types.FieldTypeInt128:
+		c.typ, c.kind = typeDecimal, kindInt128
+		c.params = decimalType(num.MaxDecimal128Precision, 0, 128)
+	case types.FieldTypeDecimal256:
+		c.typ, c.kind = typeDecimal, kindInt256
+		c.params = decimalType(num.MaxDecimal256Precision, f.Scale, 256)
+	case types.FieldTypeInt256:
+		c.typ, c.kind = typeDecimal, kindInt256
+		c.params = decimalType(num.MaxDecimal256Precision, 0, 256)
+	default:
+		return c, fmt.Errorf("%w %s for field %s", ErrUnsupportedType, f.Type, f.Name)
+	}
+	return c, nil
+}
+
+func intType(width int32, signed bool) fbTable {
+	return newTable(2).int32(0, width).bool(1, signed)
+}
+
+func decimalType(prec int, scale uint8, width int32) fbTable {
+	return newTable(3).int32(0, int32(prec)).int32(1, int32(scale)).int32(2, width)
+}
+
+// timeUnit returns the Arrow time unit matching knox time scale s. Days
+// are stored as seconds.
+func timeUnit(s uint8) int16 {
+	switch schema.TimeScale(s) {
+	case schema.TIME_SCALE_NANO:
+		return unitNano
+	case schema.TIME_SCALE_MICRO:
+		return unitMicro
+	case schema.TIME_SCALE_MILLI:
+		return unitMilli
+	default:
+		return unitSecond
+	}
+}
+
+// field builds the Field table of schema field f.
+func (c column) field(f *schema.Field) fbTable {
+	return newTable(7).
+		object(0, fbString(f.Name)).
+		bool(1, f.IsNullable()).
+		uint8(2, c.typ).
+		object(3, c.params).
+		object(5, fbVector{})
+}
+
+// message wraps a message header into a Message table.
+func message(header byte, obj fbObject, bodyLen int64) fbTable {
+	return newTable(4).
+		int16(0, metadataV5).
+		uint8(1, header).
+		object(2, obj).
+		int64(3, bodyLen)
+}
+
+// appendStruct appends a FieldNode or Buffer struct.
+func appendStruct(buf []byte, a, b int64) []byte {
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(a))
+	return binary.LittleEndian.AppendUint64(buf, uint64(b))
+}
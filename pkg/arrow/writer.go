@@ -0,0 +1,414 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package arrow
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"blockwatch.cc/knoxdb/internal/block"
+	"blockwatch.cc/knoxdb/internal/pack"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"blockwatch.cc/knoxdb/pkg/util"
+)
+
+var padding [bufferAlign]byte
+
+// Writer writes packs as Arrow IPC stream. The schema message is written
+// before the first record batch, each call to WritePack stores one record
+// batch and Close writes the end-of-stream marker.
+//
+// Numeric columns without selection are written straight from block
+// memory, other types are converted into Arrow layout first.
+type Writer struct {
+	w       io.Writer
+	s       *schema.Schema
+	fields  []*schema.Field // visible fields
+	idx     []int           // pack block index per visible field
+	cols    []column        // Arrow type per visible field
+	body    [][]byte        // record batch buffers
+	bodyLen int64
+	nodes   []byte   // field node structs
+	layout  []byte   // buffer structs
+	scratch [][]byte // conversion buffers
+	fb      fbBuilder
+	started bool
+	closed  bool
+}
+
+// NewWriter creates an Arrow stream writer for packs of schema s. Meta
+// fields are skipped. Returns ErrUnsupportedType when s contains fields
+// without Arrow representation.
+func NewWriter(s *schema.Schema, w io.Writer) (*Writer, error) {
+	aw := &Writer{
+		w: w,
+		s: s,
+	}
+	for i, f := range s.Fields {
+		if !f.IsVisible() {
+			continue
+		}
+		c, err := newColumn(f)
+		if err != nil {
+			return nil, err
+		}
+		aw.fields = append(aw.fields, f)
+		aw.idx = append(aw.idx, i)
+		aw.cols = append(aw.cols, c)
+	}
+	return aw, nil
+}
+
+// WritePack writes selected rows of pkg as a single record batch. Pack and
+// writer schema must match.
+func (w *Writer) WritePack(pkg *pack.Package) error {
+	if w.closed {
+		return io.ErrClosedPipe
+	}
+	if pkg.Schema().Hash != w.s.Hash {
+		return schema.ErrSchemaMismatch
+	}
+	if err := w.writeSchema(); err != nil {
+		return err
+	}
+	sel := pkg.Selected()
+	n := pkg.Len()
+	if sel != nil {
+		n = len(sel)
+	}
+	if n == 0 {
+		return nil
+	}
+
+	w.body = w.body[:0]
+	w.bodyLen = 0
+	w.nodes = w.nodes[:0]
+	w.layout = w.layout[:0]
+	for i, f := range w.fields {
+		if err := w.appendColumn(pkg.Block(w.idx[i]), f, w.cols[i], sel, n); err != nil {
+			return fmt.Errorf("arrow: field %s: %v", f.Name, err)
+		}
+	}
+
+	batch := newTable(3).
+		int64(0, int64(n)).
+		object(1, fbStructs{len(w.fields), w.nodes}).
+		object(2, fbStructs{len(w.body), w.layout})
+	if err := w.writeMessage(headerBatch, batch, w.bodyLen); err != nil {
+		return err
+	}
+	for _, buf := range w.body {
+		if _, err := w.w.Write(buf); err != nil {
+			return err
+		}
+		if pad := -len(buf) & (bufferAlign - 1); pad > 0 {
+			if _, err := w.w.Write(padding[:pad]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close writes the end-of-stream marker. It does not close the underlying
+// writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	if err := w.writeSchema(); err != nil {
+		return err
+	}
+	w.closed = true
+	var eos [8]byte
+	binary.LittleEndian.PutUint32(eos[:], continuation)
+	_, err := w.w.Write(eos[:])
+	return err
+}
+
+func (w *Writer) writeSchema() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	fields := make(fbVector, len(w.fields))
+	for i, f := range w.fields {
+		fields[i] = w.cols[i].field(f)
+	}
+	s := newTable(4).int16(0, littleEndian).object(1, fields)
+	if buf, err := w.s.MarshalBinary(); err == nil {
+		kv := newTable(2).
+			object(0, fbString(schemaKey)).
+			object(1, fbString(base64.StdEncoding.EncodeToString(buf)))
+		s.object(2, fbVector{kv})
+	}
+	return w.writeMessage(headerSchema, s, 0)
+}
+
+// writeMessage writes an encapsulated message header. The message body
+// must follow.
+func (w *Writer) writeMessage(header byte, obj fbObject, bodyLen int64) error {
+	meta := w.fb.finish(message(header, obj, bodyLen))
+	var prefix [8]byte
+	binary.LittleEndian.PutUint32(prefix[:], continuation)
+	binary.LittleEndian.PutUint32(prefix[4:], uint32(len(meta)))
+	if _, err := w.w.Write(prefix[:]); err != nil {
+		return err
+	}
+	_, err := w.w.Write(meta)
+	return err
+}
+
+// addBuffer appends buf to the record batch body.
+func (w *Writer) addBuffer(buf []byte) {
+	w.layout = appendStruct(w.layout, w.bodyLen, int64(len(buf)))
+	w.body = append(w.body, buf)
+	w.bodyLen += int64(len(buf)+bufferAlign-1) &^ (bufferAlign - 1)
+}
+
+// buffer returns an empty conversion buffer for the body buffer ahead
+// positions after the last added buffer.
+func (w *Writer) buffer(ahead int) []byte {
+	k := len(w.body) + ahead
+	for len(w.scratch) <= k {
+		w.scratch = append(w.scratch, nil)
+	}
+	return w.scratch[k][:0]
+}
+
+// keep stores buf for reuse by later record batches and adds it to
+// the body.
+func (w *Writer) keep(buf []byte) {
+	w.scratch[len(w.body)] = buf
+	w.addBuffer(buf)
+}
+
+// appendColumn appends field node and buffers of a single column.
+func (w *Writer) appendColumn(b *block.Block, f *schema.Field, c column, sel []uint32, n int) error {
+	row := func(i int) int {
+		if sel != nil {
+			return int(sel[i])
+		}
+		return i
+	}
+
+	// validity bitmap, omitted when there are no nulls
+	var nulls int
+	if f.IsNullable() && b.HasNulls() {
+		bits := w.buffer(0)
+		bits = append(bits, make([]byte, (n+7)/8)...)
+		for i := range n {
+			if b.IsNull(row(i)) {
+				nulls++
+			} else {
+				bits[i>>3] |= 1 << (i & 7)
+			}
+		}
+		if nulls == 0 {
+			bits = bits[:0]
+		}
+		w.keep(bits)
+	} else {
+		w.addBuffer(nil)
+	}
+	w.nodes = appendStruct(w.nodes, int64(n), int64(nulls))
+
+	le := binary.LittleEndian
+	switch c.kind {
+	case kindNumber:
+		if sel == nil && b.IsMaterialized() {
+			w.addBuffer(numberBytes(b))
+			return nil
+		}
+		buf := w.buffer(0)
+		for i := range n {
+			buf = appendNumber(buf, b, row(i))
+		}
+		w.keep(buf)
+
+	case kindTime:
+		buf := w.buffer(0)
+		acc := b.Int64()
+		for i := range n {
+			v := acc.Get(row(i))
+			if c.tod {
+				d := schema.TIME_SCALE_DAY.Nanos() / c.from
+				v = (v%d + d) % d
+			}
+			v = schema.RescaleTime(v, c.from, c.to)
+			if c.width == 4 {
+				buf = le.AppendUint32(buf, uint32(int32(v)))
+			} else {
+				buf = le.AppendUint64(buf, uint64(v))
+			}
+		}
+		w.keep(buf)
+
+	case kindDecimal:
+		buf := w.buffer(0)
+		for i := range n {
+			var v int64
+			if b.Type() == types.BlockInt32 {
+				v = int64(b.Int32().Get(row(i)))
+			} else {
+				v = b.Int64().Get(row(i))
+			}
+			buf = le.AppendUint64(buf, uint64(v))
+			buf = le.AppendUint64(buf, uint64(v>>63))
+		}
+		w.keep(buf)
+
+	case kindInt128:
+		buf := w.buffer(0)
+		acc := b.Int128()
+		for i := range n {
+			v := acc.Get(row(i))
+			buf = le.AppendUint64(buf, v[1])
+			buf = le.AppendUint64(buf, v[0])
+		}
+		w.keep(buf)
+
+	case kindInt256:
+		buf := w.buffer(0)
+		acc := b.Int256()
+		for i := range n {
+			v := acc.Get(row(i))
+			for k := 3; k >= 0; k-- {
+				buf = le.AppendUint64(buf, v[k])
+			}
+		}
+		w.keep(buf)
+
+	case kindBool:
+		buf := w.buffer(0)
+		buf = append(buf, make([]byte, (n+7)/8)...)
+		acc := b.Bool()
+		for i := range n {
+			if acc.Get(row(i)) {
+				buf[i>>3] |= 1 << (i & 7)
+			}
+		}
+		w.keep(buf)
+
+	case kindFixed:
+		buf := w.buffer(0)
+		acc := b.Bytes()
+		for i := range n {
+			v := acc.Get(row(i))
+			switch {
+			case len(v) == c.width:
+				buf = append(buf, v...)
+			case nulls > 0 && b.IsNull(row(i)):
+				buf = append(buf, make([]byte, c.width)...)
+			default:
+				return fmt.Errorf("invalid fixed length value")
+			}
+		}
+		w.keep(buf)
+
+	case kindBinary, kindEnum:
+		offs := le.AppendUint32(w.buffer(0), 0)
+		data := w.buffer(1)
+		for i := range n {
+			v, err := w.bytesValue(b, f, c, row(i))
+			if err != nil {
+				return err
+			}
+			data = append(data, v...)
+			if len(data) > math.MaxInt32 {
+				return fmt.Errorf("column too large")
+			}
+			offs = le.AppendUint32(offs, uint32(len(data)))
+		}
+		w.keep(offs)
+		w.keep(data)
+	}
+	return nil
+}
+
+// bytesValue returns the string or binary value of row.
+func (w *Writer) bytesValue(b *block.Block, f *schema.Field, c column, row int) ([]byte, error) {
+	if c.kind == kindBinary {
+		return b.Bytes().Get(row), nil
+	}
+	var code uint32
+	if b.Type() == types.BlockUint16 {
+		code = uint32(b.Uint16().Get(row))
+	} else {
+		code = b.Uint32().Get(row)
+	}
+	if f.IsNullable() && b.IsNull(row) {
+		return nil, nil
+	}
+	if f.Enum == nil {
+		return nil, schema.ErrEnumUndefined
+	}
+	v, ok := f.Enum.Value32(code)
+	if !ok {
+		return nil, fmt.Errorf("invalid enum code %d", code)
+	}
+	return util.UnsafeGetBytes(v), nil
+}
+
+// numberBytes returns the raw little endian data of a materialized
+// numeric block without copy.
+func numberBytes(b *block.Block) []byte {
+	switch b.Type() {
+	case types.BlockInt64:
+		return util.ToByteSlice(b.Int64().Slice())
+	case types.BlockInt32:
+		return util.ToByteSlice(b.Int32().Slice())
+	case types.BlockInt16:
+		return util.ToByteSlice(b.Int16().Slice())
+	case types.BlockInt8:
+		return util.ToByteSlice(b.Int8().Slice())
+	case types.BlockUint64:
+		return util.ToByteSlice(b.Uint64().Slice())
+	case types.BlockUint32:
+		return util.ToByteSlice(b.Uint32().Slice())
+	case types.BlockUint16:
+		return util.ToByteSlice(b.Uint16().Slice())
+	case types.BlockUint8:
+		return util.ToByteSlice(b.Uint8().Slice())
+	case types.BlockFloat64:
+		return util.ToByteSlice(b.Float64().Slice())
+	case types.BlockFloat32:
+		return util.ToByteSlice(b.Float32().Slice())
+	default:
+		return nil
+	}
+}
+
+// appendNumber appends the little endian value of row.
+func appendNumber(buf []byte, b *block.Block, row int) []byte {
+	le := binary.LittleEndian
+	switch b.Type() {
+	case types.BlockInt64:
+		return le.AppendUint64(buf, uint64(b.Int64().Get(row)))
+	case types.BlockInt32:
+		return le.AppendUint32(buf, uint32(b.Int32().Get(row)))
+	case types.BlockInt16:
+		return le.AppendUint16(buf, uint16(b.Int16().Get(row)))
+	case types.BlockInt8:
+		return append(buf, byte(b.Int8().Get(row)))
+	case types.BlockUint64:
+		return le.AppendUint64(buf, b.Uint64().Get(row))
+	case types.BlockUint32:
+		return le.AppendUint32(buf, b.Uint32().Get(row))
+	case types.BlockUint16:
+		return le.AppendUint16(buf, b.Uint16().Get(row))
+	case types.BlockUint8:
+		return append(buf, b.Uint8().Get(row))
+	case types.BlockFloat64:
+		return le.AppendUint64(buf, math.Float64bits(b.Float64().Get(row)))
+	case types.BlockFloat32:
+		return le.AppendUint32(buf, math.Float32bits(b.Float32().Get(row)))
+	default:
+		return buf
+	}
+}
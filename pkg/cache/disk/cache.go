@@ -0,0 +1,504 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+// Package disk implements a persistent LRU cache that stores values as
+// files on a local filesystem. It is used as second cache tier behind an
+// in-memory cache when primary storage is slow, e.g. on network volumes.
+//
+// Each entry is stored in its own file below a directory per partition
+// (the first word of a cache key). Files start with a small header that
+// carries a checksum so that torn writes after a crash are detected and
+// dropped on read. Entries survive restarts, recency order is restored
+// from file modification times which are refreshed on cache hits.
+package disk
+
+import (
+	"container/list"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"blockwatch.cc/knoxdb/pkg/cache"
+)
+
+const (
+	headerSize = 8
+	tmpSuffix  = ".tmp"
+
+	// touchInterval limits how often hits refresh file modification
+	// times, recency within this interval is lost on restart
+	touchInterval = time.Minute
+)
+
+var (
+	magic = [4]byte{'K', 'X', 'D', 'C'}
+	crc   = crc32.MakeTable(crc32.Castagnoli)
+
+	ErrCorrupt = errors.New("disk cache: corrupt entry")
+)
+
+type CacheStats struct {
+	Hits      int64
+	Misses    int64
+	Inserts   int64
+	Evictions int64
+	Count     int64
+	Size      int64
+}
+
+type entry struct {
+	key   cache.CacheKey
+	size  int64
+	mtime time.Time
+}
+
+// group holds the entries of a partition which share the low 32 bits of
+// their key, e.g. all blocks and versions of a data pack.
+type group map[uint64]*list.Element
+
+// Cache is a size limited persistent LRU cache. All methods are safe for
+// concurrent use.
+type Cache struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	size    int64
+	lru     *list.List                  // front is most recently used
+	parts   map[uint64]map[uint32]group // entries by partition, group and key
+	len     int
+	stats   CacheStats
+}
+
+// Open opens or creates a cache directory at path with a total size limit
+// of maxSize bytes. Entries from an earlier run are kept as long as they
+// fit into the size limit.
+func Open(path string, maxSize int64) (*Cache, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("disk cache: invalid size %d", maxSize)
+	}
+	if err := os.MkdirAll(path, 0o700); err != nil {
+		return nil, err
+	}
+	c := &Cache{
+		path:    path,
+		maxSize: maxSize,
+		lru:     list.New(),
+		parts:   make(map[uint64]map[uint32]group),
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// load restores entries from disk and removes leftover temporary files.
+func (c *Cache) load() error {
+	var files []entry
+	dirs, err := os.ReadDir(c.path)
+	if err != nil {
+		return err
+	}
+	for _, d := range dirs {
+		tag, err := strconv.ParseUint(d.Name(), 16, 64)
+		if !d.IsDir() || err != nil {
+			continue
+		}
+		dir := filepath.Join(c.path, d.Name())
+		names, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, n := range names {
+			name := filepath.Join(dir, n.Name())
+			sub, err := strconv.ParseUint(n.Name(), 16, 64)
+			if err != nil {
+				// unfinished writes
+				if filepath.Ext(name) == tmpSuffix {
+					os.Remove(name)
+				}
+				continue
+			}
+			info, err := n.Info()
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				return err
+			}
+			if info.Size() < headerSize {
+				os.Remove(name)
+				continue
+			}
+			files = append(files, entry{
+				key:   cache.NewCacheKey(tag, sub),
+				size:  info.Size(),
+				mtime: info.ModTime(),
+			})
+		}
+	}
+
+	// insert oldest first so that the most recent entries end up in front
+	slices.SortStableFunc(files, func(a, b entry) int { return a.mtime.Compare(b.mtime) })
+	for i := range files {
+		c.insertLocked(&files[i])
+	}
+	c.stats.Count = int64(len(files))
+	c.stats.Size = c.size
+	c.mu.Lock()
+	paths := c.evictLocked(nil)
+	c.mu.Unlock()
+	removeFiles(paths)
+	return nil
+}
+
+func (c *Cache) dir(tag uint64) string {
+	return filepath.Join(c.path, fmt.Sprintf("%016x", tag))
+}
+
+func (c *Cache) file(key cache.CacheKey) string {
+	return filepath.Join(c.dir(key[0]), fmt.Sprintf("%016x", key[1]))
+}
+
+// Path returns the cache directory.
+func (c *Cache) Path() string {
+	return c.path
+}
+
+// MaxSize returns the size limit in bytes.
+func (c *Cache) MaxSize() int64 {
+	return c.maxSize
+}
+
+// Get returns a copy of the value stored under key. Corrupt or missing
+// entries are removed and reported as miss.
+func (c *Cache) Get(key cache.CacheKey) ([]byte, bool) {
+	var touch bool
+	now := time.Now()
+	c.mu.Lock()
+	e, ok := c.lookupLocked(key)
+	if ok {
+		c.lru.MoveToFront(e)
+		ent := e.Value.(*entry)
+		if touch = now.Sub(ent.mtime) >= touchInterval; touch {
+			ent.mtime = now
+		}
+	}
+	c.mu.Unlock()
+	if !ok {
+		atomic.AddInt64(&c.stats.Misses, 1)
+		return nil, false
+	}
+
+	// files are replaced atomically, a concurrent eviction can remove
+	// the file which we treat as a miss
+	name := c.file(key)
+	buf, err := os.ReadFile(name)
+	if err == nil {
+		buf, err = decode(buf)
+	}
+	if err != nil {
+		c.Remove(key)
+		atomic.AddInt64(&c.stats.Misses, 1)
+		return nil, false
+	}
+
+	// persist recency for the next restart
+	if touch {
+		os.Chtimes(name, now, now)
+	}
+	atomic.AddInt64(&c.stats.Hits, 1)
+	return buf, true
+}
+
+// Contains returns true when key is cached without updating recency.
+func (c *Cache) Contains(key cache.CacheKey) bool {
+	c.mu.Lock()
+	_, ok := c.lookupLocked(key)
+	c.mu.Unlock()
+	return ok
+}
+
+// Add stores buf under key and evicts least recently used entries when
+// the cache exceeds its size limit. Values larger than the size limit are
+// not stored.
+func (c *Cache) Add(key cache.CacheKey, buf []byte) error {
+	size := int64(headerSize + len(buf))
+	if size > c.maxSize {
+		return nil
+	}
+
+	// write to a temporary file first so readers never see partial data
+	dir := c.dir(key[0])
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, "*"+tmpSuffix)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(encode(buf))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	c.mu.Lock()
+	if err := os.Rename(f.Name(), c.file(key)); err != nil {
+		c.mu.Unlock()
+		os.Remove(f.Name())
+		return err
+	}
+	now := time.Now()
+	if e, ok := c.lookupLocked(key); ok {
+		ent := e.Value.(*entry)
+		c.size += size - ent.size
+		atomic.AddInt64(&c.stats.Size, size-ent.size)
+		ent.size = size
+		ent.mtime = now
+		c.lru.MoveToFront(e)
+	} else {
+		c.insertLocked(&entry{key: key, size: size, mtime: now})
+		atomic.AddInt64(&c.stats.Inserts, 1)
+		atomic.AddInt64(&c.stats.Count, 1)
+		atomic.AddInt64(&c.stats.Size, size)
+	}
+	paths := c.evictLocked(nil)
+	c.mu.Unlock()
+	removeFiles(paths)
+	return nil
+}
+
+// Remove drops key from the cache.
+func (c *Cache) Remove(key cache.CacheKey) {
+	var paths []string
+	c.mu.Lock()
+	if e, ok := c.lookupLocked(key); ok {
+		paths = c.removeLocked(e, paths)
+	}
+	c.mu.Unlock()
+	removeFiles(paths)
+}
+
+// RemoveFunc drops all entries in partition tag for which fn returns true.
+func (c *Cache) RemoveFunc(tag uint64, fn func(sub uint64) bool) {
+	var paths []string
+	c.mu.Lock()
+	for _, g := range c.parts[tag] {
+		for sub, e := range g {
+			if fn(sub) {
+				paths = c.removeLocked(e, paths)
+			}
+		}
+	}
+	c.mu.Unlock()
+	removeFiles(paths)
+}
+
+// RemoveGroup drops entries in partition tag whose low 32 key bits equal
+// grp and for which fn returns true. Unlike RemoveFunc only entries of
+// the group are visited.
+func (c *Cache) RemoveGroup(tag uint64, grp uint32, fn func(sub uint64) bool) {
+	var paths []string
+	c.mu.Lock()
+	for sub, e := range c.parts[tag][grp] {
+		if fn(sub) {
+			paths = c.removeLocked(e, paths)
+		}
+	}
+	c.mu.Unlock()
+	removeFiles(paths)
+}
+
+// Purge drops all entries in partition tag.
+func (c *Cache) Purge(tag uint64) {
+	c.mu.Lock()
+	for _, g := range c.parts[tag] {
+		for _, e := range g {
+			c.removeLocked(e, nil)
+		}
+	}
+	c.mu.Unlock()
+
+	// files of concurrent adds may be removed too, reads treat the
+	// missing files as miss
+	os.RemoveAll(c.dir(tag))
+}
+
+// PurgeAll drops all entries.
+func (c *Cache) PurgeAll() {
+	c.mu.Lock()
+	tags := make([]uint64, 0, len(c.parts))
+	for tag, part := range c.parts {
+		tags = append(tags, tag)
+		for _, g := range part {
+			for _, e := range g {
+				c.removeLocked(e, nil)
+			}
+		}
+	}
+	c.mu.Unlock()
+	for _, tag := range tags {
+		os.RemoveAll(c.dir(tag))
+	}
+}
+
+// Len returns the number of cached entries.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.len
+}
+
+// Size returns the total size of cached entries in bytes.
+func (c *Cache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// Stats returns a snapshot of cache statistics.
+func (c *Cache) Stats() CacheStats {
+	return CacheStats{
+		Hits:      atomic.LoadInt64(&c.stats.Hits),
+		Misses:    atomic.LoadInt64(&c.stats.Misses),
+		Inserts:   atomic.LoadInt64(&c.stats.Inserts),
+		Evictions: atomic.LoadInt64(&c.stats.Evictions),
+		Count:     atomic.LoadInt64(&c.stats.Count),
+		Size:      atomic.LoadInt64(&c.stats.Size),
+	}
+}
+
+// Partition returns a view on all entries with key tag.
+func (c *Cache) Partition(tag uint64) *Partition {
+	return &Partition{Cache: c, Key: tag}
+}
+
+func (c *Cache) lookupLocked(key cache.CacheKey) (*list.Element, bool) {
+	e, ok := c.parts[key[0]][uint32(key[1])][key[1]]
+	return e, ok
+}
+
+func (c *Cache) insertLocked(ent *entry) {
+	part, ok := c.parts[ent.key[0]]
+	if !ok {
+		part = make(map[uint32]group)
+		c.parts[ent.key[0]] = part
+	}
+	g, ok := part[uint32(ent.key[1])]
+	if !ok {
+		g = make(group)
+		part[uint32(ent.key[1])] = g
+	}
+	g[ent.key[1]] = c.lru.PushFront(ent)
+	c.size += ent.size
+	c.len++
+}
+
+// evictLocked drops least recently used entries until the cache fits into
+// its size limit and appends their file paths to paths.
+func (c *Cache) evictLocked(paths []string) []string {
+	for c.size > c.maxSize {
+		e := c.lru.Back()
+		if e == nil {
+			break
+		}
+		paths = c.removeLocked(e, paths)
+		atomic.AddInt64(&c.stats.Evictions, 1)
+	}
+	return paths
+}
+
+// removeLocked drops e from the index and appends its file path to paths.
+// Callers delete files after releasing the lock.
+func (c *Cache) removeLocked(e *list.Element, paths []string) []string {
+	ent := e.Value.(*entry)
+	c.lru.Remove(e)
+	part := c.parts[ent.key[0]]
+	if g := part[uint32(ent.key[1])]; len(g) > 1 {
+		delete(g, ent.key[1])
+	} else if len(part) > 1 {
+		delete(part, uint32(ent.key[1]))
+	} else {
+		delete(c.parts, ent.key[0])
+	}
+	c.size -= ent.size
+	c.len--
+	atomic.AddInt64(&c.stats.Count, -1)
+	atomic.AddInt64(&c.stats.Size, -ent.size)
+	return append(paths, c.file(ent.key))
+}
+
+func removeFiles(paths []string) {
+	for _, p := range paths {
+		os.Remove(p)
+	}
+}
+
+// encode prepends the file header: magic and CRC32-C of buf.
+func encode(buf []byte) []byte {
+	dst := make([]byte, headerSize, headerSize+len(buf))
+	copy(dst, magic[:])
+	binary.LittleEndian.PutUint32(dst[4:], crc32.Checksum(buf, crc))
+	return append(dst, buf...)
+}
+
+func decode(buf []byte) ([]byte, error) {
+	if len(buf) < headerSize || [4]byte(buf[:4]) != magic {
+		return nil, ErrCorrupt
+	}
+	if binary.LittleEndian.Uint32(buf[4:]) != crc32.Checksum(buf[headerSize:], crc) {
+		return nil, ErrCorrupt
+	}
+	return buf[headerSize:], nil
+}
+
+// Partition addresses entries of a single partition by the second
+// key word, similar to cache.CachePartition.
+type Partition struct {
+	Cache *Cache
+	Key   uint64
+}
+
+func (p *Partition) makeKey(sub uint64) cache.CacheKey {
+	return cache.NewCacheKey(p.Key, sub)
+}
+
+func (p *Partition) Get(key uint64) ([]byte, bool) {
+	return p.Cache.Get(p.makeKey(key))
+}
+
+func (p *Partition) Contains(key uint64) bool {
+	return p.Cache.Contains(p.makeKey(key))
+}
+
+func (p *Partition) Add(key uint64, buf []byte) error {
+	return p.Cache.Add(p.makeKey(key), buf)
+}
+
+func (p *Partition) Remove(key uint64) {
+	p.Cache.Remove(p.makeKey(key))
+}
+
+func (p *Partition) RemoveFunc(fn func(key uint64) bool) {
+	p.Cache.RemoveFunc(p.Key, fn)
+}
+
+func (p *Partition) RemoveGroup(grp uint32, fn func(key uint64) bool) {
+	p.Cache.RemoveGroup(p.Key, grp, fn)
+}
+
+func (p *Partition) Purge() {
+	p.Cache.Purge(p.Key)
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package disk
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"blockwatch.cc/knoxdb/pkg/cache"
+	"github.com/stretchr/testify/require"
+)
+
+func key(tag, sub uint64) cache.CacheKey {
+	return cache.NewCacheKey(tag, sub)
+}
+
+func TestDiskCacheAddGet(t *testing.T) {
+	c, err := Open(t.TempDir(), 1<<20)
+	require.NoError(t, err)
+
+	require.NoError(t, c.Add(key(1, 1), []byte("one")))
+	require.NoError(t, c.Add(key(1, 2), []byte("two")))
+	require.NoError(t, c.Add(key(2, 1), []byte("other")))
+
+	buf, ok := c.Get(key(1, 1))
+	require.True(t, ok)
+	require.Equal(t, []byte("one"), buf)
+	_, ok = c.Get(key(1, 3))
+	require.False(t, ok)
+	require.Equal(t, 3, c.Len())
+	require.Equal(t, int64(3*headerSize+11), c.Size())
+
+	// replace
+	require.NoError(t, c.Add(key(1, 1), []byte("uno")))
+	buf, ok = c.Get(key(1, 1))
+	require.True(t, ok)
+	require.Equal(t, []byte("uno"), buf)
+	require.Equal(t, 3, c.Len())
+
+	stats := c.Stats()
+	require.Equal(t, int64(2), stats.Hits)
+	require.Equal(t, int64(1), stats.Misses)
+	require.Equal(t, int64(3), stats.Inserts)
+	require.Equal(t, int64(3), stats.Count)
+}
+
+func TestDiskCacheEvict(t *testing.T) {
+	const sz = 100
+	c, err := Open(t.TempDir(), 3*(headerSize+sz))
+	require.NoError(t, err)
+
+	val := bytes.Repeat([]byte{1}, sz)
+	for i := range 3 {
+		require.NoError(t, c.Add(key(1, uint64(i)), val))
+	}
+
+	// touch the oldest entry so the second one is evicted
+	_, ok := c.Get(key(1, 0))
+	require.True(t, ok)
+	require.NoError(t, c.Add(key(1, 3), val))
+	require.Equal(t, 3, c.Len())
+	require.True(t, c.Contains(key(1, 0)))
+	require.False(t, c.Contains(key(1, 1)))
+	require.Equal(t, int64(1), c.Stats().Evictions)
+
+	// values larger than the cache are skipped
+	require.NoError(t, c.Add(key(1, 4), make([]byte, 4*sz)))
+	require.False(t, c.Contains(key(1, 4)))
+	require.Equal(t, 3, c.Len())
+}
+
+func TestDiskCacheReopen(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Open(dir, 1<<20)
+	require.NoError(t, err)
+	require.NoError(t, c.Add(key(1, 1), []byte("one")))
+	require.NoError(t, c.Add(key(1, 2), []byte("two")))
+	require.NoError(t, c.Add(key(2, 1), []byte("three")))
+
+	// corrupt an entry and leave a temporary file behind
+	require.NoError(t, os.WriteFile(c.file(key(1, 2)), []byte("KXDC0000two"), 0o600))
+	require.NoError(t, os.WriteFile(c.dir(1)+"/123"+tmpSuffix, []byte("tmp"), 0o600))
+
+	c, err = Open(dir, 1<<20)
+	require.NoError(t, err)
+	require.Equal(t, 3, c.Len())
+	buf, ok := c.Get(key(1, 1))
+	require.True(t, ok)
+	require.Equal(t, []byte("one"), buf)
+	_, ok = c.Get(key(1, 2))
+	require.False(t, ok, "corrupt entry")
+	require.Equal(t, 2, c.Len())
+	_, err = os.Stat(c.dir(1) + "/123" + tmpSuffix)
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	// smaller limits evict on open
+	c, err = Open(dir, headerSize+5)
+	require.NoError(t, err)
+	require.Equal(t, 1, c.Len())
+}
+
+func TestDiskCacheReopenRecency(t *testing.T) {
+	const sz = 100
+	dir := t.TempDir()
+	c, err := Open(dir, 3*(headerSize+sz))
+	require.NoError(t, err)
+
+	// entries written an hour apart, oldest first
+	val := bytes.Repeat([]byte{1}, sz)
+	now := time.Now()
+	for i := range 3 {
+		require.NoError(t, c.Add(key(1, uint64(i)), val))
+		mtime := now.Add(time.Duration(i-3) * time.Hour)
+		require.NoError(t, os.Chtimes(c.file(key(1, uint64(i))), mtime, mtime))
+	}
+
+	// a hit on the oldest entry is remembered across restarts
+	c, err = Open(dir, 3*(headerSize+sz))
+	require.NoError(t, err)
+	_, ok := c.Get(key(1, 0))
+	require.True(t, ok)
+	info, err := os.Stat(c.file(key(1, 0)))
+	require.NoError(t, err)
+	require.False(t, info.ModTime().Before(now))
+
+	c, err = Open(dir, 3*(headerSize+sz))
+	require.NoError(t, err)
+	require.NoError(t, c.Add(key(1, 3), val))
+	require.True(t, c.Contains(key(1, 0)))
+	require.False(t, c.Contains(key(1, 1)))
+	require.True(t, c.Contains(key(1, 2)))
+	_, err = os.Stat(c.file(key(1, 1)))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestDiskCacheMissingFile(t *testing.T) {
+	c, err := Open(t.TempDir(), 1<<20)
+	require.NoError(t, err)
+	require.NoError(t, c.Add(key(1, 1), []byte("one")))
+	require.NoError(t, os.Remove(c.file(key(1, 1))))
+	_, ok := c.Get(key(1, 1))
+	require.False(t, ok)
+	require.Equal(t, 0, c.Len())
+	require.Equal(t, int64(0), c.Size())
+}
+
+func TestDiskCachePartition(t *testing.T) {
+	c, err := Open(t.TempDir(), 1<<20)
+	require.NoError(t, err)
+	p, q := c.Partition(1), c.Partition(2)
+	for i := range 4 {
+		require.NoError(t, p.Add(uint64(i), []byte{byte(i)}))
+		require.NoError(t, q.Add(uint64(i), []byte{byte(i)}))
+	}
+
+	p.RemoveFunc(func(k uint64) bool { return k%2 == 0 })
+	require.False(t, p.Contains(0))
+	require.True(t, p.Contains(1))
+	require.True(t, q.Contains(0))
+	require.Equal(t, 6, c.Len())
+
+	p.Purge()
+	require.False(t, p.Contains(1))
+	require.Equal(t, 4, c.Len())
+	_, err = os.Stat(c.dir(1))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	c.PurgeAll()
+	require.Equal(t, 0, c.Len())
+	require.Equal(t, int64(0), c.Size())
+}
+
+func TestDiskCacheRemoveGroup(t *testing.T) {
+	c, err := Open(t.TempDir(), 1<<20)
+	require.NoError(t, err)
+	p, q := c.Partition(1), c.Partition(2)
+	for grp := range uint64(3) {
+		for hi := range uint64(4) {
+			require.NoError(t, p.Add(hi<<32|grp, []byte{byte(hi)}))
+		}
+	}
+	require.NoError(t, q.Add(1, []byte{1}))
+
+	// only entries of group 1 are visited
+	var seen []uint64
+	p.RemoveGroup(1, func(k uint64) bool {
+		seen = append(seen, k)
+		return k>>32 != 2
+	})
+	require.ElementsMatch(t, []uint64{1, 1<<32 | 1, 2<<32 | 1, 3<<32 | 1}, seen)
+	require.True(t, p.Contains(2<<32|1))
+	require.False(t, p.Contains(1))
+	require.False(t, p.Contains(3<<32|1))
+	require.True(t, p.Contains(0))
+	require.True(t, p.Contains(3<<32|2))
+	require.True(t, q.Contains(1))
+	require.Equal(t, 10, c.Len())
+
+	// emptied groups are dropped
+	p.RemoveGroup(1, func(uint64) bool { return true })
+	require.NotContains(t, c.parts[1], uint32(1))
+	require.Equal(t, 9, c.Len())
+	p.RemoveGroup(7, func(uint64) bool { return true })
+	require.Equal(t, 9, c.Len())
+
+	// group index is rebuilt on reopen
+	c2, err := Open(c.Path(), 1<<20)
+	require.NoError(t, err)
+	seen = seen[:0]
+	c2.Partition(1).RemoveGroup(2, func(k uint64) bool {
+		seen = append(seen, k)
+		return true
+	})
+	require.Len(t, seen, 4)
+	require.Equal(t, 5, c2.Len())
+}
@@ -506,6 +506,15 @@ func (d *Decoder) decodePhysical(base unsafe.Pointer, line []string) error {
 			*(*string)(ptr) = d.pool.GetString(n)
 
 		case types.FieldTypeBytes:
+			if l := f.Logical(); l > 0 {
+				// parse logical type text format
+				val, err := l.Parse(line[i])
+				if err != nil {
+					return &DecodeError{d.r.lineNo, i, f.Name, line[i], err}
+				}
+				copy(unsafe.Slice((*byte)(ptr), f.Fixed), val)
+				break
+			}
 			// decode hex to binary
 			s := strings.TrimPrefix(line[i], "0x")
 			if f.Fixed > 0 {
@@ -572,7 +581,7 @@ func (d *Decoder) decodePhysical(base unsafe.Pointer, line []string) error {
 			if err != nil {
 				return &DecodeError{d.r.lineNo, i, f.Name, line[i], err}
 			}
-			*(*[]byte)(ptr) = bytes.Clone(big.Bytes()) // copy
+			*(*[]byte)(ptr) = big.Bytes()
 
 		default:
 			return &DecodeError{d.r.lineNo, i, f.Name, line[i], schema.ErrInvalidValueType}
@@ -719,6 +728,15 @@ func (d *Decoder) decodeLogical(base unsafe.Pointer, line []string) error {
 			*(*string)(ptr) = d.pool.GetString(n)
 
 		case types.FieldTypeBytes:
+			if l := f.Logical(); l > 0 {
+				// parse logical type text format
+				val, err := l.Parse(line[i])
+				if err != nil {
+					return &DecodeError{d.r.lineNo, i, f.Name, line[i], err}
+				}
+				copy(unsafe.Slice((*byte)(ptr), f.Fixed), val)
+				break
+			}
 			// decode hex to binary
 			s := strings.TrimPrefix(line[i], "0x")
 			if f.Fixed > 0 {
@@ -831,7 +849,7 @@ func (d *Decoder) decodePack(pkg *pack.Package, line []string) error {
 				b.Uint8().Append(0)
 			case types.BlockBool:
 				b.Bool().Append(false)
-			case types.BlockBytes:
+			case types.BlockBytes, types.BlockBigint:
 				if fixed := f.Fixed; fixed > 0 {
 					if fixed <= 32 {
 						b.Bytes().Append(zeros[:fixed])
@@ -978,6 +996,15 @@ func (d *Decoder) decodePack(pkg *pack.Package, line []string) error {
 			b.Bytes().Append(util.UnsafeGetBytes(line[i]))
 
 		case types.FieldTypeBytes:
+			if l := f.Logical(); l > 0 {
+				// parse logical type text format
+				val, err := l.Parse(line[i])
+				if err != nil {
+					return &DecodeError{d.r.lineNo, i, f.Name, line[i], err}
+				}
+				b.Bytes().Append(val)
+				break
+			}
 			// decode hex to binary
 			s := strings.TrimPrefix(line[i], "0x")
 			var (
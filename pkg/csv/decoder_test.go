@@ -5,6 +5,7 @@ package csv
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -189,6 +190,27 @@ func TestDecodeWithType(t *testing.T) {
 	require.Equal(t, fmt.Sprintf("%v", &BV), fmt.Sprintf("%v", val))
 }
 
+func TestDecodeLogical(t *testing.T) {
+	dec := NewDecoder(schema.MustSchemaOf(L{}), strings.NewReader(CsvL)).WithHeader(false)
+	val, err := dec.Decode()
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("%v", &LV), fmt.Sprintf("%v", val))
+}
+
+func TestDecodeBigint(t *testing.T) {
+	s := schema.NewBuilder().Bigint("b").Finalize().Schema()
+	dec := NewDecoder(s, strings.NewReader("115792089237316195423570985008687907853269984665640564039457584007913129639936\n-5\n")).WithHeader(false)
+	for _, v := range []string{
+		"115792089237316195423570985008687907853269984665640564039457584007913129639936",
+		"-5",
+	} {
+		val, err := dec.Decode()
+		require.NoError(t, err)
+		buf := reflect.ValueOf(val).Elem().Field(0).Bytes()
+		require.Equal(t, v, num.NewBigFromBytes(buf).String())
+	}
+}
+
 func BenchmarkDecoder(b *testing.B) {
 	s := NewSniffer(strings.NewReader(netBench), 0)
 	require.NoError(b, s.Sniff())
@@ -14,6 +14,7 @@ import (
 	"time"
 	"unsafe"
 
+	"blockwatch.cc/knoxdb/internal/pack"
 	"blockwatch.cc/knoxdb/internal/types"
 	"blockwatch.cc/knoxdb/pkg/num"
 	"blockwatch.cc/knoxdb/pkg/schema"
@@ -155,6 +156,96 @@ func (e *Encoder) EncodeSlice(v []any) error {
 	return nil
 }
 
+// Encodes all selected records from pkg. Pack and encoder schema must match.
+func (e *Encoder) EncodePack(pkg *pack.Package) error {
+	// check pack schema
+	if pkg.Schema().Hash != e.s.Hash {
+		return schema.ErrSchemaMismatch
+	}
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	// encode rows through a reusable native struct
+	val := reflect.New(e.typ)
+	base := val.UnsafePointer()
+	for _, row := range pkg.Selected() {
+		val.Elem().SetZero()
+		e.readPack(base, pkg, int(row))
+		if err := e.encode(base); err != nil {
+			return err
+		}
+	}
+	if pkg.Selected() == nil {
+		for row := range pkg.Len() {
+			val.Elem().SetZero()
+			e.readPack(base, pkg, row)
+			if err := e.encode(base); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readPack copies visible field values of a pack row into a native struct.
+func (e *Encoder) readPack(base unsafe.Pointer, pkg *pack.Package, row int) {
+	var i int
+	for k, f := range pkg.Schema().Fields {
+		if !f.IsVisible() {
+			continue
+		}
+		ptr := unsafe.Add(base, e.ofs[i])
+		i++
+
+		// keep zero value for missing blocks (e.g. after schema change)
+		b := pkg.Block(k)
+		if b == nil {
+			continue
+		}
+
+		switch f.Type {
+		case types.FieldTypeTimestamp, types.FieldTypeDate, types.FieldTypeTime,
+			types.FieldTypeInt64, types.FieldTypeDecimal64:
+			*(*int64)(ptr) = b.Int64().Get(row)
+		case types.FieldTypeInt32, types.FieldTypeDecimal32:
+			*(*int32)(ptr) = b.Int32().Get(row)
+		case types.FieldTypeInt16:
+			*(*int16)(ptr) = b.Int16().Get(row)
+		case types.FieldTypeInt8:
+			*(*int8)(ptr) = b.Int8().Get(row)
+		case types.FieldTypeUint64:
+			*(*uint64)(ptr) = b.Uint64().Get(row)
+		case types.FieldTypeUint32:
+			*(*uint32)(ptr) = b.Uint32().Get(row)
+		case types.FieldTypeUint16:
+			*(*uint16)(ptr) = b.Uint16().Get(row)
+		case types.FieldTypeUint8:
+			*(*uint8)(ptr) = b.Uint8().Get(row)
+		case types.FieldTypeFloat64:
+			*(*float64)(ptr) = b.Float64().Get(row)
+		case types.FieldTypeFloat32:
+			*(*float32)(ptr) = b.Float32().Get(row)
+		case types.FieldTypeBoolean:
+			*(*bool)(ptr) = b.Bool().Get(row)
+		case types.FieldTypeString:
+			*(*string)(ptr) = util.UnsafeGetString(b.Bytes().Get(row))
+		case types.FieldTypeBytes:
+			if f.Fixed > 0 {
+				copy(unsafe.Slice((*byte)(ptr), f.Fixed), b.Bytes().Get(row))
+			} else {
+				*(*[]byte)(ptr) = b.Bytes().Get(row)
+			}
+		case types.FieldTypeBigint:
+			*(*[]byte)(ptr) = b.Bytes().Get(row)
+		case types.FieldTypeInt128, types.FieldTypeDecimal128:
+			*(*[16]byte)(ptr) = b.Int128().Get(row).Bytes16()
+		case types.FieldTypeInt256, types.FieldTypeDecimal256:
+			*(*[32]byte)(ptr) = b.Int256().Get(row).Bytes32()
+		}
+	}
+}
+
 func (e *Encoder) writeHeader() error {
 	if e.flags&EncoderFlagWriteHeader == 0 {
 		return nil
@@ -269,8 +360,10 @@ func (e *Encoder) encode(base unsafe.Pointer) error {
 			}
 
 		case types.FieldTypeBytes:
-			// encode hex
-			if f.Fixed > 0 {
+			// encode logical types in text format, others as hex
+			if l := f.Logical(); l > 0 {
+				e.buf = l.Append(e.buf, unsafe.Slice((*byte)(ptr), f.Fixed))
+			} else if f.Fixed > 0 {
 				e.buf = hex.AppendEncode(e.buf, unsafe.Slice((*byte)(ptr), f.Fixed))
 			} else {
 				e.buf = hex.AppendEncode(e.buf, *(*[]byte)(ptr))
@@ -6,8 +6,11 @@ package csv
 import (
 	"bytes"
 	"io"
+	"net/netip"
+	"strings"
 	"testing"
 
+	"blockwatch.cc/knoxdb/internal/pack"
 	"blockwatch.cc/knoxdb/pkg/schema"
 	"github.com/stretchr/testify/require"
 )
@@ -94,6 +97,16 @@ var EncoderCases = []encoderTest{
 		Trim:   false,
 		Err:    false,
 	},
+	{
+		Name:   "Logical",
+		Src:    &LV,
+		Schema: schema.MustSchemaOf(L{}),
+		Sep:    ',',
+		Csv:    CsvL,
+		Header: false,
+		Trim:   false,
+		Err:    false,
+	},
 	{
 		Name:   "DoubleQuote",
 		Src:    &A{`Hello,"me"`, 1, 0.1, true},
@@ -106,6 +119,20 @@ var EncoderCases = []encoderTest{
 	},
 }
 
+type L struct {
+	Uid  [16]byte `knox:"uid,uuid"`
+	Peer [16]byte `knox:"peer,ip"`
+	Addr [20]byte `knox:"addr,addr20"`
+}
+
+var LV = L{
+	Uid:  [16]byte{0x75, 0xfc, 0xf8, 0x75, 0x01, 0x7d, 0x45, 0x79, 0xbf, 0xd9, 0x79, 0x1d, 0x3e, 0x67, 0x67, 0xf0},
+	Peer: netip.MustParseAddr("10.1.2.3").As16(),
+	Addr: [20]byte{19: 1},
+}
+
+const CsvL = "75fcf875-017d-4579-bfd9-791d3e6767f0,10.1.2.3,0x0000000000000000000000000000000000000001\n"
+
 func TestEncode(t *testing.T) {
 	for _, c := range EncoderCases {
 		t.Run(c.Name, func(t *testing.T) {
@@ -126,6 +153,31 @@ func TestEncodeSlice(t *testing.T) {
 	require.NoError(t, enc.Encode([]A{A1V, A3V, A1V}))
 }
 
+func TestEncodePack(t *testing.T) {
+	for _, c := range []struct {
+		Name string
+		S    *schema.Schema
+		Csv  string
+	}{
+		{"AllTypes", schema.MustSchemaOf(SchemaB{}), CsvB + "\n"},
+		{"Logical", schema.MustSchemaOf(L{}), CsvL},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			pkg := pack.New().WithSchema(c.S).WithMaxRows(4).Alloc()
+			defer pkg.Release()
+			dec := NewDecoder(c.S, strings.NewReader(c.Csv+c.Csv)).WithHeader(false)
+			n, err := dec.DecodePack(pkg)
+			require.NoError(t, err)
+			require.Equal(t, 2, n)
+
+			w := new(bytes.Buffer)
+			enc := NewEncoder(c.S, w).WithHeader(false)
+			require.NoError(t, enc.EncodePack(pkg))
+			require.Equal(t, c.Csv+c.Csv, w.String())
+		})
+	}
+}
+
 func BenchmarkEncoder(b *testing.B) {
 	s := schema.MustSchemaOf(SchemaB{})
 	enc := NewEncoder(s, io.Discard)
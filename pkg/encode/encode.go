@@ -46,6 +46,7 @@ const (
 	TStringFixed      = encode.TStringFixed
 	TStringCompact    = encode.TStringCompact
 	TStringDictionary = encode.TStringDictionary
+	TStringFSST       = encode.TStringFSST
 
 	TBitmapZero   = encode.TBitmapZero
 	TBitmapOne    = encode.TBitmapOne
@@ -0,0 +1,36 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package knox
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"blockwatch.cc/knoxdb/pkg/arrow"
+)
+
+// WriteArrow writes a query result to w as Arrow IPC stream with a single
+// record batch. Numeric columns are written directly from result memory.
+func WriteArrow(res QueryResult, w io.Writer) error {
+	aw, err := arrow.NewWriter(res.Schema(), w)
+	if err != nil {
+		return err
+	}
+	if err := aw.WritePack(res.Pack()); err != nil {
+		return err
+	}
+	return aw.Close()
+}
+
+// ExportArrow runs the query and writes its result to w as Arrow IPC
+// stream. The full result is materialized before encoding.
+func (q Query) ExportArrow(ctx context.Context, w io.Writer) error {
+	res, err := q.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("query %s: %v", q.tag, err)
+	}
+	defer res.Close()
+	return WriteArrow(res, w)
+}
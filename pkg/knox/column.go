@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package knox
+
+import (
+	"fmt"
+	"iter"
+
+	"blockwatch.cc/knoxdb/internal/block"
+	"blockwatch.cc/knoxdb/internal/encode"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/schema"
+)
+
+// Column returns all values of result column name as typed slice. T must
+// match the column's storage type, i.e. int64 for timestamps and 64bit
+// decimals or uint16/uint32 codes for enums. When the column is stored
+// uncompressed and the result has no selection the returned slice
+// references result memory directly and is only valid until the result
+// is closed. Otherwise values are copied.
+func Column[T types.Number](res QueryResult, name string) ([]T, error) {
+	b, err := resultBlock[T](res, name)
+	if err != nil {
+		return nil, err
+	}
+	acc := block.GetAccessor[T](b)
+	sel := res.Pack().Selected()
+	if sel == nil && b.IsMaterialized() {
+		return acc.Slice(), nil
+	}
+	if b.IsMaterialized() {
+		return acc.AppendTo(make([]T, 0, len(sel)), sel), nil
+	}
+	n := b.Len()
+	if sel != nil {
+		n = len(sel)
+	}
+	vals := make([]T, n)
+	for i := range vals {
+		row := i
+		if sel != nil {
+			row = int(sel[i])
+		}
+		vals[i] = acc.Get(row)
+	}
+	return vals, nil
+}
+
+// Chunks returns an iterator over result column name which yields the
+// row offset and up to types.CHUNK_SIZE consecutive values at a time.
+// Yielded slices are only valid during a single iteration step.
+func Chunks[T types.Number](res QueryResult, name string) (iter.Seq2[int, []T], error) {
+	b, err := resultBlock[T](res, name)
+	if err != nil {
+		return nil, err
+	}
+	acc := block.GetAccessor[T](b)
+	sel := res.Pack().Selected()
+	return func(fn func(int, []T) bool) {
+		if sel != nil {
+			var buf [types.CHUNK_SIZE]T
+			for i := 0; i < len(sel); i += types.CHUNK_SIZE {
+				chunk := buf[:min(types.CHUNK_SIZE, len(sel)-i)]
+				for k := range chunk {
+					chunk[k] = acc.Get(int(sel[i+k]))
+				}
+				if !fn(i, chunk) {
+					return
+				}
+			}
+			return
+		}
+		it := acc.Chunks()
+		defer it.Close()
+		for i := 0; ; {
+			chunk, n := it.NextChunk()
+			if n == 0 {
+				return
+			}
+			if !fn(i, chunk[:n]) {
+				return
+			}
+			i += n
+		}
+	}, nil
+}
+
+// resultBlock returns the block of result column name after checking its
+// storage type matches T.
+func resultBlock[T types.Number](res QueryResult, name string) (*block.Block, error) {
+	idx, ok := res.Schema().Index(name)
+	if !ok {
+		return nil, fmt.Errorf("column %q: %w", name, schema.ErrInvalidField)
+	}
+	b := res.Pack().Block(idx)
+	if b == nil {
+		return nil, fmt.Errorf("column %q: %w", name, schema.ErrInvalidField)
+	}
+	if typ := encode.BlockType[T](); b.Type() != typ {
+		return nil, fmt.Errorf("column %q: %w %s for %s block",
+			name, schema.ErrInvalidValueType, typ, b.Type())
+	}
+	return b, nil
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package knox
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"blockwatch.cc/knoxdb/internal/pack"
+	"blockwatch.cc/knoxdb/pkg/csv"
+)
+
+// number of CSV records decoded per import batch
+const csvImportBatchSize = 1 << 16
+
+// CSVOptions controls the CSV dialect used for import and export.
+type CSVOptions struct {
+	Separator  rune   // field separator, defaults to ','
+	Comment    rune   // comment line prefix (import only), none when zero
+	Header     bool   // read or write a header line
+	Trim       bool   // trim whitespace around fields
+	TimeFormat string // optional time format override
+	DateFormat string // optional date format override
+}
+
+func DefaultCSVOptions() CSVOptions {
+	return CSVOptions{
+		Separator: csv.Separator,
+		Header:    true,
+	}
+}
+
+func (o CSVOptions) decoder(d *csv.Decoder) *csv.Decoder {
+	d.WithHeader(o.Header).WithTrim(o.Trim)
+	if o.Separator != 0 {
+		d.WithSeparator(o.Separator)
+	}
+	if o.Comment != 0 {
+		d.WithComment(o.Comment)
+	}
+	if o.TimeFormat != "" {
+		d.WithTimeFormat(o.TimeFormat)
+	}
+	if o.DateFormat != "" {
+		d.WithDateFormat(o.DateFormat)
+	}
+	return d
+}
+
+func (o CSVOptions) encoder(e *csv.Encoder) *csv.Encoder {
+	e.WithHeader(o.Header).WithTrim(o.Trim)
+	if o.Separator != 0 {
+		e.WithSeparator(o.Separator)
+	}
+	if o.TimeFormat != "" {
+		e.WithTimeFormat(o.TimeFormat)
+	}
+	if o.DateFormat != "" {
+		e.WithDateFormat(o.DateFormat)
+	}
+	return e
+}
+
+// ImportCSV bulk loads CSV records from r into the table. CSV columns must
+// match the table's visible fields in order. Non-zero primary keys in the
+// CSV are preserved and must be strictly ascending and larger than any
+// existing key, zero keys are assigned from the table sequence. Records are
+// stored at pack granularity bypassing the journal, therefore only the
+// transaction which created the table may import, other transactions fail
+// with ErrTableNotNew. Aborting this transaction drops the table along with
+// imported data. Returns the first assigned primary key and the number of
+// imported records.
+func (t TableImpl) ImportCSV(ctx context.Context, r io.Reader, opts CSVOptions) (uint64, int, error) {
+	s := t.table.Schema()
+	dec := opts.decoder(csv.NewDecoder(s, r))
+	pkg := pack.New().
+		WithSchema(s).
+		WithMaxRows(csvImportBatchSize).
+		Alloc()
+	defer pkg.Release()
+
+	// use or open tx
+	ctx, commit, abort, err := t.db.Begin(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer abort()
+
+	var (
+		firstPk uint64
+		total   int
+	)
+	for {
+		n, err := dec.DecodePack(pkg)
+		if err != nil {
+			return 0, total, fmt.Errorf("csv import: %v", err)
+		}
+		if n == 0 {
+			break
+		}
+		pk, n, err := t.table.ImportInto(ctx, pkg)
+		if err != nil {
+			return 0, total, err
+		}
+		if total == 0 {
+			firstPk = pk
+		}
+		total += n
+	}
+
+	if err := commit(); err != nil {
+		return 0, total, err
+	}
+
+	return firstPk, total, nil
+}
+
+// ExportCSV runs the query and writes its result to w. The full result is
+// materialized before encoding.
+func (q Query) ExportCSV(ctx context.Context, w io.Writer, opts CSVOptions) error {
+	res, err := q.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("query %s: %v", q.tag, err)
+	}
+	defer res.Close()
+	enc := opts.encoder(csv.NewEncoder(res.Schema(), w))
+	return enc.EncodePack(res.Pack())
+}
@@ -66,6 +66,14 @@ func (d *DB) Sync(ctx context.Context) error {
 	return d.engine.Sync(ctx)
 }
 
+func (d *DB) Checkpoint(ctx context.Context) error {
+	return d.engine.Checkpoint(ctx)
+}
+
+func (d *DB) GC(ctx context.Context) error {
+	return d.engine.GC(ctx)
+}
+
 // Transaction
 func (d *DB) Begin(ctx context.Context, flags ...TxFlags) (context.Context, func() error, func() error, error) {
 	ctx, _, commit, abort, err := d.engine.WithTransaction(ctx, flags...)
@@ -117,6 +125,10 @@ func (d *DB) CompactTable(ctx context.Context, name string) error {
 	return d.engine.CompactTable(ctx, name)
 }
 
+func (d *DB) FlushTable(ctx context.Context, name string) error {
+	return d.engine.FlushTable(ctx, name)
+}
+
 // Index
 func (d *DB) ListIndexes(name string) []string {
 	return d.engine.IndexNames(name)
@@ -127,7 +139,7 @@ func (d *DB) FindIndex(name string) (Index, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &IndexImpl{i, d}, nil
+	return &IndexImpl{index: i, db: d, engine: d.engine}, nil
 }
 
 func (d *DB) CreateIndex(ctx context.Context, s *schema.IndexSchema, opts ...Option) error {
@@ -143,6 +155,28 @@ func (d *DB) DropIndex(ctx context.Context, name string) error {
 	return d.engine.DropIndex(ctx, name)
 }
 
+// Rollup
+func (d *DB) ListRollups(name string) []string {
+	tables := d.engine.RollupTables(name)
+	names := make([]string, 0, len(tables))
+	for _, t := range tables {
+		names = append(names, t.Schema().Name)
+	}
+	return names
+}
+
+func (d *DB) CreateRollup(ctx context.Context, r *Rollup, opts ...Option) error {
+	_, err := d.engine.CreateRollup(ctx, r, opts...)
+	return err
+}
+
+func (d *DB) DropRollup(ctx context.Context, name string) error {
+	if !d.engine.IsRollupTable(name) {
+		return engine.ErrNoTable
+	}
+	return d.engine.DropTable(ctx, name)
+}
+
 // Enum
 func (d *DB) ListEnums() []string {
 	return d.engine.EnumNames()
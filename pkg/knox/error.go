@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 
 	"blockwatch.cc/knoxdb/internal/engine"
 	"blockwatch.cc/knoxdb/internal/types"
@@ -28,6 +29,9 @@ var (
 	ErrIndexExists    = engine.ErrIndexExists
 	ErrEnumExists     = engine.ErrEnumExists
 
+	ErrTableNotNew  = engine.ErrTableNotNew
+	ErrPkOutOfOrder = engine.ErrPkOutOfOrder
+
 	// loop breaker
 	EndStream = types.EndStream
 
@@ -65,3 +69,9 @@ func (t *errorTable) Count(_ context.Context, _ QueryRequest) (int, error)
 func (t *errorTable) Stream(_ context.Context, _ QueryRequest, _ func(QueryRow) error) error {
 	return t.err
 }
+func (t *errorTable) ImportCSV(_ context.Context, _ io.Reader, _ CSVOptions) (uint64, int, error) {
+	return 0, 0, t.err
+}
+func (t *errorTable) ImportParquet(_ context.Context, _ io.ReaderAt, _ int64) (uint64, int, error) {
+	return 0, 0, t.err
+}
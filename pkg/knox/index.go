@@ -4,6 +4,8 @@
 package knox
 
 import (
+	"context"
+
 	"blockwatch.cc/knoxdb/internal/engine"
 	"blockwatch.cc/knoxdb/pkg/schema"
 )
@@ -11,8 +13,9 @@ import (
 var _ Index = (*IndexImpl)(nil)
 
 type IndexImpl struct {
-	index engine.IndexEngine
-	db    Database
+	index  engine.IndexEngine
+	db     Database
+	engine *engine.Engine
 }
 
 func (t IndexImpl) DB() Database {
@@ -34,3 +37,33 @@ func (t IndexImpl) Metrics() IndexMetrics {
 func (t IndexImpl) Engine() engine.IndexEngine {
 	return t.index
 }
+
+// Status returns whether the index is ready for queries or still building.
+func (t IndexImpl) Status() IndexStatus {
+	return t.index.Status()
+}
+
+// Progress returns background build progress between 0 and 1.
+func (t IndexImpl) Progress() float64 {
+	if b, ok := t.engine.GetIndexBuild(t.index.IndexSchema().Name); ok {
+		return b.Progress()
+	}
+	if t.index.IsReady() {
+		return 1
+	}
+	return 0
+}
+
+// Cancel stops a running background build. The build resumes when
+// the database is opened next time.
+func (t IndexImpl) Cancel(ctx context.Context) error {
+	return t.engine.CancelIndexBuild(ctx, t.index.IndexSchema().Name)
+}
+
+// Wait blocks until a running background build completes.
+func (t IndexImpl) Wait(ctx context.Context) error {
+	if b, ok := t.engine.GetIndexBuild(t.index.IndexSchema().Name); ok {
+		return b.Wait(ctx)
+	}
+	return nil
+}
@@ -5,8 +5,10 @@ package knox
 
 import (
 	"context"
+	"io"
 
 	"blockwatch.cc/knoxdb/internal/engine"
+	"blockwatch.cc/knoxdb/internal/rollup"
 	"blockwatch.cc/knoxdb/internal/types"
 	"blockwatch.cc/knoxdb/pkg/schema"
 )
@@ -20,17 +22,24 @@ type (
 	IndexKind = engine.IndexKind
 	IndexType = types.IndexType
 
+	IndexStatus = engine.IndexStatus
+
 	TableMetrics = engine.TableMetrics
 	IndexMetrics = engine.IndexMetrics
 
 	QueryResult = engine.QueryResult
 	QueryRow    = engine.QueryRow
+
+	Rollup          = rollup.Rollup
+	RollupAggregate = rollup.Aggregate
+	RollupFunc      = rollup.Func
 )
 
 var (
 	WithNamespace       = engine.WithNamespace
 	WithPath            = engine.WithPath
 	WithCacheSize       = engine.WithCacheSize
+	WithDiskCache       = engine.WithDiskCache
 	WithWalSegmentSize  = engine.WithWalSegmentSize
 	WithWalRecoveryMode = engine.WithWalRecoveryMode
 	WithLockTimeout     = engine.WithLockTimeout
@@ -56,6 +65,13 @@ const (
 
 	IndexKindPack = engine.IndexKindPack
 	IndexKindLSM  = engine.IndexKindLSM
+
+	IndexStatusReady    = engine.IndexStatusReady
+	IndexStatusBuilding = engine.IndexStatusBuilding
+
+	RollupSum = rollup.FuncSum
+	RollupMin = rollup.FuncMin
+	RollupMax = rollup.FuncMax
 )
 
 const (
@@ -63,6 +79,8 @@ const (
 	IndexTypeHash      = types.IndexTypeHash
 	IndexTypeInt       = types.IndexTypeInt
 	IndexTypeComposite = types.IndexTypeComposite
+	IndexTypeToken     = types.IndexTypeToken
+	IndexTypeBitmap    = types.IndexTypeBitmap
 
 	FilterTypeBloom2b = types.FilterTypeBloom2b
 	FilterTypeBloom3b = types.FilterTypeBloom3b
@@ -95,6 +113,8 @@ type Table interface {
 	Count(context.Context, QueryRequest) (int, error)
 	Query(context.Context, QueryRequest) (QueryResult, error)
 	Stream(context.Context, QueryRequest, func(QueryRow) error) error
+	ImportCSV(context.Context, io.Reader, CSVOptions) (uint64, int, error)
+	ImportParquet(context.Context, io.ReaderAt, int64) (uint64, int, error)
 }
 
 type Index interface {
@@ -103,11 +123,17 @@ type Index interface {
 	IndexSchema() *schema.IndexSchema
 	Metrics() IndexMetrics
 	Engine() engine.IndexEngine
+	Status() IndexStatus
+	Progress() float64
+	Cancel(context.Context) error
+	Wait(context.Context) error
 }
 
 type Database interface {
 	// db global
 	Sync(ctx context.Context) error
+	Checkpoint(ctx context.Context) error
+	GC(ctx context.Context) error
 	Begin(ctx context.Context, flags ...TxFlags) (context.Context, func() error, func() error, error)
 	Close(ctx context.Context) error
 
@@ -120,6 +146,7 @@ type Database interface {
 	AlterTable(ctx context.Context, name string, s *schema.Schema) error
 	TruncateTable(ctx context.Context, name string) error
 	CompactTable(ctx context.Context, name string) error
+	FlushTable(ctx context.Context, name string) error
 
 	// indexes
 	ListIndexes(name string) []string
@@ -128,6 +155,11 @@ type Database interface {
 	RebuildIndex(ctx context.Context, name string) error
 	DropIndex(ctx context.Context, name string) error
 
+	// rollups
+	ListRollups(name string) []string
+	CreateRollup(ctx context.Context, r *Rollup, opts ...Option) error
+	DropRollup(ctx context.Context, name string) error
+
 	// enums
 	ListEnums() []string
 	FindEnum(name string) (*schema.EnumDictionary, error)
@@ -159,7 +159,7 @@ func (j Join) Execute(ctx context.Context, val any) error {
 	}
 
 	// use or open tx
-	ctx, _, abort, err := j.left.Table.DB().Begin(ctx)
+	ctx, _, abort, err := j.left.Table.DB().Begin(ctx, TxFlagReadOnly)
 	if err != nil {
 		return err
 	}
@@ -240,7 +240,7 @@ func (j Join) Stream(ctx context.Context, fn func(QueryRow) error) error {
 	}
 
 	// use or open tx
-	ctx, _, abort, err := j.left.Table.DB().Begin(ctx)
+	ctx, _, abort, err := j.left.Table.DB().Begin(ctx, TxFlagReadOnly)
 	if err != nil {
 		return err
 	}
@@ -261,7 +261,7 @@ func (j Join) Run(ctx context.Context) (QueryResult, error) {
 	}
 
 	// use or open tx
-	ctx, _, abort, err := j.left.Table.DB().Begin(ctx)
+	ctx, _, abort, err := j.left.Table.DB().Begin(ctx, TxFlagReadOnly)
 	if err != nil {
 		return nil, err
 	}
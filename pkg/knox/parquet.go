@@ -0,0 +1,142 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package knox
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"blockwatch.cc/knoxdb/internal/pack"
+	"blockwatch.cc/knoxdb/pkg/parquet"
+)
+
+// ParquetOptions controls Parquet export.
+type ParquetOptions struct {
+	Compression  parquet.Compression // column chunk compression, defaults to snappy
+	RowGroupSize int                 // max rows per row group, zero for one per pack
+}
+
+func DefaultParquetOptions() ParquetOptions {
+	return ParquetOptions{
+		Compression: parquet.CompressionSnappy,
+	}
+}
+
+// ImportParquet bulk loads a Parquet file into the table one row group at
+// a time. Columns are matched to table fields by name, table fields without
+// column are filled with zero values or NULL. Non-zero primary keys are
+// preserved and must be strictly ascending and larger than any existing key,
+// zero keys are assigned from the table sequence. Like ImportCSV only the
+// transaction which created the table may import and aborting it drops the
+// table with imported data. Returns the first primary key and the number of
+// imported records.
+func (t TableImpl) ImportParquet(ctx context.Context, r io.ReaderAt, size int64) (uint64, int, error) {
+	pr, err := parquet.NewReader(r, size)
+	if err != nil {
+		return 0, 0, err
+	}
+	var maxRows int
+	for i := range pr.NumRowGroups() {
+		maxRows = max(maxRows, pr.RowGroupLen(i))
+	}
+	if maxRows == 0 {
+		return 0, 0, nil
+	}
+	pkg := pack.New().
+		WithSchema(t.table.Schema()).
+		WithMaxRows(maxRows).
+		Alloc()
+	defer pkg.Release()
+
+	// use or open tx
+	ctx, commit, abort, err := t.db.Begin(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer abort()
+
+	var (
+		firstPk uint64
+		total   int
+	)
+	for i := range pr.NumRowGroups() {
+		pkg.Clear()
+		if _, err := pr.ReadRowGroup(i, pkg); err != nil {
+			return 0, total, fmt.Errorf("parquet import: row group %d: %v", i, err)
+		}
+		pk, n, err := t.table.ImportInto(ctx, pkg)
+		if err != nil {
+			return 0, total, err
+		}
+		if total == 0 {
+			firstPk = pk
+		}
+		total += n
+	}
+
+	if err := commit(); err != nil {
+		return 0, total, err
+	}
+
+	return firstPk, total, nil
+}
+
+// ExportParquet runs the query and streams its result to w as Parquet file.
+// Each table pack with matches is written as a separate row group which is
+// split further when it contains more than RowGroupSize rows.
+func (q Query) ExportParquet(ctx context.Context, w io.Writer, opts ParquetOptions) error {
+	plan, err := q.MakePlan()
+	if err != nil {
+		return fmt.Errorf("query %s: %v", q.tag, err)
+	}
+	defer plan.Close()
+
+	// use or open tx
+	ctx, commit, abort, err := q.table.DB().Begin(ctx, TxFlagReadOnly)
+	if err != nil {
+		return err
+	}
+	defer abort()
+
+	if err := plan.Compile(ctx); err != nil {
+		return fmt.Errorf("query %s: %v", q.tag, err)
+	}
+
+	pw, err := parquet.NewWriter(plan.Schema(), w)
+	if err != nil {
+		return err
+	}
+	pw.WithCompression(opts.Compression)
+
+	var win []uint32
+	err = q.table.Engine().StreamPacks(ctx, plan, func(pkg *pack.Package) error {
+		n := pkg.Len()
+		if opts.RowGroupSize <= 0 || n <= opts.RowGroupSize {
+			return pw.WritePack(pkg)
+		}
+
+		// split large packs into selection windows
+		defer pkg.WithSelection(nil)
+		for i := 0; i < n; i += opts.RowGroupSize {
+			win = win[:0]
+			for k := i; k < min(i+opts.RowGroupSize, n); k++ {
+				win = append(win, uint32(k))
+			}
+			pkg.WithSelection(win)
+			if err := pw.WritePack(pkg); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("query %s: %v", q.tag, err)
+	}
+	if err := pw.Close(); err != nil {
+		return err
+	}
+
+	return commit()
+}
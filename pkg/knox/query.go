@@ -24,6 +24,7 @@ type (
 	FilterMode = types.FilterMode
 	QueryFlags = query.QueryFlags
 	RangeValue = query.RangeValue
+	IndexExpr  = schema.IndexExpr
 )
 
 // condition builder functions
@@ -39,8 +40,20 @@ var (
 	Gt       = query.Gt       // func (col string, val any) Condition
 	Ge       = query.Ge       // func (col string, val any) Condition
 	Regexp   = query.Regexp   // func (col string, val any) Condition
+	Contains = query.Contains // func (col string, val any) Condition
+	Match    = query.Match    // func (col string, val any) Condition
+	IsNull   = query.IsNull   // func (col string) Condition
+	NotNull  = query.NotNull  // func (col string) Condition
 	Range    = query.Range    // func (col string, from, to any) Condition
 
+	// parse `field.mode` keys and string values into conditions
+	ParseCondition = query.ParseCondition // func (key, val string, s *schema.Schema) (Condition, error)
+
+	// key expressions for use with Condition.WithExpr and computed key indexes
+	Lower      = schema.Lower      // func () IndexExpr
+	Prefix     = schema.Prefix     // func (n int) IndexExpr
+	TimeBucket = schema.TimeBucket // func (d time.Duration) IndexExpr
+
 	ParseFilterMode = types.ParseFilterMode
 )
 
@@ -56,6 +69,13 @@ const (
 	FilterModeNotIn    = types.FilterModeNotIn
 	FilterModeRange    = types.FilterModeRange
 	FilterModeRegexp   = types.FilterModeRegexp
+	FilterModeContains = types.FilterModeContains
+	FilterModeMatch    = types.FilterModeMatch
+	FilterModeIsNull   = types.FilterModeIsNull
+	FilterModeNotNull  = types.FilterModeNotNull
+	FilterModeAny      = types.FilterModeAny
+	FilterModeAll      = types.FilterModeAll
+	FilterModePrefix   = types.FilterModePrefix
 )
 
 const (
@@ -273,6 +293,34 @@ func (q Query) AndRegexp(field string, value any) Query {
 	return q.And(field, FilterModeRegexp, value)
 }
 
+func (q Query) AndContains(field string, value any) Query {
+	return q.And(field, FilterModeContains, value)
+}
+
+func (q Query) AndMatch(field string, value any) Query {
+	return q.And(field, FilterModeMatch, value)
+}
+
+func (q Query) AndAny(field string, value any) Query {
+	return q.And(field, FilterModeAny, value)
+}
+
+func (q Query) AndAll(field string, value any) Query {
+	return q.And(field, FilterModeAll, value)
+}
+
+func (q Query) AndPrefix(field string, value any) Query {
+	return q.And(field, FilterModePrefix, value)
+}
+
+func (q Query) AndIsNull(field string) Query {
+	return q.And(field, FilterModeIsNull, nil)
+}
+
+func (q Query) AndNotNull(field string) Query {
+	return q.And(field, FilterModeNotNull, nil)
+}
+
 func (q Query) AndRange(field string, from, to any) Query {
 	return q.And(field, FilterModeRange, RangeValue{from, to})
 }
@@ -599,6 +647,34 @@ func (q GenericQuery[T]) AndRegexp(field string, value any) GenericQuery[T] {
 	return q.And(field, FilterModeRegexp, value)
 }
 
+func (q GenericQuery[T]) AndContains(field string, value any) GenericQuery[T] {
+	return q.And(field, FilterModeContains, value)
+}
+
+func (q GenericQuery[T]) AndMatch(field string, value any) GenericQuery[T] {
+	return q.And(field, FilterModeMatch, value)
+}
+
+func (q GenericQuery[T]) AndAny(field string, value any) GenericQuery[T] {
+	return q.And(field, FilterModeAny, value)
+}
+
+func (q GenericQuery[T]) AndAll(field string, value any) GenericQuery[T] {
+	return q.And(field, FilterModeAll, value)
+}
+
+func (q GenericQuery[T]) AndPrefix(field string, value any) GenericQuery[T] {
+	return q.And(field, FilterModePrefix, value)
+}
+
+func (q GenericQuery[T]) AndIsNull(field string) GenericQuery[T] {
+	return q.And(field, FilterModeIsNull, nil)
+}
+
+func (q GenericQuery[T]) AndNotNull(field string) GenericQuery[T] {
+	return q.And(field, FilterModeNotNull, nil)
+}
+
 func (q GenericQuery[T]) AndRange(field string, from, to any) GenericQuery[T] {
 	q.Query = q.Query.AndRange(field, from, to)
 	return q
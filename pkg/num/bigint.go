@@ -5,18 +5,30 @@ package num
 
 import (
 	"bytes"
+	"encoding/binary"
 	"math"
 	"math/big"
 	"math/bits"
 	"strings"
 )
 
-// A variable length sequence of bytes representing an unsigned Big integer
-// number with unlimited precision. Negative bigints are unsupported for
-// binary marshaling to enable native comparisons operators on encoded
-// binary data (data is stored as big-endian variable length byte slice).
+// A signed integer number with unlimited precision. The binary encoding
+// is order-preserving so that encoded values compare like numbers with
+// bytes.Compare (see Bytes).
 type Big big.Int
 
+// Binary encoding header bytes. Zero is a single header byte. Magnitudes
+// shorter than bigShortLen bytes store their length in the header, longer
+// magnitudes follow a 4 byte big-endian length. Negative values store the
+// complement of header length and magnitude so that larger magnitudes
+// sort first.
+const (
+	bigZero     = 0x80
+	bigPosLong  = 0xFF
+	bigNegLong  = 0x00
+	bigShortLen = 0x7F
+)
+
 var BigZero = NewBig(0)
 
 func NewBig(i int64) Big {
@@ -31,9 +43,11 @@ func NewFromBigInt(b *big.Int) Big {
 	return z
 }
 
+// NewBigFromBytes decodes a binary encoded bigint as produced by Bytes.
+// Invalid encodings decode as zero.
 func NewBigFromBytes(buf []byte) Big {
 	var z Big
-	(*big.Int)(&z).SetBytes(buf)
+	z.SetBytes(buf)
 	return z
 }
 
@@ -137,6 +151,18 @@ func BigCmp(a, b Big) int {
 	return a.Cmp(b)
 }
 
+// BigCmpBytes numerically compares two binary encoded bigints without
+// decoding them. Empty slices compare as zero.
+func BigCmpBytes(a, b []byte) int {
+	if len(a) == 0 {
+		a = []byte{bigZero}
+	}
+	if len(b) == 0 {
+		b = []byte{bigZero}
+	}
+	return bytes.Compare(a, b)
+}
+
 func (z Big) IsLess(b Big) bool {
 	return z.Cmp(b) < 0
 }
@@ -159,8 +185,12 @@ func (z *Big) SetInt64(i int64) *Big {
 	return z
 }
 
+// SetBytes sets z to the binary encoded bigint in buf. Invalid encodings
+// set z to zero.
 func (z *Big) SetBytes(buf []byte) *Big {
-	(*big.Int)(z).SetBytes(buf)
+	if z.UnmarshalBinary(buf) != nil {
+		(*big.Int)(z).SetInt64(0)
+	}
 	return z
 }
 
@@ -170,18 +200,85 @@ func (z Big) Clone() Big {
 	return x
 }
 
+// UnmarshalBinary decodes a binary encoded bigint as produced by Bytes.
+// An empty buffer decodes as zero.
 func (z *Big) UnmarshalBinary(buf []byte) error {
-	(*big.Int)(z).SetBytes(buf)
+	x := (*big.Int)(z)
+	if len(buf) == 0 {
+		x.SetInt64(0)
+		return nil
+	}
+	h, buf := buf[0], buf[1:]
+	neg := h < bigZero
+	if neg {
+		h = ^h
+	}
+	n := int(h - bigZero)
+	if h == bigPosLong {
+		if len(buf) < 4 {
+			return ErrInvalidBig
+		}
+		l := binary.BigEndian.Uint32(buf)
+		if neg {
+			l = ^l
+		}
+		n, buf = int(l), buf[4:]
+		if n < bigShortLen {
+			return ErrInvalidBig
+		}
+	}
+	switch {
+	case len(buf) != n, neg && n == 0:
+		return ErrInvalidBig
+	case n > 0 && !neg && buf[0] == 0, n > 0 && neg && buf[0] == 0xFF:
+		// leading zero magnitude bytes are not canonical
+		return ErrInvalidBig
+	}
+	if !neg {
+		x.SetBytes(buf)
+		return nil
+	}
+	mag := make([]byte, n)
+	for i, b := range buf {
+		mag[i] = ^b
+	}
+	x.SetBytes(mag).Neg(x)
 	return nil
 }
 
 func (z *Big) DecodeBuffer(buf *bytes.Buffer) error {
-	(*big.Int)(z).SetBytes(buf.Bytes())
-	return nil
+	return z.UnmarshalBinary(buf.Bytes())
 }
 
+// Bytes returns the order-preserving binary encoding of z. A header byte
+// holds sign and magnitude length followed by the big-endian magnitude.
+// Negative values store the complement of length and magnitude.
 func (z Big) Bytes() []byte {
-	return (*big.Int)(&z).Bytes()
+	return z.AppendBinary(nil)
+}
+
+// AppendBinary appends the binary encoding of z to buf.
+func (z Big) AppendBinary(buf []byte) []byte {
+	x := (*big.Int)(&z)
+	if x.Sign() == 0 {
+		return append(buf, bigZero)
+	}
+	mag := x.Bytes()
+	n := len(mag)
+	start := len(buf)
+	if n < bigShortLen {
+		buf = append(buf, bigZero+byte(n))
+	} else {
+		buf = append(buf, bigPosLong)
+		buf = binary.BigEndian.AppendUint32(buf, uint32(n))
+	}
+	buf = append(buf, mag...)
+	if x.Sign() < 0 {
+		for i := start; i < len(buf); i++ {
+			buf[i] = ^buf[i]
+		}
+	}
+	return buf
 }
 
 func (z Big) MarshalBinary() ([]byte, error) {
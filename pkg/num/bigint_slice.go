@@ -0,0 +1,131 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package num
+
+import (
+	"slices"
+	"sort"
+)
+
+func BigSort(s []Big) []Big {
+	slices.SortFunc(s, BigCmp)
+	return s
+}
+
+func BigMinMax(s []Big) (Big, Big) {
+	switch l := len(s); l {
+	case 0:
+		return BigZero, BigZero
+	case 1:
+		return s[0], s[0]
+	default:
+		return s[0], s[l-1]
+	}
+}
+
+func BigUnique(s []Big) []Big {
+	if len(s) == 0 {
+		return s
+	}
+	slices.SortFunc(s, BigCmp)
+	return slices.CompactFunc(s, Big.Equal)
+}
+
+func BigContains(s []Big, val Big) bool {
+	// s is sorted, use binary search to find value
+	_, ok := slices.BinarySearchFunc(s, val, BigCmp)
+	return ok
+}
+
+func BigIntersect(a, b []Big) []Big {
+	if a == nil || b == nil {
+		return nil
+	}
+	out := make([]Big, 0, min(len(a), len(b)))
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch c := a[i].Cmp(b[j]); {
+		case c < 0:
+			i++
+		case c > 0:
+			j++
+		default:
+			if l := len(out); l == 0 || !out[l-1].Equal(a[i]) {
+				out = append(out, a[i])
+			}
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+func BigUnion(a, b []Big) []Big {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	out := make([]Big, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch c := a[i].Cmp(b[j]); {
+		case c < 0:
+			out = append(out, a[i])
+			i++
+		case c > 0:
+			out = append(out, b[j])
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	out = append(out, a[i:]...)
+	return append(out, b[j:]...)
+}
+
+func BigDifference(a, b []Big) []Big {
+	if len(b) == 0 {
+		return a
+	}
+	if a == nil {
+		return nil
+	}
+	out := make([]Big, 0, len(a))
+	var k int
+	for _, v := range a {
+		for k < len(b) && b[k].Cmp(v) < 0 {
+			k++
+		}
+		if k < len(b) && b[k].Equal(v) {
+			continue
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+func BigRemoveRange(s []Big, from, to Big) []Big {
+	n := len(s)
+	start := sort.Search(n, func(i int) bool { return s[i].Cmp(from) >= 0 })
+	end := sort.Search(n, func(i int) bool { return s[i].Cmp(to) > 0 })
+	if start >= end {
+		return slices.Clone(s)
+	}
+	out := make([]Big, 0, n-end+start)
+	out = append(out, s[:start]...)
+	return append(out, s[end:]...)
+}
+
+func BigIntersectRange(s []Big, from, to Big) []Big {
+	n := len(s)
+	start := sort.Search(n, func(i int) bool { return s[i].Cmp(from) >= 0 })
+	end := sort.Search(n, func(i int) bool { return s[i].Cmp(to) > 0 })
+	if start >= end {
+		return []Big{}
+	}
+	return slices.Clone(s[start:end])
+}
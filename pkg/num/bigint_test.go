@@ -19,71 +19,61 @@ type BigintDecodeTest struct {
 var bigintDecodeCases = []BigintDecodeTest{
 	{
 		name: "l0",
-		buf:  []byte{},
+		buf:  []byte{0x80},
 		val:  "0",
 	},
 	{
 		name: "l1",
-		buf:  []byte{0x20},
+		buf:  []byte{0x81, 0x20},
 		val:  "32",
 	},
 	{
 		name: "l9",
-		buf:  []byte{0x20, 0x40, 0x81, 0x02, 0x04, 0x08, 0x10, 0x20},
+		buf:  []byte{0x88, 0x20, 0x40, 0x81, 0x02, 0x04, 0x08, 0x10, 0x20},
 		val:  "2323999253380730912",
 	},
 	{
 		name: "l10",
-		buf:  []byte{0x10, 0x20, 0x40, 0x81, 0x02, 0x04, 0x08, 0x10, 0x20},
+		buf:  []byte{0x89, 0x10, 0x20, 0x40, 0x81, 0x02, 0x04, 0x08, 0x10, 0x20},
 		val:  "297471904432733556768",
 	},
 	{
 		name: "l18",
-		buf:  []byte{0x10, 0x20, 0x40, 0x81, 0x02, 0x04, 0x08, 0x10, 0x20, 0x40, 0x81, 0x02, 0x04, 0x08, 0x10, 0x20},
+		buf:  []byte{0x90, 0x10, 0x20, 0x40, 0x81, 0x02, 0x04, 0x08, 0x10, 0x20, 0x40, 0x81, 0x02, 0x04, 0x08, 0x10, 0x20},
 		val:  "21435109727303210296905487082316107808",
 	},
 	{
 		name: "l19",
-		buf:  []byte{0x08, 0x10, 0x20, 0x40, 0x81, 0x02, 0x04, 0x08, 0x10, 0x20, 0x40, 0x81, 0x02, 0x04, 0x08, 0x10, 0x20},
+		buf:  []byte{0x91, 0x08, 0x10, 0x20, 0x40, 0x81, 0x02, 0x04, 0x08, 0x10, 0x20, 0x40, 0x81, 0x02, 0x04, 0x08, 0x10, 0x20},
 		val:  "2743694045094810918003902346536461799456",
 	},
 
-	// negative bigints are not supported
-	// {
-	// 	name: "n1",
-	// 	buf:  []byte{0x20},
-	//
-	// 	sign: 1,
-	// 	val:  "-32",
-	// },
-	// {
-	// 	name: "n9",
-	// 	buf:  []byte{0xe0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0x40},
-	//
-	// 	sign: 1,
-	// 	val:  "-2323999253380730912",
-	// },
-	// {
-	// 	name: "n10",
-	// 	buf:  []byte{0xe0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0x40},
-	//
-	// 	sign: 1,
-	// 	val:  "-297471904432733556768",
-	// },
-	// {
-	// 	name: "n18",
-	// 	buf:  []byte{0xe0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0x40},
-	//
-	// 	sign: 1,
-	// 	val:  "-21435109727303210296905487082316107808",
-	// },
-	// {
-	// 	name: "n19",
-	// 	buf:  []byte{0xe0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0xc0, 0x40},
-	//
-	// 	sign: 1,
-	// 	val:  "-2743694045094810918003902346536461799456",
-	// },
+	// negative values store the complement of header and magnitude
+	{
+		name: "n1",
+		buf:  []byte{0x7e, 0xdf},
+		val:  "-32",
+	},
+	{
+		name: "n9",
+		buf:  []byte{0x77, 0xdf, 0xbf, 0x7e, 0xfd, 0xfb, 0xf7, 0xef, 0xdf},
+		val:  "-2323999253380730912",
+	},
+	{
+		name: "n10",
+		buf:  []byte{0x76, 0xef, 0xdf, 0xbf, 0x7e, 0xfd, 0xfb, 0xf7, 0xef, 0xdf},
+		val:  "-297471904432733556768",
+	},
+	{
+		name: "n18",
+		buf:  []byte{0x6f, 0xef, 0xdf, 0xbf, 0x7e, 0xfd, 0xfb, 0xf7, 0xef, 0xdf, 0xbf, 0x7e, 0xfd, 0xfb, 0xf7, 0xef, 0xdf},
+		val:  "-21435109727303210296905487082316107808",
+	},
+	{
+		name: "n19",
+		buf:  []byte{0x6e, 0xf7, 0xef, 0xdf, 0xbf, 0x7e, 0xfd, 0xfb, 0xf7, 0xef, 0xdf, 0xbf, 0x7e, 0xfd, 0xfb, 0xf7, 0xef, 0xdf},
+		val:  "-2743694045094810918003902346536461799456",
+	},
 }
 
 func TestBigCompare(t *testing.T) {
@@ -91,6 +81,9 @@ func TestBigCompare(t *testing.T) {
 		{1 << 8, 1<<8 + 1},
 		{1 << 16, 1<<16 + 1},
 		{1 << 32, 1<<32 + 1},
+		{0xff, 1 << 8},
+		{0xffff, 1 << 24},
+		{1 << 40, 1 << 8},
 	} {
 		var x, y Big
 		x.SetInt64(v[0])
@@ -99,10 +92,13 @@ func TestBigCompare(t *testing.T) {
 			t.Errorf("%d ? %d: unexpected cmp order", v[0], v[1])
 		}
 
-		cmp := bytes.Compare(x.Bytes(), y.Bytes())
+		cmp := BigCmpBytes(x.Bytes(), y.Bytes())
 		if got, want := cmp < 0, v[0] < v[1]; got != want {
 			t.Errorf("%d ? %d: unexpected bytes cmp order", v[0], v[1])
 		}
+		if got, want := BigCmpBytes(x.Neg().Bytes(), y.Neg().Bytes()) > 0, v[0] < v[1]; got != want {
+			t.Errorf("-%d ? -%d: unexpected bytes cmp order", v[0], v[1])
+		}
 	}
 }
 
@@ -167,6 +163,93 @@ func TestBigBinaryMarshal(t *testing.T) {
 	}
 }
 
+func TestBigBinaryOrder(t *testing.T) {
+	// values across signs, short and long form magnitudes
+	long := NewBig(1).Lsh(8 * 200)
+	vals := []Big{
+		long.Lsh(8).Neg(),
+		long.Neg(),
+		long.Sub64(1).Neg(),
+		NewBig(1).Lsh(8 * 126).Neg(),
+		NewBig(1).Lsh(8 * 126).Sub64(1).Neg(),
+		NewBig(1 << 40).Neg(),
+		NewBig(256).Neg(),
+		NewBig(255).Neg(),
+		NewBig(1).Neg(),
+		BigZero,
+		NewBig(1),
+		NewBig(255),
+		NewBig(256),
+		NewBig(1 << 40),
+		NewBig(1).Lsh(8 * 126).Sub64(1),
+		NewBig(1).Lsh(8 * 126),
+		long.Sub64(1),
+		long,
+		long.Lsh(8),
+	}
+	for i, x := range vals {
+		buf := x.Bytes()
+		var z Big
+		if err := z.UnmarshalBinary(buf); err != nil {
+			t.Fatalf("%s: unexpected binary unmarshal error %v", x, err)
+		}
+		if z.Cmp(x) != 0 {
+			t.Errorf("%s: unexpected roundtrip value %s", x, z)
+		}
+		if got := NewBigFromBytes(buf); got.Cmp(x) != 0 {
+			t.Errorf("%s: unexpected decoded value %s", x, got)
+		}
+		for j, y := range vals {
+			want := 0
+			switch {
+			case i < j:
+				want = -1
+			case i > j:
+				want = 1
+			}
+			if got := bytes.Compare(buf, y.Bytes()); got != want {
+				t.Errorf("%s ? %s: unexpected bytes order %d, expected %d", x, y, got, want)
+			}
+			if got := BigCmpBytes(buf, y.Bytes()); got != want {
+				t.Errorf("%s ? %s: unexpected bytes cmp %d, expected %d", x, y, got, want)
+			}
+		}
+	}
+
+	// empty slices compare as zero
+	if got, want := BigCmpBytes(nil, BigZero.Bytes()), 0; got != want {
+		t.Errorf("unexpected empty cmp %d", got)
+	}
+	if got, want := BigCmpBytes(nil, NewBig(-1).Bytes()), 1; got != want {
+		t.Errorf("unexpected empty cmp %d with negative", got)
+	}
+
+	// appending keeps existing data
+	if got, want := NewBig(-32).AppendBinary([]byte{1}), []byte{1, 0x7e, 0xdf}; !bytes.Equal(got, want) {
+		t.Errorf("unexpected append result %v, expected %v", got, want)
+	}
+}
+
+func TestBigBinaryInvalid(t *testing.T) {
+	for _, buf := range [][]byte{
+		{0x81},                               // short magnitude
+		{0x81, 0x20, 0x00},                   // long magnitude
+		{0x82, 0x00, 0x20},                   // leading zero
+		{0x7d, 0xff, 0xdf},                   // negative leading zero
+		{0x7f},                               // negative zero
+		{0xff, 0x00, 0x00},                   // short long-form length
+		{0xff, 0x00, 0x00, 0x00, 0x01, 0x20}, // long form for short magnitude
+	} {
+		var z Big
+		if got, want := z.UnmarshalBinary(buf), ErrInvalidBig; got != want {
+			t.Errorf("%x: unexpected error %v, expected %v", buf, got, want)
+		}
+		if got := NewBigFromBytes(buf); !got.IsBigZero() {
+			t.Errorf("%x: unexpected decoded value %s", buf, got)
+		}
+	}
+}
+
 type benchmarkSize struct {
 	name string
 	l    int
@@ -207,3 +290,35 @@ func BenchmarkBigUnmarshalBinary(b *testing.B) {
 		})
 	}
 }
+
+func TestBigSlice(t *testing.T) {
+	mk := func(v ...int64) []Big {
+		s := make([]Big, len(v))
+		for i := range v {
+			s[i] = NewBig(v[i])
+		}
+		return s
+	}
+	eq := func(name string, got, want []Big) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("%s: unexpected len %d, expected %d", name, len(got), len(want))
+		}
+		for i := range got {
+			if !got[i].Equal(want[i]) {
+				t.Errorf("%s[%d]: unexpected value %s, expected %s", name, i, got[i], want[i])
+			}
+		}
+	}
+	a := BigUnique(mk(256, 1, 255, 256, 1<<40, 1))
+	eq("unique", a, mk(1, 255, 256, 1<<40))
+	b := mk(0, 255, 1<<40, 1<<41)
+	eq("intersect", BigIntersect(a, b), mk(255, 1<<40))
+	eq("union", BigUnion(a, b), mk(0, 1, 255, 256, 1<<40, 1<<41))
+	eq("difference", BigDifference(a, b), mk(1, 256))
+	eq("remove_range", BigRemoveRange(a, NewBig(2), NewBig(256)), mk(1, 1<<40))
+	eq("intersect_range", BigIntersectRange(a, NewBig(2), NewBig(256)), mk(255, 256))
+	if !BigContains(a, NewBig(256)) || BigContains(a, NewBig(2)) {
+		t.Errorf("unexpected contains result")
+	}
+}
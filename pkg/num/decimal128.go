@@ -128,6 +128,22 @@ func (d Decimal128) Quantize(scale uint8) Decimal128 {
 	return d
 }
 
+// Rescale converts d to scale using rounding mode. Unlike Quantize it fails
+// when the result does not fit or when mode is RoundExact and digits are lost.
+func (d Decimal128) Rescale(scale uint8, mode RoundingMode) (Decimal128, error) {
+	if scale > MaxDecimal128Precision {
+		return d, ErrScaleOverflow
+	}
+	v, err := rescale256(d.Int256(), d.scale, scale, mode)
+	if err != nil {
+		return d, err
+	}
+	if !v.IsInt128() {
+		return d, ErrPrecisionOverflow
+	}
+	return Decimal128{v.Int128(), scale}, nil
+}
+
 func (d Decimal128) Int64() int64 {
 	return d.val.Int64()
 }
@@ -365,6 +381,8 @@ func ParseDecimal128(s string) (Decimal128, error) {
 	return dec, err
 }
 
+// EqualScaleDecimal128 quantizes the operand with higher scale to the lower
+// scale which may round. Use Cmp for exact comparison.
 func EqualScaleDecimal128(a, b Decimal128) (Decimal128, Decimal128) {
 	switch {
 	case a.scale == b.scale:
@@ -377,33 +395,32 @@ func EqualScaleDecimal128(a, b Decimal128) (Decimal128, Decimal128) {
 }
 
 func (a Decimal128) Eq(b Decimal128) bool {
-	x, y := EqualScaleDecimal128(a, b)
-	return x.val.Eq(y.val)
+	return a.Cmp(b) == 0
 }
 
 func (a Decimal128) Gt(b Decimal128) bool {
-	x, y := EqualScaleDecimal128(a, b)
-	return x.val.Gt(y.val)
+	return a.Cmp(b) > 0
 }
 
 func (a Decimal128) Ge(b Decimal128) bool {
-	x, y := EqualScaleDecimal128(a, b)
-	return x.val.Ge(y.val)
+	return a.Cmp(b) >= 0
 }
 
 func (a Decimal128) Lt(b Decimal128) bool {
-	x, y := EqualScaleDecimal128(a, b)
-	return x.val.Lt(y.val)
+	return a.Cmp(b) < 0
 }
 
 func (a Decimal128) Le(b Decimal128) bool {
-	x, y := EqualScaleDecimal128(a, b)
-	return x.val.Le(y.val)
+	return a.Cmp(b) <= 0
 }
 
+// Cmp compares decimals exactly, values with different scale are compared
+// at the higher scale.
 func (a Decimal128) Cmp(b Decimal128) int {
-	x, y := EqualScaleDecimal128(a, b)
-	return x.val.Cmp(y.val)
+	if a.scale == b.scale {
+		return a.val.Cmp(b.val)
+	}
+	return cmpDecimal(a.Int256(), a.scale, b.Int256(), b.scale)
 }
 
 func CompareDecimal128(a, b Decimal128) int {
@@ -144,6 +144,16 @@ func (d Decimal256) Quantize(scale uint8) Decimal256 {
 	return d
 }
 
+// Rescale converts d to scale using rounding mode. Unlike Quantize it fails
+// when the result does not fit or when mode is RoundExact and digits are lost.
+func (d Decimal256) Rescale(scale uint8, mode RoundingMode) (Decimal256, error) {
+	v, err := rescale256(d.val, d.scale, scale, mode)
+	if err != nil {
+		return d, err
+	}
+	return Decimal256{v, scale}, nil
+}
+
 func (d Decimal256) Int64() int64 {
 	return d.val.Int64()
 }
@@ -392,6 +402,8 @@ func ParseDecimal256(s string) (Decimal256, error) {
 	return dec, err
 }
 
+// EqualScaleDecimal256 quantizes the operand with higher scale to the lower
+// scale which may round. Use Cmp for exact comparison.
 func EqualScaleDecimal256(a, b Decimal256) (Decimal256, Decimal256) {
 	switch {
 	case a.scale == b.scale:
@@ -404,33 +416,32 @@ func EqualScaleDecimal256(a, b Decimal256) (Decimal256, Decimal256) {
 }
 
 func (a Decimal256) Eq(b Decimal256) bool {
-	x, y := EqualScaleDecimal256(a, b)
-	return x.val.Eq(y.val)
+	return a.Cmp(b) == 0
 }
 
 func (a Decimal256) Gt(b Decimal256) bool {
-	x, y := EqualScaleDecimal256(a, b)
-	return x.val.Gt(y.val)
+	return a.Cmp(b) > 0
 }
 
 func (a Decimal256) Ge(b Decimal256) bool {
-	x, y := EqualScaleDecimal256(a, b)
-	return x.val.Ge(y.val)
+	return a.Cmp(b) >= 0
 }
 
 func (a Decimal256) Lt(b Decimal256) bool {
-	x, y := EqualScaleDecimal256(a, b)
-	return x.val.Lt(y.val)
+	return a.Cmp(b) < 0
 }
 
 func (a Decimal256) Le(b Decimal256) bool {
-	x, y := EqualScaleDecimal256(a, b)
-	return x.val.Le(y.val)
+	return a.Cmp(b) <= 0
 }
 
+// Cmp compares decimals exactly, values with different scale are compared
+// at the higher scale.
 func (a Decimal256) Cmp(b Decimal256) int {
-	x, y := EqualScaleDecimal256(a, b)
-	return x.val.Cmp(y.val)
+	if a.scale == b.scale {
+		return a.val.Cmp(b.val)
+	}
+	return cmpDecimal(a.Int256(), a.scale, b.Int256(), b.scale)
 }
 
 func CompareDecimal256(a, b Decimal256) int {
@@ -89,6 +89,22 @@ func (d Decimal32) Quantize(scale uint8) Decimal32 {
 	return d
 }
 
+// Rescale converts d to scale using rounding mode. Unlike Quantize it fails
+// when the result does not fit or when mode is RoundExact and digits are lost.
+func (d Decimal32) Rescale(scale uint8, mode RoundingMode) (Decimal32, error) {
+	if scale > MaxDecimal32Precision {
+		return d, ErrScaleOverflow
+	}
+	v, err := rescale256(d.Int256(), d.scale, scale, mode)
+	if err != nil {
+		return d, err
+	}
+	if !v.IsInt64() || int64(int32(v.Int64())) != v.Int64() {
+		return d, ErrPrecisionOverflow
+	}
+	return Decimal32{int32(v.Int64()), scale}, nil
+}
+
 func (d Decimal32) Int32() int32 {
 	return d.val
 }
@@ -313,6 +329,8 @@ func ParseDecimal32(s string) (Decimal32, error) {
 	return dec, err
 }
 
+// EqualScaleDecimal32 quantizes the operand with higher scale to the lower
+// scale which may round. Use Cmp for exact comparison.
 func EqualScaleDecimal32(a, b Decimal32) (Decimal32, Decimal32) {
 	switch {
 	case a.scale == b.scale:
@@ -325,39 +343,39 @@ func EqualScaleDecimal32(a, b Decimal32) (Decimal32, Decimal32) {
 }
 
 func (a Decimal32) Eq(b Decimal32) bool {
-	x, y := EqualScaleDecimal32(a, b)
-	return x.val == y.val
+	return a.Cmp(b) == 0
 }
 
 func (a Decimal32) Gt(b Decimal32) bool {
-	x, y := EqualScaleDecimal32(a, b)
-	return x.val > y.val
+	return a.Cmp(b) > 0
 }
 
 func (a Decimal32) Ge(b Decimal32) bool {
-	x, y := EqualScaleDecimal32(a, b)
-	return x.val >= y.val
+	return a.Cmp(b) >= 0
 }
 
 func (a Decimal32) Lt(b Decimal32) bool {
-	x, y := EqualScaleDecimal32(a, b)
-	return x.val < y.val
+	return a.Cmp(b) < 0
 }
 
 func (a Decimal32) Le(b Decimal32) bool {
-	x, y := EqualScaleDecimal32(a, b)
-	return x.val <= y.val
+	return a.Cmp(b) <= 0
 }
 
+// Cmp compares decimals exactly, values with different scale are compared
+// at the higher scale.
 func (a Decimal32) Cmp(b Decimal32) int {
-	switch {
-	case a.Lt(b):
-		return -1
-	case a.Eq(b):
-		return 0
-	default:
-		return 1
+	if a.scale == b.scale {
+		switch {
+		case a.val < b.val:
+			return -1
+		case a.val > b.val:
+			return 1
+		default:
+			return 0
+		}
 	}
+	return cmpDecimal(a.Int256(), a.scale, b.Int256(), b.scale)
 }
 
 func CompareDecimal32(a, b Decimal32) int {
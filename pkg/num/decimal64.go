@@ -89,6 +89,22 @@ func (d Decimal64) Quantize(scale uint8) Decimal64 {
 	return d
 }
 
+// Rescale converts d to scale using rounding mode. Unlike Quantize it fails
+// when the result does not fit or when mode is RoundExact and digits are lost.
+func (d Decimal64) Rescale(scale uint8, mode RoundingMode) (Decimal64, error) {
+	if scale > MaxDecimal64Precision {
+		return d, ErrScaleOverflow
+	}
+	v, err := rescale256(d.Int256(), d.scale, scale, mode)
+	if err != nil {
+		return d, err
+	}
+	if !v.IsInt64() {
+		return d, ErrPrecisionOverflow
+	}
+	return Decimal64{v.Int64(), scale}, nil
+}
+
 func (d Decimal64) Int64() int64 {
 	return d.val
 }
@@ -302,6 +318,8 @@ func ParseDecimal64(s string) (Decimal64, error) {
 	return dec, err
 }
 
+// EqualScaleDecimal64 quantizes the operand with higher scale to the lower
+// scale which may round. Use Cmp for exact comparison.
 func EqualScaleDecimal64(a, b Decimal64) (Decimal64, Decimal64) {
 	switch {
 	case a.scale == b.scale:
@@ -314,39 +332,39 @@ func EqualScaleDecimal64(a, b Decimal64) (Decimal64, Decimal64) {
 }
 
 func (a Decimal64) Eq(b Decimal64) bool {
-	x, y := EqualScaleDecimal64(a, b)
-	return x.val == y.val
+	return a.Cmp(b) == 0
 }
 
 func (a Decimal64) Gt(b Decimal64) bool {
-	x, y := EqualScaleDecimal64(a, b)
-	return x.val > y.val
+	return a.Cmp(b) > 0
 }
 
 func (a Decimal64) Ge(b Decimal64) bool {
-	x, y := EqualScaleDecimal64(a, b)
-	return x.val >= y.val
+	return a.Cmp(b) >= 0
 }
 
 func (a Decimal64) Lt(b Decimal64) bool {
-	x, y := EqualScaleDecimal64(a, b)
-	return x.val < y.val
+	return a.Cmp(b) < 0
 }
 
 func (a Decimal64) Le(b Decimal64) bool {
-	x, y := EqualScaleDecimal64(a, b)
-	return x.val <= y.val
+	return a.Cmp(b) <= 0
 }
 
+// Cmp compares decimals exactly, values with different scale are compared
+// at the higher scale.
 func (a Decimal64) Cmp(b Decimal64) int {
-	switch {
-	case a.Lt(b):
-		return -1
-	case a.Eq(b):
-		return 0
-	default:
-		return 1
+	if a.scale == b.scale {
+		switch {
+		case a.val < b.val:
+			return -1
+		case a.val > b.val:
+			return 1
+		default:
+			return 0
+		}
 	}
+	return cmpDecimal(a.Int256(), a.scale, b.Int256(), b.scale)
 }
 
 func CompareDecimal64(a, b Decimal64) int {
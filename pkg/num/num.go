@@ -15,6 +15,7 @@ var (
 	ErrInvalidFloat64     = errors.New("num: invalid float64 number")
 	ErrInvalidDecimal     = errors.New("num: invalid decimal number")
 	ErrInvalidNumber      = errors.New("num: invalid number")
+	ErrInvalidBig         = errors.New("num: invalid bigint encoding")
 )
 
 type Accuracy int8
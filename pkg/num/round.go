@@ -0,0 +1,185 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package num
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// RoundingMode defines how decimals are rounded when their scale is reduced.
+type RoundingMode byte
+
+const (
+	RoundHalfEven RoundingMode = iota // nearest, ties to even (IEEE 754 default)
+	RoundHalfUp                       // nearest, ties away from zero
+	RoundDown                         // towards zero (truncate)
+	RoundUp                           // away from zero
+	RoundFloor                        // towards negative infinity
+	RoundCeil                         // towards positive infinity
+	RoundExact                        // fail when rounding is required
+)
+
+var roundingModeNames = [...]string{
+	RoundHalfEven: "half_even",
+	RoundHalfUp:   "half_up",
+	RoundDown:     "down",
+	RoundUp:       "up",
+	RoundFloor:    "floor",
+	RoundCeil:     "ceil",
+	RoundExact:    "exact",
+}
+
+func (m RoundingMode) IsValid() bool {
+	return m <= RoundExact
+}
+
+func (m RoundingMode) String() string {
+	if !m.IsValid() {
+		return "invalid"
+	}
+	return roundingModeNames[m]
+}
+
+func ParseRoundingMode(s string) (RoundingMode, error) {
+	for i, n := range roundingModeNames {
+		if n == s {
+			return RoundingMode(i), nil
+		}
+	}
+	return 0, fmt.Errorf("num: invalid rounding mode %q", s)
+}
+
+// pow10Int256 returns 10^n for n <= MaxDecimal256Precision.
+func pow10Int256(n int) Int256 {
+	l := len(pow10) - 2
+	y := Int256{0, 0, 0, pow10[n%l]}
+	for i := n / l; i > 0; i-- {
+		y = y.Mul(Int256{0, 0, 0, pow10[l]})
+	}
+	return y
+}
+
+// quoRound divides x by positive y and rounds the quotient using mode.
+// Returns ErrPrecisionUnderflow for inexact results in RoundExact mode.
+func quoRound(x, y Int256, mode RoundingMode) (Int256, error) {
+	neg := x.Sign() < 0
+	q, r := x.Abs().QuoRem(y)
+	if !r.IsZero() {
+		var up bool
+		switch mode {
+		case RoundHalfEven:
+			c := r.Add(r).Cmp(y)
+			up = c > 0 || c == 0 && q[3]&1 == 1
+		case RoundHalfUp:
+			up = r.Add(r).Cmp(y) >= 0
+		case RoundUp:
+			up = true
+		case RoundFloor:
+			up = neg
+		case RoundCeil:
+			up = !neg
+		case RoundExact:
+			return ZeroInt256, ErrPrecisionUnderflow
+		}
+		if up {
+			q = q.Add64(1)
+		}
+	}
+	if neg {
+		q = q.Neg()
+	}
+	return q, nil
+}
+
+// rescale256 converts unscaled value v from scale `from` to scale `to`.
+// Increasing scale is exact or fails with ErrPrecisionOverflow, reducing
+// scale rounds according to mode.
+func rescale256(v Int256, from, to uint8, mode RoundingMode) (Int256, error) {
+	switch {
+	case to > MaxDecimal256Precision:
+		return v, ErrScaleOverflow
+	case from == to || v.IsZero():
+		return v, nil
+	case to > from:
+		diff := int(to - from)
+		if int(NewDecimal256(v, 0).Precision())+diff > MaxDecimal256Precision {
+			return v, ErrPrecisionOverflow
+		}
+		return v.Mul(pow10Int256(diff)), nil
+	default:
+		return quoRound(v, pow10Int256(int(from-to)), mode)
+	}
+}
+
+// Quo64 divides d by n and rounds the quotient to scale using mode. The
+// quotient is rounded once so that means and ratios do not accumulate
+// rounding errors.
+func (d Decimal256) Quo64(n int64, scale uint8, mode RoundingMode) (Decimal256, error) {
+	if n == 0 {
+		return d, ErrInvalidNumber
+	}
+	x, y := d.val, Int256FromInt64(n)
+	if n < 0 {
+		x, y = x.Neg(), y.Neg()
+	}
+	if scale >= d.scale {
+		v, err := rescale256(x, d.scale, scale, RoundExact)
+		if err != nil {
+			return d, err
+		}
+		x = v
+	} else {
+		diff := int(d.scale - scale)
+		if int(NewDecimal256(y, 0).Precision())+diff > MaxDecimal256Precision {
+			return d, ErrPrecisionOverflow
+		}
+		y = y.Mul(pow10Int256(diff))
+	}
+	q, err := quoRound(x, y, mode)
+	if err != nil {
+		return d, err
+	}
+	return Decimal256{q, scale}, nil
+}
+
+// cmpDecimal compares two unscaled decimal values with possibly different
+// scales without loss of precision.
+func cmpDecimal(x Int256, xs uint8, y Int256, ys uint8) int {
+	if xs == ys {
+		return x.Cmp(y)
+	}
+	if sx, sy := x.Sign(), y.Sign(); sx != sy {
+		return cmpInt(sx, sy)
+	}
+	if xs < ys {
+		if v, err := rescale256(x, xs, ys, RoundExact); err == nil {
+			return v.Cmp(y)
+		}
+	} else {
+		if v, err := rescale256(y, ys, xs, RoundExact); err == nil {
+			return x.Cmp(v)
+		}
+	}
+
+	// values exceed 256 bit at common scale
+	bx, by := x.AsBigInt().Big(), y.AsBigInt().Big()
+	if xs < ys {
+		bx.Mul(bx, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(ys-xs)), nil))
+	} else {
+		by.Mul(by, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(xs-ys)), nil))
+	}
+	return bx.Cmp(by)
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package num
+
+import (
+	"testing"
+)
+
+func TestDecimalRescale(t *testing.T) {
+	var tests = []struct {
+		in    string
+		scale uint8
+		mode  RoundingMode
+		out   string
+		err   error
+	}{
+		// scale up is exact
+		{in: "1.5", scale: 3, mode: RoundExact, out: "1.500"},
+		{in: "-1.5", scale: 3, mode: RoundExact, out: "-1.500"},
+		// ties
+		{in: "2.5", scale: 0, mode: RoundHalfEven, out: "2"},
+		{in: "3.5", scale: 0, mode: RoundHalfEven, out: "4"},
+		{in: "-2.5", scale: 0, mode: RoundHalfEven, out: "-2"},
+		{in: "2.5", scale: 0, mode: RoundHalfUp, out: "3"},
+		{in: "-2.5", scale: 0, mode: RoundHalfUp, out: "-3"},
+		{in: "2.49", scale: 0, mode: RoundHalfUp, out: "2"},
+		// directed
+		{in: "1.01", scale: 1, mode: RoundDown, out: "1.0"},
+		{in: "-1.01", scale: 1, mode: RoundDown, out: "-1.0"},
+		{in: "1.01", scale: 1, mode: RoundUp, out: "1.1"},
+		{in: "-1.01", scale: 1, mode: RoundUp, out: "-1.1"},
+		{in: "1.09", scale: 1, mode: RoundFloor, out: "1.0"},
+		{in: "-1.01", scale: 1, mode: RoundFloor, out: "-1.1"},
+		{in: "1.01", scale: 1, mode: RoundCeil, out: "1.1"},
+		{in: "-1.09", scale: 1, mode: RoundCeil, out: "-1.0"},
+		// exact
+		{in: "1.10", scale: 1, mode: RoundExact, out: "1.1"},
+		{in: "1.05", scale: 1, mode: RoundExact, err: ErrPrecisionUnderflow},
+		// overflow
+		{in: "99999999.9", scale: 2, mode: RoundExact, err: ErrPrecisionOverflow},
+	}
+	for _, test := range tests {
+		d32, err := MustParseDecimal32(test.in).Rescale(test.scale, test.mode)
+		if err != test.err {
+			t.Errorf("d32 %s/%d/%s: unexpected error %v", test.in, test.scale, test.mode, err)
+		} else if err == nil && d32.String() != test.out {
+			t.Errorf("d32 %s/%d/%s: exp %s, got %s", test.in, test.scale, test.mode, test.out, d32)
+		}
+		if test.err == ErrPrecisionOverflow {
+			continue
+		}
+		d64, err := MustParseDecimal64(test.in).Rescale(test.scale, test.mode)
+		if err != test.err {
+			t.Errorf("d64 %s/%d/%s: unexpected error %v", test.in, test.scale, test.mode, err)
+		} else if err == nil && d64.String() != test.out {
+			t.Errorf("d64 %s/%d/%s: exp %s, got %s", test.in, test.scale, test.mode, test.out, d64)
+		}
+		d128, err := MustParseDecimal128(test.in).Rescale(test.scale, test.mode)
+		if err != test.err {
+			t.Errorf("d128 %s/%d/%s: unexpected error %v", test.in, test.scale, test.mode, err)
+		} else if err == nil && d128.String() != test.out {
+			t.Errorf("d128 %s/%d/%s: exp %s, got %s", test.in, test.scale, test.mode, test.out, d128)
+		}
+		d256, err := MustParseDecimal256(test.in).Rescale(test.scale, test.mode)
+		if err != test.err {
+			t.Errorf("d256 %s/%d/%s: unexpected error %v", test.in, test.scale, test.mode, err)
+		} else if err == nil && d256.String() != test.out {
+			t.Errorf("d256 %s/%d/%s: exp %s, got %s", test.in, test.scale, test.mode, test.out, d256)
+		}
+	}
+}
+
+func TestDecimalCompareExact(t *testing.T) {
+	// values that differ below the lower scale must not compare equal
+	if MustParseDecimal64("1.05").Eq(MustParseDecimal64("1.0")) {
+		t.Errorf("d64 1.05 == 1.0")
+	}
+	if !MustParseDecimal64("1.05").Gt(MustParseDecimal64("1.0")) {
+		t.Errorf("d64 1.05 <= 1.0")
+	}
+	if MustParseDecimal32("-0.15").Cmp(MustParseDecimal32("-0.2")) != 1 {
+		t.Errorf("d32 -0.15 <= -0.2")
+	}
+	if MustParseDecimal128("0.00000000000000000001").Cmp(MustParseDecimal128("0.0")) != 1 {
+		t.Errorf("d128 1e-20 <= 0")
+	}
+
+	// common scale exceeds 256 bits
+	a := NewDecimal256(MaxInt256, 0)
+	b := NewDecimal256(MaxInt256, 76)
+	if a.Cmp(b) != 1 || b.Cmp(a) != -1 {
+		t.Errorf("d256 big compare failed")
+	}
+	if NewDecimal256(MaxInt256.Neg(), 0).Cmp(b) != -1 {
+		t.Errorf("d256 big negative compare failed")
+	}
+}
+
+func TestDecimalQuo64(t *testing.T) {
+	var tests = []struct {
+		in    string
+		n     int64
+		scale uint8
+		mode  RoundingMode
+		out   string
+	}{
+		{in: "10.00", n: 4, scale: 2, mode: RoundHalfEven, out: "2.50"},
+		{in: "10.00", n: 4, scale: 1, mode: RoundHalfEven, out: "2.5"},
+		{in: "10", n: 4, scale: 0, mode: RoundHalfEven, out: "2"},
+		{in: "10", n: 4, scale: 0, mode: RoundHalfUp, out: "3"},
+		{in: "1", n: 3, scale: 4, mode: RoundHalfEven, out: "0.3333"},
+		{in: "2", n: 3, scale: 4, mode: RoundDown, out: "0.6666"},
+		{in: "-2", n: 3, scale: 4, mode: RoundHalfEven, out: "-0.6667"},
+		{in: "2", n: -3, scale: 4, mode: RoundCeil, out: "-0.6666"},
+		// single rounding step when reducing scale
+		{in: "1.249", n: 1, scale: 1, mode: RoundHalfUp, out: "1.2"},
+	}
+	for _, test := range tests {
+		d, err := MustParseDecimal256(test.in).Quo64(test.n, test.scale, test.mode)
+		if err != nil {
+			t.Errorf("%s/%d: unexpected error %v", test.in, test.n, err)
+			continue
+		}
+		if got := d.String(); got != test.out {
+			t.Errorf("%s/%d: exp %s, got %s", test.in, test.n, test.out, got)
+		}
+	}
+	if _, err := MustParseDecimal256("1").Quo64(0, 0, RoundHalfEven); err == nil {
+		t.Errorf("expected division by zero error")
+	}
+}
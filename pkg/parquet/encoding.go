@@ -0,0 +1,330 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"sync"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	zstdEnc *zstd.Encoder
+	zstdDec *zstd.Decoder
+	zstdErr error
+	zstdMu  sync.Once
+)
+
+func zstdInit() error {
+	zstdMu.Do(func() {
+		zstdEnc, zstdErr = zstd.NewWriter(nil)
+		if zstdErr != nil {
+			return
+		}
+		zstdDec, zstdErr = zstd.NewReader(nil)
+	})
+	return zstdErr
+}
+
+func compress(c Compression, dst, src []byte) ([]byte, error) {
+	switch c {
+	case CompressionNone:
+		return append(dst, src...), nil
+	case CompressionSnappy:
+		return snappy.Encode(dst[:cap(dst)], src), nil
+	case CompressionGzip:
+		b := bytes.NewBuffer(dst[:0])
+		zw := gzip.NewWriter(b)
+		if _, err := zw.Write(src); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	case CompressionZstd:
+		if err := zstdInit(); err != nil {
+			return nil, err
+		}
+		return zstdEnc.EncodeAll(src, dst[:0]), nil
+	default:
+		return nil, fmt.Errorf("parquet: unsupported compression %d", c)
+	}
+}
+
+func decompress(c Compression, dst, src []byte, size int) ([]byte, error) {
+	switch c {
+	case CompressionNone:
+		return src, nil
+	case CompressionSnappy:
+		if n, err := snappy.DecodedLen(src); err != nil || n != size {
+			return nil, fmt.Errorf("parquet: invalid snappy page")
+		}
+		return snappy.Decode(dst[:cap(dst)], src)
+	case CompressionGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(src))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		if cap(dst) < size {
+			dst = make([]byte, size)
+		}
+		dst = dst[:size]
+		if _, err := io.ReadFull(zr, dst); err != nil {
+			return nil, err
+		}
+		return dst, nil
+	case CompressionZstd:
+		if err := zstdInit(); err != nil {
+			return nil, err
+		}
+		return zstdDec.DecodeAll(src, dst[:0])
+	default:
+		return nil, fmt.Errorf("parquet: unsupported compression %d", c)
+	}
+}
+
+// appendLevels encodes definition levels of bit width 1 using RLE runs of
+// the RLE/bit-packing hybrid encoding.
+func appendLevels(buf []byte, levels []byte) []byte {
+	for i := 0; i < len(levels); {
+		j := i + 1
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		buf = binary.AppendUvarint(buf, uint64(j-i)<<1)
+		buf = append(buf, levels[i])
+		i = j
+	}
+	return buf
+}
+
+// decodeHybrid decodes n values of bit width bw from the RLE/bit-packing
+// hybrid encoding and appends them to dst.
+func decodeHybrid(dst []int32, buf []byte, bw, n int) ([]int32, error) {
+	if bw < 0 || bw > 32 {
+		return dst, fmt.Errorf("parquet: invalid bit width %d", bw)
+	}
+	var (
+		pos  int
+		mask = uint64(1)<<bw - 1
+		vlen = (bw + 7) / 8
+	)
+	for n > 0 {
+		h, k := binary.Uvarint(buf[pos:])
+		if k <= 0 {
+			return dst, ErrInvalidFile
+		}
+		pos += k
+		if h&1 == 0 {
+			// rle run
+			cnt := int(min(h>>1, uint64(n)))
+			if len(buf)-pos < vlen {
+				return dst, ErrInvalidFile
+			}
+			var v uint32
+			for i := range vlen {
+				v |= uint32(buf[pos+i]) << (8 * i)
+			}
+			pos += vlen
+			for range cnt {
+				dst = append(dst, int32(v))
+			}
+			n -= cnt
+			continue
+		}
+		// bit-packed groups of 8 values
+		groups := h >> 1
+		if groups > uint64(len(buf)) {
+			return dst, ErrInvalidFile
+		}
+		nbytes := int(groups) * bw
+		if len(buf)-pos < nbytes {
+			return dst, ErrInvalidFile
+		}
+		cnt := min(int(groups)*8, n)
+		var (
+			acc   uint64
+			nbits int
+			p     = pos
+		)
+		for range cnt {
+			for nbits < bw {
+				acc |= uint64(buf[p]) << nbits
+				p++
+				nbits += 8
+			}
+			dst = append(dst, int32(acc&mask))
+			acc >>= bw
+			nbits -= bw
+		}
+		pos += nbytes
+		n -= cnt
+	}
+	return dst, nil
+}
+
+// column holds decoded values of a single column chunk in its physical
+// representation. Only non-null values are stored.
+type column struct {
+	typ   Type
+	n     int    // number of rows including nulls
+	valid []bool // row validity, nil when all rows are valid
+	b     []bool
+	i32   []int32
+	i64   []int64
+	f32   []float32
+	f64   []float64
+	bin   [][]byte
+}
+
+func (c *column) reset(typ Type) {
+	c.typ = typ
+	c.n = 0
+	c.valid = c.valid[:0]
+	c.b = c.b[:0]
+	c.i32 = c.i32[:0]
+	c.i64 = c.i64[:0]
+	c.f32 = c.f32[:0]
+	c.f64 = c.f64[:0]
+	c.bin = c.bin[:0]
+}
+
+func (c *column) len() int {
+	switch c.typ {
+	case TypeBoolean:
+		return len(c.b)
+	case TypeInt32:
+		return len(c.i32)
+	case TypeInt64, TypeInt96:
+		return len(c.i64)
+	case TypeFloat:
+		return len(c.f32)
+	case TypeDouble:
+		return len(c.f64)
+	default:
+		return len(c.bin)
+	}
+}
+
+// appendFrom appends dictionary values at positions idx.
+func (c *column) appendFrom(dict *column, idx []int32) error {
+	n := int32(dict.len())
+	for _, i := range idx {
+		if i < 0 || i >= n {
+			return fmt.Errorf("parquet: dictionary index %d out of range", i)
+		}
+		switch c.typ {
+		case TypeBoolean:
+			c.b = append(c.b, dict.b[i])
+		case TypeInt32:
+			c.i32 = append(c.i32, dict.i32[i])
+		case TypeInt64, TypeInt96:
+			c.i64 = append(c.i64, dict.i64[i])
+		case TypeFloat:
+			c.f32 = append(c.f32, dict.f32[i])
+		case TypeDouble:
+			c.f64 = append(c.f64, dict.f64[i])
+		default:
+			c.bin = append(c.bin, dict.bin[i])
+		}
+	}
+	return nil
+}
+
+// decodePlain decodes n plain encoded values from buf. Byte array values
+// reference buf.
+func (c *column) decodePlain(buf []byte, n, typeLen int) error {
+	switch c.typ {
+	case TypeBoolean:
+		if len(buf)*8 < n {
+			return ErrInvalidFile
+		}
+		for i := range n {
+			c.b = append(c.b, buf[i>>3]&(1<<(i&7)) != 0)
+		}
+	case TypeInt32:
+		if len(buf) < 4*n {
+			return ErrInvalidFile
+		}
+		for i := range n {
+			c.i32 = append(c.i32, int32(binary.LittleEndian.Uint32(buf[4*i:])))
+		}
+	case TypeInt64:
+		if len(buf) < 8*n {
+			return ErrInvalidFile
+		}
+		for i := range n {
+			c.i64 = append(c.i64, int64(binary.LittleEndian.Uint64(buf[8*i:])))
+		}
+	case TypeInt96:
+		// legacy timestamps: nanoseconds of day and julian day
+		if len(buf) < 12*n {
+			return ErrInvalidFile
+		}
+		for i := range n {
+			v := buf[12*i:]
+			nanos := int64(binary.LittleEndian.Uint64(v))
+			days := int64(binary.LittleEndian.Uint32(v[8:])) - julianUnixEpoch
+			c.i64 = append(c.i64, days*nanosPerDay+nanos)
+		}
+	case TypeFloat:
+		if len(buf) < 4*n {
+			return ErrInvalidFile
+		}
+		for i := range n {
+			c.f32 = append(c.f32, math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:])))
+		}
+	case TypeDouble:
+		if len(buf) < 8*n {
+			return ErrInvalidFile
+		}
+		for i := range n {
+			c.f64 = append(c.f64, math.Float64frombits(binary.LittleEndian.Uint64(buf[8*i:])))
+		}
+	case TypeByteArray:
+		var pos int
+		for range n {
+			if len(buf)-pos < 4 {
+				return ErrInvalidFile
+			}
+			l := int(binary.LittleEndian.Uint32(buf[pos:]))
+			pos += 4
+			if l < 0 || len(buf)-pos < l {
+				return ErrInvalidFile
+			}
+			c.bin = append(c.bin, buf[pos:pos+l:pos+l])
+			pos += l
+		}
+	case TypeFixedLenByteArray:
+		if typeLen <= 0 || len(buf) < typeLen*n {
+			return ErrInvalidFile
+		}
+		for i := range n {
+			c.bin = append(c.bin, buf[i*typeLen:(i+1)*typeLen:(i+1)*typeLen])
+		}
+	default:
+		return fmt.Errorf("%w %s", ErrUnsupportedType, c.typ)
+	}
+	return nil
+}
+
+const (
+	julianUnixEpoch = 2440588 // julian day number of 1970-01-01
+	nanosPerDay     = 86400000000000
+)
+
+// bitWidth returns the number of bits required to store v.
+func bitWidth(v int) int {
+	return bits.Len(uint(v))
+}
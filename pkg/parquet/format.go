@@ -0,0 +1,832 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package parquet
+
+// Parquet file format metadata, see
+// https://github.com/apache/parquet-format/blob/master/src/main/thrift/parquet.thrift
+//
+// Only members used by this package are modelled, other fields are skipped
+// on decode.
+
+var magic = []byte("PAR1")
+
+// physical types
+type Type int32
+
+const (
+	TypeBoolean Type = iota
+	TypeInt32
+	TypeInt64
+	TypeInt96
+	TypeFloat
+	TypeDouble
+	TypeByteArray
+	TypeFixedLenByteArray
+)
+
+var typeNames = [...]string{
+	"BOOLEAN",
+	"INT32",
+	"INT64",
+	"INT96",
+	"FLOAT",
+	"DOUBLE",
+	"BYTE_ARRAY",
+	"FIXED_LEN_BYTE_ARRAY",
+}
+
+func (t Type) String() string {
+	if t >= 0 && int(t) < len(typeNames) {
+		return typeNames[t]
+	}
+	return "UNKNOWN"
+}
+
+type Repetition int32
+
+const (
+	Required Repetition = iota
+	Optional
+	Repeated
+)
+
+// legacy converted types, still written by many tools
+type ConvertedType int32
+
+const (
+	ConvertedUTF8            ConvertedType = 0
+	ConvertedEnum            ConvertedType = 4
+	ConvertedDecimal         ConvertedType = 5
+	ConvertedDate            ConvertedType = 6
+	ConvertedTimeMillis      ConvertedType = 7
+	ConvertedTimeMicros      ConvertedType = 8
+	ConvertedTimestampMillis ConvertedType = 9
+	ConvertedTimestampMicros ConvertedType = 10
+	ConvertedUint8           ConvertedType = 11
+	ConvertedUint16          ConvertedType = 12
+	ConvertedUint32          ConvertedType = 13
+	ConvertedUint64          ConvertedType = 14
+	ConvertedInt8            ConvertedType = 15
+	ConvertedInt16           ConvertedType = 16
+	ConvertedInt32           ConvertedType = 17
+	ConvertedInt64           ConvertedType = 18
+	ConvertedJSON            ConvertedType = 19
+)
+
+type Encoding int32
+
+const (
+	EncodingPlain           Encoding = 0
+	EncodingPlainDictionary Encoding = 2
+	EncodingRLE             Encoding = 3
+	EncodingBitPacked       Encoding = 4
+	EncodingRLEDictionary   Encoding = 8
+)
+
+type Compression int32
+
+const (
+	CompressionNone   Compression = 0
+	CompressionSnappy Compression = 1
+	CompressionGzip   Compression = 2
+	CompressionZstd   Compression = 6
+)
+
+func (c Compression) String() string {
+	switch c {
+	case CompressionNone:
+		return "none"
+	case CompressionSnappy:
+		return "snappy"
+	case CompressionGzip:
+		return "gzip"
+	case CompressionZstd:
+		return "zstd"
+	default:
+		return "unknown"
+	}
+}
+
+func ParseCompression(s string) (Compression, bool) {
+	switch s {
+	case "", "none", "uncompressed":
+		return CompressionNone, true
+	case "snappy":
+		return CompressionSnappy, true
+	case "gzip":
+		return CompressionGzip, true
+	case "zstd":
+		return CompressionZstd, true
+	default:
+		return 0, false
+	}
+}
+
+type pageType int32
+
+const (
+	pageData       pageType = 0
+	pageDictionary pageType = 2
+	pageDataV2     pageType = 3
+)
+
+// time units of TIME and TIMESTAMP logical types
+type TimeUnit byte
+
+const (
+	UnitNone TimeUnit = iota
+	UnitMillis
+	UnitMicros
+	UnitNanos
+)
+
+// logical type kinds (LogicalType union member ids)
+type LogicalKind int16
+
+const (
+	LogicalNone      LogicalKind = 0
+	LogicalString    LogicalKind = 1
+	LogicalEnum      LogicalKind = 4
+	LogicalDecimal   LogicalKind = 5
+	LogicalDate      LogicalKind = 6
+	LogicalTime      LogicalKind = 7
+	LogicalTimestamp LogicalKind = 8
+	LogicalInteger   LogicalKind = 10
+	LogicalJSON      LogicalKind = 12
+	LogicalUUID      LogicalKind = 14
+)
+
+// LogicalType flattens the Parquet LogicalType union.
+type LogicalType struct {
+	Kind      LogicalKind
+	Scale     int32    // decimal
+	Precision int32    // decimal
+	Unit      TimeUnit // time, timestamp
+	UTC       bool     // time, timestamp
+	BitWidth  int8     // integer
+	Signed    bool     // integer
+}
+
+type SchemaElement struct {
+	Type          Type
+	HasType       bool
+	TypeLength    int32
+	Repetition    Repetition
+	Name          string
+	NumChildren   int32
+	ConvertedType ConvertedType
+	HasConverted  bool
+	Scale         int32
+	Precision     int32
+	Logical       LogicalType
+}
+
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+type Statistics struct {
+	NullCount int64
+	Min       []byte
+	Max       []byte
+}
+
+type ColumnMetaData struct {
+	Type                  Type
+	Encodings             []Encoding
+	Path                  []string
+	Codec                 Compression
+	NumValues             int64
+	TotalUncompressedSize int64
+	TotalCompressedSize   int64
+	DataPageOffset        int64
+	DictionaryPageOffset  int64
+	Statistics            *Statistics
+}
+
+type ColumnChunk struct {
+	FileOffset int64
+	Meta       ColumnMetaData
+}
+
+type SortingColumn struct {
+	ColumnIdx  int32
+	Descending bool
+	NullsFirst bool
+}
+
+type RowGroup struct {
+	Columns       []ColumnChunk
+	TotalByteSize int64
+	NumRows       int64
+	Sorting       []SortingColumn
+}
+
+type FileMetaData struct {
+	Version   int32
+	Schema    []SchemaElement
+	NumRows   int64
+	RowGroups []RowGroup
+	KeyValues []KeyValue
+	CreatedBy string
+}
+
+type dataPageHeader struct {
+	NumValues int32
+	Encoding  Encoding
+	DefLevels Encoding
+	RepLevels Encoding
+}
+
+type dataPageHeaderV2 struct {
+	NumValues    int32
+	NumNulls     int32
+	NumRows      int32
+	Encoding     Encoding
+	DefLevelsLen int32
+	RepLevelsLen int32
+	Compressed   bool
+}
+
+type dictPageHeader struct {
+	NumValues int32
+	Encoding  Encoding
+}
+
+type pageHeader struct {
+	Type             pageType
+	UncompressedSize int32
+	CompressedSize   int32
+	Data             dataPageHeader
+	DataV2           dataPageHeaderV2
+	Dict             dictPageHeader
+}
+
+// encoders
+
+func (m *FileMetaData) encode(w *thriftWriter) {
+	w.structBegin()
+	w.i32Field(1, m.Version)
+	w.field(2, tList)
+	w.listBegin(tStruct, len(m.Schema))
+	for i := range m.Schema {
+		m.Schema[i].encode(w)
+	}
+	w.i64Field(3, m.NumRows)
+	w.field(4, tList)
+	w.listBegin(tStruct, len(m.RowGroups))
+	for i := range m.RowGroups {
+		m.RowGroups[i].encode(w)
+	}
+	if len(m.KeyValues) > 0 {
+		w.field(5, tList)
+		w.listBegin(tStruct, len(m.KeyValues))
+		for _, kv := range m.KeyValues {
+			w.structBegin()
+			w.binaryField(1, []byte(kv.Key))
+			w.binaryField(2, []byte(kv.Value))
+			w.structEnd()
+		}
+	}
+	if m.CreatedBy != "" {
+		w.binaryField(6, []byte(m.CreatedBy))
+	}
+	// column orders: type defined order for all leaf columns which
+	// makes min/max statistics valid for unsigned and logical types
+	if n := len(m.Schema) - 1; n > 0 {
+		w.field(7, tList)
+		w.listBegin(tStruct, n)
+		for range n {
+			w.structBegin()
+			w.emptyStructField(1)
+			w.structEnd()
+		}
+	}
+	w.structEnd()
+}
+
+func (e *SchemaElement) encode(w *thriftWriter) {
+	w.structBegin()
+	if e.HasType {
+		w.i32Field(1, int32(e.Type))
+		if e.Type == TypeFixedLenByteArray {
+			w.i32Field(2, e.TypeLength)
+		}
+		w.i32Field(3, int32(e.Repetition))
+	}
+	w.binaryField(4, []byte(e.Name))
+	if !e.HasType {
+		w.i32Field(5, e.NumChildren)
+	}
+	if e.HasConverted {
+		w.i32Field(6, int32(e.ConvertedType))
+	}
+	if e.Logical.Kind == LogicalDecimal {
+		w.i32Field(7, e.Scale)
+		w.i32Field(8, e.Precision)
+	}
+	if e.Logical.Kind != LogicalNone {
+		w.field(10, tStruct)
+		e.Logical.encode(w)
+	}
+	w.structEnd()
+}
+
+func (l *LogicalType) encode(w *thriftWriter) {
+	w.structBegin()
+	switch l.Kind {
+	case LogicalDecimal:
+		w.field(int16(l.Kind), tStruct)
+		w.structBegin()
+		w.i32Field(1, l.Scale)
+		w.i32Field(2, l.Precision)
+		w.structEnd()
+	case LogicalTime, LogicalTimestamp:
+		w.field(int16(l.Kind), tStruct)
+		w.structBegin()
+		w.boolField(1, l.UTC)
+		w.field(2, tStruct)
+		w.structBegin()
+		w.emptyStructField(int16(l.Unit))
+		w.structEnd()
+		w.structEnd()
+	case LogicalInteger:
+		w.field(int16(l.Kind), tStruct)
+		w.structBegin()
+		w.field(1, tByte)
+		w.buf = append(w.buf, byte(l.BitWidth))
+		w.boolField(2, l.Signed)
+		w.structEnd()
+	default:
+		w.emptyStructField(int16(l.Kind))
+	}
+	w.structEnd()
+}
+
+func (g *RowGroup) encode(w *thriftWriter) {
+	w.structBegin()
+	w.field(1, tList)
+	w.listBegin(tStruct, len(g.Columns))
+	for i := range g.Columns {
+		g.Columns[i].encode(w)
+	}
+	w.i64Field(2, g.TotalByteSize)
+	w.i64Field(3, g.NumRows)
+	if len(g.Sorting) > 0 {
+		w.field(4, tList)
+		w.listBegin(tStruct, len(g.Sorting))
+		for _, s := range g.Sorting {
+			w.structBegin()
+			w.i32Field(1, s.ColumnIdx)
+			w.boolField(2, s.Descending)
+			w.boolField(3, s.NullsFirst)
+			w.structEnd()
+		}
+	}
+	w.structEnd()
+}
+
+func (c *ColumnChunk) encode(w *thriftWriter) {
+	w.structBegin()
+	w.i64Field(2, c.FileOffset)
+	w.field(3, tStruct)
+	c.Meta.encode(w)
+	w.structEnd()
+}
+
+func (m *ColumnMetaData) encode(w *thriftWriter) {
+	w.structBegin()
+	w.i32Field(1, int32(m.Type))
+	w.field(2, tList)
+	w.listBegin(tI32, len(m.Encodings))
+	for _, e := range m.Encodings {
+		w.varint(int64(e))
+	}
+	w.field(3, tList)
+	w.listBegin(tBinary, len(m.Path))
+	for _, p := range m.Path {
+		w.binary([]byte(p))
+	}
+	w.i32Field(4, int32(m.Codec))
+	w.i64Field(5, m.NumValues)
+	w.i64Field(6, m.TotalUncompressedSize)
+	w.i64Field(7, m.TotalCompressedSize)
+	w.i64Field(9, m.DataPageOffset)
+	if m.DictionaryPageOffset > 0 {
+		w.i64Field(11, m.DictionaryPageOffset)
+	}
+	if s := m.Statistics; s != nil {
+		w.field(12, tStruct)
+		w.structBegin()
+		w.i64Field(3, s.NullCount)
+		if s.Max != nil {
+			w.binaryField(5, s.Max)
+			w.binaryField(6, s.Min)
+		}
+		w.structEnd()
+	}
+	w.structEnd()
+}
+
+func (h *pageHeader) encode(w *thriftWriter) {
+	w.structBegin()
+	w.i32Field(1, int32(h.Type))
+	w.i32Field(2, h.UncompressedSize)
+	w.i32Field(3, h.CompressedSize)
+	w.field(5, tStruct)
+	w.structBegin()
+	w.i32Field(1, h.Data.NumValues)
+	w.i32Field(2, int32(h.Data.Encoding))
+	w.i32Field(3, int32(h.Data.DefLevels))
+	w.i32Field(4, int32(h.Data.RepLevels))
+	w.structEnd()
+	w.structEnd()
+}
+
+// decoders
+
+func (m *FileMetaData) decode(r *thriftReader) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 1 && typ == tI32:
+			m.Version, err = r.i32()
+		case id == 2 && typ == tList:
+			var n int
+			if _, n, err = r.list(); err != nil {
+				return err
+			}
+			m.Schema = make([]SchemaElement, n)
+			for i := range m.Schema {
+				if err = m.Schema[i].decode(r); err != nil {
+					return err
+				}
+			}
+		case id == 3 && typ == tI64:
+			m.NumRows, err = r.varint()
+		case id == 4 && typ == tList:
+			var n int
+			if _, n, err = r.list(); err != nil {
+				return err
+			}
+			m.RowGroups = make([]RowGroup, n)
+			for i := range m.RowGroups {
+				if err = m.RowGroups[i].decode(r); err != nil {
+					return err
+				}
+			}
+		case id == 5 && typ == tList:
+			var n int
+			if _, n, err = r.list(); err != nil {
+				return err
+			}
+			m.KeyValues = make([]KeyValue, n)
+			for i := range m.KeyValues {
+				kv := &m.KeyValues[i]
+				err = r.readStruct(func(id int16, typ byte) error {
+					if typ != tBinary || id > 2 {
+						return r.skip(typ)
+					}
+					b, err := r.binary()
+					if id == 1 {
+						kv.Key = string(b)
+					} else {
+						kv.Value = string(b)
+					}
+					return err
+				})
+				if err != nil {
+					return err
+				}
+			}
+		case id == 6 && typ == tBinary:
+			var b []byte
+			b, err = r.binary()
+			m.CreatedBy = string(b)
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+}
+
+func (e *SchemaElement) decode(r *thriftReader) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		var (
+			v   int32
+			err error
+		)
+		switch {
+		case id == 4 && typ == tBinary:
+			var b []byte
+			b, err = r.binary()
+			e.Name = string(b)
+			return err
+		case id == 10 && typ == tStruct:
+			return e.Logical.decode(r)
+		case typ == tI32 && id <= 8:
+			if v, err = r.i32(); err != nil {
+				return err
+			}
+		default:
+			return r.skip(typ)
+		}
+		switch id {
+		case 1:
+			e.Type, e.HasType = Type(v), true
+		case 2:
+			e.TypeLength = v
+		case 3:
+			e.Repetition = Repetition(v)
+		case 5:
+			e.NumChildren = v
+		case 6:
+			e.ConvertedType, e.HasConverted = ConvertedType(v), true
+		case 7:
+			e.Scale = v
+		case 8:
+			e.Precision = v
+		}
+		return nil
+	})
+}
+
+func (l *LogicalType) decode(r *thriftReader) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		if typ != tStruct {
+			return r.skip(typ)
+		}
+		l.Kind = LogicalKind(id)
+		switch l.Kind {
+		case LogicalDecimal:
+			return r.readStruct(func(id int16, typ byte) error {
+				var err error
+				switch {
+				case id == 1 && typ == tI32:
+					l.Scale, err = r.i32()
+				case id == 2 && typ == tI32:
+					l.Precision, err = r.i32()
+				default:
+					err = r.skip(typ)
+				}
+				return err
+			})
+		case LogicalTime, LogicalTimestamp:
+			return r.readStruct(func(id int16, typ byte) error {
+				switch {
+				case id == 1 && (typ == tTrue || typ == tFalse):
+					l.UTC = r.bool(typ)
+					return nil
+				case id == 2 && typ == tStruct:
+					return r.readStruct(func(id int16, typ byte) error {
+						l.Unit = TimeUnit(id)
+						return r.skip(typ)
+					})
+				default:
+					return r.skip(typ)
+				}
+			})
+		case LogicalInteger:
+			return r.readStruct(func(id int16, typ byte) error {
+				switch {
+				case id == 1 && typ == tByte:
+					b, err := r.byte()
+					l.BitWidth = int8(b)
+					return err
+				case id == 2 && (typ == tTrue || typ == tFalse):
+					l.Signed = r.bool(typ)
+					return nil
+				default:
+					return r.skip(typ)
+				}
+			})
+		default:
+			return r.skip(typ)
+		}
+	})
+}
+
+func (g *RowGroup) decode(r *thriftReader) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 1 && typ == tList:
+			var n int
+			if _, n, err = r.list(); err != nil {
+				return err
+			}
+			g.Columns = make([]ColumnChunk, n)
+			for i := range g.Columns {
+				if err = g.Columns[i].decode(r); err != nil {
+					return err
+				}
+			}
+		case id == 2 && typ == tI64:
+			g.TotalByteSize, err = r.varint()
+		case id == 3 && typ == tI64:
+			g.NumRows, err = r.varint()
+		case id == 4 && typ == tList:
+			var n int
+			if _, n, err = r.list(); err != nil {
+				return err
+			}
+			g.Sorting = make([]SortingColumn, n)
+			for i := range g.Sorting {
+				if err = g.Sorting[i].decode(r); err != nil {
+					return err
+				}
+			}
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+}
+
+func (s *SortingColumn) decode(r *thriftReader) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 1 && typ == tI32:
+			s.ColumnIdx, err = r.i32()
+		case id == 2 && (typ == tTrue || typ == tFalse):
+			s.Descending = r.bool(typ)
+		case id == 3 && (typ == tTrue || typ == tFalse):
+			s.NullsFirst = r.bool(typ)
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+}
+
+func (c *ColumnChunk) decode(r *thriftReader) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 1 && typ == tBinary:
+			return errExternalColumn
+		case id == 2 && typ == tI64:
+			c.FileOffset, err = r.varint()
+		case id == 3 && typ == tStruct:
+			err = c.Meta.decode(r)
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+}
+
+func (m *ColumnMetaData) decode(r *thriftReader) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 1 && typ == tI32:
+			var v int32
+			v, err = r.i32()
+			m.Type = Type(v)
+		case id == 2 && typ == tList:
+			var n int
+			if _, n, err = r.list(); err != nil {
+				return err
+			}
+			m.Encodings = make([]Encoding, n)
+			for i := range m.Encodings {
+				var v int32
+				if v, err = r.i32(); err != nil {
+					return err
+				}
+				m.Encodings[i] = Encoding(v)
+			}
+		case id == 3 && typ == tList:
+			var n int
+			if _, n, err = r.list(); err != nil {
+				return err
+			}
+			m.Path = make([]string, n)
+			for i := range m.Path {
+				var b []byte
+				if b, err = r.binary(); err != nil {
+					return err
+				}
+				m.Path[i] = string(b)
+			}
+		case id == 4 && typ == tI32:
+			var v int32
+			v, err = r.i32()
+			m.Codec = Compression(v)
+		case id == 5 && typ == tI64:
+			m.NumValues, err = r.varint()
+		case id == 6 && typ == tI64:
+			m.TotalUncompressedSize, err = r.varint()
+		case id == 7 && typ == tI64:
+			m.TotalCompressedSize, err = r.varint()
+		case id == 9 && typ == tI64:
+			m.DataPageOffset, err = r.varint()
+		case id == 11 && typ == tI64:
+			m.DictionaryPageOffset, err = r.varint()
+		case id == 12 && typ == tStruct:
+			m.Statistics = &Statistics{}
+			err = m.Statistics.decode(r)
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+}
+
+func (s *Statistics) decode(r *thriftReader) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 3 && typ == tI64:
+			s.NullCount, err = r.varint()
+		case id == 5 && typ == tBinary:
+			s.Max, err = r.binary()
+		case id == 6 && typ == tBinary:
+			s.Min, err = r.binary()
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+}
+
+func (h *pageHeader) decode(r *thriftReader) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 1 && typ == tI32:
+			var v int32
+			v, err = r.i32()
+			h.Type = pageType(v)
+		case id == 2 && typ == tI32:
+			h.UncompressedSize, err = r.i32()
+		case id == 3 && typ == tI32:
+			h.CompressedSize, err = r.i32()
+		case id == 5 && typ == tStruct:
+			err = r.readStruct(func(id int16, typ byte) error {
+				if typ != tI32 || id > 4 {
+					return r.skip(typ)
+				}
+				v, err := r.i32()
+				switch id {
+				case 1:
+					h.Data.NumValues = v
+				case 2:
+					h.Data.Encoding = Encoding(v)
+				case 3:
+					h.Data.DefLevels = Encoding(v)
+				case 4:
+					h.Data.RepLevels = Encoding(v)
+				}
+				return err
+			})
+		case id == 7 && typ == tStruct:
+			err = r.readStruct(func(id int16, typ byte) error {
+				if typ != tI32 || id > 2 {
+					return r.skip(typ)
+				}
+				v, err := r.i32()
+				if id == 1 {
+					h.Dict.NumValues = v
+				} else {
+					h.Dict.Encoding = Encoding(v)
+				}
+				return err
+			})
+		case id == 8 && typ == tStruct:
+			h.DataV2.Compressed = true // default
+			err = r.readStruct(func(id int16, typ byte) error {
+				if id == 7 && (typ == tTrue || typ == tFalse) {
+					h.DataV2.Compressed = r.bool(typ)
+					return nil
+				}
+				if typ != tI32 || id > 6 {
+					return r.skip(typ)
+				}
+				v, err := r.i32()
+				switch id {
+				case 1:
+					h.DataV2.NumValues = v
+				case 2:
+					h.DataV2.NumNulls = v
+				case 3:
+					h.DataV2.NumRows = v
+				case 4:
+					h.DataV2.Encoding = Encoding(v)
+				case 5:
+					h.DataV2.DefLevelsLen = v
+				case 6:
+					h.DataV2.RepLevelsLen = v
+				}
+				return err
+			})
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package parquet
+
+//go:generate go -C ../../internal/tests/interop run ./gen -out ../../../pkg/parquet/testdata
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"testing"
+
+	"blockwatch.cc/knoxdb/internal/pack"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"github.com/stretchr/testify/require"
+)
+
+// goldenRow holds the values of row i in testdata/golden_*.parquet as
+// written by internal/tests/interop/gen. Nil pointers are NULL.
+type goldenRow struct {
+	Id   int64
+	Name *string
+	I32  *int32
+	D9   int32
+	D18  *int64
+	D38  *big.Int
+	TsMs int64
+	TsUs int64
+	TsNs *int64
+}
+
+const (
+	goldenRows     = 150
+	goldenBaseMs   = 1749261601000 // 2025-06-07T02:00:01Z
+	goldenGroupLen = 64
+)
+
+func makeGoldenRow(i int) goldenRow {
+	v := int64(i - goldenRows/2)
+	ms := goldenBaseMs + v*3600001
+	r := goldenRow{
+		Id:   int64(i + 1),
+		D9:   int32(v * 12345),
+		D38:  new(big.Int).Add(new(big.Int).Mul(big.NewInt(v), big.NewInt(1e18)), big.NewInt(int64(i))),
+		TsMs: ms,
+		TsUs: ms*1000 + v,
+	}
+	if i%5 != 0 {
+		s := []string{"alpha", "beta", "gamma", "delta"}[i%4]
+		r.Name = &s
+	}
+	if i%3 != 0 {
+		x := int32(v * 1000)
+		r.I32 = &x
+	}
+	if i%7 != 0 {
+		x := v * 1000000000001
+		r.D18 = &x
+	}
+	if i%11 != 0 {
+		x := ms*1000000 + v*1001
+		r.TsNs = &x
+	}
+	return r
+}
+
+// TestGoldenFiles reads files written by Apache Arrow's Parquet writer
+// with dictionary pages, optional columns, decimals of all physical
+// types and all timestamp units.
+func TestGoldenFiles(t *testing.T) {
+	for _, name := range []string{
+		"testdata/golden_v1.parquet",
+		"testdata/golden_v2.parquet",
+	} {
+		t.Run(name, func(t *testing.T) {
+			buf, err := os.ReadFile(name)
+			require.NoError(t, err)
+			r, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+			require.NoError(t, err)
+			require.Equal(t, int64(goldenRows), r.NumRows())
+			require.Equal(t, 3, r.NumRowGroups())
+
+			// inferred schema
+			s := r.Schema()
+			for _, c := range []struct {
+				name     string
+				typ      types.FieldType
+				scale    uint8
+				nullable bool
+			}{
+				{"id", types.FieldTypeInt64, 0, false},
+				{"name", types.FieldTypeString, 0, true},
+				{"i32", types.FieldTypeInt32, 0, true},
+				{"d9", types.FieldTypeDecimal32, 2, false},
+				{"d18", types.FieldTypeDecimal64, 4, true},
+				{"d38", types.FieldTypeDecimal128, 6, false},
+				{"ts_ms", types.FieldTypeTimestamp, uint8(schema.TIME_SCALE_MILLI), false},
+				{"ts_us", types.FieldTypeTimestamp, uint8(schema.TIME_SCALE_MICRO), false},
+				{"ts_ns", types.FieldTypeTimestamp, uint8(schema.TIME_SCALE_NANO), true},
+			} {
+				f, ok := s.Find(c.name)
+				require.True(t, ok, c.name)
+				require.Equal(t, c.typ, f.Type, c.name)
+				require.Equal(t, c.scale, f.Scale, c.name)
+				require.Equal(t, c.nullable, f.IsNullable(), c.name)
+			}
+
+			// decimal storage types
+			require.Equal(t, TypeInt32, r.leaves[3].Type)
+			require.Equal(t, TypeInt64, r.leaves[4].Type)
+			require.Equal(t, TypeFixedLenByteArray, r.leaves[5].Type)
+
+			// dictionary pages are used for all columns
+			for _, cc := range r.meta.RowGroups[0].Columns {
+				require.Positive(t, cc.Meta.DictionaryPageOffset, cc.Meta.Path)
+			}
+
+			var row int
+			for g := range r.NumRowGroups() {
+				pkg := pack.New().WithSchema(s).WithMaxRows(r.RowGroupLen(g)).Alloc()
+				n, err := r.ReadRowGroup(g, pkg)
+				require.NoError(t, err)
+				require.Equal(t, min(goldenGroupLen, goldenRows-row), n)
+				for k := range n {
+					exp := makeGoldenRow(row)
+					require.Equal(t, exp.Id, pkg.Block(0).Int64().Get(k))
+					if exp.Name == nil {
+						require.True(t, pkg.Block(1).IsNull(k), "row %d name", row)
+					} else {
+						require.False(t, pkg.Block(1).IsNull(k), "row %d name", row)
+						require.Equal(t, *exp.Name, string(pkg.Block(1).Bytes().Get(k)))
+					}
+					if exp.I32 == nil {
+						require.True(t, pkg.Block(2).IsNull(k), "row %d i32", row)
+					} else {
+						require.False(t, pkg.Block(2).IsNull(k), "row %d i32", row)
+						require.Equal(t, *exp.I32, pkg.Block(2).Int32().Get(k))
+					}
+					require.Equal(t, exp.D9, pkg.Block(3).Int32().Get(k))
+					if exp.D18 == nil {
+						require.True(t, pkg.Block(4).IsNull(k), "row %d d18", row)
+					} else {
+						require.False(t, pkg.Block(4).IsNull(k), "row %d d18", row)
+						require.Equal(t, *exp.D18, pkg.Block(4).Int64().Get(k))
+					}
+					require.Equal(t, exp.D38.String(), pkg.Block(5).Int128().Get(k).String())
+					require.Equal(t, exp.TsMs, pkg.Block(6).Int64().Get(k))
+					require.Equal(t, exp.TsUs, pkg.Block(7).Int64().Get(k))
+					if exp.TsNs == nil {
+						require.True(t, pkg.Block(8).IsNull(k), "row %d ts_ns", row)
+					} else {
+						require.False(t, pkg.Block(8).IsNull(k), "row %d ts_ns", row)
+						require.Equal(t, *exp.TsNs, pkg.Block(8).Int64().Get(k))
+					}
+					row++
+				}
+				pkg.Release()
+			}
+			require.Equal(t, goldenRows, row)
+		})
+	}
+}
@@ -0,0 +1,424 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"blockwatch.cc/knoxdb/internal/pack"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/num"
+	"blockwatch.cc/knoxdb/pkg/schema"
+	"github.com/stretchr/testify/require"
+)
+
+// all supported types including nullable fields
+type AllTypes struct {
+	Id      uint64         `knox:"id,pk"`
+	Int64   int64          `knox:"i64"`
+	Int32   int32          `knox:"i32"`
+	Int16   int16          `knox:"i16"`
+	Int8    int8           `knox:"i8"`
+	Uint64  uint64         `knox:"u64"`
+	Uint32  uint32         `knox:"u32"`
+	Uint16  uint16         `knox:"u16"`
+	Uint8   uint8          `knox:"u8"`
+	Float64 float64        `knox:"f64"`
+	Float32 float32        `knox:"f32"`
+	D32     num.Decimal32  `knox:"d32,scale=5"`
+	D64     num.Decimal64  `knox:"d64,scale=15"`
+	D128    num.Decimal128 `knox:"d128,scale=18"`
+	D256    num.Decimal256 `knox:"d256,scale=24"`
+	I128    num.Int128     `knox:"i128"`
+	I256    num.Int256     `knox:"i256"`
+	Bool    bool           `knox:"bool"`
+	Time    time.Time      `knox:"time,scale=s"`
+	Ts      time.Time      `knox:"ts,scale=us"`
+	Date    time.Time      `knox:"date,date"`
+	Tod     time.Time      `knox:"tod,time"`
+	Hash    []byte         `knox:"bytes"`
+	Array   [2]byte        `knox:"array2"`
+	Uid     [16]byte       `knox:"uid,uuid"`
+	String  string         `knox:"string"`
+	Big     num.Big        `knox:"big"`
+	OptInt  *int64         `knox:"opt_i64"`
+	OptStr  *string        `knox:"opt_str"`
+}
+
+func makeRows(n int) []AllTypes {
+	res := make([]AllTypes, n)
+	tm := time.Date(2025, 6, 7, 2, 0, 1, 0, time.UTC)
+	for i := range res {
+		v := int64(i) - int64(n/2) // include negative values
+		r := AllTypes{
+			Id:      uint64(i + 1),
+			Int64:   v * 1e12,
+			Int32:   int32(v) * 1e6,
+			Int16:   int16(v),
+			Int8:    int8(v),
+			Uint64:  uint64(i) << 60,
+			Uint32:  uint32(i) << 28,
+			Uint16:  uint16(i) << 12,
+			Uint8:   uint8(i) << 4,
+			Float64: float64(v) * 1.5,
+			Float32: float32(v) * 0.5,
+			D32:     num.NewDecimal32(int32(v)*100001, 5),
+			D64:     num.NewDecimal64(v*1000000000000001, 15),
+			D128:    num.NewDecimal128(num.Int128FromInt64(v*1000000000000000001), 18),
+			D256:    num.NewDecimal256(num.Int256FromInt64(v).Mul(num.Int256FromInt64(1e18)), 24),
+			I128:    num.Int128FromInt64(v).Lsh(70),
+			I256:    num.Int256FromInt64(v).Lsh(200),
+			Bool:    i%3 == 0,
+			Time:    tm.Add(time.Duration(v) * time.Hour),
+			Ts:      tm.Add(time.Duration(v) * time.Microsecond),
+			Date:    tm.AddDate(0, 0, int(v)).Truncate(24 * time.Hour),
+			Tod:     time.Unix(int64(i)*61, 0).UTC(), // time of day
+			Hash:    bytes.Repeat([]byte{byte(i)}, i%5),
+			Array:   [2]byte{byte(i), byte(i >> 8)},
+			Uid:     [16]byte{0: byte(i), 15: 1},
+			String:  string(rune('a' + i%26)),
+			Big:     num.NewBig(v * 1e15),
+		}
+		if i%4 != 0 {
+			x, s := v, r.String
+			r.OptInt, r.OptStr = &x, &s
+		}
+		res[i] = r
+	}
+	return res
+}
+
+func makePack(t testing.TB, s *schema.Schema, rows []AllTypes) *pack.Package {
+	pkg := pack.New().WithSchema(s).WithMaxRows(len(rows)).Alloc()
+	enc := schema.NewGenericEncoder[AllTypes]()
+	for _, v := range rows {
+		buf, err := enc.Encode(v, nil)
+		require.NoError(t, err)
+		pkg.AppendWire(buf, nil)
+	}
+	return pkg
+}
+
+func requirePackEqual(t *testing.T, exp, got *pack.Package) {
+	t.Helper()
+	require.Equal(t, exp.Len(), got.Len())
+	for i, f := range exp.Schema().Fields {
+		eb, gb := exp.Block(i), got.Block(i)
+		for row := range exp.Len() {
+			require.Equal(t, eb.IsNull(row), gb.IsNull(row), "field %s row %d null", f.Name, row)
+			require.Equal(t, eb.Get(row), gb.Get(row), "field %s row %d", f.Name, row)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	s := schema.MustSchemaOf(AllTypes{})
+	rows := makeRows(40)
+	p1 := makePack(t, s, rows[:24])
+	defer p1.Release()
+	p2 := makePack(t, s, rows[24:])
+	defer p2.Release()
+
+	for _, c := range []Compression{
+		CompressionNone,
+		CompressionSnappy,
+		CompressionGzip,
+		CompressionZstd,
+	} {
+		t.Run(c.String(), func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			w, err := NewWriter(s, buf)
+			require.NoError(t, err)
+			w.WithCompression(c)
+			require.NoError(t, w.WritePack(p1))
+			require.NoError(t, w.WritePack(p2))
+			require.NoError(t, w.Close())
+
+			r, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			require.NoError(t, err)
+			require.Equal(t, s.Hash, r.Schema().Hash)
+			require.Equal(t, int64(len(rows)), r.NumRows())
+			require.Equal(t, 2, r.NumRowGroups())
+
+			for i, exp := range []*pack.Package{p1, p2} {
+				require.Equal(t, exp.Len(), r.RowGroupLen(i))
+				got := pack.New().WithSchema(s).WithMaxRows(r.RowGroupLen(i)).Alloc()
+				n, err := r.ReadRowGroup(i, got)
+				require.NoError(t, err)
+				require.Equal(t, exp.Len(), n)
+				requirePackEqual(t, exp, got)
+				got.Release()
+
+				// pk sort order and statistics
+				g := r.meta.RowGroups[i]
+				require.Len(t, g.Sorting, 1)
+				require.Equal(t, int32(0), g.Sorting[0].ColumnIdx)
+				st := g.Columns[0].Meta.Statistics
+				require.NotNil(t, st)
+				require.Equal(t, exp.Block(0).Uint64().Get(0), binary.LittleEndian.Uint64(st.Min))
+				require.Equal(t, exp.Block(0).Uint64().Get(exp.Len()-1), binary.LittleEndian.Uint64(st.Max))
+			}
+		})
+	}
+}
+
+func TestWriteSelection(t *testing.T) {
+	s := schema.MustSchemaOf(AllTypes{})
+	rows := makeRows(10)
+	pkg := makePack(t, s, rows)
+	defer pkg.Release()
+	pkg.WithSelection([]uint32{1, 4, 9})
+
+	buf := new(bytes.Buffer)
+	w, err := NewWriter(s, buf)
+	require.NoError(t, err)
+	require.NoError(t, w.WritePack(pkg))
+	require.NoError(t, w.Close())
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	got := pack.New().WithSchema(s).WithMaxRows(3).Alloc()
+	defer got.Release()
+	_, err = r.ReadRowGroup(0, got)
+	require.NoError(t, err)
+
+	exp := makePack(t, s, []AllTypes{rows[1], rows[4], rows[9]})
+	defer exp.Release()
+	requirePackEqual(t, exp, got)
+}
+
+func TestEmptyFile(t *testing.T) {
+	s := schema.MustSchemaOf(AllTypes{})
+	buf := new(bytes.Buffer)
+	w, err := NewWriter(s, buf)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Equal(t, int64(0), r.NumRows())
+	require.Equal(t, 0, r.NumRowGroups())
+	require.Equal(t, s.Hash, r.Schema().Hash)
+}
+
+func TestInvalidFile(t *testing.T) {
+	for _, buf := range [][]byte{
+		nil,
+		[]byte("PAR1"),
+		[]byte("PAR1\x00\x00\x00\x00PAR2"),
+		[]byte("PAR1\xff\x00\x00\x00PAR1"),
+	} {
+		_, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+		require.Error(t, err)
+	}
+}
+
+func TestInferSchema(t *testing.T) {
+	s := schema.MustSchemaOf(AllTypes{})
+	var leaves []SchemaElement
+	for _, f := range s.Fields {
+		e, err := element(f)
+		require.NoError(t, err)
+		leaves = append(leaves, e)
+	}
+	is, err := inferSchema(&FileMetaData{}, leaves)
+	require.NoError(t, err)
+	require.Len(t, is.Fields, len(s.Fields))
+
+	for i, f := range is.Fields {
+		exp := s.Fields[i]
+		require.Equal(t, exp.Name, f.Name)
+		require.Equal(t, exp.IsNullable(), f.IsNullable(), f.Name)
+		switch exp.Name {
+		case "id":
+			// pk flag is not represented in Parquet
+			require.Equal(t, types.FieldTypeUint64, f.Type)
+		case "time", "tod":
+			// seconds are stored as millis
+			require.Equal(t, exp.Type, f.Type)
+			require.Equal(t, uint8(schema.TIME_SCALE_MILLI), f.Scale)
+		case "i128":
+			require.Equal(t, types.FieldTypeDecimal128, f.Type)
+		case "i256":
+			require.Equal(t, types.FieldTypeDecimal256, f.Type)
+		case "big":
+			require.Equal(t, types.FieldTypeBytes, f.Type)
+		default:
+			require.Equal(t, exp.Type, f.Type, f.Name)
+			require.Equal(t, exp.Scale, f.Scale, f.Name)
+			require.Equal(t, exp.Fixed, f.Fixed, f.Name)
+		}
+	}
+}
+
+// writeChunk writes a column chunk of an optional int64 column holding
+// values [1, NULL, 2, 1, NULL] with a dictionary page followed by a
+// RLE_DICTIONARY encoded v2 data page.
+func writeChunk(t *testing.T, c Compression) []byte {
+	var w thriftWriter
+
+	// dictionary page with plain values 1, 2
+	dict := binary.LittleEndian.AppendUint64(nil, 1)
+	dict = binary.LittleEndian.AppendUint64(dict, 2)
+	zdict, err := compress(c, nil, dict)
+	require.NoError(t, err)
+	w.structBegin()
+	w.i32Field(1, int32(pageDictionary))
+	w.i32Field(2, int32(len(dict)))
+	w.i32Field(3, int32(len(zdict)))
+	w.field(7, tStruct)
+	w.structBegin()
+	w.i32Field(1, 2)
+	w.i32Field(2, int32(EncodingPlain))
+	w.structEnd()
+	w.structEnd()
+	w.buf = append(w.buf, zdict...)
+
+	// definition levels 1,0,1,1,0 bit-packed, uncompressed
+	levels := []byte{1<<1 | 1, 0b01101}
+	// dictionary indexes 0,1,0 bit width 1 using rle runs
+	vals := []byte{1, 1 << 1, 0, 1 << 1, 1, 1 << 1, 0}
+	zvals, err := compress(c, nil, vals)
+	require.NoError(t, err)
+	w.structBegin()
+	w.i32Field(1, int32(pageDataV2))
+	w.i32Field(2, int32(len(levels)+len(vals)))
+	w.i32Field(3, int32(len(levels)+len(zvals)))
+	w.field(8, tStruct)
+	w.structBegin()
+	w.i32Field(1, 5)
+	w.i32Field(2, 2)
+	w.i32Field(3, 5)
+	w.i32Field(4, int32(EncodingRLEDictionary))
+	w.i32Field(5, int32(len(levels)))
+	w.i32Field(6, 0)
+	w.structEnd()
+	w.structEnd()
+	w.buf = append(w.buf, levels...)
+	w.buf = append(w.buf, zvals...)
+	return w.buf
+}
+
+func TestDictionaryPage(t *testing.T) {
+	s := schema.MustSchemaOf(struct {
+		Id  uint64 `knox:"id,pk"`
+		Val *int64 `knox:"val"`
+	}{})
+	e := SchemaElement{
+		Name:       "val",
+		Type:       TypeInt64,
+		HasType:    true,
+		Repetition: Optional,
+	}
+	for _, c := range []Compression{CompressionNone, CompressionSnappy} {
+		t.Run(c.String(), func(t *testing.T) {
+			chunk := writeChunk(t, c)
+			r := &Reader{
+				r:      bytes.NewReader(chunk),
+				leaves: []SchemaElement{e},
+				names:  map[string]int{"val": 0},
+				meta: FileMetaData{
+					RowGroups: []RowGroup{{
+						NumRows: 5,
+						Columns: []ColumnChunk{{
+							Meta: ColumnMetaData{
+								Type:                 TypeInt64,
+								Codec:                c,
+								NumValues:            5,
+								TotalCompressedSize:  int64(len(chunk)),
+								DictionaryPageOffset: 0,
+								DataPageOffset:       0,
+							},
+						}},
+					}},
+				},
+			}
+			pkg := pack.New().WithSchema(s).WithMaxRows(5).Alloc()
+			defer pkg.Release()
+			n, err := r.ReadRowGroup(0, pkg)
+			require.NoError(t, err)
+			require.Equal(t, 5, n)
+			require.Equal(t, 5, pkg.Len())
+
+			b := pkg.Block(1)
+			for i, v := range []int64{1, 0, 2, 1, 0} {
+				require.Equal(t, v, b.Int64().Get(i), "row %d", i)
+				require.Equal(t, v == 0, b.IsNull(i), "row %d", i)
+			}
+			// missing id column is zero filled
+			require.Equal(t, uint64(0), pkg.Block(0).Uint64().Get(4))
+		})
+	}
+}
+
+func TestHybrid(t *testing.T) {
+	// rle run of 3x 5, bit-packed group of 8 values 0..7 with bit width 3
+	buf := []byte{3 << 1, 5, 1<<1 | 1, 0b10001000, 0b11000110, 0b11111010}
+	vals, err := decodeHybrid(nil, buf, 3, 11)
+	require.NoError(t, err)
+	require.Equal(t, []int32{5, 5, 5, 0, 1, 2, 3, 4, 5, 6, 7}, vals)
+
+	// truncated input
+	_, err = decodeHybrid(nil, buf[:4], 3, 11)
+	require.Error(t, err)
+
+	// levels round trip
+	levels := []byte{1, 1, 1, 0, 0, 1, 0}
+	vals, err = decodeHybrid(nil, appendLevels(nil, levels), 1, len(levels))
+	require.NoError(t, err)
+	for i, v := range levels {
+		require.Equal(t, int32(v), vals[i])
+	}
+}
+
+func TestThrift(t *testing.T) {
+	meta := FileMetaData{
+		Version: 1,
+		NumRows: 3,
+		Schema: []SchemaElement{
+			{Name: "schema", NumChildren: 2},
+			{Name: "a", Type: TypeInt64, HasType: true, Repetition: Optional,
+				Logical: LogicalType{Kind: LogicalTimestamp, Unit: UnitMicros, UTC: true}},
+			{Name: "b", Type: TypeFixedLenByteArray, HasType: true, TypeLength: 16,
+				Scale: 4, Precision: 38, ConvertedType: ConvertedDecimal, HasConverted: true,
+				Logical: LogicalType{Kind: LogicalDecimal, Scale: 4, Precision: 38}},
+		},
+		RowGroups: []RowGroup{{
+			NumRows:       3,
+			TotalByteSize: 100,
+			Columns: []ColumnChunk{{
+				FileOffset: 4,
+				Meta: ColumnMetaData{
+					Type:                  TypeInt64,
+					Encodings:             []Encoding{EncodingPlain, EncodingRLE},
+					Path:                  []string{"a"},
+					Codec:                 CompressionZstd,
+					NumValues:             3,
+					TotalUncompressedSize: 50,
+					TotalCompressedSize:   40,
+					DataPageOffset:        4,
+					Statistics:            &Statistics{NullCount: 1, Min: []byte{1}, Max: []byte{2}},
+				},
+			}},
+			Sorting: []SortingColumn{{ColumnIdx: 0}},
+		}},
+		KeyValues: []KeyValue{{"k", "v"}},
+		CreatedBy: createdBy,
+	}
+	var w thriftWriter
+	meta.encode(&w)
+
+	var got FileMetaData
+	require.NoError(t, got.decode(&thriftReader{buf: w.buf}))
+	require.Equal(t, meta, got)
+
+	// truncated input must fail
+	for i := range w.buf {
+		var m FileMetaData
+		require.Error(t, m.decode(&thriftReader{buf: w.buf[:i]}))
+	}
+}
@@ -0,0 +1,543 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"blockwatch.cc/knoxdb/internal/block"
+	"blockwatch.cc/knoxdb/internal/pack"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/num"
+	"blockwatch.cc/knoxdb/pkg/schema"
+)
+
+// max accepted footer size
+const maxFooterSize = 64 << 20
+
+// Reader reads Parquet files with flat schemas into packs. Row groups
+// are decoded one at a time. Supported are PLAIN, dictionary and RLE
+// boolean encodings in v1 and v2 data pages.
+type Reader struct {
+	r      io.ReaderAt
+	meta   FileMetaData
+	leaves []SchemaElement
+	names  map[string]int // leaf column index by name
+	s      *schema.Schema
+	col    column
+	dict   column
+	levels []int32
+	idx    []int32
+}
+
+// NewReader reads the file footer from r and infers a knox schema from
+// the Parquet schema. Files with nested or repeated columns are rejected.
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+	if size < int64(2*len(magic)+4) {
+		return nil, ErrInvalidFile
+	}
+	var tail [8]byte
+	if _, err := r.ReadAt(tail[:], size-8); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(tail[4:], magic) {
+		return nil, ErrInvalidFile
+	}
+	n := int64(binary.LittleEndian.Uint32(tail[:]))
+	if n > maxFooterSize || n > size-int64(2*len(magic)+4) {
+		return nil, ErrInvalidFile
+	}
+	buf := make([]byte, n)
+	if _, err := r.ReadAt(buf, size-8-n); err != nil {
+		return nil, err
+	}
+
+	pr := &Reader{
+		r:     r,
+		names: make(map[string]int),
+	}
+	if err := pr.meta.decode(&thriftReader{buf: buf}); err != nil {
+		return nil, fmt.Errorf("parquet: reading footer: %w", err)
+	}
+	if len(pr.meta.Schema) == 0 {
+		return nil, ErrInvalidFile
+	}
+	for _, e := range pr.meta.Schema[1:] {
+		if e.NumChildren > 0 || e.Repetition == Repeated || !e.HasType {
+			return nil, fmt.Errorf("%w: nested column %s", ErrUnsupportedType, e.Name)
+		}
+		pr.names[e.Name] = len(pr.leaves)
+		pr.leaves = append(pr.leaves, e)
+	}
+	for _, g := range pr.meta.RowGroups {
+		if len(g.Columns) != len(pr.leaves) {
+			return nil, ErrInvalidFile
+		}
+	}
+
+	s, err := inferSchema(&pr.meta, pr.leaves)
+	if err != nil {
+		return nil, err
+	}
+	pr.s = s
+	return pr, nil
+}
+
+// Schema returns the knox schema matching the file's columns.
+func (r *Reader) Schema() *schema.Schema {
+	return r.s
+}
+
+func (r *Reader) NumRows() int64 {
+	return r.meta.NumRows
+}
+
+func (r *Reader) NumRowGroups() int {
+	return len(r.meta.RowGroups)
+}
+
+// RowGroupLen returns the number of rows in row group i.
+func (r *Reader) RowGroupLen(i int) int {
+	return int(r.meta.RowGroups[i].NumRows)
+}
+
+// ReadRowGroup appends all rows of row group i to pkg. Columns are
+// matched to pack fields by name and converted to the field type. Meta
+// fields and fields without a matching column are filled with zero values
+// (or NULL for nullable fields). Returns the number of rows read.
+func (r *Reader) ReadRowGroup(i int, pkg *pack.Package) (int, error) {
+	if i < 0 || i >= len(r.meta.RowGroups) {
+		return 0, fmt.Errorf("parquet: row group %d out of range", i)
+	}
+	g := &r.meta.RowGroups[i]
+	n := int(g.NumRows)
+	if n < 0 {
+		return 0, ErrInvalidFile
+	}
+	for k, f := range pkg.Schema().Fields {
+		b := pkg.Block(k)
+		if b == nil {
+			continue
+		}
+		base := b.Len()
+		j, ok := r.names[f.Name]
+		if f.IsMeta() || !ok {
+			for row := range n {
+				appendZero(b, f)
+				if f.IsNullable() && !f.IsMeta() {
+					b.SetNull(base + row)
+				}
+			}
+			b.SetDirty()
+			continue
+		}
+		e := &r.leaves[j]
+		if err := r.readColumn(&g.Columns[j], e, n); err != nil {
+			return 0, fmt.Errorf("parquet: column %s: %w", e.Name, err)
+		}
+		if err := appendColumn(b, f, e, &r.col, base); err != nil {
+			return 0, fmt.Errorf("parquet: column %s: %w", e.Name, err)
+		}
+		b.SetDirty()
+	}
+	pkg.UpdateLen()
+	return n, nil
+}
+
+// readColumn decodes all pages of column chunk cc.
+func (r *Reader) readColumn(cc *ColumnChunk, e *SchemaElement, n int) error {
+	m := &cc.Meta
+	r.col.reset(e.Type)
+	r.dict.reset(e.Type)
+
+	start := m.DataPageOffset
+	if m.DictionaryPageOffset > 0 && m.DictionaryPageOffset < start {
+		start = m.DictionaryPageOffset
+	}
+	if start < 0 || m.TotalCompressedSize < 0 || m.TotalCompressedSize > math.MaxInt32 {
+		return ErrInvalidFile
+	}
+
+	// decoded byte array values reference page buffers, so we use
+	// fresh buffers per column chunk and page
+	buf := make([]byte, m.TotalCompressedSize)
+	if _, err := r.r.ReadAt(buf, start); err != nil {
+		return err
+	}
+
+	var pos int
+	for r.col.n < n && pos < len(buf) {
+		var h pageHeader
+		tr := thriftReader{buf: buf[pos:]}
+		if err := h.decode(&tr); err != nil {
+			return err
+		}
+		pos += tr.pos
+		if h.CompressedSize < 0 || h.UncompressedSize < 0 || int(h.CompressedSize) > len(buf)-pos {
+			return ErrInvalidFile
+		}
+		page := buf[pos : pos+int(h.CompressedSize)]
+		pos += int(h.CompressedSize)
+
+		var err error
+		switch h.Type {
+		case pageDictionary:
+			err = r.readDictPage(&h, page, m.Codec, e)
+		case pageData:
+			err = r.readDataPage(&h, page, m.Codec, e)
+		case pageDataV2:
+			err = r.readDataPageV2(&h, page, m.Codec, e)
+		default:
+			// skip index pages
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if r.col.n != n {
+		return fmt.Errorf("%w: expected %d values, got %d", ErrInvalidFile, n, r.col.n)
+	}
+	return nil
+}
+
+func (r *Reader) readDictPage(h *pageHeader, page []byte, c Compression, e *SchemaElement) error {
+	data, err := decompress(c, nil, page, int(h.UncompressedSize))
+	if err != nil {
+		return err
+	}
+	if h.Dict.Encoding != EncodingPlain && h.Dict.Encoding != EncodingPlainDictionary {
+		return fmt.Errorf("%w dictionary encoding %d", ErrUnsupportedType, h.Dict.Encoding)
+	}
+	r.dict.reset(e.Type)
+	return r.dict.decodePlain(data, int(h.Dict.NumValues), int(e.TypeLength))
+}
+
+func (r *Reader) readDataPage(h *pageHeader, page []byte, c Compression, e *SchemaElement) error {
+	data, err := decompress(c, nil, page, int(h.UncompressedSize))
+	if err != nil {
+		return err
+	}
+	n := int(h.Data.NumValues)
+	nvals := n
+	if e.Repetition == Optional {
+		if len(data) < 4 {
+			return ErrInvalidFile
+		}
+		l := int(binary.LittleEndian.Uint32(data))
+		if l < 0 || l > len(data)-4 {
+			return ErrInvalidFile
+		}
+		if nvals, err = r.readLevels(data[4:4+l], n); err != nil {
+			return err
+		}
+		data = data[4+l:]
+	}
+	r.col.n += n
+	return r.readValues(data, h.Data.Encoding, nvals, e)
+}
+
+func (r *Reader) readDataPageV2(h *pageHeader, page []byte, c Compression, e *SchemaElement) error {
+	d := &h.DataV2
+	ll := int(d.DefLevelsLen) + int(d.RepLevelsLen)
+	if d.DefLevelsLen < 0 || d.RepLevelsLen < 0 || ll > len(page) {
+		return ErrInvalidFile
+	}
+	n := int(d.NumValues)
+	nvals := n
+	if e.Repetition == Optional {
+		var err error
+		levels := page[d.RepLevelsLen:ll]
+		if nvals, err = r.readLevels(levels, n); err != nil {
+			return err
+		}
+	}
+	data := page[ll:]
+	if d.Compressed {
+		var err error
+		data, err = decompress(c, nil, data, int(h.UncompressedSize)-ll)
+		if err != nil {
+			return err
+		}
+	}
+	r.col.n += n
+	return r.readValues(data, d.Encoding, nvals, e)
+}
+
+// readLevels decodes n definition levels into the column validity and
+// returns the number of non-null values.
+func (r *Reader) readLevels(buf []byte, n int) (int, error) {
+	var err error
+	r.levels, err = decodeHybrid(r.levels[:0], buf, 1, n)
+	if err != nil {
+		return 0, err
+	}
+	// backfill validity of earlier pages without nulls
+	for len(r.col.valid) < r.col.n {
+		r.col.valid = append(r.col.valid, true)
+	}
+	var nvals int
+	for _, l := range r.levels {
+		r.col.valid = append(r.col.valid, l == 1)
+		nvals += int(l & 1)
+	}
+	return nvals, nil
+}
+
+func (r *Reader) readValues(buf []byte, enc Encoding, n int, e *SchemaElement) error {
+	switch enc {
+	case EncodingPlain:
+		return r.col.decodePlain(buf, n, int(e.TypeLength))
+	case EncodingPlainDictionary, EncodingRLEDictionary:
+		if n == 0 {
+			return nil
+		}
+		if len(buf) == 0 {
+			return ErrInvalidFile
+		}
+		var err error
+		r.idx, err = decodeHybrid(r.idx[:0], buf[1:], int(buf[0]), n)
+		if err != nil {
+			return err
+		}
+		return r.col.appendFrom(&r.dict, r.idx)
+	case EncodingRLE:
+		if e.Type != TypeBoolean || len(buf) < 4 {
+			return ErrInvalidFile
+		}
+		l := int(binary.LittleEndian.Uint32(buf))
+		if l < 0 || l > len(buf)-4 {
+			return ErrInvalidFile
+		}
+		var err error
+		r.idx, err = decodeHybrid(r.idx[:0], buf[4:4+l], 1, n)
+		if err != nil {
+			return err
+		}
+		for _, v := range r.idx {
+			r.col.b = append(r.col.b, v != 0)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w encoding %d", ErrUnsupportedType, enc)
+	}
+}
+
+// appendZero appends the zero value of field f to block b.
+func appendZero(b *block.Block, f *schema.Field) {
+	switch b.Type() {
+	case types.BlockUint64, types.BlockInt64, types.BlockFloat64:
+		b.Uint64().Append(0)
+	case types.BlockUint32, types.BlockInt32, types.BlockFloat32:
+		b.Uint32().Append(0)
+	case types.BlockUint16, types.BlockInt16:
+		b.Uint16().Append(0)
+	case types.BlockUint8, types.BlockInt8:
+		b.Uint8().Append(0)
+	case types.BlockBool:
+		b.Bool().Append(false)
+	case types.BlockBytes, types.BlockBigint:
+		b.Bytes().Append(make([]byte, f.Fixed))
+	case types.BlockInt256:
+		b.Int256().Append(num.ZeroInt256)
+	case types.BlockInt128:
+		b.Int128().Append(num.ZeroInt128)
+	}
+}
+
+// appendColumn converts decoded column values to the type of field f and
+// appends them to block b starting at row base.
+func appendColumn(b *block.Block, f *schema.Field, e *SchemaElement, c *column, base int) error {
+	var (
+		j        int
+		l        = e.logical()
+		unsigned = l.Kind == LogicalInteger && !l.Signed
+		isTime   = f.Type == types.FieldTypeTimestamp || f.Type == types.FieldTypeDate || f.Type == types.FieldTypeTime
+		isEnum   = f.Is(types.FieldFlagEnum)
+		isBin    = e.Type == TypeByteArray || e.Type == TypeFixedLenByteArray
+		from, to int64
+	)
+	if isEnum && f.Enum == nil {
+		return schema.ErrEnumUndefined
+	}
+	if isTime {
+		from, to = e.timeNanos(), schema.TimeScale(f.Scale).Nanos()
+	}
+	if l.Kind == LogicalDecimal && l.Scale != int32(f.Scale) {
+		switch f.Type {
+		case types.FieldTypeDecimal32, types.FieldTypeDecimal64,
+			types.FieldTypeDecimal128, types.FieldTypeDecimal256:
+			return fmt.Errorf("decimal scale %d does not match field scale %d", l.Scale, f.Scale)
+		}
+	}
+
+	for row := range c.n {
+		if len(c.valid) > 0 && !c.valid[row] {
+			appendZero(b, f)
+			if f.IsNullable() {
+				b.SetNull(base + row)
+			}
+			continue
+		}
+
+		// load source value
+		var (
+			iv  int64
+			fv  float64
+			bv  []byte
+			isF bool
+		)
+		switch c.typ {
+		case TypeBoolean:
+			if c.b[j] {
+				iv = 1
+			}
+		case TypeInt32:
+			if unsigned {
+				iv = int64(uint32(c.i32[j]))
+			} else {
+				iv = int64(c.i32[j])
+			}
+		case TypeInt64, TypeInt96:
+			iv = c.i64[j]
+		case TypeFloat:
+			fv, isF = float64(c.f32[j]), true
+		case TypeDouble:
+			fv, isF = c.f64[j], true
+		default:
+			bv = c.bin[j]
+		}
+		j++
+		if isF {
+			iv = int64(fv)
+		} else if !isBin {
+			fv = float64(iv)
+		}
+
+		// convert into target type
+		if isEnum {
+			if !isBin {
+				return fmt.Errorf("%w %s for enum field", ErrUnsupportedType, c.typ)
+			}
+			code, ok := f.Enum.Code32(string(bv))
+			if !ok {
+				return fmt.Errorf("undefined enum value %q", bv)
+			}
+			if f.Type == types.FieldTypeUint16 {
+				b.Uint16().Append(uint16(code))
+			} else {
+				b.Uint32().Append(code)
+			}
+			continue
+		}
+
+		switch f.Type {
+		case types.FieldTypeString, types.FieldTypeBytes, types.FieldTypeBigint:
+			if !isBin {
+				return fmt.Errorf("%w %s for %s field", ErrUnsupportedType, c.typ, f.Type)
+			}
+			if f.Fixed > 0 && len(bv) != int(f.Fixed) {
+				return fmt.Errorf("invalid value length %d for fixed length %d", len(bv), f.Fixed)
+			}
+			b.Bytes().Append(bv)
+			continue
+		case types.FieldTypeInt128, types.FieldTypeDecimal128:
+			if !isBin {
+				b.Int128().Append(num.Int128FromInt64(iv))
+				continue
+			}
+			var x [16]byte
+			if err := signExtend(x[:], bv); err != nil {
+				return err
+			}
+			b.Int128().Append(num.Int128FromBytes(x[:]))
+			continue
+		case types.FieldTypeInt256, types.FieldTypeDecimal256:
+			if !isBin {
+				b.Int256().Append(num.Int256FromInt64(iv))
+				continue
+			}
+			var x [32]byte
+			if err := signExtend(x[:], bv); err != nil {
+				return err
+			}
+			b.Int256().Append(num.Int256FromBytes(x[:]))
+			continue
+		}
+
+		// remaining targets are numeric
+		if isBin {
+			if l.Kind != LogicalDecimal {
+				return fmt.Errorf("%w %s for %s field", ErrUnsupportedType, c.typ, f.Type)
+			}
+			var x [8]byte
+			if err := signExtend(x[:], bv); err != nil {
+				return err
+			}
+			iv = int64(binary.BigEndian.Uint64(x[:]))
+			fv = float64(iv)
+		}
+		switch f.Type {
+		case types.FieldTypeTimestamp, types.FieldTypeDate, types.FieldTypeTime:
+			b.Int64().Append(schema.RescaleTime(iv, from, to))
+		case types.FieldTypeInt64, types.FieldTypeDecimal64:
+			b.Int64().Append(iv)
+		case types.FieldTypeInt32, types.FieldTypeDecimal32:
+			b.Int32().Append(int32(iv))
+		case types.FieldTypeInt16:
+			b.Int16().Append(int16(iv))
+		case types.FieldTypeInt8:
+			b.Int8().Append(int8(iv))
+		case types.FieldTypeUint64:
+			b.Uint64().Append(uint64(iv))
+		case types.FieldTypeUint32:
+			b.Uint32().Append(uint32(iv))
+		case types.FieldTypeUint16:
+			b.Uint16().Append(uint16(iv))
+		case types.FieldTypeUint8:
+			b.Uint8().Append(uint8(iv))
+		case types.FieldTypeFloat64:
+			b.Float64().Append(fv)
+		case types.FieldTypeFloat32:
+			b.Float32().Append(float32(fv))
+		case types.FieldTypeBoolean:
+			b.Bool().Append(iv != 0 || fv != 0)
+		default:
+			return fmt.Errorf("%w %s", ErrUnsupportedType, f.Type)
+		}
+	}
+	return nil
+}
+
+// signExtend copies big-endian two's complement value src into the tail
+// of dst and fills leading bytes with its sign.
+func signExtend(dst, src []byte) error {
+	if len(src) > len(dst) {
+		// accept wider values when the leading bytes are sign extension
+		ext := len(src) - len(dst)
+		fill := byte(0)
+		if src[ext]&0x80 != 0 {
+			fill = 0xff
+		}
+		for _, v := range src[:ext] {
+			if v != fill {
+				return fmt.Errorf("value overflows %d bytes", len(dst))
+			}
+		}
+		src = src[ext:]
+	}
+	var fill byte
+	if len(src) > 0 && src[0]&0x80 != 0 {
+		fill = 0xff
+	}
+	pad := len(dst) - len(src)
+	for i := range pad {
+		dst[i] = fill
+	}
+	copy(dst[pad:], src)
+	return nil
+}
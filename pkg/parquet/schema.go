@@ -0,0 +1,379 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package parquet
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/num"
+	"blockwatch.cc/knoxdb/pkg/schema"
+)
+
+var (
+	ErrUnsupportedType = errors.New("parquet: unsupported type")
+	ErrInvalidFile     = errors.New("parquet: invalid file")
+	errExternalColumn  = errors.New("parquet: external column chunks are unsupported")
+)
+
+// key of the key-value metadata entry carrying the original knox schema
+const schemaKey = "knox.schema"
+
+// nanoseconds per Parquet time unit
+var unitNanos = [...]int64{
+	UnitNone:   0,
+	UnitMillis: 1000000,
+	UnitMicros: 1000,
+	UnitNanos:  1,
+}
+
+// timeUnit returns the Parquet time unit able to represent knox time scale
+// s without loss. Seconds are stored as milliseconds.
+func timeUnit(s uint8) TimeUnit {
+	switch schema.TimeScale(s) {
+	case schema.TIME_SCALE_NANO:
+		return UnitNanos
+	case schema.TIME_SCALE_MICRO:
+		return UnitMicros
+	default:
+		return UnitMillis
+	}
+}
+
+func timeScale(u TimeUnit) uint8 {
+	switch u {
+	case UnitNanos:
+		return uint8(schema.TIME_SCALE_NANO)
+	case UnitMicros:
+		return uint8(schema.TIME_SCALE_MICRO)
+	default:
+		return uint8(schema.TIME_SCALE_MILLI)
+	}
+}
+
+func intLogical(width int8, signed bool) LogicalType {
+	return LogicalType{Kind: LogicalInteger, BitWidth: width, Signed: signed}
+}
+
+func decimalLogical(scale uint8, prec int) LogicalType {
+	return LogicalType{Kind: LogicalDecimal, Scale: int32(scale), Precision: int32(prec)}
+}
+
+// element maps a knox schema field to a Parquet leaf column.
+func element(f *schema.Field) (SchemaElement, error) {
+	e := SchemaElement{
+		Name:       f.Name,
+		HasType:    true,
+		Repetition: Required,
+	}
+	if f.IsNullable() {
+		e.Repetition = Optional
+	}
+	if f.Is(types.FieldFlagEnum) {
+		e.Type = TypeByteArray
+		e.Logical = LogicalType{Kind: LogicalEnum}
+		e.ConvertedType, e.HasConverted = ConvertedEnum, true
+		return e, nil
+	}
+
+	switch f.Type {
+	case types.FieldTypeTimestamp:
+		e.Type = TypeInt64
+		e.Logical = LogicalType{Kind: LogicalTimestamp, UTC: true, Unit: timeUnit(f.Scale)}
+		switch e.Logical.Unit {
+		case UnitMillis:
+			e.ConvertedType, e.HasConverted = ConvertedTimestampMillis, true
+		case UnitMicros:
+			e.ConvertedType, e.HasConverted = ConvertedTimestampMicros, true
+		}
+	case types.FieldTypeDate:
+		e.Type = TypeInt32
+		e.Logical = LogicalType{Kind: LogicalDate}
+		e.ConvertedType, e.HasConverted = ConvertedDate, true
+	case types.FieldTypeTime:
+		e.Logical = LogicalType{Kind: LogicalTime, UTC: true, Unit: timeUnit(f.Scale)}
+		switch e.Logical.Unit {
+		case UnitMillis:
+			e.Type = TypeInt32
+			e.ConvertedType, e.HasConverted = ConvertedTimeMillis, true
+		case UnitMicros:
+			e.Type = TypeInt64
+			e.ConvertedType, e.HasConverted = ConvertedTimeMicros, true
+		default:
+			e.Type = TypeInt64
+		}
+	case types.FieldTypeInt64:
+		e.Type = TypeInt64
+	case types.FieldTypeInt32:
+		e.Type = TypeInt32
+	case types.FieldTypeInt16:
+		e.Type = TypeInt32
+		e.Logical = intLogical(16, true)
+		e.ConvertedType, e.HasConverted = ConvertedInt16, true
+	case types.FieldTypeInt8:
+		e.Type = TypeInt32
+		e.Logical = intLogical(8, true)
+		e.ConvertedType, e.HasConverted = ConvertedInt8, true
+	case types.FieldTypeUint64:
+		e.Type = TypeInt64
+		e.Logical = intLogical(64, false)
+		e.ConvertedType, e.HasConverted = ConvertedUint64, true
+	case types.FieldTypeUint32:
+		e.Type = TypeInt32
+		e.Logical = intLogical(32, false)
+		e.ConvertedType, e.HasConverted = ConvertedUint32, true
+	case types.FieldTypeUint16:
+		e.Type = TypeInt32
+		e.Logical = intLogical(16, false)
+		e.ConvertedType, e.HasConverted = ConvertedUint16, true
+	case types.FieldTypeUint8:
+		e.Type = TypeInt32
+		e.Logical = intLogical(8, false)
+		e.ConvertedType, e.HasConverted = ConvertedUint8, true
+	case types.FieldTypeFloat64:
+		e.Type = TypeDouble
+	case types.FieldTypeFloat32:
+		e.Type = TypeFloat
+	case types.FieldTypeBoolean:
+		e.Type = TypeBoolean
+	case types.FieldTypeString:
+		e.Type = TypeByteArray
+		e.Logical = LogicalType{Kind: LogicalString}
+		e.ConvertedType, e.HasConverted = ConvertedUTF8, true
+	case types.FieldTypeBytes:
+		e.Type = TypeByteArray
+		if f.Fixed > 0 {
+			e.Type = TypeFixedLenByteArray
+			e.TypeLength = int32(f.Fixed)
+		}
+		if f.Logical() == schema.LT_UUID {
+			e.Logical = LogicalType{Kind: LogicalUUID}
+		}
+	case types.FieldTypeBigint:
+		e.Type = TypeByteArray
+	case types.FieldTypeDecimal32:
+		e.Type = TypeInt32
+		e.Logical = decimalLogical(f.Scale, num.MaxDecimal32Precision)
+	case types.FieldTypeDecimal64:
+		e.Type = TypeInt64
+		e.Logical = decimalLogical(f.Scale, num.MaxDecimal64Precision)
+	case types.FieldTypeDecimal128:
+		e.Type, e.TypeLength = TypeFixedLenByteArray, 16
+		e.Logical = decimalLogical(f.Scale, num.MaxDecimal128Precision)
+	case types.FieldTypeDecimal256:
+		e.Type, e.TypeLength = TypeFixedLenByteArray, 32
+		e.Logical = decimalLogical(f.Scale, num.MaxDecimal256Precision)
+	case types.FieldTypeInt128:
+		e.Type, e.TypeLength = TypeFixedLenByteArray, 16
+		e.Logical = decimalLogical(0, num.MaxDecimal128Precision)
+	case types.FieldTypeInt256:
+		e.Type, e.TypeLength = TypeFixedLenByteArray, 32
+		e.Logical = decimalLogical(0, num.MaxDecimal256Precision)
+	default:
+		return e, fmt.Errorf("%w %s for field %s", ErrUnsupportedType, f.Type, f.Name)
+	}
+	if e.Logical.Kind == LogicalDecimal {
+		e.Scale, e.Precision = e.Logical.Scale, e.Logical.Precision
+		e.ConvertedType, e.HasConverted = ConvertedDecimal, true
+	}
+	return e, nil
+}
+
+// logical returns the element's logical type, translating legacy
+// converted type annotations when no logical type is present.
+func (e *SchemaElement) logical() LogicalType {
+	if e.Logical.Kind != LogicalNone || !e.HasConverted {
+		return e.Logical
+	}
+	switch e.ConvertedType {
+	case ConvertedUTF8, ConvertedJSON:
+		return LogicalType{Kind: LogicalString}
+	case ConvertedEnum:
+		return LogicalType{Kind: LogicalEnum}
+	case ConvertedDecimal:
+		return LogicalType{Kind: LogicalDecimal, Scale: e.Scale, Precision: e.Precision}
+	case ConvertedDate:
+		return LogicalType{Kind: LogicalDate}
+	case ConvertedTimeMillis:
+		return LogicalType{Kind: LogicalTime, UTC: true, Unit: UnitMillis}
+	case ConvertedTimeMicros:
+		return LogicalType{Kind: LogicalTime, UTC: true, Unit: UnitMicros}
+	case ConvertedTimestampMillis:
+		return LogicalType{Kind: LogicalTimestamp, UTC: true, Unit: UnitMillis}
+	case ConvertedTimestampMicros:
+		return LogicalType{Kind: LogicalTimestamp, UTC: true, Unit: UnitMicros}
+	case ConvertedUint8:
+		return intLogical(8, false)
+	case ConvertedUint16:
+		return intLogical(16, false)
+	case ConvertedUint32:
+		return intLogical(32, false)
+	case ConvertedUint64:
+		return intLogical(64, false)
+	case ConvertedInt8:
+		return intLogical(8, true)
+	case ConvertedInt16:
+		return intLogical(16, true)
+	case ConvertedInt32:
+		return intLogical(32, true)
+	case ConvertedInt64:
+		return intLogical(64, true)
+	}
+	return e.Logical
+}
+
+// timeNanos returns the number of nanoseconds per stored time value or
+// zero when the column carries no time unit.
+func (e *SchemaElement) timeNanos() int64 {
+	if e.Type == TypeInt96 {
+		return 1
+	}
+	l := e.logical()
+	switch l.Kind {
+	case LogicalDate:
+		return schema.TIME_SCALE_DAY.Nanos()
+	case LogicalTime, LogicalTimestamp:
+		if l.Unit <= UnitNanos {
+			return unitNanos[l.Unit]
+		}
+	}
+	return 0
+}
+
+// addField appends a knox field matching Parquet column e to builder b.
+func addField(b *schema.Builder, e *SchemaElement) error {
+	var opts []schema.BuilderOption
+	if e.Repetition == Optional {
+		opts = append(opts, schema.Nullable())
+	}
+	l := e.logical()
+	switch e.Type {
+	case TypeBoolean:
+		b.Bool(e.Name, opts...)
+	case TypeInt32:
+		switch l.Kind {
+		case LogicalDate:
+			b.Date(e.Name, append(opts, schema.Scale(uint8(schema.TIME_SCALE_DAY)))...)
+		case LogicalTime:
+			b.Time(e.Name, append(opts, schema.Scale(timeScale(l.Unit)))...)
+		case LogicalDecimal:
+			b.Decimal32(e.Name, append(opts, schema.Scale(int(l.Scale)))...)
+		case LogicalInteger:
+			switch {
+			case l.BitWidth == 8 && l.Signed:
+				b.Int8(e.Name, opts...)
+			case l.BitWidth == 16 && l.Signed:
+				b.Int16(e.Name, opts...)
+			case l.BitWidth == 8:
+				b.Uint8(e.Name, opts...)
+			case l.BitWidth == 16:
+				b.Uint16(e.Name, opts...)
+			case !l.Signed:
+				b.Uint32(e.Name, opts...)
+			default:
+				b.Int32(e.Name, opts...)
+			}
+		default:
+			b.Int32(e.Name, opts...)
+		}
+	case TypeInt64:
+		switch l.Kind {
+		case LogicalTimestamp:
+			b.Timestamp(e.Name, append(opts, schema.Scale(timeScale(l.Unit)))...)
+		case LogicalTime:
+			b.Time(e.Name, append(opts, schema.Scale(timeScale(l.Unit)))...)
+		case LogicalDecimal:
+			b.Decimal64(e.Name, append(opts, schema.Scale(int(l.Scale)))...)
+		case LogicalInteger:
+			if !l.Signed {
+				b.Uint64(e.Name, opts...)
+			} else {
+				b.Int64(e.Name, opts...)
+			}
+		default:
+			b.Int64(e.Name, opts...)
+		}
+	case TypeInt96:
+		b.Timestamp(e.Name, append(opts, schema.Scale(uint8(schema.TIME_SCALE_NANO)))...)
+	case TypeFloat:
+		b.Float32(e.Name, opts...)
+	case TypeDouble:
+		b.Float64(e.Name, opts...)
+	case TypeByteArray, TypeFixedLenByteArray:
+		switch l.Kind {
+		case LogicalString, LogicalEnum, LogicalJSON:
+			b.String(e.Name, opts...)
+		case LogicalDecimal:
+			if l.Precision <= num.MaxDecimal128Precision {
+				b.Decimal128(e.Name, append(opts, schema.Scale(int(l.Scale)))...)
+			} else {
+				b.Decimal256(e.Name, append(opts, schema.Scale(int(l.Scale)))...)
+			}
+		case LogicalUUID:
+			b.UUID(e.Name, opts...)
+		default:
+			if e.Type == TypeFixedLenByteArray {
+				opts = append(opts, schema.Fixed(int(e.TypeLength)))
+			}
+			b.Bytes(e.Name, opts...)
+		}
+	default:
+		return fmt.Errorf("%w %s for column %s", ErrUnsupportedType, e.Type, e.Name)
+	}
+	return nil
+}
+
+// inferSchema creates a knox schema from Parquet leaf columns. Files
+// written by this package carry the original schema which is used
+// when all its visible fields have a matching column.
+func inferSchema(meta *FileMetaData, leaves []SchemaElement) (*schema.Schema, error) {
+	for _, kv := range meta.KeyValues {
+		if kv.Key != schemaKey {
+			continue
+		}
+		buf, err := base64.StdEncoding.DecodeString(kv.Value)
+		if err != nil {
+			break
+		}
+		s := schema.NewSchema()
+		if err := s.UnmarshalBinary(buf); err != nil {
+			break
+		}
+		if matchesColumns(s, leaves) {
+			return s, nil
+		}
+	}
+
+	name := "parquet"
+	if len(meta.Schema) > 0 && meta.Schema[0].Name != "" {
+		name = meta.Schema[0].Name
+	}
+	b := schema.NewBuilder().WithName(name)
+	for i := range leaves {
+		if err := addField(b, &leaves[i]); err != nil {
+			return nil, err
+		}
+	}
+	s := b.Finalize().Schema()
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func matchesColumns(s *schema.Schema, leaves []SchemaElement) bool {
+	var i int
+	for _, f := range s.Fields {
+		if !f.IsVisible() {
+			continue
+		}
+		if i >= len(leaves) || leaves[i].Name != f.Name {
+			return false
+		}
+		i++
+	}
+	return i == len(leaves)
+}
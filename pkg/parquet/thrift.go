@@ -0,0 +1,293 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Minimal Thrift compact protocol codec for Parquet metadata.
+// See https://github.com/apache/thrift/blob/master/doc/specs/thrift-compact-protocol.md
+
+const (
+	tStop   byte = 0
+	tTrue   byte = 1
+	tFalse  byte = 2
+	tByte   byte = 3
+	tI16    byte = 4
+	tI32    byte = 5
+	tI64    byte = 6
+	tDouble byte = 7
+	tBinary byte = 8
+	tList   byte = 9
+	tSet    byte = 10
+	tMap    byte = 11
+	tStruct byte = 12
+)
+
+const maxThriftDepth = 64
+
+var errShortThrift = errors.New("parquet: short thrift buffer")
+
+// thriftWriter appends compact protocol encoded values to buf.
+type thriftWriter struct {
+	buf  []byte
+	last []int16 // last field id per nested struct
+}
+
+func (w *thriftWriter) uvarint(v uint64) {
+	w.buf = binary.AppendUvarint(w.buf, v)
+}
+
+func (w *thriftWriter) varint(v int64) {
+	w.buf = binary.AppendVarint(w.buf, v) // zigzag
+}
+
+func (w *thriftWriter) field(id int16, typ byte) {
+	last := w.last[len(w.last)-1]
+	if d := id - last; d > 0 && d <= 15 {
+		w.buf = append(w.buf, byte(d)<<4|typ)
+	} else {
+		w.buf = append(w.buf, typ)
+		w.varint(int64(id))
+	}
+	w.last[len(w.last)-1] = id
+}
+
+func (w *thriftWriter) structBegin() {
+	w.last = append(w.last, 0)
+}
+
+func (w *thriftWriter) structEnd() {
+	w.buf = append(w.buf, tStop)
+	w.last = w.last[:len(w.last)-1]
+}
+
+func (w *thriftWriter) listBegin(typ byte, n int) {
+	if n < 15 {
+		w.buf = append(w.buf, byte(n)<<4|typ)
+	} else {
+		w.buf = append(w.buf, 0xF0|typ)
+		w.uvarint(uint64(n))
+	}
+}
+
+func (w *thriftWriter) boolField(id int16, v bool) {
+	if v {
+		w.field(id, tTrue)
+	} else {
+		w.field(id, tFalse)
+	}
+}
+
+func (w *thriftWriter) i32Field(id int16, v int32) {
+	w.field(id, tI32)
+	w.varint(int64(v))
+}
+
+func (w *thriftWriter) i64Field(id int16, v int64) {
+	w.field(id, tI64)
+	w.varint(v)
+}
+
+func (w *thriftWriter) binaryField(id int16, v []byte) {
+	w.field(id, tBinary)
+	w.binary(v)
+}
+
+func (w *thriftWriter) binary(v []byte) {
+	w.uvarint(uint64(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+// emptyStructField writes a struct field without members, used for
+// Parquet union members like StringType.
+func (w *thriftWriter) emptyStructField(id int16) {
+	w.field(id, tStruct)
+	w.buf = append(w.buf, tStop)
+}
+
+// thriftReader decodes compact protocol encoded values from buf.
+type thriftReader struct {
+	buf   []byte
+	pos   int
+	depth int
+}
+
+func (r *thriftReader) byte() (byte, error) {
+	if r.pos >= len(r.buf) {
+		return 0, errShortThrift
+	}
+	b := r.buf[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *thriftReader) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		return 0, errShortThrift
+	}
+	r.pos += n
+	return v, nil
+}
+
+func (r *thriftReader) varint() (int64, error) {
+	v, n := binary.Varint(r.buf[r.pos:])
+	if n <= 0 {
+		return 0, errShortThrift
+	}
+	r.pos += n
+	return v, nil
+}
+
+func (r *thriftReader) i32() (int32, error) {
+	v, err := r.varint()
+	if err != nil {
+		return 0, err
+	}
+	if v < math.MinInt32 || v > math.MaxInt32 {
+		return 0, fmt.Errorf("parquet: thrift i32 overflow")
+	}
+	return int32(v), nil
+}
+
+func (r *thriftReader) binary() ([]byte, error) {
+	n, err := r.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(r.buf)-r.pos) < n {
+		return nil, errShortThrift
+	}
+	v := r.buf[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return v, nil
+}
+
+func (r *thriftReader) list() (byte, int, error) {
+	b, err := r.byte()
+	if err != nil {
+		return 0, 0, err
+	}
+	n := int(b >> 4)
+	if n == 15 {
+		v, err := r.uvarint()
+		if err != nil {
+			return 0, 0, err
+		}
+		if v > uint64(len(r.buf)) {
+			return 0, 0, errShortThrift
+		}
+		n = int(v)
+	}
+	return b & 0x0F, n, nil
+}
+
+// readStruct calls fn for each field of a struct. Field handlers read the
+// value or call skip. Boolean field values are encoded in typ.
+func (r *thriftReader) readStruct(fn func(id int16, typ byte) error) error {
+	if r.depth++; r.depth > maxThriftDepth {
+		return fmt.Errorf("parquet: thrift nesting too deep")
+	}
+	defer func() { r.depth-- }()
+	var last int16
+	for {
+		b, err := r.byte()
+		if err != nil {
+			return err
+		}
+		typ := b & 0x0F
+		if typ == tStop {
+			return nil
+		}
+		id := last + int16(b>>4)
+		if b>>4 == 0 {
+			v, err := r.varint()
+			if err != nil {
+				return err
+			}
+			id = int16(v)
+		}
+		last = id
+		if err := fn(id, typ); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *thriftReader) bool(typ byte) bool {
+	return typ == tTrue
+}
+
+// skip consumes a value of type typ.
+func (r *thriftReader) skip(typ byte) error {
+	switch typ {
+	case tTrue, tFalse:
+		return nil
+	case tByte:
+		_, err := r.byte()
+		return err
+	case tI16, tI32, tI64:
+		_, err := r.varint()
+		return err
+	case tDouble:
+		if len(r.buf)-r.pos < 8 {
+			return errShortThrift
+		}
+		r.pos += 8
+		return nil
+	case tBinary:
+		_, err := r.binary()
+		return err
+	case tList, tSet:
+		et, n, err := r.list()
+		if err != nil {
+			return err
+		}
+		for range n {
+			if et == tTrue || et == tFalse {
+				// list booleans are encoded as one byte
+				if _, err := r.byte(); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := r.skip(et); err != nil {
+				return err
+			}
+		}
+		return nil
+	case tMap:
+		n, err := r.uvarint()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		kv, err := r.byte()
+		if err != nil {
+			return err
+		}
+		for range n {
+			if err := r.skip(kv >> 4); err != nil {
+				return err
+			}
+			if err := r.skip(kv & 0x0F); err != nil {
+				return err
+			}
+		}
+		return nil
+	case tStruct:
+		return r.readStruct(func(_ int16, typ byte) error {
+			return r.skip(typ)
+		})
+	default:
+		return fmt.Errorf("parquet: invalid thrift type %d", typ)
+	}
+}
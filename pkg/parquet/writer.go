@@ -0,0 +1,430 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package parquet
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"blockwatch.cc/knoxdb/internal/block"
+	"blockwatch.cc/knoxdb/internal/pack"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/schema"
+)
+
+const createdBy = "knoxdb"
+
+// Writer writes packs as Parquet row groups. Each call to WritePack
+// stores one row group, Close writes the file footer.
+type Writer struct {
+	w      io.Writer
+	s      *schema.Schema
+	fields []*schema.Field // visible fields
+	idx    []int           // pack block index per visible field
+	elems  []SchemaElement // leaf column elements
+	pki    int             // pk column or -1
+	codec  Compression
+	pos    int64
+	nrows  int64
+	groups []RowGroup
+	vals   []byte // page value buffer
+	levels []byte // definition levels
+	page   []byte // page assembly buffer
+	zbuf   []byte // compression buffer
+	hdr    thriftWriter
+	closed bool
+}
+
+// NewWriter creates a Parquet writer for packs of schema s. Meta fields are
+// skipped. Returns ErrUnsupportedType when s contains fields without
+// Parquet representation.
+func NewWriter(s *schema.Schema, w io.Writer) (*Writer, error) {
+	pw := &Writer{
+		w:     w,
+		s:     s,
+		pki:   -1,
+		codec: CompressionSnappy,
+	}
+	pk := s.Pk()
+	for i, f := range s.Fields {
+		if !f.IsVisible() {
+			continue
+		}
+		e, err := element(f)
+		if err != nil {
+			return nil, err
+		}
+		if pk != nil && pk.Id == f.Id && !pk.IsMeta() {
+			pw.pki = len(pw.fields)
+		}
+		pw.fields = append(pw.fields, f)
+		pw.idx = append(pw.idx, i)
+		pw.elems = append(pw.elems, e)
+	}
+	return pw, nil
+}
+
+func (w *Writer) WithCompression(c Compression) *Writer {
+	w.codec = c
+	return w
+}
+
+// WritePack writes selected rows of pkg as a single row group. Pack and
+// writer schema must match.
+func (w *Writer) WritePack(pkg *pack.Package) error {
+	if w.closed {
+		return io.ErrClosedPipe
+	}
+	if pkg.Schema().Hash != w.s.Hash {
+		return schema.ErrSchemaMismatch
+	}
+	if w.pos == 0 {
+		if err := w.write(magic); err != nil {
+			return err
+		}
+	}
+	sel := pkg.Selected()
+	n := pkg.Len()
+	if sel != nil {
+		n = len(sel)
+	}
+	if n == 0 {
+		return nil
+	}
+
+	g := RowGroup{NumRows: int64(n)}
+	for i, f := range w.fields {
+		cc, err := w.writeColumn(pkg.Block(w.idx[i]), f, &w.elems[i], sel, n)
+		if err != nil {
+			return fmt.Errorf("parquet: field %s: %v", f.Name, err)
+		}
+		g.Columns = append(g.Columns, cc)
+		g.TotalByteSize += cc.Meta.TotalUncompressedSize
+	}
+	if w.pki >= 0 && isAscending(pkg.Block(w.idx[w.pki]), sel, n) {
+		g.Sorting = []SortingColumn{{ColumnIdx: int32(w.pki)}}
+	}
+	w.groups = append(w.groups, g)
+	w.nrows += int64(n)
+	return nil
+}
+
+// Close writes the file footer. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.pos == 0 {
+		if err := w.write(magic); err != nil {
+			return err
+		}
+	}
+	meta := FileMetaData{
+		Version:   1,
+		NumRows:   w.nrows,
+		RowGroups: w.groups,
+		CreatedBy: createdBy,
+	}
+	meta.Schema = append(meta.Schema, SchemaElement{
+		Name:        w.s.Name,
+		NumChildren: int32(len(w.elems)),
+	})
+	meta.Schema = append(meta.Schema, w.elems...)
+	if buf, err := w.s.MarshalBinary(); err == nil {
+		meta.KeyValues = []KeyValue{{schemaKey, base64.StdEncoding.EncodeToString(buf)}}
+	}
+	tw := thriftWriter{}
+	meta.encode(&tw)
+	tw.buf = binary.LittleEndian.AppendUint32(tw.buf, uint32(len(tw.buf)))
+	tw.buf = append(tw.buf, magic...)
+	return w.write(tw.buf)
+}
+
+func (w *Writer) write(buf []byte) error {
+	n, err := w.w.Write(buf)
+	w.pos += int64(n)
+	return err
+}
+
+// writeColumn writes a column chunk with a single plain encoded data page.
+func (w *Writer) writeColumn(b *block.Block, f *schema.Field, e *SchemaElement, sel []uint32, n int) (ColumnChunk, error) {
+	var (
+		nulls    int64
+		stats    = newStats(e)
+		hasNulls = e.Repetition == Optional && b.HasNulls()
+	)
+	w.vals = w.vals[:0]
+	w.levels = w.levels[:0]
+
+	var bits, nbits byte
+	for i := range n {
+		row := i
+		if sel != nil {
+			row = int(sel[i])
+		}
+		if e.Repetition == Optional {
+			if hasNulls && b.IsNull(row) {
+				w.levels = append(w.levels, 0)
+				nulls++
+				continue
+			}
+			w.levels = append(w.levels, 1)
+		}
+		if e.Type == TypeBoolean {
+			if b.Bool().Get(row) {
+				bits |= 1 << nbits
+			}
+			if nbits++; nbits == 8 {
+				w.vals = append(w.vals, bits)
+				bits, nbits = 0, 0
+			}
+			continue
+		}
+		start := len(w.vals)
+		if err := w.appendValue(b, f, e, row); err != nil {
+			return ColumnChunk{}, err
+		}
+		stats.update(w.vals[start:])
+	}
+	if nbits > 0 {
+		w.vals = append(w.vals, bits)
+	}
+
+	// assemble page: definition levels followed by values
+	w.page = w.page[:0]
+	if e.Repetition == Optional {
+		w.page = append(w.page, 0, 0, 0, 0)
+		w.page = appendLevels(w.page, w.levels)
+		binary.LittleEndian.PutUint32(w.page, uint32(len(w.page)-4))
+	}
+	w.page = append(w.page, w.vals...)
+
+	var err error
+	w.zbuf, err = compress(w.codec, w.zbuf[:0], w.page)
+	if err != nil {
+		return ColumnChunk{}, err
+	}
+	if len(w.page) > math.MaxInt32 || len(w.zbuf) > math.MaxInt32 {
+		return ColumnChunk{}, fmt.Errorf("page too large")
+	}
+
+	h := pageHeader{
+		Type:             pageData,
+		UncompressedSize: int32(len(w.page)),
+		CompressedSize:   int32(len(w.zbuf)),
+		Data: dataPageHeader{
+			NumValues: int32(n),
+			Encoding:  EncodingPlain,
+			DefLevels: EncodingRLE,
+			RepLevels: EncodingRLE,
+		},
+	}
+	w.hdr.buf = w.hdr.buf[:0]
+	h.encode(&w.hdr)
+
+	offset := w.pos
+	if err := w.write(w.hdr.buf); err != nil {
+		return ColumnChunk{}, err
+	}
+	if err := w.write(w.zbuf); err != nil {
+		return ColumnChunk{}, err
+	}
+
+	return ColumnChunk{
+		FileOffset: offset,
+		Meta: ColumnMetaData{
+			Type:                  e.Type,
+			Encodings:             []Encoding{EncodingPlain, EncodingRLE},
+			Path:                  []string{e.Name},
+			Codec:                 w.codec,
+			NumValues:             int64(n),
+			TotalUncompressedSize: int64(len(w.hdr.buf) + len(w.page)),
+			TotalCompressedSize:   int64(len(w.hdr.buf) + len(w.zbuf)),
+			DataPageOffset:        offset,
+			Statistics:            stats.result(nulls),
+		},
+	}, nil
+}
+
+// appendValue appends the plain encoded value of row to the value buffer.
+func (w *Writer) appendValue(b *block.Block, f *schema.Field, e *SchemaElement, row int) error {
+	le := binary.LittleEndian
+	if f.Is(types.FieldFlagEnum) {
+		var code uint32
+		if f.Type == types.FieldTypeUint16 {
+			code = uint32(b.Uint16().Get(row))
+		} else {
+			code = b.Uint32().Get(row)
+		}
+		if f.Enum == nil {
+			return schema.ErrEnumUndefined
+		}
+		v, ok := f.Enum.Value32(code)
+		if !ok {
+			return fmt.Errorf("invalid enum code %d", code)
+		}
+		w.vals = le.AppendUint32(w.vals, uint32(len(v)))
+		w.vals = append(w.vals, v...)
+		return nil
+	}
+
+	switch f.Type {
+	case types.FieldTypeTimestamp, types.FieldTypeTime, types.FieldTypeDate:
+		v := b.Int64().Get(row)
+		if f.Type == types.FieldTypeTime {
+			// keep time of day only
+			d := nanosPerDay / schema.TimeScale(f.Scale).Nanos()
+			v = (v%d + d) % d
+		}
+		v = schema.RescaleTime(v, schema.TimeScale(f.Scale).Nanos(), e.timeNanos())
+		if e.Type == TypeInt32 {
+			w.vals = le.AppendUint32(w.vals, uint32(int32(v)))
+		} else {
+			w.vals = le.AppendUint64(w.vals, uint64(v))
+		}
+	case types.FieldTypeInt64, types.FieldTypeDecimal64:
+		w.vals = le.AppendUint64(w.vals, uint64(b.Int64().Get(row)))
+	case types.FieldTypeInt32, types.FieldTypeDecimal32:
+		w.vals = le.AppendUint32(w.vals, uint32(b.Int32().Get(row)))
+	case types.FieldTypeInt16:
+		w.vals = le.AppendUint32(w.vals, uint32(int32(b.Int16().Get(row))))
+	case types.FieldTypeInt8:
+		w.vals = le.AppendUint32(w.vals, uint32(int32(b.Int8().Get(row))))
+	case types.FieldTypeUint64:
+		w.vals = le.AppendUint64(w.vals, b.Uint64().Get(row))
+	case types.FieldTypeUint32:
+		w.vals = le.AppendUint32(w.vals, b.Uint32().Get(row))
+	case types.FieldTypeUint16:
+		w.vals = le.AppendUint32(w.vals, uint32(b.Uint16().Get(row)))
+	case types.FieldTypeUint8:
+		w.vals = le.AppendUint32(w.vals, uint32(b.Uint8().Get(row)))
+	case types.FieldTypeFloat64:
+		w.vals = le.AppendUint64(w.vals, math.Float64bits(b.Float64().Get(row)))
+	case types.FieldTypeFloat32:
+		w.vals = le.AppendUint32(w.vals, math.Float32bits(b.Float32().Get(row)))
+	case types.FieldTypeString, types.FieldTypeBytes, types.FieldTypeBigint:
+		v := b.Bytes().Get(row)
+		if e.Type == TypeFixedLenByteArray {
+			if len(v) != int(e.TypeLength) {
+				return fmt.Errorf("invalid fixed length value")
+			}
+		} else {
+			w.vals = le.AppendUint32(w.vals, uint32(len(v)))
+		}
+		w.vals = append(w.vals, v...)
+	case types.FieldTypeInt128, types.FieldTypeDecimal128:
+		v := b.Int128().Get(row).Bytes16()
+		w.vals = append(w.vals, v[:]...)
+	case types.FieldTypeInt256, types.FieldTypeDecimal256:
+		v := b.Int256().Get(row).Bytes32()
+		w.vals = append(w.vals, v[:]...)
+	default:
+		return ErrUnsupportedType
+	}
+	return nil
+}
+
+// isAscending reports whether pk values are strictly ascending.
+func isAscending(b *block.Block, sel []uint32, n int) bool {
+	pks := b.Uint64()
+	var last uint64
+	for i := range n {
+		row := i
+		if sel != nil {
+			row = int(sel[i])
+		}
+		v := pks.Get(row)
+		if i > 0 && v <= last {
+			return false
+		}
+		last = v
+	}
+	return true
+}
+
+// columnStats tracks min/max of plain encoded values in the column's
+// type defined sort order. Types without a simple order are skipped.
+type columnStats struct {
+	cmp      func(a, b []byte) int
+	skip     int // length prefix of byte array values
+	isNaN    func(v []byte) bool
+	min, max []byte
+}
+
+func newStats(e *SchemaElement) *columnStats {
+	le := binary.LittleEndian
+	s := &columnStats{}
+	l := e.logical()
+	unsigned := l.Kind == LogicalInteger && !l.Signed
+	switch e.Type {
+	case TypeInt32:
+		if unsigned {
+			s.cmp = func(a, b []byte) int { return cmp.Compare(le.Uint32(a), le.Uint32(b)) }
+		} else {
+			s.cmp = func(a, b []byte) int { return cmp.Compare(int32(le.Uint32(a)), int32(le.Uint32(b))) }
+		}
+	case TypeInt64:
+		if unsigned {
+			s.cmp = func(a, b []byte) int { return cmp.Compare(le.Uint64(a), le.Uint64(b)) }
+		} else {
+			s.cmp = func(a, b []byte) int { return cmp.Compare(int64(le.Uint64(a)), int64(le.Uint64(b))) }
+		}
+	case TypeFloat:
+		s.isNaN = func(v []byte) bool { return math.IsNaN(float64(math.Float32frombits(le.Uint32(v)))) }
+		s.cmp = func(a, b []byte) int {
+			return cmp.Compare(math.Float32frombits(le.Uint32(a)), math.Float32frombits(le.Uint32(b)))
+		}
+	case TypeDouble:
+		s.isNaN = func(v []byte) bool { return math.IsNaN(math.Float64frombits(le.Uint64(v))) }
+		s.cmp = func(a, b []byte) int {
+			return cmp.Compare(math.Float64frombits(le.Uint64(a)), math.Float64frombits(le.Uint64(b)))
+		}
+	case TypeByteArray, TypeFixedLenByteArray:
+		// decimals use signed order, enums and bigints have none
+		if l.Kind == LogicalDecimal || l.Kind == LogicalEnum || l.Kind == LogicalNone && e.Type == TypeByteArray {
+			return s
+		}
+		if e.Type == TypeByteArray {
+			s.skip = 4
+		}
+		s.cmp = bytes.Compare
+	}
+	return s
+}
+
+func (s *columnStats) update(v []byte) {
+	if s.cmp == nil {
+		return
+	}
+	v = v[s.skip:]
+	if s.isNaN != nil && s.isNaN(v) {
+		s.cmp = nil // NaN has no defined order
+		return
+	}
+	if s.min == nil {
+		s.min = append([]byte{}, v...)
+		s.max = append([]byte{}, v...)
+		return
+	}
+	if s.cmp(v, s.min) < 0 {
+		s.min = append(s.min[:0], v...)
+	}
+	if s.cmp(v, s.max) > 0 {
+		s.max = append(s.max[:0], v...)
+	}
+}
+
+func (s *columnStats) result(nulls int64) *Statistics {
+	st := &Statistics{NullCount: nulls}
+	if s.cmp != nil && s.min != nil {
+		st.Min, st.Max = s.min, s.max
+	}
+	return st
+}
@@ -52,7 +52,7 @@ func Timebase() BuilderOption {
 
 func Nullable() BuilderOption {
 	return func(b *Builder) {
-		b.currentField().Flags |= types.FieldFlagNullable
+		b.currentField().Flags |= types.FieldFlagNullable | types.FieldFlagValidity
 	}
 }
 
@@ -258,6 +258,38 @@ func (b *Builder) Bigint(name string, opts ...BuilderOption) *Builder {
 	return b.addField(FT_BIGINT, name, opts...)
 }
 
+func (b *Builder) List(name string, elem types.FieldType, opts ...BuilderOption) *Builder {
+	b.addField(FT_LIST, name)
+	b.s.Fields[len(b.s.Fields)-1].WithElem(elem)
+	return b.SetFieldOpts(opts...)
+}
+
+func (b *Builder) Document(name string, opts ...BuilderOption) *Builder {
+	return b.addField(FT_DOC, name, opts...)
+}
+
+func (b *Builder) UUID(name string, opts ...BuilderOption) *Builder {
+	return b.logical(LT_UUID, name, opts...)
+}
+
+func (b *Builder) IP(name string, opts ...BuilderOption) *Builder {
+	return b.logical(LT_IP, name, opts...)
+}
+
+func (b *Builder) Hash32(name string, opts ...BuilderOption) *Builder {
+	return b.logical(LT_HASH32, name, opts...)
+}
+
+func (b *Builder) Addr20(name string, opts ...BuilderOption) *Builder {
+	return b.logical(LT_ADDR20, name, opts...)
+}
+
+func (b *Builder) logical(l Logical, name string, opts ...BuilderOption) *Builder {
+	b.addField(FT_BYTES, name)
+	b.s.Fields[len(b.s.Fields)-1].WithLogical(l)
+	return b.SetFieldOpts(opts...)
+}
+
 func (b *Builder) AddIndex(name string, typ types.IndexType, opts ...IndexOption) *Builder {
 	if name == "" {
 		name = "I" + strconv.Itoa(len(b.s.Indexes))
@@ -289,6 +321,16 @@ func (b *Builder) IntIndex(fname string, opts ...IndexOption) *Builder {
 	return b.AddIndex(fname+"_index", types.IndexTypeInt, opts...)
 }
 
+func (b *Builder) TokenIndex(fname string, opts ...IndexOption) *Builder {
+	opts = append([]IndexOption{IndexField(fname)}, opts...)
+	return b.AddIndex(fname+"_index", types.IndexTypeToken, opts...)
+}
+
+func (b *Builder) BitmapIndex(fname string, opts ...IndexOption) *Builder {
+	opts = append([]IndexOption{IndexField(fname)}, opts...)
+	return b.AddIndex(fname+"_index", types.IndexTypeBitmap, opts...)
+}
+
 func (b *Builder) CompositeIndex(name string, opts ...IndexOption) *Builder {
 	return b.AddIndex(name, types.IndexTypeComposite, opts...)
 }
@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	"net"
+	"net/netip"
 	"reflect"
 	"strconv"
 	"time"
@@ -49,6 +51,9 @@ func NewCaster(typ types.FieldType, scale uint8, enum *EnumDictionary) ValueCast
 	case FT_STRING:
 		return StringCaster{} // MarshalText, stringer, ToString
 	case FT_BYTES:
+		if scale > 0 {
+			return LogicalCaster{Logical(scale)}
+		}
 		return BytesCaster{} // MarshalBinary
 	case FT_I8:
 		return IntCaster[int8]{}
@@ -67,7 +72,11 @@ func NewCaster(typ types.FieldType, scale uint8, enum *EnumDictionary) ValueCast
 			return enum
 		}
 	case FT_U32:
-		return UintCaster[uint32]{}
+		if enum == nil {
+			return UintCaster[uint32]{}
+		} else {
+			return enum.Dict()
+		}
 	case FT_U64:
 		return UintCaster[uint64]{}
 	case FT_F32:
@@ -78,14 +87,8 @@ func NewCaster(typ types.FieldType, scale uint8, enum *EnumDictionary) ValueCast
 		return I128Caster{}
 	case FT_I256:
 		return I256Caster{}
-	case FT_D32:
-		return IntCaster[int32]{}
-	case FT_D64:
-		return IntCaster[int64]{}
-	case FT_D128:
-		return I128Caster{}
-	case FT_D256:
-		return I256Caster{}
+	case FT_D32, FT_D64, FT_D128, FT_D256:
+		return NewDecimalCaster(typ, scale, num.RoundExact)
 	case FT_BIGINT:
 		return BigIntCaster{}
 	default:
@@ -1132,6 +1135,91 @@ func (c BytesCaster) CastSlice(val any) (res any, err error) {
 	return
 }
 
+// logical type caster
+//
+// LogicalCaster converts strings, IP addresses and byte arrays to fixed size
+// byte values of a logical type. Strings are parsed in the logical type's
+// text format.
+type LogicalCaster struct {
+	typ Logical
+}
+
+func (c LogicalCaster) CastValue(val any) (res any, err error) {
+	switch v := val.(type) {
+	case string:
+		return c.typ.Parse(v)
+	case netip.Addr:
+		if c.typ == LT_IP && v.IsValid() {
+			b := v.As16()
+			return b[:], nil
+		}
+	case net.IP:
+		if c.typ == LT_IP && v.To16() != nil {
+			return []byte(v.To16()), nil
+		}
+	default:
+		res, err = BytesCaster{}.CastValue(val)
+		if err == nil && len(res.([]byte)) == c.typ.Size() {
+			return
+		}
+	}
+	return nil, castError(val, c.typ.String())
+}
+
+func (c LogicalCaster) CastSlice(val any) (res any, err error) {
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Slice {
+		return nil, castError(val, c.typ.String())
+	}
+	cp := make([][]byte, rv.Len())
+	for i := range cp {
+		v, err := c.CastValue(rv.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		cp[i] = v.([]byte)
+	}
+	return cp, nil
+}
+
+// PrefixRange converts prefix value val into the range of fixed size byte
+// values sharing this prefix. Strings are parsed as CIDR prefix for IP
+// fields and as hex prefix otherwise.
+func (f *Field) PrefixRange(val any) ([]byte, []byte, error) {
+	if f.Type != FT_BYTES || f.Fixed == 0 {
+		return nil, nil, fmt.Errorf("prefix filter unsupported on field %s type %s", f.Name, f.TypeName())
+	}
+	var (
+		p    []byte
+		bits int
+		err  error
+	)
+	switch v := val.(type) {
+	case string:
+		p, bits, err = f.Logical().ParsePrefix(v)
+	case netip.Prefix:
+		if f.Logical() != LT_IP {
+			return nil, nil, castError(val, "prefix")
+		}
+		p, bits, err = f.Logical().ParsePrefix(v.String())
+	default:
+		var res any
+		res, err = BytesCaster{}.CastValue(val)
+		if err == nil {
+			p = res.([]byte)
+			bits = 8 * len(p)
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if bits > 8*int(f.Fixed) {
+		return nil, nil, fmt.Errorf("prefix longer than field %s size %d", f.Name, f.Fixed)
+	}
+	from, to := types.PrefixRange(p, bits, int(f.Fixed))
+	return from, to, nil
+}
+
 // int128 caster
 type I128Caster struct{}
 
@@ -1307,6 +1395,111 @@ func (c I256Caster) CastSlice(val any) (res any, err error) {
 	return
 }
 
+// decimal caster
+//
+// DecimalCaster converts decimal values of any width to the unscaled storage
+// type of a decimal column with scale. Decimal values are rescaled using the
+// caster's rounding mode, the default RoundExact fails instead of silently
+// rounding. All other values are treated as unscaled storage values.
+type DecimalCaster struct {
+	typ   types.FieldType
+	scale uint8
+	mode  num.RoundingMode
+	base  ValueCaster
+}
+
+func NewDecimalCaster(typ types.FieldType, scale uint8, mode num.RoundingMode) DecimalCaster {
+	c := DecimalCaster{typ: typ, scale: scale, mode: mode}
+	switch typ {
+	case FT_D32:
+		c.base = IntCaster[int32]{}
+	case FT_D64:
+		c.base = IntCaster[int64]{}
+	case FT_D128:
+		c.base = I128Caster{}
+	case FT_D256:
+		c.base = I256Caster{}
+	default:
+		panic(fmt.Errorf("caster: unsupported decimal type %s %d", typ, typ))
+	}
+	return c
+}
+
+func (c DecimalCaster) cast(v num.Decimal256) (any, error) {
+	d, err := v.Rescale(c.scale, c.mode)
+	if err != nil {
+		return nil, fmt.Errorf("cast: decimal %s to scale %d: %w", v, c.scale, err)
+	}
+	var (
+		res any
+		ok  bool
+	)
+	x := d.Int256()
+	switch c.typ {
+	case FT_D32:
+		res, ok = int32(x.Int64()), x.IsInt64() && int64(int32(x.Int64())) == x.Int64()
+	case FT_D64:
+		res, ok = x.Int64(), x.IsInt64()
+	case FT_D128:
+		res, ok = x.Int128(), x.IsInt128()
+	default:
+		res, ok = x, true
+	}
+	if !ok {
+		return nil, fmt.Errorf("cast: decimal %s to %s: %w", v, c.typ, num.ErrPrecisionOverflow)
+	}
+	return res, nil
+}
+
+func (c DecimalCaster) CastValue(val any) (any, error) {
+	switch v := val.(type) {
+	case num.Decimal32:
+		return c.cast(num.NewDecimal256(v.Int256(), v.Scale()))
+	case num.Decimal64:
+		return c.cast(num.NewDecimal256(v.Int256(), v.Scale()))
+	case num.Decimal128:
+		return c.cast(num.NewDecimal256(v.Int256(), v.Scale()))
+	case num.Decimal256:
+		return c.cast(v)
+	default:
+		return c.base.CastValue(val)
+	}
+}
+
+func (c DecimalCaster) CastSlice(val any) (any, error) {
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Slice {
+		return nil, castError(val, c.typ.String())
+	}
+	switch rv.Type().Elem() {
+	case reflect.TypeOf(num.Decimal32{}),
+		reflect.TypeOf(num.Decimal64{}),
+		reflect.TypeOf(num.Decimal128{}),
+		reflect.TypeOf(num.Decimal256{}):
+	default:
+		return c.base.CastSlice(val)
+	}
+	var res reflect.Value
+	switch c.typ {
+	case FT_D32:
+		res = reflect.ValueOf(make([]int32, rv.Len()))
+	case FT_D64:
+		res = reflect.ValueOf(make([]int64, rv.Len()))
+	case FT_D128:
+		res = reflect.ValueOf(make([]num.Int128, rv.Len()))
+	default:
+		res = reflect.ValueOf(make([]num.Int256, rv.Len()))
+	}
+	for i := range rv.Len() {
+		v, err := c.CastValue(rv.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		res.Index(i).Set(reflect.ValueOf(v))
+	}
+	return res.Interface(), nil
+}
+
 // num.Big caster
 type BigIntCaster struct{}
 
@@ -221,10 +221,10 @@ func TestCastNewCaster(t *testing.T) {
 		{"Float64", FT_F64, FloatCaster[float64]{}},
 		{"Int128", FT_I128, I128Caster{}},
 		{"Int256", FT_I256, I256Caster{}},
-		{"Decimal32", FT_D32, IntCaster[int32]{}},
-		{"Decimal64", FT_D64, IntCaster[int64]{}},
-		{"Decimal128", FT_D128, I128Caster{}},
-		{"Decimal256", FT_D256, I256Caster{}},
+		{"Decimal32", FT_D32, DecimalCaster{}},
+		{"Decimal64", FT_D64, DecimalCaster{}},
+		{"Decimal128", FT_D128, DecimalCaster{}},
+		{"Decimal256", FT_D256, DecimalCaster{}},
 		{"BigInt", types.FieldTypeBigint, BigIntCaster{}},
 	}
 
@@ -729,3 +729,108 @@ func TestCastI256Caster(t *testing.T) {
 		}
 	})
 }
+
+// TestCastDecimalCaster tests that decimal values of any width are rescaled
+// to column scale without silent precision loss.
+func TestCastDecimalCaster(t *testing.T) {
+	t.Run("CastValue", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			caster   ValueCaster
+			input    any
+			expected any
+			hasError bool
+		}{
+			{"D64Raw", NewCaster(FT_D64, 2, nil), int64(12345), int64(12345), false},
+			{"D64ScaleUp", NewCaster(FT_D64, 2, nil), num.NewDecimal64(15, 1), int64(150), false},
+			{"D64FromD128", NewCaster(FT_D64, 2, nil), num.MustParseDecimal128("1.2500"), int64(125), false},
+			{"D64FromD256", NewCaster(FT_D64, 2, nil), num.MustParseDecimal256("-7.1"), int64(-710), false},
+			{"D64Inexact", NewCaster(FT_D64, 2, nil), num.MustParseDecimal128("1.005"), nil, true},
+			{"D64Overflow", NewCaster(FT_D64, 2, nil), num.MustParseDecimal128("100000000000000000000"), nil, true},
+			{"D32Overflow", NewCaster(FT_D32, 4, nil), num.MustParseDecimal64("1000000"), nil, true},
+			{"D128FromD32", NewCaster(FT_D128, 6, nil), num.MustParseDecimal32("1.5"), num.Int128FromInt64(1500000), false},
+			{"D256FromD64", NewCaster(FT_D256, 0, nil), num.MustParseDecimal64("42.0"), num.Int256FromInt64(42), false},
+			{"D64Floor", NewDecimalCaster(FT_D64, 2, num.RoundFloor), num.MustParseDecimal128("1.005"), int64(100), false},
+			{"D64Ceil", NewDecimalCaster(FT_D64, 2, num.RoundCeil), num.MustParseDecimal128("1.005"), int64(101), false},
+			{"D64CeilNeg", NewDecimalCaster(FT_D64, 2, num.RoundCeil), num.MustParseDecimal128("-1.005"), int64(-100), false},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				result, err := tt.caster.CastValue(tt.input)
+				if tt.hasError {
+					assert.Error(t, err)
+				} else {
+					assert.NoError(t, err)
+					assert.Equal(t, tt.expected, result)
+				}
+			})
+		}
+	})
+
+	t.Run("CastSlice", func(t *testing.T) {
+		caster := NewCaster(FT_D64, 2, nil)
+		result, err := caster.CastSlice([]num.Decimal128{
+			num.MustParseDecimal128("1.5"),
+			num.MustParseDecimal128("-0.25"),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{150, -25}, result)
+
+		result, err = caster.CastSlice([]int64{1, 2})
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{1, 2}, result)
+
+		_, err = caster.CastSlice([]num.Decimal64{num.MustParseDecimal64("0.001")})
+		assert.Error(t, err)
+	})
+}
+
+// TestCastBigIntCaster tests that bigint conditions keep the sign of
+// negative values.
+func TestCastBigIntCaster(t *testing.T) {
+	caster := NewCaster(FT_BIGINT, 0, nil)
+
+	t.Run("CastValue", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			input    any
+			expected string
+			hasError bool
+		}{
+			{"Int", 42, "42", false},
+			{"Zero", int64(0), "0", false},
+			{"Uint64", uint64(math.MaxUint64), "18446744073709551615", false},
+			{"Int256", num.MaxInt256, num.MaxInt256.String(), false},
+			{"Big", num.NewBig(7), "7", false},
+			{"String", "42", "", true},
+			{"NegInt", -1, "-1", false},
+			{"NegInt64", int64(math.MinInt64), "-9223372036854775808", false},
+			{"NegDecimal64", num.NewDecimal64(-100, 0), "-100", false},
+			{"NegInt256", num.Int256FromInt64(-1), "-1", false},
+			{"NegBig", num.NewBig(-7), "-7", false},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				result, err := caster.CastValue(tt.input)
+				if tt.hasError {
+					assert.Error(t, err)
+				} else {
+					assert.NoError(t, err)
+					assert.Equal(t, tt.expected, result.(num.Big).String())
+				}
+			})
+		}
+	})
+
+	t.Run("CastSlice", func(t *testing.T) {
+		result, err := caster.CastSlice([]int64{0, 1, 2})
+		assert.NoError(t, err)
+		assert.Len(t, result, 3)
+
+		result, err = caster.CastSlice([]int64{1, -2})
+		assert.NoError(t, err)
+		assert.Equal(t, []num.Big{num.NewBig(1), num.NewBig(-2)}, result)
+	})
+}
@@ -5,12 +5,14 @@ package schema
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"reflect"
 	"time"
 	"unsafe"
 
+	"blockwatch.cc/knoxdb/internal/types"
 	"blockwatch.cc/knoxdb/pkg/num"
 )
 
@@ -142,6 +144,14 @@ func (d *Decoder) Read(r io.Reader, val any) error {
 	for op, code := range d.schema.Decode {
 		field := d.schema.Fields[op]
 		ptr := unsafe.Add(base, field.Offset)
+		if code != OpCodeSkip && field.HasValidity() {
+			valid := d.buf.Next(1)[0] > 0
+			if ptr = field.SetValid(ptr, valid); !valid {
+				clearValue(field, ptr)
+				d.buf.Next(field.WireSize() - 1)
+				continue
+			}
+		}
 		switch code {
 		default:
 			// int, uint, float, bool
@@ -218,6 +228,18 @@ func (d *Decoder) Read(r io.Reader, val any) error {
 			} else {
 				err = fmt.Errorf("translation for enum %q not registered", field.Name)
 			}
+
+		case OpCodeDict:
+			u32 := LE.Uint32(d.buf.Next(4))
+			if enum, ok := d.enums.Lookup(field.Name); ok {
+				val, ok := enum.Value32(u32)
+				if !ok {
+					err = fmt.Errorf("%s: invalid dict value %d", field.Name, u32)
+				}
+				*(*string)(ptr) = val
+			} else {
+				err = fmt.Errorf("translation for dict %q not registered", field.Name)
+			}
 		case OpCodeBigInt:
 			// read as raw bytes and create num.Big
 			l := LE.Uint32(d.buf.Next(4))
@@ -229,6 +251,29 @@ func (d *Decoder) Read(r io.Reader, val any) error {
 				return ErrShortBuffer
 			}
 			err = (*num.Big)(ptr).UnmarshalBinary(d.buf.Next(int(l)))
+
+		case OpCodeList:
+			l := LE.Uint32(d.buf.Next(4))
+			n, err = io.CopyN(d.buf, r, int64(l)) // may realloc!
+			if err != nil {
+				return err
+			}
+			if n != int64(l) {
+				return ErrShortBuffer
+			}
+			// explicit copy
+			setList(ptr, field, d.buf.Next(int(l)))
+
+		case OpCodeDocument:
+			l := LE.Uint32(d.buf.Next(4))
+			n, err = io.CopyN(d.buf, r, int64(l)) // may realloc!
+			if err != nil {
+				return err
+			}
+			if n != int64(l) {
+				return ErrShortBuffer
+			}
+			err = setDocument(ptr, field, d.buf.Next(int(l)))
 		}
 
 		if err != nil {
@@ -284,7 +329,30 @@ func (d *Decoder) DecodeSlice(buf []byte, slice any) (int, error) {
 	return i, nil
 }
 
+// readField reads a single struct field. Fields with validity start with a
+// validity flag, NULL values are skipped and reset the struct field.
 func readField(code OpCode, field *Field, ptr unsafe.Pointer, buf []byte, enums *EnumRegistry) []byte {
+	if !field.HasValidity() {
+		return readValue(code, field, ptr, buf, enums)
+	}
+	valid := buf[0] > 0
+	buf = buf[1:]
+	ptr = field.SetValid(ptr, valid)
+	if !valid {
+		clearValue(field, ptr)
+		return buf[field.WireSize()-1:]
+	}
+	return readValue(code, field, ptr, buf, enums)
+}
+
+// clearValue resets the Go value of a NULL field (if any) to its zero value.
+func clearValue(field *Field, ptr unsafe.Pointer) {
+	if ptr != nil {
+		reflect.NewAt(field.GoType(), ptr).Elem().SetZero()
+	}
+}
+
+func readValue(code OpCode, field *Field, ptr unsafe.Pointer, buf []byte, enums *EnumRegistry) []byte {
 	switch code {
 
 	case OpCodeInt64, OpCodeUint64, OpCodeFloat64:
@@ -388,6 +456,22 @@ func readField(code OpCode, field *Field, ptr unsafe.Pointer, buf []byte, enums
 		}
 		*(*string)(ptr) = val // FIXME: may break when enum dict grows
 
+	case OpCodeDict:
+		if enums == nil {
+			panic(fmt.Errorf("nil enum registry when decoding dict %q", field.Name))
+		}
+		u32 := LE.Uint32(buf)
+		buf = buf[4:]
+		enum, ok := enums.Lookup(field.Name)
+		if !ok {
+			panic(fmt.Errorf("translation for dict %q not registered", field.Name))
+		}
+		val, ok := enum.Value32(u32)
+		if !ok {
+			panic(fmt.Errorf("%s: invalid dict value %d", field.Name, u32))
+		}
+		*(*string)(ptr) = val
+
 	case OpCodeBigInt:
 		l := LE.Uint32(buf)
 		buf = buf[4:]
@@ -396,6 +480,40 @@ func readField(code OpCode, field *Field, ptr unsafe.Pointer, buf []byte, enums
 			_ = (*num.Big)(ptr).UnmarshalBinary(buf[:l])
 			buf = buf[l:]
 		}
+
+	case OpCodeList:
+		l := LE.Uint32(buf)
+		buf = buf[4:]
+		if l > 0 {
+			_ = buf[l-1]
+			// copy because buf may not be aligned to element size
+			setList(ptr, field, buf[:l])
+			buf = buf[l:]
+		}
+
+	case OpCodeDocument:
+		l := LE.Uint32(buf)
+		buf = buf[4:]
+		// documents were validated on encode
+		_ = setDocument(ptr, field, buf[:l])
+		buf = buf[l:]
 	}
 	return buf
 }
+
+// setList stores a typed copy of packed list elements buf at ptr.
+func setList(ptr unsafe.Pointer, field *Field, buf []byte) {
+	val := types.ListValue(field.Elem.BlockType(), buf)
+	reflect.NewAt(field.GoType(), ptr).Elem().Set(reflect.ValueOf(val))
+}
+
+// setDocument unmarshals JSON document buf into the Go value at ptr.
+// Empty documents reset the value.
+func setDocument(ptr unsafe.Pointer, field *Field, buf []byte) error {
+	v := reflect.NewAt(field.GoType(), ptr)
+	v.Elem().SetZero()
+	if len(buf) == 0 {
+		return nil
+	}
+	return json.Unmarshal(buf, v.Interface())
+}
@@ -15,11 +15,22 @@ package schema
 //
 // ```
 // pk            mark this field as primary key
-// index={type}  generate db index (hash, int, bits, bloom, bfuse)
-// zip={type}    use extra compression (snappy, lz4, zstd, none, (empty))
+// index={type}  generate db index (hash, int, token, bitmap, bits, bloom, bfuse)
+// expr={expr}   index computed keys (lower, prefix(n), time_bucket(d))
+// tokenizer={t} token index tokenizer (words, ngram(n))
+// zip={type}    use extra compression (snappy, lz4, zstd, auto, dict, none, (empty))
+// zstd={num}    use zstd compression at level num (1..22)
+// lz4={num}     use lz4 compression at level num (1..12)
+// auto={policy} pick the best codec per block (speed, balanced, ratio)
+// zip=dict      use zstd with a dictionary trained per table column
 // fixed={num}   treat as fixed length field (only byte array, byte slice, string)
 // scale={num}   scale factor (for decimal and time types only)
+// uuid          logical UUID type (only [16]byte)
+// ip            logical IPv4/IPv6 address type (only [16]byte, IPv4 stored as IPv4-mapped IPv6)
+// hash32        logical hash type (only [32]byte)
+// addr20        logical address type (only [20]byte)
 // enum          mark field as enum
+// dict          dictionary encode string field (32 bit codes, extended on insert)
 // internal      mark field as internal (not exported to users via encode/decode)
 // id={num}      override id value
 // ```
@@ -40,6 +51,7 @@ package schema
 // - primary key: the field is used as primary key (must be uint64 type)
 // - indexed: a database index will be created for this field
 // - enum: the field is an enum type with a private EnumDictionary
+//   (uint16 codes for enums, uint32 codes for dictionary fields)
 // - deleted: the field is deleted and no longer used
 // - internal: the field is not used for encoding and decoding data
 //
@@ -62,3 +74,8 @@ package schema
 // - `Decimal64` values from `0..18`
 // - `Decimal128` values from `0..38`
 // - `Decimal256` values from `0..76`
+//
+// Logical types
+// - `uuid`, `ip`, `hash32` and `addr20` annotate fixed size byte arrays with
+//   a value domain for parsing, formatting and prefix filters. The logical type
+//   is stored in the scale factor. Values sort bytewise in canonical order.
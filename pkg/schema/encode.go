@@ -10,6 +10,7 @@ import (
 	"time"
 	"unsafe"
 
+	"blockwatch.cc/knoxdb/internal/types"
 	"blockwatch.cc/knoxdb/pkg/num"
 )
 
@@ -176,7 +177,27 @@ func (e *Encoder) EncodePtrSlice(slice any, buf *bytes.Buffer) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+var zeros [32]byte
+
+// writeField writes a single struct field. Fields with validity are prefixed
+// with a validity flag and NULL values are written as zero values.
 func writeField(buf *bytes.Buffer, code OpCode, field *Field, ptr unsafe.Pointer, enums *EnumRegistry) error {
+	if !field.HasValidity() {
+		return writeValue(buf, code, field, ptr, enums)
+	}
+	ptr, ok := field.ValuePtr(ptr)
+	if ok {
+		buf.WriteByte(1)
+		return writeValue(buf, code, field, ptr, enums)
+	}
+	buf.WriteByte(0)
+	for sz := field.WireSize() - 1; sz > 0; sz -= 32 {
+		buf.Write(zeros[:min(sz, 32)])
+	}
+	return nil
+}
+
+func writeValue(buf *bytes.Buffer, code OpCode, field *Field, ptr unsafe.Pointer, enums *EnumRegistry) error {
 	var (
 		err error
 		sz  [4]byte
@@ -250,12 +271,49 @@ func writeField(buf *bytes.Buffer, code OpCode, field *Field, ptr unsafe.Pointer
 		LE.PutUint16(b[:], code)
 		_, err = buf.Write(b[:])
 
+	case OpCodeDict:
+		if enums == nil {
+			return ErrEnumUndefined
+		}
+		enum, ok := enums.Lookup(field.Name)
+		if !ok {
+			return ErrEnumUndefined
+		}
+		// extend dictionary with new values, the table engine
+		// logs dictionary growth on insert
+		code, perr := enum.Put(*(*string)(ptr))
+		if perr != nil {
+			return fmt.Errorf("%s: %w", field.Name, perr)
+		}
+		var b [4]byte
+		LE.PutUint32(b[:], code)
+		_, err = buf.Write(b[:])
+
 	case OpCodeBigInt:
 		v := *(*num.Big)(ptr)
 		b := v.Bytes()
 		LE.PutUint32(sz[:], uint32(len(b)))
 		buf.Write(sz[:])
 		_, err = buf.Write(b)
+
+	case OpCodeList:
+		// reinterpret any numeric slice header as packed element vector
+		s := *(*[]byte)(ptr)
+		b := unsafe.Slice(unsafe.SliceData(s), len(s)*field.Elem.Size())
+		LE.PutUint32(sz[:], uint32(len(b)))
+		buf.Write(sz[:])
+		_, err = buf.Write(b)
+
+	case OpCodeDocument:
+		// marshal Go value into compact JSON
+		var b []byte
+		b, err = types.DocumentBytes(reflect.NewAt(field.GoType(), ptr).Elem().Interface())
+		if err != nil {
+			return fmt.Errorf("field %s: %v", field.Name, err)
+		}
+		LE.PutUint32(sz[:], uint32(len(b)))
+		buf.Write(sz[:])
+		_, err = buf.Write(b)
 	}
 	return err
 }
@@ -5,7 +5,9 @@ package schema
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/hex"
+	"encoding/json"
 	"math/rand"
 	"os"
 	"reflect"
@@ -195,6 +197,55 @@ func TestEncodeRoundtripWithVisibility(t *testing.T) {
 	require.Equal(t, uint64(0), val2.FMetaDeleted, "meta_deleted")
 }
 
+func TestEncodeRoundtripNullable(t *testing.T) {
+	enc := NewGenericEncoder[NullableTypes]()
+	dec := NewGenericDecoder[NullableTypes]()
+	s := enc.Schema()
+
+	// all values null
+	val := NullableTypes{BaseModel: BaseModel{Id: 1}}
+	buf, err := enc.Encode(val, nil)
+	require.NoError(t, err)
+	val2, err := dec.Decode(buf, nil)
+	require.NoError(t, err)
+	require.Exactly(t, val, *val2)
+
+	view := NewView(s).Reset(buf)
+	for i := 1; i < 4; i++ {
+		require.True(t, view.IsNull(i), "field %d", i)
+	}
+	require.False(t, view.IsNull(0))
+	require.False(t, view.IsNull(4), "tagged value types are never nil")
+
+	// tagged value types keep the wire format without validity byte
+	require.False(t, s.Fields[4].HasValidity())
+	require.Equal(t, 4, s.Fields[4].WireSize())
+	require.Equal(t, []byte{0, 0, 0, 0}, buf[len(buf)-4:])
+
+	// all values set
+	n, str := int64(42), "knox"
+	val = NullableTypes{
+		BaseModel: BaseModel{Id: 2},
+		Ptr:       &n,
+		Str:       &str,
+		Null:      sql.NullInt64{Int64: 7, Valid: true},
+		Val:       3,
+	}
+	buf, err = enc.Encode(val, nil)
+	require.NoError(t, err)
+	val2, err = dec.Decode(buf, nil)
+	require.NoError(t, err)
+	require.Exactly(t, val, *val2)
+
+	view.Reset(buf)
+	for i := range 5 {
+		require.False(t, view.IsNull(i), "field %d", i)
+	}
+	v, ok := view.Get(2)
+	require.True(t, ok)
+	require.Equal(t, str, v)
+}
+
 func TestDecoderRead(t *testing.T) {
 	enc := NewGenericEncoder[encodeTestStruct]()
 	dec := NewGenericDecoder[encodeTestStruct]()
@@ -527,3 +578,187 @@ func BenchmarkDecodeSliceRead(b *testing.B) {
 		})
 	}
 }
+
+type listTypes struct {
+	Id   uint64    `knox:"id,pk"`
+	U64  []uint64  `knox:"u64"`
+	I16  []int16   `knox:"i16"`
+	F64  []float64 `knox:"f64"`
+	Data []byte    `knox:"data"`
+}
+
+func TestEncodeRoundtripList(t *testing.T) {
+	enc := NewGenericEncoder[listTypes]()
+	dec := NewGenericDecoder[listTypes]()
+	s := enc.Schema()
+	require.Equal(t, FT_LIST, s.Fields[1].Type)
+	require.Equal(t, FT_U64, s.Fields[1].Elem)
+	require.Equal(t, "list(uint64)", s.Fields[1].TypeName())
+	require.Equal(t, FT_I16, s.Fields[2].Elem)
+	require.Equal(t, FT_BYTES, s.Fields[4].Type, "byte slices are no lists")
+
+	val := listTypes{
+		Id:   1,
+		U64:  []uint64{1, 2, 1 << 40},
+		I16:  []int16{-1},
+		F64:  []float64{},
+		Data: []byte("knox"),
+	}
+	buf, err := enc.Encode(val, nil)
+	require.NoError(t, err)
+	val2, err := dec.Decode(buf, nil)
+	require.NoError(t, err)
+	require.Equal(t, val.U64, val2.U64)
+	require.Equal(t, val.I16, val2.I16)
+	require.Empty(t, val2.F64)
+	require.Equal(t, val.Data, val2.Data)
+
+	// slow path
+	val3, err := dec.Read(bytes.NewBuffer(buf))
+	require.NoError(t, err)
+	require.Equal(t, val.U64, val3.U64)
+
+	// view access
+	view := NewView(s).Reset(buf)
+	v, ok := view.Get(1)
+	require.True(t, ok)
+	require.Equal(t, val.U64, v)
+	v, ok = view.Get(4)
+	require.True(t, ok)
+	require.Equal(t, val.Data, v)
+}
+
+type docParams struct {
+	Amount int64  `json:"amount"`
+	To     string `json:"to"`
+}
+
+type docTypes struct {
+	Id     uint64          `knox:"id,pk"`
+	Raw    json.RawMessage `knox:"raw"`
+	Meta   map[string]any  `knox:"meta"`
+	Params docParams       `knox:"params,doc"`
+	Opt    *docParams      `knox:"opt,doc"`
+	Tags   []string        `knox:"tags,doc"`
+}
+
+func TestEncodeRoundtripDocument(t *testing.T) {
+	enc := NewGenericEncoder[docTypes]()
+	dec := NewGenericDecoder[docTypes]()
+	s := enc.Schema()
+	for i := 1; i < len(s.Fields); i++ {
+		require.Equal(t, FT_DOC, s.Fields[i].Type, s.Fields[i].Name)
+	}
+	require.True(t, s.Fields[4].IsNullable(), "pointer documents are nullable")
+
+	val := docTypes{
+		Id:     1,
+		Raw:    json.RawMessage(`{ "a": [1, 2], "b": null }`),
+		Meta:   map[string]any{"k": "v"},
+		Params: docParams{Amount: 100, To: "x"},
+		Tags:   []string{"a", "b"},
+	}
+	buf, err := enc.Encode(val, nil)
+	require.NoError(t, err)
+	val2, err := dec.Decode(buf, nil)
+	require.NoError(t, err)
+	require.Equal(t, json.RawMessage(`{"a":[1,2],"b":null}`), val2.Raw, "compact json")
+	require.Equal(t, val.Meta, val2.Meta)
+	require.Equal(t, val.Params, val2.Params)
+	require.Nil(t, val2.Opt)
+	require.Equal(t, val.Tags, val2.Tags)
+
+	// slow path
+	val3, err := dec.Read(bytes.NewBuffer(buf))
+	require.NoError(t, err)
+	require.Equal(t, val.Params, val3.Params)
+
+	// view access returns json
+	view := NewView(s).Reset(buf)
+	v, ok := view.Get(3)
+	require.True(t, ok)
+	require.Equal(t, json.RawMessage(`{"amount":100,"to":"x"}`), v)
+
+	// invalid json
+	val.Raw = json.RawMessage(`{`)
+	_, err = enc.Encode(val, nil)
+	require.Error(t, err)
+}
+
+type bigTypes struct {
+	Id  uint64  `knox:"id,pk"`
+	Big num.Big `knox:"big"`
+	N   int64   `knox:"n"`
+}
+
+func TestEncodeRoundtripBigint(t *testing.T) {
+	enc := NewGenericEncoder[bigTypes]()
+	dec := NewGenericDecoder[bigTypes]()
+	s := enc.Schema()
+	require.Equal(t, FT_BIGINT, s.Fields[1].Type)
+	require.False(t, s.IsFixedSize, "bigints are variable size")
+
+	view := NewView(s)
+	for _, v := range []string{"0", "1", "-5", "115792089237316195423570985008687907853269984665640564039457584007913129639936", "-340282366920938463463374607431768211456"} {
+		z, err := num.ParseBig(v)
+		require.NoError(t, err)
+		buf, err := enc.Encode(bigTypes{Id: 1, Big: z, N: -1}, nil)
+		require.NoError(t, err, v)
+		val, err := dec.Decode(buf, nil)
+		require.NoError(t, err, v)
+		require.Equal(t, v, val.Big.String())
+		require.Equal(t, int64(-1), val.N)
+
+		// slow path
+		val, err = dec.Read(bytes.NewBuffer(buf))
+		require.NoError(t, err, v)
+		require.Equal(t, v, val.Big.String())
+		require.Equal(t, int64(-1), val.N)
+
+		// view access behind the variable length value
+		view.Reset(buf)
+		n, ok := view.Get(2)
+		require.True(t, ok)
+		require.Equal(t, int64(-1), n)
+
+		w := NewWriter(s, LE)
+		require.NoError(t, w.Write(1, z), v)
+		require.NoError(t, w.Write(1, z.Big()), v)
+	}
+}
+
+type dictTypes struct {
+	Id  uint64 `knox:"id,pk"`
+	Sym string `knox:"sym,dict"`
+}
+
+func TestEncodeRoundtripDict(t *testing.T) {
+	enc := NewGenericEncoder[dictTypes]()
+	dec := NewGenericDecoder[dictTypes]()
+	s := enc.Schema()
+	require.Equal(t, FT_U32, s.Fields[1].Type)
+	require.True(t, s.Fields[1].IsDict())
+	require.Contains(t, s.StructType().Field(1).Tag.Get("knox"), ",dict")
+
+	// encoding extends the dictionary
+	vals := []dictTypes{{1, "a"}, {2, "b"}, {3, "a"}}
+	buf, err := enc.EncodeSlice(vals, nil)
+	require.NoError(t, err)
+	enum, ok := s.Enums.Load().Lookup("sym")
+	require.True(t, ok)
+	require.Equal(t, []string{"a", "b"}, enum.Values())
+
+	// wire format contains codes
+	view := NewView(s).Reset(buf)
+	v, ok := view.Get(1)
+	require.True(t, ok)
+	require.Equal(t, uint32(0), v)
+
+	// decoding translates codes
+	val, err := dec.Decode(buf[s.WireSize():], nil)
+	require.NoError(t, err)
+	require.Equal(t, vals[1], *val)
+	val2, err := dec.Read(bytes.NewBuffer(buf[2*s.WireSize():]))
+	require.NoError(t, err)
+	require.Equal(t, vals[2], *val2)
+}
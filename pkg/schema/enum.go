@@ -5,9 +5,12 @@ package schema
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
+	"math"
 	"slices"
 	"strings"
+	"sync"
 
 	"blockwatch.cc/knoxdb/internal/hash"
 	"blockwatch.cc/knoxdb/internal/types"
@@ -17,6 +20,13 @@ import (
 const (
 	EnumMaxSize   = 1<<8 - 1 // 255
 	EnumMaxValues = 1 << 16  // 65536 (0 .. 0xFFFF)
+
+	// Dictionary fields use 32 bit codes and are extended on insert.
+	// The highest code is reserved to represent values that are not
+	// in the dictionary.
+	DictMaxSize   = 1<<16 - 1      // 65535
+	DictMaxValues = math.MaxUint32 // 0 .. 0xFFFFFFFE
+	DictNoCode    = math.MaxUint32 // code for unknown values
 )
 
 type EnumRegistry struct {
@@ -51,11 +61,16 @@ func (r EnumRegistry) Lookup(name string) (*EnumDictionary, bool) {
 	return r.Get(types.TaggedHash(types.ObjectTagEnum, name))
 }
 
+// EnumDictionary maps string values to sequential codes. Enum fields
+// (uint16) use explicitly managed dictionaries with up to 65536 values,
+// dictionary fields (uint32) use dictionaries that grow on insert.
+// Dictionaries are safe for concurrent use.
 type EnumDictionary struct {
+	mu      sync.RWMutex
 	name    string
 	values  []byte
 	offsets []uint32
-	codes   map[uint64]uint16
+	codes   map[uint64]uint32
 }
 
 func NewEnumDictionary(name string) *EnumDictionary {
@@ -66,7 +81,7 @@ func NewEnumDictionary(name string) *EnumDictionary {
 		name:    name,
 		values:  make([]byte, 0),
 		offsets: make([]uint32, 0),
-		codes:   make(map[uint64]uint16),
+		codes:   make(map[uint64]uint32),
 	}
 }
 
@@ -79,15 +94,19 @@ func (e *EnumDictionary) Tag() uint64 {
 }
 
 func (e *EnumDictionary) Len() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
 	return len(e.offsets)
 }
 
 func (e *EnumDictionary) Clone() *EnumDictionary {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
 	clone := &EnumDictionary{
 		name:    e.name,
 		values:  bytes.Clone(e.values),
 		offsets: slices.Clone(e.offsets),
-		codes:   make(map[uint64]uint16, len(e.codes)),
+		codes:   make(map[uint64]uint32, len(e.codes)),
 	}
 	for c := range e.codes {
 		clone.codes[c] = e.codes[c]
@@ -96,34 +115,87 @@ func (e *EnumDictionary) Clone() *EnumDictionary {
 }
 
 func (e *EnumDictionary) Values() []string {
-	vals := make([]string, len(e.offsets))
+	return e.ValuesFrom(0)
+}
+
+// ValuesFrom returns all values starting at code. Used to log dictionary
+// growth.
+func (e *EnumDictionary) ValuesFrom(code int) []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if code >= len(e.offsets) {
+		return nil
+	}
+	vals := make([]string, len(e.offsets)-code)
 	for i := range vals {
-		vals[i] = e.value(i)
+		vals[i] = e.value(code + i)
 	}
 	return vals
 }
 
 func (e *EnumDictionary) Value(code uint16) (string, bool) {
-	if int(code) >= len(e.offsets) {
+	return e.Value32(uint32(code))
+}
+
+func (e *EnumDictionary) Value32(code uint32) (string, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if int64(code) >= int64(len(e.offsets)) {
 		return "", false
 	}
 	return e.value(int(code)), true
 }
 
 func (e *EnumDictionary) MustValue(code uint16) string {
-	if int(code) >= len(e.offsets) {
+	val, ok := e.Value32(uint32(code))
+	if !ok {
 		panic(ErrInvalidValue)
 	}
-	return e.value(int(code))
+	return val
 }
 
 func (e *EnumDictionary) Code(val string) (uint16, bool) {
+	code, ok := e.Code32(val)
+	if !ok || code >= EnumMaxValues {
+		return 0, false
+	}
+	return uint16(code), true
+}
+
+func (e *EnumDictionary) Code32(val string) (uint32, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
 	code, ok := e.codes[hash.Hash([]byte(val))]
 	return code, ok
 }
 
+// Put returns the code for val and appends val when it is not yet
+// part of the dictionary.
+func (e *EnumDictionary) Put(val string) (uint32, error) {
+	if code, ok := e.Code32(val); ok {
+		return code, nil
+	}
+	if len(val) > DictMaxSize {
+		return 0, fmt.Errorf("enum: %s %q: %w", e.name, val, ErrNameTooLong)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	h := hash.Hash([]byte(val))
+	if code, ok := e.codes[h]; ok {
+		return code, nil
+	}
+	if int64(len(e.offsets)) >= DictMaxValues {
+		return 0, ErrEnumFull
+	}
+	code := uint32(len(e.offsets))
+	e.add(h, []byte(val))
+	return code, nil
+}
+
 func (e *EnumDictionary) Append(vals ...string) error {
-	if e.Len()+len(vals) > EnumMaxValues {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.offsets)+len(vals) > EnumMaxValues {
 		return ErrEnumFull
 	}
 	unique := make(map[string]struct{})
@@ -131,7 +203,7 @@ func (e *EnumDictionary) Append(vals ...string) error {
 		if len(v) > EnumMaxSize {
 			return fmt.Errorf("enum: %s %q: %w", e.name, v, ErrNameTooLong)
 		}
-		if _, ok := e.Code(v); ok {
+		if _, ok := e.codes[hash.Hash([]byte(v))]; ok {
 			return fmt.Errorf("enum: %s %q: %w", e.name, v, ErrDuplicateName)
 		}
 		if _, ok := unique[v]; ok {
@@ -140,44 +212,78 @@ func (e *EnumDictionary) Append(vals ...string) error {
 		unique[v] = struct{}{}
 	}
 
-	clear(e.codes)
 	for _, v := range vals {
-		e.codes[hash.Hash([]byte(v))] = uint16(e.Len())
-		e.offsets = append(e.offsets, uint32(len(e.values)))
-		e.values = append(e.values, []byte(v)...)
+		e.add(hash.Hash([]byte(v)), []byte(v))
 	}
 	return nil
 }
 
-func (e EnumDictionary) MarshalBinary() ([]byte, error) {
-	buf := make([]byte, 0, len(e.values)+len(e.offsets))
-	if len(e.offsets) > 0 {
-		var pos uint32
-		for _, offs := range e.offsets[1:] {
-			buf = append(buf, byte(offs-pos))
-			buf = append(buf, e.values[pos:offs]...)
-			pos = offs
+// Values are stored with uvarint length prefixes. Values shorter than
+// 128 bytes use the same single byte length as earlier versions.
+// Dictionaries with values up to EnumMaxSize bytes use the enum format
+// with a single length byte per value. Longer dictionary values use a
+// version tagged format with uvarint lengths. Its header looks like two
+// empty values which cannot occur in the enum format since values are
+// unique.
+var enumLongHeader = []byte{0, 0, 1}
+
+func (e *EnumDictionary) MarshalBinary() ([]byte, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	long := false
+	for i := range e.offsets {
+		if len(e.value(i)) > EnumMaxSize {
+			long = true
+			break
+		}
+	}
+	buf := make([]byte, 0, len(e.values)+len(e.offsets)+len(enumLongHeader))
+	if long {
+		buf = append(buf, enumLongHeader...)
+	}
+	for i := range e.offsets {
+		v := e.value(i)
+		if long {
+			buf = binary.AppendUvarint(buf, uint64(len(v)))
+		} else {
+			buf = append(buf, byte(len(v)))
 		}
-		buf = append(buf, byte(len(e.values)-int(pos)))
-		buf = append(buf, e.values[pos:]...)
+		buf = append(buf, v...)
 	}
 	return buf, nil
 }
 
 func (e *EnumDictionary) UnmarshalBinary(buf []byte) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	e.values = e.values[:0]
 	e.offsets = e.offsets[:0]
+	clear(e.codes)
+	long := bytes.HasPrefix(buf, enumLongHeader)
+	if long {
+		buf = buf[len(enumLongHeader):]
+	}
 	for len(buf) > 0 {
-		sz := buf[0]
-		buf = buf[1:]
-		e.codes[hash.Hash(buf[:sz])] = uint16(len(e.offsets))
-		e.offsets = append(e.offsets, uint32(len(e.values)))
-		e.values = append(e.values, buf[:sz]...)
+		sz, n := uint64(buf[0]), 1
+		if long {
+			sz, n = binary.Uvarint(buf)
+		}
+		if n <= 0 || uint64(len(buf)-n) < sz {
+			return ErrShortBuffer
+		}
+		buf = buf[n:]
+		e.add(hash.Hash(buf[:sz]), buf[:sz])
 		buf = buf[sz:]
 	}
 	return nil
 }
 
+func (e *EnumDictionary) add(h uint64, v []byte) {
+	e.codes[h] = uint32(len(e.offsets))
+	e.offsets = append(e.offsets, uint32(len(e.values)))
+	e.values = append(e.values, v...)
+}
+
 func (e *EnumDictionary) value(i int) string {
 	start, end := int(e.offsets[i]), len(e.values)
 	if i < len(e.offsets)-1 {
@@ -229,7 +335,7 @@ func (e *EnumDictionary) CastValue(val any) (any, error) {
 		}
 		return code, nil
 	case uint16:
-		if int(v) >= len(e.offsets) {
+		if int(v) >= e.Len() {
 			return nil, fmt.Errorf("invalid enum code %d", v)
 		}
 		return v, nil
@@ -261,8 +367,9 @@ func (e *EnumDictionary) CastSlice(val any) (any, error) {
 		}
 		return codes, nil
 	case []uint16:
+		n := e.Len()
 		for _, vv := range v {
-			if int(vv) >= len(e.offsets) {
+			if int(vv) >= n {
 				return nil, fmt.Errorf("invalid enum code %d", vv)
 			}
 		}
@@ -271,3 +378,76 @@ func (e *EnumDictionary) CastSlice(val any) (any, error) {
 		return nil, castError(val, "enum")
 	}
 }
+
+// Dict returns a caster and parser for dictionary fields which translates
+// values into uint32 codes. Values that are not in the dictionary translate
+// to DictNoCode which matches no row.
+func (e *EnumDictionary) Dict() DictCaster {
+	return DictCaster{e}
+}
+
+type DictCaster struct {
+	dict *EnumDictionary
+}
+
+var (
+	_ ValueCaster = DictCaster{}
+	_ ValueParser = DictCaster{}
+)
+
+func (c DictCaster) code(val string) uint32 {
+	code, ok := c.dict.Code32(val)
+	if !ok {
+		return DictNoCode
+	}
+	return code
+}
+
+// ValueParser interface
+func (c DictCaster) ParseValue(s string) (any, error) {
+	return c.code(s), nil
+}
+
+func (c DictCaster) ParseSlice(s string) (any, error) {
+	vals := strings.Split(s, ",")
+	codes := make([]uint32, len(vals))
+	for i, v := range vals {
+		codes[i] = c.code(v)
+	}
+	return codes, nil
+}
+
+// ValueCaster interface
+func (c DictCaster) CastValue(val any) (any, error) {
+	switch v := val.(type) {
+	case string:
+		return c.code(v), nil
+	case []byte:
+		return c.code(string(v)), nil
+	case uint32:
+		return v, nil
+	default:
+		return nil, castError(val, "dict")
+	}
+}
+
+func (c DictCaster) CastSlice(val any) (any, error) {
+	switch v := val.(type) {
+	case []string:
+		codes := make([]uint32, len(v))
+		for i, vv := range v {
+			codes[i] = c.code(vv)
+		}
+		return codes, nil
+	case [][]byte:
+		codes := make([]uint32, len(v))
+		for i, vv := range v {
+			codes[i] = c.code(string(vv))
+		}
+		return codes, nil
+	case []uint32:
+		return v, nil
+	default:
+		return nil, castError(val, "dict")
+	}
+}
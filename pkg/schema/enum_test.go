@@ -4,6 +4,8 @@
 package schema
 
 import (
+	"strconv"
+	"strings"
 	"testing"
 
 	"blockwatch.cc/knoxdb/pkg/util"
@@ -124,6 +126,35 @@ func TestEnumMarshal(t *testing.T) {
 	assert.Equal(t, v, "a")
 }
 
+func TestEnumMarshalFormat(t *testing.T) {
+	// enum values keep a single length byte, also above 127 bytes
+	d := NewEnumDictionary("")
+	v200 := strings.Repeat("a", 200)
+	require.NoError(t, d.Append(v200, "", "b"))
+	buf, err := d.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, append(append([]byte{200}, v200...), 0, 1, 'b'), buf)
+
+	// stored enum data decodes unchanged
+	d2 := NewEnumDictionary("")
+	require.NoError(t, d2.UnmarshalBinary(buf))
+	require.Equal(t, []string{v200, "", "b"}, d2.Values())
+
+	// long dictionary values use the tagged format
+	long := strings.Repeat("x", 300)
+	_, err = d.Put(long)
+	require.NoError(t, err)
+	buf, err = d.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, enumLongHeader, buf[:len(enumLongHeader)])
+	d3 := NewEnumDictionary("")
+	require.NoError(t, d3.UnmarshalBinary(buf))
+	require.Equal(t, []string{v200, "", "b", long}, d3.Values())
+
+	// truncated data fails
+	require.ErrorIs(t, d3.UnmarshalBinary(buf[:len(buf)-1]), ErrShortBuffer)
+}
+
 var enumBenchSizes = []struct {
 	name string
 	num  int
@@ -258,3 +289,72 @@ func TestEnumConversionScenarios(t *testing.T) {
 	_, err = d.CastSlice([]int{1, 2, 3}) // Pass a slice of integers
 	assert.Error(t, err, "CastSlice should fail for non-string slice")
 }
+
+func TestEnumPut(t *testing.T) {
+	d := NewEnumDictionary("")
+	require.NoError(t, d.Append("a"))
+
+	// put returns existing and new codes
+	c, err := d.Put("a")
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), c)
+	c, err = d.Put("b")
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), c)
+	require.Equal(t, []string{"b"}, d.ValuesFrom(1))
+	require.Nil(t, d.ValuesFrom(2))
+
+	// dictionaries grow beyond the enum code space
+	for i := d.Len(); i <= EnumMaxValues; i++ {
+		_, err := d.Put(strconv.Itoa(i))
+		require.NoError(t, err)
+	}
+	c, ok := d.Code32(strconv.Itoa(EnumMaxValues))
+	require.True(t, ok)
+	require.Equal(t, uint32(EnumMaxValues), c)
+	v, ok := d.Value32(c)
+	require.True(t, ok)
+	require.Equal(t, strconv.Itoa(EnumMaxValues), v)
+	_, ok = d.Code(strconv.Itoa(EnumMaxValues))
+	require.False(t, ok, "no uint16 code")
+
+	// long values roundtrip
+	long := strings.Repeat("x", 300)
+	c, err = d.Put(long)
+	require.NoError(t, err)
+	buf, err := d.MarshalBinary()
+	require.NoError(t, err)
+	d2 := NewEnumDictionary("")
+	require.NoError(t, d2.UnmarshalBinary(buf))
+	require.Equal(t, d.Len(), d2.Len())
+	v, ok = d2.Value32(c)
+	require.True(t, ok)
+	require.Equal(t, long, v)
+	c2, ok := d2.Code32("b")
+	require.True(t, ok)
+	require.Equal(t, uint32(1), c2)
+
+	_, err = d.Put(strings.Repeat("x", DictMaxSize+1))
+	require.Error(t, err)
+}
+
+func TestEnumDictCaster(t *testing.T) {
+	d := NewEnumDictionary("")
+	d.Append("a", "b")
+	c := d.Dict()
+
+	v, err := c.ParseValue("b")
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), v)
+	v, err = c.CastValue("x")
+	require.NoError(t, err)
+	require.Equal(t, uint32(DictNoCode), v, "unknown values")
+	v, err = c.CastSlice([]string{"a", "x"})
+	require.NoError(t, err)
+	require.Equal(t, []uint32{0, DictNoCode}, v)
+	v, err = c.ParseSlice("a,b")
+	require.NoError(t, err)
+	require.Equal(t, []uint32{0, 1}, v)
+	_, err = c.CastValue(1)
+	require.Error(t, err)
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IndexExprType defines a function used to compute index keys from
+// a single base field.
+type IndexExprType byte
+
+const (
+	IndexExprNone       IndexExprType = iota // field value as is
+	IndexExprLower                           // lower(string|bytes)
+	IndexExprPrefix                          // prefix(string|bytes, n)
+	IndexExprTimeBucket                      // time_bucket(time, interval)
+)
+
+var indexExprNames = [...]string{
+	IndexExprNone:       "",
+	IndexExprLower:      "lower",
+	IndexExprPrefix:     "prefix",
+	IndexExprTimeBucket: "time_bucket",
+}
+
+func (t IndexExprType) String() string {
+	if int(t) < len(indexExprNames) {
+		return indexExprNames[t]
+	}
+	return "expr_" + strconv.Itoa(int(t))
+}
+
+// IndexExpr is a computed key expression over a single field. Indexes
+// store the computed value and queries use the same expression on their
+// filter conditions so that the planner can match both.
+type IndexExpr struct {
+	Type IndexExprType
+	Arg  int64 // prefix length or bucket interval in nanoseconds
+}
+
+// Lower returns an expression which lower cases string and byte values.
+func Lower() IndexExpr {
+	return IndexExpr{Type: IndexExprLower}
+}
+
+// Prefix returns an expression which truncates string and byte values
+// to their first n bytes.
+func Prefix(n int) IndexExpr {
+	return IndexExpr{Type: IndexExprPrefix, Arg: int64(n)}
+}
+
+// TimeBucket returns an expression which truncates time values to
+// the start of their enclosing interval d.
+func TimeBucket(d time.Duration) IndexExpr {
+	return IndexExpr{Type: IndexExprTimeBucket, Arg: int64(d)}
+}
+
+// ParseIndexExpr parses expressions in the format used by struct tags,
+// i.e. `lower`, `prefix(8)`, `time_bucket(1h)` or `time_bucket(1d)`.
+func ParseIndexExpr(s string) (IndexExpr, error) {
+	name, arg, hasArg := strings.Cut(strings.TrimSpace(s), "(")
+	if hasArg {
+		if !strings.HasSuffix(arg, ")") {
+			return IndexExpr{}, fmt.Errorf("invalid index expression %q", s)
+		}
+		arg = strings.TrimSpace(strings.TrimSuffix(arg, ")"))
+	}
+	switch name {
+	case "lower":
+		if hasArg {
+			return IndexExpr{}, fmt.Errorf("invalid index expression %q", s)
+		}
+		return Lower(), nil
+	case "prefix":
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return IndexExpr{}, fmt.Errorf("invalid prefix length %q", arg)
+		}
+		return Prefix(n), nil
+	case "time_bucket":
+		var (
+			d   time.Duration
+			err error
+		)
+		if days, ok := strings.CutSuffix(arg, "d"); ok {
+			var n int
+			n, err = strconv.Atoi(days)
+			d = time.Duration(n) * 24 * time.Hour
+		} else {
+			d, err = time.ParseDuration(arg)
+		}
+		if err != nil {
+			return IndexExpr{}, fmt.Errorf("invalid bucket interval %q", arg)
+		}
+		return TimeBucket(d), nil
+	default:
+		return IndexExpr{}, fmt.Errorf("unsupported index expression %q", s)
+	}
+}
+
+func (e IndexExpr) IsValid() bool {
+	return e.Type != IndexExprNone
+}
+
+func (e IndexExpr) String() string {
+	switch e.Type {
+	case IndexExprNone:
+		return ""
+	case IndexExprPrefix:
+		return fmt.Sprintf("prefix(%d)", e.Arg)
+	case IndexExprTimeBucket:
+		return fmt.Sprintf("time_bucket(%s)", time.Duration(e.Arg))
+	default:
+		return e.Type.String()
+	}
+}
+
+// Format returns a human readable expression on field name.
+func (e IndexExpr) Format(name string) string {
+	switch e.Type {
+	case IndexExprNone:
+		return name
+	case IndexExprPrefix:
+		return fmt.Sprintf("prefix(%s, %d)", name, e.Arg)
+	case IndexExprTimeBucket:
+		return fmt.Sprintf("time_bucket(%s, %s)", name, time.Duration(e.Arg))
+	default:
+		return fmt.Sprintf("%s(%s)", e.Type, name)
+	}
+}
+
+// Validate checks whether the expression is applicable to field f.
+func (e IndexExpr) Validate(f *Field) error {
+	switch e.Type {
+	case IndexExprNone:
+		return nil
+	case IndexExprLower, IndexExprPrefix:
+		switch f.Type {
+		case FT_STRING, FT_BYTES:
+		default:
+			return fmt.Errorf("expression %s unsupported on field %s type %s", e, f.Name, f.Type)
+		}
+		if e.Type == IndexExprPrefix && e.Arg <= 0 {
+			return fmt.Errorf("invalid prefix length %d", e.Arg)
+		}
+	case IndexExprTimeBucket:
+		switch f.Type {
+		case FT_TIMESTAMP, FT_DATE, FT_TIME:
+		default:
+			return fmt.Errorf("expression %s unsupported on field %s type %s", e, f.Name, f.Type)
+		}
+		if e.Arg <= 0 {
+			return fmt.Errorf("invalid bucket interval %s", time.Duration(e.Arg))
+		}
+	default:
+		return fmt.Errorf("invalid index expression type %d", e.Type)
+	}
+	return nil
+}
+
+// IsMonotonic returns true when the expression preserves value order,
+// i.e. a <= b implies e(a) <= e(b). Min/max statistics of monotonic
+// expressions can be computed from base field statistics.
+func (e IndexExpr) IsMonotonic() bool {
+	switch e.Type {
+	case IndexExprNone, IndexExprPrefix, IndexExprTimeBucket:
+		return true
+	default:
+		return false
+	}
+}
+
+// ApplyBytes computes the expression on a string or bytes value.
+// The result may share memory with b.
+func (e IndexExpr) ApplyBytes(b []byte) []byte {
+	switch e.Type {
+	case IndexExprLower:
+		return bytes.ToLower(b)
+	case IndexExprPrefix:
+		return b[:min(len(b), int(e.Arg))]
+	default:
+		return b
+	}
+}
+
+// ApplyInt computes the expression on a time value in the wire format
+// (scaled unix timestamp) of field f.
+func (e IndexExpr) ApplyInt(v int64, f *Field) int64 {
+	if e.Type != IndexExprTimeBucket {
+		return v
+	}
+	d := max(1, e.Arg/timeScaleFactor[f.Scale])
+	r := v % d
+	if r < 0 {
+		r += d
+	}
+	return v - r
+}
@@ -6,6 +6,7 @@ package schema
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"io"
 	"math"
@@ -13,6 +14,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unsafe"
 
 	"blockwatch.cc/knoxdb/internal/types"
 	"blockwatch.cc/knoxdb/pkg/num"
@@ -23,6 +25,7 @@ type (
 	FieldFlags = types.FieldFlags
 	IndexType  = types.IndexType
 	FilterType = types.FilterType
+	Logical    = types.LogicalType
 )
 
 const (
@@ -49,6 +52,8 @@ const (
 	FT_BIGINT    = types.FieldTypeBigint
 	FT_TIME      = types.FieldTypeTime
 	FT_DATE      = types.FieldTypeDate
+	FT_LIST      = types.FieldTypeList
+	FT_DOC       = types.FieldTypeDocument
 
 	F_PRIMARY  = types.FieldFlagPrimary
 	F_TIMEBASE = types.FieldFlagTimebase
@@ -56,11 +61,14 @@ const (
 	F_DELETED  = types.FieldFlagDeleted
 	F_METADATA = types.FieldFlagMetadata
 	F_NULLABLE = types.FieldFlagNullable
+	F_VALIDITY = types.FieldFlagValidity
 
 	I_HASH      = types.IndexTypeHash
 	I_INT       = types.IndexTypeInt
 	I_PK        = types.IndexTypePk
 	I_COMPOSITE = types.IndexTypeComposite
+	I_TOKEN     = types.IndexTypeToken
+	I_BITMAP    = types.IndexTypeBitmap
 
 	FL_BITS    = types.FilterTypeBits
 	FL_BLOOM2B = types.FilterTypeBloom2b
@@ -69,6 +77,11 @@ const (
 	FL_BLOOM5B = types.FilterTypeBloom5b
 	FL_BFUSE8  = types.FilterTypeBfuse8
 	FL_BFUSE16 = types.FilterTypeBfuse16
+
+	LT_UUID   = types.LogicalTypeUUID
+	LT_IP     = types.LogicalTypeIP
+	LT_HASH32 = types.LogicalTypeHash32
+	LT_ADDR20 = types.LogicalTypeAddr20
 )
 
 type Field struct {
@@ -80,15 +93,28 @@ type Field struct {
 	Compress types.BlockCompression // data compression from struct tag
 	Filter   FilterType             // metadata filter type
 	Fixed    uint16                 // 0..65535 fixed size array/bytes/string length
-	Scale    uint8                  // 0..255 fixed point scale, time scale
+	Scale    uint8                  // 0..255 fixed point scale, time scale, logical type
+	Elem     FieldType              // list element type
 
 	// encoder values for INSERT, UPDATE, QUERY
 	Path   []int           // reflect struct nested positions
 	Offset uintptr         // struct field offset from reflect
 	Size   uint16          // wire encoding field size in bytes, min size for []byte & string
 	Enum   *EnumDictionary // ptr to enum dictionary when field is an enum
+	Null   NullKind        // Go representation of NULL values for nullable fields
+	Valid  uintptr         // valid flag offset relative to Offset (NullValid only)
+	Doc    reflect.Type    // Go type of document fields (nil = json.RawMessage)
 }
 
+// NullKind defines how a nullable struct field represents NULL in Go.
+type NullKind byte
+
+const (
+	NullNone    NullKind = iota // value type, always valid
+	NullPointer                 // pointer type, nil is NULL (e.g. *int64)
+	NullValid                   // value with valid flag (e.g. sql.NullInt64)
+)
+
 func NewField(typ FieldType) *Field {
 	return &Field{
 		Type: typ,
@@ -101,14 +127,21 @@ func (f *Field) Clone() *Field {
 	return &clone
 }
 
+// WireSize returns the wire encoding size of a field. Nullable fields with
+// validity are prefixed with a single byte validity flag (1 = valid, 0 = NULL)
+// followed by a zero value payload when NULL.
 func (f *Field) WireSize() int {
+	sz := int(f.Size)
 	switch f.Type {
 	case FT_STRING, FT_BYTES:
 		if f.Fixed > 0 {
-			return int(f.Fixed)
+			sz = int(f.Fixed)
 		}
 	}
-	return int(f.Size)
+	if f.HasValidity() {
+		sz++
+	}
+	return sz
 }
 
 func (f *Field) IsValid() bool {
@@ -143,19 +176,78 @@ func (f *Field) IsNullable() bool {
 	return f.Flags.Is(F_NULLABLE)
 }
 
+// HasValidity reports whether the wire encoding of a nullable field carries
+// a validity byte. Fields declared nullable before NULL values were supported
+// lack this flag, keep their wire format and are always valid.
+func (f *Field) HasValidity() bool {
+	return f.Flags&(F_NULLABLE|F_VALIDITY) == F_NULLABLE|F_VALIDITY
+}
+
+// ValuePtr resolves the value of a nullable struct field at ptr and reports
+// whether the value is valid. Returns nil for NULL pointer fields.
+func (f *Field) ValuePtr(ptr unsafe.Pointer) (unsafe.Pointer, bool) {
+	switch f.Null {
+	case NullPointer:
+		p := *(*unsafe.Pointer)(ptr)
+		return p, p != nil
+	case NullValid:
+		return ptr, *(*bool)(unsafe.Add(ptr, f.Valid))
+	default:
+		return ptr, true
+	}
+}
+
+// SetValid marks a nullable struct field at ptr as valid or NULL and returns
+// the value pointer to decode into. Pointer fields are set to a newly
+// allocated value or nil when NULL.
+func (f *Field) SetValid(ptr unsafe.Pointer, valid bool) unsafe.Pointer {
+	switch f.Null {
+	case NullPointer:
+		if !valid {
+			*(*unsafe.Pointer)(ptr) = nil
+			return nil
+		}
+		p := reflect.New(f.GoType()).UnsafePointer()
+		*(*unsafe.Pointer)(ptr) = p
+		return p
+	case NullValid:
+		*(*bool)(unsafe.Add(ptr, f.Valid)) = valid
+		return ptr
+	default:
+		return ptr
+	}
+}
+
 func (f *Field) IsEnum() bool {
 	return f.Flags.Is(F_ENUM)
 }
 
+// IsDict returns true for dictionary encoded string fields which use
+// 32 bit enum codes.
+func (f *Field) IsDict() bool {
+	return f.Flags.Is(F_ENUM) && f.Type == FT_U32
+}
+
 func (f *Field) IsFixedSize() bool {
 	switch f.Type {
 	case FT_STRING, FT_BYTES:
 		return f.Fixed > 0
+	case FT_LIST, FT_DOC, FT_BIGINT:
+		return false
 	default:
 		return true
 	}
 }
 
+// Logical returns the logical type of fixed size byte fields which is
+// stored in the scale attribute.
+func (f *Field) Logical() Logical {
+	if f.Type != FT_BYTES {
+		return types.LogicalTypeNone
+	}
+	return Logical(f.Scale)
+}
+
 func (f *Field) IsCompressed() bool {
 	return f.Compress > types.BlockCompressNone
 }
@@ -179,9 +271,13 @@ func (f *Field) TypeName() (typ string) {
 	case FT_D32, FT_D64, FT_D128, FT_D256:
 		typ += "(" + strconv.Itoa(int(f.Scale)) + ")"
 	case FT_STRING, FT_BYTES:
-		if f.Fixed > 0 {
+		if l := f.Logical(); l > 0 {
+			typ = l.String()
+		} else if f.Fixed > 0 {
 			typ = "[" + strconv.Itoa(int(f.Fixed)) + "]" + typ
 		}
+	case FT_LIST:
+		typ += "(" + f.Elem.String() + ")"
 	}
 	return
 }
@@ -194,6 +290,7 @@ func ParseFieldFromTypename(typ string) (*Field, error) {
 		f     *Field
 		fixed uint16
 		scale uint8
+		elem  FieldType
 	)
 	if typ[0] == '[' {
 		num, typstr, ok := strings.Cut(typ[1:], "]")
@@ -214,7 +311,9 @@ func ParseFieldFromTypename(typ string) (*Field, error) {
 			}
 			scalestr = strings.TrimSuffix(scalestr, ")")
 			n, err := strconv.Atoi(scalestr)
-			if err == nil {
+			if typstr == FT_LIST.String() {
+				elem = types.ParseFieldType(scalestr)
+			} else if err == nil {
 				scale = uint8(n)
 			} else {
 				tscale, ok := ParseTimeScale(scalestr)
@@ -226,6 +325,10 @@ func ParseFieldFromTypename(typ string) (*Field, error) {
 		}
 		typ = typstr
 	}
+	if l := types.ParseLogicalType(typ); l > 0 && fixed == 0 {
+		f = NewField(FT_BYTES).WithLogical(l)
+		return f, f.Validate()
+	}
 	ty := types.ParseFieldType(typ)
 	if !ty.IsValid() {
 		return nil, fmt.Errorf("invalid array type: %s", typ)
@@ -234,6 +337,7 @@ func ParseFieldFromTypename(typ string) (*Field, error) {
 		Type:  ty,
 		Fixed: fixed,
 		Scale: scale,
+		Elem:  elem,
 	}
 	return f, f.Validate()
 }
@@ -242,9 +346,18 @@ func (f *Field) GoType() reflect.Type {
 	if f.Type == FT_BYTES && f.Fixed > 0 {
 		return reflect.ArrayOf(int(f.Fixed), reflect.TypeFor[byte]())
 	}
-	if f.Type == FT_U16 && f.IsEnum() {
+	if (f.Type == FT_U16 || f.Type == FT_U32) && f.IsEnum() {
 		return reflect.TypeFor[string]()
 	}
+	if f.Type == FT_LIST {
+		return reflect.SliceOf(reflect.TypeOf(f.Elem.Zero()))
+	}
+	if f.Type == FT_DOC {
+		if f.Doc != nil {
+			return f.Doc
+		}
+		return reflect.TypeFor[json.RawMessage]()
+	}
 	return reflect.TypeOf(f.Type.Zero())
 }
 
@@ -273,11 +386,24 @@ func (f *Field) WithScale(n uint8) *Field {
 	return f
 }
 
+// WithLogical turns f into a fixed size byte field of logical type l.
+func (f *Field) WithLogical(l Logical) *Field {
+	f.Type = FT_BYTES
+	f.Fixed = uint16(l.Size())
+	f.Scale = uint8(l)
+	return f
+}
+
 func (f *Field) WithFilter(typ FilterType) *Field {
 	f.Filter = typ
 	return f
 }
 
+func (f *Field) WithElem(typ FieldType) *Field {
+	f.Elem = typ
+	return f
+}
+
 func (f *Field) Validate() error {
 	// require scale on decimal fields only
 	if f.Scale != 0 {
@@ -298,6 +424,8 @@ func (f *Field) Validate() error {
 		case FT_DATE:
 			minScale = uint8(TIME_SCALE_DAY)
 			maxScale = uint8(TIME_SCALE_DAY)
+		case FT_BYTES:
+			maxScale = uint8(LT_ADDR20)
 		default:
 			return fmt.Errorf("field[%s]: scale unsupported on type %s", f.Name, f.Type)
 		}
@@ -313,6 +441,11 @@ func (f *Field) Validate() error {
 		}
 	}
 
+	// require known compression codecs and levels
+	if !f.Compress.IsValid() {
+		return fmt.Errorf("field[%s]: invalid compression %d", f.Name, f.Compress)
+	}
+
 	// require fixed on string/byte fields only
 	if f.Fixed != 0 {
 		if _, err := validateInt("fixed", int(f.Fixed), 1, int(MAX_FIXED)); err != nil {
@@ -326,9 +459,37 @@ func (f *Field) Validate() error {
 		}
 	}
 
-	// require uint16 for enum types
-	if f.Flags.Is(F_ENUM) && f.Type != FT_U16 {
-		return fmt.Errorf("field[%s]: invalid type %s for enum, requires uint16", f.Name, f.Type)
+	// require logical types to match their fixed storage size
+	if l := f.Logical(); l > 0 && int(f.Fixed) != l.Size() {
+		return fmt.Errorf("field[%s]: logical type %s requires fixed size %d", f.Name, l, l.Size())
+	}
+
+	// require uint16 for enum types and uint32 for dictionary types
+	if f.Flags.Is(F_ENUM) && f.Type != FT_U16 && f.Type != FT_U32 {
+		return fmt.Errorf("field[%s]: invalid type %s for enum, requires uint16 or uint32", f.Name, f.Type)
+	}
+
+	// require fixed size numeric elements on list fields only
+	if f.Type == FT_LIST {
+		switch f.Elem {
+		case FT_I64, FT_I32, FT_I16, FT_I8, FT_U64, FT_U32, FT_U16, FT_U8, FT_F64, FT_F32:
+			// ok
+		default:
+			return fmt.Errorf("field[%s]: invalid list element type %s", f.Name, f.Elem)
+		}
+		switch f.Filter {
+		case 0, FL_BLOOM2B, FL_BLOOM3B, FL_BLOOM4B, FL_BLOOM5B:
+			// ok
+		default:
+			return fmt.Errorf("field[%s]: unsupported filter %s on list type", f.Name, f.Filter)
+		}
+	} else if f.Elem != 0 {
+		return fmt.Errorf("field[%s]: element type unsupported on type %s", f.Name, f.Type)
+	}
+
+	// documents are queried by path and cannot use pack filters
+	if f.Type == FT_DOC && f.Filter != 0 {
+		return fmt.Errorf("field[%s]: unsupported filter %s on document type", f.Name, f.Filter)
 	}
 
 	// require timebase flag only to be used with timestamp fields
@@ -370,6 +531,9 @@ func (f *Field) Codec() OpCode {
 		return OpCodeUint64
 
 	case FT_U32:
+		if f.Flags.Is(F_ENUM) {
+			return OpCodeDict
+		}
 		return OpCodeUint32
 
 	case FT_U16:
@@ -425,6 +589,12 @@ func (f *Field) Codec() OpCode {
 	case FT_BIGINT:
 		return OpCodeBigInt
 
+	case FT_LIST:
+		return OpCodeList
+
+	case FT_DOC:
+		return OpCodeDocument
+
 	default:
 		return OpCodeInvalid
 	}
@@ -523,11 +693,27 @@ func (f *Field) Encode(w io.Writer, val any, layout binary.ByteOrder) (err error
 	case OpCodeEnum:
 		err = EncodeInt(w, OpCodeUint16, val.(uint16), layout)
 
+	case OpCodeDict:
+		err = EncodeInt(w, OpCodeUint32, val.(uint32), layout)
+
 	case OpCodeBigInt:
 		v, ok := val.(num.Big)
 		if ok {
 			err = EncodeBytes(w, v.Bytes(), 0, layout)
 		}
+
+	case OpCodeList:
+		b, ok := types.ListBytes(val)
+		if ok {
+			err = EncodeBytes(w, b, 0, layout)
+		}
+
+	case OpCodeDocument:
+		var b []byte
+		b, err = types.DocumentBytes(val)
+		if err == nil {
+			err = EncodeBytes(w, b, 0, layout)
+		}
 	}
 	return
 }
@@ -670,6 +856,26 @@ func (f *Field) Decode(r io.Reader, layout binary.ByteOrder) (val any, err error
 		n, err = r.Read(b)
 		val = num.NewBigFromBytes(b[:n])
 
+	case FT_LIST:
+		_, err = r.Read(buf[:4])
+		if err != nil {
+			return
+		}
+		u32 := layout.Uint32(buf[:4])
+		b := make([]byte, int(u32))
+		n, err = r.Read(b)
+		val = types.ListValue(f.Elem.BlockType(), b[:n])
+
+	case FT_DOC:
+		_, err = r.Read(buf[:4])
+		if err != nil {
+			return
+		}
+		u32 := layout.Uint32(buf[:4])
+		b := make([]byte, int(u32))
+		n, err = r.Read(b)
+		val = json.RawMessage(b[:n])
+
 	default:
 		err = ErrInvalidField
 	}
@@ -712,6 +918,11 @@ func (f *Field) WriteTo(w *bytes.Buffer) error {
 	// scale: u8
 	binary.Write(w, LE, f.Scale)
 
+	// elem: u8 (list fields only)
+	if f.Type == FT_LIST {
+		w.WriteByte(byte(f.Elem))
+	}
+
 	return nil
 }
 
@@ -752,6 +963,14 @@ func (f *Field) ReadFrom(buf *bytes.Buffer) (err error) {
 	// scale: u8
 	binary.Read(buf, LE, &f.Scale)
 
+	// elem: u8 (list fields only)
+	if f.Type == FT_LIST {
+		if buf.Len() < 1 {
+			return io.ErrShortBuffer
+		}
+		f.Elem = types.FieldType(buf.Next(1)[0])
+	}
+
 	// init related properties
 	f.Size = uint16(f.Type.Size())
 
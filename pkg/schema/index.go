@@ -25,13 +25,35 @@ import (
 // F1      int       `"knox:Y,index=hash"`
 // F2      int       `"knox:Z,index=int,extra=X+Y"`
 // _       struct{}  `"knox:idx,index=composite,fields=X+Y,extra=Z+X"`
+// F3      string    `"knox:N,index=hash,expr=lower"`
+// F4      string    `"knox:M,index=token,tokenizer=ngram(3)"`
 
 type IndexSchema struct {
-	Name   string    // index name
-	Type   IndexType // index type: hash, int, composite
-	Base   *Schema   // base schema
-	Fields []*Field  // indexed fields in order
-	Extra  []*Field  // extra (inline) fields
+	Name      string         // index name
+	Type      IndexType      // index type: hash, int, composite, token
+	Base      *Schema        // base schema
+	Fields    []*Field       // indexed fields in order
+	Extra     []*Field       // extra (inline) fields
+	Predicate IndexPredicate // optional row filter for partial indexes
+	Expr      IndexExpr      // optional computed key expression
+	Tokenizer Tokenizer      // term tokenizer (token index only)
+}
+
+// IndexPredicate restricts a partial index to base table rows matching
+// a condition. Predicates are implemented outside this package (see
+// query.Condition) and stored in binary form.
+type IndexPredicate interface {
+	Fields() []string
+	MarshalBinary() ([]byte, error)
+	String() string
+}
+
+var predicateDecoder func([]byte) (IndexPredicate, error)
+
+// RegisterPredicateDecoder installs the decoder used to restore index
+// predicates from their binary form.
+func RegisterPredicateDecoder(fn func([]byte) (IndexPredicate, error)) {
+	predicateDecoder = fn
 }
 
 func IndexesOf(m any) ([]*IndexSchema, error) {
@@ -60,6 +82,31 @@ func NewIndexSchema(typ IndexType, s *Schema, f ...*Field) *IndexSchema {
 	}
 }
 
+// WithPredicate turns the index into a partial index which only contains
+// rows matching predicate p.
+func (s *IndexSchema) WithPredicate(p IndexPredicate) *IndexSchema {
+	s.Predicate = p
+	return s
+}
+
+// WithExpr makes the index store computed keys from expression e
+// on its first field instead of raw field values.
+func (s *IndexSchema) WithExpr(e IndexExpr) *IndexSchema {
+	s.Expr = e
+	return s
+}
+
+// WithTokenizer sets the tokenizer used by token indexes.
+func (s *IndexSchema) WithTokenizer(t Tokenizer) *IndexSchema {
+	s.Tokenizer = t
+	return s
+}
+
+// IsPartial returns true when the index has a row filter predicate.
+func (s *IndexSchema) IsPartial() bool {
+	return s.Predicate != nil
+}
+
 func (s *IndexSchema) IsValid() bool {
 	return s.Type.IsValid() && len(s.Fields) > 0
 }
@@ -101,6 +148,24 @@ func (s *IndexSchema) Hash() uint64 {
 		hashField(f)
 	}
 
+	// predicate
+	if s.Predicate != nil {
+		buf, _ := s.Predicate.MarshalBinary()
+		h.Write(buf)
+	}
+
+	// expression
+	if s.Expr.IsValid() {
+		LE.PutUint64(b[:], uint64(s.Expr.Arg))
+		h.Write([]byte{byte(s.Expr.Type)})
+		h.Write(b[:])
+	}
+
+	// tokenizer
+	if s.Type == I_TOKEN {
+		h.Write([]byte{byte(s.Tokenizer.Type), byte(s.Tokenizer.N)})
+	}
+
 	return h.Sum64()
 }
 
@@ -208,7 +273,7 @@ func (s *IndexSchema) StorageSchema() (*Schema, error) {
 			Uint64("hash").
 			Uint64("rid", Id(MetaRid))
 
-	case I_INT:
+	case I_INT, I_BITMAP:
 		// int -> rid
 		b = NewBuilder().
 			WithName(s.Name).
@@ -223,6 +288,14 @@ func (s *IndexSchema) StorageSchema() (*Schema, error) {
 			WithVersion(s.Base.Version).
 			Uint64("hash").
 			Uint64("rid", Id(MetaRid))
+
+	case I_TOKEN:
+		// hash(term) -> rid
+		b = NewBuilder().
+			WithName(s.Name).
+			WithVersion(s.Base.Version).
+			Uint64("hash").
+			Uint64("rid", Id(MetaRid))
 	}
 
 	// add extra fields (assign new ids)
@@ -267,6 +340,40 @@ func (s *IndexSchema) Validate() error {
 		}
 	}
 
+	// predicate fields must be defined in base schema
+	if s.Predicate != nil {
+		names := s.Predicate.Fields()
+		if len(names) == 0 {
+			return fmt.Errorf("index[%s]: empty predicate", s.Name)
+		}
+		for _, n := range names {
+			if _, ok := s.Base.Find(n); !ok {
+				return fmt.Errorf("index[%s]: predicate field %s not in base schema %s",
+					s.Name, n, s.Base.Name)
+			}
+		}
+		if s.Type == I_PK {
+			return fmt.Errorf("index[%s]: primary index cannot be partial", s.Name)
+		}
+	}
+
+	// key expressions work on a single hash or int index field
+	if s.Expr.IsValid() {
+		switch s.Type {
+		case I_HASH:
+			// ok
+		case I_INT:
+			if s.Expr.Type != IndexExprTimeBucket {
+				return fmt.Errorf("index[%s]: expression %s requires hash index", s.Name, s.Expr)
+			}
+		default:
+			return fmt.Errorf("index[%s]: expression unsupported on %s index", s.Name, s.Type)
+		}
+		if err := s.Expr.Validate(s.Fields[0]); err != nil {
+			return fmt.Errorf("index[%s]: %v", s.Name, err)
+		}
+	}
+
 	// fields and extra lists must not contain duplicate entries
 	unique := make(map[uint16]struct{})
 	for _, f := range s.Fields {
@@ -283,6 +390,13 @@ func (s *IndexSchema) Validate() error {
 		unique[f.Id] = struct{}{}
 	}
 
+	// documents are only queried by path
+	for _, f := range s.Fields {
+		if f.Type == FT_DOC {
+			return fmt.Errorf("index[%s]: unsupported index on document field %s", s.Name, f.Name)
+		}
+	}
+
 	// check type-specific restrictions
 	switch s.Type {
 	case I_INT:
@@ -323,6 +437,45 @@ func (s *IndexSchema) Validate() error {
 		if len(s.Fields) < 2 {
 			return fmt.Errorf("index[%s]: composite index requires at least 2 fields", s.Name)
 		}
+
+	case I_TOKEN:
+		// requires single string field, rows expand into multiple terms
+		// so extra fields are not supported
+		if len(s.Fields) > 1 {
+			return fmt.Errorf("index[%s]: token index requires single field", s.Name)
+		}
+		f := s.Fields[0]
+		switch f.Type {
+		case FT_STRING, FT_BYTES:
+			// ok
+		default:
+			return fmt.Errorf("index[%s]: unsupported token index on field %s type %s",
+				s.Name, f.Name, f.Type)
+		}
+		if len(s.Extra) > 0 {
+			return fmt.Errorf("index[%s]: token index does not support extra fields", s.Name)
+		}
+		if err := s.Tokenizer.Validate(); err != nil {
+			return fmt.Errorf("index[%s]: %v", s.Name, err)
+		}
+
+	case I_BITMAP:
+		// requires single integer or enum field, values are bitmap keys
+		// so extra fields are not supported
+		if len(s.Fields) > 1 {
+			return fmt.Errorf("index[%s]: bitmap index requires single field", s.Name)
+		}
+		f := s.Fields[0]
+		switch f.Type {
+		case FT_I64, FT_I32, FT_I16, FT_I8, FT_U64, FT_U32, FT_U16, FT_U8:
+			// ok
+		default:
+			return fmt.Errorf("index[%s]: unsupported bitmap index on field %s type %s",
+				s.Name, f.Name, f.Type)
+		}
+		if len(s.Extra) > 0 {
+			return fmt.Errorf("index[%s]: bitmap index does not support extra fields", s.Name)
+		}
 	}
 
 	return nil
@@ -331,8 +484,8 @@ func (s *IndexSchema) Validate() error {
 func (s IndexSchema) MarshalBinary() ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0, 22+len(s.Name)+32*(len(s.Fields)+len(s.Extra))))
 
-	// version: byte
-	buf.WriteByte(1)
+	// version: byte (v2 adds predicate, v3 adds expression, v4 adds tokenizer)
+	buf.WriteByte(4)
 
 	// type: byte
 	buf.WriteByte(byte(s.Type))
@@ -356,6 +509,26 @@ func (s IndexSchema) MarshalBinary() ([]byte, error) {
 		f.WriteTo(buf)
 	}
 
+	// predicate
+	var pred []byte
+	if s.Predicate != nil {
+		var err error
+		pred, err = s.Predicate.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+	}
+	binary.Write(buf, LE, uint32(len(pred)))
+	buf.Write(pred)
+
+	// expression: type byte + arg i64
+	buf.WriteByte(byte(s.Expr.Type))
+	binary.Write(buf, LE, s.Expr.Arg)
+
+	// tokenizer: type byte + n byte
+	buf.WriteByte(byte(s.Tokenizer.Type))
+	buf.WriteByte(byte(s.Tokenizer.N))
+
 	return buf.Bytes(), nil
 }
 
@@ -365,7 +538,8 @@ func (s *IndexSchema) UnmarshalBinary(b []byte) (err error) {
 	}
 
 	// version
-	if b[0] != 1 {
+	version := b[0]
+	if version < 1 || version > 4 {
 		return fmt.Errorf("invalid index schema version %d", b[0])
 	}
 
@@ -423,6 +597,44 @@ func (s *IndexSchema) UnmarshalBinary(b []byte) (err error) {
 		s.Extra[i] = f
 	}
 
+	// predicate
+	if version > 1 {
+		err = binary.Read(buf, LE, &l)
+		if err != nil {
+			return
+		}
+		if l > 0 {
+			if predicateDecoder == nil {
+				return fmt.Errorf("index[%s]: missing predicate decoder", s.Name)
+			}
+			s.Predicate, err = predicateDecoder(buf.Next(int(l)))
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	// expression
+	if version > 2 {
+		if buf.Len() < 9 {
+			return io.ErrShortBuffer
+		}
+		s.Expr.Type = IndexExprType(buf.Next(1)[0])
+		err = binary.Read(buf, LE, &s.Expr.Arg)
+		if err != nil {
+			return
+		}
+	}
+
+	// tokenizer
+	if version > 3 {
+		if buf.Len() < 2 {
+			return io.ErrShortBuffer
+		}
+		s.Tokenizer.Type = TokenizerType(buf.Next(1)[0])
+		s.Tokenizer.N = int(buf.Next(1)[0])
+	}
+
 	// Note: although not strictly required, users may want to resolve
 	// base schema from its hash
 
@@ -561,6 +773,10 @@ func reflectStructFieldForIndex(f reflect.StructField, tagName string, base *Sch
 				index.Type = I_PK
 			case "composite":
 				index.Type = I_COMPOSITE
+			case "token":
+				index.Type = I_TOKEN
+			case "bitmap":
+				index.Type = I_BITMAP
 			default:
 				return nil, fmt.Errorf("unsupported index type %q", val)
 			}
@@ -585,6 +801,18 @@ func reflectStructFieldForIndex(f reflect.StructField, tagName string, base *Sch
 				}
 				index.Extra = append(index.Extra, field)
 			}
+		case "expr":
+			expr, err := ParseIndexExpr(val)
+			if err != nil {
+				return nil, err
+			}
+			index.Expr = expr
+		case "tokenizer":
+			tok, err := ParseTokenizer(val)
+			if err != nil {
+				return nil, err
+			}
+			index.Tokenizer = tok
 		}
 	}
 
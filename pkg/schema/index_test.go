@@ -6,6 +6,7 @@ package schema
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"blockwatch.cc/knoxdb/internal/types"
 	"github.com/stretchr/testify/assert"
@@ -290,3 +291,118 @@ func TestIndexValidation(t *testing.T) {
 		})
 	}
 }
+
+type IndexExprModel struct {
+	BaseModel
+	Name string    `knox:"name,index=hash,expr=lower"`
+	Hash []byte    `knox:"hash,index=hash,expr=prefix(8)"`
+	Time time.Time `knox:"time,index=int,expr=time_bucket(1d)"`
+}
+
+func TestIndexExpr(t *testing.T) {
+	for _, c := range []struct {
+		in    string
+		expr  IndexExpr
+		iserr bool
+	}{
+		{in: "lower", expr: Lower()},
+		{in: "prefix(8)", expr: Prefix(8)},
+		{in: "time_bucket(1h)", expr: TimeBucket(time.Hour)},
+		{in: "time_bucket(1d)", expr: TimeBucket(24 * time.Hour)},
+		{in: "lower(1)", iserr: true},
+		{in: "prefix(x)", iserr: true},
+		{in: "upper", iserr: true},
+	} {
+		e, err := ParseIndexExpr(c.in)
+		if c.iserr {
+			require.Error(t, err, c.in)
+			continue
+		}
+		require.NoError(t, err, c.in)
+		require.Equal(t, c.expr, e, c.in)
+	}
+
+	s, err := GenericSchema[IndexExprModel]()
+	require.NoError(t, err)
+	require.Len(t, s.Indexes, 4)
+	exprs := []IndexExpr{Lower(), Prefix(8), TimeBucket(24 * time.Hour)}
+	for i, idx := range s.Indexes[1:] {
+		require.Equal(t, exprs[i], idx.Expr, idx.Name)
+		buf, err := idx.MarshalBinary()
+		require.NoError(t, err)
+		idx2 := &IndexSchema{Base: s}
+		require.NoError(t, idx2.UnmarshalBinary(buf))
+		require.Equal(t, idx.Expr, idx2.Expr, idx.Name)
+		require.Equal(t, idx.Hash(), idx2.Hash(), idx.Name)
+	}
+
+	// expressions must match field types
+	require.Error(t, NewIndexSchema(I_HASH, s, s.Fields[3]).WithExpr(Lower()).Validate())
+	require.Error(t, NewIndexSchema(I_INT, s, s.Fields[1]).WithExpr(Lower()).Validate())
+}
+
+type IndexTokenModel struct {
+	BaseModel
+	Text  string `knox:"text,index=token"`
+	Title string `knox:"title,index=token,tokenizer=ngram(3)"`
+	Num   int64  `knox:"num"`
+}
+
+func TestIndexToken(t *testing.T) {
+	for _, c := range []struct {
+		in    string
+		tok   Tokenizer
+		iserr bool
+	}{
+		{in: "words", tok: Words()},
+		{in: "ngram(3)", tok: Ngram(3)},
+		{in: "words(1)", iserr: true},
+		{in: "ngram(x)", iserr: true},
+		{in: "stem", iserr: true},
+	} {
+		tok, err := ParseTokenizer(c.in)
+		if c.iserr {
+			require.Error(t, err, c.in)
+			continue
+		}
+		require.NoError(t, err, c.in)
+		require.Equal(t, c.tok, tok, c.in)
+		require.Equal(t, c.in, tok.String(), c.in)
+	}
+
+	toString := func(v [][]byte) []string {
+		var s []string
+		for _, b := range v {
+			s = append(s, string(b))
+		}
+		return s
+	}
+	require.Equal(t, []string{"hello", "wörld", "42"}, toString(Words().Tokens([]byte("Hello, Wörld! hello-42"))))
+	require.Equal(t, []string{"abc", "bcd", "cda", "dab"}, toString(Ngram(3).Tokens([]byte("ABCDabc"))))
+	require.Nil(t, Ngram(3).Tokens([]byte("ab")))
+
+	q := ParseTermQuery([]byte("Foo bar | baz"))
+	require.Len(t, q, 2)
+	require.Equal(t, []string{"foo", "bar"}, toString(q[0]))
+	require.Equal(t, []string{"baz"}, toString(q[1]))
+
+	s, err := GenericSchema[IndexTokenModel]()
+	require.NoError(t, err)
+	require.Len(t, s.Indexes, 3)
+	toks := []Tokenizer{Words(), Ngram(3)}
+	for i, idx := range s.Indexes[1:] {
+		require.Equal(t, I_TOKEN, idx.Type, idx.Name)
+		require.Equal(t, toks[i], idx.Tokenizer, idx.Name)
+		buf, err := idx.MarshalBinary()
+		require.NoError(t, err)
+		idx2 := &IndexSchema{Base: s}
+		require.NoError(t, idx2.UnmarshalBinary(buf))
+		require.Equal(t, idx.Tokenizer, idx2.Tokenizer, idx.Name)
+		require.Equal(t, idx.Hash(), idx2.Hash(), idx.Name)
+	}
+	require.NotEqual(t, s.Indexes[1].Hash(), NewIndexSchema(I_TOKEN, s, s.Fields[1]).WithTokenizer(Ngram(3)).Hash())
+
+	// token indexes require string or bytes fields and valid tokenizers
+	require.Error(t, NewIndexSchema(I_TOKEN, s, s.Fields[3]).Validate())
+	require.Error(t, NewIndexSchema(I_TOKEN, s, s.Fields[1]).WithTokenizer(Ngram(0)).Validate())
+}
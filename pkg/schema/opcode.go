@@ -36,10 +36,13 @@ const (
 	OpCodeBigInt                    // 0x19 25
 	OpCodeEnum                      // 0x1A 26
 	OpCodeSkip                      // 0x1B 27
+	OpCodeList                      // 0x1C 28
+	OpCodeDocument                  // 0x1D 29
+	OpCodeDict                      // 0x1E 30
 )
 
 var (
-	opCodeStrings = "__i8_i16_i32_i64_u8_u16_u32_u64_f32_f64_bool_fixbyte_fixstr_str_byte_timestamp_time_date_i128_i256_d32_d64_d128_d256_bigint_enum_skip"
+	opCodeStrings = "__i8_i16_i32_i64_u8_u16_u32_u64_f32_f64_bool_fixbyte_fixstr_str_byte_timestamp_time_date_i128_i256_d32_d64_d128_d256_bigint_enum_skip_list_doc_dict"
 	opCodeIdx     = [...]int{
 		0,                           // invalid
 		2, 5, 9, 13, 17, 20, 24, 28, // int/uint
@@ -53,7 +56,10 @@ var (
 		117, // bigint
 		124, // enum
 		129, // skip
-		134, // end-of-string
+		134, // list
+		139, // doc
+		143, // dict
+		148, // end-of-string
 	}
 )
 
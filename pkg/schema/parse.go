@@ -34,6 +34,9 @@ func NewParser(typ types.FieldType, scale uint8, enum *EnumDictionary) ValuePars
 	case FT_STRING:
 		return StringParser{}
 	case FT_BYTES:
+		if scale > 0 {
+			return LogicalParser{Logical(scale)}
+		}
 		return BytesParser{}
 	case FT_I8:
 		return IntParser[int8]{8}
@@ -52,7 +55,11 @@ func NewParser(typ types.FieldType, scale uint8, enum *EnumDictionary) ValuePars
 			return enum
 		}
 	case FT_U32:
-		return UintParser[uint32]{32}
+		if enum == nil {
+			return UintParser[uint32]{32}
+		} else {
+			return enum.Dict()
+		}
 	case FT_U64:
 		return UintParser[uint64]{64}
 	case FT_F32:
@@ -63,18 +70,30 @@ func NewParser(typ types.FieldType, scale uint8, enum *EnumDictionary) ValuePars
 		return I128Parser{}
 	case FT_I256:
 		return I256Parser{}
+	case FT_D32, FT_D64, FT_D128, FT_D256:
+		return NewDecimalParser(typ, scale, num.RoundExact)
+	case FT_BIGINT:
+		return BigIntParser{}
+	default:
+		panic(fmt.Errorf("parser: unsupported field type %s %d", typ, typ))
+	}
+}
+
+// NewDecimalParser returns a parser for decimal type typ that converts
+// literals to scale using rounding mode. Literals are parsed at full
+// precision so that any decimal width can be used with any column width.
+func NewDecimalParser(typ types.FieldType, scale uint8, mode num.RoundingMode) ValueParser {
+	switch typ {
 	case FT_D32:
-		return D32Parser{scale}
+		return D32Parser{scale, mode}
 	case FT_D64:
-		return D64Parser{scale}
+		return D64Parser{scale, mode}
 	case FT_D128:
-		return D128Parser{scale}
+		return D128Parser{scale, mode}
 	case FT_D256:
-		return D256Parser{scale}
-	case FT_BIGINT:
-		return BigIntParser{}
+		return D256Parser{scale, mode}
 	default:
-		panic(fmt.Errorf("parser: unsupported field type %s %d", typ, typ))
+		panic(fmt.Errorf("parser: unsupported decimal type %s %d", typ, typ))
 	}
 }
 
@@ -187,96 +206,134 @@ func (I256Parser) ParseSlice(s string) (any, error) {
 	return slice, nil
 }
 
-// Decimal32 parser
-type D32Parser struct {
-	scale uint8
-}
-
-func (p D32Parser) ParseValue(s string) (any, error) {
-	d, err := num.ParseDecimal32(s)
-	return d.Quantize(p.scale).Int32(), err
+// parseDecimal parses s at full precision and converts it to scale.
+func parseDecimal(s string, scale uint8, mode num.RoundingMode) (num.Int256, error) {
+	d, err := num.ParseDecimal256(s)
+	if err != nil {
+		return num.ZeroInt256, err
+	}
+	d, err = d.Rescale(scale, mode)
+	if err != nil {
+		return num.ZeroInt256, fmt.Errorf("decimal %s at scale %d: %w", s, scale, err)
+	}
+	return d.Int256(), nil
 }
 
-func (p D32Parser) ParseSlice(s string) (any, error) {
+func parseDecimalSlice[T any](s string, scale uint8, mode num.RoundingMode, conv func(num.Int256) (T, bool)) (any, error) {
 	vv := strings.Split(s, ",")
-	slice := make([]int32, len(vv))
+	slice := make([]T, len(vv))
 	for i, v := range vv {
-		d, err := num.ParseDecimal32(v)
+		d, err := parseDecimal(v, scale, mode)
 		if err != nil {
 			return nil, err
 		}
-		slice[i] = d.Quantize(p.scale).Int32()
+		var ok bool
+		slice[i], ok = conv(d)
+		if !ok {
+			return nil, fmt.Errorf("decimal %s at scale %d: %w", v, scale, num.ErrPrecisionOverflow)
+		}
 	}
 	return slice, nil
 }
 
+func decimalToInt32(v num.Int256) (int32, bool) {
+	return int32(v.Int64()), v.IsInt64() && int64(int32(v.Int64())) == v.Int64()
+}
+
+func decimalToInt64(v num.Int256) (int64, bool) {
+	return v.Int64(), v.IsInt64()
+}
+
+func decimalToInt128(v num.Int256) (num.Int128, bool) {
+	return v.Int128(), v.IsInt128()
+}
+
+func decimalToInt256(v num.Int256) (num.Int256, bool) {
+	return v, true
+}
+
+// Decimal32 parser
+type D32Parser struct {
+	scale uint8
+	mode  num.RoundingMode
+}
+
+func (p D32Parser) ParseValue(s string) (any, error) {
+	d, err := parseDecimal(s, p.scale, p.mode)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := decimalToInt32(d)
+	if !ok {
+		return nil, fmt.Errorf("decimal %s at scale %d: %w", s, p.scale, num.ErrPrecisionOverflow)
+	}
+	return v, nil
+}
+
+func (p D32Parser) ParseSlice(s string) (any, error) {
+	return parseDecimalSlice(s, p.scale, p.mode, decimalToInt32)
+}
+
 // Decimal64 parser
 type D64Parser struct {
 	scale uint8
+	mode  num.RoundingMode
 }
 
 func (p D64Parser) ParseValue(s string) (any, error) {
-	d, err := num.ParseDecimal64(s)
-	return d.Quantize(p.scale).Int64(), err
+	d, err := parseDecimal(s, p.scale, p.mode)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := decimalToInt64(d)
+	if !ok {
+		return nil, fmt.Errorf("decimal %s at scale %d: %w", s, p.scale, num.ErrPrecisionOverflow)
+	}
+	return v, nil
 }
 
 func (p D64Parser) ParseSlice(s string) (any, error) {
-	vv := strings.Split(s, ",")
-	slice := make([]int64, len(vv))
-	for i, v := range vv {
-		d, err := num.ParseDecimal64(v)
-		if err != nil {
-			return nil, err
-		}
-		slice[i] = d.Quantize(p.scale).Int64()
-	}
-	return slice, nil
+	return parseDecimalSlice(s, p.scale, p.mode, decimalToInt64)
 }
 
 // Decimal128 parser
 type D128Parser struct {
 	scale uint8
+	mode  num.RoundingMode
 }
 
 func (p D128Parser) ParseValue(s string) (any, error) {
-	d, err := num.ParseDecimal128(s)
-	return d.Quantize(p.scale).Int128(), err
+	d, err := parseDecimal(s, p.scale, p.mode)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := decimalToInt128(d)
+	if !ok {
+		return nil, fmt.Errorf("decimal %s at scale %d: %w", s, p.scale, num.ErrPrecisionOverflow)
+	}
+	return v, nil
 }
 
 func (p D128Parser) ParseSlice(s string) (any, error) {
-	vv := strings.Split(s, ",")
-	slice := make([]num.Int128, len(vv))
-	for i, v := range vv {
-		d, err := num.ParseDecimal128(v)
-		if err != nil {
-			return nil, err
-		}
-		slice[i] = d.Quantize(p.scale).Int128()
-	}
-	return slice, nil
+	return parseDecimalSlice(s, p.scale, p.mode, decimalToInt128)
 }
 
 // Decimal256 parser
 type D256Parser struct {
 	scale uint8
+	mode  num.RoundingMode
 }
 
 func (p D256Parser) ParseValue(s string) (any, error) {
-	d, err := num.ParseDecimal256(s)
-	return d.Quantize(p.scale).Int256(), err
+	d, err := parseDecimal(s, p.scale, p.mode)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
 }
 
 func (p D256Parser) ParseSlice(s string) (any, error) {
-	vv := strings.Split(s, ",")
-	slice := make([]num.Int256, len(vv))
-	for i, v := range vv {
-		d, err := num.ParseDecimal256(v)
-		if err != nil {
-			return nil, err
-		}
-		slice[i] = d.Quantize(p.scale).Int256()
-	}
-	return slice, nil
+	return parseDecimalSlice(s, p.scale, p.mode, decimalToInt256)
 }
 
 // string parser
@@ -322,6 +379,31 @@ func (BytesParser) ParseSlice(s string) (any, error) {
 	return slice, nil
 }
 
+// logical type parser
+type LogicalParser struct {
+	typ Logical
+}
+
+func (p LogicalParser) ParseValue(s string) (any, error) {
+	return p.typ.Parse(s)
+}
+
+func (p LogicalParser) ParseSlice(s string) (any, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	vv := strings.Split(s, ",")
+	slice := make([][]byte, len(vv))
+	for i, v := range vv {
+		b, err := p.typ.Parse(v)
+		if err != nil {
+			return nil, err
+		}
+		slice[i] = b
+	}
+	return slice, nil
+}
+
 // time parser
 type TimeParser struct {
 	scale      TimeScale
@@ -254,3 +254,42 @@ func TestIntegerParsing(t *testing.T) {
 		}
 	})
 }
+
+// TestDecimalParsing tests that decimal literals are converted to column scale
+// without silent rounding unless a rounding mode is selected.
+func TestDecimalParsing(t *testing.T) {
+	tests := []struct {
+		name     string
+		parser   ValueParser
+		input    string
+		expected any
+		hasError bool
+	}{
+		{"ScaleUp", NewParser(FT_D64, 4, nil), "1.5", int64(15000), false},
+		{"Exact", NewParser(FT_D64, 2, nil), "1.500", int64(150), false},
+		{"Inexact", NewParser(FT_D64, 2, nil), "1.005", nil, true},
+		{"WideLiteral", NewParser(FT_D32, 2, nil), "0.10000000000000000000000000", int32(10), false},
+		{"Overflow", NewParser(FT_D32, 2, nil), "100000000", nil, true},
+		{"Floor", NewDecimalParser(FT_D64, 2, num.RoundFloor), "1.009", int64(100), false},
+		{"Ceil", NewDecimalParser(FT_D128, 2, num.RoundCeil), "1.001", num.Int128FromInt64(101), false},
+		{"HalfEven", NewDecimalParser(FT_D256, 1, num.RoundHalfEven), "0.25", num.Int256FromInt64(2), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.parser.ParseValue(tt.input)
+			if tt.hasError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	_, err := NewParser(FT_D64, 2, nil).ParseSlice("1.00,1.001")
+	require.Error(t, err)
+	res, err := NewParser(FT_D64, 2, nil).ParseSlice("1.00,-2.5")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{100, -250}, res)
+}
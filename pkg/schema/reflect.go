@@ -4,6 +4,7 @@
 package schema
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/bits"
 	"reflect"
@@ -193,6 +194,10 @@ func (s *Schema) NativeStructType() reflect.Type {
 			rtyp = reflect.TypeFor[[32]byte]()
 		case FT_I128, FT_D128:
 			rtyp = reflect.TypeFor[[16]byte]()
+		case FT_LIST:
+			rtyp = f.GoType()
+		case FT_DOC:
+			rtyp = reflect.TypeFor[json.RawMessage]()
 		default:
 			continue
 		}
@@ -216,20 +221,31 @@ func (s *Schema) StructType() reflect.Type {
 		if f.IsPrimary() {
 			tag += ",pk"
 		}
-		if f.IsEnum() {
+		if f.IsDict() {
+			tag += ",dict"
+		} else if f.IsEnum() {
 			tag += ",enum"
 		}
-		if f.IsFixedSize() && f.Fixed > 0 {
-			tag += fmt.Sprintf(",fixed=%d", f.Fixed)
-		}
-		// if f.IsIndexed() {
-		// 	tag += fmt.Sprintf(",index=%s", f.Index.Type)
-		// }
-		if f.Scale > 0 {
-			tag += fmt.Sprintf(",scale=%d", f.Scale)
+		if l := f.Logical(); l > 0 {
+			tag += "," + l.String()
+		} else {
+			// byte arrays carry their size in the Go type
+			if f.Type == FT_STRING && f.Fixed > 0 {
+				tag += fmt.Sprintf(",fixed=%d", f.Fixed)
+			}
+			// if f.IsIndexed() {
+			// 	tag += fmt.Sprintf(",index=%s", f.Index.Type)
+			// }
+			if f.Scale > 0 {
+				tag += fmt.Sprintf(",scale=%d", f.Scale)
+			}
 		}
 		if f.IsCompressed() {
-			tag += ",zip=" + f.Compress.String()
+			if f.Compress.Level() > 0 {
+				tag += "," + f.Compress.String()
+			} else {
+				tag += ",zip=" + f.Compress.String()
+			}
 		}
 		tag += `"`
 		sfields = append(sfields, reflect.StructField{
@@ -266,6 +282,7 @@ var (
 	emptyType     = reflect.TypeFor[struct{}]()
 	uint8Type     = reflect.TypeFor[uint8]()
 	byteSliceType = reflect.TypeFor[[]byte]()
+	rawJSONType   = reflect.TypeFor[json.RawMessage]()
 	modelType     = reflect.TypeFor[Model]()
 )
 
@@ -282,8 +299,13 @@ func reflectStructField(f reflect.StructField, tagName string) (field *Field, er
 	// clean name
 	field.Name = strings.ToLower(strings.TrimSpace(field.Name))
 
-	// identify field type from Go type
-	err = field.ParseType(f)
+	// identify field type from Go type, the doc tag stores any Go type
+	// as JSON document
+	if hasTagFlag(tag, "doc") {
+		field.parseDocType(f.Type)
+	} else {
+		err = field.ParseType(f)
+	}
 	if err != nil {
 		err = fmt.Errorf("field %s: %v", field.Name, err)
 		return
@@ -298,6 +320,12 @@ func reflectStructField(f reflect.StructField, tagName string) (field *Field, er
 
 	// Validate field
 
+	// nullable types cannot be declared notnull
+	if field.Null != NullNone && !field.IsNullable() {
+		err = fmt.Errorf("field %s: notnull unsupported on nullable type %s", field.Name, f.Type)
+		return
+	}
+
 	// pk field must be of type uint64
 	if field.Flags&F_PRIMARY > 0 {
 		switch f.Type.Kind() {
@@ -319,11 +347,35 @@ func reflectStructField(f reflect.StructField, tagName string) (field *Field, er
 func (f *Field) ParseType(r reflect.StructField) error {
 	var (
 		typ   types.FieldType
+		elem  types.FieldType
 		flags types.FieldFlags
 		fixed uint16
 		scale uint8
 	)
 
+	// nullable types are pointers or sql.Null style structs with valid flag,
+	// their wire encoding carries a validity byte
+	if r.Type.Kind() == reflect.Pointer {
+		if r.Type.Elem().Kind() == reflect.Pointer {
+			return fmt.Errorf("unsupported pointer type %s", r.Type)
+		}
+		if err := f.ParseType(reflect.StructField{Type: r.Type.Elem()}); err != nil {
+			return err
+		}
+		f.Flags |= F_NULLABLE | F_VALIDITY
+		f.Null = NullPointer
+		return nil
+	}
+	if isNullStruct(r.Type) {
+		if err := f.ParseType(r.Type.Field(0)); err != nil {
+			return err
+		}
+		f.Flags |= F_NULLABLE | F_VALIDITY
+		f.Null = NullValid
+		f.Valid = r.Type.Field(1).Offset
+		return nil
+	}
+
 	// field must have supported kind
 	switch r.Type.Kind() {
 	case reflect.Complex64,
@@ -377,13 +429,27 @@ func (f *Field) ParseType(r reflect.StructField) error {
 	case reflect.Bool:
 		typ = FT_BOOL
 	case reflect.Map:
-		return fmt.Errorf("unsupported map type %s", r.Type)
+		// maps with string keys are documents
+		if r.Type.Key().Kind() != reflect.String {
+			return fmt.Errorf("unsupported map type %s", r.Type)
+		}
+		typ = FT_DOC
 	case reflect.Slice:
+		if r.Type == rawJSONType {
+			typ = FT_DOC
+			break
+		}
 		if r.Type == byteSliceType {
 			typ = FT_BYTES
-		} else {
+			break
+		}
+		// slices of fixed size numbers are lists
+		var ef Field
+		if err := ef.ParseType(reflect.StructField{Type: r.Type.Elem()}); err != nil ||
+			ef.Flags != 0 || !ef.Type.BlockType().IsListElem() || ef.Type.BlockType() == types.BlockBool {
 			return fmt.Errorf("unsupported slice type %s", r.Type)
 		}
+		typ, elem = FT_LIST, ef.Type
 	case reflect.Struct:
 		// string-check is much quicker
 		switch r.Type.String() {
@@ -405,7 +471,7 @@ func (f *Field) ParseType(r reflect.StructField) error {
 		case "num.Big":
 			typ = FT_BIGINT
 		default:
-			return fmt.Errorf("unsupported nested struct type %s", r.Type)
+			return fmt.Errorf("unsupported nested struct type %s, use the doc tag to store documents", r.Type)
 		}
 	case reflect.Array:
 		// string-check is much quicker
@@ -427,13 +493,51 @@ func (f *Field) ParseType(r reflect.StructField) error {
 	}
 
 	f.Type = typ
+	f.Elem = elem
 	f.Flags = flags
 	f.Fixed = fixed
 	f.Scale = scale
+	if typ == FT_DOC {
+		f.Doc = r.Type
+	}
 
 	return nil
 }
 
+// parseDocType sets up a document field for Go type t. Pointers are
+// nullable documents.
+func (f *Field) parseDocType(t reflect.Type) {
+	f.Type = FT_DOC
+	f.Doc = t
+	if t.Kind() == reflect.Pointer {
+		f.Doc = t.Elem()
+		f.Flags |= F_NULLABLE | F_VALIDITY
+		f.Null = NullPointer
+	}
+}
+
+// hasTagFlag returns true when struct tag contains flag after the name.
+func hasTagFlag(tag, flag string) bool {
+	tokens := strings.Split(tag, ",")
+	for _, v := range tokens[1:] {
+		if strings.TrimSpace(v) == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// isNullStruct detects sql.Null style types, i.e. structs with a value
+// followed by a boolean Valid flag.
+func isNullStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.NumField() != 2 {
+		return false
+	}
+	v, ok := t.Field(0), t.Field(1)
+	return v.IsExported() && v.Offset == 0 && v.Type.Kind() != reflect.Pointer &&
+		ok.Name == "Valid" && ok.Type.Kind() == reflect.Bool
+}
+
 func (f *Field) ParseTag(tag string) error {
 	// first part is field name
 	tokens := strings.Split(tag, ",")
@@ -446,7 +550,7 @@ func (f *Field) ParseTag(tag string) error {
 		fixed    = f.Fixed
 		maxFixed = MAX_FIXED
 		maxScale = f.Scale
-		flags    types.FieldFlags
+		flags    = f.Flags & (F_NULLABLE | F_VALIDITY) // keep nullable types
 		compress types.BlockCompression
 		filter   types.FilterType
 	)
@@ -456,7 +560,7 @@ func (f *Field) ParseTag(tag string) error {
 		key = strings.TrimSpace(key)
 		val = strings.TrimSpace(val)
 		switch key {
-		case "index", "fields", "extra":
+		case "index", "fields", "extra", "expr", "tokenizer", "doc":
 			// skip here
 		case "pk":
 			flags |= F_PRIMARY
@@ -489,9 +593,34 @@ func (f *Field) ParseTag(tag string) error {
 				compress = types.BlockCompressLZ4
 			case "zstd":
 				compress = types.BlockCompressZstd
+			case "auto":
+				compress = types.BlockCompressAuto
+			case "dict":
+				compress = types.BlockCompressDict
 			default:
 				return fmt.Errorf("unsupported compression type %q", val)
 			}
+		case "lz4":
+			// compression level for lz4 (1 = fast .. 12 = high compression)
+			l, err := parseInt(val, "lz4", 1, 12)
+			if err != nil {
+				return err
+			}
+			compress = types.BlockCompressLZ4.WithLevel(l)
+		case "zstd":
+			// compression level for zstd (1 = fastest .. 22 = best ratio)
+			l, err := parseInt(val, "zstd", 1, 22)
+			if err != nil {
+				return err
+			}
+			compress = types.BlockCompressZstd.WithLevel(l)
+		case "auto":
+			// adaptive compression policy
+			p, ok := types.ParseCompressPolicy(val)
+			if !ok {
+				return fmt.Errorf("unsupported compression policy %q", val)
+			}
+			compress = types.BlockCompressAuto.WithLevel(p)
 		case "fixed":
 			// only compatible with strings, bytes must use [n]byte arrays):
 			if f.Type != FT_STRING {
@@ -538,6 +667,13 @@ func (f *Field) ParseTag(tag string) error {
 			} else {
 				return fmt.Errorf("unsupported enum type %s", f.Type)
 			}
+		case "dict":
+			if f.Type == FT_STRING {
+				flags |= F_ENUM
+				f.Type = FT_U32
+			} else {
+				return fmt.Errorf("unsupported dict type %s", f.Type)
+			}
 		case "metadata":
 			flags |= F_METADATA
 		case "id":
@@ -549,7 +685,7 @@ func (f *Field) ParseTag(tag string) error {
 		case "null":
 			flags |= F_NULLABLE
 		case "notnull":
-			flags &^= F_NULLABLE
+			flags &^= F_NULLABLE | F_VALIDITY
 		case "timestamp":
 			f.Type = FT_TIMESTAMP
 			scale = TIME_SCALE_NANO.AsUint()
@@ -561,6 +697,13 @@ func (f *Field) ParseTag(tag string) error {
 			scale = TIME_SCALE_SECOND.AsUint()
 		case "timebase":
 			flags |= F_TIMEBASE
+		case "uuid", "ip", "hash32", "addr20":
+			// logical types require a byte array of matching size
+			l := types.ParseLogicalType(key)
+			if f.Type != FT_BYTES || int(fixed) != l.Size() {
+				return fmt.Errorf("%s tag requires [%d]byte type", key, l.Size())
+			}
+			scale = uint8(l)
 		default:
 			return fmt.Errorf("unsupported struct tag '%s'", key)
 		}
@@ -621,7 +764,11 @@ func compileCodecs(s *Schema) (enc []OpCode, dec []OpCode) {
 			dc, ec = OpCodeUint64, OpCodeUint64
 
 		case FT_U32:
-			dc, ec = OpCodeUint32, OpCodeUint32
+			if f.Flags.Is(types.FieldFlagEnum) {
+				dc, ec = OpCodeDict, OpCodeDict
+			} else {
+				dc, ec = OpCodeUint32, OpCodeUint32
+			}
 
 		case FT_U16:
 			if f.Flags.Is(types.FieldFlagEnum) {
@@ -680,6 +827,12 @@ func compileCodecs(s *Schema) (enc []OpCode, dec []OpCode) {
 
 		case FT_BIGINT:
 			dc, ec = OpCodeBigInt, OpCodeBigInt
+
+		case FT_LIST:
+			dc, ec = OpCodeList, OpCodeList
+
+		case FT_DOC:
+			dc, ec = OpCodeDocument, OpCodeDocument
 		}
 
 		if !f.IsVisible() {
@@ -445,11 +445,10 @@ func (s *Schema) ResetPk(id uint16) (*Schema, bool) {
 	clone := s.Clone()
 	fold := clone.Pk()
 	fnew, _ = clone.FindId(id)
-	// flip primary key flag
-	// FIXME: changes schema hash (effect on catalog?)
+	// flip primary key flag, changes the schema hash
 	fold.Flags &^= types.FieldFlagPrimary
 	fnew.Flags |= types.FieldFlagPrimary
-	return clone, true
+	return clone.Finalize(), true
 }
 
 func (s *Schema) CanMatch(names ...string) bool {
@@ -584,6 +583,10 @@ func (s *Schema) MapSchema(dst *Schema) ([]int, error) {
 				return nil, fmt.Errorf("%w on %s: field %q scale mismatch",
 					ErrSchemaMismatch, dst.Name, dstField.Name)
 			}
+			if srcField.Elem != dstField.Elem {
+				return nil, fmt.Errorf("%w on %s: field %q list element type mismatch",
+					ErrSchemaMismatch, dst.Name, dstField.Name)
+			}
 		}
 		maps = append(maps, pos)
 	}
@@ -753,7 +756,7 @@ func (s *Schema) Finalize() *Schema {
 				s.MaxWireSize += defaultVarFieldSize
 			}
 
-			// hash: id, type, flags, fixed, scale (not: filter, compress, name)
+			// hash: id, type, flags, fixed, scale, list elem (not: filter, compress, name)
 			LE.PutUint16(b[:], f.Id)
 			h.Write(b[:2])
 			h.Write([]byte{byte(f.Type)})
@@ -761,6 +764,9 @@ func (s *Schema) Finalize() *Schema {
 			LE.PutUint16(b[:], f.Fixed)
 			h.Write(b[:2])
 			h.Write([]byte{f.Scale})
+			if f.Type == FT_LIST {
+				h.Write([]byte{byte(f.Elem)})
+			}
 
 			// fill struct type info
 			if needLayout {
@@ -821,6 +827,8 @@ func (s *Schema) String() string {
 			if f.Fixed > 0 {
 				typ = "[" + strconv.Itoa(int(f.Fixed)) + "]" + f.Type.String()
 			}
+		case FT_LIST:
+			typ = f.TypeName()
 		}
 		if typ == "" {
 			typ = f.Type.String()
@@ -5,8 +5,12 @@ package schema
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/hex"
 	"math/bits"
+	"net"
+	"net/netip"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -242,7 +246,7 @@ type NoMarshalerTypes struct {
 
 type NoMarshalerSliceTypes struct {
 	BaseModel
-	Slice []int64 `knox:"no_marshalers"`
+	Slice []string `knox:"no_marshalers"`
 }
 
 type OtherStruct struct {
@@ -269,7 +273,15 @@ type NoMarshalerMapTypes struct {
 
 type PointerTypes struct {
 	BaseModel
-	Ptr *int `knox:"ptr"`
+	Ptr **int `knox:"ptr"`
+}
+
+type NullableTypes struct {
+	BaseModel
+	Ptr  *int64        `knox:"ptr"`
+	Str  *string       `knox:"str,index=hash"`
+	Null sql.NullInt64 `knox:"null"`
+	Val  int32         `knox:"val,null"`
 }
 
 type DuplicatePkType struct {
@@ -678,6 +690,22 @@ var schemaTestCases = []schemaTest{
 		iserr: true,
 	},
 
+	// nullable pointer, sql.Null and tagged types
+	{
+		name:      "nullable_types",
+		build:     GenericSchema[NullableTypes],
+		fields:    "id,ptr,str,null,val",
+		typs:      []FieldType{FT_U64, FT_I64, FT_STRING, FT_I64, FT_I32},
+		flags:     []FieldFlags{F_PRIMARY, F_NULLABLE | F_VALIDITY, F_NULLABLE | F_VALIDITY, F_NULLABLE | F_VALIDITY, F_NULLABLE},
+		scales:    []uint8{0, 0, 0, 0, 0},
+		fixed:     []uint16{0, 0, 0, 0, 0},
+		isFixed:   false,
+		idxfields: "id,str",
+		idxtyps:   []types.IndexType{I_PK, I_HASH},
+		encode:    []OpCode{OC_U64, OC_I64, OC_STRING, OC_I64, OC_I32},
+		decode:    []OpCode{OC_U64, OC_I64, OC_STRING, OC_I64, OC_I32},
+	},
+
 	// error: unsupported ptr type
 	{
 		name:  "invalid pointer",
@@ -1309,3 +1337,201 @@ func TestSchemaDeleteField(t *testing.T) {
 	_, err = s.SelectIds(1, 2)
 	require.Error(t, err, "cannot select deleted field")
 }
+
+type compressTestStruct struct {
+	Id   uint64 `knox:"id,pk"`
+	Hot  int64  `knox:"hot,zip=lz4"`
+	Cold int64  `knox:"cold,zstd=19"`
+	Fast []byte `knox:"fast,lz4=9"`
+	Auto string `knox:"auto,zip=auto"`
+	Best string `knox:"best,auto=ratio"`
+	Dict string `knox:"dict,zip=dict"`
+}
+
+func TestSchemaCompression(t *testing.T) {
+	s, err := SchemaOf(&compressTestStruct{})
+	require.NoError(t, err)
+	require.NoError(t, s.Validate())
+
+	for name, c := range map[string]types.BlockCompression{
+		"id":   types.BlockCompressNone,
+		"hot":  types.BlockCompressLZ4,
+		"cold": types.BlockCompressZstd.WithLevel(19),
+		"fast": types.BlockCompressLZ4.WithLevel(9),
+		"auto": types.BlockCompressAuto,
+		"best": types.BlockCompressAuto.WithLevel(types.CompressPolicyRatio),
+		"dict": types.BlockCompressDict,
+	} {
+		f, ok := s.Find(name)
+		require.True(t, ok, name)
+		require.Equal(t, c, f.Compress, name)
+	}
+
+	// struct tags roundtrip
+	tags := make(map[string]string)
+	st := s.StructType()
+	for i := range st.NumField() {
+		tags[st.Field(i).Name] = st.Field(i).Tag.Get("knox")
+	}
+	require.Contains(t, tags["Cold"], ",zstd=19")
+	require.Contains(t, tags["Fast"], ",lz4=9")
+	require.Contains(t, tags["Auto"], ",zip=auto")
+	require.Contains(t, tags["Best"], ",auto=ratio")
+
+	// invalid levels and policies
+	_, err = SchemaOf(&struct {
+		Id uint64 `knox:"id,pk,zstd=23"`
+	}{})
+	require.Error(t, err)
+	_, err = SchemaOf(&struct {
+		Id uint64 `knox:"id,pk,auto=fast"`
+	}{})
+	require.Error(t, err)
+	require.Error(t, NewField(types.FieldTypeInt64).WithName("x").
+		WithCompression(types.BlockCompressSnappy.WithLevel(3)).Validate())
+}
+
+type logicalTestStruct struct {
+	Id   uint64   `knox:"id,pk"`
+	Uid  [16]byte `knox:"uid,uuid"`
+	Peer [16]byte `knox:"peer,ip"`
+	Hash [32]byte `knox:"hash,hash32"`
+	Addr [20]byte `knox:"addr,addr20"`
+	Raw  [16]byte `knox:"raw"`
+}
+
+func TestSchemaLogical(t *testing.T) {
+	s, err := SchemaOf(&logicalTestStruct{})
+	require.NoError(t, err)
+	require.NoError(t, s.Validate())
+
+	for name, l := range map[string]Logical{
+		"uid":  LT_UUID,
+		"peer": LT_IP,
+		"hash": LT_HASH32,
+		"addr": LT_ADDR20,
+		"raw":  types.LogicalTypeNone,
+	} {
+		f, ok := s.Find(name)
+		require.True(t, ok, name)
+		require.Equal(t, l, f.Logical(), name)
+		require.Equal(t, FT_BYTES, f.Type, name)
+
+		// type names roundtrip
+		f2, err := ParseFieldFromTypename(f.TypeName())
+		require.NoError(t, err, name)
+		require.Equal(t, l, f2.Logical(), name)
+		require.Equal(t, f.Fixed, f2.Fixed, name)
+	}
+
+	// binary and struct tags roundtrip
+	buf, err := s.MarshalBinary()
+	require.NoError(t, err)
+	r := &Schema{}
+	require.NoError(t, r.UnmarshalBinary(buf))
+	require.True(t, s.Equal(r))
+	f, _ := r.Find("peer")
+	require.Equal(t, LT_IP, f.Logical())
+	s2, err := SchemaOf(reflect.New(s.StructType()).Interface())
+	require.NoError(t, err)
+	f, _ = s2.Find("hash")
+	require.Equal(t, LT_HASH32, f.Logical())
+
+	// builder
+	b := NewBuilder().WithName("logical").Uint64("id", Primary()).UUID("uid").IP("peer").Hash32("hash").Addr20("addr")
+	require.NoError(t, b.Finalize().Validate())
+	f, _ = b.Schema().Find("addr")
+	require.Equal(t, uint16(20), f.Fixed)
+
+	// size mismatch
+	_, err = SchemaOf(&struct {
+		Id  uint64   `knox:"id,pk"`
+		Uid [20]byte `knox:"uid,uuid"`
+	}{})
+	require.Error(t, err)
+	_, err = SchemaOf(&struct {
+		Id  uint64 `knox:"id,pk"`
+		Uid []byte `knox:"uid,ip"`
+	}{})
+	require.Error(t, err)
+	require.Error(t, NewField(FT_BYTES).WithName("x").WithFixed(8).WithScale(uint8(LT_IP)).Validate())
+}
+
+func TestSchemaLogicalParseCast(t *testing.T) {
+	ip4 := []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 10, 1, 2, 3}
+	uid := []byte{0x75, 0xfc, 0xf8, 0x75, 0x01, 0x7d, 0x45, 0x79, 0xbf, 0xd9, 0x79, 0x1d, 0x3e, 0x67, 0x67, 0xf0}
+
+	// parse and format
+	p := NewParser(FT_BYTES, uint8(LT_IP), nil)
+	v, err := p.ParseValue("10.1.2.3")
+	require.NoError(t, err)
+	require.Equal(t, ip4, v)
+	require.Equal(t, "10.1.2.3", LT_IP.Format(ip4))
+	v, err = p.ParseValue("2001:db8::1")
+	require.NoError(t, err)
+	require.Equal(t, "2001:db8::1", LT_IP.Format(v.([]byte)))
+	_, err = p.ParseValue("10.1.2")
+	require.Error(t, err)
+
+	p = NewParser(FT_BYTES, uint8(LT_UUID), nil)
+	for _, s := range []string{"75fcf875-017d-4579-bfd9-791d3e6767f0", "75fcf875017d4579bfd9791d3e6767f0"} {
+		v, err = p.ParseValue(s)
+		require.NoError(t, err, s)
+		require.Equal(t, uid, v, s)
+	}
+	require.Equal(t, "75fcf875-017d-4579-bfd9-791d3e6767f0", LT_UUID.Format(uid))
+	v, err = p.ParseSlice("75fcf875-017d-4579-bfd9-791d3e6767f0,75fcf875017d4579bfd9791d3e6767f0")
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{uid, uid}, v)
+	_, err = p.ParseValue("75fcf875_017d_4579_bfd9_791d3e6767f0")
+	require.Error(t, err)
+
+	p = NewParser(FT_BYTES, uint8(LT_ADDR20), nil)
+	addr := "0x" + strings.Repeat("ab", 20)
+	v, err = p.ParseValue(addr)
+	require.NoError(t, err)
+	require.Equal(t, addr, LT_ADDR20.Format(v.([]byte)))
+	_, err = p.ParseValue("0xabab")
+	require.Error(t, err)
+
+	// cast
+	c := NewCaster(FT_BYTES, uint8(LT_IP), nil)
+	for _, val := range []any{"10.1.2.3", netip.MustParseAddr("10.1.2.3"), net.IPv4(10, 1, 2, 3), [16]byte(ip4), ip4} {
+		v, err := c.CastValue(val)
+		require.NoError(t, err, "%T", val)
+		require.Equal(t, ip4, v, "%T", val)
+	}
+	_, err = c.CastValue([]byte{1, 2, 3, 4})
+	require.Error(t, err)
+	v, err = c.CastSlice([]string{"10.1.2.3"})
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{ip4}, v)
+	c = NewCaster(FT_BYTES, uint8(LT_UUID), nil)
+	_, err = c.CastValue(netip.MustParseAddr("10.1.2.3"))
+	require.Error(t, err)
+
+	// prefix ranges
+	f := NewField(FT_BYTES).WithName("peer").WithLogical(LT_IP)
+	from, to, err := f.PrefixRange("10.1.0.0/16")
+	require.NoError(t, err)
+	require.Equal(t, "10.1.0.0", LT_IP.Format(from))
+	require.Equal(t, "10.1.255.255", LT_IP.Format(to))
+	from, to, err = f.PrefixRange(netip.MustParsePrefix("2001:db8::/31"))
+	require.NoError(t, err)
+	require.Equal(t, "2001:db8::", LT_IP.Format(from))
+	require.Equal(t, "2001:db9:ffff:ffff:ffff:ffff:ffff:ffff", LT_IP.Format(to))
+	from, to, err = f.PrefixRange("10.1.2.3")
+	require.NoError(t, err)
+	require.Equal(t, ip4, from)
+	require.Equal(t, ip4, to)
+
+	f = NewField(FT_BYTES).WithName("hash").WithLogical(LT_HASH32)
+	from, to, err = f.PrefixRange("0xabc")
+	require.NoError(t, err)
+	require.Equal(t, append([]byte{0xab, 0xc0}, make([]byte, 30)...), from)
+	require.Equal(t, append([]byte{0xab, 0xcf}, bytes.Repeat([]byte{0xff}, 30)...), to)
+	_, _, err = f.PrefixRange("0x" + strings.Repeat("00", 33))
+	require.Error(t, err)
+	_, _, err = NewField(FT_BYTES).WithName("var").PrefixRange("0xab")
+	require.Error(t, err)
+}
@@ -124,6 +124,12 @@ func (s TimeScale) AsUint() uint8 {
 	return uint8(s)
 }
 
+// Nanos returns the number of nanoseconds per time value stored at scale s.
+// Out of range scales are treated as days.
+func (s TimeScale) Nanos() int64 {
+	return timeScaleFactor[min(s, TIME_SCALE_DAY)]
+}
+
 func (s TimeScale) DateTimeFormat() string {
 	return timeScaleFormats[s]
 }
@@ -189,6 +195,25 @@ func DetectTimeFormat(s string) (string, TimeScale, bool, bool) {
 	return "", 0, false, false
 }
 
+// RescaleTime converts time value v from unit from to unit to, both given
+// in nanoseconds. Conversion to coarser units rounds towards negative
+// infinity. Zero units leave v unchanged.
+func RescaleTime(v, from, to int64) int64 {
+	switch {
+	case from == to || from == 0 || to == 0:
+		return v
+	case from > to:
+		return v * (from / to)
+	default:
+		d := to / from
+		q := v / d
+		if v%d < 0 {
+			q--
+		}
+		return q
+	}
+}
+
 func UnixDays(t time.Time) int64 {
 	return int64(t.Sub(time.Unix(0, 0)) / (24 * time.Hour))
 }
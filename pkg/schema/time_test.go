@@ -0,0 +1,39 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeScaleNanos(t *testing.T) {
+	require.Equal(t, int64(1), TIME_SCALE_NANO.Nanos())
+	require.Equal(t, int64(1000), TIME_SCALE_MICRO.Nanos())
+	require.Equal(t, int64(1000000), TIME_SCALE_MILLI.Nanos())
+	require.Equal(t, int64(1000000000), TIME_SCALE_SECOND.Nanos())
+	require.Equal(t, int64(86400000000000), TIME_SCALE_DAY.Nanos())
+	require.Equal(t, TIME_SCALE_DAY.Nanos(), TimeScale(9).Nanos())
+}
+
+func TestRescaleTime(t *testing.T) {
+	ms, us := TIME_SCALE_MILLI.Nanos(), TIME_SCALE_MICRO.Nanos()
+	for _, c := range []struct {
+		v, from, to, want int64
+	}{
+		{1500, ms, ms, 1500},
+		{1500, ms, us, 1500000},
+		{-1500, ms, us, -1500000},
+		{1500, us, ms, 1},
+		{-1500, us, ms, -2}, // rounds towards negative infinity
+		{-2000, us, ms, -2},
+		{86400001, ms, TIME_SCALE_DAY.Nanos(), 1},
+		{-1, ms, TIME_SCALE_DAY.Nanos(), -1},
+		{42, 0, ms, 42},
+		{42, ms, 0, 42},
+	} {
+		require.Equal(t, c.want, RescaleTime(c.v, c.from, c.to), "%d %d->%d", c.v, c.from, c.to)
+	}
+}
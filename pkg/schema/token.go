@@ -0,0 +1,159 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenizerType defines how token indexes split string values into terms.
+type TokenizerType byte
+
+const (
+	TokenizerWords TokenizerType = iota // lower case words (letters and digits)
+	TokenizerNgram                      // lower case n-grams for substring search
+)
+
+var tokenizerNames = [...]string{
+	TokenizerWords: "words",
+	TokenizerNgram: "ngram",
+}
+
+func (t TokenizerType) String() string {
+	if int(t) < len(tokenizerNames) {
+		return tokenizerNames[t]
+	}
+	return "tokenizer_" + strconv.Itoa(int(t))
+}
+
+// Tokenizer splits string and bytes values into index terms. The zero
+// value is a word tokenizer.
+type Tokenizer struct {
+	Type TokenizerType
+	N    int // n-gram length in runes
+}
+
+// Words returns a tokenizer which emits lower case words.
+func Words() Tokenizer {
+	return Tokenizer{Type: TokenizerWords}
+}
+
+// Ngram returns a tokenizer which emits lower case n-grams of n runes.
+func Ngram(n int) Tokenizer {
+	return Tokenizer{Type: TokenizerNgram, N: n}
+}
+
+// ParseTokenizer parses tokenizers in the format used by struct tags,
+// i.e. `words` or `ngram(3)`.
+func ParseTokenizer(s string) (Tokenizer, error) {
+	name, arg, hasArg := strings.Cut(strings.TrimSpace(s), "(")
+	if hasArg {
+		if !strings.HasSuffix(arg, ")") {
+			return Tokenizer{}, fmt.Errorf("invalid tokenizer %q", s)
+		}
+		arg = strings.TrimSpace(strings.TrimSuffix(arg, ")"))
+	}
+	switch name {
+	case "words":
+		if hasArg {
+			return Tokenizer{}, fmt.Errorf("invalid tokenizer %q", s)
+		}
+		return Words(), nil
+	case "ngram":
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return Tokenizer{}, fmt.Errorf("invalid n-gram length %q", arg)
+		}
+		return Ngram(n), nil
+	default:
+		return Tokenizer{}, fmt.Errorf("unsupported tokenizer %q", s)
+	}
+}
+
+func (t Tokenizer) String() string {
+	if t.Type == TokenizerNgram {
+		return fmt.Sprintf("ngram(%d)", t.N)
+	}
+	return t.Type.String()
+}
+
+func (t Tokenizer) Validate() error {
+	switch t.Type {
+	case TokenizerWords:
+		return nil
+	case TokenizerNgram:
+		if t.N < 1 || t.N > 255 {
+			return fmt.Errorf("invalid n-gram length %d", t.N)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid tokenizer type %d", t.Type)
+	}
+}
+
+// Tokens returns the unique terms contained in b in order of first
+// occurence. Terms are lower case and do not share memory with b.
+func (t Tokenizer) Tokens(b []byte) [][]byte {
+	b = bytes.ToLower(b)
+	var res [][]byte
+	switch t.Type {
+	case TokenizerWords:
+		res = bytes.FieldsFunc(b, isTokenSeparator)
+	case TokenizerNgram:
+		if utf8.RuneCount(b) < t.N {
+			return nil
+		}
+		// collect rune offsets to slice n-grams at rune boundaries
+		offs := make([]int, 0, len(b)+1)
+		for i := range string(b) {
+			offs = append(offs, i)
+		}
+		offs = append(offs, len(b))
+		res = make([][]byte, 0, len(offs)-t.N)
+		for i := 0; i+t.N < len(offs); i++ {
+			res = append(res, b[offs[i]:offs[i+t.N]])
+		}
+	}
+	return uniqueTokens(res)
+}
+
+func isTokenSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+func uniqueTokens(tokens [][]byte) [][]byte {
+	if len(tokens) < 2 {
+		return tokens
+	}
+	seen := make(map[string]struct{}, len(tokens))
+	res := tokens[:0]
+	for _, v := range tokens {
+		if _, ok := seen[string(v)]; ok {
+			continue
+		}
+		seen[string(v)] = struct{}{}
+		res = append(res, v)
+	}
+	return res
+}
+
+// ParseTermQuery splits a match query into a list of alternatives which
+// each contain a list of required words. Alternatives are separated by
+// `|`, words inside an alternative are separated by white space, e.g.
+// `foo bar | baz` matches values containing foo and bar or baz. Words
+// use the same rules as the word tokenizer.
+func ParseTermQuery(q []byte) [][][]byte {
+	var res [][][]byte
+	for alt := range bytes.SplitSeq(q, []byte("|")) {
+		if terms := Words().Tokens(alt); len(terms) > 0 {
+			res = append(res, terms)
+		}
+	}
+	return res
+}
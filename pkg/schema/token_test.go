@@ -0,0 +1,103 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func tokenStrings(tokens [][]byte) []string {
+	var res []string
+	for _, v := range tokens {
+		res = append(res, string(v))
+	}
+	return res
+}
+
+func TestTokenizerWords(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"  ,;  ", nil},
+		{"hello", []string{"hello"}},
+		{"Hello, World!", []string{"hello", "world"}},
+		{"foo-bar_baz 42", []string{"foo", "bar", "baz", "42"}},
+		{"Foo foo FOO bar", []string{"foo", "bar"}},
+		{"Grüße aus Köln", []string{"grüße", "aus", "köln"}},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.want, tokenStrings(Words().Tokens([]byte(c.in))), c.in)
+	}
+}
+
+func TestTokenizerNgram(t *testing.T) {
+	cases := []struct {
+		n    int
+		in   string
+		want []string
+	}{
+		{3, "", nil},
+		{3, "ab", nil},
+		{3, "abc", []string{"abc"}},
+		{3, "ABCD", []string{"abc", "bcd"}},
+		{2, "aaaa", []string{"aa"}},
+		{2, "a b", []string{"a ", " b"}},
+		{2, "äöü", []string{"äö", "öü"}},
+		{1, "abca", []string{"a", "b", "c"}},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.want, tokenStrings(Ngram(c.n).Tokens([]byte(c.in))), "%d %q", c.n, c.in)
+	}
+}
+
+func TestTokenizerNoAlias(t *testing.T) {
+	// terms must not share memory with the source value
+	src := []byte("hello world")
+	for _, tok := range []Tokenizer{Words(), Ngram(3)} {
+		terms := tok.Tokens(src)
+		require.NotEmpty(t, terms)
+		first := string(terms[0])
+		copy(src, "XXXXXXXXXXX")
+		require.Equal(t, first, string(terms[0]), tok.String())
+		copy(src, "hello world")
+	}
+}
+
+func TestTokenizerValidate(t *testing.T) {
+	require.NoError(t, Words().Validate())
+	require.NoError(t, Ngram(1).Validate())
+	require.NoError(t, Ngram(255).Validate())
+	require.Error(t, Ngram(0).Validate())
+	require.Error(t, Ngram(256).Validate())
+	require.Error(t, Tokenizer{Type: 7}.Validate())
+	require.Equal(t, "words", Words().String())
+	require.Equal(t, "ngram(3)", Ngram(3).String())
+	require.Equal(t, "tokenizer_7", TokenizerType(7).String())
+}
+
+func TestParseTermQuery(t *testing.T) {
+	cases := []struct {
+		in   string
+		want [][]string
+	}{
+		{"", nil},
+		{" | ", nil},
+		{"foo", [][]string{{"foo"}}},
+		{"Foo Bar", [][]string{{"foo", "bar"}}},
+		{"foo bar | baz", [][]string{{"foo", "bar"}, {"baz"}}},
+		{"foo || bar foo foo", [][]string{{"foo"}, {"bar", "foo"}}},
+	}
+	for _, c := range cases {
+		alts := ParseTermQuery([]byte(c.in))
+		var got [][]string
+		for _, terms := range alts {
+			got = append(got, tokenStrings(terms))
+		}
+		require.Equal(t, c.want, got, c.in)
+	}
+}
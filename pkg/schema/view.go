@@ -5,7 +5,9 @@ package schema
 
 import (
 	"encoding/binary"
+	"encoding/json"
 	"math"
+	"reflect"
 	"time"
 
 	"blockwatch.cc/knoxdb/internal/types"
@@ -67,6 +69,13 @@ func (v *View) buildFromSchema() *View {
 		if f.Fixed > 0 {
 			sz = int(f.Fixed)
 		}
+		if f.HasValidity() {
+			// skip validity flag, offsets point to the value
+			v.minsz++
+			if ofs >= 0 {
+				ofs++
+			}
+		}
 		if v.pki < 0 && f.Flags.Is(types.FieldFlagPrimary) && f.Type == FT_U64 {
 			// remember the first uint64 primary key field
 			v.pki = i
@@ -170,10 +179,37 @@ func (v View) Get(i int) (val any, ok bool) {
 		val, ok = num.NewDecimal32(int32(LE.Uint32(v.buf[x:y])), field.Scale), true
 	case FT_BIGINT:
 		val, ok = num.NewBigFromBytes(v.buf[x:y]), true
+	case FT_LIST:
+		val, ok = types.ListValue(field.Elem.BlockType(), v.buf[x:y]), true
+	case FT_DOC:
+		val, ok = json.RawMessage(v.buf[x:y]), true
 	}
 	return
 }
 
+// IsNull returns true when nullable field i contains a NULL value. NULL
+// values are returned as zero values by Get.
+func (v View) IsNull(i int) bool {
+	if i < 0 || i >= len(v.ofs) || !v.IsValid() || v.ofs[i] < 0 {
+		return false
+	}
+	f := v.schema.Fields[i]
+	return f.HasValidity() && v.buf[v.flag(f, v.ofs[i])] == 0
+}
+
+// flag returns the position of the validity flag for a nullable field
+// whose value starts at ofs. Dynamic length fields carry a length prefix
+// between flag and value.
+func (v View) flag(f *Field, ofs int) int {
+	switch f.Type {
+	case FT_STRING, FT_BYTES, FT_BIGINT, FT_LIST, FT_DOC:
+		if f.Fixed == 0 {
+			return ofs - 5
+		}
+	}
+	return ofs - 1
+}
+
 func (v View) GetPhy(i int) (val any, ok bool) {
 	if i < 0 || i > len(v.ofs) || !v.IsValid() {
 		return
@@ -192,7 +228,7 @@ func (v View) GetPhy(i int) (val any, ok bool) {
 		val, ok = math.Float64frombits(LE.Uint64(v.buf[x:y])), true
 	case FT_BOOL:
 		val, ok = v.buf[x] > 0, true
-	case FT_STRING, FT_BYTES, FT_BIGINT:
+	case FT_STRING, FT_BYTES, FT_BIGINT, FT_LIST, FT_DOC:
 		val, ok = v.buf[x:y], true
 	case FT_I32, FT_D32:
 		val, ok = int32(LE.Uint32(v.buf[x:y])), true
@@ -335,6 +371,17 @@ func (v View) Append(val any, i int) any {
 			val = make([]num.Big, 0)
 		}
 		val = append(val.([]num.Big), num.NewBigFromBytes(v.buf[x:y]))
+	case FT_LIST:
+		if val == nil {
+			val = reflect.MakeSlice(reflect.SliceOf(field.GoType()), 0, 0).Interface()
+		}
+		list := types.ListValue(field.Elem.BlockType(), v.buf[x:y])
+		val = reflect.Append(reflect.ValueOf(val), reflect.ValueOf(list)).Interface()
+	case FT_DOC:
+		if val == nil {
+			val = make([]json.RawMessage, 0)
+		}
+		val = append(val.([]json.RawMessage), json.RawMessage(v.buf[x:y]))
 	}
 	return val
 }
@@ -361,12 +408,20 @@ func (v View) Set(i int, val any) {
 	if x == -2 {
 		return
 	}
+	if field.HasValidity() {
+		// nil sets NULL, other values are valid
+		if val == nil {
+			v.buf[v.flag(field, x)] = 0
+			return
+		}
+		v.buf[v.flag(field, x)] = 1
+	}
 	switch field.Type {
 	case FT_U64:
 		if u64, ok := val.(uint64); ok {
 			LE.PutUint64(v.buf[x:y], u64)
 		}
-	case FT_STRING, FT_BYTES, FT_BIGINT:
+	case FT_STRING, FT_BYTES, FT_BIGINT, FT_LIST, FT_DOC:
 		// unsupported, may alter length
 	case FT_TIMESTAMP, FT_TIME, FT_DATE:
 		if tm, ok := val.(time.Time); ok {
@@ -461,13 +516,16 @@ func (v *View) Reset(buf []byte) *View {
 				v.ofs[i] = -2
 				continue
 			}
+			if f.HasValidity() {
+				ofs++
+			}
 			if f.IsFixedSize() && skip {
 				ofs += v.len[i]
 				continue
 			}
 			skip = false
 			switch f.Type {
-			case FT_STRING, FT_BYTES, FT_BIGINT:
+			case FT_STRING, FT_BYTES, FT_BIGINT, FT_LIST, FT_DOC:
 				if f.Fixed > 0 {
 					v.ofs[i] = ofs
 					v.len[i] = int(f.Fixed)
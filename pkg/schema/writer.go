@@ -10,6 +10,7 @@ import (
 	"math/big"
 	"time"
 
+	"blockwatch.cc/knoxdb/internal/types"
 	"blockwatch.cc/knoxdb/pkg/num"
 	"blockwatch.cc/knoxdb/pkg/util"
 )
@@ -76,9 +77,6 @@ func (w *Writer) Reset() {
 
 func (w *Writer) Write(i int, val any) error {
 	// sanity checks
-	if val == nil {
-		return ErrNilValue
-	}
 	if i < 0 || i >= len(w.ofs) {
 		return ErrInvalidField
 	}
@@ -92,6 +90,20 @@ func (w *Writer) Write(i int, val any) error {
 		return nil
 	}
 
+	// write validity flag for nullable fields, nil writes NULL
+	if field.HasValidity() {
+		if val == nil {
+			clear(w.buf[x:y])
+			delete(w.dyn, i)
+			return nil
+		}
+		w.buf[x] = 1
+		x++
+	}
+	if val == nil {
+		return ErrNilValue
+	}
+
 	var err error
 	switch field.Type {
 	case FT_U64:
@@ -268,7 +280,7 @@ func (w *Writer) Write(i int, val any) error {
 		case num.Big:
 			buf = v.Bytes()
 		case *big.Int:
-			buf = v.Bytes()
+			buf = num.NewFromBigInt(v).Bytes()
 		case []byte:
 			buf = v
 		default:
@@ -278,6 +290,18 @@ func (w *Writer) Write(i int, val any) error {
 		// b.dyn[i] = bytes.Clone(buf)
 		w.dyn[i] = buf
 
+	case FT_LIST:
+		// variable size, packed element vector
+		if buf, ok := types.ListBytes(val); ok {
+			w.dyn[i] = buf
+		} else {
+			err = ErrInvalidValueType
+		}
+
+	case FT_DOC:
+		// variable size, compact JSON
+		w.dyn[i], err = types.DocumentBytes(val)
+
 	default:
 		err = ErrInvalidField
 	}
@@ -318,6 +342,9 @@ func (w *Writer) Bytes() []byte {
 			buf.Write(w.buf[w.ofs[n] : w.ofs[n]+w.len[n]])
 		} else {
 			// write dynamic field
+			if f.HasValidity() {
+				buf.WriteByte(w.buf[w.ofs[n]])
+			}
 			val := w.dyn[n]
 			var l [4]byte
 			LE.PutUint32(l[:], uint32(len(val)))
@@ -0,0 +1,91 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package series
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"blockwatch.cc/knoxdb/internal/reducer"
+)
+
+// JoinAsOf returns a result with all windows and columns of r followed by
+// the value columns of o aligned to the windows of r. Each window receives
+// the last non-null value of o from a window starting at or before it
+// (last value carried forward), windows before the first value of o are
+// null. Series may use different intervals, for example to align daily
+// prices to hourly balances.
+//
+// Grouped results join groups of the same name, an ungrouped o joins all
+// groups of r. Column names of o which exist in r are prefixed with the
+// table name of o. The returned result shares buckets of r.
+func (r *Result) JoinAsOf(o *Result) (*Result, error) {
+	if o.groupBy != "" && o.groupBy != r.groupBy {
+		return nil, fmt.Errorf("cannot join series grouped by %q to series grouped by %q", o.groupBy, r.groupBy)
+	}
+
+	// value columns of o, time windows come from r
+	var idx []int
+	res := *r
+	res.cols = slices.Clone(r.cols)
+	for i, name := range o.cols {
+		if name == "time" {
+			continue
+		}
+		if r.cols.Contains(name) {
+			name = o.table + "." + name
+		}
+		idx = append(idx, i)
+		res.cols = append(res.cols, name)
+	}
+
+	res.buckets = make(map[string][]reducer.Bucket, len(r.buckets))
+	for group, buckets := range r.buckets {
+		src, ok := o.buckets[group]
+		if o.groupBy == "" {
+			src, ok = o.buckets[""]
+		}
+
+		// window times of r
+		var times []time.Time
+		if len(buckets) > 0 {
+			buckets[0].Walk(func(t time.Time, _ any) bool {
+				times = append(times, t)
+				return true
+			})
+		}
+
+		joined := slices.Clone(buckets)
+		for _, i := range idx {
+			typ, scale := o.buckets[""][i].Type()
+			b := reducer.NewValueBucket(typ, scale)
+			b.WithName(o.cols[i])
+			var (
+				last any
+				k    int
+				ts   []time.Time
+				vals []any
+			)
+			if ok {
+				src[i].Walk(func(t time.Time, v any) bool {
+					if v != nil {
+						ts = append(ts, t)
+						vals = append(vals, v)
+					}
+					return true
+				})
+			}
+			for _, t := range times {
+				for ; k < len(ts) && !ts[k].After(t); k++ {
+					last = vals[k]
+				}
+				b.Append(t, last)
+			}
+			joined = append(joined, b)
+		}
+		res.buckets[group] = joined
+	}
+	return &res, nil
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package series
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"blockwatch.cc/knoxdb/internal/pack"
+	"blockwatch.cc/knoxdb/internal/reducer"
+	"blockwatch.cc/knoxdb/internal/types"
+	"blockwatch.cc/knoxdb/pkg/arrow"
+	"blockwatch.cc/knoxdb/pkg/num"
+	"blockwatch.cc/knoxdb/pkg/schema"
+)
+
+// WriteCSV writes one CSV record per output step with a header line.
+// Grouped results start each record with the group name. Null fills
+// are written as empty fields.
+func (r Result) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	grouped := r.groupBy != ""
+	hdr := make([]string, 0, len(r.cols)+1)
+	if grouped {
+		hdr = append(hdr, r.groupBy)
+	}
+	hdr = append(hdr, r.cols...)
+	if err := cw.Write(hdr); err != nil {
+		return err
+	}
+	rec := make([]string, len(hdr))
+	for row := range r.Rows() {
+		vals := rec
+		if grouped {
+			rec[0] = row.Group
+			vals = rec[1:]
+		}
+		for i := range row.Values {
+			vals[i] = row.Format(i)
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Format returns the display string of value i. Times use RFC3339 and
+// null fills format as empty string.
+func (r Row) Format(i int) string {
+	switch val := r.Values[i].(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// Schema returns a schema describing result rows. Grouped results start
+// with a string column holding the group name. All value columns are
+// nullable to represent null fills.
+func (r Result) Schema() (*schema.Schema, error) {
+	b := schema.NewBuilder().WithName(r.table)
+	if r.groupBy != "" {
+		b.Add(r.groupBy, types.FieldTypeString)
+	}
+	buckets := r.buckets[""]
+	for i, name := range r.cols {
+		typ, scale := buckets[i].Type()
+		b.Add(name, typ, schema.Scale(scale), schema.Nullable())
+	}
+	s := b.Finalize().Schema()
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// WriteArrow writes the result to w as Arrow IPC stream with one record
+// batch per group. Column types follow the result schema.
+func (r Result) WriteArrow(w io.Writer) error {
+	s, err := r.Schema()
+	if err != nil {
+		return err
+	}
+	aw, err := arrow.NewWriter(s, w)
+	if err != nil {
+		return err
+	}
+	ofs := len(s.Fields) - len(r.cols)
+	for _, group := range r.groups {
+		times, cols := r.values(group)
+		if len(times) == 0 {
+			continue
+		}
+		pkg := pack.New().
+			WithSchema(s).
+			WithMaxRows(len(times)).
+			Alloc()
+		if ofs > 0 {
+			for range times {
+				pkg.Block(0).Append([]byte(group))
+			}
+		}
+		for i, col := range cols {
+			b := pkg.Block(ofs + i)
+			f := s.Fields[ofs+i]
+			for k := range times {
+				var v any
+				if k < len(col) {
+					v = col[k]
+				}
+				if v == nil {
+					b.SetNull(k)
+					v = f.Type.Zero()
+				}
+				b.Append(blockValue(v, f))
+			}
+		}
+		pkg.UpdateLen()
+		err := aw.WritePack(pkg)
+		pkg.Release()
+		if err != nil {
+			return err
+		}
+	}
+	return aw.Close()
+}
+
+// blockValue converts a series value into the type stored in blocks of
+// field f.
+func blockValue(v any, f *schema.Field) any {
+	switch val := v.(type) {
+	case time.Time:
+		return schema.TimeScale(f.Scale).ToUnix(val)
+	case num.Decimal256:
+		return val.Quantize(f.Scale).Int256()
+	case num.Decimal128:
+		return val.Quantize(f.Scale).Int128()
+	case string:
+		return []byte(val)
+	case reducer.Histogram:
+		return []byte(val.String())
+	default:
+		return v
+	}
+}
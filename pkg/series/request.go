@@ -29,6 +29,7 @@ type Request struct {
 	Limit    int              `form:"limit,default=100"`
 	GroupBy  string           `form:"group_by"`
 	Table    string           `form:"table"`
+	NoRollup bool             `form:"no_rollup"`
 	TypeMap  TypeMap
 	table    engine.TableEngine
 	log      log.Logger
@@ -91,6 +92,11 @@ func (r *Request) WithGroupBy(g string) *Request {
 	return r
 }
 
+func (r *Request) WithNoRollup(v bool) *Request {
+	r.NoRollup = v
+	return r
+}
+
 func (r *Request) WithType(name string, agg reducer.Aggregatable) *Request {
 	r.TypeMap[name] = agg
 	return r
@@ -134,15 +140,29 @@ func (r *Request) MakeBucket(expr Expr, s *schema.Schema) (reducer.Bucket, error
 	if !ok {
 		return nil, fmt.Errorf("unknown column %q", expr.Field)
 	}
-	b := reducer.NewBucket(f.Type)
+	index, _ := s.Index(expr.Field)
+	var b reducer.Bucket
+	switch expr.Reduce {
+	case reducer.ReducerFuncHist:
+		b = reducer.NewHistBucket(f.Type, reducer.DefaultHistogramBins)
+	case reducer.ReducerFuncTWA:
+		tf, ok := s.Find("time")
+		if !ok {
+			return nil, fmt.Errorf("missing time field in result schema")
+		}
+		ti, _ := s.Index("time")
+		b = reducer.NewTWABucket(f.Type, ti, tf.Scale)
+	default:
+		b = reducer.NewBucket(f.Type, f.Scale)
+	}
 	if b == nil {
-		return nil, fmt.Errorf("unsupported column type %q", f.Type)
+		return nil, fmt.Errorf("unsupported column type %q for reducer %s", f.Type, expr.Reduce)
 	}
 	if v, ok := r.TypeMap[expr.Field]; ok {
 		b = b.WithTypeOf(v)
 	}
 	return b.WithName(expr.Field).
-		WithIndex(int(f.Id)).
+		WithIndex(index).
 		WithReducer(expr.Reduce).
 		WithDimensions(r.Range, r.Interval).
 		WithLimit(r.Limit).
@@ -0,0 +1,162 @@
+// Copyright (c) 2025 Blockwatch Data Inc.
+// Author: alex@blockwatch.cc
+
+package series
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"blockwatch.cc/knoxdb/internal/engine"
+	"blockwatch.cc/knoxdb/internal/query"
+	"blockwatch.cc/knoxdb/internal/reducer"
+	"blockwatch.cc/knoxdb/internal/rollup"
+)
+
+// rollup returns a copy of the request that reads from the coarsest
+// rollup table which can answer it, or nil when no rollup matches.
+// A rollup matches when it has the same or a finer grouping, keeps all
+// selected aggregates and every output window is a union of whole
+// rollup windows. The rewritten request selects an extra row count.
+// Minimum and maximum partials cannot subtract deleted rows, so rollups
+// with merged deletes in the requested range do not answer min or max.
+func (r Request) rollup(ctx context.Context) *Request {
+	if r.table == nil || len(r.TypeMap) > 0 {
+		return nil
+	}
+	e := engine.GetEngine(ctx)
+	if e == nil {
+		return nil
+	}
+
+	// output window boundaries, keep the caller's select list
+	bounds := r
+	bounds.Select = nil
+	bounds.Sanitize()
+
+	var (
+		best *Request
+		dur  time.Duration
+		name = r.table.Schema().Name
+	)
+	for _, t := range e.RollupTables(name) {
+		def, err := rollup.Parse(name, t.Schema())
+		if err != nil || def.Time != "time" {
+			continue
+		}
+		if r.GroupBy != "" && def.GroupBy != r.GroupBy {
+			continue
+		}
+		if d := def.Interval.Duration(); best != nil && d <= dur {
+			continue
+		} else if !bounds.alignsWith(def.Interval.Truncate) {
+			continue
+		} else if sel, ok := r.Select.rollup(def); ok {
+			if r.Select.hasMinMax() && bounds.hasDeletes(ctx, t) {
+				continue
+			}
+			alt := r
+			alt.Select = append(sel, Expr{rollup.CountField, reducer.ReducerFuncSum})
+			alt.table = t
+			best, dur = &alt, d
+		}
+	}
+	return best
+}
+
+// hasDeletes returns true when rollup table t contains negative partials
+// of deleted rows in the request range or cannot be checked.
+func (r Request) hasDeletes(ctx context.Context, t engine.TableEngine) bool {
+	flt, err := query.And(
+		query.Range("time", r.Range.From, r.Range.To),
+		query.Lt(rollup.CountField, int64(0)),
+	).Compile(t.Schema())
+	if err != nil {
+		return true
+	}
+	plan := query.NewQueryPlan().
+		WithSchema(t.Schema()).
+		WithTable(t).
+		WithFilters(flt)
+	defer plan.Close()
+	if err := plan.Compile(ctx); err != nil {
+		return true
+	}
+	n, err := t.Count(ctx, plan)
+	return err != nil || n > 0
+}
+
+// alignsWith returns true when all output window boundaries are fixed
+// points of truncate.
+func (r Request) alignsWith(truncate func(time.Time) time.Time) bool {
+	n := 0
+	for t := r.Range.From; !t.After(r.Range.To); t = r.Interval.Next(t, 1) {
+		if !truncate(t).Equal(t) {
+			return false
+		}
+		// later windows are cut off by the limit
+		if n++; r.Limit > 0 && n > r.Limit {
+			break
+		}
+	}
+	return true
+}
+
+// rollup maps expressions to rollup table fields. Row counts become sums
+// of partial counts.
+func (l ExprList) rollup(def *rollup.Rollup) (ExprList, bool) {
+	sel := make(ExprList, len(l))
+	for i, v := range l {
+		switch {
+		case v.Field == def.Time:
+			if v.Reduce != reducer.ReducerFuncFirst {
+				return nil, false
+			}
+			sel[i] = v
+		case v.Field == "count" || (v.Reduce == reducer.ReducerFuncCount && v.Field == "*"):
+			sel[i] = Expr{rollup.CountField, reducer.ReducerFuncSum}
+		default:
+			a, ok := def.Find(v.Field, rollup.Func(v.Reduce))
+			if !ok {
+				return nil, false
+			}
+			sel[i] = Expr{a.Name(), v.Reduce}
+		}
+	}
+	return sel, true
+}
+
+// hasMinMax returns true when any expression reduces to a minimum or
+// maximum.
+func (l ExprList) hasMinMax() bool {
+	return slices.ContainsFunc(l, func(v Expr) bool {
+		return v.Reduce == reducer.ReducerFuncMin || v.Reduce == reducer.ReducerFuncMax
+	})
+}
+
+// dropEmpty removes windows whose partial row counts in column n sum to
+// zero, i.e. all their rows were deleted, and truncates buckets to n
+// columns. Groups without windows are removed.
+func (r *Result) dropEmpty(n int) {
+	for group, buckets := range r.buckets {
+		var empty []time.Time
+		buckets[n].Walk(func(t time.Time, v any) bool {
+			if v == int64(0) {
+				empty = append(empty, t)
+			}
+			return true
+		})
+		for _, t := range empty {
+			for _, b := range buckets {
+				b.Remove(t)
+			}
+		}
+		if group != "" && buckets[n].Len() == 0 {
+			delete(r.buckets, group)
+			r.groups = slices.DeleteFunc(r.groups, func(g string) bool { return g == group })
+			continue
+		}
+		r.buckets[group] = buckets[:n]
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"iter"
+	"slices"
 	"strconv"
 	"time"
 
@@ -37,12 +39,121 @@ func (r Result) Columns() []string {
 	return r.cols
 }
 
-// TODO: allow programmatic access to time-series data
-// func GetResultTimes() []time.Time {
-// }
+// bucket returns the bucket of column in group.
+func (r Result) bucket(group, column string) (reducer.Bucket, error) {
+	if !slices.Contains(r.groups, group) {
+		return nil, fmt.Errorf("unknown group %q", group)
+	}
+	i := r.cols.Index(column)
+	if i < 0 {
+		return nil, fmt.Errorf("unknown column %q", column)
+	}
+	return r.buckets[group][i], nil
+}
+
+// GetResultTimes returns the start times of all output windows in group.
+// Use an empty group name for ungrouped results.
+func GetResultTimes(r *Result, group string) ([]time.Time, error) {
+	if len(r.cols) == 0 {
+		return nil, nil
+	}
+	b, err := r.bucket(group, r.cols[0])
+	if err != nil {
+		return nil, err
+	}
+	times := make([]time.Time, 0, b.Len())
+	b.Walk(func(t time.Time, _ any) bool {
+		times = append(times, t)
+		return true
+	})
+	return times, nil
+}
+
+// GetResultVector returns all output values of column in group. T must
+// match the Go type of the column's bucket as returned by Walk. Null fills
+// are returned as zero values.
+func GetResultVector[T any](r *Result, group, column string) ([]T, error) {
+	b, err := r.bucket(group, column)
+	if err != nil {
+		return nil, err
+	}
+	vals := make([]T, 0, b.Len())
+
+	// native buckets iterate without boxing values
+	if e, ok := b.(interface {
+		Each(func(time.Time, T, bool) bool)
+	}); ok {
+		e.Each(func(_ time.Time, v T, _ bool) bool {
+			vals = append(vals, v)
+			return true
+		})
+		return vals, nil
+	}
+
+	b.Walk(func(_ time.Time, v any) bool {
+		val, ok := v.(T)
+		if !ok && v != nil {
+			err = fmt.Errorf("column %q: invalid value type %T", column, v)
+			return false
+		}
+		vals = append(vals, val)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vals, nil
+}
+
+// Row is a single output step of a time-series result. Values are in
+// column order, null fills are nil.
+type Row struct {
+	Time   time.Time
+	Group  string
+	Values []any
+}
+
+// Rows returns an iterator over all output steps ordered by group and
+// time. Values slices are reused between rows.
+func (r Result) Rows() iter.Seq[Row] {
+	return func(yield func(Row) bool) {
+		for _, group := range r.groups {
+			times, cols := r.values(group)
+			row := Row{Group: group, Values: make([]any, len(cols))}
+			for k, t := range times {
+				row.Time = t
+				for i, col := range cols {
+					row.Values[i] = nil
+					if k < len(col) {
+						row.Values[i] = col[k]
+					}
+				}
+				if !yield(row) {
+					return
+				}
+			}
+		}
+	}
+}
 
-// func GetResultVector[T any](group, column string) []T {
-// }
+// values collects step times and column values of group. Buckets emit
+// column by column, so values are returned in column major order.
+func (r Result) values(group string) ([]time.Time, [][]any) {
+	var (
+		times []time.Time
+		cols  = make([][]any, len(r.cols))
+	)
+	for i, b := range r.buckets[group] {
+		b.Walk(func(t time.Time, v any) bool {
+			if i == 0 {
+				times = append(times, t)
+			}
+			cols[i] = append(cols[i], v)
+			return true
+		})
+	}
+	return times, cols
+}
 
 // output series from all buckets
 //
@@ -101,6 +212,11 @@ func (r Request) Query(key string) (*query.QueryPlan, error) {
 		cols.AddUnique(r.GroupBy)
 	}
 
+	// query plans require the primary key in result schemas
+	if pk := r.table.Schema().Pk(); pk != nil {
+		cols.AddUnique(pk.Name)
+	}
+
 	// derive query schema from table schema
 	s, err := r.table.Schema().Select(cols...)
 	if err != nil {
@@ -122,15 +238,37 @@ func (r Request) Query(key string) (*query.QueryPlan, error) {
 	return plan, nil
 }
 
+// Run executes the request. Unless disabled with NoRollup, requests are
+// answered from the coarsest matching rollup table. Rollups only contain
+// merged rows, so results may lag behind recent inserts until the table
+// journal is merged.
 func (r Request) Run(ctx context.Context, key string) (*Result, error) {
-	plan, err := r.Query(key)
+	req, rolled := r, false
+	if !r.NoRollup {
+		if alt := r.rollup(ctx); alt != nil {
+			req, rolled = *alt, true
+		}
+	}
+	plan, err := req.Query(key)
 	if err != nil {
 		return nil, err
 	}
 	if err := plan.Compile(ctx); err != nil {
 		return nil, err
 	}
-	return r.RunQuery(ctx, plan)
+	res, err := req.RunQuery(ctx, plan)
+	if err != nil {
+		return nil, err
+	}
+
+	// hide windows of deleted rows and the extra row count
+	if rolled {
+		res.dropEmpty(len(r.Select))
+	}
+
+	// report source column names
+	res.cols = r.Select.Cols()
+	return res, nil
 }
 
 func (req Request) RunQuery(ctx context.Context, plan *query.QueryPlan) (*Result, error) {
@@ -139,6 +277,7 @@ func (req Request) RunQuery(ctx context.Context, plan *query.QueryPlan) (*Result
 	if !ok {
 		return nil, fmt.Errorf("missing time field in result schema")
 	}
+	timeScale := schema.TimeScale(plan.ResultSchema.Fields[timeIndex].Scale)
 	defer plan.Close()
 
 	// create stream manager
@@ -177,9 +316,13 @@ func (req Request) RunQuery(ctx context.Context, plan *query.QueryPlan) (*Result
 		if !ok {
 			return nil, fmt.Errorf("unknown group_by field %q", req.GroupBy)
 		}
-		groupByEnum, ok = req.table.Schema().Enums.Load().Lookup(f.Name)
-		if !ok {
-			return nil, fmt.Errorf("missing enum dictionary for field %q", req.GroupBy)
+		if f.IsEnum() {
+			if enums := req.table.Schema().Enums.Load(); enums != nil {
+				groupByEnum, ok = enums.Lookup(f.Name)
+			}
+			if groupByEnum == nil {
+				return nil, fmt.Errorf("missing enum dictionary for field %q", req.GroupBy)
+			}
 		}
 	} else {
 		res.groups = append(res.groups, "")
@@ -191,9 +334,13 @@ func (req Request) RunQuery(ctx context.Context, plan *query.QueryPlan) (*Result
 	var last time.Time
 	err := plan.Table.Stream(ctx, plan, func(r engine.QueryRow) error {
 		// read time
-		val := r.Get(timeIndex)
-		t, ok := val.(time.Time)
-		if !ok {
+		var t time.Time
+		switch val := r.Get(timeIndex).(type) {
+		case time.Time:
+			t = val
+		case int64:
+			t = timeScale.FromUnix(val)
+		default:
 			return fmt.Errorf("invalid value type %T for time field", val)
 		}
 
@@ -215,8 +362,11 @@ func (req Request) RunQuery(ctx context.Context, plan *query.QueryPlan) (*Result
 			var groupName string
 			// try enum conversion first
 			if groupByEnum != nil {
-				if enum, ok := groupByEnum.Value(group.(uint16)); ok {
-					groupName = enum
+				switch code := group.(type) {
+				case uint16:
+					groupName, _ = groupByEnum.Value(code)
+				case uint32:
+					groupName, _ = groupByEnum.Value32(code)
 				}
 			}
 			// try any -> string conversion next
@@ -43,7 +43,7 @@ func UniqueStringsStable(s []string) []string {
 	seen := make(map[string]struct{}, len(s))
 	for i := 0; i < len(s); {
 		if _, ok := seen[s[i]]; ok {
-			s = slices.Delete(s, i, i)
+			s = slices.Delete(s, i, i+1)
 		} else {
 			seen[s[i]] = struct{}{}
 			i++
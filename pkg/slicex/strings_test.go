@@ -285,6 +285,12 @@ func TestStringSliceUnique(t *testing.T) {
 	}
 }
 
+func TestStringsUniqueStable(t *testing.T) {
+	assert.Equal(t, []string{}, UniqueStringsStable([]string{}))
+	assert.Equal(t, []string{"b", "a"}, UniqueStringsStable([]string{"b", "a"}))
+	assert.Equal(t, []string{"b", "a", "c"}, UniqueStringsStable([]string{"b", "a", "b", "c", "a"}))
+}
+
 func TestStringSliceIntersect(t *testing.T) {
 	var tests = []struct {
 		n string